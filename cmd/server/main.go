@@ -1,44 +1,69 @@
 package main
 
 import (
-	"database/sql"
+	"context"
+	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/rs/cors"
 	"github.com/worlve/sp-service/internal/api"
+	archivehandler "github.com/worlve/sp-service/internal/api/handlers/archive"
+	audithandler "github.com/worlve/sp-service/internal/api/handlers/audit"
+	commenthandler "github.com/worlve/sp-service/internal/api/handlers/comment"
 	healthcheckhandler "github.com/worlve/sp-service/internal/api/handlers/healthcheck"
+	metricshandler "github.com/worlve/sp-service/internal/api/handlers/metrics"
 	pagehandler "github.com/worlve/sp-service/internal/api/handlers/page"
 	pagedetailhandler "github.com/worlve/sp-service/internal/api/handlers/pagedetail"
+	streamhandler "github.com/worlve/sp-service/internal/api/handlers/stream"
+	webhookhandler "github.com/worlve/sp-service/internal/api/handlers/webhook"
+	"github.com/worlve/sp-service/internal/config"
+	"github.com/worlve/sp-service/internal/jobs/delivery"
+	"github.com/worlve/sp-service/internal/jobs/dispatch"
+	"github.com/worlve/sp-service/internal/jobs/retention"
+	"github.com/worlve/sp-service/internal/models/appuser"
+	"github.com/worlve/sp-service/internal/models/event"
+	"github.com/worlve/sp-service/internal/models/pagetemplate"
+	"github.com/worlve/sp-service/internal/models/property"
+	"github.com/worlve/sp-service/internal/models/version"
+	archiveservice "github.com/worlve/sp-service/internal/services/archive"
+	auditservice "github.com/worlve/sp-service/internal/services/audit"
+	commentservice "github.com/worlve/sp-service/internal/services/comment"
+	eventservice "github.com/worlve/sp-service/internal/services/event"
 	healthcheckservice "github.com/worlve/sp-service/internal/services/healthcheck"
 	pageservice "github.com/worlve/sp-service/internal/services/page"
 	pagedetailservice "github.com/worlve/sp-service/internal/services/pagedetail"
+	streamservice "github.com/worlve/sp-service/internal/services/stream"
+	webhookservice "github.com/worlve/sp-service/internal/services/webhook"
+	"github.com/worlve/sp-service/internal/stores/memorystore"
 	"github.com/worlve/sp-service/internal/stores/mysqlstore"
-	"github.com/worlve/sp-service/internal/util/env"
+	"github.com/worlve/sp-service/internal/stores/mysqlstore/migrations"
+	"github.com/worlve/sp-service/internal/stores/store"
+	"github.com/worlve/sp-service/internal/util/clock"
+	"github.com/worlve/sp-service/internal/util/logger"
+	"github.com/worlve/sp-service/internal/util/metrics"
+	"github.com/worlve/sp-service/internal/util/openapi"
+	"github.com/worlve/sp-service/internal/util/publicnet"
+	"github.com/worlve/sp-service/internal/util/ratelimit"
+	"github.com/worlve/sp-service/internal/util/tracing"
+	"github.com/worlve/sp-service/internal/util/transaction"
+	"go.uber.org/zap"
 )
 
-const localUIURL = "http://127.0.0.1:8081"
-
 const (
-	defaultAdminAuthSecret = "DEFAULT_SECRET"
-	defaultPort            = "8782"
-	defaultStaticPath      = "../../static"
-	defaultDatacenter      = "LOCAL"
+	maxPoolSaturation = 0.9
+	serviceName       = "sp-service"
 )
 
-func getHTTPServerAddr() string {
-	port := env.Get("PORT", defaultPort)
-	return ":" + port
-}
-
-func getHTTPServerReadTimeout() time.Duration {
-	return 10 * time.Second
-}
-
-func getHTTPServerWriteTimeout() time.Duration {
-	return 10 * time.Second
+func getHTTPServerAddr(c config.HTTP) string {
+	return ":" + c.Port
 }
 
 func getHTTPServerMaxHeaderBytes() int {
@@ -49,110 +74,590 @@ func getAPIPath() string {
 	return "api"
 }
 
-func getStaticPath() string {
-	return env.Get("STATIC_PATH", defaultStaticPath)
-}
-
-func getDatacenter() string {
-	return env.Get("DATACENTER", api.LocalDatacenterEnv)
+func getTrashRetentionInterval() time.Duration {
+	return time.Hour
 }
 
 func main() {
-	mysqldb, err := mysqlstore.SetupMySQL("")
+	c, err := config.Load(os.Getenv(config.FileEnvKey))
 	if err != nil {
-		fmt.Printf("Failed to connect to MySQL db.\nIf connecting locally, follow https://github.com/worlve/sp-database/blob/master/README.md to get the local db running.\n")
 		log.Fatal(err)
 	}
-	defer mysqldb.Close()
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		err := runMigrate(c.MySQL, os.Args[2:])
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "config" {
+		err := runConfig(c, os.Args[2:])
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	appLogger, err := logger.New(c.IsLocal())
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer appLogger.Sync()
+	shutdownTracing, err := setupTracing(c.Tracing, appLogger)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer shutdownTracing()
+	backend, err := setupBackend(c.Store.Backend, c.MySQL)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer backend.close()
 	apiPath := getAPIPath()
-	staticPath := getStaticPath()
-	datacenter := getDatacenter()
-	handler, err := setupHandler(apiPath, staticPath, datacenter, mysqldb)
+	readiness := &healthcheckservice.Readiness{
+		Checks: append(backend.readinessChecks, healthcheckservice.Check{
+			Name:  "staticDocs",
+			Check: healthcheckservice.CheckDir(fmt.Sprintf("%v/docs", c.HTTP.StaticPath)),
+		}),
+		Clock: clock.RealClock{},
+	}
+	eventService := eventservice.InstrumentedEventService{EventService: eventservice.EventService{
+		EventStore: backend.stores.EventStore,
+		Clock:      clock.RealClock{},
+	}}
+	lastOffset, err := eventService.GetLastOffset(context.Background())
+	if err != nil {
+		log.Fatal(err)
+	}
+	streamLog := streamservice.NewLog(c.Stream.LogSize, lastOffset)
+	dispatcher := &dispatch.Dispatcher{
+		EventService: eventService,
+		Interval:     c.Events.PollInterval.Duration,
+		Retention:    c.Events.Retention.Duration,
+		Clock:        clock.RealClock{},
+		Logger:       appLogger,
+	}
+	err = subscribeToEvents(context.Background(), dispatcher, backend.stores, streamLog, lastOffset)
+	if err != nil {
+		log.Fatal(err)
+	}
+	// the dispatcher is woken as soon as a change is committed, rather than waiting for its next poll
+	backend.unitOfWork = dispatch.UnitOfWork{UnitOfWork: backend.unitOfWork, Dispatcher: dispatcher}
+	handler, err := setupHandler(apiPath, c, backend, readiness, streamLog, appLogger)
 	if err != nil {
 		log.Fatal(err)
 	}
-	handler, err = setupCors(datacenter, handler)
+	handler, err = setupCors(c.CORS, handler)
 	if err != nil {
 		log.Fatal(err)
 	}
+	handler = api.SecurityHeaders{
+		Handler:    handler,
+		APIPath:    apiPath,
+		HSTSMaxAge: c.HTTP.HSTSMaxAge.Duration,
+	}
+	requestTimeout, writeTimeout := c.HTTP.RequestTimeout.Duration, c.HTTP.WriteTimeout.Duration
+	if requestTimeout > 0 && writeTimeout > 0 && requestTimeout >= writeTimeout {
+		appLogger.Warn("REQUEST_TIMEOUT should be shorter than HTTP_WRITE_TIMEOUT so timed out requests can still respond",
+			zap.Duration("requestTimeout", requestTimeout),
+			zap.Duration("writeTimeout", writeTimeout),
+		)
+	}
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	var jobs sync.WaitGroup
+	startTrashRetentionJob(jobsCtx, &jobs, c.TrashRetention, backend.stores.PageStore, appLogger)
+	startWebhookDeliveryJob(jobsCtx, &jobs, c.Webhooks, backend.stores.WebhookStore, appLogger)
+	startEventDispatchJob(jobsCtx, &jobs, dispatcher)
 	s := &http.Server{
-		Addr:           getHTTPServerAddr(),
-		Handler:        handler,
-		ReadTimeout:    getHTTPServerReadTimeout(),
-		WriteTimeout:   getHTTPServerWriteTimeout(),
-		MaxHeaderBytes: getHTTPServerMaxHeaderBytes(),
-	}
-	fmt.Printf("Starting server at http://localhost%v\nVerify locally by running:\ncurl -X GET http://localhost%v/%v/healthcheck\nAPI docs: http://localhost%v/%v/docs\n", getHTTPServerAddr(), getHTTPServerAddr(), getAPIPath(), getHTTPServerAddr(), getAPIPath())
-	log.Fatal(s.ListenAndServe())
-}
-
-func setupHandler(apiPath, staticPath, datacenter string, mysqldb *sql.DB) (http.Handler, error) {
-	var handler http.Handler
-	pageStore := mysqlstore.NewPageStore(mysqldb)
-	userStore := mysqlstore.NewUserStore(mysqldb)
-	healthcheckStore := mysqlstore.NewHealthcheckStore(mysqldb)
-	pageTemplateStore := mysqlstore.NewPageTemplateStore(mysqldb)
-	versionStore := mysqlstore.NewVersionStore(mysqldb)
+		Addr:              getHTTPServerAddr(c.HTTP),
+		Handler:           handler,
+		ReadTimeout:       c.HTTP.ReadTimeout.Duration,
+		ReadHeaderTimeout: c.HTTP.ReadHeaderTimeout.Duration,
+		WriteTimeout:      writeTimeout,
+		IdleTimeout:       c.HTTP.IdleTimeout.Duration,
+		MaxHeaderBytes:    getHTTPServerMaxHeaderBytes(),
+	}
+	// event streams stay open until the client leaves, so they're ended rather than waited on
+	s.RegisterOnShutdown(streamLog.Close)
+	shutdownDone := make(chan struct{})
+	go func() {
+		shutdownOnSignal(s, readiness, c.HTTP.DrainDelay.Duration, c.HTTP.ShutdownTimeout.Duration, appLogger)
+		close(shutdownDone)
+	}()
+	addr := getHTTPServerAddr(c.HTTP)
+	fmt.Printf("Starting server at http://localhost%v\nVerify locally by running:\ncurl -X GET http://localhost%v/%v/healthcheck\nAPI docs: http://localhost%v/%v/docs\n", addr, addr, apiPath, addr, apiPath)
+	err = s.ListenAndServe()
+	if err != http.ErrServerClosed {
+		log.Fatal(err)
+	}
+	<-shutdownDone
+	stopJobs()
+	jobs.Wait()
+	appLogger.Info("Server stopped")
+}
+
+// shutdownOnSignal gracefully shuts the server down once it's sent SIGTERM or SIGINT.
+// It first reports not-ready for the drain delay so load balancers stop sending it traffic, then stops accepting
+// new requests and waits up to the shutdown timeout for in-flight requests to finish.
+func shutdownOnSignal(s *http.Server, readiness *healthcheckservice.Readiness, drainDelay, shutdownTimeout time.Duration, appLogger *zap.Logger) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, syscall.SIGINT)
+	sig := <-signals
+	signal.Stop(signals)
+	appLogger.Info("Draining before shutdown", zap.String("signal", sig.String()), zap.Duration("drainDelay", drainDelay))
+	readiness.Drain()
+	time.Sleep(drainDelay)
+	appLogger.Info("Shutting down", zap.Duration("shutdownTimeout", shutdownTimeout))
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	err := s.Shutdown(ctx)
+	if err != nil {
+		appLogger.Warn("In-flight requests did not finish before the shutdown timeout", zap.String("err", err.Error()))
+		s.Close()
+	}
+}
+
+// storeBackend is the set of stores the services are built on.
+type storeBackend struct {
+	stores           store.Stores
+	healthcheckStore store.HealthcheckStore
+	unitOfWork       store.UnitOfWork
+	readinessChecks  []healthcheckservice.Check
+	close            func() error
+}
+
+// setupTracing sets the tracer for the configured exporter and returns a func that sends any remaining spans.
+func setupTracing(c config.Tracing, appLogger *zap.Logger) (func(), error) {
+	var exporter tracing.Exporter
+	switch c.Exporter {
+	case config.TraceExporterNone:
+		return func() {}, nil
+	case config.TraceExporterStdout:
+		exporter = tracing.NewWriterExporter(os.Stdout)
+	case config.TraceExporterFile:
+		f, err := os.OpenFile(c.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, err
+		}
+		exporter = tracing.NewWriterExporter(f)
+	case config.TraceExporterOTLP:
+		otlpExporter := tracing.NewOTLPExporter(c.OTLPEndpoint, serviceName, 0)
+		otlpExporter.OnError = func(err error) {
+			appLogger.Warn("Failed to export spans", zap.String("err", err.Error()))
+		}
+		exporter = otlpExporter
+	default:
+		return nil, fmt.Errorf("unsupported TRACE_EXPORTER \"%v\"", c.Exporter)
+	}
+	tracing.SetTracer(&tracing.Tracer{Exporter: exporter})
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		err := exporter.Shutdown(ctx)
+		if err != nil {
+			appLogger.Warn("Failed to shut down the trace exporter", zap.String("err", err.Error()))
+		}
+	}, nil
+}
+
+func setupBackend(backendName string, mysqlConfig config.MySQL) (storeBackend, error) {
+	switch backendName {
+	case config.StoreBackendMySQL:
+		return setupMySQLBackend(mysqlConfig)
+	case config.StoreBackendMemory:
+		return setupMemoryBackend(), nil
+	default:
+		return storeBackend{}, fmt.Errorf("unsupported STORE_BACKEND \"%v\"", backendName)
+	}
+}
+
+func setupMySQLBackend(mysqlConfig config.MySQL) (storeBackend, error) {
+	mysqldb, err := mysqlstore.SetupMySQL(mysqlConfig, "")
+	if err != nil {
+		fmt.Printf("Failed to connect to MySQL db.\nIf connecting locally, follow https://github.com/worlve/sp-database/blob/master/README.md to get the local db running.\nTo run without a db, set STORE_BACKEND=%v.\n", config.StoreBackendMemory)
+		return storeBackend{}, err
+	}
+	migrator, err := migrations.NewMigrator(mysqldb)
+	if err == nil {
+		err = migrator.CheckCompatible()
+	}
+	if err != nil {
+		mysqldb.Close()
+		return storeBackend{}, err
+	}
+	mysqlstore.RegisterMetrics(metrics.Default, mysqldb)
+	return storeBackend{
+		stores: store.Stores{
+			PageStore:         mysqlstore.NewPageStore(mysqldb),
+			PageDetailStore:   mysqlstore.NewPageDetailStore(mysqldb),
+			PageTemplateStore: mysqlstore.NewPageTemplateStore(mysqldb),
+			UserStore:         mysqlstore.NewUserStore(mysqldb),
+			VersionStore:      mysqlstore.NewVersionStore(mysqldb),
+			WebhookStore:      mysqlstore.NewWebhookStore(mysqldb),
+			EventStore:        mysqlstore.NewEventStore(mysqldb),
+			AuditStore:        mysqlstore.NewAuditStore(mysqldb),
+			CommentStore:      mysqlstore.NewCommentStore(mysqldb),
+		},
+		healthcheckStore: mysqlstore.NewHealthcheckStore(mysqldb),
+		unitOfWork:       mysqlstore.NewUnitOfWork(mysqldb),
+		readinessChecks: []healthcheckservice.Check{
+			{Name: "database", Check: mysqldb.PingContext},
+			{Name: "schema", Check: func(ctx context.Context) error {
+				return migrator.CheckCompatible()
+			}},
+			{Name: "connectionPool", Check: mysqlstore.CheckPoolSaturation(mysqldb, maxPoolSaturation)},
+		},
+		close: mysqldb.Close,
+	}, nil
+}
+
+// setupMemoryBackend returns an in-memory backend seeded with enough reference data to create pages.
+func setupMemoryBackend() storeBackend {
+	memdb := memorystore.NewDB()
+	memdb.AddUser(appuser.User{GUID: "UR_1", Email: "demo@worlve.com"})
+	memdb.AddVersion(version.Version{GUID: "VR_1", Name: "Default"})
+	memdb.AddPageTemplate(pagetemplate.PageTemplate{GUID: "PGT_1", Name: "Default"})
+	memdb.AddProperty(property.Property{Key: "population", Type: property.TypeNumber})
+	memdb.AddProperty(property.Property{Key: "description", Type: property.TypeString})
+	fmt.Printf("Using the in-memory store backend: data will be lost on restart.\nSend requests as the demo user with the header X-USER-ID: UR_1\n")
+	return storeBackend{
+		stores: store.Stores{
+			PageStore:         memorystore.NewPageStore(memdb),
+			PageDetailStore:   memorystore.NewPageDetailStore(memdb),
+			PageTemplateStore: memorystore.NewPageTemplateStore(memdb),
+			UserStore:         memorystore.NewUserStore(memdb),
+			VersionStore:      memorystore.NewVersionStore(memdb),
+			WebhookStore:      memorystore.NewWebhookStore(memdb),
+			EventStore:        memorystore.NewEventStore(memdb),
+			AuditStore:        memorystore.NewAuditStore(memdb),
+			CommentStore:      memorystore.NewCommentStore(memdb),
+		},
+		healthcheckStore: memorystore.NewHealthcheckStore(memdb),
+		unitOfWork:       memorystore.NewUnitOfWork(memdb),
+		close:            func() error { return nil },
+	}
+}
+
+const migrateUsage = "usage: server migrate <status|up|down|to <version>>"
+
+// runMigrate runs the migrate subcommand against the MySQL db.
+func runMigrate(mysqlConfig config.MySQL, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf(migrateUsage)
+	}
+	toVersion := -1
+	switch args[0] {
+	case "status", "up", "down":
+	case "to":
+		if len(args) < 2 {
+			return fmt.Errorf(migrateUsage)
+		}
+		version, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("version must be an integer: %v", args[1])
+		}
+		toVersion = version
+	default:
+		return fmt.Errorf(migrateUsage)
+	}
+	mysqldb, err := mysqlstore.SetupMySQL(mysqlConfig, "")
+	if err != nil {
+		return err
+	}
+	defer mysqldb.Close()
+	migrator, err := migrations.NewMigrator(mysqldb)
+	if err != nil {
+		return err
+	}
+	switch args[0] {
+	case "up":
+		err = migrator.Up()
+	case "down":
+		err = migrator.Down()
+	case "to":
+		err = migrator.To(toVersion)
+	}
+	if err != nil {
+		return err
+	}
+	status, err := migrator.Status()
+	if err != nil {
+		return err
+	}
+	for _, applied := range status.Applied {
+		fmt.Printf("applied  %04d_%v at %v\n", applied.Version, applied.Name, applied.AppliedAt.Format(time.RFC3339))
+	}
+	for _, pending := range status.Pending {
+		fmt.Printf("pending  %04d_%v\n", pending.Version, pending.Name)
+	}
+	fmt.Printf("schema version %v of %v\n", status.CurrentVersion, status.LatestVersion)
+	return nil
+}
+
+func setupHandler(apiPath string, c config.Config, backend storeBackend, readiness *healthcheckservice.Readiness, streamLog *streamservice.Log, appLogger *zap.Logger) (http.Handler, error) {
+	router := api.NewRouter(apiPath, c.HTTP.StaticPath, getRouterHandlers(apiPath, backend, readiness, streamLog))
+	authN, authZ := getAuths(apiPath, c.Datacenter, c.Auth)
+	specValidator, err := getSpecValidator(c.HTTP, router)
+	if err != nil {
+		return nil, err
+	}
+	return &api.Handler{
+		AuthN:          authN,
+		AuthZ:          authZ,
+		Router:         router,
+		Datacenter:     c.Datacenter,
+		APIPath:        apiPath,
+		Logger:         appLogger,
+		RequestTimeout: c.HTTP.RequestTimeout.Duration,
+		RateLimiter:    getRateLimiter(c.RateLimit),
+		SpecValidator:  specValidator,
+		Auditor:        audithandler.Auditor{AuditService: newAuditService(backend)},
+	}, nil
+}
+
+// getRouterHandlers wires the services to the backend's stores and returns every API route.
+func getRouterHandlers(apiPath string, backend storeBackend, readiness *healthcheckservice.Readiness, streamLog *streamservice.Log) []api.RouterHandler {
+	pageStore := backend.stores.PageStore
+	pageDetailStore := backend.stores.PageDetailStore
+	userStore := backend.stores.UserStore
+	healthcheckStore := backend.healthcheckStore
+	pageTemplateStore := backend.stores.PageTemplateStore
+	versionStore := backend.stores.VersionStore
+	webhookStore := backend.stores.WebhookStore
+	unitOfWork := backend.unitOfWork
+	webhookService := webhookservice.WebhookService{
+		WebhookStore: webhookStore,
+		Clock:        clock.RealClock{},
+	}
+	streamService := streamservice.StreamService{
+		Log:       streamLog,
+		PageStore: pageStore,
+		Clock:     clock.RealClock{},
+	}
 	pageService := pageservice.PageService{
 		PageStore:         pageStore,
 		PageTemplateStore: pageTemplateStore,
 		VersionStore:      versionStore,
 		UserStore:         userStore,
+		UnitOfWork:        unitOfWork,
 	}
 	pageDetailService := pagedetailservice.PageDetailService{
-		PageDetailStore: pageDetailStore,
+		UnitOfWork: unitOfWork,
 	}
 	healthcheckService := healthcheckservice.HealthcheckService{
 		HealthcheckStore: healthcheckStore,
+		Readiness:        readiness,
+	}
+	archiveService := archiveservice.ArchiveService{
+		PageStore:         pageStore,
+		PageDetailStore:   pageDetailStore,
+		PageTemplateStore: pageTemplateStore,
+		VersionStore:      versionStore,
+		UserStore:         userStore,
+		UnitOfWork:        unitOfWork,
+		Clock:             clock.RealClock{},
+	}
+	commentService := commentservice.CommentService{
+		CommentStore:    backend.stores.CommentStore,
+		PageStore:       pageStore,
+		PageDetailStore: pageDetailStore,
+		Clock:           clock.RealClock{},
 	}
 	var routerHandlers []api.RouterHandler
-	routerHandlers = append(routerHandlers, pagehandler.PageRouterHandlers(apiPath, pageService)...)
-	routerHandlers = append(routerHandlers, pagedetailhandler.PageDetailRouterHandlers(apiPath, pageDetailService)...)
-	routerHandlers = append(routerHandlers, healthcheckhandler.HealthcheckRouterHandlers(apiPath, healthcheckService)...)
-	router := api.NewRouter(apiPath, staticPath, routerHandlers)
-	authN, authZ, err := getAuths(apiPath, datacenter)
+	routerHandlers = append(routerHandlers, pagehandler.PageRouterHandlers(apiPath, pageservice.InstrumentedPageService{PageService: pageService})...)
+	routerHandlers = append(routerHandlers, pagedetailhandler.PageDetailRouterHandlers(apiPath, pagedetailservice.InstrumentedPageDetailService{PageDetailService: pageDetailService})...)
+	routerHandlers = append(routerHandlers, healthcheckhandler.HealthcheckRouterHandlers(apiPath, healthcheckservice.InstrumentedHealthcheckService{HealthcheckService: healthcheckService})...)
+	routerHandlers = append(routerHandlers, streamhandler.StreamRouterHandlers(apiPath, streamservice.InstrumentedStreamService{StreamService: streamService})...)
+	routerHandlers = append(routerHandlers, webhookhandler.WebhookRouterHandlers(apiPath, webhookservice.InstrumentedWebhookService{WebhookService: webhookService})...)
+	routerHandlers = append(routerHandlers, archivehandler.ArchiveRouterHandlers(apiPath, archiveservice.InstrumentedArchiveService{ArchiveService: archiveService})...)
+	routerHandlers = append(routerHandlers, audithandler.AuditRouterHandlers(apiPath, newAuditService(backend))...)
+	routerHandlers = append(routerHandlers, commenthandler.CommentRouterHandlers(apiPath, commentservice.InstrumentedCommentService{CommentService: commentService})...)
+	routerHandlers = append(routerHandlers, metricshandler.MetricsRouterHandlers(metrics.Default)...)
+	return routerHandlers
+}
+
+// newAuditService returns the service that both records the audit log and serves its feeds.
+func newAuditService(backend storeBackend) auditservice.InstrumentedAuditService {
+	return auditservice.InstrumentedAuditService{AuditService: auditservice.AuditService{
+		AuditStore: backend.stores.AuditStore,
+		PageStore:  backend.stores.PageStore,
+	}}
+}
+
+// getSpecValidator checks requests and responses against the spec generated from the router's routes,
+// the same one served at /api/openapi.json.
+func getSpecValidator(c config.HTTP, router api.Router) (*api.SpecValidator, error) {
+	if c.SpecValidation == config.SpecValidationOff {
+		return nil, nil
+	}
+	spec, err := openapi.New(router.OpenAPI)
 	if err != nil {
-		return handler, err
+		return nil, fmt.Errorf("failed to load the API spec: %v", err)
 	}
-	return &api.Handler{
-		AuthN:      authN,
-		AuthZ:      authZ,
-		Router:     router,
-		Datacenter: datacenter,
-		APIPath:    apiPath,
+	return &api.SpecValidator{
+		Spec:   spec,
+		Strict: c.SpecValidation == config.SpecValidationStrict,
 	}, nil
 }
 
-func getAuths(apiPath, datacenter string) (api.AuthN, api.AuthZ, error) {
-	adminAuthSecret, err := getAdminAuthSecret(datacenter)
+func getRateLimiter(c config.RateLimit) *api.RateLimiter {
+	rateLimiter := &api.RateLimiter{
+		Store:          &ratelimit.MemoryStore{Clock: clock.RealClock{}},
+		Default:        getRateLimits(c.Default),
+		ClientIPHeader: c.ClientIPHeader,
+		TrustedProxies: c.TrustedProxies,
+	}
+	for _, g := range c.Groups {
+		rateLimiter.Groups = append(rateLimiter.Groups, api.RateLimitGroup{
+			Name:       g.Name,
+			PathPrefix: g.PathPrefix,
+			Limits:     getRateLimits(g.Limits),
+		})
+	}
+	return rateLimiter
+}
+
+func getRateLimits(c config.RateLimits) api.RateLimits {
+	return api.RateLimits{
+		IP:        ratelimit.Limit{Requests: c.IP.Requests, Per: c.IP.Per.Duration},
+		Admin:     ratelimit.Limit{Requests: c.Admin.Requests, Per: c.Admin.Per.Duration},
+		ProxyUser: ratelimit.Limit{Requests: c.ProxyUser.Requests, Per: c.ProxyUser.Per.Duration},
+		Public:    ratelimit.Limit{Requests: c.Public.Requests, Per: c.Public.Per.Duration},
+	}
+}
+
+func startTrashRetentionJob(ctx context.Context, jobs *sync.WaitGroup, c config.TrashRetention, pageStore store.PageStore, appLogger *zap.Logger) {
+	if c.Days <= 0 {
+		return
+	}
+	job := retention.Job{
+		PageService: pageservice.InstrumentedPageService{PageService: pageservice.PageService{
+			PageStore: pageStore,
+		}},
+		RetentionDays: c.Days,
+		Interval:      getTrashRetentionInterval(),
+		Clock:         clock.RealClock{},
+		Logger:        appLogger,
+	}
+	jobs.Add(1)
+	go func() {
+		defer jobs.Done()
+		job.Run(ctx)
+	}()
+}
+
+// startWebhookDeliveryJob sends queued webhook deliveries in the background; it's off when the poll interval is 0.
+func startWebhookDeliveryJob(ctx context.Context, jobs *sync.WaitGroup, c config.Webhooks, webhookStore store.WebhookStore, appLogger *zap.Logger) {
+	if c.PollInterval.Duration <= 0 {
+		return
+	}
+	job := delivery.Job{
+		WebhookService: webhookservice.InstrumentedWebhookService{WebhookService: webhookservice.WebhookService{
+			WebhookStore: webhookStore,
+			Clock:        clock.RealClock{},
+			HTTPClient:   publicnet.NewClient(webhookservice.DeliveryTimeout),
+			MaxAttempts:  c.MaxAttempts,
+		}},
+		Interval: c.PollInterval.Duration,
+		Logger:   appLogger,
+	}
+	jobs.Add(1)
+	go func() {
+		defer jobs.Done()
+		job.Run(ctx)
+	}()
+}
+
+// subscribeToEvents hands the changes recorded in the outbox on to the webhooks and event streams.
+// Webhook deliveries are queued once whichever instance gets to each event first, while every instance adds every
+// event after lastOffset to its own stream log, so clients see every change whichever instance they're connected to.
+func subscribeToEvents(ctx context.Context, dispatcher *dispatch.Dispatcher, stores store.Stores, streamLog *streamservice.Log, lastOffset int64) error {
+	webhookService := webhookservice.InstrumentedWebhookService{WebhookService: webhookservice.WebhookService{
+		WebhookStore: stores.WebhookStore,
+		PageStore:    stores.PageStore,
+		Clock:        clock.RealClock{},
+	}}
+	streamService := streamservice.StreamService{
+		Log:   streamLog,
+		Clock: clock.RealClock{},
+	}
+	err := dispatcher.Subscribe(ctx, dispatch.Subscriber{
+		Name: "webhooks",
+		Handler: func(ctx context.Context, e event.Event) error {
+			return webhookService.Publish(ctx, webhookservice.PublishParams{Event: e})
+		},
+		Durable: true,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to subscribe the webhooks to events: %v", err)
+	}
+	err = dispatcher.Subscribe(ctx, dispatch.Subscriber{
+		Name: "stream",
+		Handler: func(ctx context.Context, e event.Event) error {
+			streamService.Publish(ctx, streamservice.PublishParams{Event: e})
+			return nil
+		},
+		From: lastOffset,
+	})
 	if err != nil {
-		return api.AuthN{}, api.AuthZ{}, err
+		return fmt.Errorf("failed to subscribe the event streams to events: %v", err)
 	}
+	return nil
+}
+
+// startEventDispatchJob hands the events in the outbox to their subscribers in the background.
+func startEventDispatchJob(ctx context.Context, jobs *sync.WaitGroup, dispatcher *dispatch.Dispatcher) {
+	jobs.Add(1)
+	go func() {
+		defer jobs.Done()
+		dispatcher.Run(ctx)
+	}()
+}
+
+func getAuths(apiPath, datacenter string, c config.Auth) (api.AuthN, api.AuthZ) {
 	authN := api.AuthN{
-		Datacenter:      datacenter,
-		AdminAuthSecret: adminAuthSecret,
+		Datacenter:              datacenter,
+		AdminAuthSecret:         c.AdminAuthSecret,
+		PreviousAdminAuthSecret: c.PreviousAdminAuthSecret,
 	}
 	authZ := api.AuthZ{
 		APIPath: apiPath,
 	}
-	return authN, authZ, nil
+	return authN, authZ
 }
 
-func getAdminAuthSecret(datacenter string) (string, error) {
-	if datacenter != api.LocalDatacenterEnv {
-		return env.Require("ADMIN_AUTH_SECRET")
+// setupCors lets the configured origins call the API from a browser; CORS is off when there are none.
+func setupCors(c config.CORS, handler http.Handler) (http.Handler, error) {
+	if len(c.AllowedOrigins) == 0 {
+		return handler, nil
 	}
-	return env.Get("ADMIN_AUTH_SECRET", defaultAdminAuthSecret), nil
+	cc := cors.New(cors.Options{
+		AllowedOrigins:   c.AllowedOrigins,
+		AllowedMethods:   []string{"GET", "POST", "DELETE", "PUT", "OPTIONS", "PATCH"},
+		AllowedHeaders:   []string{"X-AUTH-TOKEN", "Content-Type", "X-USER-ID", transaction.RequestIDHeaderKey, tracing.TraceParentHeaderKey},
+		ExposedHeaders:   []string{transaction.RequestIDHeaderKey, api.RetryAfterHeaderKey},
+		AllowCredentials: c.AllowCredentials,
+		MaxAge:           int(c.MaxAge.Seconds()),
+	})
+	return cc.Handler(handler), nil
 }
 
-func setupCors(datacenter string, handler http.Handler) (http.Handler, error) {
-	if datacenter != api.LocalDatacenterEnv {
-		return handler, nil
+const configUsage = "usage: server config print [--redacted]"
+
+// runConfig runs the config subcommand, which prints the loaded config as JSON.
+func runConfig(c config.Config, args []string) error {
+	if len(args) == 0 || args[0] != "print" || len(args) > 2 {
+		return fmt.Errorf(configUsage)
 	}
-	c := cors.New(cors.Options{
-		AllowedOrigins: []string{localUIURL},
-		AllowedMethods: []string{"GET", "POST", "DELETE", "PUT", "OPTIONS", "PATCH"},
-		AllowedHeaders: []string{"X-AUTH-TOKEN", "Content-Type", "X-USER-ID"},
-	})
-	return c.Handler(handler), nil
+	if len(args) == 2 {
+		if args[1] != "--redacted" {
+			return fmt.Errorf(configUsage)
+		}
+		c = c.Redacted()
+	}
+	b, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(b))
+	return nil
 }
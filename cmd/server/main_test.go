@@ -0,0 +1,22 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/worlve/sp-service/internal/api"
+	healthcheckservice "github.com/worlve/sp-service/internal/services/healthcheck"
+	streamservice "github.com/worlve/sp-service/internal/services/stream"
+)
+
+func TestRoutesAreDocumented(t *testing.T) {
+	routerHandlers := getRouterHandlers(getAPIPath(), setupMemoryBackend(), &healthcheckservice.Readiness{}, streamservice.NewLog(1, 0))
+	require.NotEmpty(t, routerHandlers)
+	require.Empty(t, api.UndocumentedRoutes(routerHandlers), "every route needs a Doc for the OpenAPI spec")
+	doc := api.NewOpenAPIDocument(routerHandlers)
+	operations := 0
+	for _, pathItem := range doc.Paths {
+		operations += len(pathItem)
+	}
+	require.Equal(t, len(routerHandlers), operations, "every route needs its own path and method in the OpenAPI spec")
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strconv"
+
+	"github.com/worlve/sp-service/pkg/spclient"
+)
+
+type exportResult struct {
+	File  string `json:"file"`
+	Bytes int    `json:"bytes"`
+}
+
+func runExport(ctx context.Context, a *app, args []string) error {
+	flags := newFlagSet("export")
+	userID := flags.String("user", "", "the user whose pages to export")
+	format := flags.String("format", string(spclient.ArchiveFormatZip), "archive format: zip or ndjson")
+	versionID := flags.String("version", "", "only export the pages in this version")
+	out := flags.String("out", "", "file to write the archive to; stdout when empty")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if err := requireFlag("user", *userID); err != nil {
+		return err
+	}
+	archive, err := a.client().AsUser(*userID).Export(ctx, spclient.ExportParams{Format: spclient.ArchiveFormat(*format), VersionID: *versionID})
+	if err != nil {
+		return err
+	}
+	if *out == "" {
+		_, err = a.out.Write(archive)
+		return err
+	}
+	err = ioutil.WriteFile(*out, archive, 0600)
+	if err != nil {
+		return err
+	}
+	result := exportResult{File: *out, Bytes: len(archive)}
+	return a.printer.print(result, []string{"FILE", "BYTES"}, [][]string{{result.File, strconv.Itoa(result.Bytes)}})
+}
+
+func runImport(ctx context.Context, a *app, args []string) error {
+	flags := newFlagSet("import")
+	userID := flags.String("user", "", "the user to import the pages for")
+	format := flags.String("format", string(spclient.ArchiveFormatZip), "archive format: zip or ndjson")
+	conflict := flags.String("conflict", string(spclient.ConflictRemap), "how to handle page ids that already exist: remap, skip, or fail")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if err := requireFlag("user", *userID); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return fmt.Errorf("takes a single FILE")
+	}
+	archive, err := ioutil.ReadFile(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	result, err := a.client().AsUser(*userID).Import(ctx, archive, spclient.ImportParams{
+		Format:   spclient.ArchiveFormat(*format),
+		Conflict: spclient.ConflictStrategy(*conflict),
+	})
+	if err != nil {
+		return err
+	}
+	var rows [][]string
+	for archiveID, pageID := range result.Pages {
+		rows = append(rows, []string{archiveID, pageID})
+	}
+	for _, archiveID := range result.Skipped {
+		rows = append(rows, []string{archiveID, "skipped"})
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i][0] < rows[j][0] })
+	return a.printer.print(result, []string{"ARCHIVE ID", "PAGE ID"}, rows)
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	eventservice "github.com/worlve/sp-service/internal/services/event"
+	"github.com/worlve/sp-service/internal/util/clock"
+)
+
+type subscriberResult struct {
+	Name   string `json:"name"`
+	Offset int64  `json:"offset"`
+	Behind int64  `json:"behind"`
+}
+
+func (a *app) eventService() (eventservice.EventService, error) {
+	stores, err := a.stores()
+	if err != nil {
+		return eventservice.EventService{}, err
+	}
+	return eventservice.EventService{
+		EventStore: stores.EventStore,
+		Clock:      clock.RealClock{},
+	}, nil
+}
+
+func runEventsSubscribers(ctx context.Context, a *app, args []string) error {
+	if err := newFlagSet("events subscribers").Parse(args); err != nil {
+		return err
+	}
+	eventService, err := a.eventService()
+	if err != nil {
+		return err
+	}
+	offsets, err := eventService.GetSubscriberOffsets(ctx)
+	if err != nil {
+		return err
+	}
+	lastOffset, err := eventService.GetLastOffset(ctx)
+	if err != nil {
+		return err
+	}
+	results := make([]subscriberResult, 0, len(offsets))
+	for name, offset := range offsets {
+		results = append(results, subscriberResult{Name: name, Offset: offset, Behind: lastOffset - offset})
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Name < results[j].Name })
+	rows := make([][]string, 0, len(results))
+	for _, r := range results {
+		rows = append(rows, []string{r.Name, strconv.FormatInt(r.Offset, 10), strconv.FormatInt(r.Behind, 10)})
+	}
+	return a.printer.print(results, []string{"SUBSCRIBER", "OFFSET", "BEHIND"}, rows)
+}
+
+func runEventsReplay(ctx context.Context, a *app, args []string) error {
+	flags := newFlagSet("events replay")
+	subscriber := flags.String("subscriber", "", "name of the subscriber to replay events to")
+	offset := flags.Int64("offset", -1, "replay every event after this offset")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if err := requireFlag("subscriber", *subscriber); err != nil {
+		return err
+	}
+	if *offset < 0 {
+		return fmt.Errorf("-offset is required")
+	}
+	eventService, err := a.eventService()
+	if err != nil {
+		return err
+	}
+	err = eventService.ReplaySubscriber(ctx, eventservice.ReplaySubscriberParams{
+		Subscriber: *subscriber,
+		Offset:     *offset,
+	})
+	if err != nil {
+		return err
+	}
+	result := subscriberResult{Name: *subscriber, Offset: *offset}
+	return a.printer.print(result, []string{"SUBSCRIBER", "OFFSET"}, [][]string{{result.Name, strconv.FormatInt(result.Offset, 10)}})
+}
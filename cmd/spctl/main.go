@@ -0,0 +1,201 @@
+// Command spctl runs admin tasks against sp-service: some through its API, and the rest, which the API has no
+// routes for, directly against its MySQL db. It's configured with the same config file and env vars as the server.
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/worlve/sp-service/internal/config"
+	"github.com/worlve/sp-service/internal/stores/mysqlstore"
+	"github.com/worlve/sp-service/internal/stores/mysqlstore/migrations"
+	"github.com/worlve/sp-service/internal/stores/store"
+	"github.com/worlve/sp-service/pkg/spclient"
+)
+
+// urlEnvKey is the env var with the URL of the service the API commands call.
+const urlEnvKey = "SP_URL"
+
+const usage = `usage: spctl [-o table|json] [-url URL] <command> [flags] [args]
+
+Commands that call the API (at -url, SP_URL, or the local server):
+  pages list -user ID [-trash]                   list a user's pages, or the pages in their trash
+  pages get -user ID PAGE_ID                     show a page with its properties and details
+  trash purge -user ID                           permanently delete every page in a user's trash
+  export -user ID [-format zip|ndjson] [-out FILE]
+                                                 export a user's pages to FILE, or stdout
+  import -user ID [-format zip|ndjson] [-conflict remap|skip|fail] FILE
+                                                 import an archive into a user's account
+
+Commands that use the MySQL db directly:
+  users create -email EMAIL [-id ID]             create a user
+  users get ID                                   show a user
+  pages transfer -to ID PAGE_ID...               make a user the owner of pages
+  trash purge -older-than DURATION               permanently delete every page removed before DURATION ago
+  schema status                                  list applied and pending migrations
+  events subscribers                             list the durable event subscribers and how far behind they are
+  events replay -subscriber NAME -offset N       send a subscriber every event after offset N again
+
+Commands that change local files:
+  secret rotate [-file FILE] [-previous-file FILE]
+                                                 replace the admin secret, keeping the old one as the previous secret
+  secret clear-previous [-previous-file FILE]    stop accepting the previous admin secret`
+
+// command is a subcommand, run with the args after its name.
+type command func(ctx context.Context, a *app, args []string) error
+
+var commands = map[string]command{
+	"pages list":            runPagesList,
+	"pages get":             runPagesGet,
+	"pages transfer":        runPagesTransfer,
+	"trash purge":           runTrashPurge,
+	"export":                runExport,
+	"import":                runImport,
+	"users create":          runUsersCreate,
+	"users get":             runUsersGet,
+	"schema status":         runSchemaStatus,
+	"events subscribers":    runEventsSubscribers,
+	"events replay":         runEventsReplay,
+	"secret rotate":         runSecretRotate,
+	"secret clear-previous": runSecretClearPrevious,
+}
+
+func main() {
+	err := run(context.Background(), os.Args[1:], os.Stdout)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("spctl", flag.ContinueOnError)
+	flags.Usage = func() { fmt.Fprintln(flags.Output(), usage) }
+	output := flags.String("o", outputTable, "output format: table or json")
+	baseURL := flags.String("url", os.Getenv(urlEnvKey), "URL of the service the API commands call")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *output != outputTable && *output != outputJSON {
+		return fmt.Errorf("-o must be %v or %v", outputTable, outputJSON)
+	}
+	name, cmd, cmdArgs := findCommand(flags.Args())
+	if cmd == nil {
+		return fmt.Errorf(usage)
+	}
+	c, err := config.Load(os.Getenv(config.FileEnvKey))
+	if err != nil {
+		return err
+	}
+	a := &app{
+		config:  c,
+		baseURL: *baseURL,
+		printer: printer{format: *output, w: out},
+		out:     out,
+	}
+	defer a.close()
+	err = cmd(ctx, a, cmdArgs)
+	if err != nil {
+		return fmt.Errorf("%v: %v", name, err)
+	}
+	return nil
+}
+
+// findCommand returns the command named by the first one or two args, and the args after its name.
+func findCommand(args []string) (string, command, []string) {
+	for n := 2; n >= 1; n-- {
+		if len(args) < n {
+			continue
+		}
+		name := strings.Join(args[:n], " ")
+		if cmd, ok := commands[name]; ok {
+			return name, cmd, args[n:]
+		}
+	}
+	return "", nil, nil
+}
+
+// app is what the commands share. The API client and the db are set up the first time a command needs them.
+type app struct {
+	config  config.Config
+	baseURL string
+	printer printer
+	out     io.Writer
+	mysqldb *sql.DB
+}
+
+// client returns an API client that authenticates with the configured admin secret.
+func (a *app) client() spclient.Client {
+	baseURL := a.baseURL
+	if baseURL == "" {
+		baseURL = "http://localhost:" + a.config.HTTP.Port
+	}
+	return spclient.Client{
+		BaseURL:         baseURL,
+		AdminAuthSecret: a.config.Auth.AdminAuthSecret,
+	}
+}
+
+// db connects to the MySQL db. Unless it's only checking the schema, the schema must be at the version
+// this build's migrations expect, just as the server requires.
+func (a *app) db(checkSchema bool) (*sql.DB, error) {
+	if a.config.Store.Backend != config.StoreBackendMySQL {
+		return nil, fmt.Errorf("needs STORE_BACKEND=%v; the %v backend can only be reached through the API", config.StoreBackendMySQL, a.config.Store.Backend)
+	}
+	if a.mysqldb == nil {
+		mysqldb, err := mysqlstore.SetupMySQL(a.config.MySQL, "")
+		if err != nil {
+			return nil, err
+		}
+		a.mysqldb = mysqldb
+	}
+	if checkSchema {
+		migrator, err := migrations.NewMigrator(a.mysqldb)
+		if err != nil {
+			return nil, err
+		}
+		err = migrator.CheckCompatible()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return a.mysqldb, nil
+}
+
+func (a *app) stores() (store.Stores, error) {
+	mysqldb, err := a.db(true)
+	if err != nil {
+		return store.Stores{}, err
+	}
+	return store.Stores{
+		PageStore:  mysqlstore.NewPageStore(mysqldb),
+		UserStore:  mysqlstore.NewUserStore(mysqldb),
+		EventStore: mysqlstore.NewEventStore(mysqldb),
+	}, nil
+}
+
+func (a *app) close() {
+	if a.mysqldb != nil {
+		a.mysqldb.Close()
+	}
+}
+
+// newFlagSet returns a flag set for the command that reports errors instead of exiting.
+func newFlagSet(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(os.Stderr)
+	return flags
+}
+
+// requireFlag returns an error when a required flag is empty.
+func requireFlag(name, value string) error {
+	if value == "" {
+		return fmt.Errorf("-%v is required", name)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindCommand(t *testing.T) {
+	cases := []struct {
+		name       string
+		paramArgs  []string
+		returnName string
+		returnArgs []string
+	}{
+		{
+			name:       "test command with a subcommand",
+			paramArgs:  []string{"pages", "list", "-user", "UR_1"},
+			returnName: "pages list",
+			returnArgs: []string{"-user", "UR_1"},
+		},
+		{
+			name:       "test command without a subcommand",
+			paramArgs:  []string{"export", "-user", "UR_1"},
+			returnName: "export",
+			returnArgs: []string{"-user", "UR_1"},
+		},
+		{
+			name:      "test unknown subcommand",
+			paramArgs: []string{"pages", "delete"},
+		},
+		{
+			name: "test no command",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			name, cmd, args := findCommand(tc.paramArgs)
+			require.Equal(t, tc.returnName, name)
+			require.Equal(t, tc.returnName != "", cmd != nil)
+			require.Equal(t, tc.returnArgs, args)
+		})
+	}
+}
+
+func TestPrint(t *testing.T) {
+	cases := []struct {
+		name        string
+		paramFormat string
+		returnOut   string
+	}{
+		{
+			name:        "test table",
+			paramFormat: outputTable,
+			returnOut:   "ID    EMAIL\nUR_1  owner@test.com\n",
+		},
+		{
+			name:        "test json",
+			paramFormat: outputJSON,
+			returnOut:   "{\n  \"id\": \"UR_1\",\n  \"email\": \"owner@test.com\"\n}\n",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var out bytes.Buffer
+			p := printer{format: tc.paramFormat, w: &out}
+			v := struct {
+				ID    string `json:"id"`
+				Email string `json:"email"`
+			}{ID: "UR_1", Email: "owner@test.com"}
+			err := p.print(v, []string{"ID", "EMAIL"}, [][]string{{"UR_1", "owner@test.com"}})
+			require.NoError(t, err)
+			require.Equal(t, tc.returnOut, out.String())
+		})
+	}
+}
+
+func TestRotateSecret(t *testing.T) {
+	dir, err := ioutil.TempDir("", "spctl")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	secretFile := filepath.Join(dir, "admin_auth_secret")
+	previousSecretFile := filepath.Join(dir, "admin_auth_previous_secret")
+	require.NoError(t, ioutil.WriteFile(secretFile, []byte("OLD_SECRET\n"), 0600))
+	var out bytes.Buffer
+	a := &app{printer: printer{format: outputJSON, w: &out}, out: &out}
+	ctx := context.Background()
+
+	err = runSecretRotate(ctx, a, []string{"-file", secretFile, "-previous-file", previousSecretFile})
+	require.NoError(t, err)
+	previous, err := ioutil.ReadFile(previousSecretFile)
+	require.NoError(t, err)
+	require.Equal(t, "OLD_SECRET\n", string(previous))
+	secret, err := ioutil.ReadFile(secretFile)
+	require.NoError(t, err)
+	require.NotEqual(t, "OLD_SECRET\n", string(secret))
+	require.Len(t, secret, 44, "a 32 byte secret, base64 encoded, and a newline")
+	info, err := os.Stat(secretFile)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	err = runSecretClearPrevious(ctx, a, []string{"-previous-file", previousSecretFile})
+	require.NoError(t, err)
+	previous, err = ioutil.ReadFile(previousSecretFile)
+	require.NoError(t, err)
+	require.Empty(t, previous)
+
+	err = runSecretRotate(ctx, a, []string{"-file", previousSecretFile, "-previous-file", secretFile})
+	require.Error(t, err, "an empty secret can't be rotated")
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 2, "no temp files are left behind")
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Supported values for the -o flag
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+// printer prints a command's result as a table, or as JSON for scripts.
+type printer struct {
+	format string
+	w      io.Writer
+}
+
+// print prints v as indented JSON, or the header and rows as a table.
+func (p printer) print(v interface{}, header []string, rows [][]string) error {
+	if p.format == outputJSON {
+		b, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(p.w, string(b))
+		return err
+	}
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// printFields prints v as indented JSON, or each field name and value as a row of a table.
+func (p printer) printFields(v interface{}, fields [][2]string) error {
+	rows := make([][]string, 0, len(fields))
+	for _, field := range fields {
+		rows = append(rows, []string{field[0], field[1]})
+	}
+	return p.print(v, []string{"FIELD", "VALUE"}, rows)
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/worlve/sp-service/pkg/spclient"
+)
+
+func runPagesList(ctx context.Context, a *app, args []string) error {
+	flags := newFlagSet("pages list")
+	userID := flags.String("user", "", "the user whose pages to list")
+	trash := flags.Bool("trash", false, "list the pages in the user's trash instead")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if err := requireFlag("user", *userID); err != nil {
+		return err
+	}
+	client := a.client().AsUser(*userID)
+	pages := client.Pages()
+	if *trash {
+		pages = client.RemovedPages()
+	}
+	list := []spclient.ReducedPage{}
+	var rows [][]string
+	for pages.Next(ctx) {
+		p := pages.Page()
+		list = append(list, p)
+		row := []string{p.GUID, p.Title, string(p.Permission), formatTime(p.UpdatedAt)}
+		if *trash {
+			row = append(row, formatTime(p.DeletedAt))
+		}
+		rows = append(rows, row)
+	}
+	if err := pages.Err(); err != nil {
+		return err
+	}
+	header := []string{"ID", "TITLE", "PERMISSION", "UPDATED"}
+	if *trash {
+		header = append(header, "REMOVED")
+	}
+	return a.printer.print(list, header, rows)
+}
+
+func runPagesGet(ctx context.Context, a *app, args []string) error {
+	flags := newFlagSet("pages get")
+	userID := flags.String("user", "", "a user who can read the page")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if err := requireFlag("user", *userID); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return fmt.Errorf("takes a single PAGE_ID")
+	}
+	p, err := a.client().AsUser(*userID).GetEntirePage(ctx, flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	fields := [][2]string{
+		{"ID", p.GUID},
+		{"TITLE", p.Title},
+		{"SUMMARY", p.Summary},
+		{"PERMISSION", string(p.Permission)},
+		{"VERSION", p.Version.GUID},
+		{"TEMPLATE", p.PageTemplate.GUID},
+		{"CREATED", formatTime(p.CreatedAt)},
+		{"UPDATED", formatTime(p.UpdatedAt)},
+		{"DETAILS", strconv.Itoa(len(p.Details))},
+	}
+	for _, property := range p.Properties {
+		fields = append(fields, [2]string{"PROPERTY " + property.Key, fmt.Sprint(property.Value)})
+	}
+	return a.printer.printFields(p, fields)
+}
+
+type transferResult struct {
+	PageID string `json:"pageId"`
+	Owner  string `json:"owner"`
+}
+
+func runPagesTransfer(ctx context.Context, a *app, args []string) error {
+	flags := newFlagSet("pages transfer")
+	userID := flags.String("to", "", "the user to make the owner")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if err := requireFlag("to", *userID); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		return fmt.Errorf("takes at least one PAGE_ID")
+	}
+	stores, err := a.stores()
+	if err != nil {
+		return err
+	}
+	results := []transferResult{}
+	var rows [][]string
+	for _, pageID := range flags.Args() {
+		err := stores.PageStore.TransferPage(ctx, pageID, *userID)
+		if err != nil {
+			return fmt.Errorf("unable to transfer %v: %v", pageID, err)
+		}
+		results = append(results, transferResult{PageID: pageID, Owner: *userID})
+		rows = append(rows, []string{pageID, *userID})
+	}
+	return a.printer.print(results, []string{"PAGE", "OWNER"}, rows)
+}
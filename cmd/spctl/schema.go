@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/worlve/sp-service/internal/stores/mysqlstore/migrations"
+)
+
+type schemaStatus struct {
+	CurrentVersion int               `json:"currentVersion"`
+	LatestVersion  int               `json:"latestVersion"`
+	Migrations     []migrationStatus `json:"migrations"`
+}
+
+type migrationStatus struct {
+	Version   int        `json:"version"`
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"appliedAt,omitempty"`
+}
+
+func runSchemaStatus(ctx context.Context, a *app, args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("takes no args")
+	}
+	mysqldb, err := a.db(false)
+	if err != nil {
+		return err
+	}
+	migrator, err := migrations.NewMigrator(mysqldb)
+	if err != nil {
+		return err
+	}
+	status, err := migrator.Status()
+	if err != nil {
+		return err
+	}
+	result := schemaStatus{
+		CurrentVersion: status.CurrentVersion,
+		LatestVersion:  status.LatestVersion,
+		Migrations:     []migrationStatus{},
+	}
+	var rows [][]string
+	for _, applied := range status.Applied {
+		appliedAt := applied.AppliedAt
+		result.Migrations = append(result.Migrations, migrationStatus{
+			Version:   applied.Version,
+			Name:      applied.Name,
+			Applied:   true,
+			AppliedAt: &appliedAt,
+		})
+		rows = append(rows, []string{strconv.Itoa(applied.Version), applied.Name, "applied", formatTime(&appliedAt)})
+	}
+	for _, pending := range status.Pending {
+		result.Migrations = append(result.Migrations, migrationStatus{
+			Version: pending.Version,
+			Name:    pending.Name,
+		})
+		rows = append(rows, []string{strconv.Itoa(pending.Version), pending.Name, "pending", ""})
+	}
+	return a.printer.print(result, []string{"VERSION", "NAME", "STATUS", "APPLIED AT"}, rows)
+}
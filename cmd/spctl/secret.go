@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// The env vars the server reads the admin secrets' files from.
+const (
+	secretFileEnvKey         = "ADMIN_AUTH_SECRET_FILE"
+	previousSecretFileEnvKey = "ADMIN_AUTH_PREVIOUS_SECRET_FILE"
+)
+
+const secretBytes = 32
+
+type rotateResult struct {
+	SecretFile         string `json:"secretFile"`
+	PreviousSecretFile string `json:"previousSecretFile"`
+}
+
+// runSecretRotate moves the admin secret to the previous secret's file and writes a new one in its place.
+// Once the servers are restarted they accept both, so clients can move to the new secret before the previous
+// one is cleared.
+func runSecretRotate(ctx context.Context, a *app, args []string) error {
+	flags := newFlagSet("secret rotate")
+	secretFile := flags.String("file", os.Getenv(secretFileEnvKey), "the file the server reads ADMIN_AUTH_SECRET from")
+	previousSecretFile := flags.String("previous-file", os.Getenv(previousSecretFileEnvKey), "the file the server reads ADMIN_AUTH_PREVIOUS_SECRET from")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if err := requireFlag("file", *secretFile); err != nil {
+		return err
+	}
+	if err := requireFlag("previous-file", *previousSecretFile); err != nil {
+		return err
+	}
+	current, err := ioutil.ReadFile(*secretFile)
+	if err != nil {
+		return err
+	}
+	if strings.TrimRight(string(current), "\r\n") == "" {
+		return fmt.Errorf("%v is empty", *secretFile)
+	}
+	secret, err := generateSecret()
+	if err != nil {
+		return err
+	}
+	err = writeSecretFile(*previousSecretFile, string(current))
+	if err != nil {
+		return err
+	}
+	err = writeSecretFile(*secretFile, secret+"\n")
+	if err != nil {
+		return err
+	}
+	result := rotateResult{SecretFile: *secretFile, PreviousSecretFile: *previousSecretFile}
+	err = a.printer.print(result, []string{"SECRET FILE", "PREVIOUS SECRET FILE"}, [][]string{{result.SecretFile, result.PreviousSecretFile}})
+	if err != nil {
+		return err
+	}
+	if a.printer.format == outputTable {
+		fmt.Fprintf(a.out, "\nRestart the servers so they accept both secrets, move clients to the new secret, then run: spctl secret clear-previous\n")
+	}
+	return nil
+}
+
+// runSecretClearPrevious empties the previous secret's file, so the servers stop accepting it once they're restarted.
+func runSecretClearPrevious(ctx context.Context, a *app, args []string) error {
+	flags := newFlagSet("secret clear-previous")
+	previousSecretFile := flags.String("previous-file", os.Getenv(previousSecretFileEnvKey), "the file the server reads ADMIN_AUTH_PREVIOUS_SECRET from")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if err := requireFlag("previous-file", *previousSecretFile); err != nil {
+		return err
+	}
+	err := writeSecretFile(*previousSecretFile, "")
+	if err != nil {
+		return err
+	}
+	result := rotateResult{PreviousSecretFile: *previousSecretFile}
+	return a.printer.print(result, []string{"CLEARED"}, [][]string{{result.PreviousSecretFile}})
+}
+
+func generateSecret() (string, error) {
+	b := make([]byte, secretBytes)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// writeSecretFile replaces the file by renaming a temp file over it, so the server never reads a partial secret.
+func writeSecretFile(path, contents string) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.WriteString(contents)
+	if err == nil {
+		err = tmp.Chmod(0600)
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+type purgeResult struct {
+	Purged  int      `json:"purged"`
+	PageIDs []string `json:"pageIds,omitempty"`
+}
+
+func runTrashPurge(ctx context.Context, a *app, args []string) error {
+	flags := newFlagSet("trash purge")
+	userID := flags.String("user", "", "purge this user's trash through the API")
+	olderThan := flags.Duration("older-than", 0, "purge every user's pages removed at least this long ago, such as 720h, directly in the db")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if (*userID == "") == (*olderThan == 0) {
+		return fmt.Errorf("takes either -user or -older-than")
+	}
+	if *olderThan < 0 {
+		return fmt.Errorf("-older-than can't be negative")
+	}
+	if *userID != "" {
+		return purgeUserTrash(ctx, a, *userID)
+	}
+	stores, err := a.stores()
+	if err != nil {
+		return err
+	}
+	purged, err := stores.PageStore.PurgeRemovedPages(ctx, time.Now().Add(-*olderThan))
+	if err != nil {
+		return err
+	}
+	return a.printer.print(purgeResult{Purged: purged}, []string{"PURGED"}, [][]string{{strconv.Itoa(purged)}})
+}
+
+// purgeUserTrash lists the whole trash before purging any of it, since purging a page moves the batches after it.
+func purgeUserTrash(ctx context.Context, a *app, userID string) error {
+	client := a.client().AsUser(userID)
+	var pageIDs []string
+	pages := client.RemovedPages()
+	for pages.Next(ctx) {
+		pageIDs = append(pageIDs, pages.Page().GUID)
+	}
+	if err := pages.Err(); err != nil {
+		return err
+	}
+	result := purgeResult{PageIDs: []string{}}
+	var rows [][]string
+	for _, pageID := range pageIDs {
+		err := client.PurgePage(ctx, pageID)
+		if err != nil {
+			return fmt.Errorf("purged %v pages before failing to purge %v: %v", result.Purged, pageID, err)
+		}
+		result.Purged++
+		result.PageIDs = append(result.PageIDs, pageID)
+		rows = append(rows, []string{pageID})
+	}
+	return a.printer.print(result, []string{"PURGED"}, rows)
+}
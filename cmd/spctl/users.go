@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/worlve/sp-service/internal/models/appuser"
+)
+
+func runUsersCreate(ctx context.Context, a *app, args []string) error {
+	flags := newFlagSet("users create")
+	email := flags.String("email", "", "the user's email")
+	proposedID := flags.String("id", "", "the user's id, such as UR_123456789012; one is generated when empty")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if err := requireFlag("email", *email); err != nil {
+		return err
+	}
+	stores, err := a.stores()
+	if err != nil {
+		return err
+	}
+	guid, err := stores.UserStore.GetUniqueUserGUID(ctx, *proposedID)
+	if err != nil {
+		return err
+	}
+	u, err := stores.UserStore.CreateUser(ctx, appuser.User{GUID: guid, Email: *email})
+	if err != nil {
+		return err
+	}
+	return printUser(a, u)
+}
+
+func runUsersGet(ctx context.Context, a *app, args []string) error {
+	flags := newFlagSet("users get")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return fmt.Errorf("takes a single user ID")
+	}
+	stores, err := a.stores()
+	if err != nil {
+		return err
+	}
+	u, err := stores.UserStore.GetUser(ctx, flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	return printUser(a, u)
+}
+
+func printUser(a *app, u appuser.User) error {
+	return a.printer.print(u, []string{"ID", "EMAIL"}, [][]string{{u.GUID, u.Email}})
+}
@@ -1,11 +1,14 @@
 package api
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
+	"go.uber.org/zap"
 )
 
 // LocalDatacenterEnv should be set on DATACENTER when running locally.
@@ -18,6 +21,17 @@ type Handler struct {
 	Router     Router
 	Datacenter string
 	APIPath    string
+	// Logger is scoped to each request and put on its context; nothing is logged when it's nil.
+	Logger *zap.Logger
+	// RequestTimeout is the deadline on each request's context, after which its store calls are canceled; there's no deadline when it's 0.
+	// Event streams don't have the deadline.
+	RequestTimeout time.Duration
+	// RateLimiter rejects requests from clients over their limit; nothing is limited when it's nil.
+	RateLimiter *RateLimiter
+	// SpecValidator checks requests and responses against the API spec; nothing is checked when it's nil.
+	SpecValidator *SpecValidator
+	// Auditor records every authenticated POST, PUT, PATCH and DELETE request; nothing is audited when it's nil.
+	Auditor Auditor
 }
 
 // Authenticator inteface for authenticating.
@@ -31,28 +45,52 @@ type Authorizer interface {
 }
 
 // ServeHTTP handles responding to HTTP requests.
-func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-	r = r.WithContext(ctx)
-	if h.requiresNoAuth(w, r) {
-		h.Router.ServeHTTP(w, r)
+func (h *Handler) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.startRequest(rw, r)
+	defer finish()
+	if h.RequestTimeout > 0 && !isEventStream(r) {
+		ctx, cancel := context.WithTimeout(r.Context(), h.RequestTimeout)
+		defer cancel()
+		r = r.WithContext(ctx)
+	}
+	if h.limitIP(w, r) {
+		return
+	}
+	if h.requiresNoAuth(r) {
+		if h.limitRate(w, r, nil) {
+			return
+		}
+		h.route(w, r)
 		return
 	}
 	r, authData, responded := h.authenticate(w, r)
 	if responded {
 		return
 	}
-	r = r.WithContext(ctx)
+	r = setUserOnRequestLog(r, authData.UserID)
 	r, authData, responded = h.authorize(w, r, authData)
 	if responded {
 		return
 	}
-	ctx = SetDataOnContext(ctx, authData)
-	r = r.WithContext(ctx)
-	h.Router.ServeHTTP(w, r)
+	if h.limitRate(w, r, &authData) {
+		return
+	}
+	ctx := SetDataOnContext(r.Context(), authData)
+	r, finishAudit := h.startAudit(w, r.WithContext(ctx), authData)
+	defer finishAudit()
+	h.route(w, r)
+}
+
+// route hands the request to the router, checking it against the API spec when there's a SpecValidator.
+func (h *Handler) route(w http.ResponseWriter, r *http.Request) {
+	if h.SpecValidator == nil {
+		h.Router.ServeHTTP(w, r)
+		return
+	}
+	h.SpecValidator.serve(h.Router, w, r)
 }
 
-func (h *Handler) requiresNoAuth(w http.ResponseWriter, r *http.Request) bool {
+func (h *Handler) requiresNoAuth(r *http.Request) bool {
 	if strings.HasPrefix(r.URL.Path, fmt.Sprintf("/%v/docs", h.APIPath)) {
 		return true
 	}
@@ -61,7 +99,6 @@ func (h *Handler) requiresNoAuth(w http.ResponseWriter, r *http.Request) bool {
 	}
 	for _, nonAuthRoute := range h.Router.NonAuthRoutes {
 		if r.URL.Path == nonAuthRoute.Path && r.Method == nonAuthRoute.Method {
-			h.Router.ServeHTTP(w, r)
 			return true
 		}
 	}
@@ -70,12 +107,8 @@ func (h *Handler) requiresNoAuth(w http.ResponseWriter, r *http.Request) bool {
 
 func (h *Handler) authenticate(w http.ResponseWriter, r *http.Request) (*http.Request, AuthData, bool) {
 	authData, err := h.AuthN.Authenticate(r)
-	if castErr, ok := err.(*FailedAuthentication); ok {
-		RespondWith(r, w, http.StatusUnauthorized, castErr, err)
-		return r, authData, true
-	}
 	if err != nil {
-		RespondWith(r, w, http.StatusInternalServerError, &InternalErr{}, errors.Wrap(err, "failed to determine authn"))
+		RespondWithError(r, w, errors.Wrap(err, "failed to determine authn"))
 		return r, authData, true
 	}
 	return r, authData, false
@@ -83,12 +116,8 @@ func (h *Handler) authenticate(w http.ResponseWriter, r *http.Request) (*http.Re
 
 func (h *Handler) authorize(w http.ResponseWriter, r *http.Request, authData AuthData) (*http.Request, AuthData, bool) {
 	authData, err := h.AuthZ.Authorize(r.URL.Path, authData)
-	if castErr, ok := err.(*FailedAuthorization); ok {
-		RespondWith(r, w, http.StatusForbidden, castErr, err)
-		return r, authData, true
-	}
 	if err != nil {
-		RespondWith(r, w, http.StatusInternalServerError, &InternalErr{}, errors.Wrap(err, "failed to determine authz"))
+		RespondWithError(r, w, errors.Wrap(err, "failed to determine authz"))
 		return r, authData, true
 	}
 	return r, authData, false
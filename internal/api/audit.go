@@ -0,0 +1,72 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/worlve/sp-service/internal/util/auditlog"
+	"github.com/worlve/sp-service/internal/util/logger"
+	"github.com/worlve/sp-service/internal/util/transaction"
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+)
+
+// AuditedRequest is a mutating request made to the API, as given to the Auditor once it's handled.
+type AuditedRequest struct {
+	AuthData AuthData
+	Method   string
+	Route    string
+	// Params are the route's parameters, such as the pageID of /api/pages/:pageID.
+	Params    httprouter.Params
+	Status    int
+	RequestID string
+	// Changes are what the services recorded the request changing.
+	Changes []auditlog.Change
+}
+
+// Auditor inteface for recording mutating requests in the audit log.
+type Auditor interface {
+	Audit(ctx context.Context, request AuditedRequest) error
+}
+
+// startAudit puts a recorder for the request's changes on its context when it's a mutating request.
+// It returns a func that gives the request to the Auditor once it's handled.
+// A failure to audit is logged rather than failing the request, since its changes were already made.
+func (h *Handler) startAudit(w *statusRecorder, r *http.Request, authData AuthData) (*http.Request, func()) {
+	if h.Auditor == nil || !isMutating(r.Method) {
+		return r, func() {}
+	}
+	ctx, recorder := auditlog.WithRecorder(r.Context())
+	return r.WithContext(ctx), func() {
+		entry, ok := getAccessLogFromContext(ctx)
+		if !ok || entry.route == "" {
+			// nothing was changed by a request that didn't match a route
+			return
+		}
+		status := w.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		t, _ := transaction.GetFromContext(ctx)
+		err := h.Auditor.Audit(context.WithoutCancel(ctx), AuditedRequest{
+			AuthData:  authData,
+			Method:    r.Method,
+			Route:     entry.route,
+			Params:    entry.params,
+			Status:    status,
+			RequestID: t.RequestID,
+			Changes:   recorder.Changes(),
+		})
+		if err != nil {
+			logger.GetFromContext(ctx).Error("Failed to audit request", zap.Error(err))
+		}
+	}
+}
+
+func isMutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,122 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/worlve/sp-service/internal/util/auditlog"
+	"github.com/worlve/sp-service/internal/util/transaction"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/require"
+)
+
+type testAuditor struct {
+	requests []AuditedRequest
+}
+
+func (a *testAuditor) Audit(ctx context.Context, request AuditedRequest) error {
+	a.requests = append(a.requests, request)
+	return nil
+}
+
+func TestAudit(t *testing.T) {
+	cases := []struct {
+		name          string
+		paramMethod   string
+		paramPath     string
+		paramHeaders  map[string]string
+		returnAudited []AuditedRequest
+	}{
+		{
+			name:        "test mutating request is audited with its changes",
+			paramMethod: http.MethodPut,
+			paramPath:   "/api/test/widget/WG_1",
+			paramHeaders: map[string]string{
+				transaction.RequestIDHeaderKey: "REQUEST_1",
+				UserIDHeaderKey:                "UR_1",
+			},
+			returnAudited: []AuditedRequest{
+				{
+					AuthData:  AuthData{Type: AuthTypeProxyUser, UserID: "UR_1"},
+					Method:    http.MethodPut,
+					Route:     "/api/test/widget/:widgetID",
+					Params:    httprouter.Params{{Key: "widgetID", Value: "WG_1"}},
+					Status:    http.StatusOK,
+					RequestID: "REQUEST_1",
+					Changes:   []auditlog.Change{{TargetID: "WG_1", After: "changed"}},
+				},
+			},
+		},
+		{
+			name:        "test failed request is audited as an admin",
+			paramMethod: http.MethodDelete,
+			paramPath:   "/api/test/widget/WG_1",
+			paramHeaders: map[string]string{
+				transaction.RequestIDHeaderKey: "REQUEST_2",
+			},
+			returnAudited: []AuditedRequest{
+				{
+					AuthData:  AuthData{Type: AuthTypeAdmin},
+					Method:    http.MethodDelete,
+					Route:     "/api/test/widget/:widgetID",
+					Params:    httprouter.Params{{Key: "widgetID", Value: "WG_1"}},
+					Status:    http.StatusInternalServerError,
+					RequestID: "REQUEST_2",
+				},
+			},
+		},
+		{
+			name:        "test read isn't audited",
+			paramMethod: http.MethodGet,
+			paramPath:   "/api/test/widget/WG_1",
+		},
+		{
+			name:        "test unknown route isn't audited",
+			paramMethod: http.MethodPost,
+			paramPath:   "/api/test/unknown",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			auditor := &testAuditor{}
+			handler := Handler{
+				AuthN:   AuthN{Datacenter: LocalDatacenterEnv},
+				AuthZ:   AuthZ{APIPath: "api/test"},
+				APIPath: "api/test",
+				Router: NewRouter("api/test", "static/test", []RouterHandler{
+					{
+						Method:   http.MethodGet,
+						Endpoint: "/api/test/widget/:widgetID",
+						Handle: func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+							RespondWith(r, w, http.StatusOK, p.ByName("widgetID"), nil)
+						},
+					},
+					{
+						Method:   http.MethodPut,
+						Endpoint: "/api/test/widget/:widgetID",
+						Handle: func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+							auditlog.Record(r.Context(), auditlog.Change{TargetID: p.ByName("widgetID"), After: "changed"})
+							RespondWith(r, w, http.StatusOK, p.ByName("widgetID"), nil)
+						},
+					},
+					{
+						Method:   http.MethodDelete,
+						Endpoint: "/api/test/widget/:widgetID",
+						Handle: func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+							RespondWith(r, w, http.StatusInternalServerError, &InternalErr{}, nil)
+						},
+					},
+				}),
+				Auditor: auditor,
+			}
+			r := httptest.NewRequest(tc.paramMethod, "http://test.com"+tc.paramPath, nil)
+			for key, value := range tc.paramHeaders {
+				r.Header.Set(key, value)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), r)
+			require.Equal(t, tc.returnAudited, auditor.requests)
+		})
+	}
+}
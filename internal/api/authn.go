@@ -8,6 +8,8 @@ import (
 type AuthN struct {
 	Datacenter      string
 	AdminAuthSecret string
+	// PreviousAdminAuthSecret is also accepted when set, so clients can move to a rotated secret without downtime.
+	PreviousAdminAuthSecret string
 }
 
 // Different header key names
@@ -42,7 +44,11 @@ func (a AuthN) Authenticate(r *http.Request) (AuthData, error) {
 }
 
 func (a AuthN) isAdmin(r *http.Request) bool {
-	return r.Header.Get(AdminAuthSecretHeaderKey) == a.AdminAuthSecret || a.Datacenter == LocalDatacenterEnv
+	secret := r.Header.Get(AdminAuthSecretHeaderKey)
+	if a.PreviousAdminAuthSecret != "" && secret == a.PreviousAdminAuthSecret {
+		return true
+	}
+	return secret == a.AdminAuthSecret || a.Datacenter == LocalDatacenterEnv
 }
 
 func (a AuthN) hasUserID(r *http.Request) bool {
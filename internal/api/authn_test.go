@@ -0,0 +1,69 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthenticate(t *testing.T) {
+	cases := []struct {
+		name          string
+		paramSecret   string
+		paramUserID   string
+		returnData    AuthData
+		returnIsError bool
+	}{
+		{
+			name:        "test admin",
+			paramSecret: "SECRET",
+			returnData:  AuthData{Type: AuthTypeAdmin},
+		},
+		{
+			name:        "test proxy user",
+			paramSecret: "SECRET",
+			paramUserID: "UR_1",
+			returnData:  AuthData{Type: AuthTypeProxyUser, UserID: "UR_1"},
+		},
+		{
+			name:        "test previous secret during a rotation",
+			paramSecret: "PREVIOUS_SECRET",
+			returnData:  AuthData{Type: AuthTypeAdmin},
+		},
+		{
+			name:          "test wrong secret",
+			paramSecret:   "WRONG_SECRET",
+			paramUserID:   "UR_1",
+			returnIsError: true,
+		},
+		{
+			name:          "test missing secret",
+			returnIsError: true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			authN := AuthN{
+				Datacenter:              "PROD",
+				AdminAuthSecret:         "SECRET",
+				PreviousAdminAuthSecret: "PREVIOUS_SECRET",
+			}
+			r := httptest.NewRequest(http.MethodGet, "http://test.com/api/pages", nil)
+			if tc.paramSecret != "" {
+				r.Header.Set(AdminAuthSecretHeaderKey, tc.paramSecret)
+			}
+			if tc.paramUserID != "" {
+				r.Header.Set(UserIDHeaderKey, tc.paramUserID)
+			}
+			authData, err := authN.Authenticate(r)
+			if tc.returnIsError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.returnData, authData)
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/worlve/sp-service/internal/stores/storeerror"
+	"github.com/pkg/errors"
+)
+
+// StatusClientClosedRequest is the status recorded when the client goes away before the request is handled.
+const StatusClientClosedRequest = 499
+
+// TimedOut is an error that signifies that the request ran out of time before it could be handled.
+type TimedOut struct{}
+
+func (e *TimedOut) Error() string {
+	return "request timed out"
+}
+
+// ClientClosedRequest is an error that signifies that the client went away before the request could be handled.
+type ClientClosedRequest struct{}
+
+func (e *ClientClosedRequest) Error() string {
+	return "client closed request"
+}
+
+// canceledResponse swaps a server error caused by a canceled store call for a 503 when the request ran out of time,
+// or a 499 when the client went away.
+func canceledResponse(status int, responseData interface{}, errToLog error) (int, interface{}) {
+	if status < http.StatusInternalServerError || errToLog == nil {
+		return status, responseData
+	}
+	castErr, ok := errors.Cause(errToLog).(*storeerror.Canceled)
+	if !ok {
+		return status, responseData
+	}
+	if castErr.DeadlineExceeded() {
+		return http.StatusServiceUnavailable, &TimedOut{}
+	}
+	return StatusClientClosedRequest, &ClientClosedRequest{}
+}
+
+func statusText(status int) string {
+	if status == StatusClientClosedRequest {
+		return "Client Closed Request"
+	}
+	return http.StatusText(status)
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"github.com/worlve/sp-service/internal/stores/storeerror"
+)
+
+func TestCanceledRequest(t *testing.T) {
+	cases := []struct {
+		name              string
+		paramCancelClient bool
+		returnStatus      int
+		returnMessage     string
+	}{
+		{
+			name:          "test request past its deadline is a 503",
+			returnStatus:  http.StatusServiceUnavailable,
+			returnMessage: "request timed out",
+		},
+		{
+			name:              "test request canceled by the client is a 499",
+			paramCancelClient: true,
+			returnStatus:      StatusClientClosedRequest,
+			returnMessage:     "client closed request",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := Handler{
+				AuthN:          AuthN{Datacenter: LocalDatacenterEnv},
+				AuthZ:          AuthZ{APIPath: "api/test"},
+				APIPath:        "api/test",
+				RequestTimeout: 10 * time.Millisecond,
+				Router: NewRouter("api/test", "static/test", []RouterHandler{
+					{
+						Method:   http.MethodGet,
+						Endpoint: "/api/test/slow",
+						Handle: func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+							ctx := r.Context()
+							<-ctx.Done()
+							err := errors.Wrap(storeerror.FromContext(ctx, ctx.Err()), "failed to get slow")
+							RespondWith(r, w, http.StatusInternalServerError, &InternalErr{}, err)
+						},
+					},
+				}),
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tc.paramCancelClient {
+				cancel()
+			}
+			r := httptest.NewRequest(http.MethodGet, "http://test.com/api/test/slow", nil).WithContext(ctx)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			resp := w.Result()
+			require.Equal(t, tc.returnStatus, resp.StatusCode)
+			var body responseFormat
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+			require.Equal(t, tc.returnMessage, body.Meta.Message)
+		})
+	}
+}
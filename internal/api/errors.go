@@ -0,0 +1,183 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/worlve/sp-service/internal/stores/storeerror"
+	"github.com/pkg/errors"
+)
+
+// Code is a stable, machine-readable error code sent with every error response, so clients can branch on it
+// instead of the message.
+type Code string
+
+// Error codes. These are part of the API; don't change or reuse them.
+const (
+	CodeInvalidRequest      Code = "INVALID_REQUEST"
+	CodeValidationFailed    Code = "VALIDATION_FAILED"
+	CodeUnauthenticated     Code = "UNAUTHENTICATED"
+	CodeForbidden           Code = "FORBIDDEN"
+	CodeNotFound            Code = "NOT_FOUND"
+	CodeMethodNotAllowed    Code = "METHOD_NOT_ALLOWED"
+	CodeAlreadyExists       Code = "ALREADY_EXISTS"
+	CodeFailedDependency    Code = "FAILED_DEPENDENCY"
+	CodeRateLimited         Code = "RATE_LIMITED"
+	CodeClientClosedRequest Code = "CLIENT_CLOSED_REQUEST"
+	CodeInternal            Code = "INTERNAL"
+	CodeUnavailable         Code = "UNAVAILABLE"
+	CodeTimedOut            Code = "TIMED_OUT"
+)
+
+// Error is an error sent to the client with its status, code, and the details of each invalid field.
+type Error struct {
+	Status  int
+	Code    Code
+	Message string
+	Details []FieldError
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// FieldError is why a field in the request is invalid.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// InvalidRequest is an error for a request that can't be read, such as a body that isn't JSON.
+func InvalidRequest(message string) *Error {
+	return &Error{
+		Status:  http.StatusBadRequest,
+		Code:    CodeInvalidRequest,
+		Message: message,
+	}
+}
+
+// InvalidField is an error for a request with a missing or invalid field.
+func InvalidField(field, message string) *Error {
+	return &Error{
+		Status:  http.StatusBadRequest,
+		Code:    CodeValidationFailed,
+		Message: message,
+		Details: []FieldError{{Field: field, Message: message}},
+	}
+}
+
+// NestFields nests the fields of err under prefix, such as operations[1].title for an operation in a batch, when it's an Error.
+func NestFields(prefix string, err error) error {
+	castErr, ok := err.(*Error)
+	if !ok {
+		return errors.Wrap(err, prefix)
+	}
+	nested := &Error{
+		Status:  castErr.Status,
+		Code:    castErr.Code,
+		Message: fmt.Sprintf("%v: %v", prefix, castErr.Message),
+	}
+	for _, detail := range castErr.Details {
+		nested.Details = append(nested.Details, FieldError{
+			Field:   prefix + "." + detail.Field,
+			Message: detail.Message,
+		})
+	}
+	return nested
+}
+
+// RespondWithError responds with the status and Error that err translates to, and logs err.
+func RespondWithError(r *http.Request, w http.ResponseWriter, err error) {
+	responseErr := ToError(err)
+	RespondWith(r, w, responseErr.Status, responseErr, err)
+}
+
+// ToError translates err into the Error sent to the client.
+// Store, validation, and auth errors keep their meaning; anything else is an internal error, so its details aren't leaked.
+func ToError(err error) *Error {
+	switch castErr := errors.Cause(err).(type) {
+	case *Error:
+		return castErr
+	case *FailedAuthentication, *FailedAuthorization, *TooManyRequests:
+		return newError(statusFor(castErr), castErr)
+	case *storeerror.NotAuthorized:
+		return newError(http.StatusForbidden, &FailedAuthorization{})
+	case *storeerror.NotFound:
+		return &Error{Status: http.StatusNotFound, Code: CodeNotFound, Message: "not found"}
+	case *storeerror.InvalidField:
+		return InvalidField(castErr.Field, castErr.Message)
+	case *storeerror.DupEntry:
+		return &Error{Status: http.StatusConflict, Code: CodeAlreadyExists, Message: fmt.Sprintf("duplicate id: %v", castErr.ID)}
+	case *storeerror.DBNotSetUp:
+		return &Error{Status: http.StatusServiceUnavailable, Code: CodeUnavailable, Message: "service unavailable"}
+	}
+	return newError(http.StatusInternalServerError, &InternalErr{})
+}
+
+// newError returns the Error sent to the client for err with the status it's sent with.
+func newError(status int, err error) *Error {
+	if castErr, ok := err.(*Error); ok {
+		return castErr
+	}
+	return &Error{
+		Status:  status,
+		Code:    codeFor(status, err),
+		Message: err.Error(),
+	}
+}
+
+func statusFor(err error) int {
+	switch err.(type) {
+	case *FailedAuthentication:
+		return http.StatusUnauthorized
+	case *FailedAuthorization:
+		return http.StatusForbidden
+	case *TooManyRequests:
+		return http.StatusTooManyRequests
+	}
+	return http.StatusInternalServerError
+}
+
+// codeFor returns the code for the api's own errors, or the code for the status for any other error.
+func codeFor(status int, err error) Code {
+	switch err.(type) {
+	case *FailedAuthentication:
+		return CodeUnauthenticated
+	case *FailedAuthorization:
+		return CodeForbidden
+	case *TooManyRequests:
+		return CodeRateLimited
+	case *TimedOut:
+		return CodeTimedOut
+	case *ClientClosedRequest:
+		return CodeClientClosedRequest
+	case *InternalErr:
+		return CodeInternal
+	}
+	switch status {
+	case http.StatusBadRequest:
+		return CodeInvalidRequest
+	case http.StatusUnauthorized:
+		return CodeUnauthenticated
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusMethodNotAllowed:
+		return CodeMethodNotAllowed
+	case http.StatusConflict:
+		return CodeAlreadyExists
+	case http.StatusFailedDependency:
+		return CodeFailedDependency
+	case http.StatusTooManyRequests:
+		return CodeRateLimited
+	case StatusClientClosedRequest:
+		return CodeClientClosedRequest
+	case http.StatusServiceUnavailable:
+		return CodeUnavailable
+	}
+	if status < http.StatusInternalServerError {
+		return CodeInvalidRequest
+	}
+	return CodeInternal
+}
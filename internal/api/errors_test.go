@@ -0,0 +1,114 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"github.com/worlve/sp-service/internal/stores/storeerror"
+	"github.com/worlve/sp-service/internal/util/transaction"
+)
+
+func TestRespondWithError(t *testing.T) {
+	cases := []struct {
+		name          string
+		paramErr      error
+		returnStatus  int
+		returnCode    Code
+		returnMessage string
+		returnDetails []FieldError
+	}{
+		{
+			name:          "test invalid field",
+			paramErr:      InvalidField("title", "must provide title"),
+			returnStatus:  http.StatusBadRequest,
+			returnCode:    CodeValidationFailed,
+			returnMessage: "must provide title",
+			returnDetails: []FieldError{{Field: "title", Message: "must provide title"}},
+		},
+		{
+			name:          "test nested invalid field",
+			paramErr:      NestFields("operations[1]", InvalidField("title", "must provide title")),
+			returnStatus:  http.StatusBadRequest,
+			returnCode:    CodeValidationFailed,
+			returnMessage: "operations[1]: must provide title",
+			returnDetails: []FieldError{{Field: "operations[1].title", Message: "must provide title"}},
+		},
+		{
+			name:          "test invalid request",
+			paramErr:      InvalidRequest("invalid request"),
+			returnStatus:  http.StatusBadRequest,
+			returnCode:    CodeInvalidRequest,
+			returnMessage: "invalid request",
+		},
+		{
+			name:          "test wrapped not found",
+			paramErr:      errors.Wrap(&storeerror.NotFound{ID: "PG_1", Err: errors.New("sql: no rows")}, "failed to get page"),
+			returnStatus:  http.StatusNotFound,
+			returnCode:    CodeNotFound,
+			returnMessage: "not found",
+		},
+		{
+			name:          "test not authorized is forbidden",
+			paramErr:      &storeerror.NotAuthorized{UserID: "UR_1", TableID: "PG_1"},
+			returnStatus:  http.StatusForbidden,
+			returnCode:    CodeForbidden,
+			returnMessage: "not authorized",
+		},
+		{
+			name:          "test wrapped invalid field",
+			paramErr:      errors.Wrap(&storeerror.InvalidField{Field: "anchor", Message: "anchor.detailId DT_1 is not a detail of the page"}, "failed to add comment"),
+			returnStatus:  http.StatusBadRequest,
+			returnCode:    CodeValidationFailed,
+			returnMessage: "anchor.detailId DT_1 is not a detail of the page",
+			returnDetails: []FieldError{{Field: "anchor", Message: "anchor.detailId DT_1 is not a detail of the page"}},
+		},
+		{
+			name:          "test duplicate entry",
+			paramErr:      &storeerror.DupEntry{ID: "PG_1", Err: errors.New("Error 1062")},
+			returnStatus:  http.StatusConflict,
+			returnCode:    CodeAlreadyExists,
+			returnMessage: "duplicate id: PG_1",
+		},
+		{
+			name:          "test db not set up",
+			paramErr:      &storeerror.DBNotSetUp{},
+			returnStatus:  http.StatusServiceUnavailable,
+			returnCode:    CodeUnavailable,
+			returnMessage: "service unavailable",
+		},
+		{
+			name:          "test failed authentication",
+			paramErr:      errors.Wrap(&FailedAuthentication{}, "failed to determine authn"),
+			returnStatus:  http.StatusUnauthorized,
+			returnCode:    CodeUnauthenticated,
+			returnMessage: "not authenticated",
+		},
+		{
+			name:          "test unknown error doesn't leak",
+			paramErr:      errors.New("connection refused to 10.0.0.1"),
+			returnStatus:  http.StatusInternalServerError,
+			returnCode:    CodeInternal,
+			returnMessage: "internal server error",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "http://test.com/api/test/pages", nil)
+			r = r.WithContext(transaction.SetOnContext(r.Context(), transaction.New("RQ_TEST")))
+			w := httptest.NewRecorder()
+			RespondWithError(r, w, tc.paramErr)
+			resp := w.Result()
+			require.Equal(t, tc.returnStatus, resp.StatusCode)
+			var body responseFormat
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+			require.Equal(t, tc.returnCode, body.Meta.Code)
+			require.Equal(t, tc.returnMessage, body.Meta.Message)
+			require.Equal(t, tc.returnDetails, body.Meta.Details)
+			require.Equal(t, "RQ_TEST", body.Meta.RequestID)
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// EventStreamContentType is the media type of a stream of server-sent events.
+const EventStreamContentType = "text/event-stream"
+
+// isEventStream returns whether the request asks for a stream of server-sent events, as browsers' EventSource does.
+// Streams are held open for as long as the client wants, so they aren't given the RequestTimeout.
+func isEventStream(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), EventStreamContentType)
+}
+
+// EventStream writes server-sent events to a response.
+type EventStream struct {
+	w http.ResponseWriter
+}
+
+// StartEventStream responds with the headers of an event stream. It lifts the server's write timeout
+// for the response, which would otherwise end the stream.
+func StartEventStream(w http.ResponseWriter) *EventStream {
+	http.NewResponseController(w).SetWriteDeadline(time.Time{})
+	w.Header().Set("Content-Type", EventStreamContentType)
+	w.Header().Set("Cache-Control", "no-cache")
+	// stops proxies such as nginx from buffering the events
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	s := &EventStream{w: w}
+	s.flush()
+	return s
+}
+
+// Send writes an event and flushes it to the client. The client resumes after id when it reconnects;
+// an empty id leaves the client's last event ID as it is. data must be a single line, such as compact JSON.
+func (s *EventStream) Send(id, event string, data []byte) error {
+	var b strings.Builder
+	if id != "" {
+		fmt.Fprintf(&b, "id: %v\n", id)
+	}
+	fmt.Fprintf(&b, "event: %v\ndata: %s\n\n", event, data)
+	_, err := s.w.Write([]byte(b.String()))
+	if err != nil {
+		return err
+	}
+	s.flush()
+	return nil
+}
+
+// Comment writes a comment, which clients ignore, so idle connections aren't closed by proxies along the way.
+func (s *EventStream) Comment(text string) error {
+	_, err := fmt.Fprintf(s.w, ": %v\n\n", text)
+	if err != nil {
+		return err
+	}
+	s.flush()
+	return nil
+}
+
+func (s *EventStream) flush() {
+	if flusher, ok := s.w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
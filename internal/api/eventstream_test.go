@@ -0,0 +1,63 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventStream(t *testing.T) {
+	cases := []struct {
+		name        string
+		paramAccept string
+		returnBody  string
+	}{
+		{
+			name:        "test stream has no deadline",
+			paramAccept: EventStreamContentType,
+			returnBody:  ": no deadline\n\nid: 7\nevent: page.updated\ndata: {\"pageId\":\"PG_1\"}\n\nevent: reset\ndata: {}\n\n",
+		},
+		{
+			name:       "test other requests have the deadline",
+			returnBody: ": deadline\n\nid: 7\nevent: page.updated\ndata: {\"pageId\":\"PG_1\"}\n\nevent: reset\ndata: {}\n\n",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := Handler{
+				AuthN:          AuthN{Datacenter: LocalDatacenterEnv},
+				AuthZ:          AuthZ{APIPath: "api/test"},
+				APIPath:        "api/test",
+				RequestTimeout: time.Minute,
+				Router: NewRouter("api/test", "static/test", []RouterHandler{
+					{
+						Method:   http.MethodGet,
+						Endpoint: "/api/test/events",
+						Handle: func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+							stream := StartEventStream(w)
+							if _, ok := r.Context().Deadline(); ok {
+								stream.Comment("deadline")
+							} else {
+								stream.Comment("no deadline")
+							}
+							stream.Send("7", "page.updated", []byte(`{"pageId":"PG_1"}`))
+							stream.Send("", "reset", []byte(`{}`))
+						},
+					},
+				}),
+			}
+			r := httptest.NewRequest(http.MethodGet, "http://test.com/api/test/events", nil)
+			r.Header.Set("Accept", tc.paramAccept)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			require.Equal(t, http.StatusOK, w.Code)
+			require.Equal(t, EventStreamContentType, w.Header().Get("Content-Type"))
+			require.True(t, w.Flushed)
+			require.Equal(t, tc.returnBody, w.Body.String())
+		})
+	}
+}
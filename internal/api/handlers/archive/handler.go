@@ -0,0 +1,89 @@
+package archivehandler
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/worlve/sp-service/internal/api"
+	"github.com/worlve/sp-service/internal/models/archive"
+	archiveservice "github.com/worlve/sp-service/internal/services/archive"
+	"github.com/worlve/sp-service/internal/stores/storeerror"
+	"github.com/julienschmidt/httprouter"
+	"github.com/pkg/errors"
+)
+
+// ArchiveService see Service for more details
+type ArchiveService interface {
+	Export(ctx context.Context, params archiveservice.ExportParams) (archive.Archive, error)
+	Import(ctx context.Context, params archiveservice.ImportParams) (archiveservice.ImportResult, error)
+}
+
+// ArchiveHandler is the handler for the associated API
+type ArchiveHandler struct {
+	ArchiveService ArchiveService
+}
+
+// Export see Service for more details
+func (h ArchiveHandler) Export(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	request, err := NewExportRequest(r, p)
+	if err != nil {
+		api.RespondWithError(r, w, err)
+		return
+	}
+	ctx := r.Context()
+	authData, err := api.GetDataFromContext(ctx)
+	if err != nil {
+		api.RespondWith(r, w, http.StatusInternalServerError, &api.InternalErr{}, errors.Wrap(err, "failed to get auth data"))
+		return
+	}
+	record, err := h.ArchiveService.Export(ctx, archiveservice.ExportParams{
+		UserID:    authData.UserID,
+		VersionID: request.VersionID,
+	})
+	if err != nil {
+		api.RespondWithError(r, w, err)
+		return
+	}
+	// encode up front so a failure can still be reported as a normal error response
+	var body bytes.Buffer
+	err = archive.Encode(&body, record, request.Format)
+	if err != nil {
+		api.RespondWith(r, w, http.StatusInternalServerError, &api.InternalErr{}, errors.Wrap(err, "failed to encode archive"))
+		return
+	}
+	w.Header().Set("Content-Type", request.Format.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"export.%v\"", request.Format))
+	w.WriteHeader(http.StatusOK)
+	w.Write(body.Bytes())
+}
+
+// Import see Service for more details
+func (h ArchiveHandler) Import(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	request, err := NewImportRequest(w, r, p)
+	if err != nil {
+		api.RespondWithError(r, w, err)
+		return
+	}
+	ctx := r.Context()
+	authData, err := api.GetDataFromContext(ctx)
+	if err != nil {
+		api.RespondWith(r, w, http.StatusInternalServerError, &api.InternalErr{}, errors.Wrap(err, "failed to get auth data"))
+		return
+	}
+	result, err := h.ArchiveService.Import(ctx, archiveservice.ImportParams{
+		Archive:  request.Archive,
+		OwnerID:  authData.UserID,
+		Conflict: request.Conflict,
+	})
+	if _, ok := errors.Cause(err).(*storeerror.NotFound); ok {
+		api.RespondWith(r, w, http.StatusBadRequest, errors.New("archive references a version or page template that does not exist"), err)
+		return
+	}
+	if err != nil {
+		api.RespondWithError(r, w, err)
+		return
+	}
+	api.RespondWith(r, w, http.StatusOK, result, nil)
+}
@@ -0,0 +1,257 @@
+package archivehandler
+
+import (
+	"bytes"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/worlve/sp-service/internal/api"
+	"github.com/worlve/sp-service/internal/api/handlers/archive/mocks"
+	"github.com/worlve/sp-service/internal/api/handlers/handlertestutils"
+	"github.com/worlve/sp-service/internal/models/archive"
+	"github.com/worlve/sp-service/internal/models/permission"
+	archiveservice "github.com/worlve/sp-service/internal/services/archive"
+	"github.com/worlve/sp-service/internal/stores/storeerror"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func getArchive() archive.Archive {
+	return archive.Archive{
+		Manifest: archive.Manifest{
+			FormatVersion: archive.FormatVersion,
+			ExportedAt:    time.Date(2020, 3, 31, 12, 0, 0, 0, time.UTC),
+			OwnerID:       "UR_1",
+		},
+		Pages: []archive.Page{
+			{GUID: "PG_1", VersionID: "VR_1", PageTemplateID: "PGT_1", Title: "title 1", PermissionType: permission.TypePrivate},
+		},
+	}
+}
+
+func encodeArchive(t *testing.T, format archive.Format) string {
+	var body bytes.Buffer
+	err := archive.Encode(&body, getArchive(), format)
+	require.NoError(t, err)
+	return body.String()
+}
+
+type exportCall struct {
+	params        archiveservice.ExportParams
+	returnArchive archive.Archive
+	returnErr     error
+}
+
+func TestExport(t *testing.T) {
+	cases := []struct {
+		name                 string
+		headers              map[string]string
+		params               url.Values
+		authN                api.AuthN
+		authZ                api.AuthZ
+		expectedResponseBody string
+		expectedContentType  string
+		expectedStatusCode   int
+		exportCalls          []exportCall
+	}{
+		{
+			name:                 "not authenticated",
+			authN:                handlertestutils.DefaultAuthN("PROD"),
+			authZ:                handlertestutils.DefaultAuthZ(),
+			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"401 - Unauthorized\",\"code\":\"UNAUTHENTICATED\",\"message\":\"not authenticated\",\"requestId\":\"RQ_TEST\"}}\n",
+			expectedContentType:  "application/json",
+			expectedStatusCode:   401,
+		},
+		{
+			name: "happy path, zip by default",
+			headers: map[string]string{
+				"X-USER-ID": "UR_1",
+			},
+			authN:                handlertestutils.DefaultAuthN("LOCAL"),
+			authZ:                handlertestutils.DefaultAuthZ(),
+			expectedResponseBody: encodeArchive(t, archive.FormatZip),
+			expectedContentType:  "application/zip",
+			expectedStatusCode:   200,
+			exportCalls: []exportCall{
+				{params: archiveservice.ExportParams{UserID: "UR_1"}, returnArchive: getArchive()},
+			},
+		},
+		{
+			name: "happy path, ndjson",
+			headers: map[string]string{
+				"X-USER-ID": "UR_1",
+			},
+			params:               url.Values{"format": []string{"ndjson"}},
+			authN:                handlertestutils.DefaultAuthN("LOCAL"),
+			authZ:                handlertestutils.DefaultAuthZ(),
+			expectedResponseBody: encodeArchive(t, archive.FormatNDJSON),
+			expectedContentType:  "application/x-ndjson",
+			expectedStatusCode:   200,
+			exportCalls: []exportCall{
+				{params: archiveservice.ExportParams{UserID: "UR_1"}, returnArchive: getArchive()},
+			},
+		},
+		{
+			name: "happy path, limited to a version",
+			headers: map[string]string{
+				"X-USER-ID": "UR_1",
+			},
+			params:               url.Values{"versionId": []string{"VR_1"}},
+			authN:                handlertestutils.DefaultAuthN("LOCAL"),
+			authZ:                handlertestutils.DefaultAuthZ(),
+			expectedResponseBody: encodeArchive(t, archive.FormatZip),
+			expectedContentType:  "application/zip",
+			expectedStatusCode:   200,
+			exportCalls: []exportCall{
+				{params: archiveservice.ExportParams{UserID: "UR_1", VersionID: "VR_1"}, returnArchive: getArchive()},
+			},
+		},
+		{
+			name: "invalid format",
+			headers: map[string]string{
+				"X-USER-ID": "UR_1",
+			},
+			params:               url.Values{"format": []string{"csv"}},
+			authN:                handlertestutils.DefaultAuthN("LOCAL"),
+			authZ:                handlertestutils.DefaultAuthZ(),
+			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"400 - Bad Request\",\"code\":\"VALIDATION_FAILED\",\"message\":\"request does not match the API spec: format: must be one of: zip, ndjson\",\"details\":[{\"field\":\"format\",\"message\":\"must be one of: zip, ndjson\"}],\"requestId\":\"RQ_TEST\"}}\n",
+			expectedContentType:  "application/json",
+			expectedStatusCode:   400,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			archiveService := new(mocks.ArchiveService)
+			for index := range tc.exportCalls {
+				archiveService.On("Export", mock.Anything, tc.exportCalls[index].params).Return(tc.exportCalls[index].returnArchive, tc.exportCalls[index].returnErr)
+			}
+			routerHandlers := ArchiveRouterHandlers(tc.authZ.APIPath, archiveService)
+			resp, respBody := handlertestutils.HandleTestRequest(handlertestutils.HandleTestRequestParams{
+				Method:         http.MethodGet,
+				Endpoint:       "export",
+				Params:         tc.params,
+				Headers:        tc.headers,
+				RouterHandlers: routerHandlers,
+				AuthZ:          tc.authZ,
+				AuthN:          tc.authN,
+			})
+			require.Equal(t, tc.expectedResponseBody, respBody)
+			require.Equal(t, tc.expectedStatusCode, resp.StatusCode)
+			require.Equal(t, tc.expectedContentType, resp.Header.Get("Content-Type"))
+			archiveService.AssertNumberOfCalls(t, "Export", len(tc.exportCalls))
+		})
+	}
+}
+
+type importCall struct {
+	params       archiveservice.ImportParams
+	returnResult archiveservice.ImportResult
+	returnErr    error
+}
+
+func TestImport(t *testing.T) {
+	cases := []struct {
+		name                 string
+		headers              map[string]string
+		params               url.Values
+		requestBody          string
+		authN                api.AuthN
+		authZ                api.AuthZ
+		expectedResponseBody string
+		expectedStatusCode   int
+		importCalls          []importCall
+	}{
+		{
+			name:                 "not authenticated",
+			authN:                handlertestutils.DefaultAuthN("PROD"),
+			authZ:                handlertestutils.DefaultAuthZ(),
+			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"401 - Unauthorized\",\"code\":\"UNAUTHENTICATED\",\"message\":\"not authenticated\",\"requestId\":\"RQ_TEST\"}}\n",
+			expectedStatusCode:   401,
+		},
+		{
+			name: "happy path",
+			headers: map[string]string{
+				"X-USER-ID": "UR_2",
+			},
+			params:               url.Values{"format": []string{"ndjson"}},
+			requestBody:          encodeArchive(t, archive.FormatNDJSON),
+			authN:                handlertestutils.DefaultAuthN("LOCAL"),
+			authZ:                handlertestutils.DefaultAuthZ(),
+			expectedResponseBody: "{\"result\":{\"pages\":{\"PG_1\":\"PG_2\"},\"skipped\":[]},\"meta\":{\"httpStatus\":\"200 - OK\",\"requestId\":\"RQ_TEST\"}}\n",
+			expectedStatusCode:   200,
+			importCalls: []importCall{
+				{
+					params:       archiveservice.ImportParams{Archive: getArchive(), OwnerID: "UR_2", Conflict: archiveservice.ConflictRemap},
+					returnResult: archiveservice.ImportResult{Pages: map[string]string{"PG_1": "PG_2"}, Skipped: []string{}},
+				},
+			},
+		},
+		{
+			name: "conflicting page id",
+			headers: map[string]string{
+				"X-USER-ID": "UR_2",
+			},
+			params:               url.Values{"format": []string{"zip"}, "conflict": []string{"fail"}},
+			requestBody:          encodeArchive(t, archive.FormatZip),
+			authN:                handlertestutils.DefaultAuthN("LOCAL"),
+			authZ:                handlertestutils.DefaultAuthZ(),
+			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"409 - Conflict\",\"code\":\"ALREADY_EXISTS\",\"message\":\"duplicate id: PG_1\",\"requestId\":\"RQ_TEST\"}}\n",
+			expectedStatusCode:   409,
+			importCalls: []importCall{
+				{
+					params:    archiveservice.ImportParams{Archive: getArchive(), OwnerID: "UR_2", Conflict: archiveservice.ConflictFail},
+					returnErr: &storeerror.DupEntry{ID: "PG_1"},
+				},
+			},
+		},
+		{
+			name: "invalid conflict strategy",
+			headers: map[string]string{
+				"X-USER-ID": "UR_2",
+			},
+			params:               url.Values{"conflict": []string{"overwrite"}},
+			requestBody:          encodeArchive(t, archive.FormatZip),
+			authN:                handlertestutils.DefaultAuthN("LOCAL"),
+			authZ:                handlertestutils.DefaultAuthZ(),
+			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"400 - Bad Request\",\"code\":\"VALIDATION_FAILED\",\"message\":\"request does not match the API spec: conflict: must be one of: remap, skip, fail\",\"details\":[{\"field\":\"conflict\",\"message\":\"must be one of: remap, skip, fail\"}],\"requestId\":\"RQ_TEST\"}}\n",
+			expectedStatusCode:   400,
+		},
+		{
+			name: "invalid archive",
+			headers: map[string]string{
+				"X-USER-ID": "UR_2",
+			},
+			params:               url.Values{"format": []string{"ndjson"}},
+			requestBody:          "{\"kind\":\"page\",\"page\":{\"id\":\"PG_1\"}}\n",
+			authN:                handlertestutils.DefaultAuthN("LOCAL"),
+			authZ:                handlertestutils.DefaultAuthZ(),
+			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"400 - Bad Request\",\"code\":\"INVALID_REQUEST\",\"message\":\"invalid archive: archive is missing its manifest\",\"requestId\":\"RQ_TEST\"}}\n",
+			expectedStatusCode:   400,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			archiveService := new(mocks.ArchiveService)
+			for index := range tc.importCalls {
+				archiveService.On("Import", mock.Anything, tc.importCalls[index].params).Return(tc.importCalls[index].returnResult, tc.importCalls[index].returnErr)
+			}
+			routerHandlers := ArchiveRouterHandlers(tc.authZ.APIPath, archiveService)
+			resp, respBody := handlertestutils.HandleTestRequest(handlertestutils.HandleTestRequestParams{
+				Method:         http.MethodPost,
+				Endpoint:       "import",
+				Params:         tc.params,
+				Headers:        tc.headers,
+				Body:           strings.NewReader(tc.requestBody),
+				RouterHandlers: routerHandlers,
+				AuthZ:          tc.authZ,
+				AuthN:          tc.authN,
+			})
+			require.Equal(t, tc.expectedResponseBody, respBody)
+			require.Equal(t, tc.expectedStatusCode, resp.StatusCode)
+			archiveService.AssertNumberOfCalls(t, "Import", len(tc.importCalls))
+		})
+	}
+}
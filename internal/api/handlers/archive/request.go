@@ -0,0 +1,84 @@
+package archivehandler
+
+import (
+	"net/http"
+
+	"github.com/worlve/sp-service/internal/api"
+	"github.com/worlve/sp-service/internal/models/archive"
+	archiveservice "github.com/worlve/sp-service/internal/services/archive"
+	"github.com/julienschmidt/httprouter"
+	"github.com/pkg/errors"
+)
+
+// MaxImportBytes is the largest archive that can be given in a single Import call.
+const MaxImportBytes = 32 << 20
+
+// ExportRequest parameters from the Export call
+type ExportRequest struct {
+	Format    archive.Format
+	VersionID string
+}
+
+// NewExportRequest extracts the ExportRequest
+func NewExportRequest(r *http.Request, p httprouter.Params) (ExportRequest, error) {
+	var request ExportRequest
+	format, err := getFormat(r)
+	if err != nil {
+		return request, err
+	}
+	request.Format = format
+	request.VersionID = r.URL.Query().Get("versionId")
+	return request.validate()
+}
+
+func (request ExportRequest) validate() (ExportRequest, error) {
+	return request, nil
+}
+
+// ImportRequest parameters from the Import call
+type ImportRequest struct {
+	Archive  archive.Archive
+	Conflict archiveservice.ConflictStrategy
+}
+
+// NewImportRequest extracts the ImportRequest
+func NewImportRequest(w http.ResponseWriter, r *http.Request, p httprouter.Params) (ImportRequest, error) {
+	var request ImportRequest
+	format, err := getFormat(r)
+	if err != nil {
+		return request, err
+	}
+	conflict := archiveservice.ConflictRemap
+	if conflictString := r.URL.Query().Get("conflict"); conflictString != "" {
+		conflict, err = archiveservice.GetConflictStrategy(conflictString)
+		if err != nil {
+			return request, api.InvalidField("conflict", "conflict must be one of remap, skip, or fail")
+		}
+	}
+	request.Conflict = conflict
+	request.Archive, err = archive.Decode(http.MaxBytesReader(w, r.Body, MaxImportBytes), format)
+	if err != nil {
+		return request, api.InvalidRequest(errors.Wrap(err, "invalid archive").Error())
+	}
+	return request.validate()
+}
+
+func (request ImportRequest) validate() (ImportRequest, error) {
+	err := request.Archive.Validate()
+	if err != nil {
+		return request, api.InvalidRequest(errors.Wrap(err, "invalid archive").Error())
+	}
+	return request, nil
+}
+
+func getFormat(r *http.Request) (archive.Format, error) {
+	formatString := r.URL.Query().Get("format")
+	if formatString == "" {
+		return archive.FormatZip, nil
+	}
+	format, err := archive.GetFormat(formatString)
+	if err != nil {
+		return format, api.InvalidField("format", "format must be one of zip or ndjson")
+	}
+	return format, nil
+}
@@ -0,0 +1,75 @@
+package archivehandler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/worlve/sp-service/internal/api"
+	"github.com/worlve/sp-service/internal/models/archive"
+	archiveservice "github.com/worlve/sp-service/internal/services/archive"
+)
+
+var formatQueryParam = api.QueryParam{
+	Name:        "format",
+	Description: "Format of the archive. Defaults to zip.",
+	Enum:        []string{string(archive.FormatZip), string(archive.FormatNDJSON)},
+}
+
+// ArchiveRouterHandlers returns the requests for the associated routes.
+func ArchiveRouterHandlers(apiPath string, archiveService ArchiveService) []api.RouterHandler {
+	handler := ArchiveHandler{
+		ArchiveService: archiveService,
+	}
+	var routerHandlers []api.RouterHandler
+	routerHandlers = append(routerHandlers, api.RouterHandler{
+		Method:   http.MethodGet,
+		Endpoint: fmt.Sprintf("/%v/export", apiPath),
+		Handle:   handler.Export,
+		Doc: &api.RouteDoc{
+			OperationID: "export",
+			Summary:     "Export",
+			Description: "Exports all of the user's pages, along with their properties, details, and the versions and page templates they rely on, as a versioned archive.",
+			Tag:         "archive",
+			Query: []api.QueryParam{
+				formatQueryParam,
+				{
+					Name:        "versionId",
+					Description: "Only export the pages in this version.",
+				},
+			},
+			ResponseContentTypes: []string{
+				archive.FormatZip.ContentType(),
+				archive.FormatNDJSON.ContentType(),
+			},
+		},
+	})
+	routerHandlers = append(routerHandlers, api.RouterHandler{
+		Method:   http.MethodPost,
+		Endpoint: fmt.Sprintf("/%v/import", apiPath),
+		Handle:   handler.Import,
+		Doc: &api.RouteDoc{
+			OperationID: "import",
+			Summary:     "Import",
+			Description: "Imports an archive created by Export into the user's account. The whole import succeeds or fails together.",
+			Tag:         "archive",
+			Query: []api.QueryParam{
+				formatQueryParam,
+				{
+					Name:        "conflict",
+					Description: "How to handle page ids from the archive that already exist. Defaults to remap.",
+					Enum: []string{
+						string(archiveservice.ConflictRemap),
+						string(archiveservice.ConflictSkip),
+						string(archiveservice.ConflictFail),
+					},
+				},
+			},
+			RequestContentTypes: []string{
+				archive.FormatZip.ContentType(),
+				archive.FormatNDJSON.ContentType(),
+			},
+			Response: archiveservice.ImportResult{},
+		},
+	})
+	return routerHandlers
+}
@@ -0,0 +1,159 @@
+package audithandler
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/worlve/sp-service/internal/api"
+	"github.com/worlve/sp-service/internal/api/handlers/nextbatch"
+	"github.com/worlve/sp-service/internal/models/audit"
+	"github.com/worlve/sp-service/internal/models/page"
+	auditservice "github.com/worlve/sp-service/internal/services/audit"
+	"github.com/julienschmidt/httprouter"
+	"github.com/pkg/errors"
+)
+
+// AuditService see Service for more details
+type AuditService interface {
+	Record(ctx context.Context, params auditservice.RecordParams) error
+	GetPageEntries(ctx context.Context, params auditservice.GetPageEntriesParams) ([]audit.Entry, int, string, error)
+	GetUserEntries(ctx context.Context, params auditservice.GetUserEntriesParams) ([]audit.Entry, int, string, error)
+	GetEntries(ctx context.Context, params auditservice.GetEntriesParams) ([]audit.Entry, int, string, error)
+}
+
+// AuditHandler is the handler for the associated API
+type AuditHandler struct {
+	AuditService AuditService
+}
+
+// Auditor records the API's mutating requests in the audit log; it's the api.Handler's Auditor.
+type Auditor struct {
+	AuditService AuditService
+}
+
+// Audit see api.Auditor
+func (a Auditor) Audit(ctx context.Context, request api.AuditedRequest) error {
+	targetID, pageID := getTarget(request.Params)
+	actorType := audit.ActorProxyUser
+	if request.AuthData.IsAdmin() {
+		actorType = audit.ActorAdmin
+	}
+	return a.AuditService.Record(ctx, auditservice.RecordParams{
+		ActorType: actorType,
+		UserID:    request.AuthData.UserID,
+		Method:    request.Method,
+		Route:     request.Route,
+		TargetID:  targetID,
+		PageID:    pageID,
+		Status:    request.Status,
+		RequestID: request.RequestID,
+		Changes:   request.Changes,
+	})
+}
+
+// getTarget returns the route's last id, such as the detailID of /pages/:pageID/details/:detailID, and its pageID.
+func getTarget(params httprouter.Params) (string, string) {
+	targetID := ""
+	for _, param := range params {
+		if strings.HasSuffix(param.Key, "ID") {
+			targetID = param.Value
+		}
+	}
+	return targetID, params.ByName(PageIDRouteKey)
+}
+
+// EntryBatchResponse is a batch of audit entries, with how to get the next batch when there are more.
+type EntryBatchResponse struct {
+	Batch     []audit.Entry        `json:"batch"`
+	Total     int                  `json:"total"`
+	NextBatch *nextbatch.NextBatch `json:"nextBatch,omitempty"`
+}
+
+// GetPageActivity see Service for more details
+func (h AuditHandler) GetPageActivity(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	request, err := NewGetPageActivityRequest(r, p)
+	if err != nil {
+		api.RespondWithError(r, w, err)
+		return
+	}
+	ctx := r.Context()
+	authData, err := api.GetDataFromContext(ctx)
+	if err != nil {
+		api.RespondWith(r, w, http.StatusInternalServerError, &api.InternalErr{}, errors.Wrap(err, "failed to get auth data"))
+		return
+	}
+	records, total, nextBatchID, err := h.AuditService.GetPageEntries(ctx, auditservice.GetPageEntriesParams{
+		Page: page.Page{
+			GUID: request.GUID,
+		},
+		Filter:      request.Filter,
+		NextBatchID: request.NextBatchID,
+		UserID:      authData.UserID,
+	})
+	respondWithBatch(w, r, records, total, nextBatchID, err)
+}
+
+// GetActivity see Service for more details
+func (h AuditHandler) GetActivity(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	request, err := NewGetEntriesRequest(r, p)
+	if err != nil {
+		api.RespondWithError(r, w, err)
+		return
+	}
+	ctx := r.Context()
+	authData, err := api.GetDataFromContext(ctx)
+	if err != nil {
+		api.RespondWith(r, w, http.StatusInternalServerError, &api.InternalErr{}, errors.Wrap(err, "failed to get auth data"))
+		return
+	}
+	records, total, nextBatchID, err := h.AuditService.GetUserEntries(ctx, auditservice.GetUserEntriesParams{
+		Filter:      request.Filter,
+		NextBatchID: request.NextBatchID,
+		UserID:      authData.UserID,
+	})
+	respondWithBatch(w, r, records, total, nextBatchID, err)
+}
+
+// GetAuditLog see Service for more details
+func (h AuditHandler) GetAuditLog(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	ctx := r.Context()
+	authData, err := api.GetDataFromContext(ctx)
+	if err != nil {
+		api.RespondWith(r, w, http.StatusInternalServerError, &api.InternalErr{}, errors.Wrap(err, "failed to get auth data"))
+		return
+	}
+	if !authData.IsAdmin() {
+		api.RespondWith(r, w, http.StatusForbidden, &api.FailedAuthorization{}, errors.Errorf("user not authorized for the audit log: %v", authData.UserID))
+		return
+	}
+	request, err := NewGetEntriesRequest(r, p)
+	if err != nil {
+		api.RespondWithError(r, w, err)
+		return
+	}
+	records, total, nextBatchID, err := h.AuditService.GetEntries(ctx, auditservice.GetEntriesParams{
+		Filter:      request.Filter,
+		NextBatchID: request.NextBatchID,
+	})
+	respondWithBatch(w, r, records, total, nextBatchID, err)
+}
+
+func respondWithBatch(w http.ResponseWriter, r *http.Request, records []audit.Entry, total int, nextBatchID string, err error) {
+	if err != nil {
+		api.RespondWithError(r, w, err)
+		return
+	}
+	responseBody := EntryBatchResponse{
+		Batch: make([]audit.Entry, 0, len(records)),
+		Total: total,
+	}
+	responseBody.Batch = append(responseBody.Batch, records...)
+	if nextBatchID != "" {
+		responseBody.NextBatch = &nextbatch.NextBatch{
+			ParamKey:   "nextBatchId",
+			ParamValue: nextBatchID,
+		}
+	}
+	api.RespondWith(r, w, http.StatusOK, responseBody, nil)
+}
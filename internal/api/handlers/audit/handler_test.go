@@ -0,0 +1,261 @@
+package audithandler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/worlve/sp-service/internal/api"
+	"github.com/worlve/sp-service/internal/api/handlers/audit/mocks"
+	"github.com/worlve/sp-service/internal/api/handlers/handlertestutils"
+	"github.com/worlve/sp-service/internal/models/audit"
+	"github.com/worlve/sp-service/internal/models/page"
+	auditservice "github.com/worlve/sp-service/internal/services/audit"
+	"github.com/worlve/sp-service/internal/stores/storeerror"
+	"github.com/worlve/sp-service/internal/util/auditlog"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var createdAt = time.Date(2020, 3, 31, 12, 0, 0, 0, time.UTC)
+
+var entry = audit.Entry{
+	GUID:      "AE_1",
+	ActorType: audit.ActorProxyUser,
+	UserID:    "UR_1",
+	Method:    http.MethodPut,
+	Route:     "/api/pages/:pageID",
+	TargetID:  "PG_1",
+	PageID:    "PG_1",
+	Status:    200,
+	Before:    json.RawMessage(`{"title":"a"}`),
+	After:     json.RawMessage(`{"title":"b"}`),
+	RequestID: "RQ_1",
+	CreatedAt: &createdAt,
+}
+
+const entryJSON = "{\"id\":\"AE_1\",\"actorType\":\"proxyUser\",\"userId\":\"UR_1\",\"method\":\"PUT\",\"route\":\"/api/pages/:pageID\",\"targetId\":\"PG_1\",\"pageId\":\"PG_1\",\"status\":200,\"before\":{\"title\":\"a\"},\"after\":{\"title\":\"b\"},\"requestId\":\"RQ_1\",\"createdAt\":\"2020-03-31T12:00:00Z\"}"
+
+type getPageEntriesCall struct {
+	params        auditservice.GetPageEntriesParams
+	returnEntries []audit.Entry
+	returnTotal   int
+	returnNext    string
+	returnErr     error
+}
+
+func TestGetPageActivity(t *testing.T) {
+	since := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+	cases := []struct {
+		name                 string
+		params               url.Values
+		expectedResponseBody string
+		expectedStatusCode   int
+		getPageEntriesCalls  []getPageEntriesCall
+	}{
+		{
+			name:                 "happy path",
+			params:               url.Values{"since": []string{"2020-03-01T00:00:00Z"}, "actorType": []string{"proxyUser"}, "nextBatchId": []string{"AE_2"}},
+			expectedResponseBody: "{\"result\":{\"batch\":[" + entryJSON + "],\"total\":3,\"nextBatch\":{\"paramKey\":\"nextBatchId\",\"paramValue\":\"AE_0\"}},\"meta\":{\"httpStatus\":\"200 - OK\",\"requestId\":\"RQ_TEST\"}}\n",
+			expectedStatusCode:   200,
+			getPageEntriesCalls: []getPageEntriesCall{
+				{
+					params: auditservice.GetPageEntriesParams{
+						Page:        page.Page{GUID: "PG_1"},
+						Filter:      audit.Filter{ActorType: audit.ActorProxyUser, Since: &since},
+						NextBatchID: "AE_2",
+						UserID:      "UR_1",
+					},
+					returnEntries: []audit.Entry{entry},
+					returnTotal:   3,
+					returnNext:    "AE_0",
+				},
+			},
+		},
+		{
+			name:                 "invalid since",
+			params:               url.Values{"since": []string{"yesterday"}},
+			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"400 - Bad Request\",\"code\":\"VALIDATION_FAILED\",\"message\":\"since must be an RFC 3339 time\",\"details\":[{\"field\":\"since\",\"message\":\"since must be an RFC 3339 time\"}],\"requestId\":\"RQ_TEST\"}}\n",
+			expectedStatusCode:   400,
+		},
+		{
+			name:                 "someone else's private page",
+			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"403 - Forbidden\",\"code\":\"FORBIDDEN\",\"message\":\"not authorized\",\"requestId\":\"RQ_TEST\"}}\n",
+			expectedStatusCode:   403,
+			getPageEntriesCalls: []getPageEntriesCall{
+				{
+					params:    auditservice.GetPageEntriesParams{Page: page.Page{GUID: "PG_1"}, UserID: "UR_1"},
+					returnErr: &storeerror.NotAuthorized{UserID: "UR_1", TableID: "PG_1"},
+				},
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			auditService := new(mocks.AuditService)
+			for index := range tc.getPageEntriesCalls {
+				call := tc.getPageEntriesCalls[index]
+				auditService.On("GetPageEntries", mock.Anything, call.params).Return(call.returnEntries, call.returnTotal, call.returnNext, call.returnErr)
+			}
+			authZ := handlertestutils.DefaultAuthZ()
+			resp, respBody := handlertestutils.HandleTestRequest(handlertestutils.HandleTestRequestParams{
+				Method:         http.MethodGet,
+				Endpoint:       "pages/PG_1/activity",
+				Params:         tc.params,
+				Headers:        map[string]string{"X-USER-ID": "UR_1"},
+				RouterHandlers: AuditRouterHandlers(authZ.APIPath, auditService),
+				AuthZ:          authZ,
+				AuthN:          handlertestutils.DefaultAuthN("LOCAL"),
+			})
+			require.Equal(t, tc.expectedResponseBody, respBody)
+			require.Equal(t, tc.expectedStatusCode, resp.StatusCode)
+			auditService.AssertNumberOfCalls(t, "GetPageEntries", len(tc.getPageEntriesCalls))
+		})
+	}
+}
+
+func TestGetActivity(t *testing.T) {
+	auditService := new(mocks.AuditService)
+	auditService.On("GetUserEntries", mock.Anything, auditservice.GetUserEntriesParams{
+		Filter: audit.Filter{PageID: "PG_1"},
+		UserID: "UR_1",
+	}).Return([]audit.Entry{entry}, 1, "", nil)
+	authZ := handlertestutils.DefaultAuthZ()
+	resp, respBody := handlertestutils.HandleTestRequest(handlertestutils.HandleTestRequestParams{
+		Method:         http.MethodGet,
+		Endpoint:       "activity",
+		Params:         url.Values{"pageId": []string{"PG_1"}},
+		Headers:        map[string]string{"X-USER-ID": "UR_1"},
+		RouterHandlers: AuditRouterHandlers(authZ.APIPath, auditService),
+		AuthZ:          authZ,
+		AuthN:          handlertestutils.DefaultAuthN("LOCAL"),
+	})
+	require.Equal(t, "{\"result\":{\"batch\":["+entryJSON+"],\"total\":1},\"meta\":{\"httpStatus\":\"200 - OK\",\"requestId\":\"RQ_TEST\"}}\n", respBody)
+	require.Equal(t, 200, resp.StatusCode)
+}
+
+type getEntriesCall struct {
+	params        auditservice.GetEntriesParams
+	returnEntries []audit.Entry
+	returnTotal   int
+}
+
+func TestGetAuditLog(t *testing.T) {
+	cases := []struct {
+		name                 string
+		headers              map[string]string
+		params               url.Values
+		expectedResponseBody string
+		expectedStatusCode   int
+		getEntriesCalls      []getEntriesCall
+	}{
+		{
+			name:                 "admin happy path",
+			params:               url.Values{"userId": []string{"UR_1"}, "targetId": []string{"PG_1"}},
+			expectedResponseBody: "{\"result\":{\"batch\":[" + entryJSON + "],\"total\":1},\"meta\":{\"httpStatus\":\"200 - OK\",\"requestId\":\"RQ_TEST\"}}\n",
+			expectedStatusCode:   200,
+			getEntriesCalls: []getEntriesCall{
+				{
+					params:        auditservice.GetEntriesParams{Filter: audit.Filter{UserID: "UR_1", TargetID: "PG_1"}},
+					returnEntries: []audit.Entry{entry},
+					returnTotal:   1,
+				},
+			},
+		},
+		{
+			name: "proxied user",
+			headers: map[string]string{
+				"X-USER-ID": "UR_1",
+			},
+			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"403 - Forbidden\",\"code\":\"FORBIDDEN\",\"message\":\"not authorized\",\"requestId\":\"RQ_TEST\"}}\n",
+			expectedStatusCode:   403,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			auditService := new(mocks.AuditService)
+			for index := range tc.getEntriesCalls {
+				call := tc.getEntriesCalls[index]
+				auditService.On("GetEntries", mock.Anything, call.params).Return(call.returnEntries, call.returnTotal, "", nil)
+			}
+			authZ := handlertestutils.DefaultAuthZ()
+			resp, respBody := handlertestutils.HandleTestRequest(handlertestutils.HandleTestRequestParams{
+				Method:         http.MethodGet,
+				Endpoint:       "audit",
+				Params:         tc.params,
+				Headers:        tc.headers,
+				RouterHandlers: AuditRouterHandlers(authZ.APIPath, auditService),
+				AuthZ:          authZ,
+				AuthN:          handlertestutils.DefaultAuthN("LOCAL"),
+			})
+			require.Equal(t, tc.expectedResponseBody, respBody)
+			require.Equal(t, tc.expectedStatusCode, resp.StatusCode)
+			auditService.AssertNumberOfCalls(t, "GetEntries", len(tc.getEntriesCalls))
+		})
+	}
+}
+
+func TestAudit(t *testing.T) {
+	cases := []struct {
+		name         string
+		paramRequest api.AuditedRequest
+		returnParams auditservice.RecordParams
+	}{
+		{
+			name: "test a proxied user changing a detail",
+			paramRequest: api.AuditedRequest{
+				AuthData:  api.AuthData{Type: api.AuthTypeProxyUser, UserID: "UR_1"},
+				Method:    http.MethodPut,
+				Route:     "/api/pages/:pageID/details/:detailID",
+				Params:    httprouter.Params{{Key: "pageID", Value: "PG_1"}, {Key: "detailID", Value: "PD_1"}},
+				Status:    200,
+				RequestID: "RQ_1",
+				Changes:   []auditlog.Change{{TargetID: "PD_1", PageID: "PG_1", After: "detail"}},
+			},
+			returnParams: auditservice.RecordParams{
+				ActorType: audit.ActorProxyUser,
+				UserID:    "UR_1",
+				Method:    http.MethodPut,
+				Route:     "/api/pages/:pageID/details/:detailID",
+				TargetID:  "PD_1",
+				PageID:    "PG_1",
+				Status:    200,
+				RequestID: "RQ_1",
+				Changes:   []auditlog.Change{{TargetID: "PD_1", PageID: "PG_1", After: "detail"}},
+			},
+		},
+		{
+			name: "test an admin removing a webhook",
+			paramRequest: api.AuditedRequest{
+				AuthData:  api.AuthData{Type: api.AuthTypeAdmin},
+				Method:    http.MethodDelete,
+				Route:     "/api/webhooks/:webhookID",
+				Params:    httprouter.Params{{Key: "webhookID", Value: "WH_1"}},
+				Status:    404,
+				RequestID: "RQ_2",
+			},
+			returnParams: auditservice.RecordParams{
+				ActorType: audit.ActorAdmin,
+				Method:    http.MethodDelete,
+				Route:     "/api/webhooks/:webhookID",
+				TargetID:  "WH_1",
+				Status:    404,
+				RequestID: "RQ_2",
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			auditService := new(mocks.AuditService)
+			auditService.On("Record", mock.Anything, tc.returnParams).Return(nil)
+			auditor := Auditor{AuditService: auditService}
+			err := auditor.Audit(context.Background(), tc.paramRequest)
+			require.NoError(t, err)
+			auditService.AssertNumberOfCalls(t, "Record", 1)
+		})
+	}
+}
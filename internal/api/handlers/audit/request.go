@@ -0,0 +1,76 @@
+package audithandler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/worlve/sp-service/internal/api"
+	"github.com/worlve/sp-service/internal/models/audit"
+	"github.com/julienschmidt/httprouter"
+)
+
+// GetEntriesRequest parameters from the calls for a feed of audit entries
+type GetEntriesRequest struct {
+	Filter      audit.Filter
+	NextBatchID string
+}
+
+// NewGetEntriesRequest extracts the GetEntriesRequest
+func NewGetEntriesRequest(r *http.Request, p httprouter.Params) (GetEntriesRequest, error) {
+	query := r.URL.Query()
+	request := GetEntriesRequest{
+		Filter: audit.Filter{
+			UserID:   query.Get("userId"),
+			PageID:   query.Get("pageId"),
+			TargetID: query.Get("targetId"),
+		},
+		NextBatchID: query.Get("nextBatchId"),
+	}
+	if actorType := query.Get("actorType"); actorType != "" {
+		t, err := audit.GetActorType(actorType)
+		if err != nil {
+			return request, api.InvalidField("actorType", "actorType must be admin or proxyUser")
+		}
+		request.Filter.ActorType = t
+	}
+	var err error
+	request.Filter.Since, err = parseTime(query.Get("since"), "since")
+	if err != nil {
+		return request, err
+	}
+	request.Filter.Until, err = parseTime(query.Get("until"), "until")
+	if err != nil {
+		return request, err
+	}
+	return request, nil
+}
+
+func parseTime(value, field string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, api.InvalidField(field, field+" must be an RFC 3339 time")
+	}
+	return &t, nil
+}
+
+// GetPageActivityRequest parameters from the GetPageActivity call
+type GetPageActivityRequest struct {
+	GUID string
+	GetEntriesRequest
+}
+
+// NewGetPageActivityRequest extracts the GetPageActivityRequest
+func NewGetPageActivityRequest(r *http.Request, p httprouter.Params) (GetPageActivityRequest, error) {
+	request := GetPageActivityRequest{
+		GUID: p.ByName(PageIDRouteKey),
+	}
+	if request.GUID == "" {
+		return request, api.InvalidField("id", "must provide a page id")
+	}
+	var err error
+	request.GetEntriesRequest, err = NewGetEntriesRequest(r, p)
+	return request, err
+}
@@ -0,0 +1,78 @@
+package audithandler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/worlve/sp-service/internal/api"
+)
+
+// HTTP path fragments keys
+const (
+	PageIDRouteKey = "pageID"
+)
+
+var filterQueryParams = []api.QueryParam{
+	{Name: "actorType", Description: "Only entries made as an admin or as a proxied user.", Enum: []string{"admin", "proxyUser"}},
+	{Name: "since", Description: "Only entries made at or after this RFC 3339 time."},
+	{Name: "until", Description: "Only entries made before this RFC 3339 time."},
+	{Name: "nextBatchId", Description: "If the request is batched, to get the next batch set this parameter based on the response's result.nextBatch."},
+}
+
+// AuditRouterHandlers returns the requests for the associated routes.
+func AuditRouterHandlers(apiPath string, auditService AuditService) []api.RouterHandler {
+	handler := AuditHandler{
+		AuditService: auditService,
+	}
+	var routerHandlers []api.RouterHandler
+	routerHandlers = append(routerHandlers, api.RouterHandler{
+		Method:   http.MethodGet,
+		Endpoint: fmt.Sprintf("/%v/pages/:%v/activity", apiPath, PageIDRouteKey),
+		Handle:   handler.GetPageActivity,
+		Doc: &api.RouteDoc{
+			OperationID: "getPageActivity",
+			Summary:     "Get Page Activity",
+			Description: "Get a paginated feed of the changes made to the provided page and its details, newest first. The user must be able to read the page.",
+			Tag:         "audit",
+			Query: append([]api.QueryParam{
+				{Name: "userId", Description: "Only entries made by this user."},
+				{Name: "targetId", Description: "Only entries that changed this page or detail."},
+			}, filterQueryParams...),
+			Response: EntryBatchResponse{},
+		},
+	})
+	routerHandlers = append(routerHandlers, api.RouterHandler{
+		Method:   http.MethodGet,
+		Endpoint: fmt.Sprintf("/%v/activity", apiPath),
+		Handle:   handler.GetActivity,
+		Doc: &api.RouteDoc{
+			OperationID: "getActivity",
+			Summary:     "Get Activity",
+			Description: "Get a paginated feed of the changes the user made, newest first. An admin acting as itself gets the changes made as an admin.",
+			Tag:         "audit",
+			Query: append([]api.QueryParam{
+				{Name: "pageId", Description: "Only entries for this page."},
+				{Name: "targetId", Description: "Only entries that changed this page, detail or webhook."},
+			}, filterQueryParams...),
+			Response: EntryBatchResponse{},
+		},
+	})
+	routerHandlers = append(routerHandlers, api.RouterHandler{
+		Method:   http.MethodGet,
+		Endpoint: fmt.Sprintf("/%v/audit", apiPath),
+		Handle:   handler.GetAuditLog,
+		Doc: &api.RouteDoc{
+			OperationID: "getAuditLog",
+			Summary:     "Get Audit Log",
+			Description: "Get a paginated, filtered query of every change made through the API, newest first. Admin only.",
+			Tag:         "audit",
+			Query: append([]api.QueryParam{
+				{Name: "userId", Description: "Only entries made by this user."},
+				{Name: "pageId", Description: "Only entries for this page."},
+				{Name: "targetId", Description: "Only entries that changed this page, detail or webhook."},
+			}, filterQueryParams...),
+			Response: EntryBatchResponse{},
+		},
+	})
+	return routerHandlers
+}
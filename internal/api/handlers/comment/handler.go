@@ -0,0 +1,275 @@
+package commenthandler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/worlve/sp-service/internal/api"
+	"github.com/worlve/sp-service/internal/api/handlers/nextbatch"
+	"github.com/worlve/sp-service/internal/models/comment"
+	"github.com/worlve/sp-service/internal/models/page"
+	commentservice "github.com/worlve/sp-service/internal/services/comment"
+	"github.com/julienschmidt/httprouter"
+	"github.com/pkg/errors"
+)
+
+// CommentService see Service for more details
+type CommentService interface {
+	CreateComment(ctx context.Context, params commentservice.CreateCommentParams) (comment.Comment, error)
+	CreateReply(ctx context.Context, params commentservice.CreateReplyParams) (comment.Comment, error)
+	GetThreads(ctx context.Context, params commentservice.GetThreadsParams) ([]comment.Comment, int, string, error)
+	GetReplies(ctx context.Context, params commentservice.GetRepliesParams) ([]comment.Comment, int, string, error)
+	UpdateComment(ctx context.Context, params commentservice.UpdateCommentParams) (comment.Comment, error)
+	RemoveComment(ctx context.Context, params commentservice.RemoveCommentParams) error
+	ResolveThread(ctx context.Context, params commentservice.ResolveThreadParams) (comment.Comment, error)
+	GetMentions(ctx context.Context, params commentservice.GetMentionsParams) ([]comment.Comment, int, string, error)
+}
+
+// CommentHandler is the handler for the associated API
+type CommentHandler struct {
+	CommentService CommentService
+}
+
+// CommentBatchResponse is a batch of comments, with how to get the next batch when there are more.
+type CommentBatchResponse struct {
+	Batch     []comment.Comment    `json:"batch"`
+	Total     int                  `json:"total"`
+	NextBatch *nextbatch.NextBatch `json:"nextBatch,omitempty"`
+}
+
+func newCommentBatchResponse(records []comment.Comment, total int, nextBatchID string) CommentBatchResponse {
+	responseBody := CommentBatchResponse{
+		Batch: make([]comment.Comment, 0, len(records)),
+		Total: total,
+	}
+	responseBody.Batch = append(responseBody.Batch, records...)
+	if nextBatchID != "" {
+		responseBody.NextBatch = &nextbatch.NextBatch{
+			ParamKey:   "nextBatchId",
+			ParamValue: nextBatchID,
+		}
+	}
+	return responseBody
+}
+
+// CreateComment see Service for more details
+func (h CommentHandler) CreateComment(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	request, err := NewCreateCommentRequest(r, p)
+	if err != nil {
+		api.RespondWithError(r, w, err)
+		return
+	}
+	ctx := r.Context()
+	authData, err := api.GetDataFromContext(ctx)
+	if err != nil {
+		api.RespondWith(r, w, http.StatusInternalServerError, &api.InternalErr{}, errors.Wrap(err, "failed to get auth data"))
+		return
+	}
+	record, err := h.CommentService.CreateComment(ctx, commentservice.CreateCommentParams{
+		Page: page.Page{
+			GUID: request.PageGUID,
+		},
+		Comment: comment.Comment{
+			Body:   request.Body,
+			Anchor: request.Anchor,
+		},
+		UserID: authData.UserID,
+	})
+	if err != nil {
+		api.RespondWithError(r, w, err)
+		return
+	}
+	api.RespondWith(r, w, http.StatusOK, record, nil)
+}
+
+// GetThreads see Service for more details
+func (h CommentHandler) GetThreads(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	request, err := NewGetThreadsRequest(r, p)
+	if err != nil {
+		api.RespondWithError(r, w, err)
+		return
+	}
+	ctx := r.Context()
+	authData, err := api.GetDataFromContext(ctx)
+	if err != nil {
+		api.RespondWith(r, w, http.StatusInternalServerError, &api.InternalErr{}, errors.Wrap(err, "failed to get auth data"))
+		return
+	}
+	records, total, nextBatchID, err := h.CommentService.GetThreads(ctx, commentservice.GetThreadsParams{
+		Page: page.Page{
+			GUID: request.PageGUID,
+		},
+		Filter:      request.Filter,
+		NextBatchID: request.NextBatchID,
+		UserID:      authData.UserID,
+	})
+	if err != nil {
+		api.RespondWithError(r, w, err)
+		return
+	}
+	api.RespondWith(r, w, http.StatusOK, newCommentBatchResponse(records, total, nextBatchID), nil)
+}
+
+// UpdateComment see Service for more details
+func (h CommentHandler) UpdateComment(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	request, err := NewBodyRequest(r, p)
+	if err != nil {
+		api.RespondWithError(r, w, err)
+		return
+	}
+	ctx := r.Context()
+	authData, err := api.GetDataFromContext(ctx)
+	if err != nil {
+		api.RespondWith(r, w, http.StatusInternalServerError, &api.InternalErr{}, errors.Wrap(err, "failed to get auth data"))
+		return
+	}
+	record, err := h.CommentService.UpdateComment(ctx, commentservice.UpdateCommentParams{
+		Comment: comment.Comment{
+			GUID: request.GUID,
+			Body: request.Body,
+		},
+		UserID: authData.UserID,
+	})
+	if err != nil {
+		api.RespondWithError(r, w, err)
+		return
+	}
+	api.RespondWith(r, w, http.StatusOK, record, nil)
+}
+
+// RemoveComment see Service for more details
+func (h CommentHandler) RemoveComment(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	request, err := NewCommentRequest(r, p)
+	if err != nil {
+		api.RespondWithError(r, w, err)
+		return
+	}
+	ctx := r.Context()
+	authData, err := api.GetDataFromContext(ctx)
+	if err != nil {
+		api.RespondWith(r, w, http.StatusInternalServerError, &api.InternalErr{}, errors.Wrap(err, "failed to get auth data"))
+		return
+	}
+	err = h.CommentService.RemoveComment(ctx, commentservice.RemoveCommentParams{
+		Comment: comment.Comment{
+			GUID: request.GUID,
+		},
+		UserID: authData.UserID,
+	})
+	if err != nil {
+		api.RespondWithError(r, w, err)
+		return
+	}
+	api.RespondWith(r, w, http.StatusOK, nil, nil)
+}
+
+// CreateReply see Service for more details
+func (h CommentHandler) CreateReply(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	request, err := NewBodyRequest(r, p)
+	if err != nil {
+		api.RespondWithError(r, w, err)
+		return
+	}
+	ctx := r.Context()
+	authData, err := api.GetDataFromContext(ctx)
+	if err != nil {
+		api.RespondWith(r, w, http.StatusInternalServerError, &api.InternalErr{}, errors.Wrap(err, "failed to get auth data"))
+		return
+	}
+	record, err := h.CommentService.CreateReply(ctx, commentservice.CreateReplyParams{
+		Comment: comment.Comment{
+			GUID: request.GUID,
+		},
+		Reply: comment.Comment{
+			Body: request.Body,
+		},
+		UserID: authData.UserID,
+	})
+	if err != nil {
+		api.RespondWithError(r, w, err)
+		return
+	}
+	api.RespondWith(r, w, http.StatusOK, record, nil)
+}
+
+// GetReplies see Service for more details
+func (h CommentHandler) GetReplies(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	request, err := NewGetRepliesRequest(r, p)
+	if err != nil {
+		api.RespondWithError(r, w, err)
+		return
+	}
+	ctx := r.Context()
+	authData, err := api.GetDataFromContext(ctx)
+	if err != nil {
+		api.RespondWith(r, w, http.StatusInternalServerError, &api.InternalErr{}, errors.Wrap(err, "failed to get auth data"))
+		return
+	}
+	records, total, nextBatchID, err := h.CommentService.GetReplies(ctx, commentservice.GetRepliesParams{
+		Comment: comment.Comment{
+			GUID: request.GUID,
+		},
+		NextBatchID: request.NextBatchID,
+		UserID:      authData.UserID,
+	})
+	if err != nil {
+		api.RespondWithError(r, w, err)
+		return
+	}
+	api.RespondWith(r, w, http.StatusOK, newCommentBatchResponse(records, total, nextBatchID), nil)
+}
+
+// ResolveThread see Service for more details
+func (h CommentHandler) ResolveThread(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	h.resolveThread(w, r, p, true)
+}
+
+// ReopenThread see Service for more details
+func (h CommentHandler) ReopenThread(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	h.resolveThread(w, r, p, false)
+}
+
+func (h CommentHandler) resolveThread(w http.ResponseWriter, r *http.Request, p httprouter.Params, resolved bool) {
+	request, err := NewCommentRequest(r, p)
+	if err != nil {
+		api.RespondWithError(r, w, err)
+		return
+	}
+	ctx := r.Context()
+	authData, err := api.GetDataFromContext(ctx)
+	if err != nil {
+		api.RespondWith(r, w, http.StatusInternalServerError, &api.InternalErr{}, errors.Wrap(err, "failed to get auth data"))
+		return
+	}
+	record, err := h.CommentService.ResolveThread(ctx, commentservice.ResolveThreadParams{
+		Comment: comment.Comment{
+			GUID: request.GUID,
+		},
+		Resolved: resolved,
+		UserID:   authData.UserID,
+	})
+	if err != nil {
+		api.RespondWithError(r, w, err)
+		return
+	}
+	api.RespondWith(r, w, http.StatusOK, record, nil)
+}
+
+// GetMentions see Service for more details
+func (h CommentHandler) GetMentions(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	ctx := r.Context()
+	authData, err := api.GetDataFromContext(ctx)
+	if err != nil {
+		api.RespondWith(r, w, http.StatusInternalServerError, &api.InternalErr{}, errors.Wrap(err, "failed to get auth data"))
+		return
+	}
+	records, total, nextBatchID, err := h.CommentService.GetMentions(ctx, commentservice.GetMentionsParams{
+		NextBatchID: r.URL.Query().Get("nextBatchId"),
+		UserID:      authData.UserID,
+	})
+	if err != nil {
+		api.RespondWithError(r, w, err)
+		return
+	}
+	api.RespondWith(r, w, http.StatusOK, newCommentBatchResponse(records, total, nextBatchID), nil)
+}
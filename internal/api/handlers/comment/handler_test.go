@@ -0,0 +1,341 @@
+package commenthandler
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/worlve/sp-service/internal/api/handlers/comment/mocks"
+	"github.com/worlve/sp-service/internal/api/handlers/handlertestutils"
+	"github.com/worlve/sp-service/internal/models/comment"
+	"github.com/worlve/sp-service/internal/models/page"
+	commentservice "github.com/worlve/sp-service/internal/services/comment"
+	"github.com/worlve/sp-service/internal/stores/storeerror"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var createdAt = time.Date(2020, 3, 31, 12, 0, 0, 0, time.UTC)
+
+var thread = comment.Comment{
+	GUID:      "CM_1",
+	PageGUID:  "PG_1",
+	UserID:    "UR_1",
+	Anchor:    &comment.Anchor{DetailID: "PD_1"},
+	Body:      "thoughts? @UR_2",
+	Mentions:  []string{"UR_2"},
+	CreatedAt: &createdAt,
+	UpdatedAt: &createdAt,
+}
+
+const threadJSON = "{\"id\":\"CM_1\",\"pageId\":\"PG_1\",\"userId\":\"UR_1\",\"anchor\":{\"detailId\":\"PD_1\"},\"body\":\"thoughts? @UR_2\",\"mentions\":[\"UR_2\"],\"createdAt\":\"2020-03-31T12:00:00Z\",\"updatedAt\":\"2020-03-31T12:00:00Z\"}"
+
+type createCommentCall struct {
+	params        commentservice.CreateCommentParams
+	returnComment comment.Comment
+	returnErr     error
+}
+
+func TestCreateComment(t *testing.T) {
+	cases := []struct {
+		name                 string
+		requestBody          string
+		expectedResponseBody string
+		expectedStatusCode   int
+		createCommentCalls   []createCommentCall
+	}{
+		{
+			name:                 "happy path",
+			requestBody:          "{\"pageId\":\"PG_1\",\"body\":\"thoughts? @UR_2\",\"anchor\":{\"detailId\":\"PD_1\"}}",
+			expectedResponseBody: "{\"result\":" + threadJSON + ",\"meta\":{\"httpStatus\":\"200 - OK\",\"requestId\":\"RQ_TEST\"}}\n",
+			expectedStatusCode:   200,
+			createCommentCalls: []createCommentCall{
+				{
+					params: commentservice.CreateCommentParams{
+						Page:    page.Page{GUID: "PG_1"},
+						Comment: comment.Comment{Body: "thoughts? @UR_2", Anchor: &comment.Anchor{DetailID: "PD_1"}},
+						UserID:  "UR_1",
+					},
+					returnComment: thread,
+				},
+			},
+		},
+		{
+			name:                 "blank body",
+			requestBody:          "{\"pageId\":\"PG_1\",\"body\":\"  \"}",
+			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"400 - Bad Request\",\"code\":\"VALIDATION_FAILED\",\"message\":\"must provide body\",\"details\":[{\"field\":\"body\",\"message\":\"must provide body\"}],\"requestId\":\"RQ_TEST\"}}\n",
+			expectedStatusCode:   400,
+		},
+		{
+			name:                 "body that's too long",
+			requestBody:          "{\"pageId\":\"PG_1\",\"body\":\"" + strings.Repeat("é", comment.MaxBodyLength+1) + "\"}",
+			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"400 - Bad Request\",\"code\":\"VALIDATION_FAILED\",\"message\":\"body must be at most 10000 characters\",\"details\":[{\"field\":\"body\",\"message\":\"body must be at most 10000 characters\"}],\"requestId\":\"RQ_TEST\"}}\n",
+			expectedStatusCode:   400,
+		},
+		{
+			name:                 "anchor without a detail",
+			requestBody:          "{\"pageId\":\"PG_1\",\"body\":\"hi\",\"anchor\":{\"partitionId\":\"P_1\"}}",
+			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"400 - Bad Request\",\"code\":\"VALIDATION_FAILED\",\"message\":\"must provide anchor.detailId to anchor a comment\",\"details\":[{\"field\":\"anchor.detailId\",\"message\":\"must provide anchor.detailId to anchor a comment\"}],\"requestId\":\"RQ_TEST\"}}\n",
+			expectedStatusCode:   400,
+		},
+		{
+			name:                 "anchor to a detail of another page",
+			requestBody:          "{\"pageId\":\"PG_1\",\"body\":\"hi\",\"anchor\":{\"detailId\":\"PD_9\"}}",
+			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"400 - Bad Request\",\"code\":\"VALIDATION_FAILED\",\"message\":\"anchor.detailId PD_9 is not a detail of the page\",\"details\":[{\"field\":\"anchor\",\"message\":\"anchor.detailId PD_9 is not a detail of the page\"}],\"requestId\":\"RQ_TEST\"}}\n",
+			expectedStatusCode:   400,
+			createCommentCalls: []createCommentCall{
+				{
+					params: commentservice.CreateCommentParams{
+						Page:    page.Page{GUID: "PG_1"},
+						Comment: comment.Comment{Body: "hi", Anchor: &comment.Anchor{DetailID: "PD_9"}},
+						UserID:  "UR_1",
+					},
+					returnErr: &storeerror.InvalidField{Field: "anchor", Message: "anchor.detailId PD_9 is not a detail of the page"},
+				},
+			},
+		},
+		{
+			name:                 "no page",
+			requestBody:          "{\"pageId\":\"\",\"body\":\"hi\"}",
+			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"400 - Bad Request\",\"code\":\"VALIDATION_FAILED\",\"message\":\"must provide pageId\",\"details\":[{\"field\":\"pageId\",\"message\":\"must provide pageId\"}],\"requestId\":\"RQ_TEST\"}}\n",
+			expectedStatusCode:   400,
+		},
+		{
+			name:                 "someone else's private page",
+			requestBody:          "{\"pageId\":\"PG_1\",\"body\":\"hi\"}",
+			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"403 - Forbidden\",\"code\":\"FORBIDDEN\",\"message\":\"not authorized\",\"requestId\":\"RQ_TEST\"}}\n",
+			expectedStatusCode:   403,
+			createCommentCalls: []createCommentCall{
+				{
+					params: commentservice.CreateCommentParams{
+						Page:    page.Page{GUID: "PG_1"},
+						Comment: comment.Comment{Body: "hi"},
+						UserID:  "UR_1",
+					},
+					returnErr: &storeerror.NotAuthorized{UserID: "UR_1", TableID: "PG_1"},
+				},
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			commentService := new(mocks.CommentService)
+			for index := range tc.createCommentCalls {
+				call := tc.createCommentCalls[index]
+				commentService.On("CreateComment", mock.Anything, call.params).Return(call.returnComment, call.returnErr)
+			}
+			authZ := handlertestutils.DefaultAuthZ()
+			resp, respBody := handlertestutils.HandleTestRequest(handlertestutils.HandleTestRequestParams{
+				Method:         http.MethodPost,
+				Endpoint:       "comments",
+				Headers:        map[string]string{"X-USER-ID": "UR_1"},
+				Body:           strings.NewReader(tc.requestBody),
+				RouterHandlers: CommentRouterHandlers(authZ.APIPath, commentService),
+				AuthZ:          authZ,
+				AuthN:          handlertestutils.DefaultAuthN("LOCAL"),
+			})
+			require.Equal(t, tc.expectedResponseBody, respBody)
+			require.Equal(t, tc.expectedStatusCode, resp.StatusCode)
+			commentService.AssertNumberOfCalls(t, "CreateComment", len(tc.createCommentCalls))
+		})
+	}
+}
+
+type getThreadsCall struct {
+	params        commentservice.GetThreadsParams
+	returnThreads []comment.Comment
+	returnTotal   int
+	returnNext    string
+}
+
+func TestGetThreads(t *testing.T) {
+	resolved := false
+	cases := []struct {
+		name                 string
+		params               url.Values
+		expectedResponseBody string
+		expectedStatusCode   int
+		getThreadsCalls      []getThreadsCall
+	}{
+		{
+			name:                 "happy path",
+			params:               url.Values{"detailId": []string{"PD_1"}, "resolved": []string{"false"}, "nextBatchId": []string{"CM_2"}},
+			expectedResponseBody: "{\"result\":{\"batch\":[" + threadJSON + "],\"total\":3,\"nextBatch\":{\"paramKey\":\"nextBatchId\",\"paramValue\":\"CM_0\"}},\"meta\":{\"httpStatus\":\"200 - OK\",\"requestId\":\"RQ_TEST\"}}\n",
+			expectedStatusCode:   200,
+			getThreadsCalls: []getThreadsCall{
+				{
+					params: commentservice.GetThreadsParams{
+						Page:        page.Page{GUID: "PG_1"},
+						Filter:      comment.Filter{DetailID: "PD_1", Resolved: &resolved},
+						NextBatchID: "CM_2",
+						UserID:      "UR_1",
+					},
+					returnThreads: []comment.Comment{thread},
+					returnTotal:   3,
+					returnNext:    "CM_0",
+				},
+			},
+		},
+		{
+			name:                 "no threads",
+			expectedResponseBody: "{\"result\":{\"batch\":[],\"total\":0},\"meta\":{\"httpStatus\":\"200 - OK\",\"requestId\":\"RQ_TEST\"}}\n",
+			expectedStatusCode:   200,
+			getThreadsCalls: []getThreadsCall{
+				{
+					params: commentservice.GetThreadsParams{Page: page.Page{GUID: "PG_1"}, UserID: "UR_1"},
+				},
+			},
+		},
+		{
+			name:                 "invalid resolved",
+			params:               url.Values{"resolved": []string{"yes"}},
+			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"400 - Bad Request\",\"code\":\"VALIDATION_FAILED\",\"message\":\"request does not match the API spec: resolved: must be one of: true, false\",\"details\":[{\"field\":\"resolved\",\"message\":\"must be one of: true, false\"}],\"requestId\":\"RQ_TEST\"}}\n",
+			expectedStatusCode:   400,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			commentService := new(mocks.CommentService)
+			for index := range tc.getThreadsCalls {
+				call := tc.getThreadsCalls[index]
+				commentService.On("GetThreads", mock.Anything, call.params).Return(call.returnThreads, call.returnTotal, call.returnNext, nil)
+			}
+			authZ := handlertestutils.DefaultAuthZ()
+			resp, respBody := handlertestutils.HandleTestRequest(handlertestutils.HandleTestRequestParams{
+				Method:         http.MethodGet,
+				Endpoint:       "pages/PG_1/comments",
+				Params:         tc.params,
+				Headers:        map[string]string{"X-USER-ID": "UR_1"},
+				RouterHandlers: CommentRouterHandlers(authZ.APIPath, commentService),
+				AuthZ:          authZ,
+				AuthN:          handlertestutils.DefaultAuthN("LOCAL"),
+			})
+			require.Equal(t, tc.expectedResponseBody, respBody)
+			require.Equal(t, tc.expectedStatusCode, resp.StatusCode)
+			commentService.AssertNumberOfCalls(t, "GetThreads", len(tc.getThreadsCalls))
+		})
+	}
+}
+
+func TestUpdateComment(t *testing.T) {
+	commentService := new(mocks.CommentService)
+	commentService.On("UpdateComment", mock.Anything, commentservice.UpdateCommentParams{
+		Comment: comment.Comment{GUID: "CM_1", Body: "edited"},
+		UserID:  "UR_2",
+	}).Return(comment.Comment{}, &storeerror.NotAuthorized{UserID: "UR_2", TableID: "CM_1"})
+	authZ := handlertestutils.DefaultAuthZ()
+	resp, respBody := handlertestutils.HandleTestRequest(handlertestutils.HandleTestRequestParams{
+		Method:         http.MethodPatch,
+		Endpoint:       "comments/CM_1",
+		Headers:        map[string]string{"X-USER-ID": "UR_2"},
+		Body:           strings.NewReader("{\"body\":\"edited\"}"),
+		RouterHandlers: CommentRouterHandlers(authZ.APIPath, commentService),
+		AuthZ:          authZ,
+		AuthN:          handlertestutils.DefaultAuthN("LOCAL"),
+	})
+	require.Equal(t, "{\"meta\":{\"httpStatus\":\"403 - Forbidden\",\"code\":\"FORBIDDEN\",\"message\":\"not authorized\",\"requestId\":\"RQ_TEST\"}}\n", respBody)
+	require.Equal(t, 403, resp.StatusCode)
+}
+
+func TestRemoveComment(t *testing.T) {
+	commentService := new(mocks.CommentService)
+	commentService.On("RemoveComment", mock.Anything, commentservice.RemoveCommentParams{
+		Comment: comment.Comment{GUID: "CM_9"},
+		UserID:  "UR_1",
+	}).Return(&storeerror.NotFound{ID: "CM_9"})
+	authZ := handlertestutils.DefaultAuthZ()
+	resp, respBody := handlertestutils.HandleTestRequest(handlertestutils.HandleTestRequestParams{
+		Method:         http.MethodDelete,
+		Endpoint:       "comments/CM_9",
+		Headers:        map[string]string{"X-USER-ID": "UR_1"},
+		RouterHandlers: CommentRouterHandlers(authZ.APIPath, commentService),
+		AuthZ:          authZ,
+		AuthN:          handlertestutils.DefaultAuthN("LOCAL"),
+	})
+	require.Equal(t, "{\"meta\":{\"httpStatus\":\"404 - Not Found\",\"code\":\"NOT_FOUND\",\"message\":\"not found\",\"requestId\":\"RQ_TEST\"}}\n", respBody)
+	require.Equal(t, 404, resp.StatusCode)
+}
+
+func TestCreateReply(t *testing.T) {
+	reply := comment.Comment{GUID: "CM_2", PageGUID: "PG_1", ThreadGUID: "CM_1", UserID: "UR_2", Body: "agreed", Mentions: []string{}, CreatedAt: &createdAt, UpdatedAt: &createdAt}
+	commentService := new(mocks.CommentService)
+	commentService.On("CreateReply", mock.Anything, commentservice.CreateReplyParams{
+		Comment: comment.Comment{GUID: "CM_1"},
+		Reply:   comment.Comment{Body: "agreed"},
+		UserID:  "UR_2",
+	}).Return(reply, nil)
+	authZ := handlertestutils.DefaultAuthZ()
+	resp, respBody := handlertestutils.HandleTestRequest(handlertestutils.HandleTestRequestParams{
+		Method:         http.MethodPost,
+		Endpoint:       "comments/CM_1/replies",
+		Headers:        map[string]string{"X-USER-ID": "UR_2"},
+		Body:           strings.NewReader("{\"body\":\"agreed\"}"),
+		RouterHandlers: CommentRouterHandlers(authZ.APIPath, commentService),
+		AuthZ:          authZ,
+		AuthN:          handlertestutils.DefaultAuthN("LOCAL"),
+	})
+	require.Equal(t, "{\"result\":{\"id\":\"CM_2\",\"pageId\":\"PG_1\",\"threadId\":\"CM_1\",\"userId\":\"UR_2\",\"body\":\"agreed\",\"mentions\":[],\"createdAt\":\"2020-03-31T12:00:00Z\",\"updatedAt\":\"2020-03-31T12:00:00Z\"},\"meta\":{\"httpStatus\":\"200 - OK\",\"requestId\":\"RQ_TEST\"}}\n", respBody)
+	require.Equal(t, 200, resp.StatusCode)
+}
+
+func TestResolveThread(t *testing.T) {
+	cases := []struct {
+		name          string
+		endpoint      string
+		paramResolved bool
+	}{
+		{
+			name:          "resolve",
+			endpoint:      "comments/CM_1/resolve",
+			paramResolved: true,
+		},
+		{
+			name:     "reopen",
+			endpoint: "comments/CM_1/reopen",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			commentService := new(mocks.CommentService)
+			commentService.On("ResolveThread", mock.Anything, commentservice.ResolveThreadParams{
+				Comment:  comment.Comment{GUID: "CM_1"},
+				Resolved: tc.paramResolved,
+				UserID:   "UR_1",
+			}).Return(thread, nil)
+			authZ := handlertestutils.DefaultAuthZ()
+			resp, respBody := handlertestutils.HandleTestRequest(handlertestutils.HandleTestRequestParams{
+				Method:         http.MethodPost,
+				Endpoint:       tc.endpoint,
+				Headers:        map[string]string{"X-USER-ID": "UR_1"},
+				RouterHandlers: CommentRouterHandlers(authZ.APIPath, commentService),
+				AuthZ:          authZ,
+				AuthN:          handlertestutils.DefaultAuthN("LOCAL"),
+			})
+			require.Equal(t, "{\"result\":"+threadJSON+",\"meta\":{\"httpStatus\":\"200 - OK\",\"requestId\":\"RQ_TEST\"}}\n", respBody)
+			require.Equal(t, 200, resp.StatusCode)
+			commentService.AssertNumberOfCalls(t, "ResolveThread", 1)
+		})
+	}
+}
+
+func TestGetMentions(t *testing.T) {
+	commentService := new(mocks.CommentService)
+	commentService.On("GetMentions", mock.Anything, commentservice.GetMentionsParams{
+		NextBatchID: "CM_5",
+		UserID:      "UR_2",
+	}).Return([]comment.Comment{thread}, 1, "", nil)
+	authZ := handlertestutils.DefaultAuthZ()
+	resp, respBody := handlertestutils.HandleTestRequest(handlertestutils.HandleTestRequestParams{
+		Method:         http.MethodGet,
+		Endpoint:       "mentions",
+		Params:         url.Values{"nextBatchId": []string{"CM_5"}},
+		Headers:        map[string]string{"X-USER-ID": "UR_2"},
+		RouterHandlers: CommentRouterHandlers(authZ.APIPath, commentService),
+		AuthZ:          authZ,
+		AuthN:          handlertestutils.DefaultAuthN("LOCAL"),
+	})
+	require.Equal(t, "{\"result\":{\"batch\":["+threadJSON+"],\"total\":1},\"meta\":{\"httpStatus\":\"200 - OK\",\"requestId\":\"RQ_TEST\"}}\n", respBody)
+	require.Equal(t, 200, resp.StatusCode)
+}
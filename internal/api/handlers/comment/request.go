@@ -0,0 +1,147 @@
+package commenthandler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/worlve/sp-service/internal/api"
+	"github.com/worlve/sp-service/internal/models/comment"
+	"github.com/julienschmidt/httprouter"
+)
+
+// CommentRequest parameters from the calls on a single comment
+type CommentRequest struct {
+	GUID string
+}
+
+// NewCommentRequest extracts the CommentRequest
+func NewCommentRequest(r *http.Request, p httprouter.Params) (CommentRequest, error) {
+	var request CommentRequest
+	request.GUID = p.ByName(CommentIDRouteKey)
+	return request.validate()
+}
+
+func (request CommentRequest) validate() (CommentRequest, error) {
+	if request.GUID == "" {
+		return request, api.InvalidField("id", "must provide a comment id")
+	}
+	return request, nil
+}
+
+// CreateCommentRequest parameters from the CreateComment call
+type CreateCommentRequest struct {
+	PageGUID string          `json:"pageId"`
+	Body     string          `json:"body"`
+	Anchor   *comment.Anchor `json:"anchor"`
+}
+
+// NewCreateCommentRequest extracts the CreateCommentRequest
+func NewCreateCommentRequest(r *http.Request, p httprouter.Params) (CreateCommentRequest, error) {
+	var request CreateCommentRequest
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		return request, api.InvalidRequest("invalid request")
+	}
+	return request.validate()
+}
+
+func (request CreateCommentRequest) validate() (CreateCommentRequest, error) {
+	if request.PageGUID == "" {
+		return request, api.InvalidField("pageId", "must provide pageId")
+	}
+	err := validateBody(request.Body)
+	if err != nil {
+		return request, err
+	}
+	if request.Anchor != nil && request.Anchor.DetailID == "" {
+		return request, api.InvalidField("anchor.detailId", "must provide anchor.detailId to anchor a comment")
+	}
+	return request, nil
+}
+
+// BodyRequest parameters from the calls that write a comment on an existing one: UpdateComment and CreateReply
+type BodyRequest struct {
+	GUID string `json:"-"`
+	Body string `json:"body"`
+}
+
+// NewBodyRequest extracts the BodyRequest
+func NewBodyRequest(r *http.Request, p httprouter.Params) (BodyRequest, error) {
+	var request BodyRequest
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		return request, api.InvalidRequest("invalid request")
+	}
+	request.GUID = p.ByName(CommentIDRouteKey)
+	return request.validate()
+}
+
+func (request BodyRequest) validate() (BodyRequest, error) {
+	_, err := CommentRequest{GUID: request.GUID}.validate()
+	if err != nil {
+		return request, err
+	}
+	return request, validateBody(request.Body)
+}
+
+func validateBody(body string) error {
+	if strings.TrimSpace(body) == "" {
+		return api.InvalidField("body", "must provide body")
+	}
+	if utf8.RuneCountInString(body) > comment.MaxBodyLength {
+		return api.InvalidField("body", fmt.Sprintf("body must be at most %v characters", comment.MaxBodyLength))
+	}
+	return nil
+}
+
+// GetThreadsRequest parameters from the GetThreads call
+type GetThreadsRequest struct {
+	PageGUID    string
+	Filter      comment.Filter
+	NextBatchID string
+}
+
+// NewGetThreadsRequest extracts the GetThreadsRequest
+func NewGetThreadsRequest(r *http.Request, p httprouter.Params) (GetThreadsRequest, error) {
+	query := r.URL.Query()
+	request := GetThreadsRequest{
+		PageGUID: p.ByName(PageIDRouteKey),
+		Filter: comment.Filter{
+			DetailID: query.Get("detailId"),
+		},
+		NextBatchID: query.Get("nextBatchId"),
+	}
+	if request.PageGUID == "" {
+		return request, api.InvalidField("id", "must provide a page id")
+	}
+	switch query.Get("resolved") {
+	case "":
+	case "true":
+		resolved := true
+		request.Filter.Resolved = &resolved
+	case "false":
+		resolved := false
+		request.Filter.Resolved = &resolved
+	default:
+		return request, api.InvalidField("resolved", "resolved must be true or false")
+	}
+	return request, nil
+}
+
+// GetRepliesRequest parameters from the GetReplies call
+type GetRepliesRequest struct {
+	CommentRequest
+	NextBatchID string
+}
+
+// NewGetRepliesRequest extracts the GetRepliesRequest
+func NewGetRepliesRequest(r *http.Request, p httprouter.Params) (GetRepliesRequest, error) {
+	commentRequest, err := NewCommentRequest(r, p)
+	return GetRepliesRequest{
+		CommentRequest: commentRequest,
+		NextBatchID:    r.URL.Query().Get("nextBatchId"),
+	}, err
+}
@@ -0,0 +1,148 @@
+package commenthandler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/worlve/sp-service/internal/api"
+	"github.com/worlve/sp-service/internal/models/comment"
+)
+
+// HTTP path fragments keys
+const (
+	PageIDRouteKey    = "pageID"
+	CommentIDRouteKey = "commentID"
+)
+
+var nextBatchIDQueryParam = api.QueryParam{
+	Name:        "nextBatchId",
+	Description: "If the request is batched, to get the next batch set this parameter based on the response's result.nextBatch.",
+}
+
+// CommentRouterHandlers returns the requests for the associated routes.
+func CommentRouterHandlers(apiPath string, commentService CommentService) []api.RouterHandler {
+	handler := CommentHandler{
+		CommentService: commentService,
+	}
+	// The routes on a single comment aren't under /pages/:pageID, since POST /pages:action takes every POST under /pages.
+	commentPath := fmt.Sprintf("/%v/comments/:%v", apiPath, CommentIDRouteKey)
+	var routerHandlers []api.RouterHandler
+	routerHandlers = append(routerHandlers, api.RouterHandler{
+		Method:   http.MethodPost,
+		Endpoint: fmt.Sprintf("/%v/comments", apiPath),
+		Handle:   handler.CreateComment,
+		Doc: &api.RouteDoc{
+			OperationID: "createComment",
+			Summary:     "Create Comment",
+			Description: "Starts a thread on the provided page, optionally anchored to one of its details, or to a partition of the detail by the partition's id. Users mentioned as @UR_... who can read the page are recorded in the comment's mentions. The user must be able to read the page.",
+			Tag:         "comment",
+			Request:     CreateCommentRequest{},
+			Response:    comment.Comment{},
+		},
+	})
+	routerHandlers = append(routerHandlers, api.RouterHandler{
+		Method:   http.MethodGet,
+		Endpoint: fmt.Sprintf("/%v/pages/:%v/comments", apiPath, PageIDRouteKey),
+		Handle:   handler.GetThreads,
+		Doc: &api.RouteDoc{
+			OperationID: "getThreads",
+			Summary:     "Get Threads",
+			Description: "Get a paginated list of the provided page's threads, newest first. The user must be able to read the page.",
+			Tag:         "comment",
+			Query: []api.QueryParam{
+				{Name: "detailId", Description: "Only threads anchored to this detail."},
+				{Name: "resolved", Description: "Only resolved threads, or only open ones.", Enum: []string{"true", "false"}},
+				nextBatchIDQueryParam,
+			},
+			Response: CommentBatchResponse{},
+		},
+	})
+	routerHandlers = append(routerHandlers, api.RouterHandler{
+		Method:   http.MethodPatch,
+		Endpoint: commentPath,
+		Handle:   handler.UpdateComment,
+		Doc: &api.RouteDoc{
+			OperationID: "updateComment",
+			Summary:     "Update Comment",
+			Description: "Sets the body of the user's own comment; its mentions are recomputed from the new body. The user must still be able to read the page.",
+			Tag:         "comment",
+			Request:     BodyRequest{},
+			Response:    comment.Comment{},
+		},
+	})
+	routerHandlers = append(routerHandlers, api.RouterHandler{
+		Method:   http.MethodDelete,
+		Endpoint: commentPath,
+		Handle:   handler.RemoveComment,
+		Doc: &api.RouteDoc{
+			OperationID: "removeComment",
+			Summary:     "Remove Comment",
+			Description: "Permanently deletes the provided comment, along with its replies if it starts a thread. Only its author or the page's owner can remove it.",
+			Tag:         "comment",
+		},
+	})
+	routerHandlers = append(routerHandlers, api.RouterHandler{
+		Method:   http.MethodPost,
+		Endpoint: fmt.Sprintf("%v/replies", commentPath),
+		Handle:   handler.CreateReply,
+		Doc: &api.RouteDoc{
+			OperationID: "createReply",
+			Summary:     "Create Reply",
+			Description: "Replies to the thread the provided comment is in; replying to a reply adds to the same thread. The user must be able to read the page.",
+			Tag:         "comment",
+			Request:     BodyRequest{},
+			Response:    comment.Comment{},
+		},
+	})
+	routerHandlers = append(routerHandlers, api.RouterHandler{
+		Method:   http.MethodGet,
+		Endpoint: fmt.Sprintf("%v/replies", commentPath),
+		Handle:   handler.GetReplies,
+		Doc: &api.RouteDoc{
+			OperationID: "getReplies",
+			Summary:     "Get Replies",
+			Description: "Get a paginated list of the replies in the thread the provided comment is in, oldest first. The user must be able to read the page.",
+			Tag:         "comment",
+			Query:       []api.QueryParam{nextBatchIDQueryParam},
+			Response:    CommentBatchResponse{},
+		},
+	})
+	routerHandlers = append(routerHandlers, api.RouterHandler{
+		Method:   http.MethodPost,
+		Endpoint: fmt.Sprintf("%v/resolve", commentPath),
+		Handle:   handler.ResolveThread,
+		Doc: &api.RouteDoc{
+			OperationID: "resolveThread",
+			Summary:     "Resolve Thread",
+			Description: "Resolves the thread the provided comment is in, and returns the thread. Only the thread's author or the page's owner can resolve it.",
+			Tag:         "comment",
+			Response:    comment.Comment{},
+		},
+	})
+	routerHandlers = append(routerHandlers, api.RouterHandler{
+		Method:   http.MethodPost,
+		Endpoint: fmt.Sprintf("%v/reopen", commentPath),
+		Handle:   handler.ReopenThread,
+		Doc: &api.RouteDoc{
+			OperationID: "reopenThread",
+			Summary:     "Reopen Thread",
+			Description: "Reopens the resolved thread the provided comment is in, and returns the thread. Only the thread's author or the page's owner can reopen it.",
+			Tag:         "comment",
+			Response:    comment.Comment{},
+		},
+	})
+	routerHandlers = append(routerHandlers, api.RouterHandler{
+		Method:   http.MethodGet,
+		Endpoint: fmt.Sprintf("/%v/mentions", apiPath),
+		Handle:   handler.GetMentions,
+		Doc: &api.RouteDoc{
+			OperationID: "getMentions",
+			Summary:     "Get Mentions",
+			Description: "Get a paginated list of the comments that mention the user, newest first. Comments on pages the user can no longer read are left out of the batch.",
+			Tag:         "comment",
+			Query:       []api.QueryParam{nextBatchIDQueryParam},
+			Response:    CommentBatchResponse{},
+		},
+	})
+	return routerHandlers
+}
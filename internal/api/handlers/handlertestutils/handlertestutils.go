@@ -10,8 +10,13 @@ import (
 	"net/url"
 
 	"github.com/worlve/sp-service/internal/api"
+	"github.com/worlve/sp-service/internal/util/openapi"
+	"github.com/worlve/sp-service/internal/util/transaction"
 )
 
+// TestRequestID is the request ID sent with each test request, so it's the same in every response.
+const TestRequestID = "RQ_TEST"
+
 // HandleTestRequestParams are the params for the HandleTestRequest function.
 type HandleTestRequestParams struct {
 	Method         string
@@ -33,6 +38,10 @@ func HandleTestRequest(p HandleTestRequestParams) (*http.Response, string) {
 		Router:     router,
 		Datacenter: p.AuthN.Datacenter,
 		APIPath:    p.AuthZ.APIPath,
+		SpecValidator: &api.SpecValidator{
+			Spec:   loadSpec(router),
+			Strict: true,
+		},
 	}
 	uri := fmt.Sprintf("http://test.com/%v/%v", p.AuthZ.APIPath, p.Endpoint)
 	params := p.Params.Encode()
@@ -40,6 +49,7 @@ func HandleTestRequest(p HandleTestRequestParams) (*http.Response, string) {
 		uri = uri + "?" + params
 	}
 	r := httptest.NewRequest(p.Method, uri, p.Body)
+	r.Header.Set(transaction.RequestIDHeaderKey, TestRequestID)
 	for key, value := range p.Headers {
 		r.Header.Set(key, value)
 	}
@@ -50,6 +60,15 @@ func HandleTestRequest(p HandleTestRequestParams) (*http.Response, string) {
 	return resp, string(respBody)
 }
 
+// loadSpec returns the spec generated from the router's routes, so every test request and response is checked against it.
+func loadSpec(router api.Router) *openapi.Spec {
+	spec, err := openapi.New(router.OpenAPI)
+	if err != nil {
+		panic(err)
+	}
+	return spec
+}
+
 // DefaultAuthN is a quick way to pass in the AuthN struct to a test
 func DefaultAuthN(datacenter string) api.AuthN {
 	return api.AuthN{
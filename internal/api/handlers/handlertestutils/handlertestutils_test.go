@@ -28,7 +28,7 @@ func TestAPI(t *testing.T) {
 			endpoint:             "doesnotexist",
 			authN:                DefaultAuthN("LOCAL"),
 			authZ:                DefaultAuthZ(),
-			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"404 - Not Found\",\"message\":\"not found\"}}\n",
+			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"404 - Not Found\",\"code\":\"NOT_FOUND\",\"message\":\"not found\",\"requestId\":\"RQ_TEST\"}}\n",
 			expectedStatusCode:   404,
 		},
 		{
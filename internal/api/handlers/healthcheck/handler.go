@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"github.com/worlve/sp-service/internal/api"
+	healthcheckservice "github.com/worlve/sp-service/internal/services/healthcheck"
 	"github.com/julienschmidt/httprouter"
 	"github.com/pkg/errors"
 )
@@ -17,6 +18,12 @@ type HealthcheckHandler struct {
 // HealthcheckService see Service for more details
 type HealthcheckService interface {
 	IsHealthy(ctx context.Context) (bool, error)
+	Ready(ctx context.Context) healthcheckservice.ReadinessReport
+}
+
+// StatusResponse is the result of the healthcheck and liveness calls.
+type StatusResponse struct {
+	Status string `json:"status"`
 }
 
 // IsHealthy see Service for more details
@@ -28,7 +35,7 @@ func (h HealthcheckHandler) IsHealthy(w http.ResponseWriter, r *http.Request, p
 		return
 	}
 	if !authData.IsAdmin() {
-		api.RespondWith(r, w, http.StatusUnauthorized, &api.InternalErr{}, errors.Wrapf(err, "user not authorized for healthcheck: %v", authData.UserID))
+		api.RespondWith(r, w, http.StatusForbidden, &api.FailedAuthorization{}, errors.Errorf("user not authorized for healthcheck: %v", authData.UserID))
 		return
 	}
 	isHealthy, err := h.HealthcheckService.IsHealthy(ctx)
@@ -40,5 +47,20 @@ func (h HealthcheckHandler) IsHealthy(w http.ResponseWriter, r *http.Request, p
 	if !isHealthy {
 		statusString = "error"
 	}
-	api.RespondWith(r, w, http.StatusOK, map[string]string{"status": statusString}, nil)
+	api.RespondWith(r, w, http.StatusOK, StatusResponse{Status: statusString}, nil)
+}
+
+// IsLive responds ok as long as the server can handle requests.
+func (h HealthcheckHandler) IsLive(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	api.RespondWith(r, w, http.StatusOK, StatusResponse{Status: healthcheckservice.StatusOK}, nil)
+}
+
+// IsReady responds with the status of each dependency, and a 503 unless every one is ok and the server isn't draining.
+func (h HealthcheckHandler) IsReady(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	report := h.HealthcheckService.Ready(r.Context())
+	if !report.IsReady() {
+		api.RespondWith(r, w, http.StatusServiceUnavailable, report, nil)
+		return
+	}
+	api.RespondWith(r, w, http.StatusOK, report, nil)
 }
@@ -1,8 +1,9 @@
 package healthcheckhandler
 
 import (
-	"fmt"
+	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -10,6 +11,8 @@ import (
 	"github.com/worlve/sp-service/internal/api"
 	"github.com/worlve/sp-service/internal/api/handlers/handlertestutils"
 	"github.com/worlve/sp-service/internal/api/handlers/healthcheck/mocks"
+	healthcheckservice "github.com/worlve/sp-service/internal/services/healthcheck"
+	"github.com/worlve/sp-service/internal/util/transaction"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -37,7 +40,7 @@ func TestMethodNotAllowed(t *testing.T) {
 			authZ: api.AuthZ{
 				APIPath: "api/test",
 			},
-			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"405 - Method Not Allowed\",\"message\":\"method not allowed\"}}\n",
+			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"405 - Method Not Allowed\",\"code\":\"METHOD_NOT_ALLOWED\",\"message\":\"method not allowed\",\"requestId\":\"RQ_TEST\"}}\n",
 			expectedStatusCode:   405,
 		},
 	}
@@ -79,7 +82,7 @@ func TestIsHealthy(t *testing.T) {
 			name:                 "not authenticated",
 			authN:                handlertestutils.DefaultAuthN("PROD"),
 			authZ:                handlertestutils.DefaultAuthZ(),
-			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"401 - Unauthorized\",\"message\":\"not authenticated\"}}\n",
+			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"401 - Unauthorized\",\"code\":\"UNAUTHENTICATED\",\"message\":\"not authenticated\",\"requestId\":\"RQ_TEST\"}}\n",
 			expectedStatusCode:   401,
 		},
 		{
@@ -89,14 +92,14 @@ func TestIsHealthy(t *testing.T) {
 			},
 			authN:                handlertestutils.DefaultAuthN("PROD"),
 			authZ:                handlertestutils.DefaultAuthZ(),
-			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"401 - Unauthorized\",\"message\":\"not authenticated\"}}\n",
+			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"401 - Unauthorized\",\"code\":\"UNAUTHENTICATED\",\"message\":\"not authenticated\",\"requestId\":\"RQ_TEST\"}}\n",
 			expectedStatusCode:   401,
 		},
 		{
 			name:                 "happy healthy healthcheck, local",
 			authN:                handlertestutils.DefaultAuthN("LOCAL"),
 			authZ:                handlertestutils.DefaultAuthZ(),
-			expectedResponseBody: "{\"result\":{\"status\":\"ok\"},\"meta\":{\"httpStatus\":\"200 - OK\"}}\n",
+			expectedResponseBody: "{\"result\":{\"status\":\"ok\"},\"meta\":{\"httpStatus\":\"200 - OK\",\"requestId\":\"RQ_TEST\"}}\n",
 			expectedStatusCode:   200,
 			isHealthyCalls:       []isHealthyCall{{returnIsHealthy: true}},
 		},
@@ -107,7 +110,7 @@ func TestIsHealthy(t *testing.T) {
 			},
 			authN:                handlertestutils.DefaultAuthN("PROD"),
 			authZ:                handlertestutils.DefaultAuthZ(),
-			expectedResponseBody: "{\"result\":{\"status\":\"ok\"},\"meta\":{\"httpStatus\":\"200 - OK\"}}\n",
+			expectedResponseBody: "{\"result\":{\"status\":\"ok\"},\"meta\":{\"httpStatus\":\"200 - OK\",\"requestId\":\"RQ_TEST\"}}\n",
 			expectedStatusCode:   200,
 			isHealthyCalls:       []isHealthyCall{{returnIsHealthy: true}},
 		},
@@ -115,13 +118,13 @@ func TestIsHealthy(t *testing.T) {
 			name:                 "bad healthcheck, local",
 			authN:                handlertestutils.DefaultAuthN("LOCAL"),
 			authZ:                handlertestutils.DefaultAuthZ(),
-			expectedResponseBody: "{\"result\":{\"status\":\"error\"},\"meta\":{\"httpStatus\":\"200 - OK\"}}\n",
+			expectedResponseBody: "{\"result\":{\"status\":\"error\"},\"meta\":{\"httpStatus\":\"200 - OK\",\"requestId\":\"RQ_TEST\"}}\n",
 			expectedStatusCode:   200,
 			isHealthyCalls:       []isHealthyCall{{returnIsHealthy: false}},
 		},
 	}
 	for _, tc := range cases {
-		t.Run(fmt.Sprintf(tc.name), func(t *testing.T) {
+		t.Run(tc.name, func(t *testing.T) {
 			healthcheckService := new(mocks.HealthcheckService)
 			for index := range tc.isHealthyCalls {
 				healthcheckService.On("IsHealthy", mock.Anything).Return(tc.isHealthyCalls[index].returnIsHealthy, tc.isHealthyCalls[index].returnErr)
@@ -142,3 +145,78 @@ func TestIsHealthy(t *testing.T) {
 		})
 	}
 }
+
+func TestProbes(t *testing.T) {
+	cases := []struct {
+		name                 string
+		endpoint             string
+		readinessReport      *healthcheckservice.ReadinessReport
+		expectedResponseBody string
+		expectedStatusCode   int
+	}{
+		{
+			name:                 "live without authentication",
+			endpoint:             LivenessEndpoint,
+			expectedResponseBody: "{\"result\":{\"status\":\"ok\"},\"meta\":{\"httpStatus\":\"200 - OK\",\"requestId\":\"RQ_TEST\"}}\n",
+			expectedStatusCode:   200,
+		},
+		{
+			name:     "ready without authentication",
+			endpoint: ReadinessEndpoint,
+			readinessReport: &healthcheckservice.ReadinessReport{
+				Status: healthcheckservice.StatusOK,
+				Components: []healthcheckservice.ComponentReport{
+					{Name: "database", Status: healthcheckservice.StatusOK, LatencyMS: 1.5},
+				},
+			},
+			expectedResponseBody: "{\"result\":{\"status\":\"ok\",\"components\":[{\"name\":\"database\",\"status\":\"ok\",\"latencyMs\":1.5}]},\"meta\":{\"httpStatus\":\"200 - OK\",\"requestId\":\"RQ_TEST\"}}\n",
+			expectedStatusCode:   200,
+		},
+		{
+			name:     "not ready when a component fails",
+			endpoint: ReadinessEndpoint,
+			readinessReport: &healthcheckservice.ReadinessReport{
+				Status: healthcheckservice.StatusError,
+				Components: []healthcheckservice.ComponentReport{
+					{Name: "database", Status: healthcheckservice.StatusError, LatencyMS: 2, Error: "failure", LastError: "failure"},
+				},
+			},
+			expectedResponseBody: "{\"result\":{\"status\":\"error\",\"components\":[{\"name\":\"database\",\"status\":\"error\",\"latencyMs\":2,\"error\":\"failure\",\"lastError\":\"failure\"}]},\"meta\":{\"httpStatus\":\"503 - Service Unavailable\",\"requestId\":\"RQ_TEST\"}}\n",
+			expectedStatusCode:   503,
+		},
+		{
+			name:     "not ready when draining",
+			endpoint: ReadinessEndpoint,
+			readinessReport: &healthcheckservice.ReadinessReport{
+				Status:     healthcheckservice.StatusDraining,
+				Components: []healthcheckservice.ComponentReport{},
+			},
+			expectedResponseBody: "{\"result\":{\"status\":\"draining\",\"components\":[]},\"meta\":{\"httpStatus\":\"503 - Service Unavailable\",\"requestId\":\"RQ_TEST\"}}\n",
+			expectedStatusCode:   503,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			healthcheckService := new(mocks.HealthcheckService)
+			if tc.readinessReport != nil {
+				healthcheckService.On("Ready", mock.Anything).Return(*tc.readinessReport)
+			}
+			authZ := handlertestutils.DefaultAuthZ()
+			handler := api.Handler{
+				AuthN:   handlertestutils.DefaultAuthN("PROD"),
+				AuthZ:   authZ,
+				Router:  api.NewRouter(authZ.APIPath, "static/test", HealthcheckRouterHandlers(authZ.APIPath, healthcheckService)),
+				APIPath: authZ.APIPath,
+			}
+			r := httptest.NewRequest(http.MethodGet, "http://test.com"+tc.endpoint, nil)
+			r.Header.Set(transaction.RequestIDHeaderKey, handlertestutils.TestRequestID)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			resp := w.Result()
+			respBody, _ := ioutil.ReadAll(resp.Body)
+			require.Equal(t, tc.expectedResponseBody, string(respBody))
+			require.Equal(t, tc.expectedStatusCode, resp.StatusCode)
+			healthcheckService.AssertExpectations(t)
+		})
+	}
+}
@@ -3,6 +3,8 @@ package mocks
 
 import context "context"
 
+import healthcheckservice "github.com/worlve/sp-service/internal/services/healthcheck"
+
 import mock "github.com/stretchr/testify/mock"
 
 // HealthcheckService is an autogenerated mock type for the HealthcheckService type
@@ -30,3 +32,17 @@ func (_m *HealthcheckService) IsHealthy(ctx context.Context) (bool, error) {
 
 	return r0, r1
 }
+
+// Ready provides a mock function with given fields: ctx
+func (_m *HealthcheckService) Ready(ctx context.Context) healthcheckservice.ReadinessReport {
+	ret := _m.Called(ctx)
+
+	var r0 healthcheckservice.ReadinessReport
+	if rf, ok := ret.Get(0).(func(context.Context) healthcheckservice.ReadinessReport); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(healthcheckservice.ReadinessReport)
+	}
+
+	return r0
+}
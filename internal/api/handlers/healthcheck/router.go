@@ -5,6 +5,13 @@ import (
 	"net/http"
 
 	"github.com/worlve/sp-service/internal/api"
+	healthcheckservice "github.com/worlve/sp-service/internal/services/healthcheck"
+)
+
+// Probe endpoints are served at the root so they don't depend on the API path.
+const (
+	LivenessEndpoint  = "/livez"
+	ReadinessEndpoint = "/readyz"
 )
 
 // HealthcheckRouterHandlers returns the requests for the associated routes.
@@ -17,6 +24,39 @@ func HealthcheckRouterHandlers(apiPath string, healthcheckService HealthcheckSer
 		Method:   http.MethodGet,
 		Endpoint: fmt.Sprintf("/%v/healthcheck", apiPath),
 		Handle:   handler.IsHealthy,
+		Doc: &api.RouteDoc{
+			OperationID: "healthcheck",
+			Summary:     "Healthcheck",
+			Description: "Reports whether the service and its dependencies are healthy. Only available to admins.",
+			Tag:         "health",
+			Response:    StatusResponse{},
+		},
+	})
+	routerHandlers = append(routerHandlers, api.RouterHandler{
+		Method:   http.MethodGet,
+		Endpoint: LivenessEndpoint,
+		Handle:   handler.IsLive,
+		NoAuth:   true,
+		Doc: &api.RouteDoc{
+			OperationID: "liveness",
+			Summary:     "Liveness",
+			Description: "Responds ok as long as the server can handle requests.",
+			Tag:         "health",
+			Response:    StatusResponse{},
+		},
+	})
+	routerHandlers = append(routerHandlers, api.RouterHandler{
+		Method:   http.MethodGet,
+		Endpoint: ReadinessEndpoint,
+		Handle:   handler.IsReady,
+		NoAuth:   true,
+		Doc: &api.RouteDoc{
+			OperationID: "readiness",
+			Summary:     "Readiness",
+			Description: "Reports the status of each dependency, with a 503 - Service Unavailable unless every one is ok and the server isn't draining.",
+			Tag:         "health",
+			Response:    healthcheckservice.ReadinessReport{},
+		},
 	})
 	return routerHandlers
 }
@@ -0,0 +1,43 @@
+package metricshandler
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+
+	"github.com/worlve/sp-service/internal/api"
+	"github.com/julienschmidt/httprouter"
+	"github.com/pkg/errors"
+)
+
+// MetricsHandler is the handler for the associated API
+type MetricsHandler struct {
+	Registry Registry
+}
+
+// Registry see metrics.Registry for more details
+type Registry interface {
+	Write(w io.Writer) error
+}
+
+// GetMetrics responds with the metrics in the Prometheus text format.
+func (h MetricsHandler) GetMetrics(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	authData, err := api.GetDataFromContext(r.Context())
+	if err != nil {
+		api.RespondWith(r, w, http.StatusInternalServerError, &api.InternalErr{}, errors.Wrap(err, "failed to get auth data"))
+		return
+	}
+	if !authData.IsAdmin() {
+		api.RespondWith(r, w, http.StatusForbidden, &api.FailedAuthorization{}, errors.Errorf("user not authorized for metrics: %v", authData.UserID))
+		return
+	}
+	var buf bytes.Buffer
+	err = h.Registry.Write(&buf)
+	if err != nil {
+		api.RespondWith(r, w, http.StatusInternalServerError, &api.InternalErr{}, errors.Wrap(err, "failed to write metrics"))
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	buf.WriteTo(w)
+}
@@ -0,0 +1,79 @@
+package metricshandler
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/worlve/sp-service/internal/api"
+	"github.com/worlve/sp-service/internal/api/handlers/handlertestutils"
+	"github.com/worlve/sp-service/internal/util/metrics"
+	"github.com/worlve/sp-service/internal/util/transaction"
+)
+
+func TestGetMetrics(t *testing.T) {
+	cases := []struct {
+		name                 string
+		headers              map[string]string
+		authN                api.AuthN
+		expectedResponseBody string
+		expectedStatusCode   int
+		expectedContentType  string
+	}{
+		{
+			name:                 "not authenticated",
+			authN:                handlertestutils.DefaultAuthN("PROD"),
+			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"401 - Unauthorized\",\"code\":\"UNAUTHENTICATED\",\"message\":\"not authenticated\",\"requestId\":\"RQ_TEST\"}}\n",
+			expectedStatusCode:   http.StatusUnauthorized,
+			expectedContentType:  "application/json",
+		},
+		{
+			name:  "not an admin",
+			authN: handlertestutils.DefaultAuthN("LOCAL"),
+			headers: map[string]string{
+				"X-USER-ID": "UR_1",
+			},
+			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"403 - Forbidden\",\"code\":\"FORBIDDEN\",\"message\":\"not authorized\",\"requestId\":\"RQ_TEST\"}}\n",
+			expectedStatusCode:   http.StatusForbidden,
+			expectedContentType:  "application/json",
+		},
+		{
+			name:  "admin gets the metrics",
+			authN: handlertestutils.DefaultAuthN("PROD"),
+			headers: map[string]string{
+				"X-ADMIN-AUTH-SECRET": "SECRET",
+			},
+			expectedResponseBody: "# HELP test_total A test counter.\n# TYPE test_total counter\ntest_total{kind=\"a\"} 2\n",
+			expectedStatusCode:   http.StatusOK,
+			expectedContentType:  "text/plain; version=0.0.4; charset=utf-8",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			registry := metrics.NewRegistry()
+			registry.NewCounterVec("test_total", "A test counter.", "kind").Add(2, "a")
+			authZ := handlertestutils.DefaultAuthZ()
+			handler := api.Handler{
+				AuthN:   tc.authN,
+				AuthZ:   authZ,
+				Router:  api.NewRouter(authZ.APIPath, "static/test", MetricsRouterHandlers(registry)),
+				APIPath: authZ.APIPath,
+			}
+			r := httptest.NewRequest(http.MethodGet, "http://test.com"+MetricsEndpoint, nil)
+			r.Header.Set(transaction.RequestIDHeaderKey, handlertestutils.TestRequestID)
+			for key, value := range tc.headers {
+				r.Header.Set(key, value)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			resp := w.Result()
+			respBody, _ := ioutil.ReadAll(resp.Body)
+			require.Equal(t, tc.expectedResponseBody, string(respBody))
+			require.Equal(t, tc.expectedStatusCode, resp.StatusCode)
+			require.Equal(t, tc.expectedContentType, resp.Header.Get("Content-Type"))
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package metricshandler
+
+import (
+	"net/http"
+
+	"github.com/worlve/sp-service/internal/api"
+)
+
+// MetricsEndpoint is where the metrics are served, which is where Prometheus scrapes by default.
+const MetricsEndpoint = "/metrics"
+
+// MetricsRouterHandlers returns the requests for the associated routes.
+func MetricsRouterHandlers(registry Registry) []api.RouterHandler {
+	handler := MetricsHandler{
+		Registry: registry,
+	}
+	var routerHandlers []api.RouterHandler
+	routerHandlers = append(routerHandlers, api.RouterHandler{
+		Method:   http.MethodGet,
+		Endpoint: MetricsEndpoint,
+		Handle:   handler.GetMetrics,
+		Doc: &api.RouteDoc{
+			OperationID:          "getMetrics",
+			Summary:              "Get Metrics",
+			Description:          "Gets the service's metrics in the Prometheus text format. Only available to admins.",
+			Tag:                  "metrics",
+			ResponseContentTypes: []string{"text/plain"},
+		},
+	})
+	return routerHandlers
+}
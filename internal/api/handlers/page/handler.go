@@ -2,6 +2,7 @@ package pagehandler
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 
 	"github.com/worlve/sp-service/internal/models/pagetemplate"
@@ -29,6 +30,10 @@ type PageService interface {
 	GetEntirePage(ctx context.Context, params pageservice.GetEntirePageParams) (page.Page, error)
 	GetPageProperties(ctx context.Context, params pageservice.GetPagePropertiesParams) ([]property.Property, error)
 	ReplacePageProperties(ctx context.Context, params pageservice.ReplacePagePropertiesParams) error
+	BatchPages(ctx context.Context, params pageservice.BatchPagesParams) ([]pageservice.BatchOperationResult, error)
+	GetRemovedPages(ctx context.Context, params pageservice.GetRemovedPagesParams) ([]page.Page, int, string, error)
+	RestorePage(ctx context.Context, params pageservice.RestorePageParams) error
+	PurgePage(ctx context.Context, params pageservice.PurgePageParams) error
 }
 
 // PageHandler is the handler for the associated API
@@ -40,7 +45,7 @@ type PageHandler struct {
 func (h PageHandler) CreatePage(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
 	request, err := NewCreatePageRequest(r, p)
 	if err != nil {
-		api.RespondWith(r, w, http.StatusBadRequest, err, err)
+		api.RespondWithError(r, w, err)
 		return
 	}
 	ctx := r.Context()
@@ -63,22 +68,30 @@ func (h PageHandler) CreatePage(w http.ResponseWriter, r *http.Request, p httpro
 		},
 		OwnerID: authData.UserID,
 	})
-	if castErr, ok := err.(*storeerror.DupEntry); ok {
-		api.RespondWith(r, w, http.StatusBadRequest, castErr, err)
-		return
-	}
 	if err != nil {
-		api.RespondWith(r, w, http.StatusInternalServerError, &api.InternalErr{}, err)
+		api.RespondWithError(r, w, err)
 		return
 	}
-	api.RespondWith(r, w, http.StatusOK, map[string]string{"id": record.GUID}, nil)
+	api.RespondWith(r, w, http.StatusOK, CreatePageResponse{GUID: record.GUID}, nil)
+}
+
+// CreatePageResponse is the result of the CreatePage call.
+type CreatePageResponse struct {
+	GUID string `json:"id"`
+}
+
+// PageBatchResponse is a batch of pages, with how to get the next batch when there are more.
+type PageBatchResponse struct {
+	Batch     []page.ReducedPage   `json:"batch"`
+	Total     int                  `json:"total"`
+	NextBatch *nextbatch.NextBatch `json:"nextBatch,omitempty"`
 }
 
 // UpdatePage see Service for more details
 func (h PageHandler) UpdatePage(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
 	request, err := NewUpdatePageRequest(r, p)
 	if err != nil {
-		api.RespondWith(r, w, http.StatusBadRequest, err, err)
+		api.RespondWithError(r, w, err)
 		return
 	}
 	ctx := r.Context()
@@ -102,12 +115,8 @@ func (h PageHandler) UpdatePage(w http.ResponseWriter, r *http.Request, p httpro
 		},
 		UserID: authData.UserID,
 	})
-	if _, ok := err.(*storeerror.NotAuthorized); ok {
-		api.RespondWith(r, w, http.StatusUnauthorized, &api.FailedAuthorization{}, err)
-		return
-	}
 	if err != nil {
-		api.RespondWith(r, w, http.StatusInternalServerError, &api.InternalErr{}, err)
+		api.RespondWithError(r, w, err)
 		return
 	}
 	api.RespondWith(r, w, http.StatusOK, nil, nil)
@@ -117,7 +126,7 @@ func (h PageHandler) UpdatePage(w http.ResponseWriter, r *http.Request, p httpro
 func (h PageHandler) GetEntirePage(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
 	request, err := NewGetEntirePageRequest(r, p)
 	if err != nil {
-		api.RespondWith(r, w, http.StatusBadRequest, err, err)
+		api.RespondWithError(r, w, err)
 		return
 	}
 	ctx := r.Context()
@@ -132,12 +141,8 @@ func (h PageHandler) GetEntirePage(w http.ResponseWriter, r *http.Request, p htt
 		},
 		UserID: authData.UserID,
 	})
-	if _, ok := err.(*storeerror.NotAuthorized); ok {
-		api.RespondWith(r, w, http.StatusUnauthorized, &api.FailedAuthorization{}, err)
-		return
-	}
 	if err != nil {
-		api.RespondWith(r, w, http.StatusInternalServerError, &api.InternalErr{}, err)
+		api.RespondWithError(r, w, err)
 		return
 	}
 	conformedRecord := record.GetJSONConformed()
@@ -148,7 +153,7 @@ func (h PageHandler) GetEntirePage(w http.ResponseWriter, r *http.Request, p htt
 func (h PageHandler) GetPage(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
 	request, err := NewGetPageRequest(r, p)
 	if err != nil {
-		api.RespondWith(r, w, http.StatusBadRequest, err, err)
+		api.RespondWithError(r, w, err)
 		return
 	}
 	ctx := r.Context()
@@ -164,12 +169,8 @@ func (h PageHandler) GetPage(w http.ResponseWriter, r *http.Request, p httproute
 		UserID: authData.UserID,
 	})
 	reducedPage := record.Reduce()
-	if _, ok := err.(*storeerror.NotAuthorized); ok {
-		api.RespondWith(r, w, http.StatusUnauthorized, &api.FailedAuthorization{}, err)
-		return
-	}
 	if err != nil {
-		api.RespondWith(r, w, http.StatusInternalServerError, &api.InternalErr{}, err)
+		api.RespondWithError(r, w, err)
 		return
 	}
 	conformedRecord := reducedPage.GetJSONConformed()
@@ -180,7 +181,7 @@ func (h PageHandler) GetPage(w http.ResponseWriter, r *http.Request, p httproute
 func (h PageHandler) GetPages(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
 	request, err := NewGetPagesRequest(r, p)
 	if err != nil {
-		api.RespondWith(r, w, http.StatusBadRequest, err, err)
+		api.RespondWithError(r, w, err)
 		return
 	}
 	ctx := r.Context()
@@ -193,41 +194,26 @@ func (h PageHandler) GetPages(w http.ResponseWriter, r *http.Request, p httprout
 		NextBatchID: request.NextBatchID,
 		UserID:      authData.UserID,
 	})
-	if _, ok := err.(*storeerror.NotAuthorized); ok {
-		api.RespondWith(r, w, http.StatusUnauthorized, &api.FailedAuthorization{}, err)
-		return
-	}
 	if err != nil {
-		api.RespondWith(r, w, http.StatusInternalServerError, &api.InternalErr{}, err)
+		api.RespondWithError(r, w, err)
 		return
 	}
-	conformedRecords := make([]interface{}, 0)
+	respondWithPageBatch(r, w, records, total, nextBatchID)
+}
+
+func respondWithPageBatch(r *http.Request, w http.ResponseWriter, records []page.Page, total int, nextBatchID string) {
+	responseBody := PageBatchResponse{
+		Batch: make([]page.ReducedPage, 0, len(records)),
+		Total: total,
+	}
 	for _, record := range records {
-		reducedPage := record.Reduce()
-		conformedRecords = append(conformedRecords, reducedPage.GetJSONConformed())
-	}
-	if nextBatchID == "" {
-		responseBody := struct {
-			Batch []interface{} `json:"batch"`
-			Total int           `json:"total"`
-		}{
-			Batch: conformedRecords,
-			Total: total,
-		}
-		api.RespondWith(r, w, http.StatusOK, responseBody, nil)
-		return
+		responseBody.Batch = append(responseBody.Batch, record.Reduce())
 	}
-	responseBody := struct {
-		Batch     []interface{}       `json:"batch"`
-		Total     int                 `json:"total"`
-		NextBatch nextbatch.NextBatch `json:"nextBatch"`
-	}{
-		Batch: conformedRecords,
-		Total: total,
-		NextBatch: nextbatch.NextBatch{
+	if nextBatchID != "" {
+		responseBody.NextBatch = &nextbatch.NextBatch{
 			ParamKey:   "nextBatchId",
 			ParamValue: nextBatchID,
-		},
+		}
 	}
 	api.RespondWith(r, w, http.StatusOK, responseBody, nil)
 }
@@ -236,7 +222,7 @@ func (h PageHandler) GetPages(w http.ResponseWriter, r *http.Request, p httprout
 func (h PageHandler) DeletePage(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
 	request, err := NewDeletePageRequest(r, p)
 	if err != nil {
-		api.RespondWith(r, w, http.StatusBadRequest, err, err)
+		api.RespondWithError(r, w, err)
 		return
 	}
 	ctx := r.Context()
@@ -251,12 +237,88 @@ func (h PageHandler) DeletePage(w http.ResponseWriter, r *http.Request, p httpro
 		},
 		UserID: authData.UserID,
 	})
-	if _, ok := err.(*storeerror.NotAuthorized); ok {
-		api.RespondWith(r, w, http.StatusUnauthorized, &api.FailedAuthorization{}, err)
+	if err != nil {
+		api.RespondWithError(r, w, err)
+		return
+	}
+	api.RespondWith(r, w, http.StatusOK, nil, nil)
+}
+
+// GetRemovedPages see Service for more details
+func (h PageHandler) GetRemovedPages(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	request, err := NewGetPagesRequest(r, p)
+	if err != nil {
+		api.RespondWithError(r, w, err)
+		return
+	}
+	ctx := r.Context()
+	authData, err := api.GetDataFromContext(ctx)
+	if err != nil {
+		api.RespondWith(r, w, http.StatusInternalServerError, &api.InternalErr{}, errors.Wrap(err, "failed to get auth data"))
+		return
+	}
+	records, total, nextBatchID, err := h.PageService.GetRemovedPages(ctx, pageservice.GetRemovedPagesParams{
+		NextBatchID: request.NextBatchID,
+		UserID:      authData.UserID,
+	})
+	if err != nil {
+		api.RespondWithError(r, w, err)
+		return
+	}
+	respondWithPageBatch(r, w, records, total, nextBatchID)
+}
+
+// RestorePage see Service for more details
+func (h PageHandler) RestorePage(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	request, err := NewDeletePageRequest(r, p)
+	if err != nil {
+		api.RespondWithError(r, w, err)
+		return
+	}
+	ctx := r.Context()
+	authData, err := api.GetDataFromContext(ctx)
+	if err != nil {
+		api.RespondWith(r, w, http.StatusInternalServerError, &api.InternalErr{}, errors.Wrap(err, "failed to get auth data"))
+		return
+	}
+	err = h.PageService.RestorePage(ctx, pageservice.RestorePageParams{
+		Page: page.Page{
+			GUID: request.GUID,
+		},
+		UserID: authData.UserID,
+	})
+	respondWithTrashResult(r, w, err)
+}
+
+// PurgePage see Service for more details
+func (h PageHandler) PurgePage(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	request, err := NewDeletePageRequest(r, p)
+	if err != nil {
+		api.RespondWithError(r, w, err)
+		return
+	}
+	ctx := r.Context()
+	authData, err := api.GetDataFromContext(ctx)
+	if err != nil {
+		api.RespondWith(r, w, http.StatusInternalServerError, &api.InternalErr{}, errors.Wrap(err, "failed to get auth data"))
+		return
+	}
+	err = h.PageService.PurgePage(ctx, pageservice.PurgePageParams{
+		Page: page.Page{
+			GUID: request.GUID,
+		},
+		UserID: authData.UserID,
+	})
+	respondWithTrashResult(r, w, err)
+}
+
+func respondWithTrashResult(r *http.Request, w http.ResponseWriter, err error) {
+	if _, ok := errors.Cause(err).(*storeerror.NotFound); ok {
+		api.RespondWith(r, w, http.StatusNotFound, errors.New("page not found in trash"), err)
 		return
 	}
 	if err != nil {
-		api.RespondWith(r, w, http.StatusInternalServerError, &api.InternalErr{}, err)
+		api.RespondWithError(r, w, err)
 		return
 	}
 	api.RespondWith(r, w, http.StatusOK, nil, nil)
@@ -266,7 +328,7 @@ func (h PageHandler) DeletePage(w http.ResponseWriter, r *http.Request, p httpro
 func (h PageHandler) GetPageProperties(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
 	request, err := NewGetPagePropertiesRequest(r, p)
 	if err != nil {
-		api.RespondWith(r, w, http.StatusBadRequest, err, err)
+		api.RespondWithError(r, w, err)
 		return
 	}
 	ctx := r.Context()
@@ -281,12 +343,8 @@ func (h PageHandler) GetPageProperties(w http.ResponseWriter, r *http.Request, p
 		},
 		UserID: authData.UserID,
 	})
-	if _, ok := err.(*storeerror.NotAuthorized); ok {
-		api.RespondWith(r, w, http.StatusUnauthorized, &api.FailedAuthorization{}, err)
-		return
-	}
 	if err != nil {
-		api.RespondWith(r, w, http.StatusInternalServerError, &api.InternalErr{}, err)
+		api.RespondWithError(r, w, err)
 		return
 	}
 	api.RespondWith(r, w, http.StatusOK, records, nil)
@@ -296,7 +354,7 @@ func (h PageHandler) GetPageProperties(w http.ResponseWriter, r *http.Request, p
 func (h PageHandler) ReplacePageProperties(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
 	request, err := NewReplacePagePropertiesRequest(r, p)
 	if err != nil {
-		api.RespondWith(r, w, http.StatusBadRequest, err, err)
+		api.RespondWithError(r, w, err)
 		return
 	}
 	ctx := r.Context()
@@ -312,13 +370,106 @@ func (h PageHandler) ReplacePageProperties(w http.ResponseWriter, r *http.Reques
 		Properties: request.Properties,
 		UserID:     authData.UserID,
 	})
-	if _, ok := err.(*storeerror.NotAuthorized); ok {
-		api.RespondWith(r, w, http.StatusUnauthorized, &api.FailedAuthorization{}, err)
+	if err != nil {
+		api.RespondWithError(r, w, err)
 		return
 	}
+	api.RespondWith(r, w, http.StatusOK, nil, nil)
+}
+
+// BatchPagesResponse is the result of the BatchPages call, with a result for each operation in order.
+type BatchPagesResponse struct {
+	Atomic  bool                     `json:"atomic"`
+	Results []BatchOperationResponse `json:"results"`
+}
+
+// BatchOperationResponse is the result of a single operation within the BatchPages call.
+type BatchOperationResponse struct {
+	Index      int                            `json:"index"`
+	Type       pageservice.BatchOperationType `json:"op"`
+	GUID       string                         `json:"id,omitempty"`
+	HTTPStatus string                         `json:"httpStatus"`
+	Code       api.Code                       `json:"code,omitempty"`
+	Message    string                         `json:"message,omitempty"`
+}
+
+// BatchPages see Service for more details
+func (h PageHandler) BatchPages(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	request, err := NewBatchPagesRequest(r, p)
 	if err != nil {
-		api.RespondWith(r, w, http.StatusInternalServerError, &api.InternalErr{}, err)
+		api.RespondWithError(r, w, err)
 		return
 	}
-	api.RespondWith(r, w, http.StatusOK, nil, nil)
+	ctx := r.Context()
+	authData, err := api.GetDataFromContext(ctx)
+	if err != nil {
+		api.RespondWith(r, w, http.StatusInternalServerError, &api.InternalErr{}, errors.Wrap(err, "failed to get auth data"))
+		return
+	}
+	operations := make([]pageservice.BatchOperation, 0, len(request.Operations))
+	for _, operation := range request.Operations {
+		operations = append(operations, pageservice.BatchOperation{
+			Type: operation.Type,
+			Page: page.Page{
+				GUID:    operation.GUID,
+				Title:   operation.Title,
+				Summary: operation.Summary,
+				Version: version.Version{
+					GUID: operation.VersionID,
+				},
+				PermissionType: operation.PermissionType,
+				PageTemplate: pagetemplate.PageTemplate{
+					GUID: operation.PageTemplateID,
+				},
+			},
+		})
+	}
+	results, err := h.PageService.BatchPages(ctx, pageservice.BatchPagesParams{
+		Operations: operations,
+		Atomic:     request.Atomic,
+		UserID:     authData.UserID,
+	})
+	if err != nil {
+		api.RespondWithError(r, w, err)
+		return
+	}
+	status := http.StatusOK
+	var errToLog error
+	responses := make([]BatchOperationResponse, 0, len(results))
+	for i, result := range results {
+		resultStatus, resultErr := getBatchOperationStatus(result.Err)
+		response := BatchOperationResponse{
+			Index:      i,
+			Type:       result.Type,
+			GUID:       result.Page.GUID,
+			HTTPStatus: fmt.Sprintf("%v - %v", resultStatus, http.StatusText(resultStatus)),
+		}
+		if resultErr != nil {
+			response.Code = resultErr.Code
+			response.Message = resultErr.Message
+		}
+		if resultStatus != http.StatusOK && resultStatus != http.StatusFailedDependency && errToLog == nil {
+			errToLog = errors.Wrapf(result.Err, "batch operation %v failed", i)
+			if request.Atomic {
+				status = resultStatus
+			}
+		}
+		responses = append(responses, response)
+	}
+	responseBody := BatchPagesResponse{
+		Atomic:  request.Atomic,
+		Results: responses,
+	}
+	api.RespondWith(r, w, status, responseBody, errToLog)
+}
+
+func getBatchOperationStatus(err error) (int, *api.Error) {
+	if err == nil {
+		return http.StatusOK, nil
+	}
+	if err == pageservice.ErrBatchRolledBack || err == pageservice.ErrBatchNotAttempted {
+		return http.StatusFailedDependency, &api.Error{Status: http.StatusFailedDependency, Code: api.CodeFailedDependency, Message: err.Error()}
+	}
+	responseErr := api.ToError(err)
+	return responseErr.Status, responseErr
 }
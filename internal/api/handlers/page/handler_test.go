@@ -63,7 +63,7 @@ func TestCreatePage(t *testing.T) {
 			name:                 "not authenticated",
 			authN:                handlertestutils.DefaultAuthN("PROD"),
 			authZ:                handlertestutils.DefaultAuthZ(),
-			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"401 - Unauthorized\",\"message\":\"not authenticated\"}}\n",
+			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"401 - Unauthorized\",\"code\":\"UNAUTHENTICATED\",\"message\":\"not authenticated\",\"requestId\":\"RQ_TEST\"}}\n",
 			expectedStatusCode:   401,
 		},
 		{
@@ -80,7 +80,7 @@ func TestCreatePage(t *testing.T) {
 			// },
 			authN:                handlertestutils.DefaultAuthN("LOCAL"),
 			authZ:                handlertestutils.DefaultAuthZ(),
-			expectedResponseBody: "{\"result\":{\"id\":\"PG_1\"},\"meta\":{\"httpStatus\":\"200 - OK\"}}\n",
+			expectedResponseBody: "{\"result\":{\"id\":\"PG_1\"},\"meta\":{\"httpStatus\":\"200 - OK\",\"requestId\":\"RQ_TEST\"}}\n",
 			expectedStatusCode:   200,
 			createPageCalls: []createPageCall{
 				{
@@ -100,7 +100,18 @@ func TestCreatePage(t *testing.T) {
 			requestBody:          "{\"summary\":\"test summary\",\"versionId\":\"VR_1\",\"pageTemplateId\":\"PGT_1\",\"permission\":\"PR\"}",
 			authN:                handlertestutils.DefaultAuthN("LOCAL"),
 			authZ:                handlertestutils.DefaultAuthZ(),
-			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"400 - Bad Request\",\"message\":\"must provide title\"}}\n",
+			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"400 - Bad Request\",\"code\":\"VALIDATION_FAILED\",\"message\":\"must provide title\",\"details\":[{\"field\":\"title\",\"message\":\"must provide title\"}],\"requestId\":\"RQ_TEST\"}}\n",
+			expectedStatusCode:   400,
+		},
+		{
+			name: "unknown field for the page",
+			headers: map[string]string{
+				"X-USER-ID": "UR_1",
+			},
+			requestBody:          "{\"title\":\"test title\",\"versionId\":\"VR_1\",\"pageTemplateId\":\"PGT_1\",\"permission\":\"PR\",\"owner\":\"UR_2\"}",
+			authN:                handlertestutils.DefaultAuthN("LOCAL"),
+			authZ:                handlertestutils.DefaultAuthZ(),
+			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"400 - Bad Request\",\"code\":\"VALIDATION_FAILED\",\"message\":\"request does not match the API spec: owner: is not allowed\",\"details\":[{\"field\":\"owner\",\"message\":\"is not allowed\"}],\"requestId\":\"RQ_TEST\"}}\n",
 			expectedStatusCode:   400,
 		},
 	}
@@ -151,7 +162,7 @@ func TestUpdatePage(t *testing.T) {
 			pageID:               "PG_1",
 			authN:                handlertestutils.DefaultAuthN("PROD"),
 			authZ:                handlertestutils.DefaultAuthZ(),
-			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"401 - Unauthorized\",\"message\":\"not authenticated\"}}\n",
+			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"401 - Unauthorized\",\"code\":\"UNAUTHENTICATED\",\"message\":\"not authenticated\",\"requestId\":\"RQ_TEST\"}}\n",
 			expectedStatusCode:   401,
 		},
 		{
@@ -163,7 +174,7 @@ func TestUpdatePage(t *testing.T) {
 			requestBody:          "{\"title\":\"test title\",\"summary\":\"test summary\"}",
 			authN:                handlertestutils.DefaultAuthN("LOCAL"),
 			authZ:                handlertestutils.DefaultAuthZ(),
-			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"200 - OK\"}}\n",
+			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"200 - OK\",\"requestId\":\"RQ_TEST\"}}\n",
 			expectedStatusCode:   200,
 			updatePageCalls: []updatePageCall{
 				{
@@ -183,8 +194,8 @@ func TestUpdatePage(t *testing.T) {
 			requestBody:          "{\"title\":\"test title\",\"summary\":\"test summary\"}",
 			authN:                handlertestutils.DefaultAuthN("LOCAL"),
 			authZ:                handlertestutils.DefaultAuthZ(),
-			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"401 - Unauthorized\",\"message\":\"not authorized\"}}\n",
-			expectedStatusCode:   401,
+			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"403 - Forbidden\",\"code\":\"FORBIDDEN\",\"message\":\"not authorized\",\"requestId\":\"RQ_TEST\"}}\n",
+			expectedStatusCode:   403,
 			updatePageCalls: []updatePageCall{
 				{
 					pageParams: pageservice.UpdatePageParams{
@@ -247,7 +258,7 @@ func TestGetEntirePage(t *testing.T) {
 			pageID:               "PG_1",
 			authN:                handlertestutils.DefaultAuthN("PROD"),
 			authZ:                handlertestutils.DefaultAuthZ(),
-			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"401 - Unauthorized\",\"message\":\"not authenticated\"}}\n",
+			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"401 - Unauthorized\",\"code\":\"UNAUTHENTICATED\",\"message\":\"not authenticated\",\"requestId\":\"RQ_TEST\"}}\n",
 			expectedStatusCode:   401,
 		},
 		{
@@ -258,7 +269,7 @@ func TestGetEntirePage(t *testing.T) {
 			},
 			authN:                handlertestutils.DefaultAuthN("LOCAL"),
 			authZ:                handlertestutils.DefaultAuthZ(),
-			expectedResponseBody: "{\"result\":{\"version\":{\"id\":\"VR_1\",\"name\":\"\",\"parentId\":\"\"},\"pageTemplate\":{\"name\":\"\",\"guid\":\"PGT_1\"},\"id\":\"PG_1\",\"title\":\"test title\",\"summary\":\"test summary\",\"permission\":\"PR\",\"properties\":[],\"details\":[],\"createdAt\":null,\"updatedAt\":null},\"meta\":{\"httpStatus\":\"200 - OK\"}}\n",
+			expectedResponseBody: "{\"result\":{\"version\":{\"id\":\"VR_1\",\"name\":\"\",\"parentId\":\"\"},\"pageTemplate\":{\"name\":\"\",\"guid\":\"PGT_1\"},\"id\":\"PG_1\",\"title\":\"test title\",\"summary\":\"test summary\",\"permission\":\"PR\",\"properties\":[],\"details\":[],\"createdAt\":null,\"updatedAt\":null},\"meta\":{\"httpStatus\":\"200 - OK\",\"requestId\":\"RQ_TEST\"}}\n",
 			expectedStatusCode:   200,
 			getEntirePageCalls: []getEntirePageCall{
 				{
@@ -278,8 +289,8 @@ func TestGetEntirePage(t *testing.T) {
 			},
 			authN:                handlertestutils.DefaultAuthN("LOCAL"),
 			authZ:                handlertestutils.DefaultAuthZ(),
-			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"401 - Unauthorized\",\"message\":\"not authorized\"}}\n",
-			expectedStatusCode:   401,
+			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"403 - Forbidden\",\"code\":\"FORBIDDEN\",\"message\":\"not authorized\",\"requestId\":\"RQ_TEST\"}}\n",
+			expectedStatusCode:   403,
 			getEntirePageCalls: []getEntirePageCall{
 				{
 					pageParams: pageservice.GetEntirePageParams{
@@ -342,7 +353,7 @@ func TestGetPage(t *testing.T) {
 			pageID:               "PG_1",
 			authN:                handlertestutils.DefaultAuthN("PROD"),
 			authZ:                handlertestutils.DefaultAuthZ(),
-			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"401 - Unauthorized\",\"message\":\"not authenticated\"}}\n",
+			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"401 - Unauthorized\",\"code\":\"UNAUTHENTICATED\",\"message\":\"not authenticated\",\"requestId\":\"RQ_TEST\"}}\n",
 			expectedStatusCode:   401,
 		},
 		{
@@ -353,7 +364,7 @@ func TestGetPage(t *testing.T) {
 			},
 			authN:                handlertestutils.DefaultAuthN("LOCAL"),
 			authZ:                handlertestutils.DefaultAuthZ(),
-			expectedResponseBody: "{\"result\":{\"versionId\":\"VR_1\",\"pageTemplateId\":\"PGT_1\",\"id\":\"PG_1\",\"title\":\"test title\",\"summary\":\"test summary\",\"permission\":\"PR\",\"createdAt\":null,\"updatedAt\":null},\"meta\":{\"httpStatus\":\"200 - OK\"}}\n",
+			expectedResponseBody: "{\"result\":{\"versionId\":\"VR_1\",\"pageTemplateId\":\"PGT_1\",\"id\":\"PG_1\",\"title\":\"test title\",\"summary\":\"test summary\",\"permission\":\"PR\",\"createdAt\":null,\"updatedAt\":null},\"meta\":{\"httpStatus\":\"200 - OK\",\"requestId\":\"RQ_TEST\"}}\n",
 			expectedStatusCode:   200,
 			getPageCalls: []getPageCall{
 				{
@@ -373,8 +384,8 @@ func TestGetPage(t *testing.T) {
 			},
 			authN:                handlertestutils.DefaultAuthN("LOCAL"),
 			authZ:                handlertestutils.DefaultAuthZ(),
-			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"401 - Unauthorized\",\"message\":\"not authorized\"}}\n",
-			expectedStatusCode:   401,
+			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"403 - Forbidden\",\"code\":\"FORBIDDEN\",\"message\":\"not authorized\",\"requestId\":\"RQ_TEST\"}}\n",
+			expectedStatusCode:   403,
 			getPageCalls: []getPageCall{
 				{
 					pageParams: pageservice.GetPageParams{
@@ -437,7 +448,7 @@ func TestGetPages(t *testing.T) {
 			name:                 "not authenticated",
 			authN:                handlertestutils.DefaultAuthN("PROD"),
 			authZ:                handlertestutils.DefaultAuthZ(),
-			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"401 - Unauthorized\",\"message\":\"not authenticated\"}}\n",
+			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"401 - Unauthorized\",\"code\":\"UNAUTHENTICATED\",\"message\":\"not authenticated\",\"requestId\":\"RQ_TEST\"}}\n",
 			expectedStatusCode:   401,
 		},
 		{
@@ -447,7 +458,7 @@ func TestGetPages(t *testing.T) {
 			},
 			authN:                handlertestutils.DefaultAuthN("LOCAL"),
 			authZ:                handlertestutils.DefaultAuthZ(),
-			expectedResponseBody: "{\"result\":{\"batch\":[{\"versionId\":\"VR_1\",\"pageTemplateId\":\"PGT_1\",\"id\":\"PG_1\",\"title\":\"test title\",\"summary\":\"test summary\",\"permission\":\"PR\",\"createdAt\":null,\"updatedAt\":null},{\"versionId\":\"VR_1\",\"pageTemplateId\":\"PGT_1\",\"id\":\"PG_2\",\"title\":\"test title 2 \",\"summary\":\"test summary 2\",\"permission\":\"PR\",\"createdAt\":null,\"updatedAt\":null},{\"versionId\":\"VR_1\",\"pageTemplateId\":\"PGT_2\",\"id\":\"PG_3\",\"title\":\"test title 3\",\"summary\":\"test summary 3\",\"permission\":\"PU\",\"createdAt\":null,\"updatedAt\":null}],\"total\":10,\"nextBatch\":{\"paramKey\":\"nextBatchId\",\"paramValue\":\"PG_4\"}},\"meta\":{\"httpStatus\":\"200 - OK\"}}\n",
+			expectedResponseBody: "{\"result\":{\"batch\":[{\"versionId\":\"VR_1\",\"pageTemplateId\":\"PGT_1\",\"id\":\"PG_1\",\"title\":\"test title\",\"summary\":\"test summary\",\"permission\":\"PR\",\"createdAt\":null,\"updatedAt\":null},{\"versionId\":\"VR_1\",\"pageTemplateId\":\"PGT_1\",\"id\":\"PG_2\",\"title\":\"test title 2 \",\"summary\":\"test summary 2\",\"permission\":\"PR\",\"createdAt\":null,\"updatedAt\":null},{\"versionId\":\"VR_1\",\"pageTemplateId\":\"PGT_2\",\"id\":\"PG_3\",\"title\":\"test title 3\",\"summary\":\"test summary 3\",\"permission\":\"PU\",\"createdAt\":null,\"updatedAt\":null}],\"total\":10,\"nextBatch\":{\"paramKey\":\"nextBatchId\",\"paramValue\":\"PG_4\"}},\"meta\":{\"httpStatus\":\"200 - OK\",\"requestId\":\"RQ_TEST\"}}\n",
 			expectedStatusCode:   200,
 			getPagesCalls: []getPagesCall{
 				{
@@ -472,7 +483,7 @@ func TestGetPages(t *testing.T) {
 			},
 			authN:                handlertestutils.DefaultAuthN("LOCAL"),
 			authZ:                handlertestutils.DefaultAuthZ(),
-			expectedResponseBody: "{\"result\":{\"batch\":[],\"total\":0},\"meta\":{\"httpStatus\":\"200 - OK\"}}\n",
+			expectedResponseBody: "{\"result\":{\"batch\":[],\"total\":0},\"meta\":{\"httpStatus\":\"200 - OK\",\"requestId\":\"RQ_TEST\"}}\n",
 			expectedStatusCode:   200,
 			getPagesCalls: []getPagesCall{
 				{
@@ -533,7 +544,7 @@ func TestDeletePage(t *testing.T) {
 			pageID:               "PG_1",
 			authN:                handlertestutils.DefaultAuthN("PROD"),
 			authZ:                handlertestutils.DefaultAuthZ(),
-			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"401 - Unauthorized\",\"message\":\"not authenticated\"}}\n",
+			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"401 - Unauthorized\",\"code\":\"UNAUTHENTICATED\",\"message\":\"not authenticated\",\"requestId\":\"RQ_TEST\"}}\n",
 			expectedStatusCode:   401,
 		},
 		{
@@ -544,7 +555,7 @@ func TestDeletePage(t *testing.T) {
 			},
 			authN:                handlertestutils.DefaultAuthN("LOCAL"),
 			authZ:                handlertestutils.DefaultAuthZ(),
-			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"200 - OK\"}}\n",
+			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"200 - OK\",\"requestId\":\"RQ_TEST\"}}\n",
 			expectedStatusCode:   200,
 			removePageCalls: []removePageCall{
 				{
@@ -565,8 +576,8 @@ func TestDeletePage(t *testing.T) {
 			},
 			authN:                handlertestutils.DefaultAuthN("LOCAL"),
 			authZ:                handlertestutils.DefaultAuthZ(),
-			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"401 - Unauthorized\",\"message\":\"not authorized\"}}\n",
-			expectedStatusCode:   401,
+			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"403 - Forbidden\",\"code\":\"FORBIDDEN\",\"message\":\"not authorized\",\"requestId\":\"RQ_TEST\"}}\n",
+			expectedStatusCode:   403,
 			removePageCalls: []removePageCall{
 				{
 					pageParams: pageservice.RemovePageParams{
@@ -606,3 +617,380 @@ func TestDeletePage(t *testing.T) {
 		})
 	}
 }
+
+type batchPagesCall struct {
+	pageParams    pageservice.BatchPagesParams
+	returnResults []pageservice.BatchOperationResult
+	returnErr     error
+}
+
+func TestBatchPages(t *testing.T) {
+	cases := []struct {
+		name                 string
+		action               string
+		headers              map[string]string
+		requestBody          string
+		authN                api.AuthN
+		authZ                api.AuthZ
+		expectedResponseBody string
+		expectedStatusCode   int
+		batchPagesCalls      []batchPagesCall
+	}{
+		{
+			name:                 "not authenticated",
+			action:               ":batch",
+			authN:                handlertestutils.DefaultAuthN("PROD"),
+			authZ:                handlertestutils.DefaultAuthZ(),
+			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"401 - Unauthorized\",\"code\":\"UNAUTHENTICATED\",\"message\":\"not authenticated\",\"requestId\":\"RQ_TEST\"}}\n",
+			expectedStatusCode:   401,
+		},
+		{
+			name:   "happy batch, local",
+			action: ":batch",
+			headers: map[string]string{
+				"X-USER-ID": "UR_1",
+			},
+			requestBody:          "{\"operations\":[{\"op\":\"create\",\"title\":\"test title\",\"versionId\":\"VR_1\",\"pageTemplateId\":\"PGT_1\",\"permission\":\"PR\"},{\"op\":\"remove\",\"id\":\"PG_2\"}]}",
+			authN:                handlertestutils.DefaultAuthN("LOCAL"),
+			authZ:                handlertestutils.DefaultAuthZ(),
+			expectedResponseBody: "{\"result\":{\"atomic\":false,\"results\":[{\"index\":0,\"op\":\"create\",\"id\":\"PG_1\",\"httpStatus\":\"200 - OK\"},{\"index\":1,\"op\":\"remove\",\"id\":\"PG_2\",\"httpStatus\":\"403 - Forbidden\",\"code\":\"FORBIDDEN\",\"message\":\"not authorized\"}]},\"meta\":{\"httpStatus\":\"200 - OK\",\"requestId\":\"RQ_TEST\"}}\n",
+			expectedStatusCode:   200,
+			batchPagesCalls: []batchPagesCall{
+				{
+					pageParams: pageservice.BatchPagesParams{
+						Operations: []pageservice.BatchOperation{
+							{Type: pageservice.BatchOperationCreate, Page: getPage("", "test title", "", "VR_1", "PGT_1", permission.TypePrivate)},
+							{Type: pageservice.BatchOperationRemove, Page: getPage("PG_2", "", "", "", "", "")},
+						},
+						UserID: "UR_1",
+					},
+					returnResults: []pageservice.BatchOperationResult{
+						{Type: pageservice.BatchOperationCreate, Page: getPage("PG_1", "test title", "", "VR_1", "PGT_1", permission.TypePrivate)},
+						{Type: pageservice.BatchOperationRemove, Page: getPage("PG_2", "", "", "", "", ""), Err: &storeerror.NotAuthorized{UserID: "UR_1", TableID: "PG_2"}},
+					},
+				},
+			},
+		},
+		{
+			name:   "atomic batch with a failed operation",
+			action: ":batch",
+			headers: map[string]string{
+				"X-USER-ID": "UR_1",
+			},
+			requestBody:          "{\"atomic\":true,\"operations\":[{\"op\":\"permission\",\"id\":\"PG_1\",\"permission\":\"PU\"},{\"op\":\"remove\",\"id\":\"PG_2\"}]}",
+			authN:                handlertestutils.DefaultAuthN("LOCAL"),
+			authZ:                handlertestutils.DefaultAuthZ(),
+			expectedResponseBody: "{\"result\":{\"atomic\":true,\"results\":[{\"index\":0,\"op\":\"permission\",\"id\":\"PG_1\",\"httpStatus\":\"424 - Failed Dependency\",\"code\":\"FAILED_DEPENDENCY\",\"message\":\"rolled back due to a failed operation in the batch\"},{\"index\":1,\"op\":\"remove\",\"id\":\"PG_2\",\"httpStatus\":\"403 - Forbidden\",\"code\":\"FORBIDDEN\",\"message\":\"not authorized\"}]},\"meta\":{\"httpStatus\":\"403 - Forbidden\",\"requestId\":\"RQ_TEST\"}}\n",
+			expectedStatusCode:   403,
+			batchPagesCalls: []batchPagesCall{
+				{
+					pageParams: pageservice.BatchPagesParams{
+						Operations: []pageservice.BatchOperation{
+							{Type: pageservice.BatchOperationPermission, Page: getPage("PG_1", "", "", "", "", permission.TypePublic)},
+							{Type: pageservice.BatchOperationRemove, Page: getPage("PG_2", "", "", "", "", "")},
+						},
+						Atomic: true,
+						UserID: "UR_1",
+					},
+					returnResults: []pageservice.BatchOperationResult{
+						{Type: pageservice.BatchOperationPermission, Page: getPage("PG_1", "", "", "", "", permission.TypePublic), Err: pageservice.ErrBatchRolledBack},
+						{Type: pageservice.BatchOperationRemove, Page: getPage("PG_2", "", "", "", "", ""), Err: &storeerror.NotAuthorized{UserID: "UR_1", TableID: "PG_2"}},
+					},
+				},
+			},
+		},
+		{
+			name:   "invalid operation within the batch",
+			action: ":batch",
+			headers: map[string]string{
+				"X-USER-ID": "UR_1",
+			},
+			requestBody:          "{\"operations\":[{\"op\":\"remove\",\"id\":\"PG_1\"},{\"op\":\"create\",\"versionId\":\"VR_1\",\"pageTemplateId\":\"PGT_1\",\"permission\":\"PR\"}]}",
+			authN:                handlertestutils.DefaultAuthN("LOCAL"),
+			authZ:                handlertestutils.DefaultAuthZ(),
+			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"400 - Bad Request\",\"code\":\"VALIDATION_FAILED\",\"message\":\"operations[1]: must provide title\",\"details\":[{\"field\":\"operations[1].title\",\"message\":\"must provide title\"}],\"requestId\":\"RQ_TEST\"}}\n",
+			expectedStatusCode:   400,
+		},
+		{
+			name:   "unsupported page action",
+			action: ":unknown",
+			headers: map[string]string{
+				"X-USER-ID": "UR_1",
+			},
+			requestBody:          "{\"operations\":[{\"op\":\"remove\",\"id\":\"PG_1\"}]}",
+			authN:                handlertestutils.DefaultAuthN("LOCAL"),
+			authZ:                handlertestutils.DefaultAuthZ(),
+			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"400 - Bad Request\",\"code\":\"INVALID_REQUEST\",\"message\":\"unsupported page action\",\"requestId\":\"RQ_TEST\"}}\n",
+			expectedStatusCode:   400,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			pageService := new(mocks.PageService)
+			for index := range tc.batchPagesCalls {
+				pageService.On("BatchPages", mock.Anything, tc.batchPagesCalls[index].pageParams).Return(tc.batchPagesCalls[index].returnResults, tc.batchPagesCalls[index].returnErr)
+			}
+			routerHandlers := PageRouterHandlers(tc.authZ.APIPath, pageService)
+			resp, respBody := handlertestutils.HandleTestRequest(handlertestutils.HandleTestRequestParams{
+				Method:         http.MethodPost,
+				Endpoint:       fmt.Sprintf("pages%v", tc.action),
+				Headers:        tc.headers,
+				Body:           strings.NewReader(tc.requestBody),
+				RouterHandlers: routerHandlers,
+				AuthZ:          tc.authZ,
+				AuthN:          tc.authN,
+			})
+			require.Equal(t, tc.expectedResponseBody, respBody)
+			require.Equal(t, tc.expectedStatusCode, resp.StatusCode)
+			pageService.AssertNumberOfCalls(t, "BatchPages", len(tc.batchPagesCalls))
+		})
+	}
+}
+
+type getRemovedPagesCall struct {
+	pageParams        pageservice.GetRemovedPagesParams
+	returnPages       []page.Page
+	returnTotal       int
+	returnNextBatchID string
+	returnErr         error
+}
+
+func TestGetRemovedPages(t *testing.T) {
+	cases := []struct {
+		name                 string
+		headers              map[string]string
+		authN                api.AuthN
+		authZ                api.AuthZ
+		expectedResponseBody string
+		expectedStatusCode   int
+		getRemovedPagesCalls []getRemovedPagesCall
+	}{
+		{
+			name:                 "not authenticated",
+			authN:                handlertestutils.DefaultAuthN("PROD"),
+			authZ:                handlertestutils.DefaultAuthZ(),
+			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"401 - Unauthorized\",\"code\":\"UNAUTHENTICATED\",\"message\":\"not authenticated\",\"requestId\":\"RQ_TEST\"}}\n",
+			expectedStatusCode:   401,
+		},
+		{
+			name: "happy removed pages, local",
+			headers: map[string]string{
+				"X-USER-ID": "UR_1",
+			},
+			authN:                handlertestutils.DefaultAuthN("LOCAL"),
+			authZ:                handlertestutils.DefaultAuthZ(),
+			expectedResponseBody: "{\"result\":{\"batch\":[{\"versionId\":\"VR_1\",\"pageTemplateId\":\"PGT_1\",\"id\":\"PG_1\",\"title\":\"test title\",\"summary\":\"test summary\",\"permission\":\"PR\",\"createdAt\":null,\"updatedAt\":null}],\"total\":1},\"meta\":{\"httpStatus\":\"200 - OK\",\"requestId\":\"RQ_TEST\"}}\n",
+			expectedStatusCode:   200,
+			getRemovedPagesCalls: []getRemovedPagesCall{
+				{
+					pageParams: pageservice.GetRemovedPagesParams{
+						UserID: "UR_1",
+					},
+					returnPages: []page.Page{
+						getPage("PG_1", "test title", "test summary", "VR_1", "PGT_1", permission.TypePrivate),
+					},
+					returnTotal: 1,
+				},
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			pageService := new(mocks.PageService)
+			for index := range tc.getRemovedPagesCalls {
+				pageService.On("GetRemovedPages", mock.Anything, tc.getRemovedPagesCalls[index].pageParams).Return(tc.getRemovedPagesCalls[index].returnPages, tc.getRemovedPagesCalls[index].returnTotal, tc.getRemovedPagesCalls[index].returnNextBatchID, tc.getRemovedPagesCalls[index].returnErr)
+			}
+			routerHandlers := PageRouterHandlers(tc.authZ.APIPath, pageService)
+			resp, respBody := handlertestutils.HandleTestRequest(handlertestutils.HandleTestRequestParams{
+				Method:         http.MethodGet,
+				Endpoint:       "trash/pages",
+				Headers:        tc.headers,
+				Body:           strings.NewReader(""),
+				RouterHandlers: routerHandlers,
+				AuthZ:          tc.authZ,
+				AuthN:          tc.authN,
+			})
+			require.Equal(t, tc.expectedResponseBody, respBody)
+			require.Equal(t, tc.expectedStatusCode, resp.StatusCode)
+			pageService.AssertNumberOfCalls(t, "GetRemovedPages", len(tc.getRemovedPagesCalls))
+		})
+	}
+}
+
+type restorePageCall struct {
+	pageParams pageservice.RestorePageParams
+	returnErr  error
+}
+
+func TestRestorePage(t *testing.T) {
+	cases := []struct {
+		name                 string
+		pageID               string
+		headers              map[string]string
+		authN                api.AuthN
+		authZ                api.AuthZ
+		expectedResponseBody string
+		expectedStatusCode   int
+		restorePageCalls     []restorePageCall
+	}{
+		{
+			name:   "happy restore, local",
+			pageID: "PG_1",
+			headers: map[string]string{
+				"X-USER-ID": "UR_1",
+			},
+			authN:                handlertestutils.DefaultAuthN("LOCAL"),
+			authZ:                handlertestutils.DefaultAuthZ(),
+			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"200 - OK\",\"requestId\":\"RQ_TEST\"}}\n",
+			expectedStatusCode:   200,
+			restorePageCalls: []restorePageCall{
+				{
+					pageParams: pageservice.RestorePageParams{
+						Page:   page.Page{GUID: "PG_1"},
+						UserID: "UR_1",
+					},
+				},
+			},
+		},
+		{
+			name:   "page not in trash",
+			pageID: "PG_1",
+			headers: map[string]string{
+				"X-USER-ID": "UR_1",
+			},
+			authN:                handlertestutils.DefaultAuthN("LOCAL"),
+			authZ:                handlertestutils.DefaultAuthZ(),
+			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"404 - Not Found\",\"code\":\"NOT_FOUND\",\"message\":\"page not found in trash\",\"requestId\":\"RQ_TEST\"}}\n",
+			expectedStatusCode:   404,
+			restorePageCalls: []restorePageCall{
+				{
+					pageParams: pageservice.RestorePageParams{
+						Page:   page.Page{GUID: "PG_1"},
+						UserID: "UR_1",
+					},
+					returnErr: errors.Wrap(&storeerror.NotFound{ID: "PG_1"}, "failed to restore page"),
+				},
+			},
+		},
+		{
+			name:   "not authorized",
+			pageID: "PG_1",
+			headers: map[string]string{
+				"X-USER-ID": "UR_1",
+			},
+			authN:                handlertestutils.DefaultAuthN("LOCAL"),
+			authZ:                handlertestutils.DefaultAuthZ(),
+			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"403 - Forbidden\",\"code\":\"FORBIDDEN\",\"message\":\"not authorized\",\"requestId\":\"RQ_TEST\"}}\n",
+			expectedStatusCode:   403,
+			restorePageCalls: []restorePageCall{
+				{
+					pageParams: pageservice.RestorePageParams{
+						Page:   page.Page{GUID: "PG_1"},
+						UserID: "UR_1",
+					},
+					returnErr: &storeerror.NotAuthorized{UserID: "UR_1", TableID: "PG_1"},
+				},
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			pageService := new(mocks.PageService)
+			for index := range tc.restorePageCalls {
+				pageService.On("RestorePage", mock.Anything, tc.restorePageCalls[index].pageParams).Return(tc.restorePageCalls[index].returnErr)
+			}
+			routerHandlers := PageRouterHandlers(tc.authZ.APIPath, pageService)
+			resp, respBody := handlertestutils.HandleTestRequest(handlertestutils.HandleTestRequestParams{
+				Method:         http.MethodPost,
+				Endpoint:       fmt.Sprintf("trash/pages/%v/restore", tc.pageID),
+				Headers:        tc.headers,
+				Body:           strings.NewReader(""),
+				RouterHandlers: routerHandlers,
+				AuthZ:          tc.authZ,
+				AuthN:          tc.authN,
+			})
+			require.Equal(t, tc.expectedResponseBody, respBody)
+			require.Equal(t, tc.expectedStatusCode, resp.StatusCode)
+			pageService.AssertNumberOfCalls(t, "RestorePage", len(tc.restorePageCalls))
+		})
+	}
+}
+
+type purgePageCall struct {
+	pageParams pageservice.PurgePageParams
+	returnErr  error
+}
+
+func TestPurgePage(t *testing.T) {
+	cases := []struct {
+		name                 string
+		pageID               string
+		headers              map[string]string
+		authN                api.AuthN
+		authZ                api.AuthZ
+		expectedResponseBody string
+		expectedStatusCode   int
+		purgePageCalls       []purgePageCall
+	}{
+		{
+			name:   "happy purge, local",
+			pageID: "PG_1",
+			headers: map[string]string{
+				"X-USER-ID": "UR_1",
+			},
+			authN:                handlertestutils.DefaultAuthN("LOCAL"),
+			authZ:                handlertestutils.DefaultAuthZ(),
+			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"200 - OK\",\"requestId\":\"RQ_TEST\"}}\n",
+			expectedStatusCode:   200,
+			purgePageCalls: []purgePageCall{
+				{
+					pageParams: pageservice.PurgePageParams{
+						Page:   page.Page{GUID: "PG_1"},
+						UserID: "UR_1",
+					},
+				},
+			},
+		},
+		{
+			name:   "page not in trash",
+			pageID: "PG_1",
+			headers: map[string]string{
+				"X-USER-ID": "UR_1",
+			},
+			authN:                handlertestutils.DefaultAuthN("LOCAL"),
+			authZ:                handlertestutils.DefaultAuthZ(),
+			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"404 - Not Found\",\"code\":\"NOT_FOUND\",\"message\":\"page not found in trash\",\"requestId\":\"RQ_TEST\"}}\n",
+			expectedStatusCode:   404,
+			purgePageCalls: []purgePageCall{
+				{
+					pageParams: pageservice.PurgePageParams{
+						Page:   page.Page{GUID: "PG_1"},
+						UserID: "UR_1",
+					},
+					returnErr: errors.Wrap(&storeerror.NotFound{ID: "PG_1"}, "failed to purge page"),
+				},
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			pageService := new(mocks.PageService)
+			for index := range tc.purgePageCalls {
+				pageService.On("PurgePage", mock.Anything, tc.purgePageCalls[index].pageParams).Return(tc.purgePageCalls[index].returnErr)
+			}
+			routerHandlers := PageRouterHandlers(tc.authZ.APIPath, pageService)
+			resp, respBody := handlertestutils.HandleTestRequest(handlertestutils.HandleTestRequestParams{
+				Method:         http.MethodDelete,
+				Endpoint:       fmt.Sprintf("trash/pages/%v", tc.pageID),
+				Headers:        tc.headers,
+				Body:           strings.NewReader(""),
+				RouterHandlers: routerHandlers,
+				AuthZ:          tc.authZ,
+				AuthN:          tc.authN,
+			})
+			require.Equal(t, tc.expectedResponseBody, respBody)
+			require.Equal(t, tc.expectedStatusCode, resp.StatusCode)
+			pageService.AssertNumberOfCalls(t, "PurgePage", len(tc.purgePageCalls))
+		})
+	}
+}
@@ -5,6 +5,7 @@ package mocks
 import context "context"
 import mock "github.com/stretchr/testify/mock"
 import page "github.com/worlve/sp-service/internal/models/page"
+import property "github.com/worlve/sp-service/internal/models/property"
 
 import pageservice "github.com/worlve/sp-service/internal/services/page"
 
@@ -13,6 +14,29 @@ type PageService struct {
 	mock.Mock
 }
 
+// BatchPages provides a mock function with given fields: ctx, params
+func (_m *PageService) BatchPages(ctx context.Context, params pageservice.BatchPagesParams) ([]pageservice.BatchOperationResult, error) {
+	ret := _m.Called(ctx, params)
+
+	var r0 []pageservice.BatchOperationResult
+	if rf, ok := ret.Get(0).(func(context.Context, pageservice.BatchPagesParams) []pageservice.BatchOperationResult); ok {
+		r0 = rf(ctx, params)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]pageservice.BatchOperationResult)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, pageservice.BatchPagesParams) error); ok {
+		r1 = rf(ctx, params)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // CreatePage provides a mock function with given fields: ctx, params
 func (_m *PageService) CreatePage(ctx context.Context, params pageservice.CreatePageParams) (page.Page, error) {
 	ret := _m.Called(ctx, params)
@@ -76,6 +100,29 @@ func (_m *PageService) GetPage(ctx context.Context, params pageservice.GetPagePa
 	return r0, r1
 }
 
+// GetPageProperties provides a mock function with given fields: ctx, params
+func (_m *PageService) GetPageProperties(ctx context.Context, params pageservice.GetPagePropertiesParams) ([]property.Property, error) {
+	ret := _m.Called(ctx, params)
+
+	var r0 []property.Property
+	if rf, ok := ret.Get(0).(func(context.Context, pageservice.GetPagePropertiesParams) []property.Property); ok {
+		r0 = rf(ctx, params)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]property.Property)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, pageservice.GetPagePropertiesParams) error); ok {
+		r1 = rf(ctx, params)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetPages provides a mock function with given fields: ctx, params
 func (_m *PageService) GetPages(ctx context.Context, params pageservice.GetPagesParams) ([]page.Page, int, string, error) {
 	ret := _m.Called(ctx, params)
@@ -113,6 +160,57 @@ func (_m *PageService) GetPages(ctx context.Context, params pageservice.GetPages
 	return r0, r1, r2, r3
 }
 
+// GetRemovedPages provides a mock function with given fields: ctx, params
+func (_m *PageService) GetRemovedPages(ctx context.Context, params pageservice.GetRemovedPagesParams) ([]page.Page, int, string, error) {
+	ret := _m.Called(ctx, params)
+
+	var r0 []page.Page
+	if rf, ok := ret.Get(0).(func(context.Context, pageservice.GetRemovedPagesParams) []page.Page); ok {
+		r0 = rf(ctx, params)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]page.Page)
+		}
+	}
+
+	var r1 int
+	if rf, ok := ret.Get(1).(func(context.Context, pageservice.GetRemovedPagesParams) int); ok {
+		r1 = rf(ctx, params)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+
+	var r2 string
+	if rf, ok := ret.Get(2).(func(context.Context, pageservice.GetRemovedPagesParams) string); ok {
+		r2 = rf(ctx, params)
+	} else {
+		r2 = ret.Get(2).(string)
+	}
+
+	var r3 error
+	if rf, ok := ret.Get(3).(func(context.Context, pageservice.GetRemovedPagesParams) error); ok {
+		r3 = rf(ctx, params)
+	} else {
+		r3 = ret.Error(3)
+	}
+
+	return r0, r1, r2, r3
+}
+
+// PurgePage provides a mock function with given fields: ctx, params
+func (_m *PageService) PurgePage(ctx context.Context, params pageservice.PurgePageParams) error {
+	ret := _m.Called(ctx, params)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, pageservice.PurgePageParams) error); ok {
+		r0 = rf(ctx, params)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // RemovePage provides a mock function with given fields: ctx, params
 func (_m *PageService) RemovePage(ctx context.Context, params pageservice.RemovePageParams) error {
 	ret := _m.Called(ctx, params)
@@ -127,6 +225,34 @@ func (_m *PageService) RemovePage(ctx context.Context, params pageservice.Remove
 	return r0
 }
 
+// ReplacePageProperties provides a mock function with given fields: ctx, params
+func (_m *PageService) ReplacePageProperties(ctx context.Context, params pageservice.ReplacePagePropertiesParams) error {
+	ret := _m.Called(ctx, params)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, pageservice.ReplacePagePropertiesParams) error); ok {
+		r0 = rf(ctx, params)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RestorePage provides a mock function with given fields: ctx, params
+func (_m *PageService) RestorePage(ctx context.Context, params pageservice.RestorePageParams) error {
+	ret := _m.Called(ctx, params)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, pageservice.RestorePageParams) error); ok {
+		r0 = rf(ctx, params)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpdatePage provides a mock function with given fields: ctx, params
 func (_m *PageService) UpdatePage(ctx context.Context, params pageservice.UpdatePageParams) error {
 	ret := _m.Called(ctx, params)
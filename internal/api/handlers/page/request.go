@@ -2,21 +2,24 @@ package pagehandler
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 
+	"github.com/worlve/sp-service/internal/api"
 	"github.com/worlve/sp-service/internal/models/permission"
+	"github.com/worlve/sp-service/internal/models/property"
+	pageservice "github.com/worlve/sp-service/internal/services/page"
 	"github.com/julienschmidt/httprouter"
-	"github.com/pkg/errors"
 )
 
 // CreatePageRequest parameters from the CreatePage call
 type CreatePageRequest struct {
-	Title                string `json:"title"`
-	Summary              string `json:"summary"`
-	VersionID            string `json:"versionId"`
-	PermissionTypeString string `json:"permission"`
-	PermissionType       permission.Type
-	PageTemplateID       string `json:"pageTemplateId"`
+	Title                string          `json:"title"`
+	Summary              string          `json:"summary"`
+	VersionID            string          `json:"versionId"`
+	PermissionTypeString string          `json:"permission"`
+	PermissionType       permission.Type `json:"-"`
+	PageTemplateID       string          `json:"pageTemplateId"`
 }
 
 // NewCreatePageRequest extracts the CreatePageRequest
@@ -24,24 +27,24 @@ func NewCreatePageRequest(r *http.Request, p httprouter.Params) (CreatePageReque
 	var request CreatePageRequest
 	err := json.NewDecoder(r.Body).Decode(&request)
 	if err != nil {
-		return request, errors.New("invalid request")
+		return request, api.InvalidRequest("invalid request")
 	}
 	return request.validate()
 }
 
 func (request CreatePageRequest) validate() (CreatePageRequest, error) {
 	if request.Title == "" {
-		return request, errors.New("must provide title")
+		return request, api.InvalidField("title", "must provide title")
 	}
 	if request.VersionID == "" {
-		return request, errors.New("must provide versionId")
+		return request, api.InvalidField("versionId", "must provide versionId")
 	}
 	if request.PageTemplateID == "" {
-		return request, errors.New("must provide pageTemplateId")
+		return request, api.InvalidField("pageTemplateId", "must provide pageTemplateId")
 	}
 	permissionType, err := permission.GetPermissionType(request.PermissionTypeString)
 	if err != nil {
-		return request, errors.New("permission is not a valid value")
+		return request, api.InvalidField("permission", "permission is not a valid value")
 	}
 	request.PermissionType = permissionType
 	return request, nil
@@ -49,13 +52,13 @@ func (request CreatePageRequest) validate() (CreatePageRequest, error) {
 
 // UpdatePageRequest parameters from the UpdatePage call
 type UpdatePageRequest struct {
-	GUID                 string
-	Title                string `json:"title"`
-	Summary              string `json:"summary"`
-	VersionID            string `json:"versionId"`
-	PermissionTypeString string `json:"permission"`
-	PermissionType       permission.Type
-	PageTemplateID       string `json:"pageTemplateId"`
+	GUID                 string          `json:"-"`
+	Title                string          `json:"title"`
+	Summary              string          `json:"summary"`
+	VersionID            string          `json:"versionId"`
+	PermissionTypeString string          `json:"permission"`
+	PermissionType       permission.Type `json:"-"`
+	PageTemplateID       string          `json:"pageTemplateId"`
 }
 
 // NewUpdatePageRequest extracts the UpdatePageRequest
@@ -63,7 +66,7 @@ func NewUpdatePageRequest(r *http.Request, p httprouter.Params) (UpdatePageReque
 	var request UpdatePageRequest
 	err := json.NewDecoder(r.Body).Decode(&request)
 	if err != nil {
-		return request, errors.New("invalid request")
+		return request, api.InvalidRequest("invalid request")
 	}
 	request.GUID = p.ByName(PageIDRouteKey)
 	return request.validate()
@@ -71,12 +74,12 @@ func NewUpdatePageRequest(r *http.Request, p httprouter.Params) (UpdatePageReque
 
 func (request UpdatePageRequest) validate() (UpdatePageRequest, error) {
 	if request.GUID == "" {
-		return request, errors.New("must provide a page id")
+		return request, api.InvalidField("id", "must provide a page id")
 	}
 	if request.PermissionTypeString != "" {
 		permissionType, err := permission.GetPermissionType(request.PermissionTypeString)
 		if err != nil {
-			return request, errors.New("permission is not a valid value")
+			return request, api.InvalidField("permission", "permission is not a valid value")
 		}
 		request.PermissionType = permissionType
 	}
@@ -110,7 +113,7 @@ func NewGetPageRequest(r *http.Request, p httprouter.Params) (GetPageRequest, er
 
 func (request GetPageRequest) validate() (GetPageRequest, error) {
 	if request.GUID == "" {
-		return request, errors.New("must provide a page id")
+		return request, api.InvalidField("id", "must provide a page id")
 	}
 	return request, nil
 }
@@ -129,7 +132,7 @@ func NewDeletePageRequest(r *http.Request, p httprouter.Params) (DeletePageReque
 
 func (request DeletePageRequest) validate() (DeletePageRequest, error) {
 	if request.GUID == "" {
-		return request, errors.New("must provide a page id")
+		return request, api.InvalidField("id", "must provide a page id")
 	}
 	return request, nil
 }
@@ -149,3 +152,143 @@ func NewGetPagesRequest(r *http.Request, p httprouter.Params) (GetPagesRequest,
 func (request GetPagesRequest) validate() (GetPagesRequest, error) {
 	return request, nil
 }
+
+// GetPagePropertiesRequest parameters from the GetPageProperties call
+type GetPagePropertiesRequest struct {
+	GUID string
+}
+
+// NewGetPagePropertiesRequest extracts the GetPagePropertiesRequest
+func NewGetPagePropertiesRequest(r *http.Request, p httprouter.Params) (GetPagePropertiesRequest, error) {
+	request, err := NewGetPageRequest(r, p)
+	return GetPagePropertiesRequest{
+		GUID: request.GUID,
+	}, err
+}
+
+// ReplacePagePropertiesRequest parameters from the ReplacePageProperties call
+type ReplacePagePropertiesRequest struct {
+	GUID       string
+	Properties []property.Property
+}
+
+// NewReplacePagePropertiesRequest extracts the ReplacePagePropertiesRequest
+func NewReplacePagePropertiesRequest(r *http.Request, p httprouter.Params) (ReplacePagePropertiesRequest, error) {
+	var request ReplacePagePropertiesRequest
+	err := json.NewDecoder(r.Body).Decode(&request.Properties)
+	if err != nil {
+		return request, api.InvalidRequest("invalid request")
+	}
+	request.GUID = p.ByName(PageIDRouteKey)
+	return request.validate()
+}
+
+func (request ReplacePagePropertiesRequest) validate() (ReplacePagePropertiesRequest, error) {
+	if request.GUID == "" {
+		return request, api.InvalidField("id", "must provide a page id")
+	}
+	for i := range request.Properties {
+		if request.Properties[i].Key == "" {
+			return request, api.InvalidField(fmt.Sprintf("[%v].key", i), fmt.Sprintf("property at %v must provide a key", i))
+		}
+		propertyType, err := property.GetPropertyType(string(request.Properties[i].Type))
+		if err != nil {
+			return request, api.InvalidField(fmt.Sprintf("[%v].type", i), fmt.Sprintf("property at %v does not have a valid type", i))
+		}
+		request.Properties[i].Type = propertyType
+	}
+	return request, nil
+}
+
+// MaxBatchOperations is the most operations that can be given in a single BatchPages call.
+const MaxBatchOperations = 500
+
+// BatchPagesRequest parameters from the BatchPages call
+type BatchPagesRequest struct {
+	Atomic     bool                    `json:"atomic"`
+	Operations []BatchOperationRequest `json:"operations"`
+}
+
+// BatchOperationRequest parameters for a single operation within the BatchPages call
+type BatchOperationRequest struct {
+	Type                 pageservice.BatchOperationType `json:"op"`
+	GUID                 string                         `json:"id"`
+	Title                string                         `json:"title"`
+	Summary              string                         `json:"summary"`
+	VersionID            string                         `json:"versionId"`
+	PermissionTypeString string                         `json:"permission"`
+	PermissionType       permission.Type                `json:"-"`
+	PageTemplateID       string                         `json:"pageTemplateId"`
+}
+
+// NewBatchPagesRequest extracts the BatchPagesRequest
+func NewBatchPagesRequest(r *http.Request, p httprouter.Params) (BatchPagesRequest, error) {
+	var request BatchPagesRequest
+	if p.ByName(PageActionRouteKey) != BatchPageAction {
+		return request, api.InvalidRequest("unsupported page action")
+	}
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		return request, api.InvalidRequest("invalid request")
+	}
+	return request.validate()
+}
+
+func (request BatchPagesRequest) validate() (BatchPagesRequest, error) {
+	if len(request.Operations) == 0 {
+		return request, api.InvalidField("operations", "must provide at least one operation")
+	}
+	if len(request.Operations) > MaxBatchOperations {
+		return request, api.InvalidField("operations", fmt.Sprintf("must provide no more than %v operations", MaxBatchOperations))
+	}
+	for i := range request.Operations {
+		operation, err := request.Operations[i].validate()
+		if err != nil {
+			return request, api.NestFields(fmt.Sprintf("operations[%v]", i), err)
+		}
+		request.Operations[i] = operation
+	}
+	return request, nil
+}
+
+func (request BatchOperationRequest) validate() (BatchOperationRequest, error) {
+	switch request.Type {
+	case pageservice.BatchOperationCreate:
+		if request.GUID != "" {
+			return request, api.InvalidField("id", "must not provide a page id to create a page")
+		}
+		createRequest, err := CreatePageRequest{
+			Title:                request.Title,
+			Summary:              request.Summary,
+			VersionID:            request.VersionID,
+			PermissionTypeString: request.PermissionTypeString,
+			PageTemplateID:       request.PageTemplateID,
+		}.validate()
+		request.PermissionType = createRequest.PermissionType
+		return request, err
+	case pageservice.BatchOperationUpdate:
+		updateRequest, err := UpdatePageRequest{
+			GUID:                 request.GUID,
+			PermissionTypeString: request.PermissionTypeString,
+		}.validate()
+		request.PermissionType = updateRequest.PermissionType
+		return request, err
+	case pageservice.BatchOperationPermission:
+		if request.GUID == "" {
+			return request, api.InvalidField("id", "must provide a page id")
+		}
+		permissionType, err := permission.GetPermissionType(request.PermissionTypeString)
+		if err != nil {
+			return request, api.InvalidField("permission", "permission is not a valid value")
+		}
+		request.PermissionType = permissionType
+		return request, nil
+	case pageservice.BatchOperationRemove:
+		_, err := DeletePageRequest{
+			GUID: request.GUID,
+		}.validate()
+		return request, err
+	default:
+		return request, api.InvalidField("op", "op must be one of create, update, permission, or remove")
+	}
+}
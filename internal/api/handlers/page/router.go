@@ -5,13 +5,25 @@ import (
 	"net/http"
 
 	"github.com/worlve/sp-service/internal/api"
+	"github.com/worlve/sp-service/internal/models/page"
+	"github.com/worlve/sp-service/internal/models/property"
 )
 
 // HTTP path fragments keys
 const (
-	PageIDRouteKey = "pageID"
+	PageIDRouteKey     = "pageID"
+	PageActionRouteKey = "action"
 )
 
+// BatchPageAction is the custom action for running a batch of page operations, as in "/pages:batch".
+// Note that the route key's value includes the leading ":".
+const BatchPageAction = ":batch"
+
+var nextBatchIDQueryParam = api.QueryParam{
+	Name:        "nextBatchId",
+	Description: "If the request is batched, to get the next batch set this parameter based on the response's result.nextBatch.",
+}
+
 // PageRouterHandlers returns the requests for the associated routes.
 func PageRouterHandlers(apiPath string, pageService PageService) []api.RouterHandler {
 	handler := PageHandler{
@@ -22,41 +34,150 @@ func PageRouterHandlers(apiPath string, pageService PageService) []api.RouterHan
 		Method:   http.MethodPost,
 		Endpoint: fmt.Sprintf("/%v/pages", apiPath),
 		Handle:   handler.CreatePage,
+		Doc: &api.RouteDoc{
+			OperationID: "createPage",
+			Summary:     "Create Page",
+			Description: "Creates a new page.",
+			Tag:         "page",
+			Request:     CreatePageRequest{},
+			Response:    CreatePageResponse{},
+		},
+	})
+	routerHandlers = append(routerHandlers, api.RouterHandler{
+		Method:   http.MethodPost,
+		Endpoint: fmt.Sprintf("/%v/pages:%v", apiPath, PageActionRouteKey),
+		Handle:   handler.BatchPages,
+		Doc: &api.RouteDoc{
+			OperationID: "batchPages",
+			Summary:     "Batch Pages",
+			Description: fmt.Sprintf("Runs up to %v create, update, permission, or remove page operations in a single request. "+
+				"Each operation has its own result, in the same order as the request. If atomic is set, every operation is run in a single transaction; "+
+				"if any operation fails, none are applied and the remaining operations are reported as 424 - Failed Dependency.", MaxBatchOperations),
+			Tag:            "page",
+			Path:           fmt.Sprintf("/%v/pages%v", apiPath, BatchPageAction),
+			Request:        BatchPagesRequest{},
+			Response:       BatchPagesResponse{},
+			ErrorHasResult: true,
+		},
 	})
 	routerHandlers = append(routerHandlers, api.RouterHandler{
 		Method:   http.MethodPatch,
 		Endpoint: fmt.Sprintf("/%v/pages/:%v", apiPath, PageIDRouteKey),
 		Handle:   handler.UpdatePage,
+		Doc: &api.RouteDoc{
+			OperationID: "updatePage",
+			Summary:     "Update Page",
+			Description: "Updates the provided page with any of the parameters provided. Anything not provided or set to empty is ignored.",
+			Tag:         "page",
+			Request:     UpdatePageRequest{},
+		},
 	})
 	routerHandlers = append(routerHandlers, api.RouterHandler{
 		Method:   http.MethodDelete,
 		Endpoint: fmt.Sprintf("/%v/pages/:%v", apiPath, PageIDRouteKey),
 		Handle:   handler.DeletePage,
+		Doc: &api.RouteDoc{
+			OperationID: "removePage",
+			Summary:     "Remove Page",
+			Description: "Moves the provided page to the trash, removing it from all queries.",
+			Tag:         "page",
+		},
 	})
 	routerHandlers = append(routerHandlers, api.RouterHandler{
 		Method:   http.MethodGet,
 		Endpoint: fmt.Sprintf("/%v/pages", apiPath),
 		Handle:   handler.GetPages,
+		Doc: &api.RouteDoc{
+			OperationID: "getPages",
+			Summary:     "Get Pages",
+			Description: "Get a paginated list of the user's pages.",
+			Tag:         "page",
+			Query:       []api.QueryParam{nextBatchIDQueryParam},
+			Response:    PageBatchResponse{},
+		},
 	})
 	routerHandlers = append(routerHandlers, api.RouterHandler{
 		Method:   http.MethodGet,
 		Endpoint: fmt.Sprintf("/%v/pages/:%v", apiPath, PageIDRouteKey),
 		Handle:   handler.GetPage,
+		Doc: &api.RouteDoc{
+			OperationID: "getPage",
+			Summary:     "Get Page",
+			Description: "Get the provided page.",
+			Tag:         "page",
+			Response:    page.ReducedPage{},
+		},
 	})
 	routerHandlers = append(routerHandlers, api.RouterHandler{
 		Method:   http.MethodGet,
 		Endpoint: fmt.Sprintf("/%v/pages/:%v/full", apiPath, PageIDRouteKey),
 		Handle:   handler.GetEntirePage,
+		Doc: &api.RouteDoc{
+			OperationID: "getEntirePage",
+			Summary:     "Get Entire Page",
+			Description: "Get the provided page, including properties, details, relations, etc.",
+			Tag:         "full page",
+			Response:    page.Page{},
+		},
 	})
 	routerHandlers = append(routerHandlers, api.RouterHandler{
 		Method:   http.MethodGet,
 		Endpoint: fmt.Sprintf("/%v/pages/:%v/properties", apiPath, PageIDRouteKey),
 		Handle:   handler.GetPageProperties,
+		Doc: &api.RouteDoc{
+			OperationID: "getPageProperties",
+			Summary:     "Get Page Properties",
+			Description: "Get the provided page's properties, in order.",
+			Tag:         "page properties",
+			Response:    []property.Property{},
+		},
 	})
 	routerHandlers = append(routerHandlers, api.RouterHandler{
 		Method:   http.MethodPut,
 		Endpoint: fmt.Sprintf("/%v/pages/:%v/properties", apiPath, PageIDRouteKey),
 		Handle:   handler.ReplacePageProperties,
+		Doc: &api.RouteDoc{
+			OperationID: "replacePageProperties",
+			Summary:     "Replace Page Properties",
+			Description: "Replaces all current properties with the provided properties for the provided page in the provided order.",
+			Tag:         "page properties",
+			Request:     []property.Property{},
+		},
+	})
+	routerHandlers = append(routerHandlers, api.RouterHandler{
+		Method:   http.MethodGet,
+		Endpoint: fmt.Sprintf("/%v/trash/pages", apiPath),
+		Handle:   handler.GetRemovedPages,
+		Doc: &api.RouteDoc{
+			OperationID: "getRemovedPages",
+			Summary:     "Get Removed Pages",
+			Description: "Get a paginated list of the user's removed pages. Removed pages are purged automatically after the retention period.",
+			Tag:         "trash",
+			Query:       []api.QueryParam{nextBatchIDQueryParam},
+			Response:    PageBatchResponse{},
+		},
+	})
+	routerHandlers = append(routerHandlers, api.RouterHandler{
+		Method:   http.MethodPost,
+		Endpoint: fmt.Sprintf("/%v/trash/pages/:%v/restore", apiPath, PageIDRouteKey),
+		Handle:   handler.RestorePage,
+		Doc: &api.RouteDoc{
+			OperationID: "restorePage",
+			Summary:     "Restore Page",
+			Description: "Restores the provided removed page, along with its properties and details.",
+			Tag:         "trash",
+		},
+	})
+	routerHandlers = append(routerHandlers, api.RouterHandler{
+		Method:   http.MethodDelete,
+		Endpoint: fmt.Sprintf("/%v/trash/pages/:%v", apiPath, PageIDRouteKey),
+		Handle:   handler.PurgePage,
+		Doc: &api.RouteDoc{
+			OperationID: "purgePage",
+			Summary:     "Purge Page",
+			Description: "Permanently deletes the provided removed page. This cannot be undone.",
+			Tag:         "trash",
+		},
 	})
 	return routerHandlers
 }
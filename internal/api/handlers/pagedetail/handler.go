@@ -8,7 +8,6 @@ import (
 
 	"github.com/worlve/sp-service/internal/api"
 	pagedetailservice "github.com/worlve/sp-service/internal/services/pagedetail"
-	"github.com/worlve/sp-service/internal/stores/storeerror"
 
 	"github.com/julienschmidt/httprouter"
 	"github.com/pkg/errors"
@@ -28,7 +27,7 @@ type PageDetailHandler struct {
 func (h PageDetailHandler) UpdatePageDetail(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
 	request, err := NewUpdatePageDetailRequest(r, p)
 	if err != nil {
-		api.RespondWith(r, w, http.StatusBadRequest, err, err)
+		api.RespondWithError(r, w, err)
 		return
 	}
 	ctx := r.Context()
@@ -39,19 +38,17 @@ func (h PageDetailHandler) UpdatePageDetail(w http.ResponseWriter, r *http.Reque
 	}
 	err = h.PageDetailService.UpdatePageDetail(ctx, pagedetailservice.UpdatePageDetailParams{
 		Detail: pagedetail.PageDetail{
+			GUID:    request.PageDetailGUID,
 			Title:   request.Title,
 			Summary: request.Summary,
 			//@TODO:
 			// Partitions: request.Partitions,
 		},
+		PageID: request.PageGUID,
 		UserID: authData.UserID,
 	})
-	if _, ok := err.(*storeerror.NotAuthorized); ok {
-		api.RespondWith(r, w, http.StatusUnauthorized, &api.FailedAuthorization{}, err)
-		return
-	}
 	if err != nil {
-		api.RespondWith(r, w, http.StatusInternalServerError, &api.InternalErr{}, err)
+		api.RespondWithError(r, w, err)
 		return
 	}
 	api.RespondWith(r, w, http.StatusOK, nil, nil)
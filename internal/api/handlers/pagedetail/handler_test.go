@@ -1,11 +1,11 @@
 package pagedetailhandler
 
 import (
-	"fmt"
 	"net/http"
 	"strings"
 	"testing"
 
+	"github.com/worlve/sp-service/internal/models/pagedetail"
 	pagedetailservice "github.com/worlve/sp-service/internal/services/pagedetail"
 	"github.com/worlve/sp-service/internal/stores/storeerror"
 	"github.com/pkg/errors"
@@ -15,7 +15,7 @@ import (
 
 	"github.com/worlve/sp-service/internal/api"
 	"github.com/worlve/sp-service/internal/api/handlers/handlertestutils"
-	"github.com/worlve/sp-service/internal/api/handlers/page/mocks"
+	"github.com/worlve/sp-service/internal/api/handlers/pagedetail/mocks"
 )
 
 type updatePageDetailCall struct {
@@ -25,65 +25,98 @@ type updatePageDetailCall struct {
 
 func TestUpdatePageDetail(t *testing.T) {
 	cases := []struct {
-		name                 string
-		pageID               string
-		headers              map[string]string
-		requestBody          string
-		authN                api.AuthN
-		authZ                api.AuthZ
-		datacenter           string
-		expectedResponseBody string
-		expectedStatusCode   int
-		updatePageCalls      []updatePageCall
+		name                  string
+		endpoint              string
+		headers               map[string]string
+		requestBody           string
+		authN                 api.AuthN
+		authZ                 api.AuthZ
+		expectedResponseBody  string
+		expectedStatusCode    int
+		updatePageDetailCalls []updatePageDetailCall
 	}{
 		{
 			name:                 "not authenticated",
-			pageID:               "PG_1",
+			endpoint:             "pages/PG_1/details/DT_1",
 			authN:                handlertestutils.DefaultAuthN("PROD"),
 			authZ:                handlertestutils.DefaultAuthZ(),
-			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"401 - Unauthorized\",\"message\":\"not authenticated\"}}\n",
+			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"401 - Unauthorized\",\"code\":\"UNAUTHENTICATED\",\"message\":\"not authenticated\",\"requestId\":\"RQ_TEST\"}}\n",
 			expectedStatusCode:   401,
 		},
 		{
-			name:   "happy page creation, local",
-			pageID: "PG_1",
+			name:     "happy page detail update, local",
+			endpoint: "pages/PG_1/details/DT_1",
 			headers: map[string]string{
 				"X-USER-ID": "UR_1",
 			},
-			requestBody:          "{\"title\":\"test title\",\"summary\":\"test summary\",\"versionId\":1,\"permission\":\"PR\"}",
+			requestBody:          "{\"title\":\"test title\",\"summary\":\"test summary\"}",
 			authN:                handlertestutils.DefaultAuthN("LOCAL"),
 			authZ:                handlertestutils.DefaultAuthZ(),
-			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"200 - OK\"}}\n",
+			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"200 - OK\",\"requestId\":\"RQ_TEST\"}}\n",
 			expectedStatusCode:   200,
-			updatePageCalls: []updatePageCall{
+			updatePageDetailCalls: []updatePageDetailCall{
 				{
-					pageParams: pageservice.UpdatePageParams{
-						Page:   getPage("PG_1", 0, ""),
+					pageDetailParams: pagedetailservice.UpdatePageDetailParams{
+						Detail: pagedetail.PageDetail{
+							GUID:    "DT_1",
+							Title:   "test title",
+							Summary: "test summary",
+						},
+						PageID: "PG_1",
 						UserID: "UR_1",
 					},
 				},
 			},
 		},
 		{
-			name:   "trying to edit a page that you don't have permission to update",
-			pageID: "PG_1",
+			name:     "missing title for the page detail",
+			endpoint: "pages/PG_1/details/DT_1",
 			headers: map[string]string{
 				"X-USER-ID": "UR_1",
 			},
-			requestBody:          "{\"title\":\"test title\",\"summary\":\"test summary\",\"versionId\":1,\"permission\":\"PR\"}",
+			requestBody:          "{\"summary\":\"test summary\"}",
 			authN:                handlertestutils.DefaultAuthN("LOCAL"),
 			authZ:                handlertestutils.DefaultAuthZ(),
-			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"401 - Unauthorized\",\"message\":\"not authorized\"}}\n",
-			expectedStatusCode:   401,
-			updatePageCalls: []updatePageCall{
+			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"400 - Bad Request\",\"code\":\"VALIDATION_FAILED\",\"message\":\"a page detail must retain a title\",\"details\":[{\"field\":\"title\",\"message\":\"a page detail must retain a title\"}],\"requestId\":\"RQ_TEST\"}}\n",
+			expectedStatusCode:   400,
+		},
+		{
+			name:     "page detail fields that aren't in the spec",
+			endpoint: "pages/PG_1/details/DT_1",
+			headers: map[string]string{
+				"X-USER-ID": "UR_1",
+			},
+			requestBody:          "{\"id\":\"DT_2\",\"title\":\"test title\",\"versionId\":1}",
+			authN:                handlertestutils.DefaultAuthN("LOCAL"),
+			authZ:                handlertestutils.DefaultAuthZ(),
+			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"400 - Bad Request\",\"code\":\"VALIDATION_FAILED\",\"message\":\"request does not match the API spec: id: is not allowed; versionId: is not allowed\",\"details\":[{\"field\":\"id\",\"message\":\"is not allowed\"},{\"field\":\"versionId\",\"message\":\"is not allowed\"}],\"requestId\":\"RQ_TEST\"}}\n",
+			expectedStatusCode:   400,
+		},
+		{
+			name:     "trying to update a page detail that you don't have permission to update",
+			endpoint: "pages/PG_1/details/DT_1",
+			headers: map[string]string{
+				"X-USER-ID": "UR_1",
+			},
+			requestBody:          "{\"title\":\"test title\",\"summary\":\"test summary\"}",
+			authN:                handlertestutils.DefaultAuthN("LOCAL"),
+			authZ:                handlertestutils.DefaultAuthZ(),
+			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"403 - Forbidden\",\"code\":\"FORBIDDEN\",\"message\":\"not authorized\",\"requestId\":\"RQ_TEST\"}}\n",
+			expectedStatusCode:   403,
+			updatePageDetailCalls: []updatePageDetailCall{
 				{
-					pageParams: pageservice.UpdatePageParams{
-						Page:   getPage("PG_1", 0, ""),
+					pageDetailParams: pagedetailservice.UpdatePageDetailParams{
+						Detail: pagedetail.PageDetail{
+							GUID:    "DT_1",
+							Title:   "test title",
+							Summary: "test summary",
+						},
+						PageID: "PG_1",
 						UserID: "UR_1",
 					},
 					returnErr: &storeerror.NotAuthorized{
 						UserID:  "UR_1",
-						TableID: "PG_1",
+						TableID: "DT_1",
 						Err:     errors.New("failure"),
 					},
 				},
@@ -91,15 +124,15 @@ func TestUpdatePageDetail(t *testing.T) {
 		},
 	}
 	for _, tc := range cases {
-		t.Run(fmt.Sprintf(tc.name), func(t *testing.T) {
-			pageService := new(mocks.PageService)
-			for index := range tc.updatePageCalls {
-				pageService.On("UpdatePage", mock.Anything, tc.updatePageCalls[index].pageParams).Return(tc.updatePageCalls[index].returnErr)
+		t.Run(tc.name, func(t *testing.T) {
+			pageDetailService := new(mocks.PageDetailService)
+			for index := range tc.updatePageDetailCalls {
+				pageDetailService.On("UpdatePageDetail", mock.Anything, tc.updatePageDetailCalls[index].pageDetailParams).Return(tc.updatePageDetailCalls[index].returnErr)
 			}
-			routerHandlers := PageRouterHandlers(tc.authZ.APIPath, pageService)
+			routerHandlers := PageDetailRouterHandlers(tc.authZ.APIPath, pageDetailService)
 			resp, respBody := handlertestutils.HandleTestRequest(handlertestutils.HandleTestRequestParams{
 				Method:         http.MethodPut,
-				Endpoint:       fmt.Sprintf("pages/%v", tc.pageID),
+				Endpoint:       tc.endpoint,
 				Headers:        tc.headers,
 				Body:           strings.NewReader(tc.requestBody),
 				RouterHandlers: routerHandlers,
@@ -108,7 +141,7 @@ func TestUpdatePageDetail(t *testing.T) {
 			})
 			require.Equal(t, tc.expectedResponseBody, respBody)
 			require.Equal(t, tc.expectedStatusCode, resp.StatusCode)
-			pageService.AssertNumberOfCalls(t, "UpdatePage", len(tc.updatePageCalls))
+			pageDetailService.AssertNumberOfCalls(t, "UpdatePageDetail", len(tc.updatePageDetailCalls))
 		})
 	}
 }
@@ -4,15 +4,15 @@ import (
 	"encoding/json"
 	"net/http"
 
+	"github.com/worlve/sp-service/internal/api"
 	"github.com/worlve/sp-service/internal/models/pagedetail"
 	"github.com/julienschmidt/httprouter"
-	"github.com/pkg/errors"
 )
 
 // UpdatePageDetailRequest parameters from the UpdatePageDetail call
 type UpdatePageDetailRequest struct {
-	PageGUID       string
-	PageDetailGUID string
+	PageGUID       string                 `json:"-"`
+	PageDetailGUID string                 `json:"-"`
 	Title          string                 `json:"title"`
 	Summary        string                 `json:"summary"`
 	Partitions     []pagedetail.Partition `json:"partitions"`
@@ -23,11 +23,11 @@ func NewUpdatePageDetailRequest(r *http.Request, p httprouter.Params) (UpdatePag
 	var request UpdatePageDetailRequest
 	err := json.NewDecoder(r.Body).Decode(&request)
 	if err != nil {
-		return request, errors.New("invalid request")
+		return request, api.InvalidRequest("invalid request")
 	}
 	err = pagedetail.UnmarshalPartitions(request.Partitions)
 	if err != nil {
-		return request, errors.New("not valid page partitions")
+		return request, api.InvalidField("partitions", "not valid page partitions")
 	}
 	request.PageGUID = p.ByName(PageIDRouteKey)
 	request.PageDetailGUID = p.ByName(PageDetailIDRouteKey)
@@ -36,7 +36,7 @@ func NewUpdatePageDetailRequest(r *http.Request, p httprouter.Params) (UpdatePag
 
 func (request UpdatePageDetailRequest) validate() (UpdatePageDetailRequest, error) {
 	if request.Title == "" {
-		return request, errors.New("a page detail must retain a title")
+		return request, api.InvalidField("title", "a page detail must retain a title")
 	}
 	return request, nil
 }
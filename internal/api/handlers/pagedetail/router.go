@@ -23,6 +23,13 @@ func PageDetailRouterHandlers(apiPath string, pageDetailService PageDetailServic
 		Method:   http.MethodPut,
 		Endpoint: fmt.Sprintf("/%v/pages/:%v/details/:%v", apiPath, PageIDRouteKey, PageDetailIDRouteKey),
 		Handle:   handler.UpdatePageDetail,
+		Doc: &api.RouteDoc{
+			OperationID: "setPageDetail",
+			Summary:     "Set Page Detail",
+			Description: "Sets the title and summary of one of the provided page's details.",
+			Tag:         "page details",
+			Request:     UpdatePageDetailRequest{},
+		},
 	})
 	return routerHandlers
 }
@@ -0,0 +1,146 @@
+package streamhandler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/worlve/sp-service/internal/api"
+	"github.com/worlve/sp-service/internal/models/page"
+	"github.com/worlve/sp-service/internal/models/stream"
+	streamservice "github.com/worlve/sp-service/internal/services/stream"
+	"github.com/worlve/sp-service/internal/util/logger"
+	"github.com/julienschmidt/httprouter"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// heartbeatInterval is how often an idle stream is sent a comment, so proxies don't close it.
+const heartbeatInterval = 15 * time.Second
+
+// ResetEvent is sent in place of the backlog when the events after the client's last event ID are no longer kept,
+// so the client has to reload whatever it's showing.
+const ResetEvent = "reset"
+
+// StreamService see Service for more details
+type StreamService interface {
+	CheckPageAccess(ctx context.Context, params streamservice.CheckPageAccessParams) error
+	SubscribePage(ctx context.Context, params streamservice.SubscribePageParams) (*streamservice.Subscription, error)
+	SubscribeUser(ctx context.Context, params streamservice.SubscribeUserParams) (*streamservice.Subscription, error)
+}
+
+// StreamHandler is the handler for the associated API
+type StreamHandler struct {
+	StreamService StreamService
+}
+
+// GetPageEvents see Service for more details
+func (h StreamHandler) GetPageEvents(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	request, err := NewPageEventsRequest(r, p)
+	if err != nil {
+		api.RespondWithError(r, w, err)
+		return
+	}
+	ctx := r.Context()
+	authData, err := api.GetDataFromContext(ctx)
+	if err != nil {
+		api.RespondWith(r, w, http.StatusInternalServerError, &api.InternalErr{}, errors.Wrap(err, "failed to get auth data"))
+		return
+	}
+	sub, err := h.StreamService.SubscribePage(ctx, streamservice.SubscribePageParams{
+		Page:        page.Page{GUID: request.GUID},
+		LastEventID: request.LastEventID,
+		UserID:      authData.UserID,
+	})
+	if err != nil {
+		api.RespondWithError(r, w, err)
+		return
+	}
+	defer sub.Close()
+	h.stream(ctx, w, sub, func(e stream.Event) bool {
+		// the page may have been made private since the event or since the stream started
+		return h.canRead(ctx, request.GUID, authData.UserID)
+	})
+}
+
+// GetUserEvents see Service for more details
+func (h StreamHandler) GetUserEvents(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	request, err := NewUserEventsRequest(r, p)
+	if err != nil {
+		api.RespondWithError(r, w, err)
+		return
+	}
+	ctx := r.Context()
+	authData, err := api.GetDataFromContext(ctx)
+	if err != nil {
+		api.RespondWith(r, w, http.StatusInternalServerError, &api.InternalErr{}, errors.Wrap(err, "failed to get auth data"))
+		return
+	}
+	sub, err := h.StreamService.SubscribeUser(ctx, streamservice.SubscribeUserParams{
+		LastEventID: request.LastEventID,
+		UserID:      authData.UserID,
+	})
+	if err != nil {
+		api.RespondWithError(r, w, err)
+		return
+	}
+	defer sub.Close()
+	h.stream(ctx, w, sub, func(e stream.Event) bool {
+		return h.canRead(ctx, e.PageID, authData.UserID)
+	})
+}
+
+// canRead returns whether the user can read the page's events.
+func (h StreamHandler) canRead(ctx context.Context, pageID, userID string) bool {
+	err := h.StreamService.CheckPageAccess(ctx, streamservice.CheckPageAccessParams{
+		Page:   page.Page{GUID: pageID},
+		UserID: userID,
+	})
+	return err == nil
+}
+
+// stream sends the subscription's backlog and then each new event until the client goes away or the subscription
+// is closed. Events allowed returns false for, such as ones for a page the user can't read, aren't sent.
+func (h StreamHandler) stream(ctx context.Context, w http.ResponseWriter, sub *streamservice.Subscription, allowed func(stream.Event) bool) {
+	s := api.StartEventStream(w)
+	var err error
+	if sub.Missed {
+		err = s.Send(strconv.FormatInt(sub.LastID, 10), ResetEvent, []byte("{}"))
+	}
+	for _, e := range sub.Backlog {
+		if err != nil {
+			break
+		}
+		if allowed(e) {
+			err = send(s, e)
+		}
+	}
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+	for err == nil {
+		select {
+		case <-ctx.Done():
+			return
+		case <-heartbeat.C:
+			err = s.Comment("heartbeat")
+		case e, ok := <-sub.Events:
+			if !ok {
+				return
+			}
+			if allowed(e) {
+				err = send(s, e)
+			}
+		}
+	}
+	logger.GetFromContext(ctx).Info("Event stream ended", zap.String("err", err.Error()))
+}
+
+func send(s *api.EventStream, e stream.Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return errors.Wrapf(err, "failed to encode event: %v", e.ID)
+	}
+	return s.Send(strconv.FormatInt(e.ID, 10), string(e.Type), data)
+}
@@ -0,0 +1,173 @@
+package streamhandler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/worlve/sp-service/internal/api"
+	"github.com/worlve/sp-service/internal/api/handlers/handlertestutils"
+	"github.com/worlve/sp-service/internal/api/handlers/stream/mocks"
+	"github.com/worlve/sp-service/internal/models/event"
+	"github.com/worlve/sp-service/internal/models/page"
+	"github.com/worlve/sp-service/internal/models/stream"
+	streamservice "github.com/worlve/sp-service/internal/services/stream"
+	"github.com/worlve/sp-service/internal/stores/storeerror"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var createdAt = time.Date(2020, 3, 31, 12, 0, 0, 0, time.UTC)
+
+var authZ = handlertestutils.DefaultAuthZ()
+
+// newSubscription returns a closed subscription with the given events before and after it started, so a stream of it
+// sends them all and ends. The events are given IDs counting up from 1.
+func newSubscription(lastEventID int64, before, after []stream.Event) *streamservice.Subscription {
+	log := streamservice.NewLog(2, 0)
+	id := int64(0)
+	for _, e := range before {
+		id++
+		e.ID = id
+		log.Append(e)
+	}
+	sub := log.Subscribe(lastEventID, func(e stream.Event) bool {
+		return true
+	})
+	for _, e := range after {
+		id++
+		e.ID = id
+		log.Append(e)
+	}
+	sub.Close()
+	return sub
+}
+
+func pageEvent(eventType event.Type, pageID string) stream.Event {
+	return stream.Event{
+		Type:      eventType,
+		PageID:    pageID,
+		UserID:    "UR_1",
+		CreatedAt: createdAt,
+		Data:      json.RawMessage(`{"pageId":"` + pageID + `"}`),
+	}
+}
+
+func TestGetPageEvents(t *testing.T) {
+	cases := []struct {
+		name                 string
+		headers              map[string]string
+		params               url.Values
+		subscription         *streamservice.Subscription
+		subscribeErr         error
+		canReadErr           error
+		expectedResponseBody string
+		expectedStatusCode   int
+		subscribeCalls       []streamservice.SubscribePageParams
+	}{
+		{
+			name:    "happy path, resumes after the last event ID",
+			headers: map[string]string{"X-USER-ID": "UR_2", LastEventIDHeaderKey: "1"},
+			subscription: newSubscription(1, []stream.Event{pageEvent(event.TypePageCreated, "PG_1"), pageEvent(event.TypePageUpdated, "PG_1")},
+				[]stream.Event{pageEvent(event.TypePageRemoved, "PG_1")}),
+			expectedResponseBody: "id: 2\nevent: page.updated\ndata: {\"type\":\"page.updated\",\"pageId\":\"PG_1\",\"createdAt\":\"2020-03-31T12:00:00Z\",\"data\":{\"pageId\":\"PG_1\"}}\n\n" +
+				"id: 3\nevent: page.removed\ndata: {\"type\":\"page.removed\",\"pageId\":\"PG_1\",\"createdAt\":\"2020-03-31T12:00:00Z\",\"data\":{\"pageId\":\"PG_1\"}}\n\n",
+			expectedStatusCode: 200,
+			subscribeCalls:     []streamservice.SubscribePageParams{{Page: page.Page{GUID: "PG_1"}, LastEventID: 1, UserID: "UR_2"}},
+		},
+		{
+			name:    "events after the last event ID are gone",
+			params:  url.Values{"lastEventId": []string{"1"}},
+			headers: map[string]string{"X-USER-ID": "UR_2"},
+			subscription: newSubscription(1, []stream.Event{pageEvent(event.TypePageCreated, "PG_1"), pageEvent(event.TypePageUpdated, "PG_1"),
+				pageEvent(event.TypePageUpdated, "PG_1"), pageEvent(event.TypePageUpdated, "PG_1")}, nil),
+			expectedResponseBody: "id: 4\nevent: reset\ndata: {}\n\n",
+			expectedStatusCode:   200,
+			subscribeCalls:       []streamservice.SubscribePageParams{{Page: page.Page{GUID: "PG_1"}, LastEventID: 1, UserID: "UR_2"}},
+		},
+		{
+			name:                 "events for a page made private aren't sent",
+			headers:              map[string]string{"X-USER-ID": "UR_2"},
+			subscription:         newSubscription(0, nil, []stream.Event{pageEvent(event.TypePageUpdated, "PG_1"), pageEvent(event.TypePageDetailUpdated, "PG_1")}),
+			canReadErr:           &storeerror.NotAuthorized{UserID: "UR_2", TableID: "PG_1"},
+			expectedResponseBody: "",
+			expectedStatusCode:   200,
+			subscribeCalls:       []streamservice.SubscribePageParams{{Page: page.Page{GUID: "PG_1"}, UserID: "UR_2"}},
+		},
+		{
+			name:                 "resumed events for a page made private aren't sent",
+			headers:              map[string]string{"X-USER-ID": "UR_2", LastEventIDHeaderKey: "1"},
+			subscription:         newSubscription(1, []stream.Event{pageEvent(event.TypePageCreated, "PG_1"), pageEvent(event.TypePageUpdated, "PG_1")}, nil),
+			canReadErr:           &storeerror.NotAuthorized{UserID: "UR_2", TableID: "PG_1"},
+			expectedResponseBody: "",
+			expectedStatusCode:   200,
+			subscribeCalls:       []streamservice.SubscribePageParams{{Page: page.Page{GUID: "PG_1"}, LastEventID: 1, UserID: "UR_2"}},
+		},
+		{
+			name:                 "page the user can't read",
+			headers:              map[string]string{"X-USER-ID": "UR_2"},
+			subscribeErr:         &storeerror.NotAuthorized{UserID: "UR_2", TableID: "PG_1"},
+			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"403 - Forbidden\",\"code\":\"FORBIDDEN\",\"message\":\"not authorized\",\"requestId\":\"RQ_TEST\"}}\n",
+			expectedStatusCode:   403,
+			subscribeCalls:       []streamservice.SubscribePageParams{{Page: page.Page{GUID: "PG_1"}, UserID: "UR_2"}},
+		},
+		{
+			name:                 "invalid last event ID",
+			headers:              map[string]string{"X-USER-ID": "UR_2", LastEventIDHeaderKey: "abc"},
+			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"400 - Bad Request\",\"code\":\"VALIDATION_FAILED\",\"message\":\"must be the id of an event\",\"details\":[{\"field\":\"lastEventId\",\"message\":\"must be the id of an event\"}],\"requestId\":\"RQ_TEST\"}}\n",
+			expectedStatusCode:   400,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			streamService := new(mocks.StreamService)
+			for _, params := range tc.subscribeCalls {
+				streamService.On("SubscribePage", mock.Anything, params).Return(tc.subscription, tc.subscribeErr)
+			}
+			streamService.On("CheckPageAccess", mock.Anything, streamservice.CheckPageAccessParams{Page: page.Page{GUID: "PG_1"}, UserID: "UR_2"}).Return(tc.canReadErr)
+			resp, respBody := handlertestutils.HandleTestRequest(handlertestutils.HandleTestRequestParams{
+				Method:         http.MethodGet,
+				Endpoint:       "pages/PG_1/events",
+				Params:         tc.params,
+				Headers:        tc.headers,
+				RouterHandlers: StreamRouterHandlers(authZ.APIPath, streamService),
+				AuthZ:          authZ,
+				AuthN:          handlertestutils.DefaultAuthN("LOCAL"),
+			})
+			require.Equal(t, tc.expectedResponseBody, respBody)
+			require.Equal(t, tc.expectedStatusCode, resp.StatusCode)
+			streamService.AssertNumberOfCalls(t, "SubscribePage", len(tc.subscribeCalls))
+			if tc.expectedStatusCode == 200 {
+				require.Equal(t, api.EventStreamContentType, resp.Header.Get("Content-Type"))
+			}
+		})
+	}
+}
+
+func TestGetUserEvents(t *testing.T) {
+	// UR_2 edits UR_1's page PG_1, and someone else's private page PG_2, before and after UR_1 resumes
+	edits := func(pageID string) stream.Event {
+		e := pageEvent(event.TypePageUpdated, pageID)
+		e.UserID = "UR_2"
+		return e
+	}
+	streamService := new(mocks.StreamService)
+	streamService.On("SubscribeUser", mock.Anything, streamservice.SubscribeUserParams{LastEventID: 1, UserID: "UR_1"}).Return(
+		newSubscription(1, []stream.Event{pageEvent(event.TypePageCreated, "PG_1"), edits("PG_2"), edits("PG_1")}, []stream.Event{edits("PG_2"), edits("PG_1")}), nil)
+	streamService.On("CheckPageAccess", mock.Anything, streamservice.CheckPageAccessParams{Page: page.Page{GUID: "PG_1"}, UserID: "UR_1"}).Return(nil)
+	streamService.On("CheckPageAccess", mock.Anything, streamservice.CheckPageAccessParams{Page: page.Page{GUID: "PG_2"}, UserID: "UR_1"}).Return(
+		&storeerror.NotAuthorized{UserID: "UR_1", TableID: "PG_2"})
+	resp, respBody := handlertestutils.HandleTestRequest(handlertestutils.HandleTestRequestParams{
+		Method:         http.MethodGet,
+		Endpoint:       "events",
+		Headers:        map[string]string{"X-USER-ID": "UR_1", LastEventIDHeaderKey: "1"},
+		RouterHandlers: StreamRouterHandlers(authZ.APIPath, streamService),
+		AuthZ:          authZ,
+		AuthN:          handlertestutils.DefaultAuthN("LOCAL"),
+	})
+	require.Equal(t, "id: 3\nevent: page.updated\ndata: {\"type\":\"page.updated\",\"pageId\":\"PG_1\",\"createdAt\":\"2020-03-31T12:00:00Z\",\"data\":{\"pageId\":\"PG_1\"}}\n\n"+
+		"id: 5\nevent: page.updated\ndata: {\"type\":\"page.updated\",\"pageId\":\"PG_1\",\"createdAt\":\"2020-03-31T12:00:00Z\",\"data\":{\"pageId\":\"PG_1\"}}\n\n", respBody)
+	require.Equal(t, 200, resp.StatusCode)
+}
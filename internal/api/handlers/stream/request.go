@@ -0,0 +1,58 @@
+package streamhandler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/worlve/sp-service/internal/api"
+	"github.com/julienschmidt/httprouter"
+)
+
+// LastEventIDHeaderKey is the header a reconnecting EventSource sends with the ID of the last event it received.
+const LastEventIDHeaderKey = "Last-Event-ID"
+
+// PageEventsRequest parameters from the GetPageEvents call
+type PageEventsRequest struct {
+	GUID        string
+	LastEventID int64
+}
+
+// NewPageEventsRequest extracts the PageEventsRequest
+func NewPageEventsRequest(r *http.Request, p httprouter.Params) (PageEventsRequest, error) {
+	var request PageEventsRequest
+	request.GUID = p.ByName(PageIDRouteKey)
+	if request.GUID == "" {
+		return request, api.InvalidField("id", "must provide a page id")
+	}
+	lastEventID, err := getLastEventID(r)
+	request.LastEventID = lastEventID
+	return request, err
+}
+
+// UserEventsRequest parameters from the GetUserEvents call
+type UserEventsRequest struct {
+	LastEventID int64
+}
+
+// NewUserEventsRequest extracts the UserEventsRequest
+func NewUserEventsRequest(r *http.Request, p httprouter.Params) (UserEventsRequest, error) {
+	lastEventID, err := getLastEventID(r)
+	return UserEventsRequest{LastEventID: lastEventID}, err
+}
+
+// getLastEventID returns the event ID to resume the stream after, taken from the Last-Event-ID header or,
+// since an EventSource can't set headers on its first request, the lastEventId query param.
+func getLastEventID(r *http.Request) (int64, error) {
+	value := r.Header.Get(LastEventIDHeaderKey)
+	if value == "" {
+		value = r.URL.Query().Get("lastEventId")
+	}
+	if value == "" {
+		return 0, nil
+	}
+	lastEventID, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || lastEventID < 0 {
+		return 0, api.InvalidField("lastEventId", "must be the id of an event")
+	}
+	return lastEventID, nil
+}
@@ -0,0 +1,53 @@
+package streamhandler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/worlve/sp-service/internal/api"
+)
+
+// HTTP path fragments keys
+const (
+	PageIDRouteKey = "pageID"
+)
+
+var lastEventIDQueryParam = api.QueryParam{
+	Name:        "lastEventId",
+	Description: "The id of the last event received, to resume the stream after. The Last-Event-ID header is used instead when it's sent.",
+}
+
+// StreamRouterHandlers returns the requests for the associated routes.
+func StreamRouterHandlers(apiPath string, streamService StreamService) []api.RouterHandler {
+	handler := StreamHandler{
+		StreamService: streamService,
+	}
+	var routerHandlers []api.RouterHandler
+	routerHandlers = append(routerHandlers, api.RouterHandler{
+		Method:   http.MethodGet,
+		Endpoint: fmt.Sprintf("/%v/pages/:%v/events", apiPath, PageIDRouteKey),
+		Handle:   handler.GetPageEvents,
+		Doc: &api.RouteDoc{
+			OperationID:          "streamPageEvents",
+			Summary:              "Stream Page Events",
+			Description:          "Streams the changes made to the page as server-sent events, while the user can read it.",
+			Tag:                  "events",
+			Query:                []api.QueryParam{lastEventIDQueryParam},
+			ResponseContentTypes: []string{api.EventStreamContentType},
+		},
+	})
+	routerHandlers = append(routerHandlers, api.RouterHandler{
+		Method:   http.MethodGet,
+		Endpoint: fmt.Sprintf("/%v/events", apiPath),
+		Handle:   handler.GetUserEvents,
+		Doc: &api.RouteDoc{
+			OperationID:          "streamUserEvents",
+			Summary:              "Stream User Events",
+			Description:          "Streams the changes anyone makes to the pages the user owns or that are shared with them, and the changes the user makes, as server-sent events.",
+			Tag:                  "events",
+			Query:                []api.QueryParam{lastEventIDQueryParam},
+			ResponseContentTypes: []string{api.EventStreamContentType},
+		},
+	})
+	return routerHandlers
+}
@@ -0,0 +1,142 @@
+package webhookhandler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/worlve/sp-service/internal/api"
+	"github.com/worlve/sp-service/internal/api/handlers/nextbatch"
+	"github.com/worlve/sp-service/internal/models/webhook"
+	webhookservice "github.com/worlve/sp-service/internal/services/webhook"
+	"github.com/julienschmidt/httprouter"
+	"github.com/pkg/errors"
+)
+
+// WebhookService see Service for more details
+type WebhookService interface {
+	CreateWebhook(ctx context.Context, params webhookservice.CreateWebhookParams) (webhook.Webhook, error)
+	GetWebhooks(ctx context.Context, params webhookservice.GetWebhooksParams) ([]webhook.Webhook, error)
+	RemoveWebhook(ctx context.Context, params webhookservice.RemoveWebhookParams) error
+	GetDeliveries(ctx context.Context, params webhookservice.GetDeliveriesParams) ([]webhook.Delivery, int, string, error)
+}
+
+// WebhookHandler is the handler for the associated API
+type WebhookHandler struct {
+	WebhookService WebhookService
+}
+
+// CreateWebhook see Service for more details
+func (h WebhookHandler) CreateWebhook(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	request, err := NewCreateWebhookRequest(r, p)
+	if err != nil {
+		api.RespondWithError(r, w, err)
+		return
+	}
+	ctx := r.Context()
+	authData, err := api.GetDataFromContext(ctx)
+	if err != nil {
+		api.RespondWith(r, w, http.StatusInternalServerError, &api.InternalErr{}, errors.Wrap(err, "failed to get auth data"))
+		return
+	}
+	record, err := h.WebhookService.CreateWebhook(ctx, webhookservice.CreateWebhookParams{
+		Webhook: webhook.Webhook{
+			URL:    request.URL,
+			Events: request.Events,
+		},
+		UserID: authData.UserID,
+	})
+	if err != nil {
+		api.RespondWithError(r, w, err)
+		return
+	}
+	api.RespondWith(r, w, http.StatusOK, record, nil)
+}
+
+// GetWebhooks see Service for more details
+func (h WebhookHandler) GetWebhooks(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	ctx := r.Context()
+	authData, err := api.GetDataFromContext(ctx)
+	if err != nil {
+		api.RespondWith(r, w, http.StatusInternalServerError, &api.InternalErr{}, errors.Wrap(err, "failed to get auth data"))
+		return
+	}
+	records, err := h.WebhookService.GetWebhooks(ctx, webhookservice.GetWebhooksParams{
+		UserID: authData.UserID,
+	})
+	if err != nil {
+		api.RespondWithError(r, w, err)
+		return
+	}
+	api.RespondWith(r, w, http.StatusOK, records, nil)
+}
+
+// RemoveWebhook see Service for more details
+func (h WebhookHandler) RemoveWebhook(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	request, err := NewWebhookRequest(r, p)
+	if err != nil {
+		api.RespondWithError(r, w, err)
+		return
+	}
+	ctx := r.Context()
+	authData, err := api.GetDataFromContext(ctx)
+	if err != nil {
+		api.RespondWith(r, w, http.StatusInternalServerError, &api.InternalErr{}, errors.Wrap(err, "failed to get auth data"))
+		return
+	}
+	err = h.WebhookService.RemoveWebhook(ctx, webhookservice.RemoveWebhookParams{
+		Webhook: webhook.Webhook{
+			GUID: request.GUID,
+		},
+		UserID: authData.UserID,
+	})
+	if err != nil {
+		api.RespondWithError(r, w, err)
+		return
+	}
+	api.RespondWith(r, w, http.StatusOK, nil, nil)
+}
+
+// DeliveryBatchResponse is a batch of a webhook's deliveries, with how to get the next batch when there are more.
+type DeliveryBatchResponse struct {
+	Batch     []webhook.Delivery   `json:"batch"`
+	Total     int                  `json:"total"`
+	NextBatch *nextbatch.NextBatch `json:"nextBatch,omitempty"`
+}
+
+// GetDeliveries see Service for more details
+func (h WebhookHandler) GetDeliveries(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	request, err := NewGetDeliveriesRequest(r, p)
+	if err != nil {
+		api.RespondWithError(r, w, err)
+		return
+	}
+	ctx := r.Context()
+	authData, err := api.GetDataFromContext(ctx)
+	if err != nil {
+		api.RespondWith(r, w, http.StatusInternalServerError, &api.InternalErr{}, errors.Wrap(err, "failed to get auth data"))
+		return
+	}
+	records, total, nextBatchID, err := h.WebhookService.GetDeliveries(ctx, webhookservice.GetDeliveriesParams{
+		Webhook: webhook.Webhook{
+			GUID: request.GUID,
+		},
+		NextBatchID: request.NextBatchID,
+		UserID:      authData.UserID,
+	})
+	if err != nil {
+		api.RespondWithError(r, w, err)
+		return
+	}
+	responseBody := DeliveryBatchResponse{
+		Batch: make([]webhook.Delivery, 0, len(records)),
+		Total: total,
+	}
+	responseBody.Batch = append(responseBody.Batch, records...)
+	if nextBatchID != "" {
+		responseBody.NextBatch = &nextbatch.NextBatch{
+			ParamKey:   "nextBatchId",
+			ParamValue: nextBatchID,
+		}
+	}
+	api.RespondWith(r, w, http.StatusOK, responseBody, nil)
+}
@@ -0,0 +1,265 @@
+package webhookhandler
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/worlve/sp-service/internal/api"
+	"github.com/worlve/sp-service/internal/api/handlers/handlertestutils"
+	"github.com/worlve/sp-service/internal/api/handlers/webhook/mocks"
+	"github.com/worlve/sp-service/internal/models/webhook"
+	webhookservice "github.com/worlve/sp-service/internal/services/webhook"
+	"github.com/worlve/sp-service/internal/stores/storeerror"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var createdAt = time.Date(2020, 3, 31, 12, 0, 0, 0, time.UTC)
+
+type createWebhookCall struct {
+	params        webhookservice.CreateWebhookParams
+	returnWebhook webhook.Webhook
+	returnErr     error
+}
+
+func TestCreateWebhook(t *testing.T) {
+	cases := []struct {
+		name                 string
+		headers              map[string]string
+		requestBody          string
+		authN                api.AuthN
+		authZ                api.AuthZ
+		expectedResponseBody string
+		expectedStatusCode   int
+		createWebhookCalls   []createWebhookCall
+	}{
+		{
+			name:                 "not authenticated",
+			requestBody:          "{\"url\":\"https://example.com/hook\",\"events\":[\"page.created\"]}",
+			authN:                handlertestutils.DefaultAuthN("PROD"),
+			authZ:                handlertestutils.DefaultAuthZ(),
+			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"401 - Unauthorized\",\"code\":\"UNAUTHENTICATED\",\"message\":\"not authenticated\",\"requestId\":\"RQ_TEST\"}}\n",
+			expectedStatusCode:   401,
+		},
+		{
+			name: "happy path, duplicate events are ignored",
+			headers: map[string]string{
+				"X-USER-ID": "UR_1",
+			},
+			requestBody:          "{\"url\":\"https://example.com/hook\",\"events\":[\"page.created\",\"detail.updated\",\"page.created\"]}",
+			authN:                handlertestutils.DefaultAuthN("LOCAL"),
+			authZ:                handlertestutils.DefaultAuthZ(),
+			expectedResponseBody: "{\"result\":{\"id\":\"WH_1\",\"url\":\"https://example.com/hook\",\"events\":[\"page.created\",\"detail.updated\"],\"secret\":\"whsec_1\",\"createdAt\":\"2020-03-31T12:00:00Z\"},\"meta\":{\"httpStatus\":\"200 - OK\",\"requestId\":\"RQ_TEST\"}}\n",
+			expectedStatusCode:   200,
+			createWebhookCalls: []createWebhookCall{
+				{
+					params: webhookservice.CreateWebhookParams{
+						Webhook: webhook.Webhook{URL: "https://example.com/hook", Events: []webhook.EventType{webhook.EventPageCreated, webhook.EventPageDetailUpdated}},
+						UserID:  "UR_1",
+					},
+					returnWebhook: webhook.Webhook{GUID: "WH_1", UserID: "UR_1", URL: "https://example.com/hook", Events: []webhook.EventType{webhook.EventPageCreated, webhook.EventPageDetailUpdated}, Secret: "whsec_1", CreatedAt: &createdAt},
+				},
+			},
+		},
+		{
+			name: "url that isn't http",
+			headers: map[string]string{
+				"X-USER-ID": "UR_1",
+			},
+			requestBody:          "{\"url\":\"ftp://example.com/hook\",\"events\":[\"page.created\"]}",
+			authN:                handlertestutils.DefaultAuthN("LOCAL"),
+			authZ:                handlertestutils.DefaultAuthZ(),
+			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"400 - Bad Request\",\"code\":\"VALIDATION_FAILED\",\"message\":\"url must be an absolute http or https URL\",\"details\":[{\"field\":\"url\",\"message\":\"url must be an absolute http or https URL\"}],\"requestId\":\"RQ_TEST\"}}\n",
+			expectedStatusCode:   400,
+		},
+		{
+			name: "loopback url",
+			headers: map[string]string{
+				"X-USER-ID": "UR_1",
+			},
+			requestBody:          "{\"url\":\"http://127.0.0.1:8080/hook\",\"events\":[\"page.created\"]}",
+			authN:                handlertestutils.DefaultAuthN("LOCAL"),
+			authZ:                handlertestutils.DefaultAuthZ(),
+			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"400 - Bad Request\",\"code\":\"VALIDATION_FAILED\",\"message\":\"url host 127.0.0.1 is not public\",\"details\":[{\"field\":\"url\",\"message\":\"url host 127.0.0.1 is not public\"}],\"requestId\":\"RQ_TEST\"}}\n",
+			expectedStatusCode:   400,
+		},
+		{
+			name: "private network url",
+			headers: map[string]string{
+				"X-USER-ID": "UR_1",
+			},
+			requestBody:          "{\"url\":\"http://10.0.0.5/hook\",\"events\":[\"page.created\"]}",
+			authN:                handlertestutils.DefaultAuthN("LOCAL"),
+			authZ:                handlertestutils.DefaultAuthZ(),
+			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"400 - Bad Request\",\"code\":\"VALIDATION_FAILED\",\"message\":\"url host 10.0.0.5 is not public\",\"details\":[{\"field\":\"url\",\"message\":\"url host 10.0.0.5 is not public\"}],\"requestId\":\"RQ_TEST\"}}\n",
+			expectedStatusCode:   400,
+		},
+		{
+			name: "metadata url",
+			headers: map[string]string{
+				"X-USER-ID": "UR_1",
+			},
+			requestBody:          "{\"url\":\"http://169.254.169.254/latest/meta-data/\",\"events\":[\"page.created\"]}",
+			authN:                handlertestutils.DefaultAuthN("LOCAL"),
+			authZ:                handlertestutils.DefaultAuthZ(),
+			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"400 - Bad Request\",\"code\":\"VALIDATION_FAILED\",\"message\":\"url host 169.254.169.254 is not public\",\"details\":[{\"field\":\"url\",\"message\":\"url host 169.254.169.254 is not public\"}],\"requestId\":\"RQ_TEST\"}}\n",
+			expectedStatusCode:   400,
+		},
+		{
+			name: "localhost url",
+			headers: map[string]string{
+				"X-USER-ID": "UR_1",
+			},
+			requestBody:          "{\"url\":\"http://localhost/hook\",\"events\":[\"page.created\"]}",
+			authN:                handlertestutils.DefaultAuthN("LOCAL"),
+			authZ:                handlertestutils.DefaultAuthZ(),
+			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"400 - Bad Request\",\"code\":\"VALIDATION_FAILED\",\"message\":\"url host localhost is not public\",\"details\":[{\"field\":\"url\",\"message\":\"url host localhost is not public\"}],\"requestId\":\"RQ_TEST\"}}\n",
+			expectedStatusCode:   400,
+		},
+		{
+			name: "no events",
+			headers: map[string]string{
+				"X-USER-ID": "UR_1",
+			},
+			requestBody:          "{\"url\":\"https://example.com/hook\",\"events\":[]}",
+			authN:                handlertestutils.DefaultAuthN("LOCAL"),
+			authZ:                handlertestutils.DefaultAuthZ(),
+			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"400 - Bad Request\",\"code\":\"VALIDATION_FAILED\",\"message\":\"must provide at least one event\",\"details\":[{\"field\":\"events\",\"message\":\"must provide at least one event\"}],\"requestId\":\"RQ_TEST\"}}\n",
+			expectedStatusCode:   400,
+		},
+		{
+			name: "unknown event",
+			headers: map[string]string{
+				"X-USER-ID": "UR_1",
+			},
+			requestBody:          "{\"url\":\"https://example.com/hook\",\"events\":[\"page.exploded\"]}",
+			authN:                handlertestutils.DefaultAuthN("LOCAL"),
+			authZ:                handlertestutils.DefaultAuthZ(),
+			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"400 - Bad Request\",\"code\":\"VALIDATION_FAILED\",\"message\":\"events has an invalid value: page.exploded\",\"details\":[{\"field\":\"events\",\"message\":\"events has an invalid value: page.exploded\"}],\"requestId\":\"RQ_TEST\"}}\n",
+			expectedStatusCode:   400,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			webhookService := new(mocks.WebhookService)
+			for index := range tc.createWebhookCalls {
+				webhookService.On("CreateWebhook", mock.Anything, tc.createWebhookCalls[index].params).Return(tc.createWebhookCalls[index].returnWebhook, tc.createWebhookCalls[index].returnErr)
+			}
+			routerHandlers := WebhookRouterHandlers(tc.authZ.APIPath, webhookService)
+			resp, respBody := handlertestutils.HandleTestRequest(handlertestutils.HandleTestRequestParams{
+				Method:         http.MethodPost,
+				Endpoint:       "webhooks",
+				Headers:        tc.headers,
+				Body:           strings.NewReader(tc.requestBody),
+				RouterHandlers: routerHandlers,
+				AuthZ:          tc.authZ,
+				AuthN:          tc.authN,
+			})
+			require.Equal(t, tc.expectedResponseBody, respBody)
+			require.Equal(t, tc.expectedStatusCode, resp.StatusCode)
+			webhookService.AssertNumberOfCalls(t, "CreateWebhook", len(tc.createWebhookCalls))
+		})
+	}
+}
+
+func TestGetWebhooks(t *testing.T) {
+	webhookService := new(mocks.WebhookService)
+	webhookService.On("GetWebhooks", mock.Anything, webhookservice.GetWebhooksParams{UserID: "UR_1"}).Return([]webhook.Webhook{
+		{GUID: "WH_1", UserID: "UR_1", URL: "https://example.com/hook", Events: []webhook.EventType{webhook.EventPageRemoved}, CreatedAt: &createdAt},
+	}, nil)
+	authZ := handlertestutils.DefaultAuthZ()
+	resp, respBody := handlertestutils.HandleTestRequest(handlertestutils.HandleTestRequestParams{
+		Method:         http.MethodGet,
+		Endpoint:       "webhooks",
+		Headers:        map[string]string{"X-USER-ID": "UR_1"},
+		RouterHandlers: WebhookRouterHandlers(authZ.APIPath, webhookService),
+		AuthZ:          authZ,
+		AuthN:          handlertestutils.DefaultAuthN("LOCAL"),
+	})
+	require.Equal(t, "{\"result\":[{\"id\":\"WH_1\",\"url\":\"https://example.com/hook\",\"events\":[\"page.removed\"],\"createdAt\":\"2020-03-31T12:00:00Z\"}],\"meta\":{\"httpStatus\":\"200 - OK\",\"requestId\":\"RQ_TEST\"}}\n", respBody)
+	require.Equal(t, 200, resp.StatusCode)
+}
+
+type removeWebhookCall struct {
+	params    webhookservice.RemoveWebhookParams
+	returnErr error
+}
+
+func TestRemoveWebhook(t *testing.T) {
+	cases := []struct {
+		name                 string
+		endpoint             string
+		expectedResponseBody string
+		expectedStatusCode   int
+		removeWebhookCalls   []removeWebhookCall
+	}{
+		{
+			name:                 "happy path",
+			endpoint:             "webhooks/WH_1",
+			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"200 - OK\",\"requestId\":\"RQ_TEST\"}}\n",
+			expectedStatusCode:   200,
+			removeWebhookCalls: []removeWebhookCall{
+				{params: webhookservice.RemoveWebhookParams{Webhook: webhook.Webhook{GUID: "WH_1"}, UserID: "UR_1"}},
+			},
+		},
+		{
+			name:                 "someone else's webhook",
+			endpoint:             "webhooks/WH_2",
+			expectedResponseBody: "{\"meta\":{\"httpStatus\":\"403 - Forbidden\",\"code\":\"FORBIDDEN\",\"message\":\"not authorized\",\"requestId\":\"RQ_TEST\"}}\n",
+			expectedStatusCode:   403,
+			removeWebhookCalls: []removeWebhookCall{
+				{
+					params:    webhookservice.RemoveWebhookParams{Webhook: webhook.Webhook{GUID: "WH_2"}, UserID: "UR_1"},
+					returnErr: &storeerror.NotAuthorized{UserID: "UR_1", TableID: "WH_2"},
+				},
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			webhookService := new(mocks.WebhookService)
+			for index := range tc.removeWebhookCalls {
+				webhookService.On("RemoveWebhook", mock.Anything, tc.removeWebhookCalls[index].params).Return(tc.removeWebhookCalls[index].returnErr)
+			}
+			authZ := handlertestutils.DefaultAuthZ()
+			resp, respBody := handlertestutils.HandleTestRequest(handlertestutils.HandleTestRequestParams{
+				Method:         http.MethodDelete,
+				Endpoint:       tc.endpoint,
+				Headers:        map[string]string{"X-USER-ID": "UR_1"},
+				RouterHandlers: WebhookRouterHandlers(authZ.APIPath, webhookService),
+				AuthZ:          authZ,
+				AuthN:          handlertestutils.DefaultAuthN("LOCAL"),
+			})
+			require.Equal(t, tc.expectedResponseBody, respBody)
+			require.Equal(t, tc.expectedStatusCode, resp.StatusCode)
+			webhookService.AssertNumberOfCalls(t, "RemoveWebhook", len(tc.removeWebhookCalls))
+		})
+	}
+}
+
+func TestGetDeliveries(t *testing.T) {
+	nextAttemptAt := createdAt.Add(time.Minute)
+	webhookService := new(mocks.WebhookService)
+	webhookService.On("GetDeliveries", mock.Anything, webhookservice.GetDeliveriesParams{
+		Webhook:     webhook.Webhook{GUID: "WH_1"},
+		NextBatchID: "WD_3",
+		UserID:      "UR_1",
+	}).Return([]webhook.Delivery{
+		{GUID: "WD_3", WebhookGUID: "WH_1", Event: webhook.EventPageCreated, Payload: []byte("{}"), Status: webhook.DeliveryPending, Attempts: 1, ResponseStatus: 503, LastError: "unexpected status 503: ", NextAttemptAt: &nextAttemptAt, LastAttemptAt: &createdAt, CreatedAt: &createdAt},
+	}, 5, "WD_2", nil)
+	authZ := handlertestutils.DefaultAuthZ()
+	resp, respBody := handlertestutils.HandleTestRequest(handlertestutils.HandleTestRequestParams{
+		Method:         http.MethodGet,
+		Endpoint:       "webhooks/WH_1/deliveries",
+		Params:         url.Values{"nextBatchId": []string{"WD_3"}},
+		Headers:        map[string]string{"X-USER-ID": "UR_1"},
+		RouterHandlers: WebhookRouterHandlers(authZ.APIPath, webhookService),
+		AuthZ:          authZ,
+		AuthN:          handlertestutils.DefaultAuthN("LOCAL"),
+	})
+	require.Equal(t, "{\"result\":{\"batch\":[{\"id\":\"WD_3\",\"webhookId\":\"WH_1\",\"event\":\"page.created\",\"status\":\"pending\",\"attempts\":1,\"responseStatus\":503,\"lastError\":\"unexpected status 503: \",\"nextAttemptAt\":\"2020-03-31T12:01:00Z\",\"lastAttemptAt\":\"2020-03-31T12:00:00Z\",\"createdAt\":\"2020-03-31T12:00:00Z\"}],\"total\":5,\"nextBatch\":{\"paramKey\":\"nextBatchId\",\"paramValue\":\"WD_2\"}},\"meta\":{\"httpStatus\":\"200 - OK\",\"requestId\":\"RQ_TEST\"}}\n", respBody)
+	require.Equal(t, 200, resp.StatusCode)
+}
@@ -0,0 +1,85 @@
+package webhookhandler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/worlve/sp-service/internal/api"
+	"github.com/worlve/sp-service/internal/models/webhook"
+	"github.com/worlve/sp-service/internal/util/publicnet"
+	"github.com/julienschmidt/httprouter"
+)
+
+// CreateWebhookRequest parameters from the CreateWebhook call
+type CreateWebhookRequest struct {
+	URL          string              `json:"url"`
+	EventStrings []string            `json:"events"`
+	Events       []webhook.EventType `json:"-"`
+}
+
+// NewCreateWebhookRequest extracts the CreateWebhookRequest
+func NewCreateWebhookRequest(r *http.Request, p httprouter.Params) (CreateWebhookRequest, error) {
+	var request CreateWebhookRequest
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		return request, api.InvalidRequest("invalid request")
+	}
+	return request.validate()
+}
+
+func (request CreateWebhookRequest) validate() (CreateWebhookRequest, error) {
+	err := publicnet.CheckURL(request.URL)
+	if err != nil {
+		return request, api.InvalidField("url", "url "+err.Error())
+	}
+	if len(request.EventStrings) == 0 {
+		return request, api.InvalidField("events", "must provide at least one event")
+	}
+	request.Events = make([]webhook.EventType, 0, len(request.EventStrings))
+	seen := make(map[webhook.EventType]bool)
+	for _, eventString := range request.EventStrings {
+		eventType, err := webhook.GetEventType(eventString)
+		if err != nil {
+			return request, api.InvalidField("events", "events has an invalid value: "+eventString)
+		}
+		if !seen[eventType] {
+			seen[eventType] = true
+			request.Events = append(request.Events, eventType)
+		}
+	}
+	return request, nil
+}
+
+// WebhookRequest parameters from the calls on a single webhook
+type WebhookRequest struct {
+	GUID string
+}
+
+// NewWebhookRequest extracts the WebhookRequest
+func NewWebhookRequest(r *http.Request, p httprouter.Params) (WebhookRequest, error) {
+	var request WebhookRequest
+	request.GUID = p.ByName(WebhookIDRouteKey)
+	return request.validate()
+}
+
+func (request WebhookRequest) validate() (WebhookRequest, error) {
+	if request.GUID == "" {
+		return request, api.InvalidField("id", "must provide a webhook id")
+	}
+	return request, nil
+}
+
+// GetDeliveriesRequest parameters from the GetDeliveries call
+type GetDeliveriesRequest struct {
+	GUID        string
+	NextBatchID string
+}
+
+// NewGetDeliveriesRequest extracts the GetDeliveriesRequest
+func NewGetDeliveriesRequest(r *http.Request, p httprouter.Params) (GetDeliveriesRequest, error) {
+	webhookRequest, err := NewWebhookRequest(r, p)
+	return GetDeliveriesRequest{
+		GUID:        webhookRequest.GUID,
+		NextBatchID: r.URL.Query().Get("nextBatchId"),
+	}, err
+}
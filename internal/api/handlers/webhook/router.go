@@ -0,0 +1,75 @@
+package webhookhandler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/worlve/sp-service/internal/api"
+	"github.com/worlve/sp-service/internal/models/webhook"
+)
+
+// HTTP path fragments keys
+const (
+	WebhookIDRouteKey = "webhookID"
+)
+
+// WebhookRouterHandlers returns the requests for the associated routes.
+func WebhookRouterHandlers(apiPath string, webhookService WebhookService) []api.RouterHandler {
+	handler := WebhookHandler{
+		WebhookService: webhookService,
+	}
+	var routerHandlers []api.RouterHandler
+	routerHandlers = append(routerHandlers, api.RouterHandler{
+		Method:   http.MethodPost,
+		Endpoint: fmt.Sprintf("/%v/webhooks", apiPath),
+		Handle:   handler.CreateWebhook,
+		Doc: &api.RouteDoc{
+			OperationID: "createWebhook",
+			Summary:     "Create Webhook",
+			Description: "Subscribes a URL to changes to the user's pages. Each delivery is signed with the returned secret, which is only shown here.",
+			Tag:         "webhook",
+			Request:     CreateWebhookRequest{},
+			Response:    webhook.Webhook{},
+		},
+	})
+	routerHandlers = append(routerHandlers, api.RouterHandler{
+		Method:   http.MethodGet,
+		Endpoint: fmt.Sprintf("/%v/webhooks", apiPath),
+		Handle:   handler.GetWebhooks,
+		Doc: &api.RouteDoc{
+			OperationID: "getWebhooks",
+			Summary:     "Get Webhooks",
+			Description: "Get the user's webhooks, without their secrets.",
+			Tag:         "webhook",
+			Response:    []webhook.Webhook{},
+		},
+	})
+	routerHandlers = append(routerHandlers, api.RouterHandler{
+		Method:   http.MethodDelete,
+		Endpoint: fmt.Sprintf("/%v/webhooks/:%v", apiPath, WebhookIDRouteKey),
+		Handle:   handler.RemoveWebhook,
+		Doc: &api.RouteDoc{
+			OperationID: "removeWebhook",
+			Summary:     "Remove Webhook",
+			Description: "Permanently deletes the provided webhook along with its deliveries, including any that haven't been sent.",
+			Tag:         "webhook",
+		},
+	})
+	routerHandlers = append(routerHandlers, api.RouterHandler{
+		Method:   http.MethodGet,
+		Endpoint: fmt.Sprintf("/%v/webhooks/:%v/deliveries", apiPath, WebhookIDRouteKey),
+		Handle:   handler.GetDeliveries,
+		Doc: &api.RouteDoc{
+			OperationID: "getWebhookDeliveries",
+			Summary:     "Get Webhook Deliveries",
+			Description: "Get a paginated log of the provided webhook's deliveries, newest first, with the outcome of their attempts so far.",
+			Tag:         "webhook",
+			Query: []api.QueryParam{{
+				Name:        "nextBatchId",
+				Description: "If the request is batched, to get the next batch set this parameter based on the response's result.nextBatch.",
+			}},
+			Response: DeliveryBatchResponse{},
+		},
+	})
+	return routerHandlers
+}
@@ -0,0 +1,30 @@
+package api
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/worlve/sp-service/internal/util/metrics"
+)
+
+// unmatchedRoute is the route label for requests that didn't match a route, so unknown paths can't grow the number of series.
+const unmatchedRoute = "unmatched"
+
+var (
+	httpRequestsTotal = metrics.Default.NewCounterVec("sp_http_requests_total",
+		"Total number of HTTP requests handled.",
+		"method", "route", "status")
+	httpRequestDuration = metrics.Default.NewHistogramVec("sp_http_request_duration_seconds",
+		"Latency of the HTTP requests handled.",
+		metrics.DefaultBuckets,
+		"method", "route", "status")
+)
+
+func observeRequest(method, route string, status int, latency time.Duration) {
+	if route == "" {
+		route = unmatchedRoute
+	}
+	statusString := strconv.Itoa(status)
+	httpRequestsTotal.Inc(method, route, statusString)
+	httpRequestDuration.Observe(latency.Seconds(), method, route, statusString)
+}
@@ -0,0 +1,223 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/worlve/sp-service/internal/util/openapi3"
+)
+
+// OpenAPIEndpoint is where the generated OpenAPI spec is served, under the API path.
+const OpenAPIEndpoint = "openapi.json"
+
+const (
+	jsonContentType = "application/json"
+	metaSchemaName  = "Meta"
+)
+
+// RouteDoc describes a route in the generated OpenAPI spec.
+type RouteDoc struct {
+	OperationID string
+	Summary     string
+	Description string
+	Tag         string
+	// Path is the route's path in the spec when it can't be taken from the endpoint, such as a custom method like /api/pages:batch.
+	Path  string
+	Query []QueryParam
+	// Request is a value of the request body's type; the route has no body when it's nil.
+	Request interface{}
+	// RequestContentTypes are the body's media types when it isn't JSON, such as application/zip.
+	RequestContentTypes []string
+	// Response is a value of the type of the response's result; the response only has its meta when it's nil.
+	Response interface{}
+	// ResponseContentTypes are the response's media types when it isn't JSON; the response isn't wrapped in a result and meta.
+	ResponseContentTypes []string
+	// ErrorHasResult is set when an error response can still have the Response's result, such as a failed atomic batch.
+	ErrorHasResult bool
+}
+
+// QueryParam is a query parameter a route accepts.
+type QueryParam struct {
+	Name        string
+	Description string
+	Enum        []string
+}
+
+// pathParamPattern matches the named and catch-all path segments in an endpoint, such as /:pageID or /*filepath.
+// A ":" within a segment is left alone, since it starts a custom method like /pages:batch.
+var pathParamPattern = regexp.MustCompile(`/[:*]([A-Za-z0-9_]+)`)
+
+// NewOpenAPIDocument generates the OpenAPI spec of the routes. Routes without a Doc are left out; see UndocumentedRoutes.
+func NewOpenAPIDocument(routerHandlers []RouterHandler) openapi3.Document {
+	schemas := &openapi3.Schemas{}
+	schemas.Define(metaSchemaName, responseFormat{}.Meta)
+	doc := openapi3.Document{
+		OpenAPI: openapi3.Version,
+		Info: openapi3.Info{
+			Title:       "Project Spiderweb Service",
+			Description: "The API of the Project Spiderweb Service. This spec is generated from the service's routes.",
+			Version:     "1.0.0",
+		},
+		Servers: []openapi3.Server{{URL: "/"}},
+		Paths:   map[string]openapi3.PathItem{},
+		Components: openapi3.Components{
+			SecuritySchemes: map[string]openapi3.SecurityScheme{
+				"adminAuthSecret": {
+					Type:        "apiKey",
+					In:          "header",
+					Name:        AdminAuthSecretHeaderKey,
+					Description: "The admin auth secret. Not needed when running locally.",
+				},
+				"userId": {
+					Type:        "apiKey",
+					In:          "header",
+					Name:        UserIDHeaderKey,
+					Description: "The user the admin is acting as.",
+				},
+			},
+		},
+		Security: []map[string][]string{{"adminAuthSecret": {}, "userId": {}}},
+	}
+	for _, routerHandler := range routerHandlers {
+		if routerHandler.Doc == nil {
+			continue
+		}
+		path, operation := newOpenAPIOperation(schemas, routerHandler)
+		if _, ok := doc.Paths[path]; !ok {
+			doc.Paths[path] = openapi3.PathItem{}
+		}
+		doc.Paths[path][strings.ToLower(routerHandler.Method)] = operation
+	}
+	doc.Components.Schemas = schemas.Components
+	return doc
+}
+
+// UndocumentedRoutes returns the method and endpoint of each route without a Doc, such as "GET /api/pages".
+func UndocumentedRoutes(routerHandlers []RouterHandler) []string {
+	var undocumented []string
+	for _, routerHandler := range routerHandlers {
+		if routerHandler.Doc == nil {
+			undocumented = append(undocumented, fmt.Sprintf("%v %v", routerHandler.Method, routerHandler.Endpoint))
+		}
+	}
+	sort.Strings(undocumented)
+	return undocumented
+}
+
+func newOpenAPIOperation(schemas *openapi3.Schemas, routerHandler RouterHandler) (string, *openapi3.Operation) {
+	doc := routerHandler.Doc
+	path := doc.Path
+	if path == "" {
+		path = routerHandler.Endpoint
+	}
+	operation := &openapi3.Operation{
+		OperationID: doc.OperationID,
+		Summary:     doc.Summary,
+		Description: doc.Description,
+		Responses:   map[string]openapi3.Response{},
+	}
+	if doc.Tag != "" {
+		operation.Tags = []string{doc.Tag}
+	}
+	if routerHandler.NoAuth {
+		operation.Security = &[]map[string][]string{}
+	}
+	for _, match := range pathParamPattern.FindAllStringSubmatch(path, -1) {
+		operation.Parameters = append(operation.Parameters, openapi3.Parameter{
+			Name:     match[1],
+			In:       "path",
+			Required: true,
+			Schema:   &openapi3.Schema{Type: "string"},
+		})
+	}
+	for _, param := range doc.Query {
+		operation.Parameters = append(operation.Parameters, openapi3.Parameter{
+			Name:        param.Name,
+			In:          "query",
+			Description: param.Description,
+			Schema:      &openapi3.Schema{Type: "string", Enum: param.Enum},
+		})
+	}
+	if len(doc.RequestContentTypes) > 0 {
+		operation.RequestBody = &openapi3.RequestBody{
+			Required: true,
+			Content:  binaryContent(doc.RequestContentTypes),
+		}
+	} else if doc.Request != nil {
+		operation.RequestBody = &openapi3.RequestBody{
+			Required: true,
+			Content:  map[string]openapi3.MediaType{jsonContentType: {Schema: schemas.For(doc.Request)}},
+		}
+	}
+	if len(doc.ResponseContentTypes) > 0 {
+		operation.Responses["200"] = openapi3.Response{
+			Description: "OK",
+			Content:     binaryContent(doc.ResponseContentTypes),
+		}
+	} else {
+		operation.Responses["200"] = openapi3.Response{
+			Description: "OK",
+			Content:     map[string]openapi3.MediaType{jsonContentType: {Schema: envelope(schemas, doc.Response)}},
+		}
+	}
+	errorSchema := envelope(schemas, nil)
+	if doc.ErrorHasResult && doc.Response != nil {
+		errorSchema.Properties["result"] = schemas.For(doc.Response)
+	}
+	operation.Responses["default"] = openapi3.Response{
+		Description: "An error, described by the meta's code and message.",
+		Content:     map[string]openapi3.MediaType{jsonContentType: {Schema: errorSchema}},
+	}
+	return pathParamPattern.ReplaceAllString(path, "/{$1}"), operation
+}
+
+func binaryContent(contentTypes []string) map[string]openapi3.MediaType {
+	content := map[string]openapi3.MediaType{}
+	for _, contentType := range contentTypes {
+		content[contentType] = openapi3.MediaType{Schema: openapi3.Binary()}
+	}
+	return content
+}
+
+// envelope returns the schema of a response sent by RespondWith, with the result and meta.
+func envelope(schemas *openapi3.Schemas, result interface{}) *openapi3.Schema {
+	schema := &openapi3.Schema{
+		Type:       "object",
+		Properties: map[string]*openapi3.Schema{"meta": openapi3.Ref(metaSchemaName)},
+		Required:   []string{"meta"},
+	}
+	if result != nil {
+		schema.Properties["result"] = schemas.For(result)
+		schema.Required = []string{"result", "meta"}
+	}
+	return schema
+}
+
+// openAPIRouterHandler serves the OpenAPI spec of the routes, including itself, and returns the spec it serves.
+func openAPIRouterHandler(apiPath string, routerHandlers []RouterHandler) (RouterHandler, openapi3.Document) {
+	var body []byte
+	routerHandler := RouterHandler{
+		Method:   http.MethodGet,
+		Endpoint: fmt.Sprintf("/%v/%v", apiPath, OpenAPIEndpoint),
+		Handle: func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+			w.Header().Set("Content-Type", jsonContentType)
+			w.Write(body)
+		},
+		NoAuth: true,
+		Doc: &RouteDoc{
+			OperationID:          "getOpenAPI",
+			Summary:              "Get OpenAPI Spec",
+			Description:          "Gets this OpenAPI spec.",
+			Tag:                  "docs",
+			ResponseContentTypes: []string{jsonContentType},
+		},
+	}
+	doc := NewOpenAPIDocument(append(routerHandlers, routerHandler))
+	body, _ = json.Marshal(doc)
+	return routerHandler, doc
+}
@@ -0,0 +1,188 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/worlve/sp-service/internal/util/openapi3"
+	"github.com/julienschmidt/httprouter"
+)
+
+type testCreateItemRequest struct {
+	Name string `json:"name"`
+}
+
+type testItem struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func testOpenAPIRouterHandlers() []RouterHandler {
+	ok := func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {}
+	return []RouterHandler{
+		{
+			Method:   http.MethodPost,
+			Endpoint: "/api/test/items",
+			Handle:   ok,
+			Doc: &RouteDoc{
+				OperationID: "createItem",
+				Tag:         "item",
+				Request:     testCreateItemRequest{},
+				Response:    testItem{},
+			},
+		},
+		{
+			Method:   http.MethodGet,
+			Endpoint: "/api/test/items/:itemID",
+			Handle:   ok,
+			Doc: &RouteDoc{
+				OperationID: "getItem",
+				Query:       []QueryParam{{Name: "view", Enum: []string{"short", "long"}}},
+				Response:    testItem{},
+			},
+		},
+		{
+			Method:   http.MethodPost,
+			Endpoint: "/api/test/items:action",
+			Handle:   ok,
+			Doc: &RouteDoc{
+				OperationID: "archiveItems",
+				Path:        "/api/test/items:archive",
+			},
+		},
+		{
+			Method:   http.MethodGet,
+			Endpoint: "/livez",
+			Handle:   ok,
+			NoAuth:   true,
+			Doc: &RouteDoc{
+				OperationID:          "liveness",
+				ResponseContentTypes: []string{"text/plain"},
+			},
+		},
+	}
+}
+
+func TestNewOpenAPIDocument(t *testing.T) {
+	doc := NewOpenAPIDocument(testOpenAPIRouterHandlers())
+	itemEnvelope := &openapi3.Schema{
+		Type: "object",
+		Properties: map[string]*openapi3.Schema{
+			"result": openapi3.Ref("api.testItem"),
+			"meta":   openapi3.Ref(metaSchemaName),
+		},
+		Required: []string{"result", "meta"},
+	}
+	errorResponse := openapi3.Response{
+		Description: "An error, described by the meta's code and message.",
+		Content: map[string]openapi3.MediaType{jsonContentType: {Schema: &openapi3.Schema{
+			Type:       "object",
+			Properties: map[string]*openapi3.Schema{"meta": openapi3.Ref(metaSchemaName)},
+			Required:   []string{"meta"},
+		}}},
+	}
+	require.Equal(t, openapi3.Version, doc.OpenAPI)
+	require.Equal(t, map[string]openapi3.PathItem{
+		"/api/test/items": {
+			"post": {
+				OperationID: "createItem",
+				Tags:        []string{"item"},
+				RequestBody: &openapi3.RequestBody{
+					Required: true,
+					Content:  map[string]openapi3.MediaType{jsonContentType: {Schema: openapi3.Ref("api.testCreateItemRequest")}},
+				},
+				Responses: map[string]openapi3.Response{
+					"200":     {Description: "OK", Content: map[string]openapi3.MediaType{jsonContentType: {Schema: itemEnvelope}}},
+					"default": errorResponse,
+				},
+			},
+		},
+		"/api/test/items/{itemID}": {
+			"get": {
+				OperationID: "getItem",
+				Parameters: []openapi3.Parameter{
+					{Name: "itemID", In: "path", Required: true, Schema: &openapi3.Schema{Type: "string"}},
+					{Name: "view", In: "query", Schema: &openapi3.Schema{Type: "string", Enum: []string{"short", "long"}}},
+				},
+				Responses: map[string]openapi3.Response{
+					"200":     {Description: "OK", Content: map[string]openapi3.MediaType{jsonContentType: {Schema: itemEnvelope}}},
+					"default": errorResponse,
+				},
+			},
+		},
+		"/api/test/items:archive": {
+			"post": {
+				OperationID: "archiveItems",
+				Responses: map[string]openapi3.Response{
+					"200":     {Description: "OK", Content: map[string]openapi3.MediaType{jsonContentType: {Schema: errorResponse.Content[jsonContentType].Schema}}},
+					"default": errorResponse,
+				},
+			},
+		},
+		"/livez": {
+			"get": {
+				OperationID: "liveness",
+				Responses: map[string]openapi3.Response{
+					"200":     {Description: "OK", Content: map[string]openapi3.MediaType{"text/plain": {Schema: openapi3.Binary()}}},
+					"default": errorResponse,
+				},
+				Security: &[]map[string][]string{},
+			},
+		},
+	}, doc.Paths)
+	require.Equal(t, &openapi3.Schema{
+		Type:       "object",
+		Properties: map[string]*openapi3.Schema{"name": {Type: "string"}},
+		Required:   []string{"name"},
+	}, doc.Components.Schemas["api.testCreateItemRequest"])
+	require.Contains(t, doc.Components.Schemas, "api.testItem")
+	require.Contains(t, doc.Components.Schemas, metaSchemaName)
+}
+
+func TestUndocumentedRoutes(t *testing.T) {
+	cases := []struct {
+		name                string
+		paramRouterHandlers []RouterHandler
+		returnUndocumented  []string
+	}{
+		{
+			name:                "test documented routes",
+			paramRouterHandlers: testOpenAPIRouterHandlers(),
+		},
+		{
+			name: "test undocumented routes",
+			paramRouterHandlers: append(testOpenAPIRouterHandlers(),
+				RouterHandler{Method: http.MethodDelete, Endpoint: "/api/test/items/:itemID"},
+				RouterHandler{Method: http.MethodGet, Endpoint: "/api/test/items"},
+			),
+			returnUndocumented: []string{"DELETE /api/test/items/:itemID", "GET /api/test/items"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.returnUndocumented, UndocumentedRoutes(tc.paramRouterHandlers))
+		})
+	}
+}
+
+func TestServeOpenAPIDocument(t *testing.T) {
+	router := NewRouter("api/test", "static/test", testOpenAPIRouterHandlers())
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "http://test.com/api/test/openapi.json", nil)
+	router.ServeHTTP(w, r)
+	resp := w.Result()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, jsonContentType, resp.Header.Get("Content-Type"))
+	var doc openapi3.Document
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &doc))
+	require.Len(t, doc.Paths, 5)
+	require.Equal(t, "getOpenAPI", doc.Paths["/api/test/openapi.json"]["get"].OperationID)
+	var nonAuthPaths []string
+	for _, route := range router.NonAuthRoutes {
+		nonAuthPaths = append(nonAuthPaths, route.Path)
+	}
+	require.Equal(t, []string{"/livez", "/api/test/openapi.json"}, nonAuthPaths)
+}
@@ -0,0 +1,168 @@
+package api
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/worlve/sp-service/internal/util/logger"
+	"github.com/worlve/sp-service/internal/util/metrics"
+	"github.com/worlve/sp-service/internal/util/ratelimit"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// RetryAfterHeaderKey is the header with the seconds until a rate limited client can try again.
+const RetryAfterHeaderKey = "Retry-After"
+
+// defaultRateLimitGroup is the group for requests that don't match one of the RateLimiter's groups.
+const defaultRateLimitGroup = "default"
+
+// Kinds of clients that are rate limited separately.
+const (
+	rateLimitClientIP        = "ip"
+	rateLimitClientAdmin     = "admin"
+	rateLimitClientProxyUser = "proxyUser"
+	rateLimitClientPublic    = "public"
+)
+
+var httpRateLimitedTotal = metrics.Default.NewCounterVec("sp_http_rate_limited_total",
+	"Total number of HTTP requests rejected by the rate limiter.",
+	"group", "client")
+
+// RateLimiter limits how many requests each client can make to each group of routes.
+// Every request is limited by IP before it's authenticated. After that, proxy users are limited by their user ID,
+// admins share one limit, and requests to public routes are limited by IP.
+type RateLimiter struct {
+	Store ratelimit.Store
+	// Default limits requests that don't match one of the Groups.
+	Default RateLimits
+	Groups  []RateLimitGroup
+	// ClientIPHeader is the header with the client's IP, such as X-Forwarded-For, when the service is behind a proxy.
+	// The connection's address is used when it's empty.
+	ClientIPHeader string
+	// TrustedProxies is the number of proxies in front of the service that append to ClientIPHeader.
+	// The client's IP is that many entries from the end, since the entries before it can be set by the client; 0 is the same as 1.
+	TrustedProxies int
+}
+
+// RateLimitGroup limits the requests whose path starts with PathPrefix.
+// When more than one group matches, the one with the longest PathPrefix is used.
+type RateLimitGroup struct {
+	Name       string
+	PathPrefix string
+	Limits     RateLimits
+}
+
+// RateLimits are the limits for each kind of client.
+type RateLimits struct {
+	// IP limits every request by the client's IP before it's authenticated, so requests that fail authentication are limited too.
+	IP        ratelimit.Limit
+	Admin     ratelimit.Limit
+	ProxyUser ratelimit.Limit
+	Public    ratelimit.Limit
+}
+
+// TooManyRequests is an error that signifies that the client has gone over its rate limit.
+type TooManyRequests struct{}
+
+func (e *TooManyRequests) Error() string {
+	return "too many requests"
+}
+
+// limitIP responds with a 429 and returns true when the client's IP has gone over its rate limit.
+// It's checked before the request is authenticated.
+func (h *Handler) limitIP(w http.ResponseWriter, r *http.Request) bool {
+	if h.RateLimiter == nil {
+		return false
+	}
+	rl := h.RateLimiter
+	groupName, limits := rl.group(r.URL.Path)
+	return rl.take(w, r, groupName, rateLimitClientIP, "ip:"+rl.clientIP(r), limits.IP)
+}
+
+// limitRate responds with a 429 and returns true when the client has gone over its rate limit.
+// authData is nil for public routes.
+func (h *Handler) limitRate(w http.ResponseWriter, r *http.Request, authData *AuthData) bool {
+	if h.RateLimiter == nil {
+		return false
+	}
+	rl := h.RateLimiter
+	groupName, limits := rl.group(r.URL.Path)
+	client, key, limit := rl.client(r, authData, limits)
+	return rl.take(w, r, groupName, client, key, limit)
+}
+
+// take responds with a 429 and returns true when the client's key in the group has gone over the limit.
+func (rl *RateLimiter) take(w http.ResponseWriter, r *http.Request, groupName, client, key string, limit ratelimit.Limit) bool {
+	allowed, retryAfter, err := rl.Store.Take(r.Context(), groupName+":"+key, limit)
+	if err != nil {
+		logger.GetFromContext(r.Context()).Warn("Rate limit check failed, allowing request",
+			zap.String("err", errors.Wrap(err, "failed to take from the rate limit").Error()),
+		)
+		return false
+	}
+	if allowed {
+		return false
+	}
+	httpRateLimitedTotal.Inc(groupName, client)
+	w.Header().Set(RetryAfterHeaderKey, strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	RespondWith(r, w, http.StatusTooManyRequests, &TooManyRequests{}, nil)
+	return true
+}
+
+func (rl *RateLimiter) group(path string) (string, RateLimits) {
+	name, limits, prefixLen := defaultRateLimitGroup, rl.Default, -1
+	for _, g := range rl.Groups {
+		if strings.HasPrefix(path, g.PathPrefix) && len(g.PathPrefix) > prefixLen {
+			name, limits, prefixLen = g.Name, g.Limits, len(g.PathPrefix)
+		}
+	}
+	return name, limits
+}
+
+// client returns the kind of client, the key it's limited by, and its limit.
+func (rl *RateLimiter) client(r *http.Request, authData *AuthData, limits RateLimits) (string, string, ratelimit.Limit) {
+	if authData == nil {
+		return rateLimitClientPublic, "public:" + rl.clientIP(r), limits.Public
+	}
+	if authData.IsAdmin() {
+		return rateLimitClientAdmin, "admin", limits.Admin
+	}
+	return rateLimitClientProxyUser, "user:" + authData.UserID, limits.ProxyUser
+}
+
+func (rl *RateLimiter) clientIP(r *http.Request) string {
+	if rl.ClientIPHeader != "" {
+		if ip := rl.forwardedIP(r.Header.Get(rl.ClientIPHeader)); ip != "" {
+			return ip
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// forwardedIP returns the address the outermost trusted proxy appended to the header.
+// Proxies append the address they received the request from, so only the last TrustedProxies entries can be trusted;
+// a client can put anything before them.
+func (rl *RateLimiter) forwardedIP(header string) string {
+	if header == "" {
+		return ""
+	}
+	entries := strings.Split(header, ",")
+	hops := rl.TrustedProxies
+	if hops < 1 {
+		hops = 1
+	}
+	index := len(entries) - hops
+	if index < 0 {
+		// the request came through fewer proxies than expected, so every entry was added by one of them
+		index = 0
+	}
+	return strings.TrimSpace(entries[index])
+}
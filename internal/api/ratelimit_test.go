@@ -0,0 +1,196 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/require"
+	"github.com/worlve/sp-service/internal/util/clock"
+	"github.com/worlve/sp-service/internal/util/ratelimit"
+)
+
+func TestRateLimit(t *testing.T) {
+	limit := ratelimit.Limit{Requests: 1, Per: 10 * time.Second}
+	ok := func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		RespondWith(r, w, http.StatusOK, nil, nil)
+	}
+	type request struct {
+		path       string
+		userID     string
+		remoteAddr string
+		forwarded  string
+	}
+	cases := []struct {
+		name                string
+		paramTrustedProxies int
+		paramFirst          request
+		paramSecond         request
+		returnStatus        int
+		returnRetryAfter    string
+	}{
+		{
+			name:             "test proxy user over the limit",
+			paramFirst:       request{path: "/api/test/pages", userID: "UR_1"},
+			paramSecond:      request{path: "/api/test/pages", userID: "UR_1"},
+			returnStatus:     http.StatusTooManyRequests,
+			returnRetryAfter: "10",
+		},
+		{
+			name:         "test proxy users are limited separately",
+			paramFirst:   request{path: "/api/test/pages", userID: "UR_1"},
+			paramSecond:  request{path: "/api/test/pages", userID: "UR_2"},
+			returnStatus: http.StatusOK,
+		},
+		{
+			name:             "test admins share a limit",
+			paramFirst:       request{path: "/api/test/pages", remoteAddr: "10.0.0.1:1234"},
+			paramSecond:      request{path: "/api/test/pages", remoteAddr: "10.0.0.2:1234"},
+			returnStatus:     http.StatusTooManyRequests,
+			returnRetryAfter: "10",
+		},
+		{
+			name:         "test admin and proxy user are limited separately",
+			paramFirst:   request{path: "/api/test/pages"},
+			paramSecond:  request{path: "/api/test/pages", userID: "UR_1"},
+			returnStatus: http.StatusOK,
+		},
+		{
+			name:             "test public route limited by IP",
+			paramFirst:       request{path: "/livez", remoteAddr: "10.0.0.1:1234"},
+			paramSecond:      request{path: "/livez", remoteAddr: "10.0.0.1:5678"},
+			returnStatus:     http.StatusTooManyRequests,
+			returnRetryAfter: "10",
+		},
+		{
+			name:         "test public route from another IP",
+			paramFirst:   request{path: "/livez", remoteAddr: "10.0.0.1:1234"},
+			paramSecond:  request{path: "/livez", remoteAddr: "10.0.0.2:1234"},
+			returnStatus: http.StatusOK,
+		},
+		{
+			name:         "test public route limited by the forwarded IP",
+			paramFirst:   request{path: "/livez", remoteAddr: "10.0.0.1:1234", forwarded: "1.1.1.1"},
+			paramSecond:  request{path: "/livez", remoteAddr: "10.0.0.1:1234", forwarded: "2.2.2.2"},
+			returnStatus: http.StatusOK,
+		},
+		{
+			name:             "test spoofed forwarded IPs are ignored",
+			paramFirst:       request{path: "/livez", remoteAddr: "10.0.0.1:1234", forwarded: "6.6.6.6, 1.1.1.1"},
+			paramSecond:      request{path: "/livez", remoteAddr: "10.0.0.1:1234", forwarded: "7.7.7.7, 1.1.1.1"},
+			returnStatus:     http.StatusTooManyRequests,
+			returnRetryAfter: "10",
+		},
+		{
+			name:                "test forwarded IP behind several proxies",
+			paramTrustedProxies: 2,
+			paramFirst:          request{path: "/livez", remoteAddr: "10.0.0.1:1234", forwarded: "1.1.1.1, 10.0.0.2"},
+			paramSecond:         request{path: "/livez", remoteAddr: "10.0.0.1:1234", forwarded: "2.2.2.2, 10.0.0.2"},
+			returnStatus:        http.StatusOK,
+		},
+		{
+			name:                "test spoofed forwarded IPs behind several proxies are ignored",
+			paramTrustedProxies: 2,
+			paramFirst:          request{path: "/livez", remoteAddr: "10.0.0.1:1234", forwarded: "6.6.6.6, 1.1.1.1, 10.0.0.2"},
+			paramSecond:         request{path: "/livez", remoteAddr: "10.0.0.1:1234", forwarded: "7.7.7.7, 1.1.1.1, 10.0.0.3"},
+			returnStatus:        http.StatusTooManyRequests,
+			returnRetryAfter:    "10",
+		},
+		{
+			name:         "test groups are limited separately",
+			paramFirst:   request{path: "/api/test/pages", userID: "UR_1"},
+			paramSecond:  request{path: "/api/test/search", userID: "UR_1"},
+			returnStatus: http.StatusOK,
+		},
+		{
+			name:         "test unlimited group",
+			paramFirst:   request{path: "/api/test/search/pages", userID: "UR_1"},
+			paramSecond:  request{path: "/api/test/search/pages", userID: "UR_1"},
+			returnStatus: http.StatusOK,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+			handler := Handler{
+				AuthN:   AuthN{Datacenter: LocalDatacenterEnv},
+				AuthZ:   AuthZ{APIPath: "api/test"},
+				APIPath: "api/test",
+				Router: NewRouter("api/test", "static/test", []RouterHandler{
+					{Method: http.MethodGet, Endpoint: "/api/test/pages", Handle: ok},
+					{Method: http.MethodGet, Endpoint: "/api/test/search", Handle: ok},
+					{Method: http.MethodGet, Endpoint: "/api/test/search/pages", Handle: ok},
+					{Method: http.MethodGet, Endpoint: "/livez", Handle: ok, NoAuth: true},
+				}),
+				RateLimiter: &RateLimiter{
+					Store:   &ratelimit.MemoryStore{Clock: clock.MockClock{MockedTime: &now}},
+					Default: RateLimits{Admin: limit, ProxyUser: limit, Public: limit},
+					Groups: []RateLimitGroup{
+						{Name: "search", PathPrefix: "/api/test/search", Limits: RateLimits{Admin: limit, ProxyUser: limit, Public: limit}},
+						{Name: "searchPages", PathPrefix: "/api/test/search/pages"},
+					},
+					ClientIPHeader: "X-Forwarded-For",
+					TrustedProxies: tc.paramTrustedProxies,
+				},
+			}
+			var resp *http.Response
+			for _, req := range []request{tc.paramFirst, tc.paramSecond} {
+				r := httptest.NewRequest(http.MethodGet, "http://test.com"+req.path, nil)
+				if req.userID != "" {
+					r.Header.Set(UserIDHeaderKey, req.userID)
+				}
+				if req.remoteAddr != "" {
+					r.RemoteAddr = req.remoteAddr
+				}
+				if req.forwarded != "" {
+					r.Header.Set("X-Forwarded-For", req.forwarded)
+				}
+				w := httptest.NewRecorder()
+				handler.ServeHTTP(w, r)
+				resp = w.Result()
+			}
+			require.Equal(t, tc.returnStatus, resp.StatusCode)
+			require.Equal(t, tc.returnRetryAfter, resp.Header.Get(RetryAfterHeaderKey))
+			if tc.returnStatus == http.StatusTooManyRequests {
+				var body responseFormat
+				require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+				require.Equal(t, "too many requests", body.Meta.Message)
+			}
+		})
+	}
+}
+
+func TestRateLimitBeforeAuthentication(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	ok := func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		RespondWith(r, w, http.StatusOK, nil, nil)
+	}
+	handler := Handler{
+		AuthN:   AuthN{Datacenter: "PROD", AdminAuthSecret: "secret"},
+		AuthZ:   AuthZ{APIPath: "api/test"},
+		APIPath: "api/test",
+		Router: NewRouter("api/test", "static/test", []RouterHandler{
+			{Method: http.MethodGet, Endpoint: "/api/test/pages", Handle: ok},
+		}),
+		RateLimiter: &RateLimiter{
+			Store:   &ratelimit.MemoryStore{Clock: clock.MockClock{MockedTime: &now}},
+			Default: RateLimits{IP: ratelimit.Limit{Requests: 2, Per: 10 * time.Second}},
+		},
+	}
+	send := func(remoteAddr, secret string) int {
+		r := httptest.NewRequest(http.MethodGet, "http://test.com/api/test/pages", nil)
+		r.RemoteAddr = remoteAddr
+		r.Header.Set(AdminAuthSecretHeaderKey, secret)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w.Result().StatusCode
+	}
+	require.Equal(t, http.StatusUnauthorized, send("10.0.0.1:1234", "guess"))
+	require.Equal(t, http.StatusUnauthorized, send("10.0.0.1:1234", "guess"))
+	require.Equal(t, http.StatusTooManyRequests, send("10.0.0.1:1234", "guess"))
+	require.Equal(t, http.StatusTooManyRequests, send("10.0.0.1:1234", "secret"), "the IP is limited even with the right secret")
+	require.Equal(t, http.StatusOK, send("10.0.0.2:1234", "secret"))
+}
@@ -0,0 +1,136 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/worlve/sp-service/internal/util/logger"
+	"github.com/worlve/sp-service/internal/util/tracing"
+	"github.com/worlve/sp-service/internal/util/transaction"
+	"github.com/julienschmidt/httprouter"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// accessLog collects the details of a request as it moves through the handler chain.
+type accessLog struct {
+	route  string
+	params httprouter.Params
+	userID string
+}
+
+type accessLogKeyType string
+
+const accessLogKey = accessLogKeyType("accessLog")
+
+func setAccessLogOnContext(ctx context.Context, entry *accessLog) context.Context {
+	return context.WithValue(ctx, accessLogKey, entry)
+}
+
+func getAccessLogFromContext(ctx context.Context) (*accessLog, bool) {
+	entry, ok := ctx.Value(accessLogKey).(*accessLog)
+	return entry, ok
+}
+
+// setRoute records the route pattern that matched the request, and its parameters, for the access and audit logs.
+func setRoute(r *http.Request, route string, params httprouter.Params) {
+	if entry, ok := getAccessLogFromContext(r.Context()); ok {
+		entry.route = route
+		entry.params = params
+	}
+}
+
+// statusRecorder remembers the status written to the response for the access log.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (sr *statusRecorder) WriteHeader(status int) {
+	if sr.status == 0 {
+		sr.status = status
+	}
+	sr.ResponseWriter.WriteHeader(status)
+}
+
+func (sr *statusRecorder) Write(b []byte) (int, error) {
+	if sr.status == 0 {
+		sr.status = http.StatusOK
+	}
+	return sr.ResponseWriter.Write(b)
+}
+
+// Flush lets streaming handlers flush through the recorder.
+func (sr *statusRecorder) Flush() {
+	if flusher, ok := sr.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap lets an http.ResponseController reach the underlying response.
+func (sr *statusRecorder) Unwrap() http.ResponseWriter {
+	return sr.ResponseWriter
+}
+
+// startRequest gets or creates the request ID, starts the request's span, and puts the request-scoped logger on the context.
+// It returns a function that ends the span, writes the access log, and records the request's metrics once it is handled.
+func (h *Handler) startRequest(w http.ResponseWriter, r *http.Request) (*statusRecorder, *http.Request, func()) {
+	start := time.Now()
+	t := transaction.New(r.Header.Get(transaction.RequestIDHeaderKey))
+	w.Header().Set(transaction.RequestIDHeaderKey, t.RequestID)
+	baseLogger := h.Logger
+	if baseLogger == nil {
+		baseLogger = zap.NewNop()
+	}
+	entry := &accessLog{}
+	ctx := transaction.SetOnContext(r.Context(), t)
+	ctx = tracing.ContextWithTraceParent(ctx, r.Header.Get(tracing.TraceParentHeaderKey))
+	ctx, span := tracing.StartSpan(ctx, "HTTP "+r.Method, tracing.SpanKindServer)
+	span.SetAttribute("http.method", r.Method)
+	span.SetAttribute("http.target", r.URL.Path)
+	span.SetAttribute("requestId", t.RequestID)
+	requestLogger := baseLogger.With(t.LogFields()...)
+	if traceID := span.TraceID(); traceID != "" {
+		requestLogger = requestLogger.With(zap.String("traceId", traceID))
+	}
+	ctx = logger.SetOnContext(ctx, requestLogger)
+	ctx = setAccessLogOnContext(ctx, entry)
+	recorder := &statusRecorder{ResponseWriter: w}
+	return recorder, r.WithContext(ctx), func() {
+		status := recorder.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		latency := time.Since(start)
+		if entry.route != "" {
+			span.SetName("HTTP " + r.Method + " " + entry.route)
+			span.SetAttribute("http.route", entry.route)
+		}
+		span.SetAttribute("http.status_code", status)
+		span.SetAttribute("enduser.id", entry.userID)
+		if status >= http.StatusInternalServerError {
+			span.RecordError(errors.New(http.StatusText(status)))
+		}
+		span.End()
+		observeRequest(r.Method, entry.route, status, latency)
+		logger.GetFromContext(ctx).Info("Request handled",
+			zap.String("method", r.Method),
+			zap.String("route", entry.route),
+			zap.String("path", r.URL.Path),
+			zap.Int("status", status),
+			zap.Duration("latency", latency),
+			zap.String("userId", entry.userID),
+		)
+	}
+}
+
+// setUserOnRequestLog adds the authenticated user to the access log and to the request-scoped logger.
+func setUserOnRequestLog(r *http.Request, userID string) *http.Request {
+	ctx := r.Context()
+	if entry, ok := getAccessLogFromContext(ctx); ok {
+		entry.userID = userID
+	}
+	ctx = logger.SetOnContext(ctx, logger.GetFromContext(ctx).With(zap.String("userId", userID)))
+	return r.WithContext(ctx)
+}
@@ -0,0 +1,125 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"github.com/worlve/sp-service/internal/util/logger"
+	"github.com/worlve/sp-service/internal/util/transaction"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestRequestLog(t *testing.T) {
+	cases := []struct {
+		name              string
+		paramPath         string
+		paramHeaders      map[string]string
+		returnStatus      int
+		returnRequestID   string
+		returnRoute       string
+		returnUserID      string
+		returnErrorLogged bool
+	}{
+		{
+			name:      "test request id is propagated",
+			paramPath: "/api/test/widget/WG_1",
+			paramHeaders: map[string]string{
+				transaction.RequestIDHeaderKey: "REQUEST_1",
+				UserIDHeaderKey:                "UR_1",
+			},
+			returnStatus:    http.StatusOK,
+			returnRequestID: "REQUEST_1",
+			returnRoute:     "/api/test/widget/:widgetID",
+			returnUserID:    "UR_1",
+		},
+		{
+			name:      "test error response is logged with request fields",
+			paramPath: "/api/test/broken",
+			paramHeaders: map[string]string{
+				transaction.RequestIDHeaderKey: "REQUEST_2",
+				UserIDHeaderKey:                "UR_2",
+			},
+			returnStatus:      http.StatusInternalServerError,
+			returnRequestID:   "REQUEST_2",
+			returnRoute:       "/api/test/broken",
+			returnUserID:      "UR_2",
+			returnErrorLogged: true,
+		},
+		{
+			name:         "test unknown route has no route pattern",
+			paramPath:    "/api/test/unknown",
+			returnStatus: http.StatusNotFound,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			core := zapcore.NewCore(zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), zapcore.AddSync(&buf), zapcore.InfoLevel)
+			handler := Handler{
+				AuthN:   AuthN{Datacenter: LocalDatacenterEnv},
+				AuthZ:   AuthZ{APIPath: "api/test"},
+				APIPath: "api/test",
+				Router: NewRouter("api/test", "static/test", []RouterHandler{
+					{
+						Method:   http.MethodGet,
+						Endpoint: "/api/test/widget/:widgetID",
+						Handle: func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+							logger.GetFromContext(r.Context()).Info("handling widget")
+							RespondWith(r, w, http.StatusOK, p.ByName("widgetID"), nil)
+						},
+					},
+					{
+						Method:   http.MethodGet,
+						Endpoint: "/api/test/broken",
+						Handle: func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+							RespondWith(r, w, http.StatusInternalServerError, &InternalErr{}, errors.New("broken"))
+						},
+					},
+				}),
+				Logger: zap.New(core),
+			}
+			r := httptest.NewRequest(http.MethodGet, "http://test.com"+tc.paramPath, nil)
+			for key, value := range tc.paramHeaders {
+				r.Header.Set(key, value)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			resp := w.Result()
+			require.Equal(t, tc.returnStatus, resp.StatusCode)
+			requestID := resp.Header.Get(transaction.RequestIDHeaderKey)
+			require.NotEmpty(t, requestID)
+			if tc.returnRequestID != "" {
+				require.Equal(t, tc.returnRequestID, requestID)
+			}
+			var entries []map[string]interface{}
+			decoder := json.NewDecoder(&buf)
+			for decoder.More() {
+				var entry map[string]interface{}
+				require.NoError(t, decoder.Decode(&entry))
+				require.Equal(t, requestID, entry["requestId"])
+				require.NotEmpty(t, entry["transactionId"])
+				entries = append(entries, entry)
+			}
+			require.NotEmpty(t, entries)
+			if tc.returnErrorLogged {
+				require.Equal(t, "Response error", entries[0]["msg"])
+				require.Equal(t, "broken", entries[0]["err"])
+				require.Equal(t, tc.returnUserID, entries[0]["userId"])
+			}
+			access := entries[len(entries)-1]
+			require.Equal(t, "Request handled", access["msg"])
+			require.Equal(t, http.MethodGet, access["method"])
+			require.Equal(t, tc.returnRoute, access["route"])
+			require.Equal(t, float64(tc.returnStatus), access["status"])
+			require.Equal(t, tc.returnUserID, access["userId"])
+			require.Contains(t, access, "latency")
+		})
+	}
+}
@@ -5,38 +5,47 @@ import (
 	"fmt"
 	"net/http"
 
+	"github.com/worlve/sp-service/internal/util/logger"
+	"github.com/worlve/sp-service/internal/util/transaction"
 	"go.uber.org/zap"
 )
 
 type responseFormat struct {
 	Result interface{} `json:"result,omitempty"`
 	Meta   struct {
-		HTTPStatus string `json:"httpStatus"`
-		Message    string `json:"message,omitempty"`
+		HTTPStatus string       `json:"httpStatus"`
+		Code       Code         `json:"code,omitempty"`
+		Message    string       `json:"message,omitempty"`
+		Details    []FieldError `json:"details,omitempty"`
+		RequestID  string       `json:"requestId,omitempty"`
 	} `json:"meta"`
 }
 
 // RespondWith responds to the given request with the given responsewriter.
 // It also logs information regarding the request and response.
+// When responseData is an error, its message is sent with its code; see RespondWithError to translate errors.
+// Server errors caused by a canceled store call are sent as a 503 when the request timed out, or a 499 when the client went away.
 func RespondWith(r *http.Request, w http.ResponseWriter, status int, responseData interface{}, errToLog error) {
+	status, responseData = canceledResponse(status, responseData, errToLog)
 	dataWrapper := responseFormat{}
-	// @TODO: add in transaction/request ids.
-	dataWrapper.Meta.HTTPStatus = fmt.Sprintf("%v - %v", status, http.StatusText(status))
-	if errMsg, ok := responseData.(error); ok {
-		dataWrapper.Meta.Message = errMsg.Error()
+	dataWrapper.Meta.HTTPStatus = fmt.Sprintf("%v - %v", status, statusText(status))
+	if t, ok := transaction.GetFromContext(r.Context()); ok {
+		dataWrapper.Meta.RequestID = t.RequestID
+	}
+	if err, ok := responseData.(error); ok {
+		responseErr := newError(status, err)
+		dataWrapper.Meta.Code = responseErr.Code
+		dataWrapper.Meta.Message = responseErr.Message
+		dataWrapper.Meta.Details = responseErr.Details
 	} else {
 		dataWrapper.Result = responseData
 	}
 	if errToLog != nil {
-		// @TODO: we need a better logging paradigm.
-		logger, _ := zap.NewProduction()
-		defer logger.Sync()
-		logger.Info("Response error",
+		logger.GetFromContext(r.Context()).Info("Response error",
+			zap.Int("status", status),
 			zap.String("err", errToLog.Error()),
 			zap.String("errVerbose", fmt.Sprintf("%+v", errToLog)),
 		)
-		// @DEBUG: probably shouldn't have, but need to handler tests to get clean output
-		fmt.Printf("%+v\n", errToLog)
 	}
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
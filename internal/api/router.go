@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/http"
 
+	"github.com/worlve/sp-service/internal/util/openapi3"
 	"github.com/julienschmidt/httprouter"
 	"github.com/pkg/errors"
 )
@@ -12,6 +13,8 @@ import (
 type Router struct {
 	http.Handler
 	NonAuthRoutes []NonAuthRoute
+	// OpenAPI is the generated spec of the routes, which requests and responses are checked against.
+	OpenAPI openapi3.Document
 }
 
 // NonAuthRoute a route that does not require authentication
@@ -26,13 +29,32 @@ type RouterHandler struct {
 	Method   string
 	Endpoint string
 	Handle   httprouter.Handle
+	// NoAuth serves the route without authenticating the request, such as for probes.
+	NoAuth bool
+	// Doc describes the route in the generated OpenAPI spec.
+	Doc *RouteDoc
 }
 
 // NewRouter adds the routes to a new handler and returns the handler with non-auth routes.
+// The OpenAPI spec of the routes is served at /{apiPath}/openapi.json.
 func NewRouter(apiPath, staticPath string, routerHandlers []RouterHandler) Router {
 	handler := httprouter.New()
-	handleAuthRoutes(handler, routerHandlers)
+	openAPIHandler, doc := openAPIRouterHandler(apiPath, routerHandlers)
+	routerHandlers = append(routerHandlers, openAPIHandler)
+	var authRouterHandlers []RouterHandler
 	nonAuthRoutes := newNonAuthRoutes()
+	for _, routerHandler := range routerHandlers {
+		if routerHandler.NoAuth {
+			nonAuthRoutes = append(nonAuthRoutes, NonAuthRoute{
+				Method:  routerHandler.Method,
+				Path:    routerHandler.Endpoint,
+				Handler: routerHandler.Handle,
+			})
+			continue
+		}
+		authRouterHandlers = append(authRouterHandlers, routerHandler)
+	}
+	handleAuthRoutes(handler, authRouterHandlers)
 	handleNonAuthRoutes(handler, nonAuthRoutes)
 	serveFiles(handler, apiPath, staticPath)
 	handler.NotFound = http.HandlerFunc(handleNotFound)
@@ -41,6 +63,7 @@ func NewRouter(apiPath, staticPath string, routerHandlers []RouterHandler) Route
 	return Router{
 		Handler:       handler,
 		NonAuthRoutes: nonAuthRoutes,
+		OpenAPI:       doc,
 	}
 }
 
@@ -55,24 +78,33 @@ func handleAuthRoutes(handler *httprouter.Router, routerHandlers []RouterHandler
 }
 
 func handleAuthRoute(handler *httprouter.Router, routerHandler RouterHandler) {
+	handle := withRoute(routerHandler.Endpoint, routerHandler.Handle)
 	if routerHandler.Method == http.MethodGet {
-		handler.GET(routerHandler.Endpoint, routerHandler.Handle)
+		handler.GET(routerHandler.Endpoint, handle)
 	} else if routerHandler.Method == http.MethodPut {
-		handler.PUT(routerHandler.Endpoint, routerHandler.Handle)
+		handler.PUT(routerHandler.Endpoint, handle)
 	} else if routerHandler.Method == http.MethodPost {
-		handler.POST(routerHandler.Endpoint, routerHandler.Handle)
+		handler.POST(routerHandler.Endpoint, handle)
 	} else if routerHandler.Method == http.MethodDelete {
-		handler.DELETE(routerHandler.Endpoint, routerHandler.Handle)
+		handler.DELETE(routerHandler.Endpoint, handle)
 	} else if routerHandler.Method == http.MethodPatch {
-		handler.PATCH(routerHandler.Endpoint, routerHandler.Handle)
+		handler.PATCH(routerHandler.Endpoint, handle)
 	} else if routerHandler.Method == http.MethodOptions {
-		handler.OPTIONS(routerHandler.Endpoint, routerHandler.Handle)
+		handler.OPTIONS(routerHandler.Endpoint, handle)
 	}
 }
 
 func handleNonAuthRoutes(handler *httprouter.Router, nonAuthRoutes []NonAuthRoute) {
 	for _, route := range nonAuthRoutes {
-		handler.Handle(route.Method, route.Path, route.Handler)
+		handler.Handle(route.Method, route.Path, withRoute(route.Path, route.Handler))
+	}
+}
+
+// withRoute records the route pattern that matched the request, and its parameters, before handling it.
+func withRoute(route string, handle httprouter.Handle) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		setRoute(r, route, p)
+		handle(w, r, p)
 	}
 }
 
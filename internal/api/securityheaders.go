@@ -0,0 +1,47 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// apiContentSecurityPolicy stops browsers from running or framing anything in the API's JSON responses.
+	apiContentSecurityPolicy = "default-src 'none'; frame-ancestors 'none'"
+	// docsContentSecurityPolicy allows what the ReDoc page in static/docs loads: its script, Google Fonts, and the spec.
+	docsContentSecurityPolicy = "default-src 'self'; " +
+		"script-src 'self' https://rebilly.github.io; " +
+		"style-src 'self' 'unsafe-inline' https://fonts.googleapis.com; " +
+		"font-src https://fonts.gstatic.com; " +
+		"img-src 'self' data: https:; " +
+		"worker-src blob:; " +
+		"frame-ancestors 'none'"
+)
+
+// SecurityHeaders sets the headers that tell browsers to guard against misuse of the responses.
+type SecurityHeaders struct {
+	Handler http.Handler
+	APIPath string
+	// HSTSMaxAge is how long browsers should only use HTTPS for the service.
+	// No Strict-Transport-Security header is sent when it's 0, such as when running locally over HTTP.
+	HSTSMaxAge time.Duration
+}
+
+// ServeHTTP sets the security headers before handling the request.
+func (s SecurityHeaders) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	header := w.Header()
+	header.Set("X-Content-Type-Options", "nosniff")
+	header.Set("X-Frame-Options", "DENY")
+	header.Set("Referrer-Policy", "no-referrer")
+	if s.HSTSMaxAge > 0 {
+		header.Set("Strict-Transport-Security", fmt.Sprintf("max-age=%v; includeSubDomains", int(s.HSTSMaxAge.Seconds())))
+	}
+	if strings.HasPrefix(r.URL.Path, fmt.Sprintf("/%v/docs", s.APIPath)) {
+		header.Set("Content-Security-Policy", docsContentSecurityPolicy)
+	} else {
+		header.Set("Content-Security-Policy", apiContentSecurityPolicy)
+	}
+	s.Handler.ServeHTTP(w, r)
+}
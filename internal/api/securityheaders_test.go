@@ -0,0 +1,52 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecurityHeaders(t *testing.T) {
+	cases := []struct {
+		name       string
+		paramPath  string
+		paramHSTS  time.Duration
+		returnCSP  string
+		returnHSTS string
+	}{
+		{
+			name:       "test api response",
+			paramPath:  "/api/test/pages",
+			paramHSTS:  24 * time.Hour,
+			returnCSP:  apiContentSecurityPolicy,
+			returnHSTS: "max-age=86400; includeSubDomains",
+		},
+		{
+			name:      "test docs allow the docs page to load",
+			paramPath: "/api/test/docs/index.html",
+			returnCSP: docsContentSecurityPolicy,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := SecurityHeaders{
+				Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(http.StatusOK)
+				}),
+				APIPath:    "api/test",
+				HSTSMaxAge: tc.paramHSTS,
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "http://test.com"+tc.paramPath, nil))
+			resp := w.Result()
+			require.Equal(t, "nosniff", resp.Header.Get("X-Content-Type-Options"))
+			require.Equal(t, "DENY", resp.Header.Get("X-Frame-Options"))
+			require.Equal(t, "no-referrer", resp.Header.Get("Referrer-Policy"))
+			require.Equal(t, tc.returnCSP, resp.Header.Get("Content-Security-Policy"))
+			require.Equal(t, tc.returnHSTS, resp.Header.Get("Strict-Transport-Security"))
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/worlve/sp-service/internal/util/logger"
+	"github.com/worlve/sp-service/internal/util/openapi"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// SpecValidator checks each request and JSON response against the API spec generated from the routes, see Router.OpenAPI.
+// Routes the spec doesn't have aren't checked.
+type SpecValidator struct {
+	Spec *openapi.Spec
+	// Strict rejects requests that don't match the spec with a 400, and replaces responses that don't with a 500
+	// saying why, so it's only for tests. Otherwise mismatches are only logged.
+	Strict bool
+}
+
+// serve handles the request with next, checking the request and its response against the spec.
+func (v *SpecValidator) serve(next http.Handler, w http.ResponseWriter, r *http.Request) {
+	operation, ok := v.Spec.Find(r.Method, r.URL.Path)
+	if !ok {
+		next.ServeHTTP(w, r)
+		return
+	}
+	var body []byte
+	if operation.HasJSONBody() {
+		var err error
+		body, err = ioutil.ReadAll(r.Body)
+		if err != nil {
+			r.Body = ioutil.NopCloser(io.MultiReader(bytes.NewReader(body), r.Body))
+			next.ServeHTTP(w, r)
+			return
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+	if violations := operation.ValidateRequest(r.URL.Query(), body); len(violations) > 0 {
+		if v.Strict {
+			RespondWithError(r, w, requestMismatch(violations))
+			return
+		}
+		logMismatch(r, "Request does not match the API spec", operation, violations)
+	}
+	recorder := &specRecorder{ResponseWriter: w, hold: v.Strict}
+	next.ServeHTTP(recorder, r)
+	if !recorder.isJSON {
+		return
+	}
+	violations := operation.ValidateResponse(recorder.status, recorder.body.Bytes())
+	if len(violations) == 0 {
+		recorder.release()
+		return
+	}
+	if v.Strict {
+		err := errors.Errorf("response does not match the API spec: %v", joinViolations(violations))
+		RespondWith(r, w, http.StatusInternalServerError, err, err)
+		return
+	}
+	logMismatch(r, "Response does not match the API spec", operation, violations)
+}
+
+func requestMismatch(violations []openapi.Violation) *Error {
+	err := &Error{
+		Status:  http.StatusBadRequest,
+		Code:    CodeValidationFailed,
+		Message: fmt.Sprintf("request does not match the API spec: %v", joinViolations(violations)),
+	}
+	for _, violation := range violations {
+		err.Details = append(err.Details, FieldError{Field: violation.Field, Message: violation.Message})
+	}
+	return err
+}
+
+func logMismatch(r *http.Request, message string, operation *openapi.Operation, violations []openapi.Violation) {
+	logger.GetFromContext(r.Context()).Warn(message,
+		zap.String("operation", operation.ID),
+		zap.String("violations", joinViolations(violations)),
+	)
+}
+
+func joinViolations(violations []openapi.Violation) string {
+	messages := make([]string, 0, len(violations))
+	for _, violation := range violations {
+		messages = append(messages, violation.String())
+	}
+	return strings.Join(messages, "; ")
+}
+
+// specRecorder keeps a copy of a JSON response's body so it can be checked once it's handled.
+// When hold is set, the JSON response isn't written until release is called, so it can be replaced.
+type specRecorder struct {
+	http.ResponseWriter
+	hold   bool
+	status int
+	isJSON bool
+	body   bytes.Buffer
+}
+
+func (sr *specRecorder) WriteHeader(status int) {
+	if sr.status != 0 {
+		return
+	}
+	sr.status = status
+	sr.isJSON = strings.HasPrefix(sr.Header().Get("Content-Type"), "application/json")
+	if !sr.holding() {
+		sr.ResponseWriter.WriteHeader(status)
+	}
+}
+
+func (sr *specRecorder) Write(b []byte) (int, error) {
+	if sr.status == 0 {
+		sr.WriteHeader(http.StatusOK)
+	}
+	if sr.isJSON {
+		sr.body.Write(b)
+	}
+	if sr.holding() {
+		return len(b), nil
+	}
+	return sr.ResponseWriter.Write(b)
+}
+
+// Flush lets streaming handlers flush through the recorder, unless the response is being held.
+func (sr *specRecorder) Flush() {
+	if sr.holding() {
+		return
+	}
+	if flusher, ok := sr.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap lets an http.ResponseController reach the underlying response.
+func (sr *specRecorder) Unwrap() http.ResponseWriter {
+	return sr.ResponseWriter
+}
+
+func (sr *specRecorder) holding() bool {
+	return sr.hold && sr.isJSON
+}
+
+// release writes the held response.
+func (sr *specRecorder) release() {
+	if !sr.holding() {
+		return
+	}
+	sr.ResponseWriter.WriteHeader(sr.status)
+	sr.ResponseWriter.Write(sr.body.Bytes())
+}
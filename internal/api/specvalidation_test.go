@@ -0,0 +1,129 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/require"
+	"github.com/worlve/sp-service/internal/util/openapi"
+)
+
+type testCreatePageRequest struct {
+	Title          string `json:"title"`
+	VersionID      string `json:"versionId"`
+	PageTemplateID string `json:"pageTemplateId"`
+	Permission     string `json:"permission"`
+}
+
+type testCreatePageResponse struct {
+	ID string `json:"id"`
+}
+
+func TestSpecValidator(t *testing.T) {
+	router := NewRouter("api", "static/test", []RouterHandler{
+		{
+			Method:   http.MethodPost,
+			Endpoint: "/api/pages",
+			Handle:   func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {},
+			Doc: &RouteDoc{
+				OperationID: "createPage",
+				Request:     testCreatePageRequest{},
+				Response:    testCreatePageResponse{},
+			},
+		},
+	})
+	spec, err := openapi.New(router.OpenAPI)
+	require.NoError(t, err)
+	cases := []struct {
+		name               string
+		paramStrict        bool
+		paramPath          string
+		paramBody          string
+		paramResponse      string
+		returnHandled      bool
+		returnStatusCode   int
+		returnResponseBody string
+	}{
+		{
+			name:               "test matching request and response",
+			paramStrict:        true,
+			paramPath:          "/api/pages",
+			paramBody:          `{"title":"test title","versionId":"VR_1","pageTemplateId":"PGT_1","permission":"PR"}`,
+			paramResponse:      `{"result":{"id":"PG_1"},"meta":{"httpStatus":"200 - OK","requestId":"RQ_1"}}`,
+			returnHandled:      true,
+			returnStatusCode:   200,
+			returnResponseBody: `{"result":{"id":"PG_1"},"meta":{"httpStatus":"200 - OK","requestId":"RQ_1"}}`,
+		},
+		{
+			name:               "test strict request mismatch",
+			paramStrict:        true,
+			paramPath:          "/api/pages",
+			paramBody:          `{"title":"test title","versionId":1,"pageTemplateId":"PGT_1","permission":"PR"}`,
+			returnStatusCode:   400,
+			returnResponseBody: "{\"meta\":{\"httpStatus\":\"400 - Bad Request\",\"code\":\"VALIDATION_FAILED\",\"message\":\"request does not match the API spec: versionId: must be a string\",\"details\":[{\"field\":\"versionId\",\"message\":\"must be a string\"}]}}\n",
+		},
+		{
+			name:               "test log only request mismatch",
+			paramPath:          "/api/pages",
+			paramBody:          `{"title":"test title","versionId":1,"pageTemplateId":"PGT_1","permission":"PR"}`,
+			paramResponse:      `{"result":{"id":"PG_1"},"meta":{"httpStatus":"200 - OK","requestId":"RQ_1"}}`,
+			returnHandled:      true,
+			returnStatusCode:   200,
+			returnResponseBody: `{"result":{"id":"PG_1"},"meta":{"httpStatus":"200 - OK","requestId":"RQ_1"}}`,
+		},
+		{
+			name:               "test strict response mismatch",
+			paramStrict:        true,
+			paramPath:          "/api/pages",
+			paramBody:          `{"title":"test title","versionId":"VR_1","pageTemplateId":"PGT_1","permission":"PR"}`,
+			paramResponse:      `{"result":{"guid":"PG_1"},"meta":{"httpStatus":"200 - OK","requestId":"RQ_1"}}`,
+			returnHandled:      true,
+			returnStatusCode:   500,
+			returnResponseBody: "{\"meta\":{\"httpStatus\":\"500 - Internal Server Error\",\"code\":\"INTERNAL\",\"message\":\"response does not match the API spec: result.id: is required; result.guid: is not allowed\"}}\n",
+		},
+		{
+			name:               "test log only response mismatch",
+			paramPath:          "/api/pages",
+			paramBody:          `{"title":"test title","versionId":"VR_1","pageTemplateId":"PGT_1","permission":"PR"}`,
+			paramResponse:      `{"result":{"guid":"PG_1"},"meta":{"httpStatus":"200 - OK","requestId":"RQ_1"}}`,
+			returnHandled:      true,
+			returnStatusCode:   200,
+			returnResponseBody: `{"result":{"guid":"PG_1"},"meta":{"httpStatus":"200 - OK","requestId":"RQ_1"}}`,
+		},
+		{
+			name:               "test route that isn't in the spec",
+			paramStrict:        true,
+			paramPath:          "/api/unknown",
+			paramBody:          `{"anything":true}`,
+			paramResponse:      `{"anything":true}`,
+			returnHandled:      true,
+			returnStatusCode:   200,
+			returnResponseBody: `{"anything":true}`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			validator := SpecValidator{
+				Spec:   spec,
+				Strict: tc.paramStrict,
+			}
+			handled := false
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				handled = true
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(tc.paramResponse))
+			})
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "http://test.com"+tc.paramPath, strings.NewReader(tc.paramBody))
+			validator.serve(next, w, r)
+			resp := w.Result()
+			require.Equal(t, tc.returnHandled, handled)
+			require.Equal(t, tc.returnStatusCode, resp.StatusCode)
+			require.Equal(t, tc.returnResponseBody, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,323 @@
+// Package config loads the service's configuration from an optional JSON file, with env vars overriding the file.
+// Every setting has a default for local development; settings without a safe default elsewhere, like secrets,
+// are required outside of the LOCAL datacenter.
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// LocalDatacenter is the datacenter used when running locally.
+const LocalDatacenter = "LOCAL"
+
+// Supported values for Store.Backend
+const (
+	StoreBackendMySQL  = "mysql"
+	StoreBackendMemory = "memory"
+)
+
+// Supported values for Tracing.Exporter
+const (
+	TraceExporterNone   = "none"
+	TraceExporterStdout = "stdout"
+	TraceExporterFile   = "file"
+	TraceExporterOTLP   = "otlp"
+)
+
+// Supported values for HTTP.SpecValidation
+const (
+	SpecValidationOff    = "off"
+	SpecValidationLog    = "log"
+	SpecValidationStrict = "strict"
+)
+
+// FileEnvKey is the env var with the path to the config file.
+const FileEnvKey = "CONFIG_FILE"
+
+// Config is every setting the service can be configured with.
+// The env tag is the env var that overrides the setting. Secret settings can also be read from the file named by
+// the env var with a _FILE suffix, and are hidden by Redacted.
+type Config struct {
+	Datacenter     string         `json:"datacenter" env:"DATACENTER"`
+	HTTP           HTTP           `json:"http"`
+	CORS           CORS           `json:"cors"`
+	Auth           Auth           `json:"auth"`
+	Store          Store          `json:"store"`
+	MySQL          MySQL          `json:"mysql"`
+	Tracing        Tracing        `json:"tracing"`
+	TrashRetention TrashRetention `json:"trashRetention"`
+	RateLimit      RateLimit      `json:"rateLimit"`
+	Webhooks       Webhooks       `json:"webhooks"`
+	Stream         Stream         `json:"stream"`
+	Events         Events         `json:"events"`
+}
+
+// HTTP configures the server.
+type HTTP struct {
+	Port              string   `json:"port" env:"PORT"`
+	StaticPath        string   `json:"staticPath" env:"STATIC_PATH"`
+	ReadTimeout       Duration `json:"readTimeout" env:"HTTP_READ_TIMEOUT"`
+	ReadHeaderTimeout Duration `json:"readHeaderTimeout" env:"HTTP_READ_HEADER_TIMEOUT"`
+	WriteTimeout      Duration `json:"writeTimeout" env:"HTTP_WRITE_TIMEOUT"`
+	IdleTimeout       Duration `json:"idleTimeout" env:"HTTP_IDLE_TIMEOUT"`
+	// RequestTimeout is how long a request may run before its store calls are canceled; 0 disables it.
+	RequestTimeout Duration `json:"requestTimeout" env:"REQUEST_TIMEOUT"`
+	// DrainDelay is how long the server reports not-ready before it shuts down.
+	DrainDelay Duration `json:"drainDelay" env:"DRAIN_DELAY"`
+	// ShutdownTimeout is how long in-flight requests get to finish once the server stops accepting new ones.
+	ShutdownTimeout Duration `json:"shutdownTimeout" env:"SHUTDOWN_TIMEOUT"`
+	// HSTSMaxAge is how long browsers should only use HTTPS for the service; 0 doesn't send the header.
+	HSTSMaxAge Duration `json:"hstsMaxAge" env:"HSTS_MAX_AGE"`
+	// SpecValidation is what happens to requests and responses that don't match the API spec: off ignores them,
+	// log logs them, and strict rejects them, which is meant for tests.
+	SpecValidation string `json:"specValidation" env:"SPEC_VALIDATION"`
+}
+
+// CORS configures which browser origins can call the API.
+type CORS struct {
+	// AllowedOrigins are the origins, such as https://app.worlve.com, that can call the API from a browser.
+	// Each can have one wildcard, such as https://*.worlve.com. CORS is off when there are none.
+	AllowedOrigins []string `json:"allowedOrigins" env:"CORS_ALLOWED_ORIGINS"`
+	// AllowCredentials lets the allowed origins send cookies and auth headers.
+	AllowCredentials bool `json:"allowCredentials" env:"CORS_ALLOW_CREDENTIALS"`
+	// MaxAge is how long browsers can cache a preflight response.
+	MaxAge Duration `json:"maxAge" env:"CORS_MAX_AGE"`
+}
+
+// Auth configures authentication.
+type Auth struct {
+	AdminAuthSecret string `json:"adminAuthSecret" env:"ADMIN_AUTH_SECRET" secret:"true"`
+	// PreviousAdminAuthSecret is still accepted while the admin secret is being rotated.
+	PreviousAdminAuthSecret string `json:"previousAdminAuthSecret" env:"ADMIN_AUTH_PREVIOUS_SECRET" secret:"true"`
+}
+
+// Store configures where the stores persist their data.
+// The memory backend loses all data on restart and is only meant for demos and offline testing.
+type Store struct {
+	Backend string `json:"backend" env:"STORE_BACKEND"`
+}
+
+// MySQL configures the connection to the MySQL db.
+// The root user is only used to create and drop databases for the mysqlstore tests.
+type MySQL struct {
+	Host         string `json:"host" env:"MYSQL_HOST"`
+	Protocol     string `json:"protocol" env:"MYSQL_PROTOCOL"`
+	Database     string `json:"database" env:"MYSQL_DATABASE"`
+	User         string `json:"user" env:"MYSQL_USER"`
+	Password     string `json:"password" env:"MYSQL_PASSWORD" secret:"true"`
+	RootUser     string `json:"rootUser" env:"MYSQL_ROOT_USER"`
+	RootPassword string `json:"rootPassword" env:"MYSQL_ROOT_PASSWORD" secret:"true"`
+	Charset      string `json:"charset" env:"MYSQL_CHARSET"`
+	// MaxOpenConns is the most connections the pool opens at once; 0 means no limit.
+	MaxOpenConns int `json:"maxOpenConns" env:"MYSQL_MAX_OPEN_CONNS"`
+}
+
+// Tracing configures where spans are sent.
+type Tracing struct {
+	Exporter     string `json:"exporter" env:"TRACE_EXPORTER"`
+	File         string `json:"file" env:"TRACE_FILE"`
+	OTLPEndpoint string `json:"otlpEndpoint" env:"OTEL_EXPORTER_OTLP_ENDPOINT"`
+}
+
+// TrashRetention configures how long removed pages are kept.
+type TrashRetention struct {
+	// Days is the number of days a removed page is kept in the trash before it is purged; 0 disables purging.
+	Days int `json:"days" env:"TRASH_RETENTION_DAYS"`
+}
+
+// Webhooks configures how webhook deliveries are sent.
+type Webhooks struct {
+	// PollInterval is how often the outbox is checked for deliveries that are due; 0 stops sending them.
+	PollInterval Duration `json:"pollInterval" env:"WEBHOOK_POLL_INTERVAL"`
+	// MaxAttempts is how many times a delivery is attempted before it's marked as failed.
+	MaxAttempts int `json:"maxAttempts" env:"WEBHOOK_MAX_ATTEMPTS"`
+}
+
+// Stream configures the event streams clients follow page changes with.
+type Stream struct {
+	// LogSize is how many recent events are kept in memory for reconnecting clients to resume from.
+	LogSize int `json:"logSize" env:"STREAM_LOG_SIZE"`
+}
+
+// Events configures the outbox the services record their changes in, and how the changes are dispatched.
+type Events struct {
+	// PollInterval is how often the outbox is checked for events added through other instances; the changes made
+	// through this instance are dispatched as soon as they're committed.
+	PollInterval Duration `json:"pollInterval" env:"EVENT_POLL_INTERVAL"`
+	// Retention is how long events are kept for subscribers to be replayed from; 0 keeps them forever.
+	Retention Duration `json:"retention" env:"EVENT_RETENTION"`
+}
+
+// RateLimit configures how many requests each client can make.
+// Every request is limited by IP before it's authenticated. After that, proxy users are limited by their user ID,
+// admins share one limit, and requests to public routes are limited by IP.
+type RateLimit struct {
+	// Default limits requests that don't match one of the Groups.
+	Default RateLimits `json:"default" env:"RATE_LIMIT"`
+	// Groups limit the requests whose path starts with their pathPrefix separately; they're only set in the file.
+	Groups []RateLimitGroup `json:"groups"`
+	// ClientIPHeader is the header with the client's IP, such as X-Forwarded-For, when the service is behind a proxy.
+	ClientIPHeader string `json:"clientIPHeader" env:"RATE_LIMIT_CLIENT_IP_HEADER"`
+	// TrustedProxies is the number of proxies in front of the service that append to the ClientIPHeader.
+	TrustedProxies int `json:"trustedProxies" env:"RATE_LIMIT_TRUSTED_PROXIES"`
+}
+
+// RateLimitGroup limits a group of routes separately from the rest.
+type RateLimitGroup struct {
+	Name       string     `json:"name"`
+	PathPrefix string     `json:"pathPrefix"`
+	Limits     RateLimits `json:"limits"`
+}
+
+// RateLimits are the limits for each kind of client.
+type RateLimits struct {
+	IP        RateLimitRule `json:"ip" env:"IP"`
+	Admin     RateLimitRule `json:"admin" env:"ADMIN"`
+	ProxyUser RateLimitRule `json:"proxyUser" env:"PROXY_USER"`
+	Public    RateLimitRule `json:"public" env:"PUBLIC"`
+}
+
+// RateLimitRule allows bursts of up to Requests, refilled at Requests per Per; 0 requests is unlimited.
+type RateLimitRule struct {
+	Requests int      `json:"requests" env:"REQUESTS"`
+	Per      Duration `json:"per" env:"PER"`
+}
+
+// IsLocal is true when running locally.
+func (c Config) IsLocal() bool {
+	return c.Datacenter == LocalDatacenter
+}
+
+// Defaults returns the default config for the datacenter.
+// Only the LOCAL datacenter has default secrets.
+func Defaults(datacenter string) Config {
+	c := Config{
+		Datacenter: datacenter,
+		HTTP: HTTP{
+			Port:              "8782",
+			StaticPath:        "../../static",
+			ReadTimeout:       Duration{10 * time.Second},
+			ReadHeaderTimeout: Duration{5 * time.Second},
+			WriteTimeout:      Duration{10 * time.Second},
+			IdleTimeout:       Duration{60 * time.Second},
+			RequestTimeout:    Duration{8 * time.Second},
+			DrainDelay:        Duration{5 * time.Second},
+			ShutdownTimeout:   Duration{20 * time.Second},
+			HSTSMaxAge:        Duration{365 * 24 * time.Hour},
+			SpecValidation:    SpecValidationLog,
+		},
+		CORS: CORS{
+			MaxAge: Duration{10 * time.Minute},
+		},
+		Store: Store{
+			Backend: StoreBackendMySQL,
+		},
+		MySQL: MySQL{
+			Host:         "127.0.0.1:3306",
+			Protocol:     "tcp",
+			Database:     "spiderweb_dev",
+			User:         "spiderweb_dev",
+			Charset:      "utf8",
+			MaxOpenConns: 25,
+		},
+		Tracing: Tracing{
+			Exporter:     TraceExporterNone,
+			File:         "traces.jsonl",
+			OTLPEndpoint: "http://localhost:4318",
+		},
+		TrashRetention: TrashRetention{
+			Days: 30,
+		},
+		Webhooks: Webhooks{
+			PollInterval: Duration{10 * time.Second},
+			MaxAttempts:  8,
+		},
+		Stream: Stream{
+			LogSize: 1000,
+		},
+		Events: Events{
+			PollInterval: Duration{time.Second},
+			Retention:    Duration{7 * 24 * time.Hour},
+		},
+		RateLimit: RateLimit{
+			Default: RateLimits{
+				IP:        RateLimitRule{Requests: 6000, Per: Duration{time.Minute}},
+				Admin:     RateLimitRule{Requests: 3000, Per: Duration{time.Minute}},
+				ProxyUser: RateLimitRule{Requests: 600, Per: Duration{time.Minute}},
+				Public:    RateLimitRule{Requests: 120, Per: Duration{time.Minute}},
+			},
+			TrustedProxies: 1,
+		},
+	}
+	if datacenter == LocalDatacenter {
+		c.HTTP.DrainDelay = Duration{}
+		c.CORS.AllowedOrigins = []string{"http://127.0.0.1:8081"}
+		c.HTTP.HSTSMaxAge = Duration{}
+		c.Auth.AdminAuthSecret = "DEFAULT_SECRET"
+		c.MySQL.Password = "password"
+		c.MySQL.RootUser = "root"
+		c.MySQL.RootPassword = "rootpassword"
+	}
+	return c
+}
+
+// Load returns the config from the JSON file at path, if there is one, with env vars overriding it.
+// It errors if the file can't be read or the config isn't valid.
+func Load(path string) (Config, error) {
+	return load(path, os.LookupEnv)
+}
+
+func load(path string, lookupEnv func(key string) (string, bool)) (Config, error) {
+	var contents []byte
+	if path != "" {
+		var err error
+		contents, err = ioutil.ReadFile(path)
+		if err != nil {
+			return Config{}, errors.Wrap(err, "unable to read config file")
+		}
+	}
+	c := Defaults(getDatacenter(contents, lookupEnv))
+	if contents != nil {
+		err := decodeFile(contents, &c)
+		if err != nil {
+			return Config{}, errors.Wrapf(err, "invalid config file %v", path)
+		}
+	}
+	err := applyEnv(&c, lookupEnv)
+	if err != nil {
+		return Config{}, err
+	}
+	err = c.Validate()
+	if err != nil {
+		return Config{}, err
+	}
+	return c, nil
+}
+
+// getDatacenter finds the datacenter before the rest of the config is loaded, since it decides the defaults.
+func getDatacenter(contents []byte, lookupEnv func(key string) (string, bool)) string {
+	if datacenter, ok := lookupEnv("DATACENTER"); ok {
+		return datacenter
+	}
+	if contents != nil {
+		var file struct {
+			Datacenter string `json:"datacenter"`
+		}
+		if json.Unmarshal(contents, &file) == nil && file.Datacenter != "" {
+			return file.Datacenter
+		}
+	}
+	return LocalDatacenter
+}
+
+func decodeFile(contents []byte, c *Config) error {
+	decoder := json.NewDecoder(bytes.NewReader(contents))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(c)
+}
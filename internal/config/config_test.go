@@ -0,0 +1,267 @@
+package config
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeTestFile(t *testing.T, dir, name, contents string) string {
+	path := filepath.Join(dir, name)
+	require.NoError(t, ioutil.WriteFile(path, []byte(contents), 0600))
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	secretPath := writeTestFile(t, dir, "secret", "FILE_SECRET\n")
+	cases := []struct {
+		name         string
+		paramFile    string
+		paramEnv     map[string]string
+		returnConfig func() Config
+		returnErr    string
+	}{
+		{
+			name: "test local defaults",
+			returnConfig: func() Config {
+				return Defaults(LocalDatacenter)
+			},
+		},
+		{
+			name:      "test file overrides defaults",
+			paramFile: `{"http": {"port": "9000", "requestTimeout": "3s"}, "trashRetention": {"days": 7}}`,
+			returnConfig: func() Config {
+				c := Defaults(LocalDatacenter)
+				c.HTTP.Port = "9000"
+				c.HTTP.RequestTimeout = Duration{3 * time.Second}
+				c.TrashRetention.Days = 7
+				return c
+			},
+		},
+		{
+			name:      "test env overrides the file",
+			paramFile: `{"http": {"port": "9000"}, "store": {"backend": "memory"}}`,
+			paramEnv: map[string]string{
+				"PORT":                 "9001",
+				"HTTP_WRITE_TIMEOUT":   "30s",
+				"MYSQL_MAX_OPEN_CONNS": "5",
+			},
+			returnConfig: func() Config {
+				c := Defaults(LocalDatacenter)
+				c.HTTP.Port = "9001"
+				c.HTTP.WriteTimeout = Duration{30 * time.Second}
+				c.Store.Backend = StoreBackendMemory
+				c.MySQL.MaxOpenConns = 5
+				return c
+			},
+		},
+		{
+			name: "test root user is separate from the service user",
+			paramEnv: map[string]string{
+				"MYSQL_USER":          "service",
+				"MYSQL_PASSWORD":      "servicepassword",
+				"MYSQL_ROOT_USER":     "admin",
+				"MYSQL_ROOT_PASSWORD": "adminpassword",
+			},
+			returnConfig: func() Config {
+				c := Defaults(LocalDatacenter)
+				c.MySQL.User = "service"
+				c.MySQL.Password = "servicepassword"
+				c.MySQL.RootUser = "admin"
+				c.MySQL.RootPassword = "adminpassword"
+				return c
+			},
+		},
+		{
+			name:      "test prod secrets from env and files",
+			paramFile: `{"datacenter": "PROD"}`,
+			paramEnv: map[string]string{
+				"ADMIN_AUTH_SECRET":   "ENV_SECRET",
+				"MYSQL_PASSWORD":      "ignored",
+				"MYSQL_PASSWORD_FILE": secretPath,
+			},
+			returnConfig: func() Config {
+				c := Defaults("PROD")
+				c.Auth.AdminAuthSecret = "ENV_SECRET"
+				c.MySQL.Password = "FILE_SECRET"
+				return c
+			},
+		},
+		{
+			name:     "test prod requires secrets",
+			paramEnv: map[string]string{"DATACENTER": "PROD"},
+			returnErr: "invalid config:\n" +
+				"  auth.adminAuthSecret (ADMIN_AUTH_SECRET or ADMIN_AUTH_SECRET_FILE) is required when datacenter is PROD\n" +
+				"  mysql.password (MYSQL_PASSWORD or MYSQL_PASSWORD_FILE) is required when datacenter is PROD",
+		},
+		{
+			name: "test prod memory backend doesn't require the mysql password",
+			paramEnv: map[string]string{
+				"DATACENTER":        "PROD",
+				"STORE_BACKEND":     "memory",
+				"ADMIN_AUTH_SECRET": "SECRET",
+			},
+			returnConfig: func() Config {
+				c := Defaults("PROD")
+				c.Store.Backend = StoreBackendMemory
+				c.Auth.AdminAuthSecret = "SECRET"
+				return c
+			},
+		},
+		{
+			name: "test invalid values",
+			paramEnv: map[string]string{
+				"STORE_BACKEND":        "postgres",
+				"TRACE_EXPORTER":       "zipkin",
+				"DRAIN_DELAY":          "-1s",
+				"TRASH_RETENTION_DAYS": "-1",
+				"WEBHOOK_MAX_ATTEMPTS": "0",
+				"STREAM_LOG_SIZE":      "0",
+				"EVENT_POLL_INTERVAL":  "0s",
+				"EVENT_RETENTION":      "-1h",
+			},
+			returnErr: "invalid config:\n" +
+				"  store.backend (STORE_BACKEND) is \"postgres\" but must be one of: mysql, memory\n" +
+				"  tracing.exporter (TRACE_EXPORTER) is \"zipkin\" but must be one of: none, stdout, file, otlp\n" +
+				"  http.drainDelay (DRAIN_DELAY) can't be negative\n" +
+				"  events.retention (EVENT_RETENTION) can't be negative\n" +
+				"  trashRetention.days (TRASH_RETENTION_DAYS) can't be negative\n" +
+				"  webhooks.maxAttempts (WEBHOOK_MAX_ATTEMPTS) must be at least 1\n" +
+				"  stream.logSize (STREAM_LOG_SIZE) must be at least 1\n" +
+				"  events.pollInterval (EVENT_POLL_INTERVAL) must be more than 0",
+		},
+		{
+			name:      "test cors for a hosted front end",
+			paramFile: `{"datacenter": "PROD", "cors": {"allowedOrigins": ["https://app.worlve.com"]}}`,
+			paramEnv: map[string]string{
+				"CORS_ALLOWED_ORIGINS":   "https://app.worlve.com, https://*.worlve.com",
+				"CORS_ALLOW_CREDENTIALS": "true",
+				"ADMIN_AUTH_SECRET":      "SECRET",
+				"MYSQL_PASSWORD":         "PASSWORD",
+			},
+			returnConfig: func() Config {
+				c := Defaults("PROD")
+				c.CORS.AllowedOrigins = []string{"https://app.worlve.com", "https://*.worlve.com"}
+				c.CORS.AllowCredentials = true
+				c.Auth.AdminAuthSecret = "SECRET"
+				c.MySQL.Password = "PASSWORD"
+				return c
+			},
+		},
+		{
+			name: "test invalid cors",
+			paramEnv: map[string]string{
+				"CORS_ALLOWED_ORIGINS":   "*,app.worlve.com,https://*.*.worlve.com",
+				"CORS_ALLOW_CREDENTIALS": "true",
+			},
+			returnErr: "invalid config:\n" +
+				"  cors.allowedOrigins (CORS_ALLOWED_ORIGINS) can't allow every origin when cors.allowCredentials (CORS_ALLOW_CREDENTIALS) is set\n" +
+				"  cors.allowedOrigins (CORS_ALLOWED_ORIGINS) has \"app.worlve.com\" but origins must start with http:// or https://\n" +
+				"  cors.allowedOrigins (CORS_ALLOWED_ORIGINS) has \"https://*.*.worlve.com\" but origins can only have one wildcard",
+		},
+		{
+			name:      "test rate limits from the file and env",
+			paramFile: `{"rateLimit": {"groups": [{"name": "search", "pathPrefix": "/api/search", "limits": {"proxyUser": {"requests": 10, "per": "1s"}}}]}}`,
+			paramEnv: map[string]string{
+				"RATE_LIMIT_PUBLIC_REQUESTS":  "5",
+				"RATE_LIMIT_PUBLIC_PER":       "1s",
+				"RATE_LIMIT_CLIENT_IP_HEADER": "X-Forwarded-For",
+				"RATE_LIMIT_TRUSTED_PROXIES":  "2",
+				"RATE_LIMIT_IP_REQUESTS":      "50",
+			},
+			returnConfig: func() Config {
+				c := Defaults(LocalDatacenter)
+				c.RateLimit.Default.Public = RateLimitRule{Requests: 5, Per: Duration{time.Second}}
+				c.RateLimit.Default.IP.Requests = 50
+				c.RateLimit.Groups = []RateLimitGroup{
+					{
+						Name:       "search",
+						PathPrefix: "/api/search",
+						Limits: RateLimits{
+							ProxyUser: RateLimitRule{Requests: 10, Per: Duration{time.Second}},
+						},
+					},
+				}
+				c.RateLimit.ClientIPHeader = "X-Forwarded-For"
+				c.RateLimit.TrustedProxies = 2
+				return c
+			},
+		},
+		{
+			name:      "test invalid rate limits",
+			paramFile: `{"rateLimit": {"groups": [{"limits": {"admin": {"requests": -1}}}]}}`,
+			paramEnv: map[string]string{
+				"RATE_LIMIT_PROXY_USER_PER":  "0s",
+				"RATE_LIMIT_TRUSTED_PROXIES": "0",
+			},
+			returnErr: "invalid config:\n" +
+				"  rateLimit.default.proxyUser.per (RATE_LIMIT_PROXY_USER_PER) must be positive when rateLimit.default.proxyUser.requests (RATE_LIMIT_PROXY_USER_REQUESTS) is set\n" +
+				"  rateLimit.trustedProxies (RATE_LIMIT_TRUSTED_PROXIES) must be at least 1\n" +
+				"  rateLimit.groups[0].name is required\n" +
+				"  rateLimit.groups[0].pathPrefix is required\n" +
+				"  rateLimit.groups[0].limits.admin.requests can't be negative",
+		},
+		{
+			name:      "test env that can't be parsed",
+			paramEnv:  map[string]string{"REQUEST_TIMEOUT": "soon"},
+			returnErr: "env var \"REQUEST_TIMEOUT\" is not a valid http.requestTimeout: time: invalid duration \"soon\"",
+		},
+		{
+			name:      "test unknown setting in the file",
+			paramFile: `{"http": {"prot": "9000"}}`,
+			returnErr: "json: unknown field \"prot\"",
+		},
+		{
+			name:      "test duration that isn't a string in the file",
+			paramFile: `{"http": {"readTimeout": 10}}`,
+			returnErr: "duration must be a string such as \"10s\": 10",
+		},
+		{
+			name:      "test missing secret file",
+			paramEnv:  map[string]string{"ADMIN_AUTH_SECRET_FILE": filepath.Join(dir, "missing")},
+			returnErr: "unable to read auth.adminAuthSecret from ADMIN_AUTH_SECRET_FILE",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := ""
+			if tc.paramFile != "" {
+				path = writeTestFile(t, dir, "config.json", tc.paramFile)
+			}
+			c, err := load(path, func(key string) (string, bool) {
+				value, ok := tc.paramEnv[key]
+				return value, ok
+			})
+			if tc.returnErr != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.returnErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.returnConfig(), c)
+		})
+	}
+}
+
+func TestRedacted(t *testing.T) {
+	c := Defaults("PROD")
+	c.Auth.AdminAuthSecret = "SECRET"
+	c.MySQL.Password = "PASSWORD"
+	redactedConfig := c.Redacted()
+	require.Equal(t, "[REDACTED]", redactedConfig.Auth.AdminAuthSecret)
+	require.Equal(t, "[REDACTED]", redactedConfig.MySQL.Password)
+	require.Equal(t, "", redactedConfig.MySQL.RootPassword)
+	require.Equal(t, "SECRET", c.Auth.AdminAuthSecret)
+	b, err := json.Marshal(redactedConfig)
+	require.NoError(t, err)
+	require.NotContains(t, string(b), "PASSWORD")
+	require.Contains(t, string(b), `"readTimeout":"10s"`)
+}
@@ -0,0 +1,162 @@
+package config
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// redacted replaces secret values when the config is printed.
+const redacted = "[REDACTED]"
+
+// secretFileSuffix is added to a secret's env var to name the env var with the path of a file containing the secret.
+const secretFileSuffix = "_FILE"
+
+// Duration is a time.Duration written in the config file as a string, such as "10s".
+type Duration struct {
+	time.Duration
+}
+
+// MarshalJSON writes the duration as a string.
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON reads the duration from a string.
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	err := json.Unmarshal(b, &s)
+	if err != nil {
+		return errors.Errorf("duration must be a string such as \"10s\": %s", b)
+	}
+	return d.set(s)
+}
+
+func (d *Duration) set(s string) error {
+	duration, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	d.Duration = duration
+	return nil
+}
+
+var durationType = reflect.TypeOf(Duration{})
+
+// field is a single setting in the config.
+type field struct {
+	value  reflect.Value
+	path   string
+	envKey string
+	secret bool
+}
+
+// fields lists every setting in the config, in the order they're declared.
+// A struct with an env tag prefixes the env vars of its settings, such as RATE_LIMIT_ADMIN_REQUESTS.
+func fields(v reflect.Value, path, envPrefix string) []field {
+	var result []field
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		structField := t.Field(i)
+		name := strings.Split(structField.Tag.Get("json"), ",")[0]
+		if path != "" {
+			name = path + "." + name
+		}
+		value := v.Field(i)
+		envKey := structField.Tag.Get("env")
+		if envKey != "" && envPrefix != "" {
+			envKey = envPrefix + "_" + envKey
+		}
+		if structField.Type.Kind() == reflect.Struct && structField.Type != durationType {
+			result = append(result, fields(value, name, envKey)...)
+			continue
+		}
+		result = append(result, field{
+			value:  value,
+			path:   name,
+			envKey: envKey,
+			secret: structField.Tag.Get("secret") == "true",
+		})
+	}
+	return result
+}
+
+// applyEnv overrides each setting with its env var, or for secrets with the contents of the file named by its _FILE env var.
+func applyEnv(c *Config, lookupEnv func(key string) (string, bool)) error {
+	for _, f := range fields(reflect.ValueOf(c).Elem(), "", "") {
+		if f.envKey == "" {
+			continue
+		}
+		if f.secret {
+			if path, ok := lookupEnv(f.envKey + secretFileSuffix); ok {
+				contents, err := ioutil.ReadFile(path)
+				if err != nil {
+					return errors.Wrapf(err, "unable to read %v from %v", f.path, f.envKey+secretFileSuffix)
+				}
+				f.value.SetString(strings.TrimRight(string(contents), "\r\n"))
+				continue
+			}
+		}
+		value, ok := lookupEnv(f.envKey)
+		if !ok {
+			continue
+		}
+		err := f.set(value)
+		if err != nil {
+			return errors.Wrapf(err, "env var \"%v\" is not a valid %v", f.envKey, f.path)
+		}
+	}
+	return nil
+}
+
+func (f field) set(value string) error {
+	if f.value.Type() == durationType {
+		return f.value.Addr().Interface().(*Duration).set(value)
+	}
+	switch f.value.Kind() {
+	case reflect.String:
+		f.value.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		f.value.SetBool(b)
+	case reflect.Slice:
+		if f.value.Type().Elem().Kind() != reflect.String {
+			return errors.Errorf("unsupported setting type %v", f.value.Type())
+		}
+		// lists are comma separated, such as "https://a.worlve.com,https://b.worlve.com"
+		var list []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		f.value.Set(reflect.ValueOf(list))
+	case reflect.Int:
+		i, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		f.value.SetInt(int64(i))
+	default:
+		return errors.Errorf("unsupported setting type %v", f.value.Type())
+	}
+	return nil
+}
+
+// Redacted returns a copy of the config with every secret that's set hidden, so it's safe to print.
+func (c Config) Redacted() Config {
+	for _, f := range fields(reflect.ValueOf(&c).Elem(), "", "") {
+		if f.secret && f.value.String() != "" {
+			f.value.SetString(redacted)
+		}
+	}
+	return c
+}
@@ -0,0 +1,159 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Validate returns an error listing every invalid or missing setting.
+func (c Config) Validate() error {
+	var problems []string
+	if c.Datacenter == "" {
+		problems = append(problems, "datacenter (DATACENTER) is required")
+	}
+	if c.HTTP.Port == "" {
+		problems = append(problems, "http.port (PORT) is required")
+	}
+	if !c.IsLocal() {
+		if c.Auth.AdminAuthSecret == "" {
+			problems = append(problems, requiredSecret("auth.adminAuthSecret", "ADMIN_AUTH_SECRET", c.Datacenter))
+		}
+		if c.Store.Backend == StoreBackendMySQL && c.MySQL.Password == "" {
+			problems = append(problems, requiredSecret("mysql.password", "MYSQL_PASSWORD", c.Datacenter))
+		}
+	}
+	problems = append(problems, oneOf("store.backend", "STORE_BACKEND", c.Store.Backend, StoreBackendMySQL, StoreBackendMemory)...)
+	problems = append(problems, oneOf("http.specValidation", "SPEC_VALIDATION", c.HTTP.SpecValidation, SpecValidationOff, SpecValidationLog, SpecValidationStrict)...)
+	problems = append(problems, oneOf("tracing.exporter", "TRACE_EXPORTER", c.Tracing.Exporter, TraceExporterNone, TraceExporterStdout, TraceExporterFile, TraceExporterOTLP)...)
+	durations := []struct {
+		path     string
+		envKey   string
+		duration Duration
+	}{
+		{"http.readTimeout", "HTTP_READ_TIMEOUT", c.HTTP.ReadTimeout},
+		{"http.readHeaderTimeout", "HTTP_READ_HEADER_TIMEOUT", c.HTTP.ReadHeaderTimeout},
+		{"http.writeTimeout", "HTTP_WRITE_TIMEOUT", c.HTTP.WriteTimeout},
+		{"http.idleTimeout", "HTTP_IDLE_TIMEOUT", c.HTTP.IdleTimeout},
+		{"http.requestTimeout", "REQUEST_TIMEOUT", c.HTTP.RequestTimeout},
+		{"http.drainDelay", "DRAIN_DELAY", c.HTTP.DrainDelay},
+		{"http.shutdownTimeout", "SHUTDOWN_TIMEOUT", c.HTTP.ShutdownTimeout},
+		{"http.hstsMaxAge", "HSTS_MAX_AGE", c.HTTP.HSTSMaxAge},
+		{"cors.maxAge", "CORS_MAX_AGE", c.CORS.MaxAge},
+		{"webhooks.pollInterval", "WEBHOOK_POLL_INTERVAL", c.Webhooks.PollInterval},
+		{"events.retention", "EVENT_RETENTION", c.Events.Retention},
+	}
+	for _, d := range durations {
+		if d.duration.Duration < 0 {
+			problems = append(problems, fmt.Sprintf("%v (%v) can't be negative", d.path, d.envKey))
+		}
+	}
+	if c.MySQL.MaxOpenConns < 0 {
+		problems = append(problems, "mysql.maxOpenConns (MYSQL_MAX_OPEN_CONNS) can't be negative")
+	}
+	if c.TrashRetention.Days < 0 {
+		problems = append(problems, "trashRetention.days (TRASH_RETENTION_DAYS) can't be negative")
+	}
+	if c.Webhooks.MaxAttempts < 1 {
+		problems = append(problems, "webhooks.maxAttempts (WEBHOOK_MAX_ATTEMPTS) must be at least 1")
+	}
+	if c.Stream.LogSize < 1 {
+		problems = append(problems, "stream.logSize (STREAM_LOG_SIZE) must be at least 1")
+	}
+	if c.Events.PollInterval.Duration <= 0 {
+		problems = append(problems, "events.pollInterval (EVENT_POLL_INTERVAL) must be more than 0")
+	}
+	problems = append(problems, c.CORS.validate()...)
+	problems = append(problems, c.RateLimit.validate()...)
+	if len(problems) > 0 {
+		return errors.Errorf("invalid config:\n  %v", strings.Join(problems, "\n  "))
+	}
+	return nil
+}
+
+func requiredSecret(path, envKey, datacenter string) string {
+	return fmt.Sprintf("%v (%v or %v%v) is required when datacenter is %v", path, envKey, envKey, secretFileSuffix, datacenter)
+}
+
+func oneOf(path, envKey, value string, allowed ...string) []string {
+	for _, a := range allowed {
+		if value == a {
+			return nil
+		}
+	}
+	return []string{fmt.Sprintf("%v (%v) is \"%v\" but must be one of: %v", path, envKey, value, strings.Join(allowed, ", "))}
+}
+
+func (c CORS) validate() []string {
+	var problems []string
+	for _, origin := range c.AllowedOrigins {
+		if origin == "*" {
+			if c.AllowCredentials {
+				problems = append(problems, "cors.allowedOrigins (CORS_ALLOWED_ORIGINS) can't allow every origin when cors.allowCredentials (CORS_ALLOW_CREDENTIALS) is set")
+			}
+			continue
+		}
+		if !strings.HasPrefix(origin, "http://") && !strings.HasPrefix(origin, "https://") {
+			problems = append(problems, fmt.Sprintf("cors.allowedOrigins (CORS_ALLOWED_ORIGINS) has \"%v\" but origins must start with http:// or https://", origin))
+		}
+		if strings.Count(origin, "*") > 1 {
+			problems = append(problems, fmt.Sprintf("cors.allowedOrigins (CORS_ALLOWED_ORIGINS) has \"%v\" but origins can only have one wildcard", origin))
+		}
+	}
+	return problems
+}
+
+func (rl RateLimit) validate() []string {
+	problems := rl.Default.validate("rateLimit.default", "RATE_LIMIT")
+	if rl.TrustedProxies < 1 {
+		problems = append(problems, "rateLimit.trustedProxies (RATE_LIMIT_TRUSTED_PROXIES) must be at least 1")
+	}
+	for i, g := range rl.Groups {
+		path := fmt.Sprintf("rateLimit.groups[%v]", i)
+		if g.Name == "" {
+			problems = append(problems, path+".name is required")
+		}
+		if g.PathPrefix == "" {
+			problems = append(problems, path+".pathPrefix is required")
+		}
+		problems = append(problems, g.Limits.validate(path+".limits", "")...)
+	}
+	return problems
+}
+
+func (l RateLimits) validate(path, envPrefix string) []string {
+	var problems []string
+	rules := []struct {
+		name   string
+		envKey string
+		rule   RateLimitRule
+	}{
+		{"ip", "IP", l.IP},
+		{"admin", "ADMIN", l.Admin},
+		{"proxyUser", "PROXY_USER", l.ProxyUser},
+		{"public", "PUBLIC", l.Public},
+	}
+	for _, r := range rules {
+		requests, per := setting(path+"."+r.name+".requests", ""), setting(path+"."+r.name+".per", "")
+		if envPrefix != "" {
+			envKey := envPrefix + "_" + r.envKey
+			requests, per = setting(path+"."+r.name+".requests", envKey+"_REQUESTS"), setting(path+"."+r.name+".per", envKey+"_PER")
+		}
+		if r.rule.Requests < 0 {
+			problems = append(problems, requests+" can't be negative")
+		}
+		if r.rule.Requests > 0 && r.rule.Per.Duration <= 0 {
+			problems = append(problems, per+" must be positive when "+requests+" is set")
+		}
+	}
+	return problems
+}
+
+// setting names a setting by its path in the file and its env var, when it has one.
+func setting(path, envKey string) string {
+	if envKey == "" {
+		return path
+	}
+	return fmt.Sprintf("%v (%v)", path, envKey)
+}
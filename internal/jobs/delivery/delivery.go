@@ -0,0 +1,71 @@
+package delivery
+
+import (
+	"context"
+	"time"
+
+	webhookservice "github.com/worlve/sp-service/internal/services/webhook"
+	"github.com/worlve/sp-service/internal/util/logger"
+	"go.uber.org/zap"
+)
+
+// batchSize is the most deliveries attempted in a single call to the service.
+const batchSize = 50
+
+// WebhookService see Service for more details
+type WebhookService interface {
+	DeliverDue(ctx context.Context, params webhookservice.DeliverDueParams) (int, error)
+}
+
+// Job sends the webhook deliveries that are due from the outbox.
+type Job struct {
+	WebhookService WebhookService
+	Interval       time.Duration
+	// Logger is used for the job's logs and put on the context of each run; nothing is logged when it's nil.
+	Logger *zap.Logger
+}
+
+// Run sends due deliveries immediately and then every Interval until ctx is done.
+func (j Job) Run(ctx context.Context) {
+	l := j.Logger
+	if l == nil {
+		l = zap.NewNop()
+	}
+	l = l.With(zap.String("job", "webhookDelivery"))
+	ctx = logger.SetOnContext(ctx, l)
+	ticker := time.NewTicker(j.Interval)
+	defer ticker.Stop()
+	for {
+		count, err := j.RunOnce(ctx)
+		if err != nil {
+			l.Error("Webhook delivery job failed",
+				zap.String("err", err.Error()),
+			)
+		} else if count > 0 {
+			l.Info("Webhook delivery job delivered events",
+				zap.Int("count", count),
+			)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce sends due deliveries a batch at a time until a batch comes back with nothing delivered,
+// and returns the number delivered.
+func (j Job) RunOnce(ctx context.Context) (int, error) {
+	total := 0
+	for ctx.Err() == nil {
+		count, err := j.WebhookService.DeliverDue(ctx, webhookservice.DeliverDueParams{
+			Limit: batchSize,
+		})
+		total += count
+		if err != nil || count == 0 {
+			return total, err
+		}
+	}
+	return total, nil
+}
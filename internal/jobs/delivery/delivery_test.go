@@ -0,0 +1,69 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/worlve/sp-service/internal/jobs/delivery/mocks"
+	webhookservice "github.com/worlve/sp-service/internal/services/webhook"
+	"github.com/worlve/sp-service/internal/util/testutils"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type deliverDueCall struct {
+	returnCount int
+	returnErr   error
+}
+
+func TestRunOnce(t *testing.T) {
+	cases := []struct {
+		name            string
+		deliverDueCalls []deliverDueCall
+		returnCount     int
+		returnErr       error
+	}{
+		{
+			name: "test keeps going until nothing is delivered",
+			deliverDueCalls: []deliverDueCall{
+				{returnCount: batchSize},
+				{returnCount: 3},
+				{returnCount: 0},
+			},
+			returnCount: batchSize + 3,
+		},
+		{
+			name: "test nothing due",
+			deliverDueCalls: []deliverDueCall{
+				{returnCount: 0},
+			},
+		},
+		{
+			name: "test failed delivery",
+			deliverDueCalls: []deliverDueCall{
+				{returnCount: 2},
+				{returnCount: 1, returnErr: errors.New("failed to update delivery")},
+			},
+			returnErr: errors.New("failed to update delivery"),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			webhookService := new(mocks.WebhookService)
+			for index := range tc.deliverDueCalls {
+				webhookService.On("DeliverDue", mock.Anything, webhookservice.DeliverDueParams{Limit: batchSize}).Return(tc.deliverDueCalls[index].returnCount, tc.deliverDueCalls[index].returnErr).Once()
+			}
+			job := Job{
+				WebhookService: webhookService,
+			}
+			count, err := job.RunOnce(context.Background())
+			webhookService.AssertNumberOfCalls(t, "DeliverDue", len(tc.deliverDueCalls))
+			errExpected := testutils.TestErrorAgainstCase(t, err, tc.returnErr)
+			if errExpected {
+				return
+			}
+			require.Equal(t, tc.returnCount, count)
+		})
+	}
+}
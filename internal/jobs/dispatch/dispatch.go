@@ -0,0 +1,345 @@
+package dispatch
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/worlve/sp-service/internal/models/event"
+	eventservice "github.com/worlve/sp-service/internal/services/event"
+	"github.com/worlve/sp-service/internal/stores/store"
+	"github.com/worlve/sp-service/internal/util/clock"
+	"github.com/worlve/sp-service/internal/util/logger"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+const (
+	// batchSize is the most events read from the outbox at a time.
+	batchSize = 100
+	// commitWindow is how long a missing offset is waited for. Offsets are assigned when an event is written but
+	// the event only shows up once its transaction commits, so a later event can be read before an earlier one.
+	// Once the window has passed the missing offset is taken to be from a transaction that was rolled back.
+	commitWindow = time.Minute
+	// purgeInterval is how often events older than the Retention are purged.
+	purgeInterval = time.Hour
+	// subscriberLease is how long an instance has a durable subscriber to itself once it's claimed it.
+	subscriberLease = 30 * time.Second
+)
+
+// EventService see Service for more details
+type EventService interface {
+	GetEvents(ctx context.Context, params eventservice.GetEventsParams) ([]event.Event, error)
+	StartSubscriber(ctx context.Context, params eventservice.StartSubscriberParams) (int64, error)
+	ClaimSubscriber(ctx context.Context, params eventservice.ClaimSubscriberParams) (bool, error)
+	GetSubscriberOffset(ctx context.Context, params eventservice.GetSubscriberOffsetParams) (int64, error)
+	AdvanceSubscriber(ctx context.Context, params eventservice.AdvanceSubscriberParams) (bool, error)
+	ReplaySubscriber(ctx context.Context, params eventservice.ReplaySubscriberParams) error
+	PurgeEvents(ctx context.Context, params eventservice.PurgeEventsParams) error
+}
+
+// Handler handles a single event. When it returns an error the subscriber stops, and gets the event again on the next run.
+type Handler func(ctx context.Context, e event.Event) error
+
+// Subscriber is something that's told about every event in the outbox.
+type Subscriber struct {
+	Name    string
+	Handler Handler
+	// Durable subscribers keep their offset in the store, so they pick up where they left off after a restart and
+	// are shared by every instance of the service. Other subscribers keep their offset in memory and start after
+	// the From offset, for when every instance needs every event, such as to push them to its own clients.
+	Durable bool
+	From    int64
+}
+
+type subscription struct {
+	Subscriber
+	// offset is the last event handled by a subscriber that isn't Durable.
+	offset int64
+	// leaseUntil is when this instance's claim on a Durable subscriber ends.
+	leaseUntil time.Time
+}
+
+// Dispatcher delivers the events in the outbox to its subscribers, in order and at least once: a subscriber that
+// fails on an event, or whose offset isn't saved after handling it, gets the event again.
+// Each instance of the service runs one. A durable subscriber is claimed by one instance at a time, while every
+// instance hands every event to the subscribers that aren't durable.
+type Dispatcher struct {
+	EventService EventService
+	Interval     time.Duration
+	// Retention is how long events are kept for subscribers to be replayed from; they're kept forever when it's 0.
+	Retention time.Duration
+	Clock     clock.Clock
+	// Logger is used for the dispatcher's logs and put on the context of each run; nothing is logged when it's nil.
+	Logger *zap.Logger
+
+	mu            sync.Mutex
+	subscriptions []*subscription
+	wake          chan struct{}
+	lastPurge     time.Time
+}
+
+// Subscribe adds a subscriber, which gets the events from the next run on.
+func (d *Dispatcher) Subscribe(ctx context.Context, s Subscriber) error {
+	if s.Name == "" || s.Handler == nil {
+		return errors.New("must provide the subscriber's Name and Handler")
+	}
+	d.mu.Lock()
+	for _, sub := range d.subscriptions {
+		if sub.Name == s.Name {
+			d.mu.Unlock()
+			return errors.Errorf("subscriber %v is already subscribed", s.Name)
+		}
+	}
+	d.mu.Unlock()
+	if s.Durable {
+		_, err := d.EventService.StartSubscriber(ctx, eventservice.StartSubscriberParams{
+			Subscriber: s.Name,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.subscriptions = append(d.subscriptions, &subscription{
+		Subscriber: s,
+		offset:     s.From,
+	})
+	return nil
+}
+
+// Replay moves the subscriber back to the given offset, so it gets every event after it again.
+func (d *Dispatcher) Replay(ctx context.Context, name string, offset int64) error {
+	sub, ok := d.getSubscription(name)
+	if !ok {
+		return errors.Errorf("subscriber %v isn't subscribed", name)
+	}
+	if sub.Durable {
+		err := d.EventService.ReplaySubscriber(ctx, eventservice.ReplaySubscriberParams{
+			Subscriber: name,
+			Offset:     offset,
+		})
+		if err != nil {
+			return err
+		}
+	} else {
+		if offset < 0 {
+			return errors.New("offset must not be negative")
+		}
+		d.mu.Lock()
+		sub.offset = offset
+		d.mu.Unlock()
+	}
+	d.Notify()
+	return nil
+}
+
+// Notify wakes the dispatcher to run now rather than at the next Interval, such as when an event was just added.
+func (d *Dispatcher) Notify() {
+	select {
+	case d.getWake() <- struct{}{}:
+	default:
+	}
+}
+
+// Run delivers events immediately, then every Interval or whenever it's notified, until ctx is done.
+func (d *Dispatcher) Run(ctx context.Context) {
+	l := d.Logger
+	if l == nil {
+		l = zap.NewNop()
+	}
+	l = l.With(zap.String("job", "eventDispatch"))
+	ctx = logger.SetOnContext(ctx, l)
+	ticker := time.NewTicker(d.Interval)
+	defer ticker.Stop()
+	wake := d.getWake()
+	for {
+		_, err := d.RunOnce(ctx)
+		if err != nil {
+			l.Error("Event dispatch failed",
+				zap.String("err", err.Error()),
+			)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-wake:
+		}
+	}
+}
+
+// RunOnce hands each subscriber the events it hasn't handled yet, and returns the number handled.
+// A subscriber that fails doesn't hold up the others; the first failure is returned.
+func (d *Dispatcher) RunOnce(ctx context.Context) (int, error) {
+	returnErr := d.purge(ctx)
+	d.mu.Lock()
+	subscriptions := append([]*subscription(nil), d.subscriptions...)
+	d.mu.Unlock()
+	total := 0
+	for _, sub := range subscriptions {
+		count, err := d.dispatch(ctx, sub)
+		total += count
+		if err != nil && returnErr == nil {
+			returnErr = err
+		}
+	}
+	return total, returnErr
+}
+
+// dispatch hands the subscriber the events after its offset, in order, until it's caught up or one fails.
+func (d *Dispatcher) dispatch(ctx context.Context, sub *subscription) (int, error) {
+	claimed, err := d.claim(ctx, sub)
+	if err != nil || !claimed {
+		return 0, errors.Wrapf(err, "failed to claim subscriber %v", sub.Name)
+	}
+	total := 0
+	for ctx.Err() == nil {
+		offset, err := d.getOffset(ctx, sub)
+		if err != nil {
+			return total, errors.Wrapf(err, "failed to get the offset of subscriber %v", sub.Name)
+		}
+		events, err := d.EventService.GetEvents(ctx, eventservice.GetEventsParams{
+			AfterOffset: offset,
+			Limit:       batchSize,
+		})
+		if err != nil {
+			return total, err
+		}
+		for _, e := range events {
+			if sub.Durable && !d.Clock.Now().Before(sub.leaseUntil) {
+				// another instance may have claimed it; it's claimed again on the next run
+				return total, nil
+			}
+			if e.Offset != offset+1 && e.CreatedAt != nil && d.Clock.Now().Sub(*e.CreatedAt) < commitWindow {
+				// the missing events may still be committing
+				return total, nil
+			}
+			err = sub.Handler(ctx, e)
+			if err != nil {
+				return total, errors.Wrapf(err, "subscriber %v failed to handle event %v", sub.Name, e.Offset)
+			}
+			advanced, err := d.advance(ctx, sub, offset, e.Offset)
+			if err != nil {
+				return total, errors.Wrapf(err, "failed to advance subscriber %v", sub.Name)
+			}
+			total++
+			if !advanced {
+				// it was replayed, or another instance got here first; start again from where it is now
+				break
+			}
+			offset = e.Offset
+		}
+		if len(events) < batchSize {
+			return total, nil
+		}
+	}
+	return total, nil
+}
+
+// claim returns whether this instance has the subscriber to itself, claiming it if it doesn't already.
+// Subscribers that aren't durable are always this instance's.
+func (d *Dispatcher) claim(ctx context.Context, sub *subscription) (bool, error) {
+	if !sub.Durable {
+		return true, nil
+	}
+	now := d.Clock.Now()
+	if now.Before(sub.leaseUntil) {
+		return true, nil
+	}
+	leaseUntil := now.Add(subscriberLease)
+	claimed, err := d.EventService.ClaimSubscriber(ctx, eventservice.ClaimSubscriberParams{
+		Subscriber: sub.Name,
+		LeaseUntil: leaseUntil,
+	})
+	if err != nil || !claimed {
+		return false, err
+	}
+	sub.leaseUntil = leaseUntil
+	return true, nil
+}
+
+func (d *Dispatcher) getOffset(ctx context.Context, sub *subscription) (int64, error) {
+	if sub.Durable {
+		return d.EventService.GetSubscriberOffset(ctx, eventservice.GetSubscriberOffsetParams{
+			Subscriber: sub.Name,
+		})
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return sub.offset, nil
+}
+
+func (d *Dispatcher) advance(ctx context.Context, sub *subscription, from, to int64) (bool, error) {
+	if sub.Durable {
+		return d.EventService.AdvanceSubscriber(ctx, eventservice.AdvanceSubscriberParams{
+			Subscriber: sub.Name,
+			From:       from,
+			To:         to,
+		})
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if sub.offset != from {
+		return false, nil
+	}
+	sub.offset = to
+	return true, nil
+}
+
+// purge deletes the events older than the Retention, at most once every purgeInterval.
+func (d *Dispatcher) purge(ctx context.Context) error {
+	if d.Retention <= 0 {
+		return nil
+	}
+	now := d.Clock.Now()
+	d.mu.Lock()
+	due := now.Sub(d.lastPurge) >= purgeInterval
+	if due {
+		d.lastPurge = now
+	}
+	d.mu.Unlock()
+	if !due {
+		return nil
+	}
+	return d.EventService.PurgeEvents(ctx, eventservice.PurgeEventsParams{
+		CreatedBefore: now.Add(-d.Retention),
+	})
+}
+
+func (d *Dispatcher) getSubscription(name string) (*subscription, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, sub := range d.subscriptions {
+		if sub.Name == name {
+			return sub, true
+		}
+	}
+	return nil, false
+}
+
+func (d *Dispatcher) getWake() chan struct{} {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.wake == nil {
+		d.wake = make(chan struct{}, 1)
+	}
+	return d.wake
+}
+
+// UnitOfWork is a store.UnitOfWork that notifies the Dispatcher whenever a unit of work is committed, so the
+// events written within it are delivered straight away rather than at the next Interval.
+type UnitOfWork struct {
+	store.UnitOfWork
+	Dispatcher *Dispatcher
+}
+
+// Do see store.UnitOfWork.Do
+func (u UnitOfWork) Do(ctx context.Context, fn func(stores store.Stores) error) error {
+	err := u.UnitOfWork.Do(ctx, fn)
+	if err == nil {
+		u.Dispatcher.Notify()
+	}
+	return err
+}
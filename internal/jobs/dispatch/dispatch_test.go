@@ -0,0 +1,215 @@
+package dispatch
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/worlve/sp-service/internal/jobs/dispatch/mocks"
+	"github.com/worlve/sp-service/internal/models/event"
+	eventservice "github.com/worlve/sp-service/internal/services/event"
+	"github.com/worlve/sp-service/internal/stores/store"
+	storemocks "github.com/worlve/sp-service/internal/stores/store/mocks"
+	"github.com/worlve/sp-service/internal/util/clock"
+	"github.com/worlve/sp-service/internal/util/testutils"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var now = time.Date(2020, 3, 31, 12, 0, 0, 0, time.UTC)
+
+func getEvent(offset int64, createdAt time.Time) event.Event {
+	return event.Event{Offset: offset, Type: event.TypePageRemoved, CreatedAt: &createdAt}
+}
+
+// newEventService returns an EventService over the given events that keeps durable offsets in offsets.
+func newEventService(events []event.Event, offsets map[string]int64, claimed bool) *mocks.EventService {
+	eventService := new(mocks.EventService)
+	eventService.On("GetEvents", mock.Anything, mock.Anything).Return(func(ctx context.Context, params eventservice.GetEventsParams) []event.Event {
+		var after []event.Event
+		for _, e := range events {
+			if e.Offset > params.AfterOffset && len(after) < params.Limit {
+				after = append(after, e)
+			}
+		}
+		return after
+	}, nil)
+	eventService.On("StartSubscriber", mock.Anything, mock.Anything).Return(int64(0), nil)
+	eventService.On("ClaimSubscriber", mock.Anything, mock.Anything).Return(claimed, nil)
+	eventService.On("GetSubscriberOffset", mock.Anything, mock.Anything).Return(func(ctx context.Context, params eventservice.GetSubscriberOffsetParams) int64 {
+		return offsets[params.Subscriber]
+	}, nil)
+	eventService.On("AdvanceSubscriber", mock.Anything, mock.Anything).Return(func(ctx context.Context, params eventservice.AdvanceSubscriberParams) bool {
+		if offsets[params.Subscriber] != params.From {
+			return false
+		}
+		offsets[params.Subscriber] = params.To
+		return true
+	}, nil)
+	eventService.On("ReplaySubscriber", mock.Anything, mock.Anything).Return(func(ctx context.Context, params eventservice.ReplaySubscriberParams) error {
+		offsets[params.Subscriber] = params.Offset
+		return nil
+	})
+	return eventService
+}
+
+func TestRunOnce(t *testing.T) {
+	cases := []struct {
+		name          string
+		events        []event.Event
+		paramDurable  bool
+		paramFrom     int64
+		claimed       bool
+		failOn        int64
+		returnHandled []int64
+		returnOffset  int64
+		returnErr     error
+	}{
+		{
+			name:          "test durable subscriber",
+			events:        []event.Event{getEvent(1, now), getEvent(2, now), getEvent(3, now)},
+			paramDurable:  true,
+			claimed:       true,
+			returnHandled: []int64{1, 2, 3},
+			returnOffset:  3,
+		},
+		{
+			name:         "test durable subscriber claimed by another instance",
+			events:       []event.Event{getEvent(1, now)},
+			paramDurable: true,
+			returnOffset: 0,
+		},
+		{
+			name:          "test subscriber that isn't durable starts from its offset",
+			events:        []event.Event{getEvent(1, now), getEvent(2, now), getEvent(3, now)},
+			paramFrom:     1,
+			returnHandled: []int64{2, 3},
+			returnOffset:  3,
+		},
+		{
+			name:          "test failed event is kept for the next run",
+			events:        []event.Event{getEvent(1, now), getEvent(2, now), getEvent(3, now)},
+			paramDurable:  true,
+			claimed:       true,
+			failOn:        2,
+			returnHandled: []int64{1, 2},
+			returnOffset:  1,
+			returnErr:     errors.New("subscriber test failed to handle event 2: failed"),
+		},
+		{
+			name:          "test waits for a missing offset that may still be committing",
+			events:        []event.Event{getEvent(1, now), getEvent(3, now)},
+			paramDurable:  true,
+			claimed:       true,
+			returnHandled: []int64{1},
+			returnOffset:  1,
+		},
+		{
+			name:          "test skips a missing offset once the commit window has passed",
+			events:        []event.Event{getEvent(1, now.Add(-time.Hour)), getEvent(3, now.Add(-time.Hour))},
+			paramDurable:  true,
+			claimed:       true,
+			returnHandled: []int64{1, 3},
+			returnOffset:  3,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			offsets := map[string]int64{}
+			eventService := newEventService(tc.events, offsets, tc.claimed)
+			dispatcher := &Dispatcher{
+				EventService: eventService,
+				Clock:        clock.MockClock{MockedTime: &now},
+			}
+			var handled []int64
+			err := dispatcher.Subscribe(context.Background(), Subscriber{
+				Name: "test",
+				Handler: func(ctx context.Context, e event.Event) error {
+					handled = append(handled, e.Offset)
+					if e.Offset == tc.failOn {
+						return errors.New("failed")
+					}
+					return nil
+				},
+				Durable: tc.paramDurable,
+				From:    tc.paramFrom,
+			})
+			require.NoError(t, err)
+			count, err := dispatcher.RunOnce(context.Background())
+			errExpected := testutils.TestErrorAgainstCase(t, err, tc.returnErr)
+			require.Equal(t, tc.returnHandled, handled)
+			offset := offsets["test"]
+			if !tc.paramDurable {
+				offset = dispatcher.subscriptions[0].offset
+			}
+			require.Equal(t, tc.returnOffset, offset)
+			if errExpected {
+				return
+			}
+			require.Equal(t, len(tc.returnHandled), count)
+		})
+	}
+}
+
+func TestReplay(t *testing.T) {
+	for _, durable := range []bool{true, false} {
+		offsets := map[string]int64{}
+		eventService := newEventService([]event.Event{getEvent(1, now), getEvent(2, now), getEvent(3, now)}, offsets, true)
+		dispatcher := &Dispatcher{
+			EventService: eventService,
+			Clock:        clock.MockClock{MockedTime: &now},
+		}
+		var handled []int64
+		err := dispatcher.Subscribe(context.Background(), Subscriber{
+			Name: "test",
+			Handler: func(ctx context.Context, e event.Event) error {
+				handled = append(handled, e.Offset)
+				return nil
+			},
+			Durable: durable,
+		})
+		require.NoError(t, err)
+		_, err = dispatcher.RunOnce(context.Background())
+		require.NoError(t, err)
+		err = dispatcher.Replay(context.Background(), "test", 1)
+		require.NoError(t, err)
+		_, err = dispatcher.RunOnce(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, []int64{1, 2, 3, 2, 3}, handled, "durable: %v", durable)
+	}
+	err := (&Dispatcher{}).Replay(context.Background(), "missing", 1)
+	require.EqualError(t, err, "subscriber missing isn't subscribed")
+}
+
+func TestSubscribeTwice(t *testing.T) {
+	dispatcher := &Dispatcher{EventService: newEventService(nil, map[string]int64{}, true)}
+	handler := func(ctx context.Context, e event.Event) error { return nil }
+	require.NoError(t, dispatcher.Subscribe(context.Background(), Subscriber{Name: "test", Handler: handler}))
+	require.EqualError(t, dispatcher.Subscribe(context.Background(), Subscriber{Name: "test", Handler: handler}), "subscriber test is already subscribed")
+}
+
+func TestUnitOfWorkNotifies(t *testing.T) {
+	cases := []struct {
+		name         string
+		paramErr     error
+		returnNotify bool
+	}{
+		{name: "test committed", returnNotify: true},
+		{name: "test rolled back", paramErr: errors.New("failed")},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			unitOfWork := new(storemocks.UnitOfWork)
+			unitOfWork.On("Do", mock.Anything, mock.Anything).Return(func(ctx context.Context, fn func(store.Stores) error) error {
+				return fn(store.Stores{})
+			})
+			dispatcher := &Dispatcher{}
+			err := UnitOfWork{UnitOfWork: unitOfWork, Dispatcher: dispatcher}.Do(context.Background(), func(stores store.Stores) error {
+				return tc.paramErr
+			})
+			require.Equal(t, tc.paramErr, err)
+			require.Equal(t, tc.returnNotify, len(dispatcher.getWake()) == 1)
+		})
+	}
+}
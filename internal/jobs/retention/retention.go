@@ -0,0 +1,62 @@
+package retention
+
+import (
+	"context"
+	"time"
+
+	pageservice "github.com/worlve/sp-service/internal/services/page"
+	"github.com/worlve/sp-service/internal/util/clock"
+	"github.com/worlve/sp-service/internal/util/logger"
+	"go.uber.org/zap"
+)
+
+// PageService see Service for more details
+type PageService interface {
+	PurgeRemovedPages(ctx context.Context, params pageservice.PurgeRemovedPagesParams) (int, error)
+}
+
+// Job permanently deletes removed pages once they have been in the trash for longer than RetentionDays.
+type Job struct {
+	PageService   PageService
+	RetentionDays int
+	Interval      time.Duration
+	Clock         clock.Clock
+	// Logger is used for the job's logs and put on the context of each run; nothing is logged when it's nil.
+	Logger *zap.Logger
+}
+
+// Run purges expired pages immediately and then every Interval until ctx is done.
+func (j Job) Run(ctx context.Context) {
+	l := j.Logger
+	if l == nil {
+		l = zap.NewNop()
+	}
+	l = l.With(zap.String("job", "trashRetention"))
+	ctx = logger.SetOnContext(ctx, l)
+	ticker := time.NewTicker(j.Interval)
+	defer ticker.Stop()
+	for {
+		count, err := j.RunOnce(ctx)
+		if err != nil {
+			l.Error("Trash retention job failed",
+				zap.String("err", err.Error()),
+			)
+		} else if count > 0 {
+			l.Info("Trash retention job purged pages",
+				zap.Int("count", count),
+			)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce purges every page removed more than RetentionDays ago and returns the number of pages purged.
+func (j Job) RunOnce(ctx context.Context) (int, error) {
+	return j.PageService.PurgeRemovedPages(ctx, pageservice.PurgeRemovedPagesParams{
+		RemovedBefore: j.Clock.Now().AddDate(0, 0, -j.RetentionDays),
+	})
+}
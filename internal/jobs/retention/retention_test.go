@@ -0,0 +1,75 @@
+package retention
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/worlve/sp-service/internal/jobs/retention/mocks"
+	pageservice "github.com/worlve/sp-service/internal/services/page"
+	"github.com/worlve/sp-service/internal/util/clock"
+	"github.com/worlve/sp-service/internal/util/testutils"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type purgeRemovedPagesCall struct {
+	params      pageservice.PurgeRemovedPagesParams
+	returnCount int
+	returnErr   error
+}
+
+func TestRunOnce(t *testing.T) {
+	now := time.Date(2020, 3, 31, 12, 0, 0, 0, time.UTC)
+	cases := []struct {
+		name                   string
+		retentionDays          int
+		purgeRemovedPagesCalls []purgeRemovedPagesCall
+		returnCount            int
+		returnErr              error
+	}{
+		{
+			name:          "test happy path",
+			retentionDays: 30,
+			purgeRemovedPagesCalls: []purgeRemovedPagesCall{
+				{
+					params:      pageservice.PurgeRemovedPagesParams{RemovedBefore: time.Date(2020, 3, 1, 12, 0, 0, 0, time.UTC)},
+					returnCount: 2,
+				},
+			},
+			returnCount: 2,
+		},
+		{
+			name:          "test failed purge",
+			retentionDays: 1,
+			purgeRemovedPagesCalls: []purgeRemovedPagesCall{
+				{
+					params:    pageservice.PurgeRemovedPagesParams{RemovedBefore: time.Date(2020, 3, 30, 12, 0, 0, 0, time.UTC)},
+					returnErr: errors.New("failed to purge removed pages"),
+				},
+			},
+			returnErr: errors.New("failed to purge removed pages"),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			pageService := new(mocks.PageService)
+			for index := range tc.purgeRemovedPagesCalls {
+				pageService.On("PurgeRemovedPages", mock.Anything, tc.purgeRemovedPagesCalls[index].params).Return(tc.purgeRemovedPagesCalls[index].returnCount, tc.purgeRemovedPagesCalls[index].returnErr)
+			}
+			job := Job{
+				PageService:   pageService,
+				RetentionDays: tc.retentionDays,
+				Clock:         clock.MockClock{MockedTime: &now},
+			}
+			count, err := job.RunOnce(context.Background())
+			pageService.AssertNumberOfCalls(t, "PurgeRemovedPages", len(tc.purgeRemovedPagesCalls))
+			errExpected := testutils.TestErrorAgainstCase(t, err, tc.returnErr)
+			if errExpected {
+				return
+			}
+			require.Equal(t, tc.returnCount, count)
+		})
+	}
+}
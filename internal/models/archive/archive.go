@@ -0,0 +1,125 @@
+package archive
+
+import (
+	"time"
+
+	"github.com/worlve/sp-service/internal/models/pagedetail"
+	"github.com/worlve/sp-service/internal/models/pagetemplate"
+	"github.com/worlve/sp-service/internal/models/permission"
+	"github.com/worlve/sp-service/internal/models/property"
+	"github.com/worlve/sp-service/internal/models/version"
+	"github.com/pkg/errors"
+)
+
+// FormatVersion is the current version of the archive format.
+// It should be incremented whenever a change is made that older importers cannot read.
+const FormatVersion = 1
+
+// Archive is a portable snapshot of everything a user owns.
+type Archive struct {
+	Manifest      Manifest
+	Versions      []version.Version
+	PageTemplates []pagetemplate.PageTemplate
+	Pages         []Page
+}
+
+// Manifest describes the archive itself.
+type Manifest struct {
+	FormatVersion int       `json:"formatVersion"`
+	ExportedAt    time.Time `json:"exportedAt"`
+	OwnerID       string    `json:"ownerId"`
+	// VersionID is the version the export was limited to, if any.
+	VersionID string `json:"versionId,omitempty"`
+}
+
+// Page is a single page as it is stored in the archive.
+type Page struct {
+	GUID           string                  `json:"id"`
+	VersionID      string                  `json:"versionId"`
+	PageTemplateID string                  `json:"pageTemplateId"`
+	Title          string                  `json:"title"`
+	Summary        string                  `json:"summary"`
+	PermissionType permission.Type         `json:"permission"`
+	Properties     []property.Property     `json:"properties"`
+	Details        []pagedetail.PageDetail `json:"details"`
+}
+
+// Format is a supported encoding of the archive.
+type Format string
+
+// All the valid values for Format
+const (
+	FormatZip    Format = "zip"
+	FormatNDJSON Format = "ndjson"
+)
+
+// GetFormat returns the correct format for the given string.
+func GetFormat(formatString string) (Format, error) {
+	switch formatString {
+	case string(FormatZip):
+		return FormatZip, nil
+	case string(FormatNDJSON):
+		return FormatNDJSON, nil
+	default:
+		return FormatZip, errors.Errorf("invalid archive format %v", formatString)
+	}
+}
+
+// ContentType returns the MIME type for the format.
+func (f Format) ContentType() string {
+	if f == FormatNDJSON {
+		return "application/x-ndjson"
+	}
+	return "application/zip"
+}
+
+// Validate checks that the archive can be imported.
+func (a Archive) Validate() error {
+	if a.Manifest.FormatVersion == 0 {
+		return errors.New("archive is missing its manifest")
+	}
+	if a.Manifest.FormatVersion > FormatVersion {
+		return errors.Errorf("archive format version %v is newer than the supported version %v", a.Manifest.FormatVersion, FormatVersion)
+	}
+	seen := make(map[string]bool)
+	for i, p := range a.Pages {
+		if p.GUID == "" {
+			return errors.Errorf("page at %v must have an id", i)
+		}
+		if seen[p.GUID] {
+			return errors.Errorf("page %v is in the archive more than once", p.GUID)
+		}
+		seen[p.GUID] = true
+		if p.Title == "" {
+			return errors.Errorf("page %v must have a title", p.GUID)
+		}
+		if p.VersionID == "" {
+			return errors.Errorf("page %v must have a versionId", p.GUID)
+		}
+		if p.PageTemplateID == "" {
+			return errors.Errorf("page %v must have a pageTemplateId", p.GUID)
+		}
+		if _, err := permission.GetPermissionType(string(p.PermissionType)); err != nil {
+			return errors.Wrapf(err, "page %v has an invalid permission", p.GUID)
+		}
+	}
+	return nil
+}
+
+// RemapRelations replaces every relation within the details' partitions using the given mapping of old to new page guids.
+// Relations to pages that are not in the mapping are left as is.
+func RemapRelations(details []pagedetail.PageDetail, guidMap map[string]string) {
+	for i := range details {
+		remapPartitionRelations(details[i].Partitions, guidMap)
+	}
+}
+
+func remapPartitionRelations(partitions []pagedetail.Partition, guidMap map[string]string) {
+	for i := range partitions {
+		if newGUID, ok := guidMap[partitions[i].Relation]; ok {
+			partitions[i].Relation = newGUID
+		}
+		remapPartitionRelations(partitions[i].Partitions, guidMap)
+		remapPartitionRelations(partitions[i].Items, guidMap)
+	}
+}
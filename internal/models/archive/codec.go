@@ -0,0 +1,205 @@
+package archive
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"path"
+	"strings"
+
+	"github.com/worlve/sp-service/internal/models/pagetemplate"
+	"github.com/worlve/sp-service/internal/models/version"
+	"github.com/pkg/errors"
+)
+
+// File names used within a zip archive.
+const (
+	zipManifestFile      = "manifest.json"
+	zipVersionsFile      = "versions.json"
+	zipPageTemplatesFile = "pagetemplates.json"
+	zipPagesDir          = "pages/"
+)
+
+// Kinds of records within an NDJSON archive.
+const (
+	ndjsonKindManifest     = "manifest"
+	ndjsonKindVersion      = "version"
+	ndjsonKindPageTemplate = "pageTemplate"
+	ndjsonKindPage         = "page"
+)
+
+type ndjsonRecord struct {
+	Kind         string                     `json:"kind"`
+	Manifest     *Manifest                  `json:"manifest,omitempty"`
+	Version      *version.Version           `json:"version,omitempty"`
+	PageTemplate *pagetemplate.PageTemplate `json:"pageTemplate,omitempty"`
+	Page         *Page                      `json:"page,omitempty"`
+}
+
+// Encode writes the archive to w in the given format.
+func Encode(w io.Writer, a Archive, format Format) error {
+	switch format {
+	case FormatZip:
+		return encodeZip(w, a)
+	case FormatNDJSON:
+		return encodeNDJSON(w, a)
+	default:
+		return errors.Errorf("invalid archive format %v", format)
+	}
+}
+
+// Decode reads an archive in the given format from r.
+func Decode(r io.Reader, format Format) (Archive, error) {
+	switch format {
+	case FormatZip:
+		return decodeZip(r)
+	case FormatNDJSON:
+		return decodeNDJSON(r)
+	default:
+		return Archive{}, errors.Errorf("invalid archive format %v", format)
+	}
+}
+
+func encodeZip(w io.Writer, a Archive) error {
+	zw := zip.NewWriter(w)
+	err := writeZipJSON(zw, zipManifestFile, a.Manifest)
+	if err != nil {
+		return err
+	}
+	err = writeZipJSON(zw, zipVersionsFile, a.Versions)
+	if err != nil {
+		return err
+	}
+	err = writeZipJSON(zw, zipPageTemplatesFile, a.PageTemplates)
+	if err != nil {
+		return err
+	}
+	for _, p := range a.Pages {
+		err = writeZipJSON(zw, zipPagesDir+p.GUID+".json", p)
+		if err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func writeZipJSON(zw *zip.Writer, name string, v interface{}) error {
+	fw, err := zw.Create(name)
+	if err != nil {
+		return errors.Wrapf(err, "unable to add %v to archive", name)
+	}
+	encoder := json.NewEncoder(fw)
+	encoder.SetIndent("", "  ")
+	err = encoder.Encode(v)
+	if err != nil {
+		return errors.Wrapf(err, "unable to write %v to archive", name)
+	}
+	return nil
+}
+
+func decodeZip(r io.Reader) (Archive, error) {
+	var a Archive
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return a, errors.Wrap(err, "unable to read archive")
+	}
+	zr, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		return a, errors.Wrap(err, "unable to open zip archive")
+	}
+	for _, f := range zr.File {
+		switch {
+		case f.Name == zipManifestFile:
+			err = readZipJSON(f, &a.Manifest)
+		case f.Name == zipVersionsFile:
+			err = readZipJSON(f, &a.Versions)
+		case f.Name == zipPageTemplatesFile:
+			err = readZipJSON(f, &a.PageTemplates)
+		case strings.HasPrefix(f.Name, zipPagesDir) && path.Ext(f.Name) == ".json":
+			var p Page
+			err = readZipJSON(f, &p)
+			a.Pages = append(a.Pages, p)
+		}
+		if err != nil {
+			return a, err
+		}
+	}
+	return a, nil
+}
+
+func readZipJSON(f *zip.File, v interface{}) error {
+	rc, err := f.Open()
+	if err != nil {
+		return errors.Wrapf(err, "unable to open %v in archive", f.Name)
+	}
+	defer rc.Close()
+	err = json.NewDecoder(rc).Decode(v)
+	if err != nil {
+		return errors.Wrapf(err, "unable to decode %v in archive", f.Name)
+	}
+	return nil
+}
+
+func encodeNDJSON(w io.Writer, a Archive) error {
+	encoder := json.NewEncoder(w)
+	err := encoder.Encode(ndjsonRecord{Kind: ndjsonKindManifest, Manifest: &a.Manifest})
+	if err != nil {
+		return errors.Wrap(err, "unable to write manifest")
+	}
+	for i := range a.Versions {
+		err = encoder.Encode(ndjsonRecord{Kind: ndjsonKindVersion, Version: &a.Versions[i]})
+		if err != nil {
+			return errors.Wrapf(err, "unable to write version %v", a.Versions[i].GUID)
+		}
+	}
+	for i := range a.PageTemplates {
+		err = encoder.Encode(ndjsonRecord{Kind: ndjsonKindPageTemplate, PageTemplate: &a.PageTemplates[i]})
+		if err != nil {
+			return errors.Wrapf(err, "unable to write page template %v", a.PageTemplates[i].GUID)
+		}
+	}
+	for i := range a.Pages {
+		err = encoder.Encode(ndjsonRecord{Kind: ndjsonKindPage, Page: &a.Pages[i]})
+		if err != nil {
+			return errors.Wrapf(err, "unable to write page %v", a.Pages[i].GUID)
+		}
+	}
+	return nil
+}
+
+func decodeNDJSON(r io.Reader) (Archive, error) {
+	var a Archive
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line = line + 1
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var record ndjsonRecord
+		err := json.Unmarshal(scanner.Bytes(), &record)
+		if err != nil {
+			return a, errors.Wrapf(err, "unable to decode line %v", line)
+		}
+		switch {
+		case record.Kind == ndjsonKindManifest && record.Manifest != nil:
+			a.Manifest = *record.Manifest
+		case record.Kind == ndjsonKindVersion && record.Version != nil:
+			a.Versions = append(a.Versions, *record.Version)
+		case record.Kind == ndjsonKindPageTemplate && record.PageTemplate != nil:
+			a.PageTemplates = append(a.PageTemplates, *record.PageTemplate)
+		case record.Kind == ndjsonKindPage && record.Page != nil:
+			a.Pages = append(a.Pages, *record.Page)
+		default:
+			return a, errors.Errorf("unsupported record on line %v", line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return a, errors.Wrap(err, "unable to read archive")
+	}
+	return a, nil
+}
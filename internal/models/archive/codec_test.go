@@ -0,0 +1,158 @@
+package archive
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/worlve/sp-service/internal/models/pagedetail"
+	"github.com/worlve/sp-service/internal/models/pagetemplate"
+	"github.com/worlve/sp-service/internal/models/permission"
+	"github.com/worlve/sp-service/internal/models/property"
+	"github.com/worlve/sp-service/internal/models/version"
+	"github.com/worlve/sp-service/internal/util/testutils"
+	"github.com/stretchr/testify/require"
+)
+
+func getTestArchive() Archive {
+	return Archive{
+		Manifest: Manifest{
+			FormatVersion: FormatVersion,
+			ExportedAt:    time.Date(2020, 3, 31, 12, 0, 0, 0, time.UTC),
+			OwnerID:       "UR_1",
+		},
+		Versions:      []version.Version{{GUID: "VR_1", Name: "Default"}},
+		PageTemplates: []pagetemplate.PageTemplate{{GUID: "PGT_1", Name: "Place"}},
+		Pages: []Page{
+			{
+				GUID:           "PG_1",
+				VersionID:      "VR_1",
+				PageTemplateID: "PGT_1",
+				Title:          "test title",
+				PermissionType: permission.TypePrivate,
+				Properties: []property.Property{
+					{Key: "population", Type: property.TypeNumber, Value: float64(100)},
+				},
+				Details: []pagedetail.PageDetail{
+					{GUID: "DT_1", Title: "test detail", Partitions: []pagedetail.Partition{{TypeString: "relation", Value: "other page", Relation: "PG_2"}}},
+				},
+			},
+			{
+				GUID:           "PG_2",
+				VersionID:      "VR_1",
+				PageTemplateID: "PGT_1",
+				Title:          "test title 2",
+				PermissionType: permission.TypePublic,
+				Properties:     []property.Property{},
+				Details:        []pagedetail.PageDetail{},
+			},
+		},
+	}
+}
+
+func TestEncodeDecode(t *testing.T) {
+	cases := []struct {
+		name   string
+		format Format
+	}{
+		{
+			name:   "zip round trip",
+			format: FormatZip,
+		},
+		{
+			name:   "ndjson round trip",
+			format: FormatNDJSON,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			a := getTestArchive()
+			var b bytes.Buffer
+			err := Encode(&b, a, tc.format)
+			require.NoError(t, err)
+			result, err := Decode(&b, tc.format)
+			require.NoError(t, err)
+			require.Equal(t, a, result)
+		})
+	}
+}
+
+func TestDecodeNDJSON(t *testing.T) {
+	cases := []struct {
+		name      string
+		paramBody string
+		returnErr error
+	}{
+		{
+			name:      "unsupported record",
+			paramBody: "{\"kind\":\"manifest\",\"manifest\":{\"formatVersion\":1}}\n{\"kind\":\"campaign\"}\n",
+			returnErr: errors.New("unsupported record on line 2"),
+		},
+		{
+			name:      "invalid json",
+			paramBody: "{\"kind\":",
+			returnErr: errors.New("unable to decode line 1: unexpected end of JSON input"),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Decode(strings.NewReader(tc.paramBody), FormatNDJSON)
+			testutils.TestErrorAgainstCase(t, err, tc.returnErr)
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cases := []struct {
+		name         string
+		paramArchive func() Archive
+		returnErr    error
+	}{
+		{
+			name:         "valid archive",
+			paramArchive: getTestArchive,
+		},
+		{
+			name: "newer format version",
+			paramArchive: func() Archive {
+				a := getTestArchive()
+				a.Manifest.FormatVersion = FormatVersion + 1
+				return a
+			},
+			returnErr: errors.New("archive format version 2 is newer than the supported version 1"),
+		},
+		{
+			name: "duplicate page",
+			paramArchive: func() Archive {
+				a := getTestArchive()
+				a.Pages[1].GUID = "PG_1"
+				return a
+			},
+			returnErr: errors.New("page PG_1 is in the archive more than once"),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.paramArchive().Validate()
+			testutils.TestErrorAgainstCase(t, err, tc.returnErr)
+		})
+	}
+}
+
+func TestRemapRelations(t *testing.T) {
+	details := []pagedetail.PageDetail{
+		{
+			Partitions: []pagedetail.Partition{
+				{TypeString: "relation", Relation: "PG_1"},
+				{TypeString: "p", Partitions: []pagedetail.Partition{{TypeString: "relation", Relation: "PG_2"}}},
+				{TypeString: "ul", Items: []pagedetail.Partition{{TypeString: "relation", Relation: "PG_3"}}},
+			},
+		},
+	}
+	RemapRelations(details, map[string]string{"PG_1": "PG_A", "PG_3": "PG_C"})
+	require.Equal(t, "PG_A", details[0].Partitions[0].Relation)
+	require.Equal(t, "PG_2", details[0].Partitions[1].Partitions[0].Relation)
+	require.Equal(t, "PG_C", details[0].Partitions[2].Items[0].Relation)
+}
@@ -0,0 +1,81 @@
+package audit
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ActorType is how the actor of an entry was authenticated.
+type ActorType string
+
+// valid ActorType values; they're the same as the API's auth types.
+const (
+	ActorAdmin     ActorType = "admin"
+	ActorProxyUser ActorType = "proxyUser"
+)
+
+// GetActorType returns the ActorType of the given string, or an error if it isn't valid.
+func GetActorType(s string) (ActorType, error) {
+	for _, actorType := range []ActorType{ActorAdmin, ActorProxyUser} {
+		if string(actorType) == s {
+			return actorType, nil
+		}
+	}
+	return "", errors.Errorf("invalid actor type: %v", s)
+}
+
+// Entry is a record of a single mutating call made to the API.
+type Entry struct {
+	ID        int64     `json:"-"`
+	GUID      string    `json:"id"`
+	ActorType ActorType `json:"actorType"`
+	// UserID is the user who made the call; it's empty for an admin acting as itself.
+	UserID string `json:"userId,omitempty"`
+	Method string `json:"method"`
+	Route  string `json:"route"`
+	// TargetID is the GUID of what the call changed, and PageID is the page it belongs to, if any.
+	TargetID string `json:"targetId,omitempty"`
+	PageID   string `json:"pageId,omitempty"`
+	Status   int    `json:"status"`
+	// Before and After summarize the target as it was before and after the call, when known.
+	Before    json.RawMessage `json:"before,omitempty"`
+	After     json.RawMessage `json:"after,omitempty"`
+	RequestID string          `json:"requestId"`
+	CreatedAt *time.Time      `json:"createdAt"`
+}
+
+// Filter narrows down the entries returned; empty fields don't filter.
+type Filter struct {
+	UserID    string
+	PageID    string
+	TargetID  string
+	ActorType ActorType
+	// Since and Until bound when the entries were created; Until is exclusive.
+	Since *time.Time
+	Until *time.Time
+}
+
+// Matches returns whether the entry is within the filter.
+func (f Filter) Matches(e Entry) bool {
+	if f.UserID != "" && e.UserID != f.UserID {
+		return false
+	}
+	if f.PageID != "" && e.PageID != f.PageID {
+		return false
+	}
+	if f.TargetID != "" && e.TargetID != f.TargetID {
+		return false
+	}
+	if f.ActorType != "" && e.ActorType != f.ActorType {
+		return false
+	}
+	if f.Since != nil && (e.CreatedAt == nil || e.CreatedAt.Before(*f.Since)) {
+		return false
+	}
+	if f.Until != nil && (e.CreatedAt == nil || !e.CreatedAt.Before(*f.Until)) {
+		return false
+	}
+	return true
+}
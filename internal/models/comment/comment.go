@@ -0,0 +1,82 @@
+package comment
+
+import (
+	"regexp"
+	"time"
+)
+
+// MaxBodyLength is the longest a comment's body can be, in characters.
+const MaxBodyLength = 10000
+
+// Anchor is the part of a page a thread is about.
+type Anchor struct {
+	DetailID string `json:"detailId"`
+	// PartitionID narrows the anchor down to a partition of the detail.
+	PartitionID string `json:"partitionId,omitempty"`
+}
+
+// Comment is a remark on a page. A comment without a ThreadGUID starts a thread, and the rest are its replies.
+type Comment struct {
+	ID       int64  `json:"-"`
+	GUID     string `json:"id"`
+	PageGUID string `json:"pageId"`
+	// ThreadGUID is the comment that started the thread this one replies to.
+	ThreadGUID string `json:"threadId,omitempty"`
+	UserID     string `json:"userId"`
+	// Anchor is only set on threads, and is nil for a thread about the whole page.
+	Anchor *Anchor `json:"anchor,omitempty"`
+	Body   string  `json:"body"`
+	// Mentions are the users mentioned in the body who could read the page when it was written.
+	Mentions []string `json:"mentions"`
+	// ResolvedAt and ResolvedBy are only set on resolved threads.
+	ResolvedAt *time.Time `json:"resolvedAt,omitempty"`
+	ResolvedBy string     `json:"resolvedBy,omitempty"`
+	CreatedAt  *time.Time `json:"createdAt"`
+	UpdatedAt  *time.Time `json:"updatedAt"`
+}
+
+// IsThread returns whether the comment starts a thread rather than replying to one.
+func (c Comment) IsThread() bool {
+	return c.ThreadGUID == ""
+}
+
+// GetThreadGUID returns the GUID of the thread the comment is in, which is its own for a thread.
+func (c Comment) GetThreadGUID() string {
+	if c.IsThread() {
+		return c.GUID
+	}
+	return c.ThreadGUID
+}
+
+// Filter narrows down the threads returned; empty fields don't filter.
+type Filter struct {
+	DetailID string
+	Resolved *bool
+}
+
+// Matches returns whether the thread is within the filter.
+func (f Filter) Matches(c Comment) bool {
+	if f.DetailID != "" && (c.Anchor == nil || c.Anchor.DetailID != f.DetailID) {
+		return false
+	}
+	if f.Resolved != nil && *f.Resolved != (c.ResolvedAt != nil) {
+		return false
+	}
+	return true
+}
+
+// mentionPattern doesn't match within a word, such as an email address.
+var mentionPattern = regexp.MustCompile(`(?:^|[^A-Za-z0-9_.@])@(UR_[A-Za-z0-9]+)`)
+
+// ParseMentions returns the users mentioned in the body as @UR_..., in the order they're first mentioned.
+func ParseMentions(body string) []string {
+	mentions := []string{}
+	seen := map[string]bool{}
+	for _, match := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		if !seen[match[1]] {
+			seen[match[1]] = true
+			mentions = append(mentions, match[1])
+		}
+	}
+	return mentions
+}
@@ -0,0 +1,79 @@
+package comment
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMentions(t *testing.T) {
+	cases := []struct {
+		name           string
+		paramBody      string
+		returnMentions []string
+	}{
+		{
+			name:           "test mentions in the order they're first made",
+			paramBody:      "@UR_2 and (@UR_1), what do you think? cc @UR_2.",
+			returnMentions: []string{"UR_2", "UR_1"},
+		},
+		{
+			name:           "test an email address isn't a mention",
+			paramBody:      "email someone@UR_1 about UR_2",
+			returnMentions: []string{},
+		},
+		{
+			name:           "test other ids aren't mentions",
+			paramBody:      "see @PG_1 or @ur_1",
+			returnMentions: []string{},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.returnMentions, ParseMentions(tc.paramBody))
+		})
+	}
+}
+
+func TestFilterMatches(t *testing.T) {
+	resolved, open := true, false
+	cases := []struct {
+		name         string
+		paramFilter  Filter
+		paramComment Comment
+		returnMatch  bool
+	}{
+		{
+			name:         "test empty filter",
+			paramComment: Comment{GUID: "CM_1"},
+			returnMatch:  true,
+		},
+		{
+			name:         "test detail",
+			paramFilter:  Filter{DetailID: "PD_1"},
+			paramComment: Comment{GUID: "CM_1", Anchor: &Anchor{DetailID: "PD_1", PartitionID: "P1"}},
+			returnMatch:  true,
+		},
+		{
+			name:         "test thread about the whole page isn't about a detail",
+			paramFilter:  Filter{DetailID: "PD_1"},
+			paramComment: Comment{GUID: "CM_1"},
+		},
+		{
+			name:         "test open threads",
+			paramFilter:  Filter{Resolved: &open},
+			paramComment: Comment{GUID: "CM_1"},
+			returnMatch:  true,
+		},
+		{
+			name:         "test resolved threads",
+			paramFilter:  Filter{Resolved: &resolved},
+			paramComment: Comment{GUID: "CM_1"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.returnMatch, tc.paramFilter.Matches(tc.paramComment))
+		})
+	}
+}
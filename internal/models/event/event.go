@@ -0,0 +1,144 @@
+package event
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/worlve/sp-service/internal/models/page"
+	"github.com/worlve/sp-service/internal/models/pagedetail"
+	"github.com/worlve/sp-service/internal/models/property"
+	"github.com/pkg/errors"
+)
+
+// Type is the kind of change an event records.
+type Type string
+
+// valid Type values.
+const (
+	TypePageCreated        Type = "page.created"
+	TypePageUpdated        Type = "page.updated"
+	TypePageRemoved        Type = "page.removed"
+	TypePageRestored       Type = "page.restored"
+	TypePagePurged         Type = "page.purged"
+	TypePropertiesReplaced Type = "properties.replaced"
+	TypePageDetailUpdated  Type = "detail.updated"
+)
+
+// Event is a change made through one of the services, as recorded in the outbox.
+type Event struct {
+	// Offset is the event's position in the outbox; subscribers track how far they've got by it.
+	Offset int64  `json:"offset"`
+	GUID   string `json:"id"`
+	Type   Type   `json:"type"`
+	PageID string `json:"pageId"`
+	// UserID is the user who made the change.
+	UserID string `json:"userId"`
+	// Payload is the event's Data encoded as JSON.
+	Payload   json.RawMessage `json:"data"`
+	CreatedAt *time.Time      `json:"createdAt"`
+}
+
+// Data is the typed detail of a change; it's one of the structs below.
+type Data interface {
+	header() (Type, string)
+}
+
+// PageCreated is the data of a page.created event.
+type PageCreated struct {
+	PageID string           `json:"pageId"`
+	Page   page.ReducedPage `json:"page"`
+}
+
+// PageUpdated is the data of a page.updated event. Page is the page as it is after the change.
+type PageUpdated struct {
+	PageID string           `json:"pageId"`
+	Page   page.ReducedPage `json:"page"`
+}
+
+// PageRemoved is the data of a page.removed event.
+type PageRemoved struct {
+	PageID string `json:"pageId"`
+}
+
+// PageRestored is the data of a page.restored event. Page is the page as it is once it's restored.
+type PageRestored struct {
+	PageID string           `json:"pageId"`
+	Page   page.ReducedPage `json:"page"`
+}
+
+// PagePurged is the data of a page.purged event.
+type PagePurged struct {
+	PageID string `json:"pageId"`
+}
+
+// PropertiesReplaced is the data of a properties.replaced event.
+type PropertiesReplaced struct {
+	PageID     string              `json:"pageId"`
+	Properties []property.Property `json:"properties"`
+}
+
+// PageDetailUpdated is the data of a detail.updated event.
+type PageDetailUpdated struct {
+	PageID string                `json:"pageId"`
+	Detail pagedetail.PageDetail `json:"detail"`
+}
+
+func (d PageCreated) header() (Type, string)        { return TypePageCreated, d.PageID }
+func (d PageUpdated) header() (Type, string)        { return TypePageUpdated, d.PageID }
+func (d PageRemoved) header() (Type, string)        { return TypePageRemoved, d.PageID }
+func (d PageRestored) header() (Type, string)       { return TypePageRestored, d.PageID }
+func (d PagePurged) header() (Type, string)         { return TypePagePurged, d.PageID }
+func (d PropertiesReplaced) header() (Type, string) { return TypePropertiesReplaced, d.PageID }
+func (d PageDetailUpdated) header() (Type, string)  { return TypePageDetailUpdated, d.PageID }
+
+// New returns the event for a change the user made, ready to be added to the outbox.
+func New(guid string, data Data, userID string) (Event, error) {
+	eventType, pageID := data.header()
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return Event{}, errors.Wrapf(err, "failed to encode %v event", eventType)
+	}
+	return Event{
+		GUID:    guid,
+		Type:    eventType,
+		PageID:  pageID,
+		UserID:  userID,
+		Payload: payload,
+	}, nil
+}
+
+// Decode returns the event's typed data.
+func (e Event) Decode() (Data, error) {
+	var err error
+	switch e.Type {
+	case TypePageCreated:
+		var data PageCreated
+		err = json.Unmarshal(e.Payload, &data)
+		return data, errors.Wrapf(err, "failed to decode event %v", e.GUID)
+	case TypePageUpdated:
+		var data PageUpdated
+		err = json.Unmarshal(e.Payload, &data)
+		return data, errors.Wrapf(err, "failed to decode event %v", e.GUID)
+	case TypePageRemoved:
+		var data PageRemoved
+		err = json.Unmarshal(e.Payload, &data)
+		return data, errors.Wrapf(err, "failed to decode event %v", e.GUID)
+	case TypePageRestored:
+		var data PageRestored
+		err = json.Unmarshal(e.Payload, &data)
+		return data, errors.Wrapf(err, "failed to decode event %v", e.GUID)
+	case TypePagePurged:
+		var data PagePurged
+		err = json.Unmarshal(e.Payload, &data)
+		return data, errors.Wrapf(err, "failed to decode event %v", e.GUID)
+	case TypePropertiesReplaced:
+		var data PropertiesReplaced
+		err = json.Unmarshal(e.Payload, &data)
+		return data, errors.Wrapf(err, "failed to decode event %v", e.GUID)
+	case TypePageDetailUpdated:
+		var data PageDetailUpdated
+		err = json.Unmarshal(e.Payload, &data)
+		return data, errors.Wrapf(err, "failed to decode event %v", e.GUID)
+	}
+	return nil, errors.Errorf("unknown type %v of event %v", e.Type, e.GUID)
+}
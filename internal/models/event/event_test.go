@@ -0,0 +1,49 @@
+package event
+
+import (
+	"testing"
+
+	"github.com/worlve/sp-service/internal/models/page"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAndDecode(t *testing.T) {
+	cases := []struct {
+		name       string
+		paramData  Data
+		returnType Type
+		returnJSON string
+	}{
+		{
+			name:       "test page created",
+			paramData:  PageCreated{PageID: "PG_1", Page: page.ReducedPage{GUID: "PG_1", Title: "Title"}},
+			returnType: TypePageCreated,
+			returnJSON: `{"pageId":"PG_1","page":{"versionId":"","pageTemplateId":"","id":"PG_1","title":"Title","summary":"","permission":"","createdAt":null,"updatedAt":null}}`,
+		},
+		{
+			name:       "test page removed",
+			paramData:  PageRemoved{PageID: "PG_1"},
+			returnType: TypePageRemoved,
+			returnJSON: `{"pageId":"PG_1"}`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			e, err := New("EV_1", tc.paramData, "UR_1")
+			require.NoError(t, err)
+			require.Equal(t, "EV_1", e.GUID)
+			require.Equal(t, tc.returnType, e.Type)
+			require.Equal(t, "PG_1", e.PageID)
+			require.Equal(t, "UR_1", e.UserID)
+			require.JSONEq(t, tc.returnJSON, string(e.Payload))
+			data, err := e.Decode()
+			require.NoError(t, err)
+			require.Equal(t, tc.paramData, data)
+		})
+	}
+}
+
+func TestDecodeUnknownType(t *testing.T) {
+	_, err := Event{GUID: "EV_1", Type: "page.renamed", Payload: []byte(`{}`)}.Decode()
+	require.EqualError(t, err, "unknown type page.renamed of event EV_1")
+}
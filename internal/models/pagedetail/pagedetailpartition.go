@@ -4,6 +4,8 @@ import "github.com/pkg/errors"
 
 // Partition is a single markdown partition for a detail.
 type Partition struct {
+	// GUID is optional, and is set by the client so comments can be anchored to the partition.
+	GUID       string        `json:"id,omitempty"`
 	Type       PartitionType `json:"-"`
 	TypeString string        `json:"type"`
 	Value      string        `json:"value,omitempty"`
@@ -38,6 +40,16 @@ func UnmarshalPartitions(p []Partition) error {
 	return nil
 }
 
+// HasPartition returns whether any of the partitions, or the partitions and items within them, has the GUID.
+func HasPartition(partitions []Partition, guid string) bool {
+	for _, p := range partitions {
+		if p.GUID == guid || HasPartition(p.Partitions, guid) || HasPartition(p.Items, guid) {
+			return true
+		}
+	}
+	return false
+}
+
 // PartitionType is a valid property type.
 type PartitionType string
 
@@ -69,3 +69,30 @@ func TestUnmarshelPartitions(t *testing.T) {
 		})
 	}
 }
+
+func TestHasPartition(t *testing.T) {
+	partitions := []Partition{
+		{GUID: "P_1", TypeString: "h1"},
+		{
+			TypeString: "ul",
+			Items: []Partition{
+				{GUID: "P_2", TypeString: "li", Partitions: []Partition{{GUID: "P_3", TypeString: "p"}}},
+			},
+		},
+	}
+	cases := []struct {
+		name      string
+		paramGUID string
+		returnHas bool
+	}{
+		{name: "test top level partition", paramGUID: "P_1", returnHas: true},
+		{name: "test item", paramGUID: "P_2", returnHas: true},
+		{name: "test partition within an item", paramGUID: "P_3", returnHas: true},
+		{name: "test missing partition", paramGUID: "P_4"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.returnHas, HasPartition(partitions, tc.paramGUID))
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package stream
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/worlve/sp-service/internal/models/event"
+)
+
+// Event is a change to a page, as pushed to the clients streaming it.
+type Event struct {
+	// ID is the offset of the event in the outbox; a client resumes its stream after the last ID it saw.
+	ID     int64      `json:"-"`
+	Type   event.Type `json:"type"`
+	PageID string     `json:"pageId"`
+	// UserID is the user whose page changed.
+	UserID    string          `json:"-"`
+	CreatedAt time.Time       `json:"createdAt"`
+	Data      json.RawMessage `json:"data"`
+}
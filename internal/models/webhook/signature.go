@@ -0,0 +1,59 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The headers sent with each delivery.
+const (
+	SignatureHeader = "X-SP-Signature"
+	EventHeader     = "X-SP-Event"
+	DeliveryHeader  = "X-SP-Delivery"
+)
+
+// Sign returns the SignatureHeader value for a delivery of body sent at t: the unix time and an HMAC-SHA256 of
+// "<unix time>.<body>" keyed with the webhook's secret, as "t=<unix time>,v1=<hex hmac>".
+func Sign(secret string, t time.Time, body []byte) string {
+	timestamp := strconv.FormatInt(t.Unix(), 10)
+	return fmt.Sprintf("t=%v,v1=%v", timestamp, computeSignature(secret, timestamp, body))
+}
+
+// VerifySignature returns whether header is a valid signature of body made with secret no more than tolerance
+// before now. A tolerance of 0 accepts any age.
+func VerifySignature(secret, header string, body []byte, now time.Time, tolerance time.Duration) bool {
+	var timestamp, signature string
+	for _, part := range strings.Split(header, ",") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		switch kv[0] {
+		case "t":
+			timestamp = kv[1]
+		case "v1":
+			signature = kv[1]
+		}
+	}
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || signature == "" {
+		return false
+	}
+	if tolerance > 0 && now.Sub(time.Unix(unix, 0)) > tolerance {
+		return false
+	}
+	return hmac.Equal([]byte(signature), []byte(computeSignature(secret, timestamp, body)))
+}
+
+func computeSignature(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,75 @@
+package webhook
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifySignature(t *testing.T) {
+	signedAt := time.Unix(1600000000, 0)
+	body := []byte(`{"id":"EV_1"}`)
+	cases := []struct {
+		name           string
+		paramSecret    string
+		paramHeader    string
+		paramBody      []byte
+		paramNow       time.Time
+		paramTolerance time.Duration
+		returnValid    bool
+	}{
+		{
+			name:           "test valid signature",
+			paramSecret:    "secret",
+			paramHeader:    Sign("secret", signedAt, body),
+			paramBody:      body,
+			paramNow:       signedAt.Add(time.Minute),
+			paramTolerance: 5 * time.Minute,
+			returnValid:    true,
+		},
+		{
+			name:        "test any age is accepted without a tolerance",
+			paramSecret: "secret",
+			paramHeader: Sign("secret", signedAt, body),
+			paramBody:   body,
+			paramNow:    signedAt.Add(24 * time.Hour),
+			returnValid: true,
+		},
+		{
+			name:        "test wrong secret",
+			paramSecret: "other",
+			paramHeader: Sign("secret", signedAt, body),
+			paramBody:   body,
+			paramNow:    signedAt,
+		},
+		{
+			name:        "test changed body",
+			paramSecret: "secret",
+			paramHeader: Sign("secret", signedAt, body),
+			paramBody:   []byte(`{"id":"EV_2"}`),
+			paramNow:    signedAt,
+		},
+		{
+			name:           "test too old",
+			paramSecret:    "secret",
+			paramHeader:    Sign("secret", signedAt, body),
+			paramBody:      body,
+			paramNow:       signedAt.Add(time.Hour),
+			paramTolerance: 5 * time.Minute,
+		},
+		{
+			name:        "test malformed header",
+			paramSecret: "secret",
+			paramHeader: "v1=abc",
+			paramBody:   body,
+			paramNow:    signedAt,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			valid := VerifySignature(tc.paramSecret, tc.paramHeader, tc.paramBody, tc.paramNow, tc.paramTolerance)
+			require.Equal(t, tc.returnValid, valid)
+		})
+	}
+}
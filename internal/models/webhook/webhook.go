@@ -0,0 +1,102 @@
+package webhook
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/worlve/sp-service/internal/models/event"
+	"github.com/pkg/errors"
+)
+
+// EventType is a change a webhook can subscribe to.
+type EventType string
+
+// valid EventType values; they're the types of the events in the outbox.
+const (
+	EventPageCreated        = EventType(event.TypePageCreated)
+	EventPageUpdated        = EventType(event.TypePageUpdated)
+	EventPageRemoved        = EventType(event.TypePageRemoved)
+	EventPageRestored       = EventType(event.TypePageRestored)
+	EventPagePurged         = EventType(event.TypePagePurged)
+	EventPropertiesReplaced = EventType(event.TypePropertiesReplaced)
+	EventPageDetailUpdated  = EventType(event.TypePageDetailUpdated)
+)
+
+// EventTypes are all the valid EventType values.
+var EventTypes = []EventType{
+	EventPageCreated,
+	EventPageUpdated,
+	EventPageRemoved,
+	EventPageRestored,
+	EventPagePurged,
+	EventPropertiesReplaced,
+	EventPageDetailUpdated,
+}
+
+// GetEventType returns the EventType of the given string, or an error if it isn't valid.
+func GetEventType(s string) (EventType, error) {
+	for _, eventType := range EventTypes {
+		if string(eventType) == s {
+			return eventType, nil
+		}
+	}
+	return "", errors.Errorf("invalid event type: %v", s)
+}
+
+// Webhook is a user's subscription to have events POSTed to its URL.
+type Webhook struct {
+	ID     int64       `json:"-"`
+	GUID   string      `json:"id"`
+	UserID string      `json:"-"`
+	URL    string      `json:"url"`
+	Events []EventType `json:"events"`
+	// Secret signs each delivery. It's only shown when the webhook is created.
+	Secret    string     `json:"secret,omitempty"`
+	CreatedAt *time.Time `json:"createdAt"`
+}
+
+// IsSubscribed returns whether the webhook receives the event type.
+func (w Webhook) IsSubscribed(eventType EventType) bool {
+	for _, e := range w.Events {
+		if e == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// DeliveryStatus is where a delivery is in being sent.
+type DeliveryStatus string
+
+// valid DeliveryStatus values.
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliveryDelivered DeliveryStatus = "delivered"
+	DeliveryFailed    DeliveryStatus = "failed"
+)
+
+// Delivery is a single event to send to a webhook, and the outcome of its attempts so far.
+type Delivery struct {
+	ID             int64          `json:"-"`
+	GUID           string         `json:"id"`
+	WebhookGUID    string         `json:"webhookId"`
+	Event          EventType      `json:"event"`
+	Payload        []byte         `json:"-"`
+	Status         DeliveryStatus `json:"status"`
+	Attempts       int            `json:"attempts"`
+	ResponseStatus int            `json:"responseStatus,omitempty"`
+	LastError      string         `json:"lastError,omitempty"`
+	NextAttemptAt  *time.Time     `json:"nextAttemptAt,omitempty"`
+	LastAttemptAt  *time.Time     `json:"lastAttemptAt,omitempty"`
+	DeliveredAt    *time.Time     `json:"deliveredAt,omitempty"`
+	CreatedAt      *time.Time     `json:"createdAt"`
+}
+
+// Event is the body POSTed to a webhook for a single change.
+// ID is the ID of the event in the outbox, so receivers can use it to ignore an event they've already had.
+type Event struct {
+	ID        string          `json:"id"`
+	Type      EventType       `json:"type"`
+	CreatedAt time.Time       `json:"createdAt"`
+	Data      json.RawMessage `json:"data"`
+}
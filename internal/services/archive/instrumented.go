@@ -0,0 +1,29 @@
+package archiveservice
+
+import (
+	"context"
+
+	"github.com/worlve/sp-service/internal/models/archive"
+	"github.com/worlve/sp-service/internal/services/instrumentation"
+)
+
+const serviceName = "archive"
+
+// InstrumentedArchiveService is a ArchiveService that records a span and counts the errors for each of its methods.
+type InstrumentedArchiveService struct {
+	ArchiveService
+}
+
+// Export see ArchiveService.Export
+func (s InstrumentedArchiveService) Export(ctx context.Context, params ExportParams) (archive.Archive, error) {
+	ctx, end := instrumentation.Start(ctx, serviceName, "Export")
+	result, err := s.ArchiveService.Export(ctx, params)
+	return result, end(err)
+}
+
+// Import see ArchiveService.Import
+func (s InstrumentedArchiveService) Import(ctx context.Context, params ImportParams) (ImportResult, error) {
+	ctx, end := instrumentation.Start(ctx, serviceName, "Import")
+	result, err := s.ArchiveService.Import(ctx, params)
+	return result, end(err)
+}
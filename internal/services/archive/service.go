@@ -0,0 +1,315 @@
+package archiveservice
+
+import (
+	"context"
+
+	"github.com/worlve/sp-service/internal/models/archive"
+	"github.com/worlve/sp-service/internal/models/event"
+	"github.com/worlve/sp-service/internal/models/page"
+	"github.com/worlve/sp-service/internal/models/pagedetail"
+	"github.com/worlve/sp-service/internal/models/pagetemplate"
+	"github.com/worlve/sp-service/internal/models/property"
+	"github.com/worlve/sp-service/internal/models/version"
+	eventservice "github.com/worlve/sp-service/internal/services/event"
+	"github.com/worlve/sp-service/internal/stores/store"
+	"github.com/worlve/sp-service/internal/stores/storeerror"
+	"github.com/worlve/sp-service/internal/util/auditlog"
+	"github.com/worlve/sp-service/internal/util/clock"
+	"github.com/worlve/sp-service/internal/util/logger"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// exportBatchSize is the number of pages requested from the store at a time while exporting.
+const exportBatchSize = 100
+
+// ArchiveService is the service for exporting and importing archives
+type ArchiveService struct {
+	PageStore         store.PageStore
+	PageDetailStore   store.PageDetailStore
+	PageTemplateStore store.PageTemplateStore
+	VersionStore      store.VersionStore
+	UserStore         store.UserStore
+	UnitOfWork        store.UnitOfWork
+	Clock             clock.Clock
+	// eventStore is the outbox of the unit of work the service is bound to.
+	eventStore store.EventStore
+}
+
+// ExportParams params for Export
+type ExportParams struct {
+	UserID string
+	// VersionID limits the export to the pages in that version when it's set.
+	VersionID string
+}
+
+// Export returns an archive of every page the user owns, along with the versions and page templates they use.
+// When params.VersionID is set only the pages in that version are exported.
+func (s ArchiveService) Export(ctx context.Context, params ExportParams) (archive.Archive, error) {
+	a := archive.Archive{
+		Manifest: archive.Manifest{
+			FormatVersion: archive.FormatVersion,
+			ExportedAt:    s.Clock.Now().UTC(),
+			OwnerID:       params.UserID,
+			VersionID:     params.VersionID,
+		},
+		Versions:      make([]version.Version, 0),
+		PageTemplates: make([]pagetemplate.PageTemplate, 0),
+		Pages:         make([]archive.Page, 0),
+	}
+	if params.VersionID != "" {
+		_, err := s.VersionStore.GetVersion(ctx, params.VersionID)
+		if err != nil {
+			return a, errors.Wrapf(err, "failed to get version to export: %+v", params)
+		}
+	}
+	var versionGUIDs, pageTemplateGUIDs []string
+	seen := make(map[string]bool)
+	nextBatchID := ""
+	for {
+		pages, _, thisNextBatchID, err := s.PageStore.GetPages(ctx, params.UserID, nextBatchID, exportBatchSize)
+		if err != nil {
+			return a, errors.Wrapf(err, "failed to get pages to export: %+v", params)
+		}
+		for _, p := range pages {
+			if params.VersionID != "" && p.Version.GUID != params.VersionID {
+				continue
+			}
+			archivePage, err := s.getArchivePage(ctx, p)
+			if err != nil {
+				return a, errors.Wrapf(err, "failed to export page: %+v", params)
+			}
+			a.Pages = append(a.Pages, archivePage)
+			if !seen[p.Version.GUID] {
+				seen[p.Version.GUID] = true
+				versionGUIDs = append(versionGUIDs, p.Version.GUID)
+			}
+			if !seen[p.PageTemplate.GUID] {
+				seen[p.PageTemplate.GUID] = true
+				pageTemplateGUIDs = append(pageTemplateGUIDs, p.PageTemplate.GUID)
+			}
+		}
+		if thisNextBatchID == "" {
+			break
+		}
+		nextBatchID = thisNextBatchID
+	}
+	for _, guid := range versionGUIDs {
+		v, err := s.VersionStore.GetVersion(ctx, guid)
+		if err != nil {
+			return a, errors.Wrapf(err, "failed to get version %v to export: %+v", guid, params)
+		}
+		a.Versions = append(a.Versions, v)
+	}
+	for _, guid := range pageTemplateGUIDs {
+		pt, err := s.PageTemplateStore.GetPageTemplate(ctx, guid)
+		if err != nil {
+			return a, errors.Wrapf(err, "failed to get page template %v to export: %+v", guid, params)
+		}
+		a.PageTemplates = append(a.PageTemplates, pt)
+	}
+	return a, nil
+}
+
+func (s ArchiveService) getArchivePage(ctx context.Context, p page.Page) (archive.Page, error) {
+	properties, err := s.PageStore.GetPageProperties(ctx, p.GUID)
+	if err != nil {
+		return archive.Page{}, err
+	}
+	if properties == nil {
+		properties = make([]property.Property, 0)
+	}
+	details, err := s.PageDetailStore.GetPageDetails(ctx, p.GUID)
+	if err != nil {
+		return archive.Page{}, err
+	}
+	if details == nil {
+		details = make([]pagedetail.PageDetail, 0)
+	}
+	return archive.Page{
+		GUID:           p.GUID,
+		VersionID:      p.Version.GUID,
+		PageTemplateID: p.PageTemplate.GUID,
+		Title:          p.Title,
+		Summary:        p.Summary,
+		PermissionType: p.PermissionType,
+		Properties:     properties,
+		Details:        details,
+	}, nil
+}
+
+// ConflictStrategy determines how a page is imported when its id already exists.
+type ConflictStrategy string
+
+// All the valid values for ConflictStrategy
+const (
+	// ConflictRemap gives the page a newly generated id.
+	ConflictRemap ConflictStrategy = "remap"
+	// ConflictSkip leaves the existing page as is and does not import the page.
+	ConflictSkip ConflictStrategy = "skip"
+	// ConflictFail fails the entire import.
+	ConflictFail ConflictStrategy = "fail"
+)
+
+// GetConflictStrategy returns the correct conflict strategy for the given string.
+func GetConflictStrategy(conflictStrategyString string) (ConflictStrategy, error) {
+	switch conflictStrategyString {
+	case string(ConflictRemap):
+		return ConflictRemap, nil
+	case string(ConflictSkip):
+		return ConflictSkip, nil
+	case string(ConflictFail):
+		return ConflictFail, nil
+	default:
+		return ConflictRemap, errors.Errorf("invalid conflict strategy %v", conflictStrategyString)
+	}
+}
+
+// ImportParams params for Import
+type ImportParams struct {
+	Archive  archive.Archive
+	OwnerID  string
+	Conflict ConflictStrategy
+}
+
+// ImportResult is the outcome of a successful import.
+type ImportResult struct {
+	// Pages maps each imported page's id in the archive to its id after the import.
+	Pages map[string]string `json:"pages"`
+	// Skipped are the ids of the pages in the archive that were not imported due to a conflict.
+	Skipped []string `json:"skipped"`
+}
+
+// Import creates every page in the archive under the owner, all within a single unit of work.
+// Page ids are kept when they are free and otherwise handled based on params.Conflict.
+// Relations between pages in the archive are remapped to the imported ids.
+func (s ArchiveService) Import(ctx context.Context, params ImportParams) (ImportResult, error) {
+	err := params.Archive.Validate()
+	if err != nil {
+		return ImportResult{}, err
+	}
+	var result ImportResult
+	var created []page.Page
+	err = s.UnitOfWork.Do(ctx, func(stores store.Stores) error {
+		var err error
+		result, created, err = s.withStores(stores).importArchive(ctx, params)
+		return err
+	})
+	if err != nil {
+		if _, ok := err.(*storeerror.DupEntry); ok {
+			return ImportResult{}, err
+		}
+		return ImportResult{}, errors.Wrapf(err, "failed to import archive for owner %v", params.OwnerID)
+	}
+	for _, p := range created {
+		auditlog.Record(ctx, auditlog.Change{TargetID: p.GUID, PageID: p.GUID, After: p.Reduce()})
+	}
+	logger.GetFromContext(ctx).Info("Imported archive",
+		zap.String("ownerId", params.OwnerID),
+		zap.Int("imported", len(result.Pages)),
+		zap.Int("skipped", len(result.Skipped)),
+		zap.String("conflict", string(params.Conflict)),
+	)
+	return result, nil
+}
+
+func (s ArchiveService) withStores(stores store.Stores) ArchiveService {
+	s.PageStore = stores.PageStore
+	s.PageDetailStore = stores.PageDetailStore
+	s.PageTemplateStore = stores.PageTemplateStore
+	s.VersionStore = stores.VersionStore
+	s.UserStore = stores.UserStore
+	s.eventStore = stores.EventStore
+	return s
+}
+
+// importArchive returns the pages it created along with the result.
+func (s ArchiveService) importArchive(ctx context.Context, params ImportParams) (ImportResult, []page.Page, error) {
+	result := ImportResult{
+		Pages:   make(map[string]string),
+		Skipped: make([]string, 0),
+	}
+	var pages []page.Page
+	owner, err := s.UserStore.GetUser(ctx, params.OwnerID)
+	if err != nil {
+		return result, nil, errors.Wrapf(err, "unable to get owner %v", params.OwnerID)
+	}
+	versions := make(map[string]version.Version)
+	pageTemplates := make(map[string]pagetemplate.PageTemplate)
+	for _, p := range params.Archive.Pages {
+		if _, ok := versions[p.VersionID]; !ok {
+			v, err := s.VersionStore.GetVersion(ctx, p.VersionID)
+			if err != nil {
+				return result, nil, errors.Wrapf(err, "unable to find version %v", p.VersionID)
+			}
+			versions[p.VersionID] = v
+		}
+		if _, ok := pageTemplates[p.PageTemplateID]; !ok {
+			pt, err := s.PageTemplateStore.GetPageTemplate(ctx, p.PageTemplateID)
+			if err != nil {
+				return result, nil, errors.Wrapf(err, "unable to find page template %v", p.PageTemplateID)
+			}
+			pageTemplates[p.PageTemplateID] = pt
+		}
+	}
+	// all ids need to be known before any page is created so relations can be remapped.
+	for _, p := range params.Archive.Pages {
+		guid, err := s.PageStore.GetUniquePageGUID(ctx, p.GUID)
+		if _, ok := err.(*storeerror.DupEntry); ok {
+			switch params.Conflict {
+			case ConflictSkip:
+				result.Skipped = append(result.Skipped, p.GUID)
+				continue
+			case ConflictFail:
+				return result, nil, err
+			default:
+				guid, err = s.PageStore.GetUniquePageGUID(ctx, "")
+			}
+		}
+		if err != nil {
+			return result, nil, errors.Wrapf(err, "unable to get a unique id for page %v", p.GUID)
+		}
+		result.Pages[p.GUID] = guid
+	}
+	for _, p := range params.Archive.Pages {
+		guid, ok := result.Pages[p.GUID]
+		if !ok {
+			continue
+		}
+		archive.RemapRelations(p.Details, result.Pages)
+		created, err := s.PageStore.CreatePage(ctx, page.Page{
+			GUID:           guid,
+			Title:          p.Title,
+			Summary:        p.Summary,
+			Version:        versions[p.VersionID],
+			PageTemplate:   pageTemplates[p.PageTemplateID],
+			PermissionType: p.PermissionType,
+		}, owner.ID)
+		if err != nil {
+			return result, nil, errors.Wrapf(err, "unable to create page %v", p.GUID)
+		}
+		err = eventservice.Emit(ctx, s.eventStore, event.PageCreated{PageID: guid, Page: created.Reduce()}, params.OwnerID)
+		if err != nil {
+			return result, nil, err
+		}
+		pages = append(pages, created)
+		if len(p.Details) > 0 {
+			err = s.PageDetailStore.ReplacePageDetails(ctx, guid, p.Details)
+			if err != nil {
+				return result, nil, errors.Wrapf(err, "unable to add details to page %v", p.GUID)
+			}
+		}
+		if len(p.Properties) == 0 {
+			continue
+		}
+		err = s.PageStore.ReplacePageProperties(ctx, guid, p.Properties)
+		if err != nil {
+			return result, nil, errors.Wrapf(err, "unable to add properties to page %v", p.GUID)
+		}
+		err = eventservice.Emit(ctx, s.eventStore, event.PropertiesReplaced{PageID: guid, Properties: p.Properties}, params.OwnerID)
+		if err != nil {
+			return result, nil, err
+		}
+	}
+	return result, pages, nil
+}
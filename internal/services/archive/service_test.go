@@ -0,0 +1,427 @@
+package archiveservice
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/worlve/sp-service/internal/models/appuser"
+	"github.com/worlve/sp-service/internal/models/archive"
+	"github.com/worlve/sp-service/internal/models/event"
+	"github.com/worlve/sp-service/internal/models/page"
+	"github.com/worlve/sp-service/internal/models/pagedetail"
+	"github.com/worlve/sp-service/internal/models/pagetemplate"
+	"github.com/worlve/sp-service/internal/models/permission"
+	"github.com/worlve/sp-service/internal/models/property"
+	"github.com/worlve/sp-service/internal/models/version"
+	"github.com/worlve/sp-service/internal/stores/memorystore"
+	"github.com/worlve/sp-service/internal/stores/store"
+	"github.com/worlve/sp-service/internal/stores/store/mocks"
+	"github.com/worlve/sp-service/internal/stores/storeerror"
+	"github.com/worlve/sp-service/internal/util/clock"
+	"github.com/worlve/sp-service/internal/util/testutils"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var now = time.Date(2020, 3, 31, 12, 0, 0, 0, time.UTC)
+
+type getPagesCall struct {
+	paramNextBatchID  string
+	returnPages       []page.Page
+	returnNextBatchID string
+	returnErr         error
+}
+
+type getPagePropertiesCall struct {
+	paramPageGUID    string
+	returnProperties []property.Property
+}
+
+type getPageDetailsCall struct {
+	paramPageGUID string
+	returnDetails []pagedetail.PageDetail
+}
+
+func TestExport(t *testing.T) {
+	cases := []struct {
+		name                   string
+		params                 ExportParams
+		getPagesCalls          []getPagesCall
+		getPagePropertiesCalls []getPagePropertiesCall
+		getPageDetailsCalls    []getPageDetailsCall
+		getVersionErr          error
+		returnArchive          archive.Archive
+		returnErr              error
+	}{
+		{
+			name:   "test happy path, multiple batches",
+			params: ExportParams{UserID: "UR_1"},
+			getPagesCalls: []getPagesCall{
+				{
+					returnPages: []page.Page{
+						{GUID: "PG_1", Title: "title 1", Version: version.Version{GUID: "VR_1"}, PageTemplate: pagetemplate.PageTemplate{GUID: "PGT_1"}, PermissionType: permission.TypePrivate},
+					},
+					returnNextBatchID: "PG_2",
+				},
+				{
+					paramNextBatchID: "PG_2",
+					returnPages: []page.Page{
+						{GUID: "PG_2", Title: "title 2", Version: version.Version{GUID: "VR_1"}, PageTemplate: pagetemplate.PageTemplate{GUID: "PGT_1"}, PermissionType: permission.TypePublic},
+					},
+				},
+			},
+			getPagePropertiesCalls: []getPagePropertiesCall{
+				{paramPageGUID: "PG_1", returnProperties: []property.Property{{Key: "color", Type: property.TypeString, Value: "blue"}}},
+				{paramPageGUID: "PG_2"},
+			},
+			getPageDetailsCalls: []getPageDetailsCall{
+				{paramPageGUID: "PG_1", returnDetails: []pagedetail.PageDetail{{GUID: "DT_1", Title: "history", Partitions: []pagedetail.Partition{{TypeString: "relation", Relation: "PG_2"}}}}},
+				{paramPageGUID: "PG_2"},
+			},
+			returnArchive: archive.Archive{
+				Manifest:      archive.Manifest{FormatVersion: archive.FormatVersion, ExportedAt: now, OwnerID: "UR_1"},
+				Versions:      []version.Version{{GUID: "VR_1", Name: "Default"}},
+				PageTemplates: []pagetemplate.PageTemplate{{GUID: "PGT_1", Name: "Place"}},
+				Pages: []archive.Page{
+					{GUID: "PG_1", VersionID: "VR_1", PageTemplateID: "PGT_1", Title: "title 1", PermissionType: permission.TypePrivate, Properties: []property.Property{{Key: "color", Type: property.TypeString, Value: "blue"}}, Details: []pagedetail.PageDetail{{GUID: "DT_1", Title: "history", Partitions: []pagedetail.Partition{{TypeString: "relation", Relation: "PG_2"}}}}},
+					{GUID: "PG_2", VersionID: "VR_1", PageTemplateID: "PGT_1", Title: "title 2", PermissionType: permission.TypePublic, Properties: []property.Property{}, Details: []pagedetail.PageDetail{}},
+				},
+			},
+		},
+		{
+			name:   "test happy path, limited to a version",
+			params: ExportParams{UserID: "UR_1", VersionID: "VR_1"},
+			getPagesCalls: []getPagesCall{
+				{
+					returnPages: []page.Page{
+						{GUID: "PG_1", Title: "title 1", Version: version.Version{GUID: "VR_1"}, PageTemplate: pagetemplate.PageTemplate{GUID: "PGT_1"}, PermissionType: permission.TypePrivate},
+						{GUID: "PG_2", Title: "title 2", Version: version.Version{GUID: "VR_2"}, PageTemplate: pagetemplate.PageTemplate{GUID: "PGT_2"}, PermissionType: permission.TypePrivate},
+					},
+				},
+			},
+			getPagePropertiesCalls: []getPagePropertiesCall{
+				{paramPageGUID: "PG_1"},
+			},
+			getPageDetailsCalls: []getPageDetailsCall{
+				{paramPageGUID: "PG_1"},
+			},
+			returnArchive: archive.Archive{
+				Manifest:      archive.Manifest{FormatVersion: archive.FormatVersion, ExportedAt: now, OwnerID: "UR_1", VersionID: "VR_1"},
+				Versions:      []version.Version{{GUID: "VR_1", Name: "Default"}},
+				PageTemplates: []pagetemplate.PageTemplate{{GUID: "PGT_1", Name: "Place"}},
+				Pages: []archive.Page{
+					{GUID: "PG_1", VersionID: "VR_1", PageTemplateID: "PGT_1", Title: "title 1", PermissionType: permission.TypePrivate, Properties: []property.Property{}, Details: []pagedetail.PageDetail{}},
+				},
+			},
+		},
+		{
+			name:          "test missing version",
+			params:        ExportParams{UserID: "UR_1", VersionID: "VR_1"},
+			getVersionErr: &storeerror.NotFound{ID: "VR_1"},
+			returnErr:     errors.New("failed to get version to export: {UserID:UR_1 VersionID:VR_1}: Could not find: VR_1"),
+		},
+		{
+			name:   "test failed to get pages",
+			params: ExportParams{UserID: "UR_1"},
+			getPagesCalls: []getPagesCall{
+				{returnErr: errors.New("failed")},
+			},
+			returnErr: errors.New("failed to get pages to export: {UserID:UR_1 VersionID:}: failed"),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			pageStore := new(mocks.PageStore)
+			pageDetailStore := new(mocks.PageDetailStore)
+			pageTemplateStore := new(mocks.PageTemplateStore)
+			versionStore := new(mocks.VersionStore)
+			for index := range tc.getPagesCalls {
+				pageStore.On("GetPages", mock.Anything, tc.params.UserID, tc.getPagesCalls[index].paramNextBatchID, exportBatchSize).Return(tc.getPagesCalls[index].returnPages, 0, tc.getPagesCalls[index].returnNextBatchID, tc.getPagesCalls[index].returnErr)
+			}
+			for index := range tc.getPagePropertiesCalls {
+				pageStore.On("GetPageProperties", mock.Anything, tc.getPagePropertiesCalls[index].paramPageGUID).Return(tc.getPagePropertiesCalls[index].returnProperties, nil)
+			}
+			for index := range tc.getPageDetailsCalls {
+				pageDetailStore.On("GetPageDetails", mock.Anything, tc.getPageDetailsCalls[index].paramPageGUID).Return(tc.getPageDetailsCalls[index].returnDetails, nil)
+			}
+			versionStore.On("GetVersion", mock.Anything, "VR_1").Return(version.Version{GUID: "VR_1", Name: "Default"}, tc.getVersionErr)
+			pageTemplateStore.On("GetPageTemplate", mock.Anything, "PGT_1").Return(pagetemplate.PageTemplate{GUID: "PGT_1", Name: "Place"}, nil)
+			archiveService := ArchiveService{
+				PageStore:         pageStore,
+				PageDetailStore:   pageDetailStore,
+				PageTemplateStore: pageTemplateStore,
+				VersionStore:      versionStore,
+				Clock:             clock.MockClock{MockedTime: &now},
+			}
+			result, err := archiveService.Export(context.Background(), tc.params)
+			pageStore.AssertNumberOfCalls(t, "GetPages", len(tc.getPagesCalls))
+			pageStore.AssertNumberOfCalls(t, "GetPageProperties", len(tc.getPagePropertiesCalls))
+			pageDetailStore.AssertNumberOfCalls(t, "GetPageDetails", len(tc.getPageDetailsCalls))
+			errExpected := testutils.TestErrorAgainstCase(t, err, tc.returnErr)
+			if errExpected {
+				return
+			}
+			require.Equal(t, tc.returnArchive, result)
+		})
+	}
+}
+
+type getUniquePageGUIDCall struct {
+	paramProposedGUID string
+	returnGUID        string
+	returnErr         error
+}
+
+type createPageCall struct {
+	paramPage page.Page
+}
+
+type replacePagePropertiesCall struct {
+	paramPageGUID   string
+	paramProperties []property.Property
+}
+
+type replacePageDetailsCall struct {
+	paramPageGUID string
+	paramDetails  []pagedetail.PageDetail
+}
+
+func getImportArchive() archive.Archive {
+	return archive.Archive{
+		Manifest: archive.Manifest{FormatVersion: archive.FormatVersion, OwnerID: "UR_OTHER"},
+		Pages: []archive.Page{
+			{
+				GUID: "PG_111111111111", VersionID: "VR_1", PageTemplateID: "PGT_1", Title: "title 1", PermissionType: permission.TypePrivate,
+				Properties: []property.Property{{Key: "color", Type: property.TypeString, Value: "blue"}},
+				Details:    []pagedetail.PageDetail{{GUID: "DT_1", Partitions: []pagedetail.Partition{{TypeString: "relation", Relation: "PG_222222222222"}}}},
+			},
+			{
+				GUID: "PG_222222222222", VersionID: "VR_1", PageTemplateID: "PGT_1", Title: "title 2", PermissionType: permission.TypePublic,
+			},
+		},
+	}
+}
+
+func TestImport(t *testing.T) {
+	dupErr := &storeerror.DupEntry{ID: "PG_222222222222"}
+	cases := []struct {
+		name                       string
+		params                     ImportParams
+		getVersionErr              error
+		getUniquePageGUIDCalls     []getUniquePageGUIDCall
+		createPageCalls            []createPageCall
+		replacePagePropertiesCalls []replacePagePropertiesCall
+		replacePageDetailsCalls    []replacePageDetailsCall
+		returnResult               ImportResult
+		returnErr                  error
+	}{
+		{
+			name:   "test happy path, remaps conflicting ids and relations",
+			params: ImportParams{Archive: getImportArchive(), OwnerID: "UR_1", Conflict: ConflictRemap},
+			getUniquePageGUIDCalls: []getUniquePageGUIDCall{
+				{paramProposedGUID: "PG_111111111111", returnGUID: "PG_111111111111"},
+				{paramProposedGUID: "PG_222222222222", returnErr: dupErr},
+				{paramProposedGUID: "", returnGUID: "PG_333333333333"},
+			},
+			createPageCalls: []createPageCall{
+				{paramPage: page.Page{
+					GUID: "PG_111111111111", Title: "title 1", PermissionType: permission.TypePrivate,
+					Version: version.Version{ID: 1, GUID: "VR_1"}, PageTemplate: pagetemplate.PageTemplate{ID: 2, GUID: "PGT_1"},
+				}},
+				{paramPage: page.Page{
+					GUID: "PG_333333333333", Title: "title 2", PermissionType: permission.TypePublic,
+					Version: version.Version{ID: 1, GUID: "VR_1"}, PageTemplate: pagetemplate.PageTemplate{ID: 2, GUID: "PGT_1"},
+				}},
+			},
+			replacePagePropertiesCalls: []replacePagePropertiesCall{
+				{paramPageGUID: "PG_111111111111", paramProperties: []property.Property{{Key: "color", Type: property.TypeString, Value: "blue"}}},
+			},
+			replacePageDetailsCalls: []replacePageDetailsCall{
+				{paramPageGUID: "PG_111111111111", paramDetails: []pagedetail.PageDetail{{GUID: "DT_1", Partitions: []pagedetail.Partition{{TypeString: "relation", Relation: "PG_333333333333"}}}}},
+			},
+			returnResult: ImportResult{
+				Pages:   map[string]string{"PG_111111111111": "PG_111111111111", "PG_222222222222": "PG_333333333333"},
+				Skipped: []string{},
+			},
+		},
+		{
+			name:   "test skips conflicting ids",
+			params: ImportParams{Archive: getImportArchive(), OwnerID: "UR_1", Conflict: ConflictSkip},
+			getUniquePageGUIDCalls: []getUniquePageGUIDCall{
+				{paramProposedGUID: "PG_111111111111", returnGUID: "PG_111111111111"},
+				{paramProposedGUID: "PG_222222222222", returnErr: dupErr},
+			},
+			createPageCalls: []createPageCall{
+				{paramPage: page.Page{
+					GUID: "PG_111111111111", Title: "title 1", PermissionType: permission.TypePrivate,
+					Version: version.Version{ID: 1, GUID: "VR_1"}, PageTemplate: pagetemplate.PageTemplate{ID: 2, GUID: "PGT_1"},
+				}},
+			},
+			replacePagePropertiesCalls: []replacePagePropertiesCall{
+				{paramPageGUID: "PG_111111111111", paramProperties: []property.Property{{Key: "color", Type: property.TypeString, Value: "blue"}}},
+			},
+			replacePageDetailsCalls: []replacePageDetailsCall{
+				{paramPageGUID: "PG_111111111111", paramDetails: []pagedetail.PageDetail{{GUID: "DT_1", Partitions: []pagedetail.Partition{{TypeString: "relation", Relation: "PG_222222222222"}}}}},
+			},
+			returnResult: ImportResult{
+				Pages:   map[string]string{"PG_111111111111": "PG_111111111111"},
+				Skipped: []string{"PG_222222222222"},
+			},
+		},
+		{
+			name:   "test fails on conflicting ids",
+			params: ImportParams{Archive: getImportArchive(), OwnerID: "UR_1", Conflict: ConflictFail},
+			getUniquePageGUIDCalls: []getUniquePageGUIDCall{
+				{paramProposedGUID: "PG_111111111111", returnGUID: "PG_111111111111"},
+				{paramProposedGUID: "PG_222222222222", returnErr: dupErr},
+			},
+			returnErr: dupErr,
+		},
+		{
+			name:          "test missing version",
+			params:        ImportParams{Archive: getImportArchive(), OwnerID: "UR_1", Conflict: ConflictRemap},
+			getVersionErr: &storeerror.NotFound{ID: "VR_1"},
+			returnErr:     errors.New("failed to import archive for owner UR_1: unable to find version VR_1: Could not find: VR_1"),
+		},
+		{
+			name:      "test invalid archive",
+			params:    ImportParams{Archive: archive.Archive{}, OwnerID: "UR_1"},
+			returnErr: errors.New("archive is missing its manifest"),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			pageStore := new(mocks.PageStore)
+			pageDetailStore := new(mocks.PageDetailStore)
+			pageTemplateStore := new(mocks.PageTemplateStore)
+			versionStore := new(mocks.VersionStore)
+			userStore := new(mocks.UserStore)
+			eventStore := new(mocks.EventStore)
+			eventStore.On("CreateEvent", mock.Anything, mock.Anything).Return(event.Event{}, nil)
+			unitOfWork := new(mocks.UnitOfWork)
+			unitOfWork.On("Do", mock.Anything, mock.Anything).Return(func(ctx context.Context, fn func(store.Stores) error) error {
+				return fn(store.Stores{
+					PageStore:         pageStore,
+					PageDetailStore:   pageDetailStore,
+					PageTemplateStore: pageTemplateStore,
+					VersionStore:      versionStore,
+					UserStore:         userStore,
+					EventStore:        eventStore,
+				})
+			})
+			userStore.On("GetUser", mock.Anything, "UR_1").Return(appuser.User{ID: 3, GUID: "UR_1"}, nil)
+			versionStore.On("GetVersion", mock.Anything, "VR_1").Return(version.Version{ID: 1, GUID: "VR_1"}, tc.getVersionErr)
+			pageTemplateStore.On("GetPageTemplate", mock.Anything, "PGT_1").Return(pagetemplate.PageTemplate{ID: 2, GUID: "PGT_1"}, nil)
+			for index := range tc.getUniquePageGUIDCalls {
+				pageStore.On("GetUniquePageGUID", mock.Anything, tc.getUniquePageGUIDCalls[index].paramProposedGUID).Return(tc.getUniquePageGUIDCalls[index].returnGUID, tc.getUniquePageGUIDCalls[index].returnErr)
+			}
+			for index := range tc.createPageCalls {
+				pageStore.On("CreatePage", mock.Anything, tc.createPageCalls[index].paramPage, int64(3)).Return(tc.createPageCalls[index].paramPage, nil)
+			}
+			for index := range tc.replacePagePropertiesCalls {
+				pageStore.On("ReplacePageProperties", mock.Anything, tc.replacePagePropertiesCalls[index].paramPageGUID, tc.replacePagePropertiesCalls[index].paramProperties).Return(nil)
+			}
+			for index := range tc.replacePageDetailsCalls {
+				pageDetailStore.On("ReplacePageDetails", mock.Anything, tc.replacePageDetailsCalls[index].paramPageGUID, tc.replacePageDetailsCalls[index].paramDetails).Return(nil)
+			}
+			archiveService := ArchiveService{
+				UnitOfWork: unitOfWork,
+			}
+			result, err := archiveService.Import(context.Background(), tc.params)
+			pageStore.AssertNumberOfCalls(t, "GetUniquePageGUID", len(tc.getUniquePageGUIDCalls))
+			pageStore.AssertNumberOfCalls(t, "CreatePage", len(tc.createPageCalls))
+			pageStore.AssertNumberOfCalls(t, "ReplacePageProperties", len(tc.replacePagePropertiesCalls))
+			pageDetailStore.AssertNumberOfCalls(t, "ReplacePageDetails", len(tc.replacePageDetailsCalls))
+			eventStore.AssertNumberOfCalls(t, "CreateEvent", len(tc.createPageCalls)+len(tc.replacePagePropertiesCalls))
+			errExpected := testutils.TestErrorAgainstCase(t, err, tc.returnErr)
+			if errExpected {
+				return
+			}
+			require.Equal(t, tc.returnResult, result)
+		})
+	}
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	memdb := memorystore.NewDB()
+	owner := memdb.AddUser(appuser.User{GUID: "UR_1"})
+	memdb.AddUser(appuser.User{GUID: "UR_2"})
+	firstVersion := memdb.AddVersion(version.Version{GUID: "VR_1", Name: "first version"})
+	secondVersion := memdb.AddVersion(version.Version{GUID: "VR_2", Name: "second version"})
+	pt := memdb.AddPageTemplate(pagetemplate.PageTemplate{GUID: "PGT_1", Name: "Place"})
+	memdb.AddProperty(property.Property{Key: "color", Type: property.TypeString})
+	archiveService := ArchiveService{
+		PageStore:         memorystore.NewPageStore(memdb),
+		PageDetailStore:   memorystore.NewPageDetailStore(memdb),
+		PageTemplateStore: memorystore.NewPageTemplateStore(memdb),
+		VersionStore:      memorystore.NewVersionStore(memdb),
+		UserStore:         memorystore.NewUserStore(memdb),
+		UnitOfWork:        memorystore.NewUnitOfWork(memdb),
+		Clock:             clock.MockClock{MockedTime: &now},
+	}
+	for _, p := range []page.Page{
+		{GUID: "PG_111111111111", Title: "capital", Version: firstVersion, PageTemplate: pt, PermissionType: permission.TypePrivate},
+		{GUID: "PG_222222222222", Title: "kingdom", Version: firstVersion, PageTemplate: pt, PermissionType: permission.TypePublic},
+		{GUID: "PG_333333333333", Title: "ruins", Version: secondVersion, PageTemplate: pt, PermissionType: permission.TypePrivate},
+	} {
+		_, err := archiveService.PageStore.CreatePage(ctx, p, owner.ID)
+		require.NoError(t, err)
+	}
+	err := archiveService.PageDetailStore.ReplacePageDetails(ctx, "PG_111111111111", []pagedetail.PageDetail{
+		{GUID: "DT_1", Title: "history", Summary: "long ago", Partitions: []pagedetail.Partition{
+			{TypeString: "p", Partitions: []pagedetail.Partition{
+				{TypeString: "text", Value: "capital of "},
+				{TypeString: "relation", Value: "the kingdom", Relation: "PG_222222222222"},
+			}},
+		}},
+		{GUID: "DT_2", Title: "people"},
+	})
+	require.NoError(t, err)
+	err = archiveService.PageStore.ReplacePageProperties(ctx, "PG_111111111111", []property.Property{{Key: "color", Type: property.TypeString, Value: "blue"}})
+	require.NoError(t, err)
+
+	exported, err := archiveService.Export(ctx, ExportParams{UserID: "UR_1", VersionID: "VR_1"})
+	require.NoError(t, err)
+	require.Len(t, exported.Pages, 2)
+	var body bytes.Buffer
+	err = archive.Encode(&body, exported, archive.FormatNDJSON)
+	require.NoError(t, err)
+	decoded, err := archive.Decode(&body, archive.FormatNDJSON)
+	require.NoError(t, err)
+
+	// the pages already exist, so importing them again gives them new ids
+	result, err := archiveService.Import(ctx, ImportParams{Archive: decoded, OwnerID: "UR_2", Conflict: ConflictRemap})
+	require.NoError(t, err)
+	require.Len(t, result.Pages, 2)
+	capitalGUID := result.Pages["PG_111111111111"]
+	kingdomGUID := result.Pages["PG_222222222222"]
+	require.NotEqual(t, "PG_111111111111", capitalGUID)
+	require.NotEqual(t, "PG_222222222222", kingdomGUID)
+
+	imported, err := archiveService.Export(ctx, ExportParams{UserID: "UR_2"})
+	require.NoError(t, err)
+	pages := make(map[string]archive.Page)
+	for _, p := range imported.Pages {
+		pages[p.GUID] = p
+	}
+	require.Len(t, pages, 2)
+	capital := pages[capitalGUID]
+	require.Equal(t, "capital", capital.Title)
+	require.Equal(t, "VR_1", capital.VersionID)
+	require.Equal(t, []property.Property{{ID: capital.Properties[0].ID, Key: "color", Type: property.TypeString, Value: "blue"}}, capital.Properties)
+	require.Equal(t, []pagedetail.PageDetail{
+		{ID: capital.Details[0].ID, GUID: "DT_1", Title: "history", Summary: "long ago", Partitions: []pagedetail.Partition{
+			{Type: pagedetail.PartitionTypeParagraph, TypeString: "p", Partitions: []pagedetail.Partition{
+				{Type: pagedetail.PartitionTypeText, TypeString: "text", Value: "capital of "},
+				{Type: pagedetail.PartitionTypeRelation, TypeString: "relation", Value: "the kingdom", Relation: kingdomGUID},
+			}},
+		}},
+		{ID: capital.Details[1].ID, GUID: "DT_2", Title: "people", Partitions: []pagedetail.Partition{}},
+	}, capital.Details)
+	require.Equal(t, "kingdom", pages[kingdomGUID].Title)
+	require.Equal(t, []pagedetail.PageDetail{}, pages[kingdomGUID].Details)
+}
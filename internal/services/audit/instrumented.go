@@ -0,0 +1,42 @@
+package auditservice
+
+import (
+	"context"
+
+	"github.com/worlve/sp-service/internal/models/audit"
+	"github.com/worlve/sp-service/internal/services/instrumentation"
+)
+
+const serviceName = "audit"
+
+// InstrumentedAuditService is an AuditService that records a span and counts the errors for each of its methods.
+type InstrumentedAuditService struct {
+	AuditService
+}
+
+// Record see AuditService.Record
+func (s InstrumentedAuditService) Record(ctx context.Context, params RecordParams) error {
+	ctx, end := instrumentation.Start(ctx, serviceName, "Record")
+	return end(s.AuditService.Record(ctx, params))
+}
+
+// GetPageEntries see AuditService.GetPageEntries
+func (s InstrumentedAuditService) GetPageEntries(ctx context.Context, params GetPageEntriesParams) ([]audit.Entry, int, string, error) {
+	ctx, end := instrumentation.Start(ctx, serviceName, "GetPageEntries")
+	results, total, nextBatchID, err := s.AuditService.GetPageEntries(ctx, params)
+	return results, total, nextBatchID, end(err)
+}
+
+// GetUserEntries see AuditService.GetUserEntries
+func (s InstrumentedAuditService) GetUserEntries(ctx context.Context, params GetUserEntriesParams) ([]audit.Entry, int, string, error) {
+	ctx, end := instrumentation.Start(ctx, serviceName, "GetUserEntries")
+	results, total, nextBatchID, err := s.AuditService.GetUserEntries(ctx, params)
+	return results, total, nextBatchID, end(err)
+}
+
+// GetEntries see AuditService.GetEntries
+func (s InstrumentedAuditService) GetEntries(ctx context.Context, params GetEntriesParams) ([]audit.Entry, int, string, error) {
+	ctx, end := instrumentation.Start(ctx, serviceName, "GetEntries")
+	results, total, nextBatchID, err := s.AuditService.GetEntries(ctx, params)
+	return results, total, nextBatchID, end(err)
+}
@@ -0,0 +1,146 @@
+package auditservice
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/worlve/sp-service/internal/models/audit"
+	"github.com/worlve/sp-service/internal/models/page"
+	"github.com/worlve/sp-service/internal/stores/store"
+	"github.com/worlve/sp-service/internal/stores/storeerror"
+	"github.com/worlve/sp-service/internal/util/auditlog"
+	"github.com/worlve/sp-service/internal/util/guidgen"
+	"github.com/pkg/errors"
+)
+
+// batchSize is the number of entries returned in each batch of a feed.
+const batchSize = 20
+
+// AuditService is the service for recording and reading the audit log
+type AuditService struct {
+	AuditStore store.AuditStore
+	PageStore  store.PageStore
+}
+
+// RecordParams params for Record
+type RecordParams struct {
+	ActorType audit.ActorType
+	UserID    string
+	Method    string
+	Route     string
+	// TargetID and PageID are taken from the route, for when the call didn't record any changes.
+	TargetID  string
+	PageID    string
+	Status    int
+	RequestID string
+	Changes   []auditlog.Change
+}
+
+// Record adds the entries for a mutating call to the audit log: one for each change it made,
+// or a single entry when it didn't make any. The changes of a failed call were rolled back, so they're left out.
+func (s AuditService) Record(ctx context.Context, params RecordParams) error {
+	changes := params.Changes
+	if len(changes) == 0 || params.Status >= http.StatusBadRequest {
+		changes = []auditlog.Change{{TargetID: params.TargetID, PageID: params.PageID}}
+	}
+	entries := make([]audit.Entry, 0, len(changes))
+	for _, change := range changes {
+		before, err := summarize(change.Before)
+		if err != nil {
+			return errors.Wrapf(err, "failed to summarize before of: %v", change.TargetID)
+		}
+		after, err := summarize(change.After)
+		if err != nil {
+			return errors.Wrapf(err, "failed to summarize after of: %v", change.TargetID)
+		}
+		entries = append(entries, audit.Entry{
+			GUID:      guidgen.GenerateGUID("AE", 24),
+			ActorType: params.ActorType,
+			UserID:    params.UserID,
+			Method:    params.Method,
+			Route:     params.Route,
+			TargetID:  change.TargetID,
+			PageID:    change.PageID,
+			Status:    params.Status,
+			Before:    before,
+			After:     after,
+			RequestID: params.RequestID,
+		})
+	}
+	err := s.AuditStore.CreateEntries(ctx, entries)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create audit entries for request: %v", params.RequestID)
+	}
+	return nil
+}
+
+func summarize(v interface{}) (json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return json.Marshal(v)
+}
+
+// GetPageEntriesParams params for GetPageEntries
+type GetPageEntriesParams struct {
+	Page        page.Page
+	Filter      audit.Filter
+	NextBatchID string
+	UserID      string
+}
+
+// GetPageEntries returns the page's activity feed, newest first, if the user can read the page.
+func (s AuditService) GetPageEntries(ctx context.Context, params GetPageEntriesParams) ([]audit.Entry, int, string, error) {
+	canRead, err := s.PageStore.CanReadPage(ctx, params.Page.GUID, params.UserID)
+	if err != nil {
+		return nil, 0, "", errors.Wrapf(err, "failed to check page access: %+v", params)
+	}
+	if !canRead {
+		return nil, 0, "", &storeerror.NotAuthorized{
+			UserID:  params.UserID,
+			TableID: params.Page.GUID,
+		}
+	}
+	filter := params.Filter
+	filter.PageID = params.Page.GUID
+	return s.getEntries(ctx, filter, params.NextBatchID)
+}
+
+// GetUserEntriesParams params for GetUserEntries
+type GetUserEntriesParams struct {
+	Filter      audit.Filter
+	NextBatchID string
+	UserID      string
+}
+
+// GetUserEntries returns the activity feed of the calls the user made, newest first.
+// An admin acting as itself has no UserID, so its feed is the calls made as an admin.
+func (s AuditService) GetUserEntries(ctx context.Context, params GetUserEntriesParams) ([]audit.Entry, int, string, error) {
+	filter := params.Filter
+	filter.UserID = params.UserID
+	if params.UserID == "" {
+		filter.ActorType = audit.ActorAdmin
+	}
+	return s.getEntries(ctx, filter, params.NextBatchID)
+}
+
+// GetEntriesParams params for GetEntries
+type GetEntriesParams struct {
+	Filter      audit.Filter
+	NextBatchID string
+}
+
+// GetEntries returns the entries of the whole audit log within the filter, newest first.
+// It isn't limited to any user, so it's only for admins.
+func (s AuditService) GetEntries(ctx context.Context, params GetEntriesParams) ([]audit.Entry, int, string, error) {
+	return s.getEntries(ctx, params.Filter, params.NextBatchID)
+}
+
+func (s AuditService) getEntries(ctx context.Context, filter audit.Filter, nextBatchID string) ([]audit.Entry, int, string, error) {
+	entries, total, nextBatchID, err := s.AuditStore.GetEntries(ctx, filter, nextBatchID, batchSize)
+	if err != nil {
+		return entries, total, nextBatchID, errors.Wrapf(err, "failed to get audit entries: %+v", filter)
+	}
+	return entries, total, nextBatchID, nil
+}
@@ -0,0 +1,166 @@
+package auditservice
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/worlve/sp-service/internal/models/audit"
+	"github.com/worlve/sp-service/internal/models/page"
+	"github.com/worlve/sp-service/internal/stores/store/mocks"
+	"github.com/worlve/sp-service/internal/util/auditlog"
+	"github.com/worlve/sp-service/internal/util/testutils"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecord(t *testing.T) {
+	cases := []struct {
+		name          string
+		params        RecordParams
+		createErr     error
+		returnEntries []audit.Entry
+		returnErr     error
+	}{
+		{
+			name: "test an entry for each change",
+			params: RecordParams{
+				ActorType: audit.ActorProxyUser, UserID: "UR_1", Method: "PUT", Route: "/api/pages/:pageID", TargetID: "PG_1", PageID: "PG_1", Status: 200, RequestID: "RQ_1",
+				Changes: []auditlog.Change{
+					{TargetID: "PG_1", PageID: "PG_1", Before: map[string]string{"title": "a"}, After: map[string]string{"title": "b"}},
+					{TargetID: "PD_1", PageID: "PG_1", After: "detail"},
+				},
+			},
+			returnEntries: []audit.Entry{
+				{ActorType: audit.ActorProxyUser, UserID: "UR_1", Method: "PUT", Route: "/api/pages/:pageID", TargetID: "PG_1", PageID: "PG_1", Status: 200, Before: []byte(`{"title":"a"}`), After: []byte(`{"title":"b"}`), RequestID: "RQ_1"},
+				{ActorType: audit.ActorProxyUser, UserID: "UR_1", Method: "PUT", Route: "/api/pages/:pageID", TargetID: "PD_1", PageID: "PG_1", Status: 200, After: []byte(`"detail"`), RequestID: "RQ_1"},
+			},
+		},
+		{
+			name:   "test a single entry from the route without changes",
+			params: RecordParams{ActorType: audit.ActorAdmin, Method: "DELETE", Route: "/api/webhooks/:webhookID", TargetID: "WH_1", Status: 204, RequestID: "RQ_1"},
+			returnEntries: []audit.Entry{
+				{ActorType: audit.ActorAdmin, Method: "DELETE", Route: "/api/webhooks/:webhookID", TargetID: "WH_1", Status: 204, RequestID: "RQ_1"},
+			},
+		},
+		{
+			name: "test the changes of a failed call are left out",
+			params: RecordParams{
+				ActorType: audit.ActorProxyUser, UserID: "UR_1", Method: "POST", Route: "/api/pages:action", Status: 400, RequestID: "RQ_1",
+				Changes: []auditlog.Change{{TargetID: "PG_1", PageID: "PG_1", After: "page"}},
+			},
+			returnEntries: []audit.Entry{
+				{ActorType: audit.ActorProxyUser, UserID: "UR_1", Method: "POST", Route: "/api/pages:action", Status: 400, RequestID: "RQ_1"},
+			},
+		},
+		{
+			name:      "test failed to create",
+			params:    RecordParams{ActorType: audit.ActorAdmin, Method: "POST", Route: "/api/pages", Status: 201, RequestID: "RQ_1"},
+			createErr: errors.New("failed"),
+			returnErr: errors.New("failed to create audit entries for request: RQ_1: failed"),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var created []audit.Entry
+			auditStore := new(mocks.AuditStore)
+			auditStore.On("CreateEntries", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				created = args.Get(1).([]audit.Entry)
+			}).Return(tc.createErr)
+			auditService := AuditService{AuditStore: auditStore}
+			err := auditService.Record(context.Background(), tc.params)
+			errExpected := testutils.TestErrorAgainstCase(t, err, tc.returnErr)
+			if errExpected {
+				return
+			}
+			for i := range created {
+				require.Regexp(t, "^AE_", created[i].GUID)
+				created[i].GUID = ""
+			}
+			require.Equal(t, tc.returnEntries, created)
+		})
+	}
+}
+
+func TestGetPageEntries(t *testing.T) {
+	cases := []struct {
+		name       string
+		params     GetPageEntriesParams
+		canRead    bool
+		canReadErr error
+		returnErr  error
+	}{
+		{
+			name:    "test happy path",
+			params:  GetPageEntriesParams{Page: page.Page{GUID: "PG_1"}, Filter: audit.Filter{PageID: "PG_2", ActorType: audit.ActorAdmin}, NextBatchID: "AE_1", UserID: "UR_2"},
+			canRead: true,
+		},
+		{
+			name:      "test someone else's private page",
+			params:    GetPageEntriesParams{Page: page.Page{GUID: "PG_1"}, UserID: "UR_2"},
+			returnErr: errors.New("User UR_2 is not authorized to perform the action on the ID PG_1"),
+		},
+		{
+			name:       "test failed to check access",
+			params:     GetPageEntriesParams{Page: page.Page{GUID: "PG_1"}, UserID: "UR_2"},
+			canReadErr: errors.New("failed"),
+			returnErr:  errors.New("failed to check page access: {Page:{ID:0 Version:{ID:0 GUID: Name: ParentGUID:} PageTemplate:{ID:0 Name: GUID:} GUID:PG_1 Title: Summary: PermissionType: PageProperties:[] PageDetails:[] CreatedAt:<nil> UpdatedAt:<nil> DeletedAt:<nil>} Filter:{UserID: PageID: TargetID: ActorType: Since:<nil> Until:<nil>} NextBatchID: UserID:UR_2}: failed"),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			pageStore := new(mocks.PageStore)
+			pageStore.On("CanReadPage", mock.Anything, "PG_1", "UR_2").Return(tc.canRead, tc.canReadErr)
+			auditStore := new(mocks.AuditStore)
+			auditStore.On("GetEntries", mock.Anything, audit.Filter{PageID: "PG_1", ActorType: audit.ActorAdmin}, "AE_1", batchSize).Return([]audit.Entry{{GUID: "AE_1"}}, 3, "AE_2", nil)
+			auditService := AuditService{AuditStore: auditStore, PageStore: pageStore}
+			entries, total, nextBatchID, err := auditService.GetPageEntries(context.Background(), tc.params)
+			errExpected := testutils.TestErrorAgainstCase(t, err, tc.returnErr)
+			if errExpected {
+				auditStore.AssertNotCalled(t, "GetEntries", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+			require.Equal(t, []audit.Entry{{GUID: "AE_1"}}, entries)
+			require.Equal(t, 3, total)
+			require.Equal(t, "AE_2", nextBatchID)
+		})
+	}
+}
+
+func TestGetUserEntries(t *testing.T) {
+	cases := []struct {
+		name        string
+		params      GetUserEntriesParams
+		paramFilter audit.Filter
+	}{
+		{
+			name:        "test the user's entries",
+			params:      GetUserEntriesParams{Filter: audit.Filter{UserID: "UR_2", PageID: "PG_1"}, UserID: "UR_1"},
+			paramFilter: audit.Filter{UserID: "UR_1", PageID: "PG_1"},
+		},
+		{
+			name:        "test an admin's entries",
+			params:      GetUserEntriesParams{Filter: audit.Filter{ActorType: audit.ActorProxyUser}},
+			paramFilter: audit.Filter{ActorType: audit.ActorAdmin},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			auditStore := new(mocks.AuditStore)
+			auditStore.On("GetEntries", mock.Anything, tc.paramFilter, "", batchSize).Return([]audit.Entry{{GUID: "AE_1"}}, 1, "", nil)
+			auditService := AuditService{AuditStore: auditStore}
+			entries, total, _, err := auditService.GetUserEntries(context.Background(), tc.params)
+			require.NoError(t, err)
+			require.Equal(t, []audit.Entry{{GUID: "AE_1"}}, entries)
+			require.Equal(t, 1, total)
+		})
+	}
+}
+
+func TestGetEntries(t *testing.T) {
+	auditStore := new(mocks.AuditStore)
+	auditStore.On("GetEntries", mock.Anything, audit.Filter{TargetID: "WH_1"}, "AE_1", batchSize).Return(nil, 0, "", errors.New("failed"))
+	auditService := AuditService{AuditStore: auditStore}
+	_, _, _, err := auditService.GetEntries(context.Background(), GetEntriesParams{Filter: audit.Filter{TargetID: "WH_1"}, NextBatchID: "AE_1"})
+	require.EqualError(t, err, "failed to get audit entries: {UserID: PageID: TargetID:WH_1 ActorType: Since:<nil> Until:<nil>}: failed")
+}
@@ -0,0 +1,70 @@
+package commentservice
+
+import (
+	"context"
+
+	"github.com/worlve/sp-service/internal/models/comment"
+	"github.com/worlve/sp-service/internal/services/instrumentation"
+)
+
+const serviceName = "comment"
+
+// InstrumentedCommentService is a CommentService that records a span and counts the errors for each of its methods.
+type InstrumentedCommentService struct {
+	CommentService
+}
+
+// CreateComment see CommentService.CreateComment
+func (s InstrumentedCommentService) CreateComment(ctx context.Context, params CreateCommentParams) (comment.Comment, error) {
+	ctx, end := instrumentation.Start(ctx, serviceName, "CreateComment")
+	c, err := s.CommentService.CreateComment(ctx, params)
+	return c, end(err)
+}
+
+// CreateReply see CommentService.CreateReply
+func (s InstrumentedCommentService) CreateReply(ctx context.Context, params CreateReplyParams) (comment.Comment, error) {
+	ctx, end := instrumentation.Start(ctx, serviceName, "CreateReply")
+	c, err := s.CommentService.CreateReply(ctx, params)
+	return c, end(err)
+}
+
+// GetThreads see CommentService.GetThreads
+func (s InstrumentedCommentService) GetThreads(ctx context.Context, params GetThreadsParams) ([]comment.Comment, int, string, error) {
+	ctx, end := instrumentation.Start(ctx, serviceName, "GetThreads")
+	results, total, nextBatchID, err := s.CommentService.GetThreads(ctx, params)
+	return results, total, nextBatchID, end(err)
+}
+
+// GetReplies see CommentService.GetReplies
+func (s InstrumentedCommentService) GetReplies(ctx context.Context, params GetRepliesParams) ([]comment.Comment, int, string, error) {
+	ctx, end := instrumentation.Start(ctx, serviceName, "GetReplies")
+	results, total, nextBatchID, err := s.CommentService.GetReplies(ctx, params)
+	return results, total, nextBatchID, end(err)
+}
+
+// UpdateComment see CommentService.UpdateComment
+func (s InstrumentedCommentService) UpdateComment(ctx context.Context, params UpdateCommentParams) (comment.Comment, error) {
+	ctx, end := instrumentation.Start(ctx, serviceName, "UpdateComment")
+	c, err := s.CommentService.UpdateComment(ctx, params)
+	return c, end(err)
+}
+
+// RemoveComment see CommentService.RemoveComment
+func (s InstrumentedCommentService) RemoveComment(ctx context.Context, params RemoveCommentParams) error {
+	ctx, end := instrumentation.Start(ctx, serviceName, "RemoveComment")
+	return end(s.CommentService.RemoveComment(ctx, params))
+}
+
+// ResolveThread see CommentService.ResolveThread
+func (s InstrumentedCommentService) ResolveThread(ctx context.Context, params ResolveThreadParams) (comment.Comment, error) {
+	ctx, end := instrumentation.Start(ctx, serviceName, "ResolveThread")
+	c, err := s.CommentService.ResolveThread(ctx, params)
+	return c, end(err)
+}
+
+// GetMentions see CommentService.GetMentions
+func (s InstrumentedCommentService) GetMentions(ctx context.Context, params GetMentionsParams) ([]comment.Comment, int, string, error) {
+	ctx, end := instrumentation.Start(ctx, serviceName, "GetMentions")
+	results, total, nextBatchID, err := s.CommentService.GetMentions(ctx, params)
+	return results, total, nextBatchID, end(err)
+}
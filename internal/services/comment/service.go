@@ -0,0 +1,351 @@
+package commentservice
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/worlve/sp-service/internal/models/comment"
+	"github.com/worlve/sp-service/internal/models/page"
+	"github.com/worlve/sp-service/internal/models/pagedetail"
+	"github.com/worlve/sp-service/internal/stores/store"
+	"github.com/worlve/sp-service/internal/stores/storeerror"
+	"github.com/worlve/sp-service/internal/util/auditlog"
+	"github.com/worlve/sp-service/internal/util/clock"
+	"github.com/worlve/sp-service/internal/util/guidgen"
+	"github.com/pkg/errors"
+)
+
+// batchSize is the number of comments returned in each batch.
+const batchSize = 20
+
+// CommentService is the service for handling comment-related APIs
+type CommentService struct {
+	CommentStore    store.CommentStore
+	PageStore       store.PageStore
+	PageDetailStore store.PageDetailStore
+	Clock           clock.Clock
+}
+
+// CreateCommentParams params for CreateComment
+type CreateCommentParams struct {
+	Page    page.Page
+	Comment comment.Comment
+	UserID  string
+}
+
+// CreateComment starts a thread on the page, optionally anchored to one of its details.
+func (s CommentService) CreateComment(ctx context.Context, params CreateCommentParams) (comment.Comment, error) {
+	err := s.canReadPage(ctx, params.Page.GUID, params.UserID)
+	if err != nil {
+		return comment.Comment{}, err
+	}
+	if params.Comment.Anchor != nil {
+		err = s.checkAnchor(ctx, params.Page.GUID, *params.Comment.Anchor)
+		if err != nil {
+			return comment.Comment{}, err
+		}
+	}
+	return s.createComment(ctx, comment.Comment{
+		PageGUID: params.Page.GUID,
+		UserID:   params.UserID,
+		Anchor:   params.Comment.Anchor,
+		Body:     params.Comment.Body,
+	})
+}
+
+// checkAnchor returns a storeerror.InvalidField unless the anchor's detail, and its partition when it has one,
+// are on the page.
+func (s CommentService) checkAnchor(ctx context.Context, pageGUID string, anchor comment.Anchor) error {
+	details, err := s.PageDetailStore.GetPageDetails(ctx, pageGUID)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get page details to check the anchor: %+v", anchor)
+	}
+	for _, d := range details {
+		if d.GUID != anchor.DetailID {
+			continue
+		}
+		if anchor.PartitionID != "" && !pagedetail.HasPartition(d.Partitions, anchor.PartitionID) {
+			return &storeerror.InvalidField{Field: "anchor", Message: fmt.Sprintf("anchor.partitionId %v is not a partition of the detail", anchor.PartitionID)}
+		}
+		return nil
+	}
+	return &storeerror.InvalidField{Field: "anchor", Message: fmt.Sprintf("anchor.detailId %v is not a detail of the page", anchor.DetailID)}
+}
+
+// CreateReplyParams params for CreateReply
+type CreateReplyParams struct {
+	Comment comment.Comment
+	Reply   comment.Comment
+	UserID  string
+}
+
+// CreateReply replies to the comment's thread; replying to a reply adds to the thread it's in.
+func (s CommentService) CreateReply(ctx context.Context, params CreateReplyParams) (comment.Comment, error) {
+	c, err := s.getComment(ctx, params.Comment.GUID, params.UserID)
+	if err != nil {
+		return comment.Comment{}, err
+	}
+	return s.createComment(ctx, comment.Comment{
+		PageGUID:   c.PageGUID,
+		ThreadGUID: c.GetThreadGUID(),
+		UserID:     params.UserID,
+		Body:       params.Reply.Body,
+	})
+}
+
+func (s CommentService) createComment(ctx context.Context, record comment.Comment) (comment.Comment, error) {
+	mentions, err := s.getMentions(ctx, record.PageGUID, record.Body)
+	if err != nil {
+		return comment.Comment{}, err
+	}
+	record.GUID = guidgen.GenerateGUID("CM", 24)
+	record.Mentions = mentions
+	c, err := s.CommentStore.CreateComment(ctx, record)
+	if err != nil {
+		return c, errors.Wrapf(err, "failed to create comment: %+v", record)
+	}
+	auditlog.Record(ctx, auditlog.Change{TargetID: c.GUID, PageID: c.PageGUID, After: c})
+	return c, nil
+}
+
+// getMentions returns the users mentioned in the body who can read the page, since nobody else can see the comment.
+func (s CommentService) getMentions(ctx context.Context, pageGUID, body string) ([]string, error) {
+	mentions := []string{}
+	for _, userID := range comment.ParseMentions(body) {
+		canRead, err := s.PageStore.CanReadPage(ctx, pageGUID, userID)
+		if err != nil {
+			if _, ok := errors.Cause(err).(*storeerror.NotAuthorized); ok {
+				continue
+			}
+			return nil, errors.Wrapf(err, "failed to check page access of mentioned user: %v", userID)
+		}
+		if canRead {
+			mentions = append(mentions, userID)
+		}
+	}
+	return mentions, nil
+}
+
+// GetThreadsParams params for GetThreads
+type GetThreadsParams struct {
+	Page        page.Page
+	Filter      comment.Filter
+	NextBatchID string
+	UserID      string
+}
+
+// GetThreads returns the page's threads within the filter, newest first.
+func (s CommentService) GetThreads(ctx context.Context, params GetThreadsParams) ([]comment.Comment, int, string, error) {
+	err := s.canReadPage(ctx, params.Page.GUID, params.UserID)
+	if err != nil {
+		return nil, 0, "", err
+	}
+	threads, total, nextBatchID, err := s.CommentStore.GetThreads(ctx, params.Page.GUID, params.Filter, params.NextBatchID, batchSize)
+	if err != nil {
+		return threads, total, nextBatchID, errors.Wrapf(err, "failed to get threads: %+v", params)
+	}
+	return threads, total, nextBatchID, nil
+}
+
+// GetRepliesParams params for GetReplies
+type GetRepliesParams struct {
+	Comment     comment.Comment
+	NextBatchID string
+	UserID      string
+}
+
+// GetReplies returns the replies in the comment's thread, oldest first.
+func (s CommentService) GetReplies(ctx context.Context, params GetRepliesParams) ([]comment.Comment, int, string, error) {
+	c, err := s.getComment(ctx, params.Comment.GUID, params.UserID)
+	if err != nil {
+		return nil, 0, "", err
+	}
+	replies, total, nextBatchID, err := s.CommentStore.GetReplies(ctx, c.GetThreadGUID(), params.NextBatchID, batchSize)
+	if err != nil {
+		return replies, total, nextBatchID, errors.Wrapf(err, "failed to get replies: %+v", params)
+	}
+	return replies, total, nextBatchID, nil
+}
+
+// UpdateCommentParams params for UpdateComment
+type UpdateCommentParams struct {
+	Comment comment.Comment
+	UserID  string
+}
+
+// UpdateComment sets the body of the user's own comment, along with who it mentions.
+func (s CommentService) UpdateComment(ctx context.Context, params UpdateCommentParams) (comment.Comment, error) {
+	before, err := s.getComment(ctx, params.Comment.GUID, params.UserID)
+	if err != nil {
+		return comment.Comment{}, err
+	}
+	if before.UserID != params.UserID {
+		return comment.Comment{}, &storeerror.NotAuthorized{
+			UserID:  params.UserID,
+			TableID: before.GUID,
+		}
+	}
+	mentions, err := s.getMentions(ctx, before.PageGUID, params.Comment.Body)
+	if err != nil {
+		return comment.Comment{}, err
+	}
+	record := before
+	record.Body = params.Comment.Body
+	record.Mentions = mentions
+	c, err := s.CommentStore.UpdateComment(ctx, record)
+	if err != nil {
+		return c, errors.Wrapf(err, "failed to update comment: %+v", params)
+	}
+	auditlog.Record(ctx, auditlog.Change{TargetID: c.GUID, PageID: c.PageGUID, Before: before, After: c})
+	return c, nil
+}
+
+// RemoveCommentParams params for RemoveComment
+type RemoveCommentParams struct {
+	Comment comment.Comment
+	UserID  string
+}
+
+// RemoveComment permanently deletes the comment, along with its replies if it starts a thread.
+// Comments can be removed by their author or the page's owner.
+func (s CommentService) RemoveComment(ctx context.Context, params RemoveCommentParams) error {
+	c, err := s.getComment(ctx, params.Comment.GUID, params.UserID)
+	if err != nil {
+		return err
+	}
+	err = s.canModerate(ctx, c, params.UserID)
+	if err != nil {
+		return err
+	}
+	err = s.CommentStore.RemoveComment(ctx, c.GUID)
+	if err != nil {
+		return errors.Wrapf(err, "failed to remove comment: %+v", params)
+	}
+	auditlog.Record(ctx, auditlog.Change{TargetID: c.GUID, PageID: c.PageGUID, Before: c})
+	return nil
+}
+
+// ResolveThreadParams params for ResolveThread
+type ResolveThreadParams struct {
+	Comment comment.Comment
+	// Resolved is false to reopen the thread.
+	Resolved bool
+	UserID   string
+}
+
+// ResolveThread resolves or reopens the thread the comment is in, and returns the thread.
+// Threads can be resolved by their author or the page's owner.
+func (s CommentService) ResolveThread(ctx context.Context, params ResolveThreadParams) (comment.Comment, error) {
+	c, err := s.getComment(ctx, params.Comment.GUID, params.UserID)
+	if err != nil {
+		return comment.Comment{}, err
+	}
+	before := c
+	if !c.IsThread() {
+		before, err = s.CommentStore.GetComment(ctx, c.ThreadGUID)
+		if err != nil {
+			return comment.Comment{}, errors.Wrapf(err, "failed to get thread: %v", c.ThreadGUID)
+		}
+	}
+	err = s.canModerate(ctx, before, params.UserID)
+	if err != nil {
+		return comment.Comment{}, err
+	}
+	if params.Resolved == (before.ResolvedAt != nil) {
+		return before, nil
+	}
+	record := before
+	record.ResolvedAt = nil
+	record.ResolvedBy = ""
+	if params.Resolved {
+		now := s.Clock.Now()
+		record.ResolvedAt = &now
+		record.ResolvedBy = params.UserID
+	}
+	thread, err := s.CommentStore.UpdateComment(ctx, record)
+	if err != nil {
+		return thread, errors.Wrapf(err, "failed to resolve thread: %+v", params)
+	}
+	auditlog.Record(ctx, auditlog.Change{TargetID: thread.GUID, PageID: thread.PageGUID, Before: before, After: thread})
+	return thread, nil
+}
+
+// GetMentionsParams params for GetMentions
+type GetMentionsParams struct {
+	NextBatchID string
+	UserID      string
+}
+
+// GetMentions returns the comments that mention the user, newest first. Comments on pages the user can
+// no longer read are left out of the batch, though they're still counted in the total.
+func (s CommentService) GetMentions(ctx context.Context, params GetMentionsParams) ([]comment.Comment, int, string, error) {
+	mentions, total, nextBatchID, err := s.CommentStore.GetMentions(ctx, params.UserID, params.NextBatchID, batchSize)
+	if err != nil {
+		return mentions, total, nextBatchID, errors.Wrapf(err, "failed to get mentions: %+v", params)
+	}
+	canReadPages := map[string]bool{}
+	readable := make([]comment.Comment, 0, len(mentions))
+	for _, c := range mentions {
+		canRead, ok := canReadPages[c.PageGUID]
+		if !ok {
+			canRead, err = s.PageStore.CanReadPage(ctx, c.PageGUID, params.UserID)
+			if err != nil {
+				if _, ok := errors.Cause(err).(*storeerror.NotAuthorized); !ok {
+					return nil, 0, "", errors.Wrapf(err, "failed to check page access: %v", c.PageGUID)
+				}
+			}
+			canReadPages[c.PageGUID] = canRead
+		}
+		if canRead {
+			readable = append(readable, c)
+		}
+	}
+	return readable, total, nextBatchID, nil
+}
+
+// canReadPage returns a storeerror.NotAuthorized if the user can't read the page.
+func (s CommentService) canReadPage(ctx context.Context, pageGUID, userID string) error {
+	canRead, err := s.PageStore.CanReadPage(ctx, pageGUID, userID)
+	if err != nil {
+		return errors.Wrapf(err, "failed to check page access: %v", pageGUID)
+	}
+	if !canRead {
+		return &storeerror.NotAuthorized{
+			UserID:  userID,
+			TableID: pageGUID,
+		}
+	}
+	return nil
+}
+
+// getComment returns the comment, or a storeerror.NotAuthorized if the user can't read its page.
+func (s CommentService) getComment(ctx context.Context, commentGUID, userID string) (comment.Comment, error) {
+	c, err := s.CommentStore.GetComment(ctx, commentGUID)
+	if err != nil {
+		return comment.Comment{}, errors.Wrapf(err, "failed to get comment: %v", commentGUID)
+	}
+	err = s.canReadPage(ctx, c.PageGUID, userID)
+	if err != nil {
+		return comment.Comment{}, err
+	}
+	return c, nil
+}
+
+// canModerate returns a storeerror.NotAuthorized unless the user wrote the comment or owns its page;
+// users the page is shared with can edit it, but can't moderate its comments.
+func (s CommentService) canModerate(ctx context.Context, c comment.Comment, userID string) error {
+	if c.UserID == userID {
+		return nil
+	}
+	isOwner, err := s.PageStore.CanEditPage(ctx, c.PageGUID, userID)
+	if err != nil {
+		return err
+	}
+	if !isOwner {
+		return &storeerror.NotAuthorized{
+			UserID:  userID,
+			TableID: c.PageGUID,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,340 @@
+package commentservice
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/worlve/sp-service/internal/models/comment"
+	"github.com/worlve/sp-service/internal/models/page"
+	"github.com/worlve/sp-service/internal/models/pagedetail"
+	"github.com/worlve/sp-service/internal/stores/store/mocks"
+	"github.com/worlve/sp-service/internal/stores/storeerror"
+	"github.com/worlve/sp-service/internal/util/clock"
+	"github.com/worlve/sp-service/internal/util/testutils"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var notAuthorized = &storeerror.NotAuthorized{UserID: "UR_3", TableID: "PG_1"}
+
+func TestCreateComment(t *testing.T) {
+	cases := []struct {
+		name          string
+		params        CreateCommentParams
+		canRead       bool
+		returnComment comment.Comment
+		returnErr     error
+	}{
+		{
+			name: "test mentions of users who can't read the page are dropped",
+			params: CreateCommentParams{
+				Page:    page.Page{GUID: "PG_1"},
+				Comment: comment.Comment{Body: "@UR_2 @UR_3 @UR_4 thoughts?", Anchor: &comment.Anchor{DetailID: "PD_1"}},
+				UserID:  "UR_1",
+			},
+			canRead: true,
+			returnComment: comment.Comment{
+				PageGUID: "PG_1",
+				UserID:   "UR_1",
+				Anchor:   &comment.Anchor{DetailID: "PD_1"},
+				Body:     "@UR_2 @UR_3 @UR_4 thoughts?",
+				Mentions: []string{"UR_2"},
+			},
+		},
+		{
+			name: "test anchored to a partition of the detail",
+			params: CreateCommentParams{
+				Page:    page.Page{GUID: "PG_1"},
+				Comment: comment.Comment{Body: "this bit", Anchor: &comment.Anchor{DetailID: "PD_2", PartitionID: "P_2"}},
+				UserID:  "UR_1",
+			},
+			canRead: true,
+			returnComment: comment.Comment{
+				PageGUID: "PG_1",
+				UserID:   "UR_1",
+				Anchor:   &comment.Anchor{DetailID: "PD_2", PartitionID: "P_2"},
+				Body:     "this bit",
+				Mentions: []string{},
+			},
+		},
+		{
+			name: "test detail of another page",
+			params: CreateCommentParams{
+				Page:    page.Page{GUID: "PG_1"},
+				Comment: comment.Comment{Body: "hi", Anchor: &comment.Anchor{DetailID: "PD_3"}},
+				UserID:  "UR_1",
+			},
+			canRead:   true,
+			returnErr: &storeerror.InvalidField{Field: "anchor", Message: "anchor.detailId PD_3 is not a detail of the page"},
+		},
+		{
+			name: "test partition of another detail",
+			params: CreateCommentParams{
+				Page:    page.Page{GUID: "PG_1"},
+				Comment: comment.Comment{Body: "hi", Anchor: &comment.Anchor{DetailID: "PD_1", PartitionID: "P_2"}},
+				UserID:  "UR_1",
+			},
+			canRead:   true,
+			returnErr: &storeerror.InvalidField{Field: "anchor", Message: "anchor.partitionId P_2 is not a partition of the detail"},
+		},
+		{
+			name:      "test someone else's private page",
+			params:    CreateCommentParams{Page: page.Page{GUID: "PG_1"}, Comment: comment.Comment{Body: "hi"}, UserID: "UR_1"},
+			returnErr: errors.New("User UR_1 is not authorized to perform the action on the ID PG_1"),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			pageStore := new(mocks.PageStore)
+			pageStore.On("CanReadPage", mock.Anything, "PG_1", "UR_1").Return(tc.canRead, nil)
+			pageStore.On("CanReadPage", mock.Anything, "PG_1", "UR_2").Return(true, nil)
+			pageStore.On("CanReadPage", mock.Anything, "PG_1", "UR_3").Return(false, nil)
+			pageStore.On("CanReadPage", mock.Anything, "PG_1", "UR_4").Return(false, notAuthorized)
+			commentStore := new(mocks.CommentStore)
+			commentStore.On("CreateComment", mock.Anything, mock.Anything).Return(func(ctx context.Context, record comment.Comment) comment.Comment {
+				return record
+			}, nil)
+			pageDetailStore := new(mocks.PageDetailStore)
+			pageDetailStore.On("GetPageDetails", mock.Anything, "PG_1").Return([]pagedetail.PageDetail{
+				{GUID: "PD_1", Partitions: []pagedetail.Partition{{GUID: "P_1", TypeString: "p"}}},
+				{GUID: "PD_2", Partitions: []pagedetail.Partition{{TypeString: "ul", Items: []pagedetail.Partition{{GUID: "P_2", TypeString: "li"}}}}},
+			}, nil)
+			commentService := CommentService{CommentStore: commentStore, PageStore: pageStore, PageDetailStore: pageDetailStore}
+			c, err := commentService.CreateComment(context.Background(), tc.params)
+			errExpected := testutils.TestErrorAgainstCase(t, err, tc.returnErr)
+			if errExpected {
+				commentStore.AssertNotCalled(t, "CreateComment", mock.Anything, mock.Anything)
+				return
+			}
+			require.Regexp(t, "^CM_", c.GUID)
+			c.GUID = ""
+			require.Equal(t, tc.returnComment, c)
+		})
+	}
+}
+
+func TestCreateReply(t *testing.T) {
+	cases := []struct {
+		name             string
+		paramCommentGUID string
+		returnThreadGUID string
+		returnErr        error
+	}{
+		{
+			name:             "test replying to a thread",
+			paramCommentGUID: "CM_1",
+			returnThreadGUID: "CM_1",
+		},
+		{
+			name:             "test replying to a reply adds to its thread",
+			paramCommentGUID: "CM_2",
+			returnThreadGUID: "CM_1",
+		},
+		{
+			name:             "test a comment on a page the user can't read",
+			paramCommentGUID: "CM_3",
+			returnErr:        errors.New("User UR_1 is not authorized to perform the action on the ID PG_2"),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			pageStore := new(mocks.PageStore)
+			pageStore.On("CanReadPage", mock.Anything, "PG_1", "UR_1").Return(true, nil)
+			pageStore.On("CanReadPage", mock.Anything, "PG_2", "UR_1").Return(false, nil)
+			commentStore := new(mocks.CommentStore)
+			commentStore.On("GetComment", mock.Anything, "CM_1").Return(comment.Comment{GUID: "CM_1", PageGUID: "PG_1"}, nil)
+			commentStore.On("GetComment", mock.Anything, "CM_2").Return(comment.Comment{GUID: "CM_2", PageGUID: "PG_1", ThreadGUID: "CM_1"}, nil)
+			commentStore.On("GetComment", mock.Anything, "CM_3").Return(comment.Comment{GUID: "CM_3", PageGUID: "PG_2"}, nil)
+			commentStore.On("CreateComment", mock.Anything, mock.Anything).Return(func(ctx context.Context, record comment.Comment) comment.Comment {
+				return record
+			}, nil)
+			commentService := CommentService{CommentStore: commentStore, PageStore: pageStore}
+			c, err := commentService.CreateReply(context.Background(), CreateReplyParams{
+				Comment: comment.Comment{GUID: tc.paramCommentGUID},
+				Reply:   comment.Comment{Body: "agreed", Anchor: &comment.Anchor{DetailID: "PD_1"}},
+				UserID:  "UR_1",
+			})
+			errExpected := testutils.TestErrorAgainstCase(t, err, tc.returnErr)
+			if errExpected {
+				return
+			}
+			require.Equal(t, "PG_1", c.PageGUID)
+			require.Equal(t, tc.returnThreadGUID, c.ThreadGUID)
+			require.Nil(t, c.Anchor, "only threads are anchored")
+			require.Equal(t, "agreed", c.Body)
+		})
+	}
+}
+
+func TestUpdateComment(t *testing.T) {
+	cases := []struct {
+		name        string
+		paramUserID string
+		returnErr   error
+	}{
+		{
+			name:        "test the author",
+			paramUserID: "UR_1",
+		},
+		{
+			name:        "test someone else",
+			paramUserID: "UR_2",
+			returnErr:   errors.New("User UR_2 is not authorized to perform the action on the ID CM_1"),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			pageStore := new(mocks.PageStore)
+			pageStore.On("CanReadPage", mock.Anything, "PG_1", mock.Anything).Return(true, nil)
+			commentStore := new(mocks.CommentStore)
+			commentStore.On("GetComment", mock.Anything, "CM_1").Return(comment.Comment{GUID: "CM_1", PageGUID: "PG_1", UserID: "UR_1", Body: "hi", Mentions: []string{}}, nil)
+			commentStore.On("UpdateComment", mock.Anything, comment.Comment{GUID: "CM_1", PageGUID: "PG_1", UserID: "UR_1", Body: "hi @UR_2", Mentions: []string{"UR_2"}}).Return(comment.Comment{GUID: "CM_1"}, nil)
+			commentService := CommentService{CommentStore: commentStore, PageStore: pageStore}
+			_, err := commentService.UpdateComment(context.Background(), UpdateCommentParams{
+				Comment: comment.Comment{GUID: "CM_1", Body: "hi @UR_2"},
+				UserID:  tc.paramUserID,
+			})
+			errExpected := testutils.TestErrorAgainstCase(t, err, tc.returnErr)
+			if errExpected {
+				commentStore.AssertNotCalled(t, "UpdateComment", mock.Anything, mock.Anything)
+				return
+			}
+			commentStore.AssertNumberOfCalls(t, "UpdateComment", 1)
+		})
+	}
+}
+
+func TestRemoveComment(t *testing.T) {
+	cases := []struct {
+		name        string
+		paramUserID string
+		notOwner    bool
+		isOwnerErr  error
+		returnErr   error
+	}{
+		{
+			name:        "test the author",
+			paramUserID: "UR_2",
+		},
+		{
+			name:        "test the page's owner",
+			paramUserID: "UR_1",
+		},
+		{
+			name:        "test an editor who doesn't own the page",
+			paramUserID: "UR_3",
+			notOwner:    true,
+			returnErr:   errors.New("User UR_3 is not authorized to perform the action on the ID PG_1"),
+		},
+		{
+			name:        "test someone else",
+			paramUserID: "UR_3",
+			isOwnerErr:  notAuthorized,
+			returnErr:   errors.New("User UR_3 is not authorized to perform the action on the ID PG_1"),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			pageStore := new(mocks.PageStore)
+			pageStore.On("CanReadPage", mock.Anything, "PG_1", tc.paramUserID).Return(true, nil)
+			pageStore.On("CanEditPage", mock.Anything, "PG_1", tc.paramUserID).Return(tc.isOwnerErr == nil && !tc.notOwner, tc.isOwnerErr)
+			commentStore := new(mocks.CommentStore)
+			commentStore.On("GetComment", mock.Anything, "CM_1").Return(comment.Comment{GUID: "CM_1", PageGUID: "PG_1", UserID: "UR_2"}, nil)
+			commentStore.On("RemoveComment", mock.Anything, "CM_1").Return(nil)
+			commentService := CommentService{CommentStore: commentStore, PageStore: pageStore}
+			err := commentService.RemoveComment(context.Background(), RemoveCommentParams{
+				Comment: comment.Comment{GUID: "CM_1"},
+				UserID:  tc.paramUserID,
+			})
+			errExpected := testutils.TestErrorAgainstCase(t, err, tc.returnErr)
+			if errExpected {
+				commentStore.AssertNotCalled(t, "RemoveComment", mock.Anything, mock.Anything)
+				return
+			}
+			commentStore.AssertNumberOfCalls(t, "RemoveComment", 1)
+		})
+	}
+}
+
+func TestResolveThread(t *testing.T) {
+	now := time.Date(2020, 3, 31, 12, 0, 0, 0, time.UTC)
+	cases := []struct {
+		name         string
+		params       ResolveThreadParams
+		thread       comment.Comment
+		returnUpdate *comment.Comment
+		returnErr    error
+	}{
+		{
+			name:         "test resolving a reply resolves its thread",
+			params:       ResolveThreadParams{Comment: comment.Comment{GUID: "CM_2"}, Resolved: true, UserID: "UR_2"},
+			thread:       comment.Comment{GUID: "CM_1", PageGUID: "PG_1", UserID: "UR_2"},
+			returnUpdate: &comment.Comment{GUID: "CM_1", PageGUID: "PG_1", UserID: "UR_2", ResolvedAt: &now, ResolvedBy: "UR_2"},
+		},
+		{
+			name:         "test reopening",
+			params:       ResolveThreadParams{Comment: comment.Comment{GUID: "CM_1"}, UserID: "UR_2"},
+			thread:       comment.Comment{GUID: "CM_1", PageGUID: "PG_1", UserID: "UR_2", ResolvedAt: &now, ResolvedBy: "UR_2"},
+			returnUpdate: &comment.Comment{GUID: "CM_1", PageGUID: "PG_1", UserID: "UR_2"},
+		},
+		{
+			name:   "test a thread that's already resolved",
+			params: ResolveThreadParams{Comment: comment.Comment{GUID: "CM_1"}, Resolved: true, UserID: "UR_2"},
+			thread: comment.Comment{GUID: "CM_1", PageGUID: "PG_1", UserID: "UR_2", ResolvedAt: &now, ResolvedBy: "UR_1"},
+		},
+		{
+			name:      "test someone else's thread",
+			params:    ResolveThreadParams{Comment: comment.Comment{GUID: "CM_1"}, Resolved: true, UserID: "UR_3"},
+			thread:    comment.Comment{GUID: "CM_1", PageGUID: "PG_1", UserID: "UR_2"},
+			returnErr: errors.New("User UR_3 is not authorized to perform the action on the ID PG_1"),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			pageStore := new(mocks.PageStore)
+			pageStore.On("CanReadPage", mock.Anything, "PG_1", tc.params.UserID).Return(true, nil)
+			pageStore.On("CanEditPage", mock.Anything, "PG_1", "UR_3").Return(false, notAuthorized)
+			commentStore := new(mocks.CommentStore)
+			commentStore.On("GetComment", mock.Anything, "CM_1").Return(tc.thread, nil)
+			commentStore.On("GetComment", mock.Anything, "CM_2").Return(comment.Comment{GUID: "CM_2", PageGUID: "PG_1", ThreadGUID: "CM_1", UserID: "UR_3"}, nil)
+			if tc.returnUpdate != nil {
+				commentStore.On("UpdateComment", mock.Anything, *tc.returnUpdate).Return(*tc.returnUpdate, nil)
+			}
+			commentService := CommentService{CommentStore: commentStore, PageStore: pageStore, Clock: clock.MockClock{MockedTime: &now}}
+			thread, err := commentService.ResolveThread(context.Background(), tc.params)
+			errExpected := testutils.TestErrorAgainstCase(t, err, tc.returnErr)
+			if errExpected {
+				return
+			}
+			if tc.returnUpdate == nil {
+				commentStore.AssertNotCalled(t, "UpdateComment", mock.Anything, mock.Anything)
+				require.Equal(t, tc.thread, thread)
+				return
+			}
+			require.Equal(t, *tc.returnUpdate, thread)
+		})
+	}
+}
+
+func TestGetMentions(t *testing.T) {
+	pageStore := new(mocks.PageStore)
+	pageStore.On("CanReadPage", mock.Anything, "PG_1", "UR_1").Return(true, nil)
+	pageStore.On("CanReadPage", mock.Anything, "PG_2", "UR_1").Return(false, nil)
+	pageStore.On("CanReadPage", mock.Anything, "PG_3", "UR_1").Return(false, notAuthorized)
+	commentStore := new(mocks.CommentStore)
+	commentStore.On("GetMentions", mock.Anything, "UR_1", "CM_9", batchSize).Return([]comment.Comment{
+		{GUID: "CM_5", PageGUID: "PG_1"},
+		{GUID: "CM_4", PageGUID: "PG_2"},
+		{GUID: "CM_3", PageGUID: "PG_3"},
+		{GUID: "CM_2", PageGUID: "PG_1"},
+	}, 6, "CM_1", nil)
+	commentService := CommentService{CommentStore: commentStore, PageStore: pageStore}
+	mentions, total, nextBatchID, err := commentService.GetMentions(context.Background(), GetMentionsParams{NextBatchID: "CM_9", UserID: "UR_1"})
+	require.NoError(t, err)
+	require.Equal(t, []comment.Comment{{GUID: "CM_5", PageGUID: "PG_1"}, {GUID: "CM_2", PageGUID: "PG_1"}}, mentions)
+	require.Equal(t, 6, total)
+	require.Equal(t, "CM_1", nextBatchID)
+	pageStore.AssertNumberOfCalls(t, "CanReadPage", 3)
+}
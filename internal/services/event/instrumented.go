@@ -0,0 +1,76 @@
+package eventservice
+
+import (
+	"context"
+
+	"github.com/worlve/sp-service/internal/models/event"
+	"github.com/worlve/sp-service/internal/services/instrumentation"
+)
+
+const serviceName = "event"
+
+// InstrumentedEventService is an EventService that records a span and counts the errors for each of its methods.
+type InstrumentedEventService struct {
+	EventService
+}
+
+// GetEvents see EventService.GetEvents
+func (s InstrumentedEventService) GetEvents(ctx context.Context, params GetEventsParams) ([]event.Event, error) {
+	ctx, end := instrumentation.Start(ctx, serviceName, "GetEvents")
+	results, err := s.EventService.GetEvents(ctx, params)
+	return results, end(err)
+}
+
+// GetLastOffset see EventService.GetLastOffset
+func (s InstrumentedEventService) GetLastOffset(ctx context.Context) (int64, error) {
+	ctx, end := instrumentation.Start(ctx, serviceName, "GetLastOffset")
+	offset, err := s.EventService.GetLastOffset(ctx)
+	return offset, end(err)
+}
+
+// StartSubscriber see EventService.StartSubscriber
+func (s InstrumentedEventService) StartSubscriber(ctx context.Context, params StartSubscriberParams) (int64, error) {
+	ctx, end := instrumentation.Start(ctx, serviceName, "StartSubscriber")
+	offset, err := s.EventService.StartSubscriber(ctx, params)
+	return offset, end(err)
+}
+
+// GetSubscriberOffset see EventService.GetSubscriberOffset
+func (s InstrumentedEventService) GetSubscriberOffset(ctx context.Context, params GetSubscriberOffsetParams) (int64, error) {
+	ctx, end := instrumentation.Start(ctx, serviceName, "GetSubscriberOffset")
+	offset, err := s.EventService.GetSubscriberOffset(ctx, params)
+	return offset, end(err)
+}
+
+// GetSubscriberOffsets see EventService.GetSubscriberOffsets
+func (s InstrumentedEventService) GetSubscriberOffsets(ctx context.Context) (map[string]int64, error) {
+	ctx, end := instrumentation.Start(ctx, serviceName, "GetSubscriberOffsets")
+	offsets, err := s.EventService.GetSubscriberOffsets(ctx)
+	return offsets, end(err)
+}
+
+// ClaimSubscriber see EventService.ClaimSubscriber
+func (s InstrumentedEventService) ClaimSubscriber(ctx context.Context, params ClaimSubscriberParams) (bool, error) {
+	ctx, end := instrumentation.Start(ctx, serviceName, "ClaimSubscriber")
+	claimed, err := s.EventService.ClaimSubscriber(ctx, params)
+	return claimed, end(err)
+}
+
+// AdvanceSubscriber see EventService.AdvanceSubscriber
+func (s InstrumentedEventService) AdvanceSubscriber(ctx context.Context, params AdvanceSubscriberParams) (bool, error) {
+	ctx, end := instrumentation.Start(ctx, serviceName, "AdvanceSubscriber")
+	advanced, err := s.EventService.AdvanceSubscriber(ctx, params)
+	return advanced, end(err)
+}
+
+// ReplaySubscriber see EventService.ReplaySubscriber
+func (s InstrumentedEventService) ReplaySubscriber(ctx context.Context, params ReplaySubscriberParams) error {
+	ctx, end := instrumentation.Start(ctx, serviceName, "ReplaySubscriber")
+	return end(s.EventService.ReplaySubscriber(ctx, params))
+}
+
+// PurgeEvents see EventService.PurgeEvents
+func (s InstrumentedEventService) PurgeEvents(ctx context.Context, params PurgeEventsParams) error {
+	ctx, end := instrumentation.Start(ctx, serviceName, "PurgeEvents")
+	return end(s.EventService.PurgeEvents(ctx, params))
+}
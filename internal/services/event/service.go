@@ -0,0 +1,164 @@
+package eventservice
+
+import (
+	"context"
+	"time"
+
+	"github.com/worlve/sp-service/internal/models/event"
+	"github.com/worlve/sp-service/internal/stores/store"
+	"github.com/worlve/sp-service/internal/stores/storeerror"
+	"github.com/worlve/sp-service/internal/util/clock"
+	"github.com/worlve/sp-service/internal/util/guidgen"
+	"github.com/pkg/errors"
+)
+
+// EventService is the service for reading the event outbox and tracking how far its subscribers have got
+type EventService struct {
+	EventStore store.EventStore
+	Clock      clock.Clock
+}
+
+// GetEventsParams params for GetEvents
+type GetEventsParams struct {
+	AfterOffset int64
+	Limit       int
+}
+
+// GetEvents returns the events after the given offset, in order.
+func (s EventService) GetEvents(ctx context.Context, params GetEventsParams) ([]event.Event, error) {
+	events, err := s.EventStore.GetEvents(ctx, params.AfterOffset, params.Limit)
+	if err != nil {
+		return events, errors.Wrapf(err, "failed to get events: %+v", params)
+	}
+	return events, nil
+}
+
+// GetLastOffset returns the offset of the newest event, or 0 when there are none.
+func (s EventService) GetLastOffset(ctx context.Context) (int64, error) {
+	offset, err := s.EventStore.GetLastOffset(ctx)
+	if err != nil {
+		return offset, errors.Wrap(err, "failed to get the last event offset")
+	}
+	return offset, nil
+}
+
+// StartSubscriberParams params for StartSubscriber
+type StartSubscriberParams struct {
+	Subscriber string
+}
+
+// StartSubscriber returns the offset the subscriber has got to. A new subscriber starts at the newest event,
+// so it only gets the events after it was added unless it's replayed.
+func (s EventService) StartSubscriber(ctx context.Context, params StartSubscriberParams) (int64, error) {
+	offset, err := s.EventStore.GetSubscriberOffset(ctx, params.Subscriber)
+	if _, ok := err.(*storeerror.NotFound); !ok {
+		return offset, err
+	}
+	offset, err = s.GetLastOffset(ctx)
+	if err != nil {
+		return offset, err
+	}
+	err = s.EventStore.SetSubscriberOffset(ctx, params.Subscriber, offset)
+	if err != nil {
+		return offset, errors.Wrapf(err, "failed to add subscriber: %+v", params)
+	}
+	return offset, nil
+}
+
+// GetSubscriberOffsetParams params for GetSubscriberOffset
+type GetSubscriberOffsetParams struct {
+	Subscriber string
+}
+
+// GetSubscriberOffset returns the offset of the last event the subscriber handled.
+func (s EventService) GetSubscriberOffset(ctx context.Context, params GetSubscriberOffsetParams) (int64, error) {
+	return s.EventStore.GetSubscriberOffset(ctx, params.Subscriber)
+}
+
+// GetSubscriberOffsets returns the offset of every subscriber by its name.
+func (s EventService) GetSubscriberOffsets(ctx context.Context) (map[string]int64, error) {
+	offsets, err := s.EventStore.GetSubscriberOffsets(ctx)
+	if err != nil {
+		return offsets, errors.Wrap(err, "failed to get subscriber offsets")
+	}
+	return offsets, nil
+}
+
+// ClaimSubscriberParams params for ClaimSubscriber
+type ClaimSubscriberParams struct {
+	Subscriber string
+	LeaseUntil time.Time
+}
+
+// ClaimSubscriber leases the subscriber until LeaseUntil, so it's only handed events by one instance at a time.
+// Returns false if another instance's lease on it hasn't ended yet.
+func (s EventService) ClaimSubscriber(ctx context.Context, params ClaimSubscriberParams) (bool, error) {
+	return s.EventStore.ClaimSubscriber(ctx, params.Subscriber, s.Clock.Now(), params.LeaseUntil)
+}
+
+// AdvanceSubscriberParams params for AdvanceSubscriber
+type AdvanceSubscriberParams struct {
+	Subscriber string
+	From       int64
+	To         int64
+}
+
+// AdvanceSubscriber records that the subscriber has handled the events up to To.
+// Returns false if the subscriber is no longer at From, because it was replayed or another instance moved it on.
+func (s EventService) AdvanceSubscriber(ctx context.Context, params AdvanceSubscriberParams) (bool, error) {
+	return s.EventStore.AdvanceSubscriberOffset(ctx, params.Subscriber, params.From, params.To)
+}
+
+// ReplaySubscriberParams params for ReplaySubscriber
+type ReplaySubscriberParams struct {
+	Subscriber string
+	Offset     int64
+}
+
+// ReplaySubscriber moves the subscriber back to the given offset, so it gets every event after it again.
+func (s EventService) ReplaySubscriber(ctx context.Context, params ReplaySubscriberParams) error {
+	_, err := s.EventStore.GetSubscriberOffset(ctx, params.Subscriber)
+	if err != nil {
+		return err
+	}
+	lastOffset, err := s.GetLastOffset(ctx)
+	if err != nil {
+		return err
+	}
+	if params.Offset < 0 || params.Offset > lastOffset {
+		return errors.Errorf("offset must be between 0 and the last event offset %v", lastOffset)
+	}
+	err = s.EventStore.SetSubscriberOffset(ctx, params.Subscriber, params.Offset)
+	if err != nil {
+		return errors.Wrapf(err, "failed to replay subscriber: %+v", params)
+	}
+	return nil
+}
+
+// PurgeEventsParams params for PurgeEvents
+type PurgeEventsParams struct {
+	CreatedBefore time.Time
+}
+
+// PurgeEvents permanently deletes every event created before the given time; they can no longer be replayed.
+func (s EventService) PurgeEvents(ctx context.Context, params PurgeEventsParams) error {
+	err := s.EventStore.PurgeEvents(ctx, params.CreatedBefore)
+	if err != nil {
+		return errors.Wrapf(err, "failed to purge events: %+v", params)
+	}
+	return nil
+}
+
+// Emit adds the event for a change the user made to the outbox. The event store should be the one of the unit of
+// work the change is made in, so the event is only kept if the change is.
+func Emit(ctx context.Context, eventStore store.EventStore, data event.Data, userID string) error {
+	e, err := event.New(guidgen.GenerateGUID("EV", 24), data, userID)
+	if err != nil {
+		return err
+	}
+	_, err = eventStore.CreateEvent(ctx, e)
+	if err != nil {
+		return errors.Wrapf(err, "failed to add %v event for page %v to the outbox", e.Type, e.PageID)
+	}
+	return nil
+}
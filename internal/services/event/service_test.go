@@ -0,0 +1,93 @@
+package eventservice
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/worlve/sp-service/internal/stores/store/mocks"
+	"github.com/worlve/sp-service/internal/stores/storeerror"
+	"github.com/worlve/sp-service/internal/util/testutils"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStartSubscriber(t *testing.T) {
+	cases := []struct {
+		name            string
+		getOffsetErr    error
+		offset          int64
+		returnOffset    int64
+		returnSetOffset bool
+	}{
+		{
+			name:         "test existing subscriber resumes from its offset",
+			offset:       3,
+			returnOffset: 3,
+		},
+		{
+			name:            "test new subscriber starts at the newest event",
+			getOffsetErr:    &storeerror.NotFound{ID: "webhooks"},
+			returnOffset:    9,
+			returnSetOffset: true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			eventStore := new(mocks.EventStore)
+			eventStore.On("GetSubscriberOffset", mock.Anything, "webhooks").Return(tc.offset, tc.getOffsetErr)
+			eventStore.On("GetLastOffset", mock.Anything).Return(int64(9), nil)
+			eventStore.On("SetSubscriberOffset", mock.Anything, "webhooks", int64(9)).Return(nil)
+			eventService := EventService{EventStore: eventStore}
+			offset, err := eventService.StartSubscriber(context.Background(), StartSubscriberParams{Subscriber: "webhooks"})
+			require.NoError(t, err)
+			require.Equal(t, tc.returnOffset, offset)
+			if tc.returnSetOffset {
+				eventStore.AssertCalled(t, "SetSubscriberOffset", mock.Anything, "webhooks", int64(9))
+			} else {
+				eventStore.AssertNotCalled(t, "SetSubscriberOffset", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestReplaySubscriber(t *testing.T) {
+	cases := []struct {
+		name         string
+		params       ReplaySubscriberParams
+		getOffsetErr error
+		returnErr    error
+	}{
+		{
+			name:   "test happy path",
+			params: ReplaySubscriberParams{Subscriber: "webhooks", Offset: 2},
+		},
+		{
+			name:      "test offset after the newest event",
+			params:    ReplaySubscriberParams{Subscriber: "webhooks", Offset: 10},
+			returnErr: errors.New("offset must be between 0 and the last event offset 9"),
+		},
+		{
+			name:         "test unknown subscriber",
+			params:       ReplaySubscriberParams{Subscriber: "search", Offset: 2},
+			getOffsetErr: &storeerror.NotFound{ID: "search"},
+			returnErr:    errors.New("Could not find: search"),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			eventStore := new(mocks.EventStore)
+			eventStore.On("GetSubscriberOffset", mock.Anything, tc.params.Subscriber).Return(int64(7), tc.getOffsetErr)
+			eventStore.On("GetLastOffset", mock.Anything).Return(int64(9), nil)
+			eventStore.On("SetSubscriberOffset", mock.Anything, tc.params.Subscriber, tc.params.Offset).Return(nil)
+			eventService := EventService{EventStore: eventStore}
+			err := eventService.ReplaySubscriber(context.Background(), tc.params)
+			errExpected := testutils.TestErrorAgainstCase(t, err, tc.returnErr)
+			if errExpected {
+				eventStore.AssertNotCalled(t, "SetSubscriberOffset", mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+			eventStore.AssertCalled(t, "SetSubscriberOffset", mock.Anything, "webhooks", int64(2))
+		})
+	}
+}
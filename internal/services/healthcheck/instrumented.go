@@ -0,0 +1,21 @@
+package healthcheckservice
+
+import (
+	"context"
+
+	"github.com/worlve/sp-service/internal/services/instrumentation"
+)
+
+const serviceName = "healthcheck"
+
+// InstrumentedHealthcheckService is a HealthcheckService that records a span and counts the errors for each of its methods.
+type InstrumentedHealthcheckService struct {
+	HealthcheckService
+}
+
+// IsHealthy see HealthcheckService.IsHealthy
+func (s InstrumentedHealthcheckService) IsHealthy(ctx context.Context) (bool, error) {
+	ctx, end := instrumentation.Start(ctx, serviceName, "IsHealthy")
+	result, err := s.HealthcheckService.IsHealthy(ctx)
+	return result, end(err)
+}
@@ -0,0 +1,181 @@
+package healthcheckservice
+
+import (
+	"context"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/worlve/sp-service/internal/util/clock"
+	"github.com/pkg/errors"
+)
+
+// Statuses reported for readiness and its components.
+const (
+	StatusOK       = "ok"
+	StatusError    = "error"
+	StatusDraining = "draining"
+)
+
+const defaultCheckTimeout = 2 * time.Second
+
+// Check is a dependency that must be healthy for the service to be ready for traffic.
+type Check struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+// ComponentReport is the result of a single readiness check.
+// LastError is the most recent error the check returned, even if it has passed since.
+type ComponentReport struct {
+	Name        string     `json:"name"`
+	Status      string     `json:"status"`
+	LatencyMS   float64    `json:"latencyMs"`
+	Error       string     `json:"error,omitempty"`
+	LastError   string     `json:"lastError,omitempty"`
+	LastErrorAt *time.Time `json:"lastErrorAt,omitempty"`
+}
+
+// ReadinessReport is the result of every readiness check.
+type ReadinessReport struct {
+	Status     string            `json:"status"`
+	Components []ComponentReport `json:"components"`
+}
+
+// IsReady is true when the service should receive traffic.
+func (r ReadinessReport) IsReady() bool {
+	return r.Status == StatusOK
+}
+
+type lastError struct {
+	message string
+	at      time.Time
+}
+
+// Readiness runs the readiness checks and remembers the last error of each.
+// It's shared by every request, so it must be used as a pointer.
+type Readiness struct {
+	Checks []Check
+	// Timeout limits how long each check can run; it defaults to 2 seconds.
+	Timeout time.Duration
+	Clock   clock.Clock
+
+	draining   int32
+	mu         sync.Mutex
+	lastErrors map[string]lastError
+}
+
+// Drain marks the service as not ready so traffic moves elsewhere before it shuts down.
+func (r *Readiness) Drain() {
+	atomic.StoreInt32(&r.draining, 1)
+}
+
+// IsDraining is true once Drain has been called.
+func (r *Readiness) IsDraining() bool {
+	return atomic.LoadInt32(&r.draining) == 1
+}
+
+// Check runs every check concurrently and reports their results in the order of Checks.
+func (r *Readiness) Check(ctx context.Context) ReadinessReport {
+	report := ReadinessReport{
+		Status:     StatusOK,
+		Components: make([]ComponentReport, len(r.Checks)),
+	}
+	var wg sync.WaitGroup
+	for index := range r.Checks {
+		wg.Add(1)
+		go func(index int) {
+			defer wg.Done()
+			report.Components[index] = r.runCheck(ctx, r.Checks[index])
+		}(index)
+	}
+	wg.Wait()
+	for _, component := range report.Components {
+		if component.Status != StatusOK {
+			report.Status = StatusError
+		}
+	}
+	if r.IsDraining() {
+		report.Status = StatusDraining
+	}
+	return report
+}
+
+func (r *Readiness) runCheck(ctx context.Context, check Check) ComponentReport {
+	timeout := r.Timeout
+	if timeout <= 0 {
+		timeout = defaultCheckTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	start := time.Now()
+	err := runWithContext(ctx, check.Check)
+	component := ComponentReport{
+		Name:      check.Name,
+		Status:    StatusOK,
+		LatencyMS: float64(time.Since(start)) / float64(time.Millisecond),
+	}
+	if err != nil {
+		component.Status = StatusError
+		component.Error = err.Error()
+	}
+	last, ok := r.recordError(check.Name, err)
+	if ok {
+		component.LastError = last.message
+		component.LastErrorAt = &last.at
+	}
+	return component
+}
+
+// runWithContext returns early with the context's error when the check doesn't return before ctx is done.
+func runWithContext(ctx context.Context, check func(ctx context.Context) error) error {
+	result := make(chan error, 1)
+	go func() {
+		result <- check(ctx)
+	}()
+	select {
+	case err := <-result:
+		return err
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err(), "check did not finish")
+	}
+}
+
+// recordError remembers err as the check's last error and returns the last error recorded for the check.
+func (r *Readiness) recordError(name string, err error) (lastError, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.lastErrors == nil {
+		r.lastErrors = map[string]lastError{}
+	}
+	if err != nil {
+		r.lastErrors[name] = lastError{
+			message: err.Error(),
+			at:      r.now().UTC(),
+		}
+	}
+	last, ok := r.lastErrors[name]
+	return last, ok
+}
+
+func (r *Readiness) now() time.Time {
+	if r.Clock == nil {
+		return time.Now()
+	}
+	return r.Clock.Now()
+}
+
+// CheckDir returns a readiness check that errors unless the directory exists.
+func CheckDir(path string) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return errors.Errorf("%v is not a directory", path)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,118 @@
+package healthcheckservice
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/worlve/sp-service/internal/util/clock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadiness(t *testing.T) {
+	checkTime := time.Date(2020, time.January, 2, 3, 4, 5, 0, time.UTC)
+	passing := Check{Name: "passing", Check: func(ctx context.Context) error { return nil }}
+	failing := Check{Name: "failing", Check: func(ctx context.Context) error { return errors.New("failure") }}
+	slow := Check{Name: "slow", Check: func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	}}
+	cases := []struct {
+		name             string
+		paramChecks      []Check
+		paramDrain       bool
+		returnStatus     string
+		returnComponents []ComponentReport
+	}{
+		{
+			name:         "test every check passes",
+			paramChecks:  []Check{passing},
+			returnStatus: StatusOK,
+			returnComponents: []ComponentReport{
+				{Name: "passing", Status: StatusOK},
+			},
+		},
+		{
+			name:         "test a failing check is not ready",
+			paramChecks:  []Check{passing, failing},
+			returnStatus: StatusError,
+			returnComponents: []ComponentReport{
+				{Name: "passing", Status: StatusOK},
+				{Name: "failing", Status: StatusError, Error: "failure", LastError: "failure", LastErrorAt: &checkTime},
+			},
+		},
+		{
+			name:         "test a check past its timeout is not ready",
+			paramChecks:  []Check{slow},
+			returnStatus: StatusError,
+			returnComponents: []ComponentReport{
+				{Name: "slow", Status: StatusError, Error: "check did not finish: context deadline exceeded", LastError: "check did not finish: context deadline exceeded", LastErrorAt: &checkTime},
+			},
+		},
+		{
+			name:         "test draining is not ready",
+			paramChecks:  []Check{passing},
+			paramDrain:   true,
+			returnStatus: StatusDraining,
+			returnComponents: []ComponentReport{
+				{Name: "passing", Status: StatusOK},
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			readiness := &Readiness{
+				Checks:  tc.paramChecks,
+				Timeout: 10 * time.Millisecond,
+				Clock:   clock.MockClock{MockedTime: &checkTime},
+			}
+			if tc.paramDrain {
+				readiness.Drain()
+			}
+			report := HealthcheckService{Readiness: readiness}.Ready(context.Background())
+			require.Equal(t, tc.returnStatus, report.Status)
+			require.Equal(t, tc.returnStatus == StatusOK, report.IsReady())
+			for index := range report.Components {
+				report.Components[index].LatencyMS = 0
+			}
+			require.Equal(t, tc.returnComponents, report.Components)
+		})
+	}
+}
+
+func TestReadinessKeepsLastError(t *testing.T) {
+	checkTime := time.Date(2020, time.January, 2, 3, 4, 5, 0, time.UTC)
+	fail := true
+	readiness := &Readiness{
+		Checks: []Check{{Name: "flaky", Check: func(ctx context.Context) error {
+			if fail {
+				return errors.New("failure")
+			}
+			return nil
+		}}},
+		Clock: clock.MockClock{MockedTime: &checkTime},
+	}
+	report := readiness.Check(context.Background())
+	require.Equal(t, StatusError, report.Status)
+	fail = false
+	report = readiness.Check(context.Background())
+	require.Equal(t, StatusOK, report.Status)
+	require.Empty(t, report.Components[0].Error)
+	require.Equal(t, "failure", report.Components[0].LastError)
+	require.Equal(t, &checkTime, report.Components[0].LastErrorAt)
+}
+
+func TestCheckDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "checkdir")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "file")
+	require.NoError(t, ioutil.WriteFile(file, []byte("file"), 0644))
+	require.NoError(t, CheckDir(dir)(context.Background()))
+	require.EqualError(t, CheckDir(file)(context.Background()), file+" is not a directory")
+	require.Error(t, CheckDir(filepath.Join(dir, "missing"))(context.Background()))
+}
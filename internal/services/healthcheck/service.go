@@ -9,9 +9,18 @@ import (
 // HealthcheckService is the service for handling healthcheck-related APIs
 type HealthcheckService struct {
 	HealthcheckStore store.HealthcheckStore
+	Readiness        *Readiness
 }
 
 // IsHealthy creates a new healthcheck.
 func (s HealthcheckService) IsHealthy(ctx context.Context) (bool, error) {
-	return s.HealthcheckStore.IsHealthy()
+	return s.HealthcheckStore.IsHealthy(ctx)
+}
+
+// Ready reports whether the service and its dependencies are ready for traffic.
+func (s HealthcheckService) Ready(ctx context.Context) ReadinessReport {
+	if s.Readiness == nil {
+		return ReadinessReport{Status: StatusOK, Components: []ComponentReport{}}
+	}
+	return s.Readiness.Check(ctx)
 }
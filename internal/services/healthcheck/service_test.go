@@ -8,6 +8,7 @@ import (
 
 	"github.com/worlve/sp-service/internal/stores/store/mocks"
 	"github.com/worlve/sp-service/internal/util/testutils"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
@@ -57,7 +58,7 @@ func TestIsHealthy(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			healthcheckStore := new(mocks.HealthcheckStore)
 			for index := range tc.isHealthyCalls {
-				healthcheckStore.On("IsHealthy").Return(tc.isHealthyCalls[index].returnIsHealthy, tc.isHealthyCalls[index].returnErr)
+				healthcheckStore.On("IsHealthy", mock.Anything).Return(tc.isHealthyCalls[index].returnIsHealthy, tc.isHealthyCalls[index].returnErr)
 			}
 			healthcheckService = HealthcheckService{
 				HealthcheckStore: healthcheckStore,
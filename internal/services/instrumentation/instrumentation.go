@@ -0,0 +1,56 @@
+// Package instrumentation records spans and metrics for the service methods.
+package instrumentation
+
+import (
+	"context"
+
+	"github.com/worlve/sp-service/internal/stores/storeerror"
+	"github.com/worlve/sp-service/internal/util/metrics"
+	"github.com/worlve/sp-service/internal/util/tracing"
+	"github.com/pkg/errors"
+)
+
+var errorsTotal = metrics.Default.NewCounterVec("sp_service_errors_total",
+	"Total number of errors returned by service methods.",
+	"service", "method", "type")
+
+// Start starts a span for the service's method.
+// The returned func must be called with the method's error to end the span and count the error; it returns the error as is.
+func Start(ctx context.Context, service, method string) (context.Context, func(err error) error) {
+	ctx, span := tracing.StartSpan(ctx, service+"."+method, tracing.SpanKindInternal)
+	return ctx, func(err error) error {
+		span.RecordError(err)
+		span.End()
+		return ObserveError(service, method, err)
+	}
+}
+
+// ObserveError counts err against the service's method when it isn't nil, then returns it as is.
+func ObserveError(service, method string, err error) error {
+	if err != nil {
+		errorsTotal.Inc(service, method, errorType(err))
+	}
+	return err
+}
+
+// ErrorCount returns the number of errors of the given type counted for the service's method.
+func ErrorCount(service, method, errType string) float64 {
+	return errorsTotal.Value(service, method, errType)
+}
+
+func errorType(err error) string {
+	switch errors.Cause(err).(type) {
+	case *storeerror.NotFound:
+		return "notFound"
+	case *storeerror.NotAuthorized:
+		return "notAuthorized"
+	case *storeerror.DupEntry:
+		return "dupEntry"
+	case *storeerror.DBNotSetUp:
+		return "dbNotSetUp"
+	case *storeerror.Canceled:
+		return "canceled"
+	default:
+		return "other"
+	}
+}
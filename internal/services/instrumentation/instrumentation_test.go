@@ -0,0 +1,61 @@
+package instrumentation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/worlve/sp-service/internal/stores/storeerror"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObserveError(t *testing.T) {
+	cases := []struct {
+		name          string
+		paramMethod   string
+		paramErr      error
+		returnErrType string
+		returnCount   float64
+	}{
+		{
+			name:        "test nil error is not counted",
+			paramMethod: "NoError",
+			returnCount: 0,
+		},
+		{
+			name:          "test wrapped not found error",
+			paramMethod:   "NotFound",
+			paramErr:      errors.Wrap(&storeerror.NotFound{ID: "PG_1"}, "failed"),
+			returnErrType: "notFound",
+			returnCount:   1,
+		},
+		{
+			name:          "test dup entry error",
+			paramMethod:   "DupEntry",
+			paramErr:      &storeerror.DupEntry{ID: "PG_1"},
+			returnErrType: "dupEntry",
+			returnCount:   1,
+		},
+		{
+			name:          "test canceled error",
+			paramMethod:   "Canceled",
+			paramErr:      &storeerror.Canceled{Err: context.Canceled},
+			returnErrType: "canceled",
+			returnCount:   1,
+		},
+		{
+			name:          "test unknown error",
+			paramMethod:   "Other",
+			paramErr:      errors.New("failed"),
+			returnErrType: "other",
+			returnCount:   1,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ObserveError("test", tc.paramMethod, tc.paramErr)
+			require.Equal(t, tc.paramErr, err)
+			require.Equal(t, tc.returnCount, ErrorCount("test", tc.paramMethod, tc.returnErrType))
+		})
+	}
+}
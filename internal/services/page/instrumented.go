@@ -0,0 +1,102 @@
+package pageservice
+
+import (
+	"context"
+
+	"github.com/worlve/sp-service/internal/models/page"
+	"github.com/worlve/sp-service/internal/models/property"
+	"github.com/worlve/sp-service/internal/services/instrumentation"
+)
+
+const serviceName = "page"
+
+// InstrumentedPageService is a PageService that records a span and counts the errors for each of its methods.
+type InstrumentedPageService struct {
+	PageService
+}
+
+// CreatePage see PageService.CreatePage
+func (s InstrumentedPageService) CreatePage(ctx context.Context, params CreatePageParams) (page.Page, error) {
+	ctx, end := instrumentation.Start(ctx, serviceName, "CreatePage")
+	result, err := s.PageService.CreatePage(ctx, params)
+	return result, end(err)
+}
+
+// UpdatePage see PageService.UpdatePage
+func (s InstrumentedPageService) UpdatePage(ctx context.Context, params UpdatePageParams) error {
+	ctx, end := instrumentation.Start(ctx, serviceName, "UpdatePage")
+	return end(s.PageService.UpdatePage(ctx, params))
+}
+
+// GetPage see PageService.GetPage
+func (s InstrumentedPageService) GetPage(ctx context.Context, params GetPageParams) (page.Page, error) {
+	ctx, end := instrumentation.Start(ctx, serviceName, "GetPage")
+	result, err := s.PageService.GetPage(ctx, params)
+	return result, end(err)
+}
+
+// GetEntirePage see PageService.GetEntirePage
+func (s InstrumentedPageService) GetEntirePage(ctx context.Context, params GetEntirePageParams) (page.Page, error) {
+	ctx, end := instrumentation.Start(ctx, serviceName, "GetEntirePage")
+	result, err := s.PageService.GetEntirePage(ctx, params)
+	return result, end(err)
+}
+
+// GetPages see PageService.GetPages
+func (s InstrumentedPageService) GetPages(ctx context.Context, params GetPagesParams) ([]page.Page, int, string, error) {
+	ctx, end := instrumentation.Start(ctx, serviceName, "GetPages")
+	results, total, nextBatchID, err := s.PageService.GetPages(ctx, params)
+	return results, total, nextBatchID, end(err)
+}
+
+// RemovePage see PageService.RemovePage
+func (s InstrumentedPageService) RemovePage(ctx context.Context, params RemovePageParams) error {
+	ctx, end := instrumentation.Start(ctx, serviceName, "RemovePage")
+	return end(s.PageService.RemovePage(ctx, params))
+}
+
+// GetRemovedPages see PageService.GetRemovedPages
+func (s InstrumentedPageService) GetRemovedPages(ctx context.Context, params GetRemovedPagesParams) ([]page.Page, int, string, error) {
+	ctx, end := instrumentation.Start(ctx, serviceName, "GetRemovedPages")
+	results, total, nextBatchID, err := s.PageService.GetRemovedPages(ctx, params)
+	return results, total, nextBatchID, end(err)
+}
+
+// RestorePage see PageService.RestorePage
+func (s InstrumentedPageService) RestorePage(ctx context.Context, params RestorePageParams) error {
+	ctx, end := instrumentation.Start(ctx, serviceName, "RestorePage")
+	return end(s.PageService.RestorePage(ctx, params))
+}
+
+// PurgePage see PageService.PurgePage
+func (s InstrumentedPageService) PurgePage(ctx context.Context, params PurgePageParams) error {
+	ctx, end := instrumentation.Start(ctx, serviceName, "PurgePage")
+	return end(s.PageService.PurgePage(ctx, params))
+}
+
+// PurgeRemovedPages see PageService.PurgeRemovedPages
+func (s InstrumentedPageService) PurgeRemovedPages(ctx context.Context, params PurgeRemovedPagesParams) (int, error) {
+	ctx, end := instrumentation.Start(ctx, serviceName, "PurgeRemovedPages")
+	count, err := s.PageService.PurgeRemovedPages(ctx, params)
+	return count, end(err)
+}
+
+// GetPageProperties see PageService.GetPageProperties
+func (s InstrumentedPageService) GetPageProperties(ctx context.Context, params GetPagePropertiesParams) ([]property.Property, error) {
+	ctx, end := instrumentation.Start(ctx, serviceName, "GetPageProperties")
+	results, err := s.PageService.GetPageProperties(ctx, params)
+	return results, end(err)
+}
+
+// ReplacePageProperties see PageService.ReplacePageProperties
+func (s InstrumentedPageService) ReplacePageProperties(ctx context.Context, params ReplacePagePropertiesParams) error {
+	ctx, end := instrumentation.Start(ctx, serviceName, "ReplacePageProperties")
+	return end(s.PageService.ReplacePageProperties(ctx, params))
+}
+
+// BatchPages see PageService.BatchPages
+func (s InstrumentedPageService) BatchPages(ctx context.Context, params BatchPagesParams) ([]BatchOperationResult, error) {
+	ctx, end := instrumentation.Start(ctx, serviceName, "BatchPages")
+	results, err := s.PageService.BatchPages(ctx, params)
+	return results, end(err)
+}
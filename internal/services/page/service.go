@@ -2,11 +2,17 @@ package pageservice
 
 import (
 	"context"
+	"time"
 
+	"github.com/worlve/sp-service/internal/models/event"
 	"github.com/worlve/sp-service/internal/models/page"
 	"github.com/worlve/sp-service/internal/models/property"
+	eventservice "github.com/worlve/sp-service/internal/services/event"
 	"github.com/worlve/sp-service/internal/stores/store"
+	"github.com/worlve/sp-service/internal/util/auditlog"
+	"github.com/worlve/sp-service/internal/util/logger"
 	"github.com/pkg/errors"
+	"go.uber.org/zap"
 )
 
 // PageService is the service for handling page-related APIs
@@ -15,6 +21,10 @@ type PageService struct {
 	PageTemplateStore store.PageTemplateStore
 	VersionStore      store.VersionStore
 	UserStore         store.UserStore
+	// UnitOfWork runs each change along with adding its event to the outbox.
+	UnitOfWork store.UnitOfWork
+	// eventStore is the outbox of the unit of work the service is bound to; see withinUnitOfWork.
+	eventStore store.EventStore
 }
 
 // CreatePageParams params for CreatePage
@@ -25,33 +35,41 @@ type CreatePageParams struct {
 
 // CreatePage creates a new page.
 func (s PageService) CreatePage(ctx context.Context, params CreatePageParams) (page.Page, error) {
-	err := s.populatePageIDs(ctx, &params.Page)
-	if err != nil {
-		return page.Page{}, err
-	}
-	pageGUID, err := s.PageStore.GetUniquePageGUID(params.Page.GUID)
-	if err != nil {
-		return page.Page{}, err
-	}
-	params.Page.GUID = pageGUID
-	u, err := s.UserStore.GetUser(params.OwnerID)
-	page, err := s.PageStore.CreatePage(params.Page, u.ID)
+	var created page.Page
+	err := s.withinUnitOfWork(ctx, func(tx PageService) error {
+		err := tx.populatePageIDs(ctx, &params.Page)
+		if err != nil {
+			return err
+		}
+		pageGUID, err := tx.PageStore.GetUniquePageGUID(ctx, params.Page.GUID)
+		if err != nil {
+			return err
+		}
+		params.Page.GUID = pageGUID
+		u, err := tx.UserStore.GetUser(ctx, params.OwnerID)
+		created, err = tx.PageStore.CreatePage(ctx, params.Page, u.ID)
+		if err != nil {
+			return errors.Wrapf(err, "failed to create page: %+v", params)
+		}
+		return eventservice.Emit(ctx, tx.eventStore, event.PageCreated{PageID: created.GUID, Page: created.Reduce()}, params.OwnerID)
+	})
 	if err != nil {
-		return page, errors.Wrapf(err, "failed to create page: %+v", params)
+		return created, err
 	}
-	return page, nil
+	auditlog.Record(ctx, auditlog.Change{TargetID: created.GUID, PageID: created.GUID, After: created.Reduce()})
+	return created, nil
 }
 
 func (s PageService) populatePageIDs(ctx context.Context, p *page.Page) error {
 	if p.PageTemplate.GUID != "" {
-		pt, err := s.PageTemplateStore.GetPageTemplate(p.PageTemplate.GUID)
+		pt, err := s.PageTemplateStore.GetPageTemplate(ctx, p.PageTemplate.GUID)
 		if err != nil {
 			return err
 		}
 		p.PageTemplate = pt
 	}
 	if p.Version.GUID != "" {
-		v, err := s.VersionStore.GetVersion(p.Version.GUID)
+		v, err := s.VersionStore.GetVersion(ctx, p.Version.GUID)
 		if err != nil {
 			return err
 		}
@@ -68,18 +86,34 @@ type UpdatePageParams struct {
 
 // UpdatePage sets a page to what is provided.
 func (s PageService) UpdatePage(ctx context.Context, params UpdatePageParams) error {
-	_, err := s.PageStore.CanEditPage(params.Page.GUID, params.UserID)
-	if err != nil {
-		return err
-	}
-	err = s.populatePageIDs(ctx, &params.Page)
+	var before, updated page.Page
+	err := s.withinUnitOfWork(ctx, func(tx PageService) error {
+		_, err := tx.PageStore.CanEditPage(ctx, params.Page.GUID, params.UserID)
+		if err != nil {
+			return err
+		}
+		err = tx.populatePageIDs(ctx, &params.Page)
+		if err != nil {
+			return err
+		}
+		before, err = tx.PageStore.GetPage(ctx, params.Page.GUID)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get page: %+v", params)
+		}
+		err = tx.PageStore.UpdatePage(ctx, params.Page)
+		if err != nil {
+			return errors.Wrapf(err, "failed to update page: %+v", params)
+		}
+		updated, err = tx.PageStore.GetPage(ctx, params.Page.GUID)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get updated page: %+v", params)
+		}
+		return eventservice.Emit(ctx, tx.eventStore, event.PageUpdated{PageID: updated.GUID, Page: updated.Reduce()}, params.UserID)
+	})
 	if err != nil {
 		return err
 	}
-	err = s.PageStore.UpdatePage(params.Page)
-	if err != nil {
-		return errors.Wrapf(err, "failed to update page: %+v", params)
-	}
+	auditlog.Record(ctx, auditlog.Change{TargetID: updated.GUID, PageID: updated.GUID, Before: before.Reduce(), After: updated.Reduce()})
 	return nil
 }
 
@@ -91,11 +125,11 @@ type GetPageParams struct {
 
 // GetPage returns just the page entity.
 func (s PageService) GetPage(ctx context.Context, params GetPageParams) (page.Page, error) {
-	_, err := s.PageStore.CanReadPage(params.Page.GUID, params.UserID)
+	_, err := s.PageStore.CanReadPage(ctx, params.Page.GUID, params.UserID)
 	if err != nil {
 		return page.Page{}, err
 	}
-	p, err := s.PageStore.GetPage(params.Page.GUID)
+	p, err := s.PageStore.GetPage(ctx, params.Page.GUID)
 	if err != nil {
 		return p, errors.Wrapf(err, "failed to get page: %+v", params)
 	}
@@ -110,10 +144,6 @@ type GetEntirePageParams struct {
 
 // GetEntirePage returns a full page object, with properties, details, etc.
 func (s PageService) GetEntirePage(ctx context.Context, params GetEntirePageParams) (page.Page, error) {
-	_, err := s.PageStore.CanReadPage(params.Page.GUID, params.UserID)
-	if err != nil {
-		return page.Page{}, err
-	}
 	p, err := s.GetPage(ctx, GetPageParams{
 		Page:   params.Page,
 		UserID: params.UserID,
@@ -136,7 +166,7 @@ type GetPagesParams struct {
 
 // GetPages returns a list of pages filtered and ordered as specified.
 func (s PageService) GetPages(ctx context.Context, params GetPagesParams) ([]page.Page, int, string, error) {
-	ps, total, nextBatchID, err := s.PageStore.GetPages(params.UserID, params.NextBatchID, 10)
+	ps, total, nextBatchID, err := s.PageStore.GetPages(ctx, params.UserID, params.NextBatchID, 10)
 	if err != nil {
 		return ps, total, nextBatchID, errors.Wrapf(err, "failed to get pages: %+v", params)
 	}
@@ -151,17 +181,117 @@ type RemovePageParams struct {
 
 // RemovePage marks the page as removed.
 func (s PageService) RemovePage(ctx context.Context, params RemovePageParams) error {
-	_, err := s.PageStore.CanEditPage(params.Page.GUID, params.UserID)
+	var before page.Page
+	err := s.withinUnitOfWork(ctx, func(tx PageService) error {
+		_, err := tx.PageStore.CanEditPage(ctx, params.Page.GUID, params.UserID)
+		if err != nil {
+			return err
+		}
+		before, err = tx.PageStore.GetPage(ctx, params.Page.GUID)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get page: %+v", params)
+		}
+		err = tx.PageStore.RemovePage(ctx, params.Page.GUID)
+		if err != nil {
+			return errors.Wrapf(err, "failed to remove page: %+v", params)
+		}
+		return eventservice.Emit(ctx, tx.eventStore, event.PageRemoved{PageID: params.Page.GUID}, params.UserID)
+	})
+	if err != nil {
+		return err
+	}
+	auditlog.Record(ctx, auditlog.Change{TargetID: params.Page.GUID, PageID: params.Page.GUID, Before: before.Reduce()})
+	return nil
+}
+
+// GetRemovedPagesParams params for GetRemovedPages
+type GetRemovedPagesParams struct {
+	NextBatchID string
+	UserID      string
+}
+
+// GetRemovedPages returns a list of the user's removed pages, which can still be restored or purged.
+func (s PageService) GetRemovedPages(ctx context.Context, params GetRemovedPagesParams) ([]page.Page, int, string, error) {
+	ps, total, nextBatchID, err := s.PageStore.GetRemovedPages(ctx, params.UserID, params.NextBatchID, 10)
+	if err != nil {
+		return ps, total, nextBatchID, errors.Wrapf(err, "failed to get removed pages: %+v", params)
+	}
+	return ps, total, nextBatchID, nil
+}
+
+// RestorePageParams params for RestorePage
+type RestorePageParams struct {
+	Page   page.Page
+	UserID string
+}
+
+// RestorePage restores a removed page, along with its properties and details.
+func (s PageService) RestorePage(ctx context.Context, params RestorePageParams) error {
+	var restored page.Page
+	err := s.withinUnitOfWork(ctx, func(tx PageService) error {
+		_, err := tx.PageStore.CanEditPage(ctx, params.Page.GUID, params.UserID)
+		if err != nil {
+			return err
+		}
+		err = tx.PageStore.RestorePage(ctx, params.Page.GUID)
+		if err != nil {
+			return errors.Wrapf(err, "failed to restore page: %+v", params)
+		}
+		restored, err = tx.PageStore.GetPage(ctx, params.Page.GUID)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get restored page: %+v", params)
+		}
+		return eventservice.Emit(ctx, tx.eventStore, event.PageRestored{PageID: params.Page.GUID, Page: restored.Reduce()}, params.UserID)
+	})
 	if err != nil {
 		return err
 	}
-	err = s.PageStore.RemovePage(params.Page.GUID)
+	auditlog.Record(ctx, auditlog.Change{TargetID: params.Page.GUID, PageID: params.Page.GUID, After: restored.Reduce()})
+	return nil
+}
+
+// PurgePageParams params for PurgePage
+type PurgePageParams struct {
+	Page   page.Page
+	UserID string
+}
+
+// PurgePage permanently deletes a removed page. Its audit entry has no summary of the page, since that was recorded
+// when it was removed.
+func (s PageService) PurgePage(ctx context.Context, params PurgePageParams) error {
+	err := s.withinUnitOfWork(ctx, func(tx PageService) error {
+		_, err := tx.PageStore.CanEditPage(ctx, params.Page.GUID, params.UserID)
+		if err != nil {
+			return err
+		}
+		err = tx.PageStore.PurgePage(ctx, params.Page.GUID)
+		if err != nil {
+			return errors.Wrapf(err, "failed to purge page: %+v", params)
+		}
+		return eventservice.Emit(ctx, tx.eventStore, event.PagePurged{PageID: params.Page.GUID}, params.UserID)
+	})
 	if err != nil {
-		return errors.Wrapf(err, "failed to remove page: %+v", params)
+		return err
 	}
+	auditlog.Record(ctx, auditlog.Change{TargetID: params.Page.GUID, PageID: params.Page.GUID})
 	return nil
 }
 
+// PurgeRemovedPagesParams params for PurgeRemovedPages
+type PurgeRemovedPagesParams struct {
+	RemovedBefore time.Time
+}
+
+// PurgeRemovedPages permanently deletes every page removed before the given time.
+// Returns the number of pages purged.
+func (s PageService) PurgeRemovedPages(ctx context.Context, params PurgeRemovedPagesParams) (int, error) {
+	count, err := s.PageStore.PurgeRemovedPages(ctx, params.RemovedBefore)
+	if err != nil {
+		return count, errors.Wrapf(err, "failed to purge removed pages: %+v", params)
+	}
+	return count, nil
+}
+
 // GetPagePropertiesParams params for GetPageProperties
 type GetPagePropertiesParams struct {
 	Page   page.Page
@@ -171,11 +301,11 @@ type GetPagePropertiesParams struct {
 // GetPageProperties returns the page's properties.
 func (s PageService) GetPageProperties(ctx context.Context, params GetPagePropertiesParams) ([]property.Property, error) {
 	ps := make([]property.Property, 0)
-	_, err := s.PageStore.CanReadPage(params.Page.GUID, params.UserID)
+	_, err := s.PageStore.CanReadPage(ctx, params.Page.GUID, params.UserID)
 	if err != nil {
 		return ps, err
 	}
-	ps, err = s.PageStore.GetPageProperties(params.Page.GUID)
+	ps, err = s.PageStore.GetPageProperties(ctx, params.Page.GUID)
 	if err != nil {
 		return ps, errors.Wrapf(err, "failed to get page properties: %+v", params)
 	}
@@ -191,13 +321,172 @@ type ReplacePagePropertiesParams struct {
 
 // ReplacePageProperties replaces the current page's properties with the new properties.
 func (s PageService) ReplacePageProperties(ctx context.Context, params ReplacePagePropertiesParams) error {
-	_, err := s.PageStore.CanEditPage(params.Page.GUID, params.UserID)
+	var before []property.Property
+	err := s.withinUnitOfWork(ctx, func(tx PageService) error {
+		_, err := tx.PageStore.CanEditPage(ctx, params.Page.GUID, params.UserID)
+		if err != nil {
+			return err
+		}
+		before, err = tx.PageStore.GetPageProperties(ctx, params.Page.GUID)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get page properties: %+v", params)
+		}
+		err = tx.PageStore.ReplacePageProperties(ctx, params.Page.GUID, params.Properties)
+		if err != nil {
+			return errors.Wrapf(err, "failed to replace page properties: %+v", params)
+		}
+		return eventservice.Emit(ctx, tx.eventStore, event.PropertiesReplaced{PageID: params.Page.GUID, Properties: params.Properties}, params.UserID)
+	})
 	if err != nil {
 		return err
 	}
-	err = s.PageStore.ReplacePageProperties(params.Page.GUID, params.Properties)
-	if err != nil {
-		return errors.Wrapf(err, "failed to replace page properties: %+v", params)
-	}
+	auditlog.Record(ctx, auditlog.Change{TargetID: params.Page.GUID, PageID: params.Page.GUID, Before: before, After: params.Properties})
 	return nil
 }
+
+// BatchOperationType is a valid type of operation within a batch.
+type BatchOperationType string
+
+// valid BatchOperationType values.
+const (
+	BatchOperationCreate     BatchOperationType = "create"
+	BatchOperationUpdate     BatchOperationType = "update"
+	BatchOperationRemove     BatchOperationType = "remove"
+	BatchOperationPermission BatchOperationType = "permission"
+)
+
+// ErrBatchRolledBack is the result err of an operation that succeeded, but was rolled back because
+// another operation within the same atomic batch failed.
+var ErrBatchRolledBack = errors.New("rolled back due to a failed operation in the batch")
+
+// ErrBatchNotAttempted is the result err of an operation that was never attempted because an
+// earlier operation within the same atomic batch failed.
+var ErrBatchNotAttempted = errors.New("not attempted due to a failed operation in the batch")
+
+// BatchOperation is a single page operation within a batch.
+type BatchOperation struct {
+	Type BatchOperationType
+	Page page.Page
+}
+
+// BatchOperationResult is the outcome of the BatchOperation of the same index.
+type BatchOperationResult struct {
+	Type BatchOperationType
+	Page page.Page
+	Err  error
+}
+
+// BatchPagesParams params for BatchPages
+type BatchPagesParams struct {
+	Operations []BatchOperation
+	Atomic     bool
+	UserID     string
+}
+
+// BatchPages runs each of the given operations in order and returns a result for each of them.
+// If Atomic is set, all operations are run within a single transaction: if any operation fails
+// then none of the operations are kept.
+func (s PageService) BatchPages(ctx context.Context, params BatchPagesParams) ([]BatchOperationResult, error) {
+	if !params.Atomic {
+		results := make([]BatchOperationResult, 0, len(params.Operations))
+		for _, operation := range params.Operations {
+			results = append(results, s.runBatchOperation(ctx, operation, params.UserID))
+		}
+		return results, nil
+	}
+	var results []BatchOperationResult
+	failedIndex := -1
+	// the operations' changes are only audited once the batch is committed, since they're rolled back with it
+	batchCtx, flushAudit := auditlog.WithBuffer(ctx)
+	err := s.withinUnitOfWork(batchCtx, func(txService PageService) error {
+		results = make([]BatchOperationResult, 0, len(params.Operations))
+		for i, operation := range params.Operations {
+			result := txService.runBatchOperation(batchCtx, operation, params.UserID)
+			results = append(results, result)
+			if result.Err != nil {
+				failedIndex = i
+				return result.Err
+			}
+		}
+		return nil
+	})
+	if failedIndex < 0 {
+		if err != nil {
+			return results, errors.Wrapf(err, "failed to run batch: %+v", params)
+		}
+		flushAudit()
+		return results, nil
+	}
+	logger.GetFromContext(ctx).Info("Batch rolled back",
+		zap.Int("failedIndex", failedIndex),
+		zap.Int("operations", len(params.Operations)),
+		zap.String("err", results[failedIndex].Err.Error()),
+	)
+	for i := range results {
+		if i != failedIndex {
+			results[i].Err = ErrBatchRolledBack
+		}
+	}
+	for _, operation := range params.Operations[len(results):] {
+		results = append(results, BatchOperationResult{
+			Type: operation.Type,
+			Page: operation.Page,
+			Err:  ErrBatchNotAttempted,
+		})
+	}
+	return results, nil
+}
+
+// withinUnitOfWork runs fn with a copy of the service bound to a unit of work, so a change and its event are kept or
+// rolled back together. A copy that's already bound to one runs fn within it, so operations can be combined.
+func (s PageService) withinUnitOfWork(ctx context.Context, fn func(tx PageService) error) error {
+	return s.UnitOfWork.Do(ctx, func(stores store.Stores) error {
+		return fn(s.withStores(stores))
+	})
+}
+
+// withStores returns a copy of the service that uses the given stores, such as those bound to a unit of work.
+func (s PageService) withStores(stores store.Stores) PageService {
+	s.PageStore = stores.PageStore
+	s.PageTemplateStore = stores.PageTemplateStore
+	s.VersionStore = stores.VersionStore
+	s.UserStore = stores.UserStore
+	s.eventStore = stores.EventStore
+	s.UnitOfWork = store.Bound(stores)
+	return s
+}
+
+func (s PageService) runBatchOperation(ctx context.Context, operation BatchOperation, userID string) BatchOperationResult {
+	result := BatchOperationResult{
+		Type: operation.Type,
+		Page: operation.Page,
+	}
+	switch operation.Type {
+	case BatchOperationCreate:
+		result.Page, result.Err = s.CreatePage(ctx, CreatePageParams{
+			Page:    operation.Page,
+			OwnerID: userID,
+		})
+	case BatchOperationUpdate:
+		result.Err = s.UpdatePage(ctx, UpdatePageParams{
+			Page:   operation.Page,
+			UserID: userID,
+		})
+	case BatchOperationPermission:
+		result.Err = s.UpdatePage(ctx, UpdatePageParams{
+			Page: page.Page{
+				GUID:           operation.Page.GUID,
+				PermissionType: operation.Page.PermissionType,
+			},
+			UserID: userID,
+		})
+	case BatchOperationRemove:
+		result.Err = s.RemovePage(ctx, RemovePageParams{
+			Page:   operation.Page,
+			UserID: userID,
+		})
+	default:
+		result.Err = errors.Errorf("unsupported batch operation type: %v", operation.Type)
+	}
+	return result
+}
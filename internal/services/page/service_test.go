@@ -6,13 +6,18 @@ import (
 	"os"
 	"testing"
 
+	"github.com/worlve/sp-service/internal/stores/store"
 	"github.com/worlve/sp-service/internal/stores/storeerror"
+	"github.com/worlve/sp-service/internal/util/auditlog"
 	"github.com/worlve/sp-service/internal/util/testutils"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
 	"github.com/worlve/sp-service/internal/models/appuser"
+	"github.com/worlve/sp-service/internal/models/event"
 	"github.com/worlve/sp-service/internal/models/page"
 	"github.com/worlve/sp-service/internal/models/pagetemplate"
+	"github.com/worlve/sp-service/internal/models/permission"
 	"github.com/worlve/sp-service/internal/models/version"
 	"github.com/worlve/sp-service/internal/stores/store/mocks"
 )
@@ -157,22 +162,32 @@ func TestUpdatePage(t *testing.T) {
 			pageTemplateStore := new(mocks.PageTemplateStore)
 			versionStore := new(mocks.VersionStore)
 			for index := range tc.getPageTemplateCalls {
-				pageTemplateStore.On("GetPageTemplate", tc.getPageTemplateCalls[index].paramPageTemplateGUID).Return(tc.getPageTemplateCalls[index].returnPageTemplate, tc.getPageTemplateCalls[index].returnErr)
+				pageTemplateStore.On("GetPageTemplate", mock.Anything, tc.getPageTemplateCalls[index].paramPageTemplateGUID).Return(tc.getPageTemplateCalls[index].returnPageTemplate, tc.getPageTemplateCalls[index].returnErr)
 			}
 			for index := range tc.getVersionCalls {
-				versionStore.On("GetVersion", tc.getVersionCalls[index].paramVersionGUID).Return(tc.getVersionCalls[index].returnVersion, tc.getVersionCalls[index].returnErr)
+				versionStore.On("GetVersion", mock.Anything, tc.getVersionCalls[index].paramVersionGUID).Return(tc.getVersionCalls[index].returnVersion, tc.getVersionCalls[index].returnErr)
 			}
 			for index := range tc.canEditPageCalls {
-				pageStore.On("CanEditPage", tc.canEditPageCalls[index].paramPageGUID, tc.canEditPageCalls[index].paramPageUserID).Return(tc.canEditPageCalls[index].returnIsOwner, tc.canEditPageCalls[index].returnErr)
+				pageStore.On("CanEditPage", mock.Anything, tc.canEditPageCalls[index].paramPageGUID, tc.canEditPageCalls[index].paramPageUserID).Return(tc.canEditPageCalls[index].returnIsOwner, tc.canEditPageCalls[index].returnErr)
 			}
 			for index := range tc.updatePageCalls {
-				pageStore.On("UpdatePage", tc.updatePageCalls[index].paramPage).Return(tc.updatePageCalls[index].returnErr)
+				pageStore.On("UpdatePage", mock.Anything, tc.updatePageCalls[index].paramPage).Return(tc.updatePageCalls[index].returnErr)
 			}
+			pageStore.On("GetPage", mock.Anything, mock.Anything).Return(func(ctx context.Context, guid string) page.Page {
+				return page.Page{GUID: guid}
+			}, nil)
+			unitOfWork, events := mockUnitOfWork(store.Stores{
+				PageStore:         pageStore,
+				PageTemplateStore: pageTemplateStore,
+				VersionStore:      versionStore,
+			}, []unitOfWorkDoCall{{}})
 			pageService = PageService{
 				PageStore:         pageStore,
 				PageTemplateStore: pageTemplateStore,
 				VersionStore:      versionStore,
+				UnitOfWork:        unitOfWork,
 			}
+			ctx, recorder := auditlog.WithRecorder(ctx)
 			err := pageService.UpdatePage(ctx, tc.params)
 			pageTemplateStore.AssertNumberOfCalls(t, "GetPageTemplate", len(tc.getPageTemplateCalls))
 			versionStore.AssertNumberOfCalls(t, "GetVersion", len(tc.getVersionCalls))
@@ -180,8 +195,12 @@ func TestUpdatePage(t *testing.T) {
 			pageStore.AssertNumberOfCalls(t, "UpdatePage", len(tc.updatePageCalls))
 			errExpected := testutils.TestErrorAgainstCase(t, err, tc.returnErr)
 			if errExpected {
+				require.Empty(t, *events)
+				require.Empty(t, recorder.Changes())
 				return
 			}
+			require.Equal(t, []event.Event{{Type: event.TypePageUpdated, PageID: tc.params.Page.GUID, UserID: tc.params.UserID}}, *events)
+			require.Equal(t, []auditlog.Change{{TargetID: tc.params.Page.GUID, PageID: tc.params.Page.GUID, Before: page.ReducedPage{GUID: tc.params.Page.GUID}, After: page.ReducedPage{GUID: tc.params.Page.GUID}}}, recorder.Changes())
 		})
 	}
 }
@@ -284,25 +303,32 @@ func TestCreatePage(t *testing.T) {
 			pageTemplateStore := new(mocks.PageTemplateStore)
 			versionStore := new(mocks.VersionStore)
 			for index := range tc.getUserCalls {
-				userStore.On("GetUser", tc.getUserCalls[index].paramUserGUID).Return(tc.getUserCalls[index].returnUser, tc.getUserCalls[index].returnErr)
+				userStore.On("GetUser", mock.Anything, tc.getUserCalls[index].paramUserGUID).Return(tc.getUserCalls[index].returnUser, tc.getUserCalls[index].returnErr)
 			}
 			for index := range tc.getPageTemplateCalls {
-				pageTemplateStore.On("GetPageTemplate", tc.getPageTemplateCalls[index].paramPageTemplateGUID).Return(tc.getPageTemplateCalls[index].returnPageTemplate, tc.getPageTemplateCalls[index].returnErr)
+				pageTemplateStore.On("GetPageTemplate", mock.Anything, tc.getPageTemplateCalls[index].paramPageTemplateGUID).Return(tc.getPageTemplateCalls[index].returnPageTemplate, tc.getPageTemplateCalls[index].returnErr)
 			}
 			for index := range tc.getVersionCalls {
-				versionStore.On("GetVersion", tc.getVersionCalls[index].paramVersionGUID).Return(tc.getVersionCalls[index].returnVersion, tc.getVersionCalls[index].returnErr)
+				versionStore.On("GetVersion", mock.Anything, tc.getVersionCalls[index].paramVersionGUID).Return(tc.getVersionCalls[index].returnVersion, tc.getVersionCalls[index].returnErr)
 			}
 			for index := range tc.getUniquePageGUIDCalls {
-				pageStore.On("GetUniquePageGUID", tc.getUniquePageGUIDCalls[index].paramProposedGUID).Return(tc.getUniquePageGUIDCalls[index].returnGUID, tc.getUniquePageGUIDCalls[index].returnErr)
+				pageStore.On("GetUniquePageGUID", mock.Anything, tc.getUniquePageGUIDCalls[index].paramProposedGUID).Return(tc.getUniquePageGUIDCalls[index].returnGUID, tc.getUniquePageGUIDCalls[index].returnErr)
 			}
 			for index := range tc.createPageCalls {
-				pageStore.On("CreatePage", tc.createPageCalls[index].paramPage, tc.createPageCalls[index].paramOwnerID).Return(tc.createPageCalls[index].returnPage, tc.createPageCalls[index].returnErr)
+				pageStore.On("CreatePage", mock.Anything, tc.createPageCalls[index].paramPage, tc.createPageCalls[index].paramOwnerID).Return(tc.createPageCalls[index].returnPage, tc.createPageCalls[index].returnErr)
 			}
+			unitOfWork, events := mockUnitOfWork(store.Stores{
+				PageStore:         pageStore,
+				PageTemplateStore: pageTemplateStore,
+				VersionStore:      versionStore,
+				UserStore:         userStore,
+			}, []unitOfWorkDoCall{{}})
 			pageService = PageService{
 				PageStore:         pageStore,
 				PageTemplateStore: pageTemplateStore,
 				VersionStore:      versionStore,
 				UserStore:         userStore,
+				UnitOfWork:        unitOfWork,
 			}
 			result, err := pageService.CreatePage(ctx, tc.params)
 			userStore.AssertNumberOfCalls(t, "GetUser", len(tc.getUserCalls))
@@ -312,9 +338,11 @@ func TestCreatePage(t *testing.T) {
 			pageStore.AssertNumberOfCalls(t, "CreatePage", len(tc.createPageCalls))
 			errExpected := testutils.TestErrorAgainstCase(t, err, tc.returnErr)
 			if errExpected {
+				require.Empty(t, *events)
 				return
 			}
 			require.Equal(t, tc.returnPage, result)
+			require.Equal(t, []event.Event{{Type: event.TypePageCreated, PageID: result.GUID, UserID: tc.params.OwnerID}}, *events)
 		})
 	}
 }
@@ -399,10 +427,10 @@ func TestGetPage(t *testing.T) {
 			pageTemplateStore := new(mocks.PageTemplateStore)
 			versionStore := new(mocks.VersionStore)
 			for index := range tc.canReadPageCalls {
-				pageStore.On("CanReadPage", tc.canReadPageCalls[index].paramPageGUID, tc.canReadPageCalls[index].paramPageUserID).Return(tc.canReadPageCalls[index].returnIsOwner, tc.canReadPageCalls[index].returnErr)
+				pageStore.On("CanReadPage", mock.Anything, tc.canReadPageCalls[index].paramPageGUID, tc.canReadPageCalls[index].paramPageUserID).Return(tc.canReadPageCalls[index].returnIsOwner, tc.canReadPageCalls[index].returnErr)
 			}
 			for index := range tc.getPageCalls {
-				pageStore.On("GetPage", tc.getPageCalls[index].paramPageGUID).Return(tc.getPageCalls[index].returnPage, tc.getPageCalls[index].returnErr)
+				pageStore.On("GetPage", mock.Anything, tc.getPageCalls[index].paramPageGUID).Return(tc.getPageCalls[index].returnPage, tc.getPageCalls[index].returnErr)
 			}
 			pageService = PageService{
 				PageStore:         pageStore,
@@ -502,16 +530,16 @@ func TestGetEntirePage(t *testing.T) {
 			pageTemplateStore := new(mocks.PageTemplateStore)
 			versionStore := new(mocks.VersionStore)
 			for index := range tc.canReadPageCalls {
-				pageStore.On("CanReadPage", tc.canReadPageCalls[index].paramPageGUID, tc.canReadPageCalls[index].paramPageUserID).Return(tc.canReadPageCalls[index].returnIsOwner, tc.canReadPageCalls[index].returnErr)
+				pageStore.On("CanReadPage", mock.Anything, tc.canReadPageCalls[index].paramPageGUID, tc.canReadPageCalls[index].paramPageUserID).Return(tc.canReadPageCalls[index].returnIsOwner, tc.canReadPageCalls[index].returnErr)
 			}
 			for index := range tc.getPageTemplateCalls {
-				pageTemplateStore.On("GetPageTemplate", tc.getPageTemplateCalls[index].paramPageTemplateGUID).Return(tc.getPageTemplateCalls[index].returnPageTemplate, tc.getPageTemplateCalls[index].returnErr)
+				pageTemplateStore.On("GetPageTemplate", mock.Anything, tc.getPageTemplateCalls[index].paramPageTemplateGUID).Return(tc.getPageTemplateCalls[index].returnPageTemplate, tc.getPageTemplateCalls[index].returnErr)
 			}
 			for index := range tc.getVersionCalls {
-				versionStore.On("GetVersion", tc.getVersionCalls[index].paramVersionGUID).Return(tc.getVersionCalls[index].returnVersion, tc.getVersionCalls[index].returnErr)
+				versionStore.On("GetVersion", mock.Anything, tc.getVersionCalls[index].paramVersionGUID).Return(tc.getVersionCalls[index].returnVersion, tc.getVersionCalls[index].returnErr)
 			}
 			for index := range tc.getPageCalls {
-				pageStore.On("GetPage", tc.getPageCalls[index].paramPageGUID).Return(tc.getPageCalls[index].returnPage, tc.getPageCalls[index].returnErr)
+				pageStore.On("GetPage", mock.Anything, tc.getPageCalls[index].paramPageGUID).Return(tc.getPageCalls[index].returnPage, tc.getPageCalls[index].returnErr)
 			}
 			pageService = PageService{
 				PageStore:         pageStore,
@@ -669,7 +697,7 @@ func TestGetPages(t *testing.T) {
 			pageTemplateStore := new(mocks.PageTemplateStore)
 			versionStore := new(mocks.VersionStore)
 			for index := range tc.getPagesCalls {
-				pageStore.On("GetPages", tc.getPagesCalls[index].paramUserID, tc.getPagesCalls[index].paramNextBatchID, tc.getPagesCalls[index].paramLimit).Return(tc.getPagesCalls[index].returnPages, tc.getPagesCalls[index].returnTotal, tc.getPagesCalls[index].returnNextBatchID, tc.getPagesCalls[index].returnErr)
+				pageStore.On("GetPages", mock.Anything, tc.getPagesCalls[index].paramUserID, tc.getPagesCalls[index].paramNextBatchID, tc.getPagesCalls[index].paramLimit).Return(tc.getPagesCalls[index].returnPages, tc.getPagesCalls[index].returnTotal, tc.getPagesCalls[index].returnNextBatchID, tc.getPagesCalls[index].returnErr)
 			}
 			pageService = PageService{
 				PageStore:         pageStore,
@@ -744,23 +772,430 @@ func TestRemovePage(t *testing.T) {
 			pageTemplateStore := new(mocks.PageTemplateStore)
 			versionStore := new(mocks.VersionStore)
 			for index := range tc.canEditPageCalls {
-				pageStore.On("CanEditPage", tc.canEditPageCalls[index].paramPageGUID, tc.canEditPageCalls[index].paramPageUserID).Return(tc.canEditPageCalls[index].returnIsOwner, tc.canEditPageCalls[index].returnErr)
+				pageStore.On("CanEditPage", mock.Anything, tc.canEditPageCalls[index].paramPageGUID, tc.canEditPageCalls[index].paramPageUserID).Return(tc.canEditPageCalls[index].returnIsOwner, tc.canEditPageCalls[index].returnErr)
 			}
 			for index := range tc.removePageCalls {
-				pageStore.On("RemovePage", tc.removePageCalls[index].paramPageGUID).Return(tc.removePageCalls[index].returnErr)
+				pageStore.On("RemovePage", mock.Anything, tc.removePageCalls[index].paramPageGUID).Return(tc.removePageCalls[index].returnErr)
 			}
+			pageStore.On("GetPage", mock.Anything, mock.Anything).Return(func(ctx context.Context, guid string) page.Page {
+				return page.Page{GUID: guid, Title: "Old Title"}
+			}, nil)
+			unitOfWork, events := mockUnitOfWork(store.Stores{
+				PageStore:         pageStore,
+				PageTemplateStore: pageTemplateStore,
+				VersionStore:      versionStore,
+			}, []unitOfWorkDoCall{{}})
 			pageService = PageService{
 				PageStore:         pageStore,
 				PageTemplateStore: pageTemplateStore,
 				VersionStore:      versionStore,
+				UnitOfWork:        unitOfWork,
 			}
+			ctx, recorder := auditlog.WithRecorder(ctx)
 			err := pageService.RemovePage(ctx, tc.params)
 			pageStore.AssertNumberOfCalls(t, "CanEditPage", len(tc.canEditPageCalls))
 			pageStore.AssertNumberOfCalls(t, "RemovePage", len(tc.removePageCalls))
 			errExpected := testutils.TestErrorAgainstCase(t, err, tc.returnErr)
 			if errExpected {
+				require.Empty(t, *events)
+				require.Empty(t, recorder.Changes())
+				return
+			}
+			require.Equal(t, []event.Event{{Type: event.TypePageRemoved, PageID: tc.params.Page.GUID, UserID: tc.params.UserID}}, *events)
+			require.Equal(t, []auditlog.Change{{TargetID: "PG_1", PageID: "PG_1", Before: page.ReducedPage{GUID: "PG_1", Title: "Old Title"}}}, recorder.Changes())
+		})
+	}
+}
+
+type unitOfWorkDoCall struct {
+	returnErr error
+}
+
+func TestBatchPages(t *testing.T) {
+	cases := []struct {
+		name              string
+		params            BatchPagesParams
+		unitOfWorkDoCalls []unitOfWorkDoCall
+		canEditPageCalls  []canEditPageCall
+		updatePageCalls   []updatePageCall
+		removePageCalls   []removePageCall
+		returnResults     []BatchOperationResult
+		returnEvents      []event.Event
+		returnAudited     []string
+		returnErr         error
+	}{
+		{
+			name: "test happy path, not atomic with a failed operation",
+			params: BatchPagesParams{
+				Operations: []BatchOperation{
+					{Type: BatchOperationRemove, Page: page.Page{GUID: "PG_1"}},
+					{Type: BatchOperationRemove, Page: page.Page{GUID: "PG_2"}},
+				},
+				UserID: "UR_1",
+			},
+			unitOfWorkDoCalls: []unitOfWorkDoCall{{}, {}},
+			canEditPageCalls: []canEditPageCall{
+				{
+					paramPageGUID:   "PG_1",
+					paramPageUserID: "UR_1",
+				},
+				{
+					paramPageGUID:   "PG_2",
+					paramPageUserID: "UR_1",
+					returnErr:       getStoreUnauthorizedErr("UR_1", "PG_2", nil),
+				},
+			},
+			removePageCalls: []removePageCall{{paramPageGUID: "PG_1"}},
+			returnResults: []BatchOperationResult{
+				{Type: BatchOperationRemove, Page: page.Page{GUID: "PG_1"}},
+				{Type: BatchOperationRemove, Page: page.Page{GUID: "PG_2"}, Err: getStoreUnauthorizedErr("UR_1", "PG_2", nil)},
+			},
+			returnEvents: []event.Event{
+				{Type: event.TypePageRemoved, PageID: "PG_1", UserID: "UR_1"},
+			},
+			returnAudited: []string{"PG_1"},
+		},
+		{
+			name: "test happy path, atomic",
+			params: BatchPagesParams{
+				Operations: []BatchOperation{
+					{Type: BatchOperationPermission, Page: page.Page{GUID: "PG_1", Title: "Ignored Title", PermissionType: permission.TypePublic}},
+					{Type: BatchOperationRemove, Page: page.Page{GUID: "PG_2"}},
+				},
+				Atomic: true,
+				UserID: "UR_1",
+			},
+			unitOfWorkDoCalls: []unitOfWorkDoCall{{}},
+			canEditPageCalls: []canEditPageCall{
+				{
+					paramPageGUID:   "PG_1",
+					paramPageUserID: "UR_1",
+				},
+				{
+					paramPageGUID:   "PG_2",
+					paramPageUserID: "UR_1",
+				},
+			},
+			updatePageCalls: []updatePageCall{{paramPage: page.Page{
+				GUID:           "PG_1",
+				PermissionType: permission.TypePublic,
+			}}},
+			removePageCalls: []removePageCall{{paramPageGUID: "PG_2"}},
+			returnResults: []BatchOperationResult{
+				{Type: BatchOperationPermission, Page: page.Page{GUID: "PG_1", Title: "Ignored Title", PermissionType: permission.TypePublic}},
+				{Type: BatchOperationRemove, Page: page.Page{GUID: "PG_2"}},
+			},
+			returnEvents: []event.Event{
+				{Type: event.TypePageUpdated, PageID: "PG_1", UserID: "UR_1"},
+				{Type: event.TypePageRemoved, PageID: "PG_2", UserID: "UR_1"},
+			},
+			returnAudited: []string{"PG_1", "PG_2"},
+		},
+		{
+			name: "test atomic with a failed operation",
+			params: BatchPagesParams{
+				Operations: []BatchOperation{
+					{Type: BatchOperationRemove, Page: page.Page{GUID: "PG_1"}},
+					{Type: BatchOperationRemove, Page: page.Page{GUID: "PG_2"}},
+					{Type: BatchOperationRemove, Page: page.Page{GUID: "PG_3"}},
+				},
+				Atomic: true,
+				UserID: "UR_1",
+			},
+			unitOfWorkDoCalls: []unitOfWorkDoCall{{}},
+			canEditPageCalls: []canEditPageCall{
+				{
+					paramPageGUID:   "PG_1",
+					paramPageUserID: "UR_1",
+				},
+				{
+					paramPageGUID:   "PG_2",
+					paramPageUserID: "UR_1",
+					returnErr:       getStoreUnauthorizedErr("UR_1", "PG_2", nil),
+				},
+			},
+			removePageCalls: []removePageCall{{paramPageGUID: "PG_1"}},
+			returnResults: []BatchOperationResult{
+				{Type: BatchOperationRemove, Page: page.Page{GUID: "PG_1"}, Err: ErrBatchRolledBack},
+				{Type: BatchOperationRemove, Page: page.Page{GUID: "PG_2"}, Err: getStoreUnauthorizedErr("UR_1", "PG_2", nil)},
+				{Type: BatchOperationRemove, Page: page.Page{GUID: "PG_3"}, Err: ErrBatchNotAttempted},
+			},
+		},
+		{
+			name: "test atomic with a failed commit",
+			params: BatchPagesParams{
+				Operations: []BatchOperation{
+					{Type: BatchOperationRemove, Page: page.Page{GUID: "PG_1"}},
+				},
+				Atomic: true,
+				UserID: "UR_1",
+			},
+			unitOfWorkDoCalls: []unitOfWorkDoCall{{returnErr: errors.New("unable to commit transaction")}},
+			canEditPageCalls: []canEditPageCall{
+				{
+					paramPageGUID:   "PG_1",
+					paramPageUserID: "UR_1",
+				},
+			},
+			removePageCalls: []removePageCall{{paramPageGUID: "PG_1"}},
+			returnErr:       errors.New("failed to run batch: {Operations:[{Type:remove Page:{ID:0 Version:{ID:0 GUID: Name: ParentGUID:} PageTemplate:{ID:0 Name: GUID:} GUID:PG_1 Title: Summary: PermissionType: PageProperties:[] PageDetails:[] CreatedAt:<nil> UpdatedAt:<nil> DeletedAt:<nil>}}] Atomic:true UserID:UR_1}: unable to commit transaction"),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			pageStore := new(mocks.PageStore)
+			pageTemplateStore := new(mocks.PageTemplateStore)
+			versionStore := new(mocks.VersionStore)
+			pageStore.On("GetPage", mock.Anything, mock.Anything).Return(func(ctx context.Context, guid string) page.Page {
+				return page.Page{GUID: guid}
+			}, nil)
+			unitOfWork, events := mockUnitOfWork(store.Stores{
+				PageStore:         pageStore,
+				PageTemplateStore: pageTemplateStore,
+				VersionStore:      versionStore,
+			}, tc.unitOfWorkDoCalls)
+			for index := range tc.canEditPageCalls {
+				pageStore.On("CanEditPage", mock.Anything, tc.canEditPageCalls[index].paramPageGUID, tc.canEditPageCalls[index].paramPageUserID).Return(tc.canEditPageCalls[index].returnIsOwner, tc.canEditPageCalls[index].returnErr)
+			}
+			for index := range tc.updatePageCalls {
+				pageStore.On("UpdatePage", mock.Anything, tc.updatePageCalls[index].paramPage).Return(tc.updatePageCalls[index].returnErr)
+			}
+			for index := range tc.removePageCalls {
+				pageStore.On("RemovePage", mock.Anything, tc.removePageCalls[index].paramPageGUID).Return(tc.removePageCalls[index].returnErr)
+			}
+			pageService = PageService{
+				PageStore:         pageStore,
+				PageTemplateStore: pageTemplateStore,
+				VersionStore:      versionStore,
+				UnitOfWork:        unitOfWork,
+			}
+			auditCtx, recorder := auditlog.WithRecorder(ctx)
+			results, err := pageService.BatchPages(auditCtx, tc.params)
+			require.Equal(t, tc.returnEvents, *events)
+			audited := []string{}
+			for _, change := range recorder.Changes() {
+				audited = append(audited, change.TargetID)
+			}
+			if tc.returnAudited == nil {
+				tc.returnAudited = []string{}
+			}
+			require.Equal(t, tc.returnAudited, audited, "only committed changes are audited")
+			unitOfWork.AssertNumberOfCalls(t, "Do", len(tc.unitOfWorkDoCalls))
+			pageStore.AssertNumberOfCalls(t, "CanEditPage", len(tc.canEditPageCalls))
+			pageStore.AssertNumberOfCalls(t, "UpdatePage", len(tc.updatePageCalls))
+			pageStore.AssertNumberOfCalls(t, "RemovePage", len(tc.removePageCalls))
+			errExpected := testutils.TestErrorAgainstCase(t, err, tc.returnErr)
+			if errExpected {
+				return
+			}
+			require.Equal(t, tc.returnResults, results)
+		})
+	}
+}
+
+type restorePageCall struct {
+	paramPageGUID string
+	returnErr     error
+}
+
+func TestRestorePage(t *testing.T) {
+	cases := []struct {
+		name             string
+		params           RestorePageParams
+		canEditPageCalls []canEditPageCall
+		restorePageCalls []restorePageCall
+		returnErr        error
+	}{
+		{
+			name: "test happy path",
+			params: RestorePageParams{
+				Page:   page.Page{GUID: "PG_1"},
+				UserID: "UR_1",
+			},
+			canEditPageCalls: []canEditPageCall{
+				{
+					paramPageGUID:   "PG_1",
+					paramPageUserID: "UR_1",
+				},
+			},
+			restorePageCalls: []restorePageCall{{paramPageGUID: "PG_1"}},
+		},
+		{
+			name: "test unauthorized call",
+			params: RestorePageParams{
+				Page:   page.Page{GUID: "PG_1"},
+				UserID: "UR_1",
+			},
+			canEditPageCalls: []canEditPageCall{
+				{
+					paramPageGUID:   "PG_1",
+					paramPageUserID: "UR_1",
+					returnErr:       getStoreUnauthorizedErr("UR_1", "PG_1", nil),
+				},
+			},
+			returnErr: errors.New("User UR_1 is not authorized to perform the action on the ID PG_1"),
+		},
+		{
+			name: "test page not removed",
+			params: RestorePageParams{
+				Page:   page.Page{GUID: "PG_1"},
+				UserID: "UR_1",
+			},
+			canEditPageCalls: []canEditPageCall{
+				{
+					paramPageGUID:   "PG_1",
+					paramPageUserID: "UR_1",
+				},
+			},
+			restorePageCalls: []restorePageCall{{paramPageGUID: "PG_1", returnErr: &storeerror.NotFound{ID: "PG_1"}}},
+			returnErr:        errors.New("failed to restore page: {Page:{ID:0 Version:{ID:0 GUID: Name: ParentGUID:} PageTemplate:{ID:0 Name: GUID:} GUID:PG_1 Title: Summary: PermissionType: PageProperties:[] PageDetails:[] CreatedAt:<nil> UpdatedAt:<nil> DeletedAt:<nil>} UserID:UR_1}: Could not find: PG_1"),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			pageStore := new(mocks.PageStore)
+			for index := range tc.canEditPageCalls {
+				pageStore.On("CanEditPage", mock.Anything, tc.canEditPageCalls[index].paramPageGUID, tc.canEditPageCalls[index].paramPageUserID).Return(tc.canEditPageCalls[index].returnIsOwner, tc.canEditPageCalls[index].returnErr)
+			}
+			for index := range tc.restorePageCalls {
+				pageStore.On("RestorePage", mock.Anything, tc.restorePageCalls[index].paramPageGUID).Return(tc.restorePageCalls[index].returnErr)
+			}
+			pageStore.On("GetPage", mock.Anything, mock.Anything).Return(func(ctx context.Context, guid string) page.Page {
+				return page.Page{GUID: guid, Title: "Restored Title"}
+			}, nil)
+			unitOfWork, events := mockUnitOfWork(store.Stores{PageStore: pageStore}, []unitOfWorkDoCall{{}})
+			pageService = PageService{
+				PageStore:  pageStore,
+				UnitOfWork: unitOfWork,
+			}
+			ctx, recorder := auditlog.WithRecorder(ctx)
+			err := pageService.RestorePage(ctx, tc.params)
+			pageStore.AssertNumberOfCalls(t, "CanEditPage", len(tc.canEditPageCalls))
+			pageStore.AssertNumberOfCalls(t, "RestorePage", len(tc.restorePageCalls))
+			errExpected := testutils.TestErrorAgainstCase(t, err, tc.returnErr)
+			if errExpected {
+				require.Empty(t, *events)
+				require.Empty(t, recorder.Changes())
+				return
+			}
+			require.Equal(t, []event.Event{{Type: event.TypePageRestored, PageID: "PG_1", UserID: "UR_1"}}, *events)
+			require.Equal(t, []auditlog.Change{{TargetID: "PG_1", PageID: "PG_1", After: page.ReducedPage{GUID: "PG_1", Title: "Restored Title"}}}, recorder.Changes())
+		})
+	}
+}
+
+type purgePageCall struct {
+	paramPageGUID string
+	returnErr     error
+}
+
+func TestPurgePage(t *testing.T) {
+	cases := []struct {
+		name             string
+		params           PurgePageParams
+		canEditPageCalls []canEditPageCall
+		purgePageCalls   []purgePageCall
+		returnErr        error
+	}{
+		{
+			name: "test happy path",
+			params: PurgePageParams{
+				Page:   page.Page{GUID: "PG_1"},
+				UserID: "UR_1",
+			},
+			canEditPageCalls: []canEditPageCall{
+				{
+					paramPageGUID:   "PG_1",
+					paramPageUserID: "UR_1",
+				},
+			},
+			purgePageCalls: []purgePageCall{{paramPageGUID: "PG_1"}},
+		},
+		{
+			name: "test unauthorized call",
+			params: PurgePageParams{
+				Page:   page.Page{GUID: "PG_1"},
+				UserID: "UR_1",
+			},
+			canEditPageCalls: []canEditPageCall{
+				{
+					paramPageGUID:   "PG_1",
+					paramPageUserID: "UR_1",
+					returnErr:       getStoreUnauthorizedErr("UR_1", "PG_1", nil),
+				},
+			},
+			returnErr: errors.New("User UR_1 is not authorized to perform the action on the ID PG_1"),
+		},
+		{
+			name: "test page not removed",
+			params: PurgePageParams{
+				Page:   page.Page{GUID: "PG_1"},
+				UserID: "UR_1",
+			},
+			canEditPageCalls: []canEditPageCall{
+				{
+					paramPageGUID:   "PG_1",
+					paramPageUserID: "UR_1",
+				},
+			},
+			purgePageCalls: []purgePageCall{{paramPageGUID: "PG_1", returnErr: &storeerror.NotFound{ID: "PG_1"}}},
+			returnErr:      errors.New("failed to purge page: {Page:{ID:0 Version:{ID:0 GUID: Name: ParentGUID:} PageTemplate:{ID:0 Name: GUID:} GUID:PG_1 Title: Summary: PermissionType: PageProperties:[] PageDetails:[] CreatedAt:<nil> UpdatedAt:<nil> DeletedAt:<nil>} UserID:UR_1}: Could not find: PG_1"),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			pageStore := new(mocks.PageStore)
+			for index := range tc.canEditPageCalls {
+				pageStore.On("CanEditPage", mock.Anything, tc.canEditPageCalls[index].paramPageGUID, tc.canEditPageCalls[index].paramPageUserID).Return(tc.canEditPageCalls[index].returnIsOwner, tc.canEditPageCalls[index].returnErr)
+			}
+			for index := range tc.purgePageCalls {
+				pageStore.On("PurgePage", mock.Anything, tc.purgePageCalls[index].paramPageGUID).Return(tc.purgePageCalls[index].returnErr)
+			}
+			unitOfWork, events := mockUnitOfWork(store.Stores{PageStore: pageStore}, []unitOfWorkDoCall{{}})
+			pageService = PageService{
+				PageStore:  pageStore,
+				UnitOfWork: unitOfWork,
+			}
+			ctx, recorder := auditlog.WithRecorder(ctx)
+			err := pageService.PurgePage(ctx, tc.params)
+			pageStore.AssertNumberOfCalls(t, "CanEditPage", len(tc.canEditPageCalls))
+			pageStore.AssertNumberOfCalls(t, "PurgePage", len(tc.purgePageCalls))
+			errExpected := testutils.TestErrorAgainstCase(t, err, tc.returnErr)
+			if errExpected {
+				require.Empty(t, *events)
+				require.Empty(t, recorder.Changes())
 				return
 			}
+			require.Equal(t, []event.Event{{Type: event.TypePagePurged, PageID: "PG_1", UserID: "UR_1"}}, *events)
+			require.Equal(t, []auditlog.Change{{TargetID: "PG_1", PageID: "PG_1"}}, recorder.Changes())
 		})
 	}
 }
+
+// mockUnitOfWork returns a UnitOfWork that runs each call with the given stores and an outbox, along with the events
+// added to the outbox by the calls that were committed. Only their Type, PageID and UserID are kept.
+func mockUnitOfWork(stores store.Stores, calls []unitOfWorkDoCall) (*mocks.UnitOfWork, *[]event.Event) {
+	unitOfWork := new(mocks.UnitOfWork)
+	committed := new([]event.Event)
+	for index := range calls {
+		returnErr := calls[index].returnErr
+		unitOfWork.On("Do", mock.Anything, mock.Anything).Return(func(ctx context.Context, fn func(store.Stores) error) error {
+			var added []event.Event
+			eventStore := new(mocks.EventStore)
+			eventStore.On("CreateEvent", mock.Anything, mock.Anything).Return(func(ctx context.Context, record event.Event) event.Event {
+				added = append(added, event.Event{Type: record.Type, PageID: record.PageID, UserID: record.UserID})
+				return record
+			}, nil)
+			stores.EventStore = eventStore
+			err := fn(stores)
+			if err == nil {
+				err = returnErr
+			}
+			if err == nil {
+				*committed = append(*committed, added...)
+			}
+			return err
+		}).Once()
+	}
+	return unitOfWork, committed
+}
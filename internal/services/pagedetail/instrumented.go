@@ -0,0 +1,20 @@
+package pagedetailservice
+
+import (
+	"context"
+
+	"github.com/worlve/sp-service/internal/services/instrumentation"
+)
+
+const serviceName = "pageDetail"
+
+// InstrumentedPageDetailService is a PageDetailService that records a span and counts the errors for each of its methods.
+type InstrumentedPageDetailService struct {
+	PageDetailService
+}
+
+// UpdatePageDetail see PageDetailService.UpdatePageDetail
+func (s InstrumentedPageDetailService) UpdatePageDetail(ctx context.Context, params UpdatePageDetailParams) error {
+	ctx, end := instrumentation.Start(ctx, serviceName, "UpdatePageDetail")
+	return end(s.PageDetailService.UpdatePageDetail(ctx, params))
+}
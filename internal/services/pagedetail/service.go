@@ -3,14 +3,18 @@ package pagedetailservice
 import (
 	"context"
 
+	"github.com/worlve/sp-service/internal/models/event"
 	"github.com/worlve/sp-service/internal/models/pagedetail"
+	eventservice "github.com/worlve/sp-service/internal/services/event"
 	"github.com/worlve/sp-service/internal/stores/store"
+	"github.com/worlve/sp-service/internal/util/auditlog"
 	"github.com/pkg/errors"
 )
 
 // PageDetailService is the service for handling page detail-related APIs
 type PageDetailService struct {
-	PageDetailStore store.PageDetailStore
+	// UnitOfWork runs each change along with adding its event to the outbox.
+	UnitOfWork store.UnitOfWork
 }
 
 // UpdatePageDetailParams params for UpdatePageDetail
@@ -20,11 +24,24 @@ type UpdatePageDetailParams struct {
 	UserID string
 }
 
-// UpdatePageDetail Updates a page detail.
+// UpdatePageDetail Updates a detail of the page, if the user can edit the page.
+// The event and audit entry are of the detail as it's stored, which the store only finds under its own page.
 func (s PageDetailService) UpdatePageDetail(ctx context.Context, params UpdatePageDetailParams) error {
-	err := s.PageDetailStore.UpdatePageDetail(params.Detail)
+	var updated pagedetail.PageDetail
+	err := s.UnitOfWork.Do(ctx, func(stores store.Stores) error {
+		_, err := stores.PageStore.CanEditPage(ctx, params.PageID, params.UserID)
+		if err != nil {
+			return err
+		}
+		updated, err = stores.PageDetailStore.UpdatePageDetail(ctx, params.PageID, params.Detail)
+		if err != nil {
+			return errors.Wrapf(err, "failed to update detail: %v", params)
+		}
+		return eventservice.Emit(ctx, stores.EventStore, event.PageDetailUpdated{PageID: params.PageID, Detail: updated}, params.UserID)
+	})
 	if err != nil {
-		return errors.Wrapf(err, "failed to update detail: %v", params)
+		return err
 	}
+	auditlog.Record(ctx, auditlog.Change{TargetID: updated.GUID, PageID: params.PageID, After: updated})
 	return nil
 }
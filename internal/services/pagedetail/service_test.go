@@ -1 +1,98 @@
 package pagedetailservice
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/worlve/sp-service/internal/models/event"
+	"github.com/worlve/sp-service/internal/models/pagedetail"
+	"github.com/worlve/sp-service/internal/stores/store"
+	"github.com/worlve/sp-service/internal/stores/storeerror"
+	"github.com/worlve/sp-service/internal/stores/store/mocks"
+	"github.com/worlve/sp-service/internal/util/testutils"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestUpdatePageDetail(t *testing.T) {
+	cases := []struct {
+		name          string
+		params        UpdatePageDetailParams
+		canEditErr    error
+		updateErr     error
+		createErr     error
+		returnCreated bool
+		returnErr     error
+	}{
+		{
+			name:          "test happy path",
+			params:        UpdatePageDetailParams{Detail: pagedetail.PageDetail{GUID: "PD_1"}, PageID: "PG_1", UserID: "UR_1"},
+			returnCreated: true,
+		},
+		{
+			name:          "test failed event fails the update",
+			params:        UpdatePageDetailParams{Detail: pagedetail.PageDetail{GUID: "PD_1"}, PageID: "PG_1", UserID: "UR_1"},
+			createErr:     errors.New("failed"),
+			returnCreated: true,
+			returnErr:     errors.New("failed to add detail.updated event for page PG_1 to the outbox: failed"),
+		},
+		{
+			name:       "test user who can't edit the page",
+			params:     UpdatePageDetailParams{Detail: pagedetail.PageDetail{GUID: "PD_1"}, PageID: "PG_1", UserID: "UR_2"},
+			canEditErr: &storeerror.NotAuthorized{TableID: "PG_1", UserID: "UR_2"},
+			returnErr:  &storeerror.NotAuthorized{TableID: "PG_1", UserID: "UR_2"},
+		},
+		{
+			name:      "test detail of another page",
+			params:    UpdatePageDetailParams{Detail: pagedetail.PageDetail{GUID: "PD_2"}, PageID: "PG_1", UserID: "UR_1"},
+			updateErr: &storeerror.NotFound{ID: "PD_2"},
+			returnErr: errors.New("failed to update detail: {{0 PD_2   []} PG_1 UR_1}: Could not find: PD_2"),
+		},
+		{
+			name:      "test failed update has no event",
+			params:    UpdatePageDetailParams{Detail: pagedetail.PageDetail{GUID: "PD_1"}, PageID: "PG_1", UserID: "UR_1"},
+			updateErr: errors.New("failed"),
+			returnErr: errors.New("failed to update detail: {{0 PD_1   []} PG_1 UR_1}: failed"),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			stored := tc.params.Detail
+			stored.ID = 7
+			stored.Partitions = []pagedetail.Partition{{GUID: "P_1", Type: pagedetail.PartitionTypeText, Value: "kept"}}
+			pageStore := new(mocks.PageStore)
+			pageStore.On("CanEditPage", mock.Anything, tc.params.PageID, tc.params.UserID).Return(true, tc.canEditErr)
+			pageDetailStore := new(mocks.PageDetailStore)
+			pageDetailStore.On("UpdatePageDetail", mock.Anything, "PG_1", tc.params.Detail).Return(stored, tc.updateErr)
+			eventStore := new(mocks.EventStore)
+			eventStore.On("CreateEvent", mock.Anything, mock.MatchedBy(func(e event.Event) bool {
+				var data event.PageDetailUpdated
+				err := json.Unmarshal(e.Payload, &data)
+				return e.Type == event.TypePageDetailUpdated && e.PageID == "PG_1" && e.UserID == "UR_1" &&
+					err == nil && len(data.Detail.Partitions) == 1 && data.Detail.Partitions[0].Value == "kept"
+			})).Return(event.Event{}, tc.createErr)
+			unitOfWork := new(mocks.UnitOfWork)
+			unitOfWork.On("Do", mock.Anything, mock.Anything).Return(func(ctx context.Context, fn func(store.Stores) error) error {
+				return fn(store.Stores{
+					PageStore:       pageStore,
+					PageDetailStore: pageDetailStore,
+					EventStore:      eventStore,
+				})
+			})
+			pageDetailService := PageDetailService{
+				UnitOfWork: unitOfWork,
+			}
+			err := pageDetailService.UpdatePageDetail(context.Background(), tc.params)
+			testutils.TestErrorAgainstCase(t, err, tc.returnErr)
+			calls := 0
+			if tc.returnCreated {
+				calls = 1
+			}
+			eventStore.AssertNumberOfCalls(t, "CreateEvent", calls)
+			if tc.canEditErr != nil {
+				pageDetailStore.AssertNotCalled(t, "UpdatePageDetail", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package streamservice
+
+import (
+	"context"
+
+	"github.com/worlve/sp-service/internal/services/instrumentation"
+)
+
+const serviceName = "stream"
+
+// InstrumentedStreamService is a StreamService that records a span and counts the errors for each of its methods.
+// Publish only adds to the in-memory log, so it isn't instrumented.
+type InstrumentedStreamService struct {
+	StreamService
+}
+
+// CheckPageAccess see StreamService.CheckPageAccess
+func (s InstrumentedStreamService) CheckPageAccess(ctx context.Context, params CheckPageAccessParams) error {
+	ctx, end := instrumentation.Start(ctx, serviceName, "CheckPageAccess")
+	return end(s.StreamService.CheckPageAccess(ctx, params))
+}
+
+// SubscribePage see StreamService.SubscribePage
+func (s InstrumentedStreamService) SubscribePage(ctx context.Context, params SubscribePageParams) (*Subscription, error) {
+	ctx, end := instrumentation.Start(ctx, serviceName, "SubscribePage")
+	result, err := s.StreamService.SubscribePage(ctx, params)
+	return result, end(err)
+}
+
+// SubscribeUser see StreamService.SubscribeUser
+func (s InstrumentedStreamService) SubscribeUser(ctx context.Context, params SubscribeUserParams) (*Subscription, error) {
+	ctx, end := instrumentation.Start(ctx, serviceName, "SubscribeUser")
+	result, err := s.StreamService.SubscribeUser(ctx, params)
+	return result, end(err)
+}
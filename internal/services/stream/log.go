@@ -0,0 +1,128 @@
+package streamservice
+
+import (
+	"sync"
+
+	"github.com/worlve/sp-service/internal/models/stream"
+)
+
+// subscriberBuffer is how many events a subscriber can fall behind before it's dropped.
+const subscriberBuffer = 64
+
+// Log keeps the most recent events in memory, so clients can resume their stream after reconnecting,
+// and passes each new event on to the subscribers it's for.
+type Log struct {
+	mu     sync.Mutex
+	size   int
+	events []stream.Event
+	lastID int64
+	// droppedID is the ID of the newest event that's dropped out of the log, or the start when none have.
+	droppedID   int64
+	subscribers map[*Subscription]struct{}
+}
+
+// NewLog returns a log that keeps the last size events. Event IDs are outbox offsets, and start is the last one
+// from before the log was made; resuming from an ID before it is reported as missed.
+func NewLog(size int, start int64) *Log {
+	return &Log{
+		size:        size,
+		lastID:      start,
+		droppedID:   start,
+		subscribers: make(map[*Subscription]struct{}),
+	}
+}
+
+// Subscription is a subscriber's place in the log.
+type Subscription struct {
+	// Backlog is the events after the last event ID the subscriber gave.
+	Backlog []stream.Event
+	// Missed is whether events after the last event ID have already dropped out of the log, in which case the
+	// Backlog is empty and the subscriber has to reload whatever it's showing.
+	Missed bool
+	// LastID is the ID of the newest event in the log when the subscription started.
+	LastID int64
+	// Events receives each new event for the subscriber. It's closed when the subscriber falls too far behind.
+	Events <-chan stream.Event
+	events chan stream.Event
+	filter func(stream.Event) bool
+	log    *Log
+}
+
+// Close stops the subscription.
+func (sub *Subscription) Close() {
+	sub.log.mu.Lock()
+	defer sub.log.mu.Unlock()
+	sub.log.remove(sub)
+}
+
+// Append adds the event to the log and sends it to its subscribers. Returns false without adding it if its ID isn't
+// newer than the last event's, as events can be handed to the log more than once.
+func (l *Log) Append(e stream.Event) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if e.ID <= l.lastID {
+		return false
+	}
+	l.lastID = e.ID
+	l.events = append(l.events, e)
+	if len(l.events) > l.size {
+		l.droppedID = l.events[len(l.events)-l.size-1].ID
+		l.events = l.events[len(l.events)-l.size:]
+	}
+	for sub := range l.subscribers {
+		if !sub.filter(e) {
+			continue
+		}
+		select {
+		case sub.events <- e:
+		default:
+			// it can resume from the log once it reconnects
+			l.remove(sub)
+		}
+	}
+	return true
+}
+
+// Subscribe returns a subscription to the events that match the filter, starting after lastEventID.
+// There's no backlog when lastEventID is 0.
+func (l *Log) Subscribe(lastEventID int64, filter func(stream.Event) bool) *Subscription {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	events := make(chan stream.Event, subscriberBuffer)
+	sub := &Subscription{
+		LastID: l.lastID,
+		Events: events,
+		events: events,
+		filter: filter,
+		log:    l,
+	}
+	if lastEventID > 0 {
+		sub.Missed = lastEventID < l.droppedID || lastEventID > l.lastID
+	}
+	if lastEventID > 0 && !sub.Missed {
+		for _, e := range l.events {
+			if e.ID > lastEventID && filter(e) {
+				sub.Backlog = append(sub.Backlog, e)
+			}
+		}
+	}
+	l.subscribers[sub] = struct{}{}
+	return sub
+}
+
+// Close ends every subscription, such as when the server is shutting down.
+func (l *Log) Close() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for sub := range l.subscribers {
+		l.remove(sub)
+	}
+}
+
+func (l *Log) remove(sub *Subscription) {
+	if _, ok := l.subscribers[sub]; !ok {
+		return
+	}
+	delete(l.subscribers, sub)
+	close(sub.events)
+}
@@ -0,0 +1,126 @@
+package streamservice
+
+import (
+	"testing"
+
+	"github.com/worlve/sp-service/internal/models/stream"
+	"github.com/stretchr/testify/require"
+)
+
+// start is the last offset from before the logs in the tests were made.
+const start = int64(100)
+
+// firstID is the ID of the first event appended to a log started at start.
+const firstID = start + 1
+
+func TestSubscribe(t *testing.T) {
+	cases := []struct {
+		name             string
+		paramLastEventID int64
+		appended         []string
+		returnBacklog    []int64
+		returnMissed     bool
+	}{
+		{
+			name:     "test no last event ID has no backlog",
+			appended: []string{"PG_1", "PG_1"},
+		},
+		{
+			name:             "test resumes after the last event ID",
+			paramLastEventID: firstID,
+			appended:         []string{"PG_1", "PG_2", "PG_1", "PG_1"},
+			returnBacklog:    []int64{firstID + 2, firstID + 3},
+		},
+		{
+			name:             "test up to date",
+			paramLastEventID: firstID + 1,
+			appended:         []string{"PG_1", "PG_1"},
+		},
+		{
+			name:             "test events dropped out of the log",
+			paramLastEventID: firstID,
+			appended:         []string{"PG_1", "PG_1", "PG_1", "PG_1", "PG_1"},
+			returnMissed:     true,
+		},
+		{
+			name:             "test last event ID from before a restart",
+			paramLastEventID: 5,
+			returnMissed:     true,
+		},
+		{
+			name:             "test resumes across a missing offset",
+			paramLastEventID: firstID,
+			appended:         []string{"PG_1", "", "PG_1"},
+			returnBacklog:    []int64{firstID + 2},
+		},
+		{
+			name:             "test last event ID from the future",
+			paramLastEventID: firstID + 10,
+			appended:         []string{"PG_1"},
+			returnMissed:     true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			log := NewLog(3, start)
+			for i, pageID := range tc.appended {
+				// an empty page ID stands for an offset that was rolled back, and so never appended
+				if pageID != "" {
+					log.Append(stream.Event{ID: firstID + int64(i), PageID: pageID})
+				}
+			}
+			sub := log.Subscribe(tc.paramLastEventID, func(e stream.Event) bool {
+				return e.PageID == "PG_1"
+			})
+			defer sub.Close()
+			var backlog []int64
+			for _, e := range sub.Backlog {
+				backlog = append(backlog, e.ID)
+			}
+			require.Equal(t, tc.returnBacklog, backlog)
+			require.Equal(t, tc.returnMissed, sub.Missed)
+			require.Equal(t, firstID-1+int64(len(tc.appended)), sub.LastID)
+		})
+	}
+}
+
+func TestAppend(t *testing.T) {
+	log := NewLog(10, start)
+	sub := log.Subscribe(0, func(e stream.Event) bool {
+		return e.PageID == "PG_1"
+	})
+	require.True(t, log.Append(stream.Event{ID: firstID, PageID: "PG_2"}))
+	e := stream.Event{ID: firstID + 1, PageID: "PG_1"}
+	require.True(t, log.Append(e))
+	require.Equal(t, e, <-sub.Events)
+
+	t.Run("test event that's already in the log is ignored", func(t *testing.T) {
+		require.False(t, log.Append(e))
+		require.False(t, log.Append(stream.Event{ID: start, PageID: "PG_1"}))
+		require.Len(t, sub.Events, 0)
+	})
+
+	t.Run("test slow subscriber is dropped", func(t *testing.T) {
+		for i := 0; i <= subscriberBuffer; i++ {
+			log.Append(stream.Event{ID: firstID + 2 + int64(i), PageID: "PG_1"})
+		}
+		received := 0
+		for range sub.Events {
+			received++
+		}
+		require.Equal(t, subscriberBuffer, received)
+		// closing after being dropped is a no-op
+		sub.Close()
+	})
+}
+
+func TestClose(t *testing.T) {
+	log := NewLog(10, start)
+	sub := log.Subscribe(0, func(e stream.Event) bool {
+		return true
+	})
+	log.Close()
+	_, ok := <-sub.Events
+	require.False(t, ok)
+	sub.Close()
+}
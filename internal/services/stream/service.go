@@ -0,0 +1,116 @@
+package streamservice
+
+import (
+	"context"
+
+	"github.com/worlve/sp-service/internal/models/event"
+	"github.com/worlve/sp-service/internal/models/page"
+	"github.com/worlve/sp-service/internal/models/stream"
+	"github.com/worlve/sp-service/internal/stores/store"
+	"github.com/worlve/sp-service/internal/stores/storeerror"
+	"github.com/worlve/sp-service/internal/util/clock"
+	"github.com/pkg/errors"
+)
+
+// StreamService is the service for streaming changes to pages as they're made
+type StreamService struct {
+	Log       *Log
+	PageStore store.PageStore
+	Clock     clock.Clock
+}
+
+// PublishParams params for Publish
+type PublishParams struct {
+	Event event.Event
+}
+
+// Publish adds a change to the log, which passes it on to the page's subscribers and to the subscriptions of the
+// users it's for; see SubscribeUser. An event that's already in the log is ignored.
+func (s StreamService) Publish(ctx context.Context, params PublishParams) {
+	createdAt := s.Clock.Now()
+	if params.Event.CreatedAt != nil {
+		createdAt = *params.Event.CreatedAt
+	}
+	s.Log.Append(stream.Event{
+		ID:        params.Event.Offset,
+		Type:      params.Event.Type,
+		PageID:    params.Event.PageID,
+		UserID:    params.Event.UserID,
+		CreatedAt: createdAt.UTC(),
+		Data:      params.Event.Payload,
+	})
+}
+
+// CheckPageAccessParams params for CheckPageAccess
+type CheckPageAccessParams struct {
+	Page   page.Page
+	UserID string
+}
+
+// CheckPageAccess returns a storeerror.NotAuthorized if the user can't read the page's events.
+func (s StreamService) CheckPageAccess(ctx context.Context, params CheckPageAccessParams) error {
+	canRead, err := s.PageStore.CanReadPage(ctx, params.Page.GUID, params.UserID)
+	if err != nil {
+		return err
+	}
+	if !canRead {
+		return &storeerror.NotAuthorized{
+			UserID:  params.UserID,
+			TableID: params.Page.GUID,
+		}
+	}
+	return nil
+}
+
+// SubscribePageParams params for SubscribePage
+type SubscribePageParams struct {
+	Page        page.Page
+	LastEventID int64
+	UserID      string
+}
+
+// SubscribePage subscribes to the changes made to a page the user can read.
+func (s StreamService) SubscribePage(ctx context.Context, params SubscribePageParams) (*Subscription, error) {
+	err := s.CheckPageAccess(ctx, CheckPageAccessParams{
+		Page:   params.Page,
+		UserID: params.UserID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Log.Subscribe(params.LastEventID, func(e stream.Event) bool {
+		return e.PageID == params.Page.GUID
+	}), nil
+}
+
+// SubscribeUserParams params for SubscribeUser
+type SubscribeUserParams struct {
+	LastEventID int64
+	UserID      string
+}
+
+// SubscribeUser subscribes to the changes made to the pages the user owns or that are shared with them, whoever makes
+// them, along with the changes the user makes to any page, such as the pages they create once it's started. Changes to
+// public pages the user isn't a member of aren't sent; they can follow those with SubscribePage. Pages shared with the
+// user after it's started are included once they reconnect. The handler still checks each event with CheckPageAccess,
+// since the page may have been made private or unshared since.
+func (s StreamService) SubscribeUser(ctx context.Context, params SubscribeUserParams) (*Subscription, error) {
+	guids, err := s.PageStore.GetUserPageGUIDs(ctx, params.UserID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get the user's pages: %+v", params)
+	}
+	pages := make(map[string]bool, len(guids))
+	for _, guid := range guids {
+		pages[guid] = true
+	}
+	// the log only calls the filter while it's locked, so it can add to pages without a lock of its own
+	return s.Log.Subscribe(params.LastEventID, func(e stream.Event) bool {
+		if e.PageID == "" {
+			return false
+		}
+		if e.UserID == params.UserID {
+			pages[e.PageID] = true
+		}
+		return pages[e.PageID]
+	}), nil
+}
@@ -0,0 +1,115 @@
+package streamservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/worlve/sp-service/internal/models/event"
+	"github.com/worlve/sp-service/internal/models/page"
+	"github.com/worlve/sp-service/internal/stores/store/mocks"
+	"github.com/worlve/sp-service/internal/stores/storeerror"
+	"github.com/worlve/sp-service/internal/util/clock"
+	"github.com/worlve/sp-service/internal/util/testutils"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubscribePage(t *testing.T) {
+	cases := []struct {
+		name          string
+		params        SubscribePageParams
+		canRead       bool
+		canReadErr    error
+		returnPageIDs []string
+		returnErr     error
+	}{
+		{
+			name:          "test happy path",
+			params:        SubscribePageParams{Page: page.Page{GUID: "PG_1"}, UserID: "UR_2"},
+			canRead:       true,
+			returnPageIDs: []string{"PG_1"},
+		},
+		{
+			name:      "test someone else's private page",
+			params:    SubscribePageParams{Page: page.Page{GUID: "PG_1"}, UserID: "UR_2"},
+			returnErr: errors.New("User UR_2 is not authorized to perform the action on the ID PG_1"),
+		},
+		{
+			name:       "test missing page",
+			params:     SubscribePageParams{Page: page.Page{GUID: "PG_1"}, UserID: "UR_2"},
+			canReadErr: &storeerror.NotAuthorized{UserID: "UR_2", TableID: "PG_1"},
+			returnErr:  errors.New("User UR_2 is not authorized to perform the action on the ID PG_1"),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			now := time.Now()
+			pageStore := new(mocks.PageStore)
+			pageStore.On("CanReadPage", mock.Anything, "PG_1", "UR_2").Return(tc.canRead, tc.canReadErr)
+			streamService := StreamService{
+				Log:       NewLog(10, 0),
+				PageStore: pageStore,
+				Clock:     clock.MockClock{MockedTime: &now},
+			}
+			sub, err := streamService.SubscribePage(context.Background(), tc.params)
+			errExpected := testutils.TestErrorAgainstCase(t, err, tc.returnErr)
+			if errExpected {
+				return
+			}
+			defer sub.Close()
+			streamService.Publish(context.Background(), PublishParams{Event: event.Event{Offset: 1, Type: event.TypePageUpdated, PageID: "PG_2", UserID: "UR_1", CreatedAt: &now}})
+			streamService.Publish(context.Background(), PublishParams{Event: event.Event{Offset: 2, Type: event.TypePageUpdated, PageID: "PG_1", UserID: "UR_1", CreatedAt: &now}})
+			e := <-sub.Events
+			require.Equal(t, int64(2), e.ID)
+			require.Equal(t, "PG_1", e.PageID)
+			require.Equal(t, event.TypePageUpdated, e.Type)
+			require.Equal(t, now.UTC(), e.CreatedAt)
+		})
+	}
+}
+
+func TestSubscribeUser(t *testing.T) {
+	now := time.Now()
+	pageStore := new(mocks.PageStore)
+	pageStore.On("GetUserPageGUIDs", mock.Anything, "UR_1").Return([]string{"PG_1", "PG_SHARED"}, nil)
+	streamService := StreamService{
+		Log:       NewLog(10, 10),
+		PageStore: pageStore,
+		Clock:     clock.MockClock{MockedTime: &now},
+	}
+	streamService.Publish(context.Background(), PublishParams{Event: event.Event{Offset: 11, Type: event.TypePageCreated, PageID: "PG_1", UserID: "UR_1"}})
+	sub, err := streamService.SubscribeUser(context.Background(), SubscribeUserParams{LastEventID: 1, UserID: "UR_1"})
+	require.NoError(t, err)
+	defer sub.Close()
+	require.True(t, sub.Missed)
+	// UR_2 editing UR_1's page reaches UR_1, who the handler checks can read it before sending it
+	streamService.Publish(context.Background(), PublishParams{Event: event.Event{Offset: 12, Type: event.TypePageUpdated, PageID: "PG_1", UserID: "UR_2"}})
+	// changes to other users' pages that aren't shared with UR_1 don't reach UR_1, even when they're public
+	streamService.Publish(context.Background(), PublishParams{Event: event.Event{Offset: 13, Type: event.TypePageUpdated, PageID: "PG_PUBLIC", UserID: "UR_2"}})
+	streamService.Publish(context.Background(), PublishParams{Event: event.Event{Offset: 14, Type: event.TypePageUpdated, PageID: "PG_SHARED", UserID: "UR_2"}})
+	// pages UR_1 creates once it's started are followed from then on
+	streamService.Publish(context.Background(), PublishParams{Event: event.Event{Offset: 15, Type: event.TypePageCreated, PageID: "PG_NEW", UserID: "UR_1"}})
+	streamService.Publish(context.Background(), PublishParams{Event: event.Event{Offset: 16, Type: event.TypePageUpdated, PageID: "PG_NEW", UserID: "UR_2"}})
+	streamService.Publish(context.Background(), PublishParams{Event: event.Event{Offset: 17, Type: event.TypePageRemoved, PageID: "PG_1", UserID: "UR_1"}})
+	var received []string
+	for len(received) < 5 {
+		e := <-sub.Events
+		received = append(received, fmt.Sprintf("%v %v %v", e.ID, e.PageID, e.UserID))
+		require.Equal(t, now.UTC(), e.CreatedAt)
+	}
+	require.Equal(t, []string{"12 PG_1 UR_2", "14 PG_SHARED UR_2", "15 PG_NEW UR_1", "16 PG_NEW UR_2", "17 PG_1 UR_1"}, received)
+
+	resumed, err := streamService.SubscribeUser(context.Background(), SubscribeUserParams{LastEventID: 11, UserID: "UR_1"})
+	require.NoError(t, err)
+	defer resumed.Close()
+	require.Len(t, resumed.Backlog, 5)
+	require.Equal(t, "UR_2", resumed.Backlog[0].UserID)
+	require.Equal(t, "PG_SHARED", resumed.Backlog[1].PageID)
+
+	pageStore.On("GetUserPageGUIDs", mock.Anything, "UR_MISSING").Return(nil, errors.New("failed"))
+	_, err = streamService.SubscribeUser(context.Background(), SubscribeUserParams{UserID: "UR_MISSING"})
+	require.Error(t, err)
+}
@@ -0,0 +1,55 @@
+package webhookservice
+
+import (
+	"context"
+
+	"github.com/worlve/sp-service/internal/models/webhook"
+	"github.com/worlve/sp-service/internal/services/instrumentation"
+)
+
+const serviceName = "webhook"
+
+// InstrumentedWebhookService is a WebhookService that records a span and counts the errors for each of its methods.
+type InstrumentedWebhookService struct {
+	WebhookService
+}
+
+// CreateWebhook see WebhookService.CreateWebhook
+func (s InstrumentedWebhookService) CreateWebhook(ctx context.Context, params CreateWebhookParams) (webhook.Webhook, error) {
+	ctx, end := instrumentation.Start(ctx, serviceName, "CreateWebhook")
+	result, err := s.WebhookService.CreateWebhook(ctx, params)
+	return result, end(err)
+}
+
+// GetWebhooks see WebhookService.GetWebhooks
+func (s InstrumentedWebhookService) GetWebhooks(ctx context.Context, params GetWebhooksParams) ([]webhook.Webhook, error) {
+	ctx, end := instrumentation.Start(ctx, serviceName, "GetWebhooks")
+	results, err := s.WebhookService.GetWebhooks(ctx, params)
+	return results, end(err)
+}
+
+// RemoveWebhook see WebhookService.RemoveWebhook
+func (s InstrumentedWebhookService) RemoveWebhook(ctx context.Context, params RemoveWebhookParams) error {
+	ctx, end := instrumentation.Start(ctx, serviceName, "RemoveWebhook")
+	return end(s.WebhookService.RemoveWebhook(ctx, params))
+}
+
+// GetDeliveries see WebhookService.GetDeliveries
+func (s InstrumentedWebhookService) GetDeliveries(ctx context.Context, params GetDeliveriesParams) ([]webhook.Delivery, int, string, error) {
+	ctx, end := instrumentation.Start(ctx, serviceName, "GetDeliveries")
+	results, total, nextBatchID, err := s.WebhookService.GetDeliveries(ctx, params)
+	return results, total, nextBatchID, end(err)
+}
+
+// Publish see WebhookService.Publish
+func (s InstrumentedWebhookService) Publish(ctx context.Context, params PublishParams) error {
+	ctx, end := instrumentation.Start(ctx, serviceName, "Publish")
+	return end(s.WebhookService.Publish(ctx, params))
+}
+
+// DeliverDue see WebhookService.DeliverDue
+func (s InstrumentedWebhookService) DeliverDue(ctx context.Context, params DeliverDueParams) (int, error) {
+	ctx, end := instrumentation.Start(ctx, serviceName, "DeliverDue")
+	count, err := s.WebhookService.DeliverDue(ctx, params)
+	return count, end(err)
+}
@@ -0,0 +1,375 @@
+package webhookservice
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/worlve/sp-service/internal/models/event"
+	"github.com/worlve/sp-service/internal/models/webhook"
+	"github.com/worlve/sp-service/internal/stores/store"
+	"github.com/worlve/sp-service/internal/stores/storeerror"
+	"github.com/worlve/sp-service/internal/util/auditlog"
+	"github.com/worlve/sp-service/internal/util/clock"
+	"github.com/worlve/sp-service/internal/util/guidgen"
+	"github.com/worlve/sp-service/internal/util/logger"
+	"github.com/worlve/sp-service/internal/util/publicnet"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// DefaultMaxAttempts is the number of times a delivery is attempted before it's marked as failed, when MaxAttempts isn't set.
+const DefaultMaxAttempts = 8
+
+const (
+	// retryBaseDelay is the wait after the first failed attempt; it doubles after each attempt after that.
+	retryBaseDelay = 30 * time.Second
+	retryMaxDelay  = 6 * time.Hour
+	// DeliveryTimeout bounds each attempt.
+	DeliveryTimeout = 10 * time.Second
+	// claimLease is how long a claimed delivery is hidden from other instances while it's being attempted.
+	claimLease  = 5 * time.Minute
+	secretBytes = 32
+	// maxDrainBody is how much of a response is read, and thrown away, so its connection can be reused.
+	maxDrainBody = 4096
+)
+
+// WebhookService is the service for handling webhook-related APIs and sending their deliveries
+type WebhookService struct {
+	WebhookStore store.WebhookStore
+	PageStore    store.PageStore
+	Clock        clock.Clock
+	// HTTPClient sends the deliveries and is shared by them, so their connections are reused; when it's nil, a
+	// package-wide client with the DeliveryTimeout that only connects to public addresses and doesn't follow redirects
+	// is used, see publicnet.NewClient.
+	HTTPClient  *http.Client
+	MaxAttempts int
+}
+
+// CreateWebhookParams params for CreateWebhook
+type CreateWebhookParams struct {
+	Webhook webhook.Webhook
+	UserID  string
+}
+
+// CreateWebhook subscribes the user's URL to the given events. The returned webhook includes the secret its
+// deliveries are signed with, which isn't shown again.
+func (s WebhookService) CreateWebhook(ctx context.Context, params CreateWebhookParams) (webhook.Webhook, error) {
+	secret, err := generateSecret()
+	if err != nil {
+		return webhook.Webhook{}, errors.Wrap(err, "failed to generate webhook secret")
+	}
+	record := webhook.Webhook{
+		GUID:   guidgen.GenerateGUID("WH", 24),
+		UserID: params.UserID,
+		URL:    params.Webhook.URL,
+		Events: params.Webhook.Events,
+		Secret: secret,
+	}
+	w, err := s.WebhookStore.CreateWebhook(ctx, record)
+	if err != nil {
+		return w, errors.Wrapf(err, "failed to create webhook: %+v", params)
+	}
+	auditlog.Record(ctx, auditlog.Change{TargetID: w.GUID, After: withoutSecret(w)})
+	return w, nil
+}
+
+// withoutSecret returns the webhook without its secret, such as for the audit log.
+func withoutSecret(w webhook.Webhook) webhook.Webhook {
+	w.Secret = ""
+	return w
+}
+
+// GetWebhooksParams params for GetWebhooks
+type GetWebhooksParams struct {
+	UserID string
+}
+
+// GetWebhooks returns the user's webhooks, without their secrets.
+func (s WebhookService) GetWebhooks(ctx context.Context, params GetWebhooksParams) ([]webhook.Webhook, error) {
+	webhooks, err := s.WebhookStore.GetWebhooks(ctx, params.UserID)
+	if err != nil {
+		return webhooks, errors.Wrapf(err, "failed to get webhooks: %+v", params)
+	}
+	for i := range webhooks {
+		webhooks[i].Secret = ""
+	}
+	return webhooks, nil
+}
+
+// RemoveWebhookParams params for RemoveWebhook
+type RemoveWebhookParams struct {
+	Webhook webhook.Webhook
+	UserID  string
+}
+
+// RemoveWebhook permanently deletes the webhook along with its delivery log.
+func (s WebhookService) RemoveWebhook(ctx context.Context, params RemoveWebhookParams) error {
+	w, err := s.canEditWebhook(ctx, params.Webhook.GUID, params.UserID)
+	if err != nil {
+		return err
+	}
+	err = s.WebhookStore.RemoveWebhook(ctx, params.Webhook.GUID)
+	if err != nil {
+		return errors.Wrapf(err, "failed to remove webhook: %+v", params)
+	}
+	auditlog.Record(ctx, auditlog.Change{TargetID: w.GUID, Before: withoutSecret(w)})
+	return nil
+}
+
+// GetDeliveriesParams params for GetDeliveries
+type GetDeliveriesParams struct {
+	Webhook     webhook.Webhook
+	NextBatchID string
+	UserID      string
+}
+
+// GetDeliveries returns the webhook's delivery log, newest first.
+func (s WebhookService) GetDeliveries(ctx context.Context, params GetDeliveriesParams) ([]webhook.Delivery, int, string, error) {
+	_, err := s.canEditWebhook(ctx, params.Webhook.GUID, params.UserID)
+	if err != nil {
+		return nil, 0, "", err
+	}
+	deliveries, total, nextBatchID, err := s.WebhookStore.GetDeliveries(ctx, params.Webhook.GUID, params.NextBatchID, 10)
+	if err != nil {
+		return deliveries, total, nextBatchID, errors.Wrapf(err, "failed to get deliveries: %+v", params)
+	}
+	return deliveries, total, nextBatchID, nil
+}
+
+// canEditWebhook returns the webhook, or a storeerror.NotAuthorized if it belongs to someone else.
+func (s WebhookService) canEditWebhook(ctx context.Context, webhookGUID, userID string) (webhook.Webhook, error) {
+	w, err := s.WebhookStore.GetWebhook(ctx, webhookGUID)
+	if err != nil {
+		return webhook.Webhook{}, err
+	}
+	if w.UserID != userID {
+		return webhook.Webhook{}, &storeerror.NotAuthorized{
+			UserID:  userID,
+			TableID: webhookGUID,
+		}
+	}
+	return w, nil
+}
+
+// PublishParams params for Publish
+type PublishParams struct {
+	Event event.Event
+}
+
+// Publish queues a delivery of the event for each webhook subscribed to it that belongs to the page's owner or to
+// the user who made the change, so owners hear about the changes others make to their pages.
+// The deliveries are sent by DeliverDue.
+func (s WebhookService) Publish(ctx context.Context, params PublishParams) error {
+	eventType := webhook.EventType(params.Event.Type)
+	userIDs, err := s.getRecipients(ctx, params.Event)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get the owner of the page to publish to: %+v", params)
+	}
+	var subscribed []webhook.Webhook
+	for _, userID := range userIDs {
+		webhooks, err := s.WebhookStore.GetWebhooks(ctx, userID)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get webhooks to publish to: %+v", params)
+		}
+		for _, w := range webhooks {
+			if w.IsSubscribed(eventType) {
+				subscribed = append(subscribed, w)
+			}
+		}
+	}
+	if len(subscribed) == 0 {
+		return nil
+	}
+	now := s.Clock.Now().UTC()
+	createdAt := now
+	if params.Event.CreatedAt != nil {
+		createdAt = params.Event.CreatedAt.UTC()
+	}
+	payload, err := json.Marshal(webhook.Event{
+		ID:        params.Event.GUID,
+		Type:      eventType,
+		CreatedAt: createdAt,
+		Data:      params.Event.Payload,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to encode event: %+v", params)
+	}
+	deliveries := make([]webhook.Delivery, 0, len(subscribed))
+	for _, w := range subscribed {
+		deliveries = append(deliveries, webhook.Delivery{
+			GUID:          guidgen.GenerateGUID("WD", 24),
+			WebhookGUID:   w.GUID,
+			Event:         eventType,
+			Payload:       payload,
+			Status:        webhook.DeliveryPending,
+			NextAttemptAt: &now,
+		})
+	}
+	err = s.WebhookStore.CreateDeliveries(ctx, deliveries)
+	if err != nil {
+		return errors.Wrapf(err, "failed to queue deliveries: %+v", params)
+	}
+	return nil
+}
+
+// getRecipients returns the users whose webhooks hear about the event: the user who made the change and, when it's
+// someone else, the page's owner. Pages that have since been purged only have the user who made the change.
+func (s WebhookService) getRecipients(ctx context.Context, e event.Event) ([]string, error) {
+	userIDs := []string{e.UserID}
+	if e.PageID == "" {
+		return userIDs, nil
+	}
+	ownerID, err := s.PageStore.GetPageOwner(ctx, e.PageID)
+	if _, ok := errors.Cause(err).(*storeerror.NotFound); ok {
+		return userIDs, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if ownerID != e.UserID {
+		userIDs = append(userIDs, ownerID)
+	}
+	return userIDs, nil
+}
+
+// DeliverDueParams params for DeliverDue
+type DeliverDueParams struct {
+	Limit int
+}
+
+// DeliverDue attempts up to Limit deliveries that are due, and returns the number that were delivered.
+// A delivery is delivered once its webhook responds with a 2xx status. Failed attempts are retried with
+// exponential backoff until MaxAttempts is reached.
+func (s WebhookService) DeliverDue(ctx context.Context, params DeliverDueParams) (int, error) {
+	now := s.Clock.Now()
+	deliveries, err := s.WebhookStore.GetDueDeliveries(ctx, now, params.Limit)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to get due deliveries: %+v", params)
+	}
+	delivered := 0
+	for _, d := range deliveries {
+		claimed, err := s.WebhookStore.ClaimDelivery(ctx, d, now.Add(claimLease))
+		if err != nil {
+			return delivered, errors.Wrapf(err, "failed to claim delivery: %v", d.GUID)
+		}
+		if !claimed {
+			continue
+		}
+		w, err := s.WebhookStore.GetWebhook(ctx, d.WebhookGUID)
+		if _, ok := errors.Cause(err).(*storeerror.NotFound); ok {
+			// removed since the delivery was read, along with the delivery itself
+			continue
+		}
+		if err != nil {
+			return delivered, errors.Wrapf(err, "failed to get webhook for delivery: %v", d.GUID)
+		}
+		d = s.attempt(ctx, w, d)
+		err = s.WebhookStore.UpdateDelivery(ctx, d)
+		if err != nil {
+			return delivered, errors.Wrapf(err, "failed to update delivery: %v", d.GUID)
+		}
+		if d.Status == webhook.DeliveryDelivered {
+			delivered++
+		}
+	}
+	return delivered, nil
+}
+
+// attempt sends the delivery once and returns it with the outcome recorded.
+func (s WebhookService) attempt(ctx context.Context, w webhook.Webhook, d webhook.Delivery) webhook.Delivery {
+	now := s.Clock.Now()
+	d.Attempts++
+	d.LastAttemptAt = &now
+	d.ResponseStatus, d.LastError = s.send(ctx, w, d, now)
+	if d.LastError == "" {
+		d.Status = webhook.DeliveryDelivered
+		d.DeliveredAt = &now
+		d.NextAttemptAt = nil
+		return d
+	}
+	logger.GetFromContext(ctx).Info("Webhook delivery attempt failed",
+		zap.String("webhookID", w.GUID),
+		zap.String("deliveryID", d.GUID),
+		zap.Int("attempts", d.Attempts),
+		zap.String("err", d.LastError),
+	)
+	if d.Attempts >= s.maxAttempts() {
+		d.Status = webhook.DeliveryFailed
+		d.NextAttemptAt = nil
+		return d
+	}
+	next := now.Add(RetryDelay(d.Attempts))
+	d.NextAttemptAt = &next
+	return d
+}
+
+// send POSTs the signed delivery and returns the response status and, unless it was a 2xx, why it failed.
+// The response body isn't kept, since the URL could be something other than a webhook receiver.
+func (s WebhookService) send(ctx context.Context, w webhook.Webhook, d webhook.Delivery, now time.Time) (int, string) {
+	req, err := http.NewRequest(http.MethodPost, w.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, err.Error()
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "sp-service-webhooks")
+	req.Header.Set(webhook.EventHeader, string(d.Event))
+	req.Header.Set(webhook.DeliveryHeader, d.GUID)
+	req.Header.Set(webhook.SignatureHeader, webhook.Sign(w.Secret, now, d.Payload))
+	resp, err := s.httpClient().Do(req)
+	if err != nil {
+		return 0, err.Error()
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, io.LimitReader(resp.Body, maxDrainBody))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Sprintf("unexpected status %v", resp.StatusCode)
+	}
+	return resp.StatusCode, ""
+}
+
+// defaultHTTPClient is built once, since each client has its own transport and pool of connections.
+var defaultHTTPClient = publicnet.NewClient(DeliveryTimeout)
+
+func (s WebhookService) httpClient() *http.Client {
+	if s.HTTPClient == nil {
+		return defaultHTTPClient
+	}
+	return s.HTTPClient
+}
+
+func (s WebhookService) maxAttempts() int {
+	if s.MaxAttempts <= 0 {
+		return DefaultMaxAttempts
+	}
+	return s.MaxAttempts
+}
+
+// RetryDelay is the wait before the next attempt of a delivery that has failed the given number of attempts.
+func RetryDelay(attempts int) time.Duration {
+	delay := retryBaseDelay
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= retryMaxDelay {
+			return retryMaxDelay
+		}
+	}
+	return delay
+}
+
+func generateSecret() (string, error) {
+	b := make([]byte, secretBytes)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return "whsec_" + base64.RawURLEncoding.EncodeToString(b), nil
+}
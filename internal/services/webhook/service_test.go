@@ -0,0 +1,360 @@
+package webhookservice
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/worlve/sp-service/internal/models/event"
+	"github.com/worlve/sp-service/internal/models/webhook"
+	"github.com/worlve/sp-service/internal/stores/store/mocks"
+	"github.com/worlve/sp-service/internal/stores/storeerror"
+	"github.com/worlve/sp-service/internal/util/auditlog"
+	"github.com/worlve/sp-service/internal/util/clock"
+	"github.com/worlve/sp-service/internal/util/testutils"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var now = time.Date(2020, 3, 31, 12, 0, 0, 0, time.UTC)
+
+func TestCreateWebhook(t *testing.T) {
+	cases := []struct {
+		name      string
+		params    CreateWebhookParams
+		returnErr error
+	}{
+		{
+			name: "test happy path",
+			params: CreateWebhookParams{
+				Webhook: webhook.Webhook{URL: "https://example.com/hook", Events: []webhook.EventType{webhook.EventPageCreated}},
+				UserID:  "UR_1",
+			},
+		},
+		{
+			name: "test failed to create",
+			params: CreateWebhookParams{
+				Webhook: webhook.Webhook{URL: "https://example.com/hook", Events: []webhook.EventType{webhook.EventPageCreated}},
+				UserID:  "UR_MISSING",
+			},
+			returnErr: errors.New("failed to create webhook: {Webhook:{ID:0 GUID: UserID: URL:https://example.com/hook Events:[page.created] Secret: CreatedAt:<nil>} UserID:UR_MISSING}: failed"),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			webhookStore := new(mocks.WebhookStore)
+			webhookStore.On("CreateWebhook", mock.Anything, mock.Anything).Return(func(ctx context.Context, record webhook.Webhook) webhook.Webhook {
+				return record
+			}, func(ctx context.Context, record webhook.Webhook) error {
+				if tc.returnErr != nil {
+					return errors.New("failed")
+				}
+				return nil
+			})
+			webhookService := WebhookService{WebhookStore: webhookStore}
+			ctx, recorder := auditlog.WithRecorder(context.Background())
+			result, err := webhookService.CreateWebhook(ctx, tc.params)
+			errExpected := testutils.TestErrorAgainstCase(t, err, tc.returnErr)
+			if errExpected {
+				require.Empty(t, recorder.Changes())
+				return
+			}
+			require.True(t, strings.HasPrefix(result.GUID, "WH_"))
+			require.Equal(t, tc.params.UserID, result.UserID)
+			require.Equal(t, tc.params.Webhook.URL, result.URL)
+			require.True(t, strings.HasPrefix(result.Secret, "whsec_"))
+			changes := recorder.Changes()
+			require.Len(t, changes, 1)
+			require.Equal(t, result.GUID, changes[0].TargetID)
+			require.Empty(t, changes[0].After.(webhook.Webhook).Secret, "the secret isn't kept in the audit log")
+		})
+	}
+}
+
+func TestRemoveWebhook(t *testing.T) {
+	cases := []struct {
+		name                string
+		params              RemoveWebhookParams
+		getWebhookErr       error
+		removeWebhookCalled bool
+		returnErr           error
+	}{
+		{
+			name:                "test happy path",
+			params:              RemoveWebhookParams{Webhook: webhook.Webhook{GUID: "WH_1"}, UserID: "UR_1"},
+			removeWebhookCalled: true,
+		},
+		{
+			name:      "test someone else's webhook",
+			params:    RemoveWebhookParams{Webhook: webhook.Webhook{GUID: "WH_1"}, UserID: "UR_2"},
+			returnErr: errors.New("User UR_2 is not authorized to perform the action on the ID WH_1"),
+		},
+		{
+			name:          "test missing webhook",
+			params:        RemoveWebhookParams{Webhook: webhook.Webhook{GUID: "WH_1"}, UserID: "UR_1"},
+			getWebhookErr: &storeerror.NotFound{ID: "WH_1"},
+			returnErr:     errors.New("Could not find: WH_1"),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			webhookStore := new(mocks.WebhookStore)
+			webhookStore.On("GetWebhook", mock.Anything, "WH_1").Return(webhook.Webhook{GUID: "WH_1", UserID: "UR_1"}, tc.getWebhookErr)
+			webhookStore.On("RemoveWebhook", mock.Anything, "WH_1").Return(nil)
+			webhookService := WebhookService{WebhookStore: webhookStore}
+			err := webhookService.RemoveWebhook(context.Background(), tc.params)
+			if tc.removeWebhookCalled {
+				webhookStore.AssertCalled(t, "RemoveWebhook", mock.Anything, "WH_1")
+			} else {
+				webhookStore.AssertNotCalled(t, "RemoveWebhook", mock.Anything, mock.Anything)
+			}
+			testutils.TestErrorAgainstCase(t, err, tc.returnErr)
+		})
+	}
+}
+
+func TestPublish(t *testing.T) {
+	createdAt := now.Add(-time.Minute)
+	cases := []struct {
+		name              string
+		params            PublishParams
+		ownerID           string
+		ownerErr          error
+		webhooks          map[string][]webhook.Webhook
+		returnDeliveredTo []string
+		returnErr         bool
+	}{
+		{
+			name:    "test only subscribed webhooks get a delivery",
+			params:  PublishParams{Event: event.Event{GUID: "EV_1", Type: event.TypePageUpdated, PageID: "PG_1", UserID: "UR_1", Payload: []byte(`{"pageId":"PG_1"}`), CreatedAt: &createdAt}},
+			ownerID: "UR_1",
+			webhooks: map[string][]webhook.Webhook{
+				"UR_1": {
+					{GUID: "WH_1", Events: []webhook.EventType{webhook.EventPageCreated, webhook.EventPageUpdated}},
+					{GUID: "WH_2", Events: []webhook.EventType{webhook.EventPageRemoved}},
+					{GUID: "WH_3", Events: []webhook.EventType{webhook.EventPageUpdated}},
+				},
+			},
+			returnDeliveredTo: []string{"WH_1", "WH_3"},
+		},
+		{
+			name:    "test the owner hears about someone else's change",
+			params:  PublishParams{Event: event.Event{GUID: "EV_1", Type: event.TypePageUpdated, PageID: "PG_1", UserID: "UR_2", Payload: []byte(`{"pageId":"PG_1"}`), CreatedAt: &createdAt}},
+			ownerID: "UR_1",
+			webhooks: map[string][]webhook.Webhook{
+				"UR_1": {{GUID: "WH_1", Events: []webhook.EventType{webhook.EventPageUpdated}}},
+				"UR_2": {{GUID: "WH_2", Events: []webhook.EventType{webhook.EventPageUpdated}}},
+			},
+			returnDeliveredTo: []string{"WH_2", "WH_1"},
+		},
+		{
+			name:     "test purged page only goes to the user who made the change",
+			params:   PublishParams{Event: event.Event{GUID: "EV_1", Type: event.TypePageUpdated, PageID: "PG_1", UserID: "UR_2", Payload: []byte(`{"pageId":"PG_1"}`), CreatedAt: &createdAt}},
+			ownerErr: &storeerror.NotFound{ID: "PG_1"},
+			webhooks: map[string][]webhook.Webhook{
+				"UR_2": {{GUID: "WH_2", Events: []webhook.EventType{webhook.EventPageUpdated}}},
+			},
+			returnDeliveredTo: []string{"WH_2"},
+		},
+		{
+			name:      "test failed to get the owner",
+			params:    PublishParams{Event: event.Event{GUID: "EV_1", Type: event.TypePageUpdated, PageID: "PG_1", UserID: "UR_2", CreatedAt: &createdAt}},
+			ownerErr:  errors.New("failure"),
+			returnErr: true,
+		},
+		{
+			name:    "test no subscribed webhooks",
+			params:  PublishParams{Event: event.Event{GUID: "EV_1", Type: event.TypePageUpdated, PageID: "PG_1", UserID: "UR_1", CreatedAt: &createdAt}},
+			ownerID: "UR_1",
+			webhooks: map[string][]webhook.Webhook{
+				"UR_1": {{GUID: "WH_2", Events: []webhook.EventType{webhook.EventPageRemoved}}},
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			pageStore := new(mocks.PageStore)
+			pageStore.On("GetPageOwner", mock.Anything, tc.params.Event.PageID).Return(tc.ownerID, tc.ownerErr)
+			webhookStore := new(mocks.WebhookStore)
+			for userID, webhooks := range tc.webhooks {
+				webhookStore.On("GetWebhooks", mock.Anything, userID).Return(webhooks, nil)
+			}
+			var deliveries []webhook.Delivery
+			webhookStore.On("CreateDeliveries", mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+				deliveries = args.Get(1).([]webhook.Delivery)
+			})
+			webhookService := WebhookService{WebhookStore: webhookStore, PageStore: pageStore, Clock: clock.MockClock{MockedTime: &now}}
+			err := webhookService.Publish(context.Background(), tc.params)
+			if tc.returnErr {
+				require.Error(t, err)
+				webhookStore.AssertNotCalled(t, "CreateDeliveries", mock.Anything, mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+			var deliveredTo []string
+			for _, d := range deliveries {
+				deliveredTo = append(deliveredTo, d.WebhookGUID)
+				require.Equal(t, webhook.DeliveryPending, d.Status)
+				require.Equal(t, now, *d.NextAttemptAt)
+				var e webhook.Event
+				require.NoError(t, json.Unmarshal(d.Payload, &e))
+				require.Equal(t, tc.params.Event.GUID, e.ID)
+				require.Equal(t, webhook.EventPageUpdated, e.Type)
+				require.Equal(t, createdAt, e.CreatedAt)
+				require.JSONEq(t, string(tc.params.Event.Payload), string(e.Data))
+			}
+			require.Equal(t, tc.returnDeliveredTo, deliveredTo)
+		})
+	}
+}
+
+func TestDeliverDue(t *testing.T) {
+	cases := []struct {
+		name                string
+		paramAttempts       int
+		claimed             bool
+		responseStatus      int
+		returnDelivered     int
+		returnStatus        webhook.DeliveryStatus
+		returnNextAttemptAt *time.Time
+	}{
+		{
+			name:            "test delivered",
+			claimed:         true,
+			responseStatus:  http.StatusNoContent,
+			returnDelivered: 1,
+			returnStatus:    webhook.DeliveryDelivered,
+		},
+		{
+			name:                "test failed attempt is retried with backoff",
+			paramAttempts:       2,
+			claimed:             true,
+			responseStatus:      http.StatusInternalServerError,
+			returnStatus:        webhook.DeliveryPending,
+			returnNextAttemptAt: timePtr(now.Add(2 * time.Minute)),
+		},
+		{
+			name:           "test failed after the last attempt",
+			paramAttempts:  DefaultMaxAttempts - 1,
+			claimed:        true,
+			responseStatus: http.StatusGone,
+			returnStatus:   webhook.DeliveryFailed,
+		},
+		{
+			name:    "test already claimed elsewhere",
+			claimed: false,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var received []*http.Request
+			var receivedBodies [][]byte
+			receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := ioutil.ReadAll(r.Body)
+				received = append(received, r)
+				receivedBodies = append(receivedBodies, body)
+				w.WriteHeader(tc.responseStatus)
+				w.Write([]byte("internal details"))
+			}))
+			defer receiver.Close()
+			due := webhook.Delivery{
+				GUID:          "WD_1",
+				WebhookGUID:   "WH_1",
+				Event:         webhook.EventPageCreated,
+				Payload:       []byte(`{"id":"EV_1"}`),
+				Status:        webhook.DeliveryPending,
+				Attempts:      tc.paramAttempts,
+				NextAttemptAt: &now,
+			}
+			webhookStore := new(mocks.WebhookStore)
+			webhookStore.On("GetDueDeliveries", mock.Anything, now, 10).Return([]webhook.Delivery{due}, nil)
+			webhookStore.On("ClaimDelivery", mock.Anything, due, now.Add(claimLease)).Return(tc.claimed, nil)
+			webhookStore.On("GetWebhook", mock.Anything, "WH_1").Return(webhook.Webhook{GUID: "WH_1", URL: receiver.URL, Secret: "secret"}, nil)
+			var updated webhook.Delivery
+			webhookStore.On("UpdateDelivery", mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+				updated = args.Get(1).(webhook.Delivery)
+			})
+			webhookService := WebhookService{
+				WebhookStore: webhookStore,
+				Clock:        clock.MockClock{MockedTime: &now},
+				HTTPClient:   receiver.Client(),
+			}
+			delivered, err := webhookService.DeliverDue(context.Background(), DeliverDueParams{Limit: 10})
+			require.NoError(t, err)
+			require.Equal(t, tc.returnDelivered, delivered)
+			if !tc.claimed {
+				require.Empty(t, received)
+				webhookStore.AssertNotCalled(t, "UpdateDelivery", mock.Anything, mock.Anything)
+				return
+			}
+			require.Len(t, received, 1)
+			require.Equal(t, "page.created", received[0].Header.Get(webhook.EventHeader))
+			require.Equal(t, "WD_1", received[0].Header.Get(webhook.DeliveryHeader))
+			require.True(t, webhook.VerifySignature("secret", received[0].Header.Get(webhook.SignatureHeader), receivedBodies[0], now, time.Minute))
+			require.Equal(t, due.Payload, receivedBodies[0])
+			require.Equal(t, tc.returnStatus, updated.Status)
+			require.Equal(t, tc.paramAttempts+1, updated.Attempts)
+			require.Equal(t, tc.responseStatus, updated.ResponseStatus)
+			require.Equal(t, tc.returnNextAttemptAt, updated.NextAttemptAt)
+			require.Equal(t, now, *updated.LastAttemptAt)
+			if tc.returnStatus == webhook.DeliveryDelivered {
+				require.Empty(t, updated.LastError)
+			} else {
+				require.Equal(t, fmt.Sprintf("unexpected status %v", tc.responseStatus), updated.LastError)
+			}
+		})
+	}
+}
+
+func TestDeliverDueRefusesPrivateAddresses(t *testing.T) {
+	var received int
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received++
+	}))
+	defer receiver.Close()
+	due := webhook.Delivery{GUID: "WD_1", WebhookGUID: "WH_1", Event: webhook.EventPageCreated, Status: webhook.DeliveryPending, NextAttemptAt: &now}
+	webhookStore := new(mocks.WebhookStore)
+	webhookStore.On("GetDueDeliveries", mock.Anything, now, 10).Return([]webhook.Delivery{due}, nil)
+	webhookStore.On("ClaimDelivery", mock.Anything, due, now.Add(claimLease)).Return(true, nil)
+	// the URL was checked when the webhook was created, but the host can resolve to a private address since
+	webhookStore.On("GetWebhook", mock.Anything, "WH_1").Return(webhook.Webhook{GUID: "WH_1", URL: receiver.URL, Secret: "secret"}, nil)
+	var updated webhook.Delivery
+	webhookStore.On("UpdateDelivery", mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		updated = args.Get(1).(webhook.Delivery)
+	})
+	webhookService := WebhookService{WebhookStore: webhookStore, Clock: clock.MockClock{MockedTime: &now}}
+	delivered, err := webhookService.DeliverDue(context.Background(), DeliverDueParams{Limit: 10})
+	require.NoError(t, err)
+	require.Zero(t, delivered)
+	require.Zero(t, received)
+	require.Equal(t, webhook.DeliveryPending, updated.Status)
+	require.Contains(t, updated.LastError, "not a public address")
+}
+
+func TestRetryDelay(t *testing.T) {
+	cases := []struct {
+		name        string
+		paramTries  int
+		returnDelay time.Duration
+	}{
+		{name: "test first retry", paramTries: 1, returnDelay: 30 * time.Second},
+		{name: "test doubles", paramTries: 3, returnDelay: 2 * time.Minute},
+		{name: "test capped", paramTries: 20, returnDelay: 6 * time.Hour},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.returnDelay, RetryDelay(tc.paramTries))
+		})
+	}
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
@@ -0,0 +1,81 @@
+package memorystore
+
+import (
+	"context"
+	"time"
+
+	"github.com/worlve/sp-service/internal/models/audit"
+	"github.com/worlve/sp-service/internal/stores/storeerror"
+	"github.com/pkg/errors"
+)
+
+// AuditStore is the in-memory store for the audit log
+type AuditStore struct {
+	db *DB
+}
+
+// NewAuditStore returns an AuditStore
+func NewAuditStore(memdb *DB) AuditStore {
+	return AuditStore{
+		db: memdb,
+	}
+}
+
+// CreateEntries adds the entries to the audit log.
+func (s AuditStore) CreateEntries(ctx context.Context, records []audit.Entry) error {
+	for _, record := range records {
+		if record.GUID == "" {
+			return errors.New("must provide record.GUID to create the audit entry")
+		}
+	}
+	if s.db == nil {
+		return &storeerror.DBNotSetUp{}
+	}
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	t := time.Now()
+	for _, record := range records {
+		record.ID = s.db.tables.nextID("AuditEntry")
+		record.CreatedAt = &t
+		s.db.tables.auditEntries = append(s.db.tables.auditEntries, record)
+	}
+	return nil
+}
+
+// GetEntries returns a batch of the entries within the filter, newest first, based on the nextBatchId
+func (s AuditStore) GetEntries(ctx context.Context, filter audit.Filter, thisBatchID string, limit int) ([]audit.Entry, int, string, error) {
+	if s.db == nil {
+		return nil, 0, "", &storeerror.DBNotSetUp{}
+	}
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+	var all []audit.Entry
+	for i := len(s.db.tables.auditEntries) - 1; i >= 0; i-- {
+		if e := s.db.tables.auditEntries[i]; filter.Matches(e) {
+			all = append(all, e)
+		}
+	}
+	start := 0
+	if thisBatchID != "" {
+		start = -1
+		for i, e := range all {
+			if e.GUID == thisBatchID {
+				start = i
+				break
+			}
+		}
+		if start < 0 {
+			return nil, 0, "", errors.Wrapf(&storeerror.NotFound{ID: thisBatchID}, "unable to use thisBatchID: %v", thisBatchID)
+		}
+	}
+	entries := make([]audit.Entry, 0, limit)
+	nextBatchID := ""
+	for _, e := range all[start:] {
+		if len(entries) == limit {
+			nextBatchID = e.GUID
+			break
+		}
+		entries = append(entries, e)
+	}
+	return entries, len(all), nextBatchID, nil
+}
@@ -0,0 +1,220 @@
+package memorystore
+
+import (
+	"context"
+	"time"
+
+	"github.com/worlve/sp-service/internal/models/comment"
+	"github.com/worlve/sp-service/internal/stores/storeerror"
+	"github.com/pkg/errors"
+)
+
+// CommentStore is the in-memory store for page comments
+type CommentStore struct {
+	db *DB
+}
+
+// NewCommentStore returns a CommentStore
+func NewCommentStore(memdb *DB) CommentStore {
+	return CommentStore{
+		db: memdb,
+	}
+}
+
+// CreateComment creates a new comment on record.PageGUID by record.UserID.
+func (s CommentStore) CreateComment(ctx context.Context, record comment.Comment) (comment.Comment, error) {
+	if record.GUID == "" {
+		return record, errors.New("must provide record.GUID to create the comment")
+	}
+	if record.Body == "" {
+		return record, errors.New("must provide record.Body to create the comment")
+	}
+	if s.db == nil {
+		return record, &storeerror.DBNotSetUp{}
+	}
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	if _, ok := s.db.tables.findPage(record.PageGUID); !ok {
+		return record, &storeerror.NotFound{
+			ID: record.PageGUID,
+		}
+	}
+	if _, ok := s.db.tables.findUser(record.UserID); !ok {
+		return record, &storeerror.NotFound{
+			ID: record.UserID,
+		}
+	}
+	if _, ok := s.db.tables.findCommentIndex(record.GUID); ok {
+		return record, &storeerror.DupEntry{
+			ID: record.GUID,
+		}
+	}
+	t := time.Now()
+	record.ID = s.db.tables.nextID("Comment")
+	record.CreatedAt = &t
+	record.UpdatedAt = &t
+	if record.Mentions == nil {
+		record.Mentions = []string{}
+	}
+	s.db.tables.comments = append(s.db.tables.comments, record)
+	return record, nil
+}
+
+// GetComment returns the given comment.
+func (s CommentStore) GetComment(ctx context.Context, guid string) (comment.Comment, error) {
+	if guid == "" {
+		return comment.Comment{}, errors.New("must provide guid to get the comment")
+	}
+	if s.db == nil {
+		return comment.Comment{}, &storeerror.DBNotSetUp{}
+	}
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+	index, ok := s.db.tables.findCommentIndex(guid)
+	if !ok {
+		return comment.Comment{}, &storeerror.NotFound{
+			ID: guid,
+		}
+	}
+	return s.db.tables.comments[index], nil
+}
+
+// GetThreads returns a batch of the page's threads within the filter, newest first, based on the nextBatchId
+func (s CommentStore) GetThreads(ctx context.Context, pageGUID string, filter comment.Filter, thisBatchID string, limit int) ([]comment.Comment, int, string, error) {
+	if pageGUID == "" {
+		return nil, 0, "", errors.New("must provide pageGUID to get threads")
+	}
+	return s.getBatch(thisBatchID, limit, true, func(c comment.Comment) bool {
+		return c.PageGUID == pageGUID && c.IsThread() && filter.Matches(c)
+	})
+}
+
+// GetReplies returns a batch of the thread's replies, oldest first, based on the nextBatchId
+func (s CommentStore) GetReplies(ctx context.Context, threadGUID string, thisBatchID string, limit int) ([]comment.Comment, int, string, error) {
+	if threadGUID == "" {
+		return nil, 0, "", errors.New("must provide threadGUID to get replies")
+	}
+	return s.getBatch(thisBatchID, limit, false, func(c comment.Comment) bool {
+		return c.ThreadGUID == threadGUID
+	})
+}
+
+// GetMentions returns a batch of the comments that mention the user, newest first, based on the nextBatchId
+func (s CommentStore) GetMentions(ctx context.Context, userID string, thisBatchID string, limit int) ([]comment.Comment, int, string, error) {
+	if userID == "" {
+		return nil, 0, "", errors.New("must provide userID to get mentions")
+	}
+	return s.getBatch(thisBatchID, limit, true, func(c comment.Comment) bool {
+		for _, mention := range c.Mentions {
+			if mention == userID {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+func (s CommentStore) getBatch(thisBatchID string, limit int, newestFirst bool, matches func(c comment.Comment) bool) ([]comment.Comment, int, string, error) {
+	if s.db == nil {
+		return nil, 0, "", &storeerror.DBNotSetUp{}
+	}
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+	var all []comment.Comment
+	for i := range s.db.tables.comments {
+		index := i
+		if newestFirst {
+			index = len(s.db.tables.comments) - 1 - i
+		}
+		if c := s.db.tables.comments[index]; matches(c) {
+			all = append(all, c)
+		}
+	}
+	start := 0
+	if thisBatchID != "" {
+		start = -1
+		for i, c := range all {
+			if c.GUID == thisBatchID {
+				start = i
+				break
+			}
+		}
+		if start < 0 {
+			return nil, 0, "", errors.Wrapf(&storeerror.NotFound{ID: thisBatchID}, "unable to use thisBatchID: %v", thisBatchID)
+		}
+	}
+	comments := make([]comment.Comment, 0, limit)
+	nextBatchID := ""
+	for _, c := range all[start:] {
+		if len(comments) == limit {
+			nextBatchID = c.GUID
+			break
+		}
+		comments = append(comments, c)
+	}
+	return comments, len(all), nextBatchID, nil
+}
+
+// UpdateComment sets the comment's body, mentions, and whether it's resolved.
+func (s CommentStore) UpdateComment(ctx context.Context, record comment.Comment) (comment.Comment, error) {
+	if record.GUID == "" {
+		return record, errors.New("must provide record.GUID to update the comment")
+	}
+	if record.Body == "" {
+		return record, errors.New("must provide record.Body to update the comment")
+	}
+	if s.db == nil {
+		return record, &storeerror.DBNotSetUp{}
+	}
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	index, ok := s.db.tables.findCommentIndex(record.GUID)
+	if !ok {
+		return record, &storeerror.NotFound{
+			ID: record.GUID,
+		}
+	}
+	t := time.Now()
+	c := s.db.tables.comments[index]
+	c.Body = record.Body
+	c.Mentions = append([]string{}, record.Mentions...)
+	c.ResolvedAt = record.ResolvedAt
+	c.ResolvedBy = record.ResolvedBy
+	c.UpdatedAt = &t
+	s.db.tables.comments[index] = c
+	return c, nil
+}
+
+// RemoveComment permanently deletes the given comment, along with its replies if it starts a thread.
+func (s CommentStore) RemoveComment(ctx context.Context, guid string) error {
+	if guid == "" {
+		return errors.New("must provide guid to remove the comment")
+	}
+	if s.db == nil {
+		return &storeerror.DBNotSetUp{}
+	}
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	if _, ok := s.db.tables.findCommentIndex(guid); !ok {
+		return &storeerror.NotFound{
+			ID: guid,
+		}
+	}
+	var comments []comment.Comment
+	for _, c := range s.db.tables.comments {
+		if c.GUID != guid && c.ThreadGUID != guid {
+			comments = append(comments, c)
+		}
+	}
+	s.db.tables.comments = comments
+	return nil
+}
+
+func (t *tables) findCommentIndex(guid string) (int, bool) {
+	for i, c := range t.comments {
+		if c.GUID == guid {
+			return i, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,178 @@
+package memorystore
+
+import (
+	"context"
+	"time"
+
+	"github.com/worlve/sp-service/internal/models/event"
+	"github.com/worlve/sp-service/internal/stores/storeerror"
+	"github.com/pkg/errors"
+)
+
+// EventStore is the in-memory store for the event outbox and the offsets of its subscribers
+type EventStore struct {
+	db *DB
+}
+
+// NewEventStore returns an EventStore
+func NewEventStore(memdb *DB) EventStore {
+	return EventStore{
+		db: memdb,
+	}
+}
+
+// CreateEvent adds the event to the outbox, returning it with its offset set.
+func (s EventStore) CreateEvent(ctx context.Context, record event.Event) (event.Event, error) {
+	if record.GUID == "" {
+		return record, errors.New("must provide record.GUID to create the event")
+	}
+	if record.Type == "" {
+		return record, errors.New("must provide record.Type to create the event")
+	}
+	if s.db == nil {
+		return record, &storeerror.DBNotSetUp{}
+	}
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	t := time.Now()
+	record.Offset = s.db.tables.nextID("Event")
+	record.CreatedAt = &t
+	s.db.tables.events = append(s.db.tables.events, record)
+	return record, nil
+}
+
+// GetEvents returns up to limit events after the given offset, in order.
+func (s EventStore) GetEvents(ctx context.Context, afterOffset int64, limit int) ([]event.Event, error) {
+	if s.db == nil {
+		return nil, &storeerror.DBNotSetUp{}
+	}
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+	events := make([]event.Event, 0)
+	for _, e := range s.db.tables.events {
+		if len(events) >= limit {
+			break
+		}
+		if e.Offset > afterOffset {
+			events = append(events, e)
+		}
+	}
+	return events, nil
+}
+
+// GetLastOffset returns the offset of the newest event, or 0 when there are none.
+func (s EventStore) GetLastOffset(ctx context.Context) (int64, error) {
+	if s.db == nil {
+		return 0, &storeerror.DBNotSetUp{}
+	}
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+	if len(s.db.tables.events) == 0 {
+		return 0, nil
+	}
+	return s.db.tables.events[len(s.db.tables.events)-1].Offset, nil
+}
+
+// PurgeEvents permanently deletes every event created before the given time.
+func (s EventStore) PurgeEvents(ctx context.Context, createdBefore time.Time) error {
+	if s.db == nil {
+		return &storeerror.DBNotSetUp{}
+	}
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	var events []event.Event
+	for _, e := range s.db.tables.events {
+		if !e.CreatedAt.Before(createdBefore) {
+			events = append(events, e)
+		}
+	}
+	s.db.tables.events = events
+	return nil
+}
+
+// GetSubscriberOffset returns the offset of the last event the subscriber handled.
+func (s EventStore) GetSubscriberOffset(ctx context.Context, subscriber string) (int64, error) {
+	if subscriber == "" {
+		return 0, errors.New("must provide subscriber to get its offset")
+	}
+	if s.db == nil {
+		return 0, &storeerror.DBNotSetUp{}
+	}
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+	offset, ok := s.db.tables.subscriberOffsets[subscriber]
+	if !ok {
+		return 0, &storeerror.NotFound{
+			ID: subscriber,
+		}
+	}
+	return offset, nil
+}
+
+// ClaimSubscriber leases the subscriber until leaseUntil, so no other instance hands it events in the meantime.
+// Returns false if another lease on it hasn't ended by now.
+func (s EventStore) ClaimSubscriber(ctx context.Context, subscriber string, now, leaseUntil time.Time) (bool, error) {
+	if subscriber == "" {
+		return false, errors.New("must provide subscriber to claim it")
+	}
+	if s.db == nil {
+		return false, &storeerror.DBNotSetUp{}
+	}
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	if _, ok := s.db.tables.subscriberOffsets[subscriber]; !ok {
+		return false, nil
+	}
+	if claimedUntil, ok := s.db.tables.subscriberClaims[subscriber]; ok && claimedUntil.After(now) {
+		return false, nil
+	}
+	s.db.tables.subscriberClaims[subscriber] = leaseUntil
+	return true, nil
+}
+
+// AdvanceSubscriberOffset moves the subscriber's offset from one event to a later one.
+// Returns false if the offset is no longer from, such as when another instance got there first.
+func (s EventStore) AdvanceSubscriberOffset(ctx context.Context, subscriber string, from, to int64) (bool, error) {
+	if subscriber == "" {
+		return false, errors.New("must provide subscriber to advance its offset")
+	}
+	if s.db == nil {
+		return false, &storeerror.DBNotSetUp{}
+	}
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	offset, ok := s.db.tables.subscriberOffsets[subscriber]
+	if !ok || offset != from {
+		return false, nil
+	}
+	s.db.tables.subscriberOffsets[subscriber] = to
+	return true, nil
+}
+
+// SetSubscriberOffset sets the subscriber's offset, adding the subscriber if it's new.
+func (s EventStore) SetSubscriberOffset(ctx context.Context, subscriber string, offset int64) error {
+	if subscriber == "" {
+		return errors.New("must provide subscriber to set its offset")
+	}
+	if s.db == nil {
+		return &storeerror.DBNotSetUp{}
+	}
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	s.db.tables.subscriberOffsets[subscriber] = offset
+	return nil
+}
+
+// GetSubscriberOffsets returns the offset of every subscriber by its name.
+func (s EventStore) GetSubscriberOffsets(ctx context.Context) (map[string]int64, error) {
+	if s.db == nil {
+		return nil, &storeerror.DBNotSetUp{}
+	}
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+	offsets := make(map[string]int64, len(s.db.tables.subscriberOffsets))
+	for subscriber, offset := range s.db.tables.subscriberOffsets {
+		offsets[subscriber] = offset
+	}
+	return offsets, nil
+}
@@ -0,0 +1,28 @@
+package memorystore
+
+import (
+	"context"
+
+	"github.com/worlve/sp-service/internal/stores/storeerror"
+)
+
+// HealthcheckStore is the in-memory store for the healthcheck
+type HealthcheckStore struct {
+	db *DB
+}
+
+// NewHealthcheckStore returns a HealthcheckStore
+func NewHealthcheckStore(memdb *DB) HealthcheckStore {
+	return HealthcheckStore{
+		db: memdb,
+	}
+}
+
+// IsHealthy checks if the db is healthy.
+// The in-memory db is always healthy once it is set up.
+func (s HealthcheckStore) IsHealthy(ctx context.Context) (bool, error) {
+	if s.db == nil {
+		return false, &storeerror.DBNotSetUp{}
+	}
+	return true, nil
+}
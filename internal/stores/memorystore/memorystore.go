@@ -0,0 +1,164 @@
+// Package memorystore is an in-memory implementation of the store interfaces.
+// It behaves the same as mysqlstore (see the storetestutils conformance tests) so the service
+// can be demoed and integration tested without a database.
+package memorystore
+
+import (
+	"sync"
+	"time"
+
+	"github.com/worlve/sp-service/internal/models/appuser"
+	"github.com/worlve/sp-service/internal/models/audit"
+	"github.com/worlve/sp-service/internal/models/comment"
+	"github.com/worlve/sp-service/internal/models/event"
+	"github.com/worlve/sp-service/internal/models/pagetemplate"
+	"github.com/worlve/sp-service/internal/models/permission"
+	"github.com/worlve/sp-service/internal/models/property"
+	"github.com/worlve/sp-service/internal/models/version"
+	"github.com/worlve/sp-service/internal/models/webhook"
+)
+
+// DB is the in-memory database shared by the stores.
+type DB struct {
+	mu     sync.RWMutex
+	tables *tables
+}
+
+type tables struct {
+	lastIDs        map[string]int64
+	users          []appuser.User
+	versions       []version.Version
+	pageTemplates  []pagetemplate.PageTemplate
+	properties     []property.Property
+	pages          []pageRow
+	pageOwners     []pageOwnerRow
+	pageProperties map[int64][]property.Property
+	pageDetails    map[int64][]pageDetailRow
+	webhooks       []webhook.Webhook
+	deliveries     []webhook.Delivery
+	events         []event.Event
+	auditEntries   []audit.Entry
+	comments       []comment.Comment
+	// subscriberOffsets and subscriberClaims are the offsets and leases of the event subscribers by their name.
+	subscriberOffsets map[string]int64
+	subscriberClaims  map[string]time.Time
+}
+
+type pageRow struct {
+	ID             int64
+	GUID           string
+	VersionID      int64
+	PageTemplateID int64
+	Title          string
+	Summary        string
+	PermissionType permission.Type
+	CreatedAt      *time.Time
+	UpdatedAt      *time.Time
+	DeletedAt      *time.Time
+}
+
+// pageDetailRow keeps the partitions encoded, as mysql does, so stored details don't share memory with the caller's.
+type pageDetailRow struct {
+	ID         int64
+	GUID       string
+	Title      string
+	Summary    string
+	Partitions []byte
+}
+
+type pageOwnerRow struct {
+	PageID  int64
+	UserID  int64
+	IsOwner bool
+}
+
+// NewDB returns an empty DB.
+func NewDB() *DB {
+	return &DB{
+		tables: newTables(),
+	}
+}
+
+func newTables() *tables {
+	return &tables{
+		lastIDs:           map[string]int64{},
+		pageProperties:    map[int64][]property.Property{},
+		pageDetails:       map[int64][]pageDetailRow{},
+		subscriberOffsets: map[string]int64{},
+		subscriberClaims:  map[string]time.Time{},
+	}
+}
+
+func (t *tables) nextID(table string) int64 {
+	t.lastIDs[table]++
+	return t.lastIDs[table]
+}
+
+// clone deep copies the tables so a unit of work can modify them without affecting the originals.
+func (t *tables) clone() *tables {
+	c := newTables()
+	for table, id := range t.lastIDs {
+		c.lastIDs[table] = id
+	}
+	c.users = append(c.users, t.users...)
+	c.versions = append(c.versions, t.versions...)
+	c.pageTemplates = append(c.pageTemplates, t.pageTemplates...)
+	c.properties = append(c.properties, t.properties...)
+	c.pages = append(c.pages, t.pages...)
+	c.pageOwners = append(c.pageOwners, t.pageOwners...)
+	c.webhooks = append(c.webhooks, t.webhooks...)
+	c.deliveries = append(c.deliveries, t.deliveries...)
+	c.events = append(c.events, t.events...)
+	c.auditEntries = append(c.auditEntries, t.auditEntries...)
+	c.comments = append(c.comments, t.comments...)
+	for pageID, pageProperties := range t.pageProperties {
+		c.pageProperties[pageID] = append([]property.Property(nil), pageProperties...)
+	}
+	for pageID, pageDetails := range t.pageDetails {
+		c.pageDetails[pageID] = append([]pageDetailRow(nil), pageDetails...)
+	}
+	for subscriber, offset := range t.subscriberOffsets {
+		c.subscriberOffsets[subscriber] = offset
+	}
+	for subscriber, claimedUntil := range t.subscriberClaims {
+		c.subscriberClaims[subscriber] = claimedUntil
+	}
+	return c
+}
+
+// AddUser adds the user to the DB, returning it with its ID set.
+func (db *DB) AddUser(u appuser.User) appuser.User {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	u.ID = db.tables.nextID("User")
+	db.tables.users = append(db.tables.users, u)
+	return u
+}
+
+// AddVersion adds the version to the DB, returning it with its ID set.
+func (db *DB) AddVersion(v version.Version) version.Version {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	v.ID = db.tables.nextID("Version")
+	db.tables.versions = append(db.tables.versions, v)
+	return v
+}
+
+// AddPageTemplate adds the page template to the DB, returning it with its ID set.
+func (db *DB) AddPageTemplate(pt pagetemplate.PageTemplate) pagetemplate.PageTemplate {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	pt.ID = db.tables.nextID("PageTemplate")
+	db.tables.pageTemplates = append(db.tables.pageTemplates, pt)
+	return pt
+}
+
+// AddProperty adds the property definition (its key and type) to the DB, returning it with its ID set.
+func (db *DB) AddProperty(p property.Property) property.Property {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	p.ID = db.tables.nextID("Property")
+	p.Value = nil
+	db.tables.properties = append(db.tables.properties, p)
+	return p
+}
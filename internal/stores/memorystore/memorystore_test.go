@@ -0,0 +1,32 @@
+package memorystore
+
+import (
+	"testing"
+
+	"github.com/worlve/sp-service/internal/stores/storetestutils"
+)
+
+func newTestBackend(t *testing.T, fixtures storetestutils.Fixtures) storetestutils.Backend {
+	memdb := NewDB()
+	for _, u := range fixtures.Users {
+		memdb.AddUser(u)
+	}
+	for _, v := range fixtures.Versions {
+		memdb.AddVersion(v)
+	}
+	for _, pt := range fixtures.PageTemplates {
+		memdb.AddPageTemplate(pt)
+	}
+	for _, p := range fixtures.Properties {
+		memdb.AddProperty(p)
+	}
+	return storetestutils.Backend{
+		Stores:           newStores(memdb),
+		HealthcheckStore: NewHealthcheckStore(memdb),
+		UnitOfWork:       NewUnitOfWork(memdb),
+	}
+}
+
+func TestConformance(t *testing.T) {
+	storetestutils.RunConformanceTests(t, newTestBackend)
+}
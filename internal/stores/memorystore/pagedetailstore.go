@@ -0,0 +1,150 @@
+package memorystore
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/worlve/sp-service/internal/models/pagedetail"
+	"github.com/worlve/sp-service/internal/stores/storeerror"
+	"github.com/worlve/sp-service/internal/util/guidgen"
+	"github.com/pkg/errors"
+)
+
+// PageDetailStore is the in-memory store for a page detail
+type PageDetailStore struct {
+	db *DB
+}
+
+// NewPageDetailStore returns a PageDetailStore
+func NewPageDetailStore(memdb *DB) PageDetailStore {
+	return PageDetailStore{
+		db: memdb,
+	}
+}
+
+// UpdatePageDetail sets the title and summary of the page's detail, and returns the detail as it's stored.
+// If the page has no detail with the record's GUID, a storeerror.NotFound will be returned.
+func (s PageDetailStore) UpdatePageDetail(ctx context.Context, pageGUID string, record pagedetail.PageDetail) (pagedetail.PageDetail, error) {
+	if pageGUID == "" {
+		return pagedetail.PageDetail{}, errors.New("must provide pageGUID to update the page detail")
+	}
+	if record.GUID == "" {
+		return pagedetail.PageDetail{}, errors.New("must provide record.GUID to update the page detail")
+	}
+	if record.Title == "" {
+		return pagedetail.PageDetail{}, errors.New("must provide record.Title to update the page detail")
+	}
+	if s.db == nil {
+		return pagedetail.PageDetail{}, &storeerror.DBNotSetUp{}
+	}
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	row, ok := s.db.tables.findPage(pageGUID)
+	if !ok {
+		return pagedetail.PageDetail{}, errors.Wrapf(&storeerror.NotFound{ID: pageGUID}, "unable to get Page.ID for guid: %v", pageGUID)
+	}
+	detailRows := s.db.tables.pageDetails[row.ID]
+	for i := range detailRows {
+		if detailRows[i].GUID != record.GUID {
+			continue
+		}
+		detailRows[i].Title = record.Title
+		detailRows[i].Summary = record.Summary
+		d, err := detailRows[i].toPageDetail()
+		if err != nil {
+			return pagedetail.PageDetail{}, err
+		}
+		return d, nil
+	}
+	return pagedetail.PageDetail{}, &storeerror.NotFound{ID: record.GUID}
+}
+
+// GetPageDetails returns the page's details in order.
+func (s PageDetailStore) GetPageDetails(ctx context.Context, pageGUID string) ([]pagedetail.PageDetail, error) {
+	if pageGUID == "" {
+		return nil, errors.New("must provide pageGUID to get the page details")
+	}
+	if s.db == nil {
+		return nil, &storeerror.DBNotSetUp{}
+	}
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+	details := make([]pagedetail.PageDetail, 0)
+	row, ok := s.db.tables.findPage(pageGUID)
+	if !ok {
+		return details, nil
+	}
+	for _, detailRow := range s.db.tables.pageDetails[row.ID] {
+		d, err := detailRow.toPageDetail()
+		if err != nil {
+			return nil, err
+		}
+		details = append(details, d)
+	}
+	return details, nil
+}
+
+func (row pageDetailRow) toPageDetail() (pagedetail.PageDetail, error) {
+	d := pagedetail.PageDetail{
+		ID:         row.ID,
+		GUID:       row.GUID,
+		Title:      row.Title,
+		Summary:    row.Summary,
+		Partitions: make([]pagedetail.Partition, 0),
+	}
+	err := json.Unmarshal(row.Partitions, &d.Partitions)
+	if err == nil {
+		err = pagedetail.UnmarshalPartitions(d.Partitions)
+	}
+	if err != nil {
+		return pagedetail.PageDetail{}, errors.Wrapf(err, "unable to read the partitions of detail %v", d.GUID)
+	}
+	if d.Partitions == nil {
+		d.Partitions = make([]pagedetail.Partition, 0)
+	}
+	return d, nil
+}
+
+// ReplacePageDetails replaces the current page's details with the new details, in order.
+// Details without a GUID are given one.
+func (s PageDetailStore) ReplacePageDetails(ctx context.Context, pageGUID string, details []pagedetail.PageDetail) error {
+	if pageGUID == "" {
+		return errors.New("must provide pageGUID to replace the page details")
+	}
+	if s.db == nil {
+		return &storeerror.DBNotSetUp{}
+	}
+	seen := make(map[string]bool)
+	for i := range details {
+		if details[i].GUID == "" {
+			details[i].GUID = guidgen.GenerateGUID("DT", 15)
+		}
+		if seen[details[i].GUID] {
+			return &storeerror.DupEntry{ID: details[i].GUID}
+		}
+		seen[details[i].GUID] = true
+	}
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	row, ok := s.db.tables.findPage(pageGUID)
+	if !ok {
+		return errors.Wrapf(&storeerror.NotFound{ID: pageGUID}, "unable to get Page.ID for guid: %v", pageGUID)
+	}
+	var replacements []pageDetailRow
+	for i, d := range details {
+		partitions, err := json.Marshal(d.Partitions)
+		if err != nil {
+			return errors.Wrapf(err, "unable to write the partitions of detail %v", d.GUID)
+		}
+		details[i].ID = s.db.tables.nextID("PageDetail")
+		replacements = append(replacements, pageDetailRow{
+			ID:         details[i].ID,
+			GUID:       d.GUID,
+			Title:      d.Title,
+			Summary:    d.Summary,
+			Partitions: partitions,
+		})
+	}
+	s.db.tables.pageDetails[row.ID] = replacements
+	return nil
+}
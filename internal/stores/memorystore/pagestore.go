@@ -0,0 +1,682 @@
+package memorystore
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/worlve/sp-service/internal/models/comment"
+	"github.com/worlve/sp-service/internal/models/page"
+	"github.com/worlve/sp-service/internal/models/property"
+	"github.com/worlve/sp-service/internal/stores/storeerror"
+	"github.com/worlve/sp-service/internal/util/guidgen"
+	"github.com/pkg/errors"
+)
+
+// PageStore is the in-memory store for pages
+type PageStore struct {
+	db *DB
+}
+
+// NewPageStore returns a PageStore
+func NewPageStore(memdb *DB) PageStore {
+	return PageStore{
+		db: memdb,
+	}
+}
+
+// CreatePage creates a new page.
+func (s PageStore) CreatePage(ctx context.Context, record page.Page, ownerID int64) (page.Page, error) {
+	if record.GUID == "" {
+		return record, errors.New("must provide record.GUID to create the page")
+	}
+	if record.Title == "" {
+		return record, errors.New("must provide record.Title to create the page")
+	}
+	if record.Version.ID == 0 {
+		return record, errors.New("must provide record.Version.ID to create the page")
+	}
+	if record.PermissionType == "" {
+		return record, errors.New("must provide record.PermissionType to create the page")
+	}
+	if record.PageTemplate.ID == 0 {
+		return record, errors.New("must provide record.PageTemplate.ID to create the page")
+	}
+	if ownerID == 0 {
+		return record, errors.New("must provide ownerID to create the page")
+	}
+	if s.db == nil {
+		return record, &storeerror.DBNotSetUp{}
+	}
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	if _, ok := s.db.tables.findPage(record.GUID); ok {
+		return record, &storeerror.DupEntry{
+			ID: record.GUID,
+		}
+	}
+	err := s.db.tables.checkPageReferences(record.Version.ID, record.PageTemplate.ID)
+	if err != nil {
+		return record, err
+	}
+	if !s.db.tables.hasUser(ownerID) {
+		return record, errors.Errorf("unable to find user %v to own the page", ownerID)
+	}
+	t := time.Now()
+	record.CreatedAt = &t
+	record.UpdatedAt = &t
+	record.ID = s.db.tables.nextID("Page")
+	s.db.tables.pages = append(s.db.tables.pages, pageRow{
+		ID:             record.ID,
+		GUID:           record.GUID,
+		VersionID:      record.Version.ID,
+		PageTemplateID: record.PageTemplate.ID,
+		Title:          record.Title,
+		Summary:        record.Summary,
+		PermissionType: record.PermissionType,
+		CreatedAt:      record.CreatedAt,
+		UpdatedAt:      record.UpdatedAt,
+	})
+	s.db.tables.pageOwners = append(s.db.tables.pageOwners, pageOwnerRow{
+		PageID:  record.ID,
+		UserID:  ownerID,
+		IsOwner: true,
+	})
+	return record, nil
+}
+
+// CanEditPage checks if the given user can modify the given page. If not, a storeerror.NotAuthorized will be returned.
+// Will also return whether or not the user is the original owner.
+func (s PageStore) CanEditPage(ctx context.Context, guid, userID string) (bool, error) {
+	if guid == "" {
+		return false, errors.New("must provide a guid to check privileges")
+	}
+	if userID == "" {
+		return false, errors.New("must provide a userID to check privileges")
+	}
+	if s.db == nil {
+		return false, &storeerror.DBNotSetUp{}
+	}
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+	return s.db.tables.canEditPage(guid, userID)
+}
+
+// CanReadPage checks if the given user can read the given page. If not, a storeerror.NotAuthorized will be returned.
+// Will also return whether or not the user is the original owner.
+func (s PageStore) CanReadPage(ctx context.Context, guid, userID string) (bool, error) {
+	isOwner, err := s.CanEditPage(ctx, guid, userID)
+	if err != nil {
+		if _, ok := err.(*storeerror.NotAuthorized); !ok {
+			return isOwner, err
+		}
+	}
+	if isOwner {
+		return isOwner, nil
+	}
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+	row, ok := s.db.tables.findPage(guid)
+	if !ok {
+		return false, &storeerror.NotAuthorized{
+			UserID:  userID,
+			TableID: guid,
+		}
+	}
+	return row.PermissionType.IsPublic(), nil
+}
+
+// GetPageOwner returns the GUID of the user who owns the given page, even once it's been removed.
+// If the page doesn't exist, a storeerror.NotFound will be returned.
+func (s PageStore) GetPageOwner(ctx context.Context, guid string) (string, error) {
+	if guid == "" {
+		return "", errors.New("must provide a guid to get the owner")
+	}
+	if s.db == nil {
+		return "", &storeerror.DBNotSetUp{}
+	}
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+	row, ok := s.db.tables.findPage(guid)
+	if !ok {
+		return "", &storeerror.NotFound{
+			ID: guid,
+		}
+	}
+	for _, owner := range s.db.tables.pageOwners {
+		if owner.PageID != row.ID || !owner.IsOwner {
+			continue
+		}
+		for _, u := range s.db.tables.users {
+			if u.ID == owner.UserID {
+				return u.GUID, nil
+			}
+		}
+	}
+	return "", &storeerror.NotFound{
+		ID: guid,
+	}
+}
+
+// UpdatePage sets the given page.
+func (s PageStore) UpdatePage(ctx context.Context, record page.Page) error {
+	if record.GUID == "" {
+		return errors.New("must provide record.GUID to update the page")
+	}
+	if s.db == nil {
+		return &storeerror.DBNotSetUp{}
+	}
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	return s.db.tables.updatePage(record)
+}
+
+// GetPage returns back the given page.
+func (s PageStore) GetPage(ctx context.Context, guid string) (page.Page, error) {
+	if guid == "" {
+		return page.Page{}, errors.New("must provide guid to get the page")
+	}
+	if s.db == nil {
+		return page.Page{}, &storeerror.DBNotSetUp{}
+	}
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+	row, ok := s.db.tables.findPage(guid)
+	if !ok || row.DeletedAt != nil {
+		return page.Page{}, &storeerror.NotFound{
+			ID: guid,
+		}
+	}
+	p := s.db.tables.getPage(row)
+	p.DeletedAt = nil
+	return p, nil
+}
+
+// GetPages returns a list of pages based on the nextBatchId
+func (s PageStore) GetPages(ctx context.Context, userID, thisBatchID string, limit int) ([]page.Page, int, string, error) {
+	return s.getPages(ctx, userID, thisBatchID, limit, false)
+}
+
+// GetUserPageGUIDs returns the GUIDs of the pages the user owns or that are shared with them, removed ones included.
+func (s PageStore) GetUserPageGUIDs(ctx context.Context, userID string) ([]string, error) {
+	if userID == "" {
+		return nil, errors.New("must provide userID to get the page GUIDs")
+	}
+	if s.db == nil {
+		return nil, &storeerror.DBNotSetUp{}
+	}
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+	guids := make([]string, 0)
+	for _, removed := range []bool{false, true} {
+		for _, row := range s.db.tables.getOwnedPages(userID, removed) {
+			guids = append(guids, row.GUID)
+		}
+	}
+	return guids, nil
+}
+
+// GetRemovedPages returns a list of removed pages based on the nextBatchId
+func (s PageStore) GetRemovedPages(ctx context.Context, userID, thisBatchID string, limit int) ([]page.Page, int, string, error) {
+	return s.getPages(ctx, userID, thisBatchID, limit, true)
+}
+
+func (s PageStore) getPages(ctx context.Context, userID, thisBatchID string, limit int, removed bool) (pages []page.Page, total int, nextBatchID string, returnErr error) {
+	if userID == "" {
+		returnErr = errors.New("must provide userID to get pages")
+		return
+	}
+	if s.db == nil {
+		returnErr = &storeerror.DBNotSetUp{}
+		return
+	}
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+	thisPageID := int64(0)
+	if thisBatchID != "" {
+		row, ok := s.db.tables.findPage(thisBatchID)
+		if !ok {
+			returnErr = errors.Wrapf(&storeerror.NotFound{ID: thisBatchID}, "unable to use thisBatchID: %v", thisBatchID)
+			return
+		}
+		thisPageID = row.ID
+	}
+	pages = make([]page.Page, 0)
+	for _, row := range s.db.tables.getOwnedPages(userID, removed) {
+		total++
+		if row.ID < thisPageID {
+			continue
+		}
+		if len(pages) == limit {
+			if nextBatchID == "" {
+				nextBatchID = row.GUID
+			}
+			continue
+		}
+		pages = append(pages, s.db.tables.getPage(row))
+	}
+	return
+}
+
+// RemovePage marks the given page and removed by setting the deletedAt property.
+func (s PageStore) RemovePage(ctx context.Context, guid string) error {
+	t := time.Now()
+	return s.UpdatePage(ctx, page.Page{
+		GUID:      guid,
+		DeletedAt: &t,
+	})
+}
+
+// RestorePage restores the given removed page, along with its properties, by clearing the deletedAt property.
+// If the page is not removed, a storeerror.NotFound will be returned.
+func (s PageStore) RestorePage(ctx context.Context, guid string) error {
+	if guid == "" {
+		return errors.New("must provide guid to restore the page")
+	}
+	if s.db == nil {
+		return &storeerror.DBNotSetUp{}
+	}
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	index, err := s.db.tables.getRemovedPageIndex(guid)
+	if err != nil {
+		return err
+	}
+	t := time.Now()
+	s.db.tables.pages[index].DeletedAt = nil
+	s.db.tables.pages[index].UpdatedAt = &t
+	return nil
+}
+
+// TransferPage makes the user the page's owner in place of its current owner, whether or not the page is removed.
+// If the page or the user doesn't exist, a storeerror.NotFound will be returned.
+func (s PageStore) TransferPage(ctx context.Context, guid, userID string) error {
+	if guid == "" {
+		return errors.New("must provide guid to transfer the page")
+	}
+	if userID == "" {
+		return errors.New("must provide userID to transfer the page")
+	}
+	if s.db == nil {
+		return &storeerror.DBNotSetUp{}
+	}
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	row, ok := s.db.tables.findPage(guid)
+	if !ok {
+		return &storeerror.NotFound{
+			ID: guid,
+		}
+	}
+	userRowID, ok := s.db.tables.findUser(userID)
+	if !ok {
+		return &storeerror.NotFound{
+			ID: userID,
+		}
+	}
+	var pageOwners []pageOwnerRow
+	for _, owner := range s.db.tables.pageOwners {
+		if owner.PageID == row.ID && (owner.IsOwner || owner.UserID == userRowID) {
+			continue
+		}
+		pageOwners = append(pageOwners, owner)
+	}
+	s.db.tables.pageOwners = append(pageOwners, pageOwnerRow{
+		PageID:  row.ID,
+		UserID:  userRowID,
+		IsOwner: true,
+	})
+	return nil
+}
+
+// PurgePage permanently deletes the given removed page, along with its owners, properties, details, and comments.
+// If the page is not removed, a storeerror.NotFound will be returned.
+func (s PageStore) PurgePage(ctx context.Context, guid string) error {
+	if guid == "" {
+		return errors.New("must provide guid to purge the page")
+	}
+	if s.db == nil {
+		return &storeerror.DBNotSetUp{}
+	}
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	index, err := s.db.tables.getRemovedPageIndex(guid)
+	if err != nil {
+		return err
+	}
+	s.db.tables.purgePages(map[int64]bool{s.db.tables.pages[index].ID: true})
+	return nil
+}
+
+// PurgeRemovedPages permanently deletes every page removed before the given time.
+// Returns the number of pages purged.
+func (s PageStore) PurgeRemovedPages(ctx context.Context, removedBefore time.Time) (int, error) {
+	if s.db == nil {
+		return 0, &storeerror.DBNotSetUp{}
+	}
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	pageIDs := map[int64]bool{}
+	for _, row := range s.db.tables.pages {
+		if row.DeletedAt != nil && row.DeletedAt.Before(removedBefore) {
+			pageIDs[row.ID] = true
+		}
+	}
+	s.db.tables.purgePages(pageIDs)
+	return len(pageIDs), nil
+}
+
+// GetUniquePageGUID returns a guid for the page that is guaranteed to be unique or errors.
+// If the proposedPageGuid is not a zero-value and not unique, it will error.
+func (s PageStore) GetUniquePageGUID(ctx context.Context, proposedPageGUID string) (string, error) {
+	err := guidgen.CheckProposedGUID(proposedPageGUID, "PG", 15)
+	if err != nil {
+		return "", err
+	}
+	if s.db == nil {
+		return "", &storeerror.DBNotSetUp{}
+	}
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+	for retry := 0; ; retry++ {
+		guid := proposedPageGUID
+		if guid == "" {
+			guid = guidgen.GenerateGUID("PG", 15)
+		}
+		if _, ok := s.db.tables.findPage(guid); !ok {
+			return guid, nil
+		}
+		if proposedPageGUID != "" {
+			return "", &storeerror.DupEntry{
+				ID:  proposedPageGUID,
+				Err: errors.Errorf("the proposed guid %v already exists", proposedPageGUID),
+			}
+		}
+		if retry >= guidgen.MaxGUIDRetryAttempts {
+			return "", guidgen.ErrMaxGUIDRetryAttempts
+		}
+	}
+}
+
+// GetPageProperties returns the page's properties.
+func (s PageStore) GetPageProperties(ctx context.Context, pageGUID string) ([]property.Property, error) {
+	if pageGUID == "" {
+		return nil, errors.New("must provide pageGUID to get the page properties")
+	}
+	if s.db == nil {
+		return nil, &storeerror.DBNotSetUp{}
+	}
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+	row, ok := s.db.tables.findPage(pageGUID)
+	if !ok || row.DeletedAt != nil {
+		return nil, nil
+	}
+	return append([]property.Property(nil), s.db.tables.pageProperties[row.ID]...), nil
+}
+
+// ReplacePageProperties replaces the current page's properties with the new properties.
+func (s PageStore) ReplacePageProperties(ctx context.Context, pageGUID string, pageProperties []property.Property) error {
+	if pageGUID == "" {
+		return errors.New("must provide pageGUID to replace the page properties")
+	}
+	if s.db == nil {
+		return &storeerror.DBNotSetUp{}
+	}
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	row, ok := s.db.tables.findPage(pageGUID)
+	if !ok {
+		return errors.Wrapf(&storeerror.NotFound{ID: pageGUID}, "unable to get Page.ID for guid: %v", pageGUID)
+	}
+	var replacements []property.Property
+	for i, pageProperty := range pageProperties {
+		if pageProperty.Key == "" {
+			return errors.Wrap(errors.Errorf("property key at %v must be non-zero value", i), "unable to get Property.ID for the pageProperties")
+		}
+		definition, ok := s.db.tables.findProperty(pageProperty.Key)
+		if !ok {
+			return errors.Wrap(errors.Errorf("unable to find the ID for the property at %v with key %v", i, pageProperty.Key), "unable to get Property.ID for the pageProperties")
+		}
+		pageProperties[i].ID = definition.ID
+		value, err := getStoredPropertyValue(pageProperty)
+		if err != nil {
+			return errors.Wrapf(err, "unable to add %v type page properties", pageProperty.Type)
+		}
+		// values are read back with the type of the property definition, as they are in mysql
+		replacements = append(replacements, property.Property{
+			ID:    definition.ID,
+			Key:   definition.Key,
+			Type:  definition.Type,
+			Value: value,
+		})
+	}
+	s.db.tables.pageProperties[row.ID] = replacements
+	return nil
+}
+
+func getStoredPropertyValue(p property.Property) (interface{}, error) {
+	switch p.Type {
+	case property.TypeNumber:
+		switch value := p.Value.(type) {
+		case float64:
+			return value, nil
+		case float32:
+			return float64(value), nil
+		case int:
+			return float64(value), nil
+		case int64:
+			return float64(value), nil
+		default:
+			return nil, errors.Errorf("value %v for property %v is not a number", p.Value, p.Key)
+		}
+	case property.TypeString:
+		if p.Value == nil {
+			return "", nil
+		}
+		return fmt.Sprint(p.Value), nil
+	default:
+		return nil, errors.Errorf("unsupported page property type for instert: %v", p.Type)
+	}
+}
+
+func (t *tables) findPage(guid string) (pageRow, bool) {
+	for _, row := range t.pages {
+		if row.GUID == guid {
+			return row, true
+		}
+	}
+	return pageRow{}, false
+}
+
+func (t *tables) findProperty(key string) (property.Property, bool) {
+	for _, p := range t.properties {
+		if p.Key == key {
+			return p, true
+		}
+	}
+	return property.Property{}, false
+}
+
+func (t *tables) findUser(guid string) (int64, bool) {
+	for _, u := range t.users {
+		if u.GUID == guid {
+			return u.ID, true
+		}
+	}
+	return 0, false
+}
+
+func (t *tables) hasUser(id int64) bool {
+	for _, u := range t.users {
+		if u.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *tables) checkPageReferences(versionID, pageTemplateID int64) error {
+	if versionID != 0 && t.getVersionGUID(versionID) == "" {
+		return errors.Errorf("unable to find version %v", versionID)
+	}
+	if pageTemplateID != 0 && t.getPageTemplateGUID(pageTemplateID) == "" {
+		return errors.Errorf("unable to find page template %v", pageTemplateID)
+	}
+	return nil
+}
+
+func (t *tables) getVersionGUID(id int64) string {
+	for _, v := range t.versions {
+		if v.ID == id {
+			return v.GUID
+		}
+	}
+	return ""
+}
+
+func (t *tables) getPageTemplateGUID(id int64) string {
+	for _, pt := range t.pageTemplates {
+		if pt.ID == id {
+			return pt.GUID
+		}
+	}
+	return ""
+}
+
+func (t *tables) getPage(row pageRow) page.Page {
+	p := page.Page{
+		ID:             row.ID,
+		GUID:           row.GUID,
+		Title:          row.Title,
+		Summary:        row.Summary,
+		PermissionType: row.PermissionType,
+		CreatedAt:      row.CreatedAt,
+		UpdatedAt:      row.UpdatedAt,
+		DeletedAt:      row.DeletedAt,
+	}
+	p.Version.GUID = t.getVersionGUID(row.VersionID)
+	p.PageTemplate.GUID = t.getPageTemplateGUID(row.PageTemplateID)
+	return p
+}
+
+func (t *tables) canEditPage(guid, userID string) (bool, error) {
+	notAuthorized := &storeerror.NotAuthorized{
+		UserID:  userID,
+		TableID: guid,
+	}
+	row, ok := t.findPage(guid)
+	if !ok {
+		return false, notAuthorized
+	}
+	userRowID, ok := t.findUser(userID)
+	if !ok {
+		return false, notAuthorized
+	}
+	for _, owner := range t.pageOwners {
+		if owner.PageID == row.ID && owner.UserID == userRowID {
+			return owner.IsOwner, nil
+		}
+	}
+	return false, notAuthorized
+}
+
+// getOwnedPages returns the user's pages in the order they were created.
+func (t *tables) getOwnedPages(userID string, removed bool) []pageRow {
+	var rows []pageRow
+	userRowID, ok := t.findUser(userID)
+	if !ok {
+		return rows
+	}
+	owned := map[int64]bool{}
+	for _, owner := range t.pageOwners {
+		if owner.UserID == userRowID {
+			owned[owner.PageID] = true
+		}
+	}
+	for _, row := range t.pages {
+		if owned[row.ID] && (row.DeletedAt != nil) == removed {
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+func (t *tables) updatePage(record page.Page) error {
+	err := t.checkPageReferences(record.Version.ID, record.PageTemplate.ID)
+	if err != nil {
+		return err
+	}
+	for i := range t.pages {
+		row := &t.pages[i]
+		if row.GUID != record.GUID {
+			continue
+		}
+		if record.Title != "" {
+			row.Title = record.Title
+		}
+		if record.Summary != "" {
+			row.Summary = record.Summary
+		}
+		if record.Version.ID != 0 {
+			row.VersionID = record.Version.ID
+		}
+		if record.PermissionType != "" {
+			row.PermissionType = record.PermissionType
+		}
+		if record.PageTemplate.ID != 0 {
+			row.PageTemplateID = record.PageTemplate.ID
+		}
+		if record.DeletedAt != nil {
+			row.DeletedAt = record.DeletedAt
+		}
+		now := time.Now()
+		row.UpdatedAt = &now
+	}
+	return nil
+}
+
+func (t *tables) getRemovedPageIndex(guid string) (int, error) {
+	for i, row := range t.pages {
+		if row.GUID == guid && row.DeletedAt != nil {
+			return i, nil
+		}
+	}
+	return -1, &storeerror.NotFound{
+		ID: guid,
+	}
+}
+
+func (t *tables) purgePages(pageIDs map[int64]bool) {
+	if len(pageIDs) == 0 {
+		return
+	}
+	var pages []pageRow
+	pageGUIDs := map[string]bool{}
+	for _, row := range t.pages {
+		if !pageIDs[row.ID] {
+			pages = append(pages, row)
+		} else {
+			pageGUIDs[row.GUID] = true
+		}
+	}
+	t.pages = pages
+	var comments []comment.Comment
+	for _, c := range t.comments {
+		if !pageGUIDs[c.PageGUID] {
+			comments = append(comments, c)
+		}
+	}
+	t.comments = comments
+	var pageOwners []pageOwnerRow
+	for _, owner := range t.pageOwners {
+		if !pageIDs[owner.PageID] {
+			pageOwners = append(pageOwners, owner)
+		}
+	}
+	t.pageOwners = pageOwners
+	for pageID := range pageIDs {
+		delete(t.pageProperties, pageID)
+		delete(t.pageDetails, pageID)
+	}
+}
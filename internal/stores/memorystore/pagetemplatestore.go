@@ -0,0 +1,41 @@
+package memorystore
+
+import (
+	"context"
+	"errors"
+
+	"github.com/worlve/sp-service/internal/models/pagetemplate"
+	"github.com/worlve/sp-service/internal/stores/storeerror"
+)
+
+// PageTemplateStore is the in-memory store for pagetemplates
+type PageTemplateStore struct {
+	db *DB
+}
+
+// NewPageTemplateStore returns a PageTemplateStore
+func NewPageTemplateStore(memdb *DB) PageTemplateStore {
+	return PageTemplateStore{
+		db: memdb,
+	}
+}
+
+// GetPageTemplate returns the given pagetemplate.
+func (s PageTemplateStore) GetPageTemplate(ctx context.Context, guid string) (pagetemplate.PageTemplate, error) {
+	if guid == "" {
+		return pagetemplate.PageTemplate{}, errors.New("must provide guid to get the pageTemplate")
+	}
+	if s.db == nil {
+		return pagetemplate.PageTemplate{}, &storeerror.DBNotSetUp{}
+	}
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+	for _, pt := range s.db.tables.pageTemplates {
+		if pt.GUID == guid {
+			return pt, nil
+		}
+	}
+	return pagetemplate.PageTemplate{}, &storeerror.NotFound{
+		ID: guid,
+	}
+}
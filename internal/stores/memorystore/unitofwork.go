@@ -0,0 +1,54 @@
+package memorystore
+
+import (
+	"context"
+
+	"github.com/worlve/sp-service/internal/stores/store"
+	"github.com/worlve/sp-service/internal/stores/storeerror"
+)
+
+// UnitOfWork is the in-memory store for running multiple store calls as a single transaction
+type UnitOfWork struct {
+	db *DB
+}
+
+// NewUnitOfWork returns a UnitOfWork
+func NewUnitOfWork(memdb *DB) UnitOfWork {
+	return UnitOfWork{
+		db: memdb,
+	}
+}
+
+// Do runs fn with stores bound to a copy of the db.
+// The copy replaces the db if fn returns nil and is discarded otherwise.
+// Other store calls wait until fn returns, so units of work are serialized.
+func (u UnitOfWork) Do(ctx context.Context, fn func(stores store.Stores) error) error {
+	if u.db == nil {
+		return &storeerror.DBNotSetUp{}
+	}
+	u.db.mu.Lock()
+	defer u.db.mu.Unlock()
+	tx := &DB{
+		tables: u.db.tables.clone(),
+	}
+	err := fn(newStores(tx))
+	if err != nil {
+		return err
+	}
+	u.db.tables = tx.tables
+	return nil
+}
+
+func newStores(db *DB) store.Stores {
+	return store.Stores{
+		PageStore:         PageStore{db: db},
+		PageDetailStore:   PageDetailStore{db: db},
+		PageTemplateStore: PageTemplateStore{db: db},
+		UserStore:         UserStore{db: db},
+		VersionStore:      VersionStore{db: db},
+		WebhookStore:      WebhookStore{db: db},
+		EventStore:        EventStore{db: db},
+		AuditStore:        AuditStore{db: db},
+		CommentStore:      CommentStore{db: db},
+	}
+}
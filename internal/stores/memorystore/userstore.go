@@ -0,0 +1,97 @@
+package memorystore
+
+import (
+	"context"
+
+	"github.com/worlve/sp-service/internal/models/appuser"
+	"github.com/worlve/sp-service/internal/stores/storeerror"
+	"github.com/worlve/sp-service/internal/util/guidgen"
+	"github.com/pkg/errors"
+)
+
+// UserStore is the in-memory store for users
+type UserStore struct {
+	db *DB
+}
+
+// NewUserStore returns a UserStore
+func NewUserStore(memdb *DB) UserStore {
+	return UserStore{
+		db: memdb,
+	}
+}
+
+// GetUser returns the given appuser.
+func (s UserStore) GetUser(ctx context.Context, guid string) (appuser.User, error) {
+	if guid == "" {
+		return appuser.User{}, errors.New("must provide guid to get the user")
+	}
+	if s.db == nil {
+		return appuser.User{}, &storeerror.DBNotSetUp{}
+	}
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+	for _, u := range s.db.tables.users {
+		if u.GUID == guid {
+			return u, nil
+		}
+	}
+	return appuser.User{}, &storeerror.NotFound{
+		ID: guid,
+	}
+}
+
+// CreateUser creates a new user.
+func (s UserStore) CreateUser(ctx context.Context, record appuser.User) (appuser.User, error) {
+	if record.GUID == "" {
+		return record, errors.New("must provide record.GUID to create the user")
+	}
+	if record.Email == "" {
+		return record, errors.New("must provide record.Email to create the user")
+	}
+	if s.db == nil {
+		return record, &storeerror.DBNotSetUp{}
+	}
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	if _, ok := s.db.tables.findUser(record.GUID); ok {
+		return record, &storeerror.DupEntry{
+			ID: record.GUID,
+		}
+	}
+	record.ID = s.db.tables.nextID("User")
+	s.db.tables.users = append(s.db.tables.users, record)
+	return record, nil
+}
+
+// GetUniqueUserGUID returns a guid for the user that is guaranteed to be unique or errors.
+// If the proposedUserGUID is not a zero-value and not unique, it will error.
+func (s UserStore) GetUniqueUserGUID(ctx context.Context, proposedUserGUID string) (string, error) {
+	err := guidgen.CheckProposedGUID(proposedUserGUID, "UR", 15)
+	if err != nil {
+		return "", err
+	}
+	if s.db == nil {
+		return "", &storeerror.DBNotSetUp{}
+	}
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+	for retry := 0; ; retry++ {
+		guid := proposedUserGUID
+		if guid == "" {
+			guid = guidgen.GenerateGUID("UR", 15)
+		}
+		if _, ok := s.db.tables.findUser(guid); !ok {
+			return guid, nil
+		}
+		if proposedUserGUID != "" {
+			return "", &storeerror.DupEntry{
+				ID:  proposedUserGUID,
+				Err: errors.Errorf("the proposed guid %v already exists", proposedUserGUID),
+			}
+		}
+		if retry >= guidgen.MaxGUIDRetryAttempts {
+			return "", guidgen.ErrMaxGUIDRetryAttempts
+		}
+	}
+}
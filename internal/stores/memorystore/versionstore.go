@@ -0,0 +1,41 @@
+package memorystore
+
+import (
+	"context"
+	"errors"
+
+	"github.com/worlve/sp-service/internal/models/version"
+	"github.com/worlve/sp-service/internal/stores/storeerror"
+)
+
+// VersionStore is the in-memory store for versions
+type VersionStore struct {
+	db *DB
+}
+
+// NewVersionStore returns a VersionStore
+func NewVersionStore(memdb *DB) VersionStore {
+	return VersionStore{
+		db: memdb,
+	}
+}
+
+// GetVersion returns the given version.
+func (s VersionStore) GetVersion(ctx context.Context, guid string) (version.Version, error) {
+	if guid == "" {
+		return version.Version{}, errors.New("must provide guid to get the version")
+	}
+	if s.db == nil {
+		return version.Version{}, &storeerror.DBNotSetUp{}
+	}
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+	for _, v := range s.db.tables.versions {
+		if v.GUID == guid {
+			return version.Version{ID: v.ID, GUID: v.GUID, Name: v.Name}, nil
+		}
+	}
+	return version.Version{}, &storeerror.NotFound{
+		ID: guid,
+	}
+}
@@ -0,0 +1,279 @@
+package memorystore
+
+import (
+	"context"
+	"time"
+
+	"github.com/worlve/sp-service/internal/models/webhook"
+	"github.com/worlve/sp-service/internal/stores/storeerror"
+	"github.com/pkg/errors"
+)
+
+// WebhookStore is the in-memory store for webhooks and their deliveries
+type WebhookStore struct {
+	db *DB
+}
+
+// NewWebhookStore returns a WebhookStore
+func NewWebhookStore(memdb *DB) WebhookStore {
+	return WebhookStore{
+		db: memdb,
+	}
+}
+
+// CreateWebhook creates a new webhook for record.UserID.
+func (s WebhookStore) CreateWebhook(ctx context.Context, record webhook.Webhook) (webhook.Webhook, error) {
+	if record.GUID == "" {
+		return record, errors.New("must provide record.GUID to create the webhook")
+	}
+	if record.URL == "" {
+		return record, errors.New("must provide record.URL to create the webhook")
+	}
+	if len(record.Events) == 0 {
+		return record, errors.New("must provide record.Events to create the webhook")
+	}
+	if s.db == nil {
+		return record, &storeerror.DBNotSetUp{}
+	}
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	if _, ok := s.db.tables.findUser(record.UserID); !ok {
+		return record, &storeerror.NotFound{
+			ID: record.UserID,
+		}
+	}
+	if _, ok := s.db.tables.findWebhook(record.GUID); ok {
+		return record, &storeerror.DupEntry{
+			ID: record.GUID,
+		}
+	}
+	t := time.Now()
+	record.ID = s.db.tables.nextID("Webhook")
+	record.CreatedAt = &t
+	s.db.tables.webhooks = append(s.db.tables.webhooks, record)
+	return record, nil
+}
+
+// GetWebhook returns the given webhook, including its secret.
+func (s WebhookStore) GetWebhook(ctx context.Context, guid string) (webhook.Webhook, error) {
+	if guid == "" {
+		return webhook.Webhook{}, errors.New("must provide guid to get the webhook")
+	}
+	if s.db == nil {
+		return webhook.Webhook{}, &storeerror.DBNotSetUp{}
+	}
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+	w, ok := s.db.tables.findWebhook(guid)
+	if !ok {
+		return webhook.Webhook{}, &storeerror.NotFound{
+			ID: guid,
+		}
+	}
+	return w, nil
+}
+
+// GetWebhooks returns the user's webhooks in the order they were created, including their secrets.
+func (s WebhookStore) GetWebhooks(ctx context.Context, userID string) ([]webhook.Webhook, error) {
+	if userID == "" {
+		return nil, errors.New("must provide userID to get webhooks")
+	}
+	if s.db == nil {
+		return nil, &storeerror.DBNotSetUp{}
+	}
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+	webhooks := make([]webhook.Webhook, 0)
+	for _, w := range s.db.tables.webhooks {
+		if w.UserID == userID {
+			webhooks = append(webhooks, w)
+		}
+	}
+	return webhooks, nil
+}
+
+// RemoveWebhook permanently deletes the given webhook, along with its deliveries.
+func (s WebhookStore) RemoveWebhook(ctx context.Context, guid string) error {
+	if guid == "" {
+		return errors.New("must provide guid to remove the webhook")
+	}
+	if s.db == nil {
+		return &storeerror.DBNotSetUp{}
+	}
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	if _, ok := s.db.tables.findWebhook(guid); !ok {
+		return &storeerror.NotFound{
+			ID: guid,
+		}
+	}
+	var webhooks []webhook.Webhook
+	for _, w := range s.db.tables.webhooks {
+		if w.GUID != guid {
+			webhooks = append(webhooks, w)
+		}
+	}
+	var deliveries []webhook.Delivery
+	for _, d := range s.db.tables.deliveries {
+		if d.WebhookGUID != guid {
+			deliveries = append(deliveries, d)
+		}
+	}
+	s.db.tables.webhooks = webhooks
+	s.db.tables.deliveries = deliveries
+	return nil
+}
+
+// CreateDeliveries adds the deliveries to the outbox.
+func (s WebhookStore) CreateDeliveries(ctx context.Context, records []webhook.Delivery) error {
+	if s.db == nil {
+		return &storeerror.DBNotSetUp{}
+	}
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	for _, record := range records {
+		if record.GUID == "" {
+			return errors.New("must provide record.GUID to create the delivery")
+		}
+		if _, ok := s.db.tables.findWebhook(record.WebhookGUID); !ok {
+			return &storeerror.NotFound{
+				ID: record.WebhookGUID,
+			}
+		}
+	}
+	t := time.Now()
+	for _, record := range records {
+		record.ID = s.db.tables.nextID("WebhookDelivery")
+		record.CreatedAt = &t
+		s.db.tables.deliveries = append(s.db.tables.deliveries, record)
+	}
+	return nil
+}
+
+// GetDueDeliveries returns up to limit pending deliveries whose next attempt is due by dueBy, oldest first.
+func (s WebhookStore) GetDueDeliveries(ctx context.Context, dueBy time.Time, limit int) ([]webhook.Delivery, error) {
+	if s.db == nil {
+		return nil, &storeerror.DBNotSetUp{}
+	}
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+	deliveries := make([]webhook.Delivery, 0)
+	for _, d := range s.db.tables.deliveries {
+		if len(deliveries) >= limit {
+			break
+		}
+		if d.Status == webhook.DeliveryPending && d.NextAttemptAt != nil && !d.NextAttemptAt.After(dueBy) {
+			deliveries = append(deliveries, d)
+		}
+	}
+	return deliveries, nil
+}
+
+// ClaimDelivery pushes the delivery's next attempt back to leaseUntil, so no one else attempts it in the meantime.
+// Returns false if the delivery was already claimed or changed since record was read.
+func (s WebhookStore) ClaimDelivery(ctx context.Context, record webhook.Delivery, leaseUntil time.Time) (bool, error) {
+	if record.GUID == "" {
+		return false, errors.New("must provide record.GUID to claim the delivery")
+	}
+	if s.db == nil {
+		return false, &storeerror.DBNotSetUp{}
+	}
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	index, ok := s.db.tables.findDeliveryIndex(record.GUID)
+	if !ok {
+		return false, nil
+	}
+	d := &s.db.tables.deliveries[index]
+	if d.Status != webhook.DeliveryPending || d.Attempts != record.Attempts || d.NextAttemptAt == nil || record.NextAttemptAt == nil || !d.NextAttemptAt.Equal(*record.NextAttemptAt) {
+		return false, nil
+	}
+	d.NextAttemptAt = &leaseUntil
+	return true, nil
+}
+
+// UpdateDelivery records the outcome of an attempt to send the delivery.
+func (s WebhookStore) UpdateDelivery(ctx context.Context, record webhook.Delivery) error {
+	if record.GUID == "" {
+		return errors.New("must provide record.GUID to update the delivery")
+	}
+	if s.db == nil {
+		return &storeerror.DBNotSetUp{}
+	}
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	index, ok := s.db.tables.findDeliveryIndex(record.GUID)
+	if !ok {
+		return &storeerror.NotFound{
+			ID: record.GUID,
+		}
+	}
+	d := &s.db.tables.deliveries[index]
+	d.Status = record.Status
+	d.Attempts = record.Attempts
+	d.ResponseStatus = record.ResponseStatus
+	d.LastError = record.LastError
+	d.NextAttemptAt = record.NextAttemptAt
+	d.LastAttemptAt = record.LastAttemptAt
+	d.DeliveredAt = record.DeliveredAt
+	return nil
+}
+
+// GetDeliveries returns a batch of the webhook's deliveries, newest first, based on the nextBatchId
+func (s WebhookStore) GetDeliveries(ctx context.Context, webhookGUID, thisBatchID string, limit int) ([]webhook.Delivery, int, string, error) {
+	if webhookGUID == "" {
+		return nil, 0, "", errors.New("must provide webhookGUID to get deliveries")
+	}
+	if s.db == nil {
+		return nil, 0, "", &storeerror.DBNotSetUp{}
+	}
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+	var all []webhook.Delivery
+	for i := len(s.db.tables.deliveries) - 1; i >= 0; i-- {
+		if d := s.db.tables.deliveries[i]; d.WebhookGUID == webhookGUID {
+			all = append(all, d)
+		}
+	}
+	start := 0
+	if thisBatchID != "" {
+		start = -1
+		for i, d := range all {
+			if d.GUID == thisBatchID {
+				start = i
+				break
+			}
+		}
+		if start < 0 {
+			return nil, 0, "", errors.Wrapf(&storeerror.NotFound{ID: thisBatchID}, "unable to use thisBatchID: %v", thisBatchID)
+		}
+	}
+	deliveries := make([]webhook.Delivery, 0, limit)
+	nextBatchID := ""
+	for _, d := range all[start:] {
+		if len(deliveries) == limit {
+			nextBatchID = d.GUID
+			break
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, len(all), nextBatchID, nil
+}
+
+func (t *tables) findWebhook(guid string) (webhook.Webhook, bool) {
+	for _, w := range t.webhooks {
+		if w.GUID == guid {
+			return w, true
+		}
+	}
+	return webhook.Webhook{}, false
+}
+
+func (t *tables) findDeliveryIndex(guid string) (int, bool) {
+	for i, d := range t.deliveries {
+		if d.GUID == guid {
+			return i, true
+		}
+	}
+	return -1, false
+}
@@ -0,0 +1,190 @@
+package mysqlstore
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/worlve/sp-service/internal/models/audit"
+	"github.com/worlve/sp-service/internal/stores/storeerror"
+	"github.com/worlve/sp-service/internal/util/wrapsql"
+	"github.com/pkg/errors"
+)
+
+// AuditStore is the mysql for the audit log
+type AuditStore struct {
+	db wrapsql.DB
+}
+
+// NewAuditStore returns an AuditStore
+func NewAuditStore(mysqldb *sql.DB) AuditStore {
+	return AuditStore{
+		db: mysqldb,
+	}
+}
+
+var auditEntrySelectors = []string{"ID", "guid", "actorType", "userGuid", "method", "route", "targetGuid", "pageGuid", "status", "beforeSummary", "afterSummary", "requestId", "createdAt"}
+
+// CreateEntries adds the entries to the audit log.
+func (s AuditStore) CreateEntries(ctx context.Context, records []audit.Entry) error {
+	for _, record := range records {
+		if record.GUID == "" {
+			return errors.New("must provide record.GUID to create the audit entry")
+		}
+	}
+	if s.db == nil {
+		return &storeerror.DBNotSetUp{}
+	}
+	t := time.Now()
+	return wrapsql.WithinTransaction(ctx, s.db, func(tx wrapsql.DB) error {
+		for _, record := range records {
+			_, err := wrapsql.ExecSingleInsert(ctx, tx, wrapsql.InsertQuery{
+				IntoTable: "AuditEntry",
+				InjectedValues: wrapsql.InjectedValues{
+					"guid":          record.GUID,
+					"actorType":     record.ActorType,
+					"userGuid":      record.UserID,
+					"method":        record.Method,
+					"route":         record.Route,
+					"targetGuid":    record.TargetID,
+					"pageGuid":      record.PageID,
+					"status":        record.Status,
+					"beforeSummary": string(record.Before),
+					"afterSummary":  string(record.After),
+					"requestId":     record.RequestID,
+					"createdAt":     &t,
+				},
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetEntries returns a batch of the entries within the filter, newest first, based on the nextBatchId
+func (s AuditStore) GetEntries(ctx context.Context, filter audit.Filter, thisBatchID string, limit int) (entries []audit.Entry, total int, nextBatchID string, returnErr error) {
+	if s.db == nil {
+		returnErr = &storeerror.DBNotSetUp{}
+		return
+	}
+	whereOperations, args := getAuditFilterOperations(filter)
+	countOperations, countArgs := whereOperations, args
+	if thisBatchID != "" {
+		thisEntryID, err := s.getEntryID(ctx, thisBatchID)
+		if err != nil {
+			returnErr = errors.Wrapf(err, "unable to use thisBatchID: %v", thisBatchID)
+			return
+		}
+		whereOperations = append(whereOperations, wrapsql.WhereOperation{LeftSide: "ID", Operator: "<= ?"})
+		args = append(args, thisEntryID)
+	}
+	statement := wrapsql.SelectStatement{
+		Selectors: auditEntrySelectors,
+		FromTable: "AuditEntry",
+		WhereClause: wrapsql.WhereClause{
+			Operator:        "AND",
+			WhereOperations: whereOperations,
+		},
+		OrderClause: wrapsql.OrderClause{
+			Column: "ID",
+			SortBy: "DESC",
+		},
+		Limit: limit + 1, // plus one so we can get an extra record to determine the nextBatchID
+	}
+	rows, err := wrapsql.Select(ctx, s.db, statement, args...)
+	entries, returnErr = scanAuditEntries(rows, err)
+	if returnErr != nil {
+		return
+	}
+	if len(entries) > limit {
+		nextBatchID = entries[len(entries)-1].GUID
+		entries = entries[:len(entries)-1]
+	}
+	countStatement := wrapsql.SelectStatement{
+		Selectors: []string{"COUNT(1)"},
+		FromTable: "AuditEntry",
+		WhereClause: wrapsql.WhereClause{
+			Operator:        "AND",
+			WhereOperations: countOperations,
+		},
+	}
+	rows, err = wrapsql.Select(ctx, s.db, countStatement, countArgs...)
+	returnErr = wrapsql.GetSingleRow("AuditEntry", rows, err, &total)
+	return
+}
+
+func getAuditFilterOperations(filter audit.Filter) ([]wrapsql.WhereOperation, []interface{}) {
+	var whereOperations []wrapsql.WhereOperation
+	var args []interface{}
+	if filter.UserID != "" {
+		whereOperations = append(whereOperations, wrapsql.WhereOperation{LeftSide: "userGuid", Operator: "= ?"})
+		args = append(args, filter.UserID)
+	}
+	if filter.PageID != "" {
+		whereOperations = append(whereOperations, wrapsql.WhereOperation{LeftSide: "pageGuid", Operator: "= ?"})
+		args = append(args, filter.PageID)
+	}
+	if filter.TargetID != "" {
+		whereOperations = append(whereOperations, wrapsql.WhereOperation{LeftSide: "targetGuid", Operator: "= ?"})
+		args = append(args, filter.TargetID)
+	}
+	if filter.ActorType != "" {
+		whereOperations = append(whereOperations, wrapsql.WhereOperation{LeftSide: "actorType", Operator: "= ?"})
+		args = append(args, filter.ActorType)
+	}
+	if filter.Since != nil {
+		whereOperations = append(whereOperations, wrapsql.WhereOperation{LeftSide: "createdAt", Operator: ">= ?"})
+		args = append(args, filter.Since)
+	}
+	if filter.Until != nil {
+		whereOperations = append(whereOperations, wrapsql.WhereOperation{LeftSide: "createdAt", Operator: "< ?"})
+		args = append(args, filter.Until)
+	}
+	return whereOperations, args
+}
+
+func scanAuditEntries(rows *sql.Rows, queryErr error) (entries []audit.Entry, returnErr error) {
+	if queryErr != nil {
+		returnErr = queryErr
+		return
+	}
+	defer rows.Close()
+	entries = make([]audit.Entry, 0)
+	for rows.Next() {
+		var e audit.Entry
+		var before, after string
+		err := rows.Scan(&e.ID, &e.GUID, &e.ActorType, &e.UserID, &e.Method, &e.Route, &e.TargetID, &e.PageID, &e.Status, &before, &after, &e.RequestID, &e.CreatedAt)
+		if err != nil {
+			returnErr = err
+			return
+		}
+		if before != "" {
+			e.Before = []byte(before)
+		}
+		if after != "" {
+			e.After = []byte(after)
+		}
+		entries = append(entries, e)
+	}
+	returnErr = rows.Err()
+	return
+}
+
+func (s AuditStore) getEntryID(ctx context.Context, guid string) (int64, error) {
+	statement := wrapsql.SelectStatement{
+		Selectors: []string{"ID"},
+		FromTable: "AuditEntry",
+		WhereClause: wrapsql.WhereClause{
+			Operator: "AND", WhereOperations: []wrapsql.WhereOperation{
+				{LeftSide: "guid", Operator: "= ?"},
+			},
+		},
+		Limit: 1,
+	}
+	rows, err := wrapsql.Select(ctx, s.db, statement, guid)
+	var id int64
+	err = wrapsql.GetSingleRow(guid, rows, err, &id)
+	return id, err
+}
@@ -0,0 +1,425 @@
+package mysqlstore
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/worlve/sp-service/internal/models/comment"
+	"github.com/worlve/sp-service/internal/stores/storeerror"
+	"github.com/worlve/sp-service/internal/util/wrapsql"
+	"github.com/pkg/errors"
+)
+
+// CommentStore is the mysql for page comments
+type CommentStore struct {
+	db wrapsql.DB
+}
+
+// NewCommentStore returns a CommentStore
+func NewCommentStore(mysqldb *sql.DB) CommentStore {
+	return CommentStore{
+		db: mysqldb,
+	}
+}
+
+var commentSelectors = []string{"Comment.ID", "Comment.guid", "Page.guid", "Comment.threadGuid", "User.guid", "Comment.detailGuid", "Comment.partitionGuid", "Comment.body", "Comment.mentions", "Comment.resolvedAt", "Comment.resolvedByGuid", "Comment.createdAt", "Comment.updatedAt"}
+
+var commentJoinClauses = []wrapsql.JoinClause{
+	{JoinTable: "Page", On: wrapsql.OnClause{LeftSide: "Comment.Page_ID", RightSide: "Page.ID"}},
+	{JoinTable: "User", On: wrapsql.OnClause{LeftSide: "Comment.User_ID", RightSide: "User.ID"}},
+}
+
+// CreateComment creates a new comment on record.PageGUID by record.UserID, along with its mentions.
+func (s CommentStore) CreateComment(ctx context.Context, record comment.Comment) (comment.Comment, error) {
+	if record.GUID == "" {
+		return record, errors.New("must provide record.GUID to create the comment")
+	}
+	if record.Body == "" {
+		return record, errors.New("must provide record.Body to create the comment")
+	}
+	if s.db == nil {
+		return record, &storeerror.DBNotSetUp{}
+	}
+	if record.Mentions == nil {
+		record.Mentions = []string{}
+	}
+	err := wrapsql.WithinTransaction(ctx, s.db, func(tx wrapsql.DB) error {
+		pageID, err := PageStore{db: tx}.getPageID(ctx, record.PageGUID)
+		if err != nil {
+			return err
+		}
+		u, err := UserStore{db: tx}.GetUser(ctx, record.UserID)
+		if err != nil {
+			return err
+		}
+		detailGUID, partitionGUID := "", ""
+		if record.Anchor != nil {
+			detailGUID, partitionGUID = record.Anchor.DetailID, record.Anchor.PartitionID
+		}
+		t := time.Now()
+		record.CreatedAt = &t
+		record.UpdatedAt = &t
+		id, err := wrapsql.ExecSingleInsert(ctx, tx, wrapsql.InsertQuery{
+			IntoTable: "Comment",
+			InjectedValues: wrapsql.InjectedValues{
+				"Page_ID":        pageID,
+				"User_ID":        u.ID,
+				"guid":           record.GUID,
+				"threadGuid":     record.ThreadGUID,
+				"detailGuid":     detailGUID,
+				"partitionGuid":  partitionGUID,
+				"body":           record.Body,
+				"mentions":       strings.Join(record.Mentions, ","),
+				"resolvedAt":     record.ResolvedAt,
+				"resolvedByGuid": record.ResolvedBy,
+				"createdAt":      record.CreatedAt,
+				"updatedAt":      record.UpdatedAt,
+			},
+		})
+		if err != nil {
+			return err
+		}
+		record.ID = id
+		return s.createMentions(ctx, tx, id, pageID, record.Mentions)
+	})
+	return record, err
+}
+
+// createMentions indexes the comment's mentions so they can be looked up by user.
+func (s CommentStore) createMentions(ctx context.Context, tx wrapsql.DB, commentID, pageID int64, mentions []string) error {
+	for _, userID := range mentions {
+		_, err := wrapsql.ExecSingleInsert(ctx, tx, wrapsql.InsertQuery{
+			IntoTable: "CommentMention",
+			InjectedValues: wrapsql.InjectedValues{
+				"Comment_ID": commentID,
+				"Page_ID":    pageID,
+				"userGuid":   userID,
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetComment returns the given comment.
+func (s CommentStore) GetComment(ctx context.Context, guid string) (comment.Comment, error) {
+	if guid == "" {
+		return comment.Comment{}, errors.New("must provide guid to get the comment")
+	}
+	if s.db == nil {
+		return comment.Comment{}, &storeerror.DBNotSetUp{}
+	}
+	statement := wrapsql.SelectStatement{
+		Selectors:   commentSelectors,
+		FromTable:   "Comment",
+		JoinClauses: commentJoinClauses,
+		WhereClause: wrapsql.WhereClause{
+			Operator: "AND", WhereOperations: []wrapsql.WhereOperation{
+				{LeftSide: "Comment.guid", Operator: "= ?"},
+			},
+		},
+		Limit: 1,
+	}
+	rows, err := wrapsql.Select(ctx, s.db, statement, guid)
+	comments, err := scanComments(rows, err)
+	if err != nil {
+		return comment.Comment{}, err
+	}
+	if len(comments) == 0 {
+		return comment.Comment{}, &storeerror.NotFound{
+			ID: guid,
+		}
+	}
+	return comments[0], nil
+}
+
+// GetThreads returns a batch of the page's threads within the filter, newest first, based on the nextBatchId
+func (s CommentStore) GetThreads(ctx context.Context, pageGUID string, filter comment.Filter, thisBatchID string, limit int) ([]comment.Comment, int, string, error) {
+	if pageGUID == "" {
+		return nil, 0, "", errors.New("must provide pageGUID to get threads")
+	}
+	whereOperations := []wrapsql.WhereOperation{
+		{LeftSide: "Page.guid", Operator: "= ?"},
+		{LeftSide: "Comment.threadGuid", Operator: "= ''"},
+	}
+	args := []interface{}{pageGUID}
+	if filter.DetailID != "" {
+		whereOperations = append(whereOperations, wrapsql.WhereOperation{LeftSide: "Comment.detailGuid", Operator: "= ?"})
+		args = append(args, filter.DetailID)
+	}
+	if filter.Resolved != nil && *filter.Resolved {
+		whereOperations = append(whereOperations, wrapsql.WhereOperation{LeftSide: "Comment.resolvedAt", Operator: "IS NOT NULL"})
+	}
+	if filter.Resolved != nil && !*filter.Resolved {
+		whereOperations = append(whereOperations, wrapsql.WhereOperation{LeftSide: "Comment.resolvedAt", Operator: "IS NULL"})
+	}
+	return s.getBatch(ctx, commentJoinClauses, whereOperations, args, thisBatchID, limit, "DESC")
+}
+
+// GetReplies returns a batch of the thread's replies, oldest first, based on the nextBatchId
+func (s CommentStore) GetReplies(ctx context.Context, threadGUID string, thisBatchID string, limit int) ([]comment.Comment, int, string, error) {
+	if threadGUID == "" {
+		return nil, 0, "", errors.New("must provide threadGUID to get replies")
+	}
+	whereOperations := []wrapsql.WhereOperation{
+		{LeftSide: "Comment.threadGuid", Operator: "= ?"},
+	}
+	return s.getBatch(ctx, commentJoinClauses, whereOperations, []interface{}{threadGUID}, thisBatchID, limit, "ASC")
+}
+
+// GetMentions returns a batch of the comments that mention the user, newest first, based on the nextBatchId
+func (s CommentStore) GetMentions(ctx context.Context, userID string, thisBatchID string, limit int) ([]comment.Comment, int, string, error) {
+	if userID == "" {
+		return nil, 0, "", errors.New("must provide userID to get mentions")
+	}
+	joinClauses := append([]wrapsql.JoinClause{
+		{JoinTable: "CommentMention", On: wrapsql.OnClause{LeftSide: "CommentMention.Comment_ID", RightSide: "Comment.ID"}},
+	}, commentJoinClauses...)
+	whereOperations := []wrapsql.WhereOperation{
+		{LeftSide: "CommentMention.userGuid", Operator: "= ?"},
+	}
+	return s.getBatch(ctx, joinClauses, whereOperations, []interface{}{userID}, thisBatchID, limit, "DESC")
+}
+
+func (s CommentStore) getBatch(ctx context.Context, joinClauses []wrapsql.JoinClause, whereOperations []wrapsql.WhereOperation, args []interface{}, thisBatchID string, limit int, sortBy string) (comments []comment.Comment, total int, nextBatchID string, returnErr error) {
+	if s.db == nil {
+		returnErr = &storeerror.DBNotSetUp{}
+		return
+	}
+	countOperations, countArgs := whereOperations, args
+	if thisBatchID != "" {
+		thisCommentID, err := s.getCommentID(ctx, thisBatchID)
+		if err != nil {
+			returnErr = errors.Wrapf(err, "unable to use thisBatchID: %v", thisBatchID)
+			return
+		}
+		operator := "<= ?"
+		if sortBy == "ASC" {
+			operator = ">= ?"
+		}
+		whereOperations = append(whereOperations, wrapsql.WhereOperation{LeftSide: "Comment.ID", Operator: operator})
+		args = append(args, thisCommentID)
+	}
+	statement := wrapsql.SelectStatement{
+		Selectors:   commentSelectors,
+		FromTable:   "Comment",
+		JoinClauses: joinClauses,
+		WhereClause: wrapsql.WhereClause{
+			Operator:        "AND",
+			WhereOperations: whereOperations,
+		},
+		OrderClause: wrapsql.OrderClause{
+			Column: "Comment.ID",
+			SortBy: sortBy,
+		},
+		Limit: limit + 1, // plus one so we can get an extra record to determine the nextBatchID
+	}
+	rows, err := wrapsql.Select(ctx, s.db, statement, args...)
+	comments, returnErr = scanComments(rows, err)
+	if returnErr != nil {
+		return
+	}
+	if len(comments) > limit {
+		nextBatchID = comments[len(comments)-1].GUID
+		comments = comments[:len(comments)-1]
+	}
+	countStatement := wrapsql.SelectStatement{
+		Selectors:   []string{"COUNT(1)"},
+		FromTable:   "Comment",
+		JoinClauses: joinClauses,
+		WhereClause: wrapsql.WhereClause{
+			Operator:        "AND",
+			WhereOperations: countOperations,
+		},
+	}
+	rows, err = wrapsql.Select(ctx, s.db, countStatement, countArgs...)
+	returnErr = wrapsql.GetSingleRow("Comment", rows, err, &total)
+	return
+}
+
+func scanComments(rows *sql.Rows, queryErr error) (comments []comment.Comment, returnErr error) {
+	if queryErr != nil {
+		returnErr = queryErr
+		return
+	}
+	defer rows.Close()
+	comments = make([]comment.Comment, 0)
+	for rows.Next() {
+		var c comment.Comment
+		var detailGUID, partitionGUID, mentions string
+		err := rows.Scan(&c.ID, &c.GUID, &c.PageGUID, &c.ThreadGUID, &c.UserID, &detailGUID, &partitionGUID, &c.Body, &mentions, &c.ResolvedAt, &c.ResolvedBy, &c.CreatedAt, &c.UpdatedAt)
+		if err != nil {
+			returnErr = err
+			return
+		}
+		if detailGUID != "" {
+			c.Anchor = &comment.Anchor{DetailID: detailGUID, PartitionID: partitionGUID}
+		}
+		c.Mentions = []string{}
+		if mentions != "" {
+			c.Mentions = strings.Split(mentions, ",")
+		}
+		comments = append(comments, c)
+	}
+	returnErr = rows.Err()
+	return
+}
+
+func (s CommentStore) getCommentID(ctx context.Context, guid string) (int64, error) {
+	statement := wrapsql.SelectStatement{
+		Selectors: []string{"ID"},
+		FromTable: "Comment",
+		WhereClause: wrapsql.WhereClause{
+			Operator: "AND", WhereOperations: []wrapsql.WhereOperation{
+				{LeftSide: "guid", Operator: "= ?"},
+			},
+		},
+		Limit: 1,
+	}
+	rows, err := wrapsql.Select(ctx, s.db, statement, guid)
+	var id int64
+	err = wrapsql.GetSingleRow(guid, rows, err, &id)
+	return id, err
+}
+
+// UpdateComment sets the comment's body, mentions, and whether it's resolved.
+func (s CommentStore) UpdateComment(ctx context.Context, record comment.Comment) (comment.Comment, error) {
+	if record.GUID == "" {
+		return record, errors.New("must provide record.GUID to update the comment")
+	}
+	if record.Body == "" {
+		return record, errors.New("must provide record.Body to update the comment")
+	}
+	if s.db == nil {
+		return record, &storeerror.DBNotSetUp{}
+	}
+	var updated comment.Comment
+	err := wrapsql.WithinTransaction(ctx, s.db, func(tx wrapsql.DB) error {
+		txStore := CommentStore{db: tx}
+		c, err := txStore.GetComment(ctx, record.GUID)
+		if err != nil {
+			return err
+		}
+		pageID, err := PageStore{db: tx}.getPageID(ctx, c.PageGUID)
+		if err != nil {
+			return err
+		}
+		t := time.Now()
+		err = wrapsql.ExecSingleUpdate(ctx, tx, wrapsql.UpdateQuery{
+			UpdateTable: "Comment",
+			InjectedValues: wrapsql.InjectedValues{
+				"body":           record.Body,
+				"mentions":       strings.Join(record.Mentions, ","),
+				"resolvedAt":     record.ResolvedAt,
+				"resolvedByGuid": record.ResolvedBy,
+				"updatedAt":      &t,
+			},
+			WhereClause: wrapsql.WhereClause{
+				Operator: "AND", WhereOperations: []wrapsql.WhereOperation{
+					{LeftSide: "ID", Operator: "= ?"},
+				},
+			},
+		}, c.ID)
+		if err != nil {
+			return err
+		}
+		err = wrapsql.ExecDelete(ctx, tx, wrapsql.DeleteQuery{
+			FromTable: "CommentMention",
+			WhereClause: wrapsql.WhereClause{
+				Operator: "AND", WhereOperations: []wrapsql.WhereOperation{
+					{LeftSide: "Comment_ID", Operator: "= ?"},
+				},
+			},
+		}, c.ID)
+		if err != nil {
+			return errors.Wrap(err, "unable to delete from CommentMention")
+		}
+		err = txStore.createMentions(ctx, tx, c.ID, pageID, record.Mentions)
+		if err != nil {
+			return err
+		}
+		updated, err = txStore.GetComment(ctx, record.GUID)
+		return err
+	})
+	return updated, err
+}
+
+// RemoveComment permanently deletes the given comment, along with its replies if it starts a thread.
+func (s CommentStore) RemoveComment(ctx context.Context, guid string) error {
+	if guid == "" {
+		return errors.New("must provide guid to remove the comment")
+	}
+	if s.db == nil {
+		return &storeerror.DBNotSetUp{}
+	}
+	return wrapsql.WithinTransaction(ctx, s.db, func(tx wrapsql.DB) error {
+		txStore := CommentStore{db: tx}
+		commentID, err := txStore.getCommentID(ctx, guid)
+		if err != nil {
+			return err
+		}
+		commentIDs, err := txStore.getReplyIDs(ctx, guid)
+		if err != nil {
+			return err
+		}
+		commentIDs = append(commentIDs, commentID)
+		var args []interface{}
+		for _, commentID := range commentIDs {
+			args = append(args, commentID)
+		}
+		tables := []struct {
+			name   string
+			column string
+		}{
+			{name: "CommentMention", column: "Comment_ID"},
+			{name: "Comment", column: "ID"},
+		}
+		for _, table := range tables {
+			err = wrapsql.ExecDelete(ctx, tx, wrapsql.DeleteQuery{
+				FromTable: table.name,
+				WhereClause: wrapsql.WhereClause{
+					Operator: "AND", WhereOperations: []wrapsql.WhereOperation{
+						{LeftSide: table.column, Operator: "IN (" + wrapsql.GetNValueStubList(len(commentIDs)) + ")"},
+					},
+				},
+			}, args...)
+			if err != nil {
+				return errors.Wrapf(err, "unable to delete from %v", table.name)
+			}
+		}
+		return nil
+	})
+}
+
+func (s CommentStore) getReplyIDs(ctx context.Context, threadGUID string) (replyIDs []int64, returnErr error) {
+	statement := wrapsql.SelectStatement{
+		Selectors: []string{"ID"},
+		FromTable: "Comment",
+		WhereClause: wrapsql.WhereClause{
+			Operator: "AND", WhereOperations: []wrapsql.WhereOperation{
+				{LeftSide: "threadGuid", Operator: "= ?"},
+			},
+		},
+	}
+	rows, err := wrapsql.Select(ctx, s.db, statement, threadGUID)
+	if err != nil {
+		returnErr = err
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id int64
+		err := rows.Scan(&id)
+		if err != nil {
+			returnErr = err
+			return
+		}
+		replyIDs = append(replyIDs, id)
+	}
+	returnErr = rows.Err()
+	return
+}
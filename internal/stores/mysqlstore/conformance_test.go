@@ -0,0 +1,44 @@
+package mysqlstore
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/worlve/sp-service/internal/models/property"
+	"github.com/worlve/sp-service/internal/stores/storetestutils"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestBackend(t *testing.T, fixtures storetestutils.Fixtures) storetestutils.Backend {
+	tables := []string{"healthcheck", "Page", "PageOwner", "PageTemplate", "WebhookDelivery", "Webhook", "Event", "EventSubscriber", "AuditEntry", "CommentMention", "Comment", "PageDetail", "User", "Version", "Property", "PagePropertyOrder", "PagePropertyNumber", "PagePropertyString"}
+	for _, table := range tables {
+		err := clearTableForTest(mysqldb, table)
+		require.NoError(t, err)
+	}
+	queries := []string{"INSERT INTO `healthcheck` (`status`) VALUES (\"ok\")"}
+	for _, u := range fixtures.Users {
+		queries = append(queries, fmt.Sprintf("INSERT INTO User (`guid`, `email`, `createdAt`, `updatedAt`) VALUES( \"%v\", \"%v\", NOW(), NOW())", u.GUID, u.Email))
+	}
+	for _, v := range fixtures.Versions {
+		queries = append(queries, fmt.Sprintf("INSERT INTO Version (`guid`, `name`, `createdAt`, `updatedAt`) VALUES( \"%v\", \"%v\", NOW(), NOW())", v.GUID, v.Name))
+	}
+	for _, pt := range fixtures.PageTemplates {
+		queries = append(queries, fmt.Sprintf("INSERT INTO PageTemplate (`Version_ID`, `guid`, `name`, `hasProperties`, `hasDetails`, `hasRelations`, `createdAt`, `updatedAt`) VALUES(1, \"%v\", \"%v\", true, true, true, NOW(), NOW())", pt.GUID, pt.Name))
+	}
+	for _, p := range fixtures.Properties {
+		propertyType, err := property.GetDBPropertyType(p.Type)
+		require.NoError(t, err)
+		queries = append(queries, fmt.Sprintf("INSERT INTO Property (`Version_ID`, `type`, `key`, `createdAt`, `updatedAt`) VALUES( 1, \"%v\", \"%v\", NOW(), NOW())", propertyType, p.Key))
+	}
+	err := execPreTestQueries(mysqldb, queries)
+	require.NoError(t, err)
+	return storetestutils.Backend{
+		Stores:           newStores(mysqldb),
+		HealthcheckStore: NewHealthcheckStore(mysqldb),
+		UnitOfWork:       NewUnitOfWork(mysqldb),
+	}
+}
+
+func TestConformance(t *testing.T) {
+	storetestutils.RunConformanceTests(t, newTestBackend)
+}
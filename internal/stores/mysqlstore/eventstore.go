@@ -0,0 +1,288 @@
+package mysqlstore
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/worlve/sp-service/internal/models/event"
+	"github.com/worlve/sp-service/internal/stores/storeerror"
+	"github.com/worlve/sp-service/internal/util/wrapsql"
+	"github.com/pkg/errors"
+)
+
+// EventStore is the mysql for the event outbox and the offsets of its subscribers
+type EventStore struct {
+	db wrapsql.DB
+}
+
+// NewEventStore returns an EventStore
+func NewEventStore(mysqldb *sql.DB) EventStore {
+	return EventStore{
+		db: mysqldb,
+	}
+}
+
+var eventSelectors = []string{"ID", "guid", "type", "pageGuid", "userGuid", "payload", "createdAt"}
+
+// CreateEvent adds the event to the outbox, returning it with its offset set.
+func (s EventStore) CreateEvent(ctx context.Context, record event.Event) (event.Event, error) {
+	if record.GUID == "" {
+		return record, errors.New("must provide record.GUID to create the event")
+	}
+	if record.Type == "" {
+		return record, errors.New("must provide record.Type to create the event")
+	}
+	if s.db == nil {
+		return record, &storeerror.DBNotSetUp{}
+	}
+	t := time.Now()
+	record.CreatedAt = &t
+	id, err := wrapsql.ExecSingleInsert(ctx, s.db, wrapsql.InsertQuery{
+		IntoTable: "Event",
+		InjectedValues: wrapsql.InjectedValues{
+			"guid":      record.GUID,
+			"type":      record.Type,
+			"pageGuid":  record.PageID,
+			"userGuid":  record.UserID,
+			"payload":   string(record.Payload),
+			"createdAt": record.CreatedAt,
+		},
+	})
+	if err != nil {
+		return record, err
+	}
+	record.Offset = id
+	return record, nil
+}
+
+// GetEvents returns up to limit events after the given offset, in order.
+func (s EventStore) GetEvents(ctx context.Context, afterOffset int64, limit int) (events []event.Event, returnErr error) {
+	if s.db == nil {
+		returnErr = &storeerror.DBNotSetUp{}
+		return
+	}
+	statement := wrapsql.SelectStatement{
+		Selectors: eventSelectors,
+		FromTable: "Event",
+		WhereClause: wrapsql.WhereClause{
+			Operator: "AND", WhereOperations: []wrapsql.WhereOperation{
+				{LeftSide: "ID", Operator: "> ?"},
+			},
+		},
+		OrderClause: wrapsql.OrderClause{
+			Column: "ID",
+			SortBy: "ASC",
+		},
+		Limit: limit,
+	}
+	rows, err := wrapsql.Select(ctx, s.db, statement, afterOffset)
+	if err != nil {
+		returnErr = err
+		return
+	}
+	defer rows.Close()
+	events = make([]event.Event, 0)
+	for rows.Next() {
+		var e event.Event
+		var payload string
+		err := rows.Scan(&e.Offset, &e.GUID, &e.Type, &e.PageID, &e.UserID, &payload, &e.CreatedAt)
+		if err != nil {
+			returnErr = err
+			return
+		}
+		e.Payload = []byte(payload)
+		events = append(events, e)
+	}
+	returnErr = rows.Err()
+	return
+}
+
+// GetLastOffset returns the offset of the newest event, or 0 when there are none.
+func (s EventStore) GetLastOffset(ctx context.Context) (int64, error) {
+	if s.db == nil {
+		return 0, &storeerror.DBNotSetUp{}
+	}
+	statement := wrapsql.SelectStatement{
+		Selectors: []string{"ID"},
+		FromTable: "Event",
+		OrderClause: wrapsql.OrderClause{
+			Column: "ID",
+			SortBy: "DESC",
+		},
+		Limit: 1,
+	}
+	rows, err := wrapsql.Select(ctx, s.db, statement)
+	var offset int64
+	err = wrapsql.GetSingleRow("Event", rows, err, &offset)
+	if _, ok := err.(*storeerror.NotFound); ok {
+		return 0, nil
+	}
+	return offset, err
+}
+
+// PurgeEvents permanently deletes every event created before the given time.
+func (s EventStore) PurgeEvents(ctx context.Context, createdBefore time.Time) error {
+	if s.db == nil {
+		return &storeerror.DBNotSetUp{}
+	}
+	return wrapsql.ExecDelete(ctx, s.db, wrapsql.DeleteQuery{
+		FromTable: "Event",
+		WhereClause: wrapsql.WhereClause{
+			Operator: "AND", WhereOperations: []wrapsql.WhereOperation{
+				{LeftSide: "createdAt", Operator: "< ?"},
+			},
+		},
+	}, createdBefore)
+}
+
+// GetSubscriberOffset returns the offset of the last event the subscriber handled.
+func (s EventStore) GetSubscriberOffset(ctx context.Context, subscriber string) (int64, error) {
+	if subscriber == "" {
+		return 0, errors.New("must provide subscriber to get its offset")
+	}
+	if s.db == nil {
+		return 0, &storeerror.DBNotSetUp{}
+	}
+	statement := wrapsql.SelectStatement{
+		Selectors: []string{"eventOffset"},
+		FromTable: "EventSubscriber",
+		WhereClause: wrapsql.WhereClause{
+			Operator: "AND", WhereOperations: []wrapsql.WhereOperation{
+				{LeftSide: "name", Operator: "= ?"},
+			},
+		},
+		Limit: 1,
+	}
+	rows, err := wrapsql.Select(ctx, s.db, statement, subscriber)
+	var offset int64
+	err = wrapsql.GetSingleRow(subscriber, rows, err, &offset)
+	return offset, err
+}
+
+// ClaimSubscriber leases the subscriber until leaseUntil, so no other instance hands it events in the meantime.
+// Returns false if another lease on it hasn't ended by now.
+func (s EventStore) ClaimSubscriber(ctx context.Context, subscriber string, now, leaseUntil time.Time) (bool, error) {
+	if subscriber == "" {
+		return false, errors.New("must provide subscriber to claim it")
+	}
+	if s.db == nil {
+		return false, &storeerror.DBNotSetUp{}
+	}
+	rowsAffected, err := wrapsql.ExecUpdate(ctx, s.db, wrapsql.UpdateQuery{
+		UpdateTable: "EventSubscriber",
+		InjectedValues: wrapsql.InjectedValues{
+			"claimedUntil": leaseUntil,
+		},
+		WhereClause: wrapsql.WhereClause{
+			Operator: "AND", WhereOperations: []wrapsql.WhereOperation{
+				{LeftSide: "name", Operator: "= ?"},
+				{LeftSide: "claimedUntil", Operator: "<= ?"},
+			},
+		},
+	}, subscriber, now)
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected == 1, nil
+}
+
+// AdvanceSubscriberOffset moves the subscriber's offset from one event to a later one.
+// Returns false if the offset is no longer from, such as when another instance got there first.
+func (s EventStore) AdvanceSubscriberOffset(ctx context.Context, subscriber string, from, to int64) (bool, error) {
+	if subscriber == "" {
+		return false, errors.New("must provide subscriber to advance its offset")
+	}
+	if s.db == nil {
+		return false, &storeerror.DBNotSetUp{}
+	}
+	rowsAffected, err := wrapsql.ExecUpdate(ctx, s.db, wrapsql.UpdateQuery{
+		UpdateTable: "EventSubscriber",
+		InjectedValues: wrapsql.InjectedValues{
+			"eventOffset": to,
+			"updatedAt":   time.Now(),
+		},
+		WhereClause: wrapsql.WhereClause{
+			Operator: "AND", WhereOperations: []wrapsql.WhereOperation{
+				{LeftSide: "name", Operator: "= ?"},
+				{LeftSide: "eventOffset", Operator: "= ?"},
+			},
+		},
+	}, subscriber, from)
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected == 1, nil
+}
+
+// SetSubscriberOffset sets the subscriber's offset, adding the subscriber if it's new.
+func (s EventStore) SetSubscriberOffset(ctx context.Context, subscriber string, offset int64) error {
+	if subscriber == "" {
+		return errors.New("must provide subscriber to set its offset")
+	}
+	if s.db == nil {
+		return &storeerror.DBNotSetUp{}
+	}
+	return wrapsql.WithinTransaction(ctx, s.db, func(tx wrapsql.DB) error {
+		txStore := EventStore{db: tx}
+		_, err := txStore.GetSubscriberOffset(ctx, subscriber)
+		if _, ok := err.(*storeerror.NotFound); ok {
+			_, err = wrapsql.ExecSingleInsert(ctx, tx, wrapsql.InsertQuery{
+				IntoTable: "EventSubscriber",
+				InjectedValues: wrapsql.InjectedValues{
+					"name":         subscriber,
+					"eventOffset":  offset,
+					"claimedUntil": time.Unix(0, 0).UTC(),
+					"updatedAt":    time.Now(),
+				},
+			})
+			return err
+		}
+		if err != nil {
+			return err
+		}
+		return wrapsql.ExecSingleUpdate(ctx, tx, wrapsql.UpdateQuery{
+			UpdateTable: "EventSubscriber",
+			InjectedValues: wrapsql.InjectedValues{
+				"eventOffset": offset,
+				"updatedAt":   time.Now(),
+			},
+			WhereClause: wrapsql.WhereClause{
+				Operator: "AND", WhereOperations: []wrapsql.WhereOperation{
+					{LeftSide: "name", Operator: "= ?"},
+				},
+			},
+		}, subscriber)
+	})
+}
+
+// GetSubscriberOffsets returns the offset of every subscriber by its name.
+func (s EventStore) GetSubscriberOffsets(ctx context.Context) (offsets map[string]int64, returnErr error) {
+	if s.db == nil {
+		returnErr = &storeerror.DBNotSetUp{}
+		return
+	}
+	statement := wrapsql.SelectStatement{
+		Selectors: []string{"name", "eventOffset"},
+		FromTable: "EventSubscriber",
+	}
+	rows, err := wrapsql.Select(ctx, s.db, statement)
+	if err != nil {
+		returnErr = err
+		return
+	}
+	defer rows.Close()
+	offsets = map[string]int64{}
+	for rows.Next() {
+		var name string
+		var offset int64
+		err := rows.Scan(&name, &offset)
+		if err != nil {
+			returnErr = err
+			return
+		}
+		offsets[name] = offset
+	}
+	returnErr = rows.Err()
+	return
+}
@@ -1,7 +1,7 @@
 package mysqlstore
 
 import (
-	"database/sql"
+	"context"
 
 	"github.com/worlve/sp-service/internal/stores/storeerror"
 	"github.com/worlve/sp-service/internal/util/guidgen"
@@ -9,10 +9,10 @@ import (
 	"github.com/pkg/errors"
 )
 
-func getUniqueGUID(db *sql.DB, prefix string, length int, table, proposedGUID string, retry int) (string, error) {
+func getUniqueGUID(ctx context.Context, db wrapsql.DB, prefix string, length int, table, proposedGUID string, retry int) (string, error) {
 	guid := proposedGUID
 	if guid == "" {
-		guid = guidgen.GenerateGUID("PG", 15)
+		guid = guidgen.GenerateGUID(prefix, length)
 	}
 	statement := wrapsql.SelectStatement{
 		Selectors: []string{"guid"},
@@ -24,17 +24,20 @@ func getUniqueGUID(db *sql.DB, prefix string, length int, table, proposedGUID st
 		},
 		Limit: 1,
 	}
-	rows, err := db.Query(wrapsql.GetSelectString(statement), guid)
+	rows, err := wrapsql.Select(ctx, db, statement, guid)
 	var resGUID string
 	err = wrapsql.GetSingleRow(guid, rows, err, &resGUID)
 	if err == nil {
 		if proposedGUID != "" {
-			return "", errors.Errorf("the proposed guid %v already exists", proposedGUID)
+			return "", &storeerror.DupEntry{
+				ID:  proposedGUID,
+				Err: errors.Errorf("the proposed guid %v already exists", proposedGUID),
+			}
 		}
 		if retry >= guidgen.MaxGUIDRetryAttempts {
 			return "", guidgen.ErrMaxGUIDRetryAttempts
 		}
-		return getUniqueGUID(db, prefix, length, table, proposedGUID, retry+1)
+		return getUniqueGUID(ctx, db, prefix, length, table, proposedGUID, retry+1)
 	}
 	if _, ok := err.(*storeerror.NotFound); ok {
 		return guid, nil
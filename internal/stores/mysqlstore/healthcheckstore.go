@@ -1,9 +1,11 @@
 package mysqlstore
 
 import (
+	"context"
 	"database/sql"
 
 	"github.com/worlve/sp-service/internal/stores/storeerror"
+	"github.com/worlve/sp-service/internal/util/wrapsql"
 )
 
 // HealthcheckStore is the mysql for pages
@@ -19,11 +21,14 @@ func NewHealthcheckStore(mysqldb *sql.DB) HealthcheckStore {
 }
 
 // IsHealthy checks if the db is healthy.
-func (s HealthcheckStore) IsHealthy() (bool, error) {
+func (s HealthcheckStore) IsHealthy(ctx context.Context) (bool, error) {
 	if s.db == nil {
 		return false, &storeerror.DBNotSetUp{}
 	}
-	rows, err := s.db.Query("SELECT `status` FROM `healthcheck`")
+	rows, err := wrapsql.Select(ctx, s.db, wrapsql.SelectStatement{
+		Selectors: []string{"status"},
+		FromTable: "healthcheck",
+	})
 	if err != nil {
 		return false, err
 	}
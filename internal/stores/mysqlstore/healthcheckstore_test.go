@@ -1,6 +1,7 @@
 package mysqlstore
 
 import (
+	"context"
 	"errors"
 	"testing"
 
@@ -9,9 +10,11 @@ import (
 )
 
 func TestHealthcheckIsHealthy(t *testing.T) {
+	ctx := context.Background()
 	cases := []struct {
 		name                   string
 		shouldReplaceDBWithNil bool
+		shouldCancelContext    bool
 		preTestQueries         []string
 		returnIsHealthy        bool
 		returnErr              error
@@ -38,6 +41,13 @@ func TestHealthcheckIsHealthy(t *testing.T) {
 			returnIsHealthy:        false,
 			returnErr:              errors.New("DB is not configured"),
 		},
+		{
+			name:                "context canceled",
+			shouldCancelContext: true,
+			preTestQueries:      []string{"INSERT INTO `healthcheck` (`status`) VALUES (\"ok\")"},
+			returnIsHealthy:     false,
+			returnErr:           errors.New("Store call canceled\ncontext canceled"),
+		},
 	}
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -51,7 +61,12 @@ func TestHealthcheckIsHealthy(t *testing.T) {
 			require.NoError(t, err)
 			err = execPreTestQueries(healthcheckStore.db, tc.preTestQueries)
 			require.NoError(t, err)
-			isHealthy, err := healthcheckStore.IsHealthy()
+			callCtx, cancel := context.WithCancel(ctx)
+			defer cancel()
+			if tc.shouldCancelContext {
+				cancel()
+			}
+			isHealthy, err := healthcheckStore.IsHealthy(callCtx)
 			errExpected := testutils.TestErrorAgainstCase(t, err, tc.returnErr)
 			if errExpected {
 				return
@@ -0,0 +1,26 @@
+package mysqlstore
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/worlve/sp-service/internal/util/metrics"
+	"github.com/worlve/sp-service/internal/util/wrapsql"
+)
+
+// RegisterMetrics registers the connection pool stats of mysqldb on the registry,
+// along with the timings of every query run through the wrapsql helpers.
+func RegisterMetrics(registry *metrics.Registry, mysqldb *sql.DB) {
+	registry.RegisterDBStats(mysqldb)
+	queryDuration := registry.NewHistogramVec("sp_db_query_duration_seconds",
+		"Latency of the queries run against the database.",
+		metrics.DefaultBuckets,
+		"operation", "table", "result")
+	wrapsql.SetQueryObserver(func(operation, table string, duration time.Duration, err error) {
+		result := "ok"
+		if err != nil {
+			result = "error"
+		}
+		queryDuration.Observe(duration.Seconds(), operation, table, result)
+	})
+}
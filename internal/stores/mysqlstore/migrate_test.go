@@ -0,0 +1,42 @@
+package mysqlstore
+
+import (
+	"testing"
+
+	"github.com/worlve/sp-service/internal/stores/mysqlstore/migrations"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrations(t *testing.T) {
+	migrator, err := migrations.NewMigrator(mysqldb)
+	require.NoError(t, err)
+	latestVersion := migrator.LatestVersion()
+	err = migrator.CheckCompatible()
+	require.NoError(t, err)
+
+	err = migrator.Down()
+	require.NoError(t, err)
+	status, err := migrator.Status()
+	require.NoError(t, err)
+	require.Equal(t, latestVersion-1, status.CurrentVersion)
+	require.Len(t, status.Pending, 1)
+	err = migrator.CheckCompatible()
+	require.Equal(t, &migrations.IncompatibleSchema{CurrentVersion: latestVersion - 1, ExpectedVersion: latestVersion}, err)
+
+	err = migrator.To(0)
+	require.NoError(t, err)
+	status, err = migrator.Status()
+	require.NoError(t, err)
+	require.Equal(t, 0, status.CurrentVersion)
+	require.Empty(t, status.Applied)
+
+	err = migrator.Up()
+	require.NoError(t, err)
+	status, err = migrator.Status()
+	require.NoError(t, err)
+	require.Equal(t, latestVersion, status.CurrentVersion)
+	require.Empty(t, status.Pending)
+
+	err = migrator.To(latestVersion + 1)
+	require.Error(t, err)
+}
@@ -0,0 +1,296 @@
+// Package migrations keeps the MySQL schema versioned alongside the service.
+// Each migration is a pair of numbered files in sql/, "<version>_<name>.up.sql" and "<version>_<name>.down.sql",
+// embedded into the binary. Applied migrations are recorded in the SchemaMigration table.
+package migrations
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+//go:embed sql/*.sql
+var files embed.FS
+
+const schemaMigrationTable = "SchemaMigration"
+
+var fileNamePattern = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migration is a single versioned change to the schema.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status is the state of the schema compared to the embedded migrations.
+type Status struct {
+	CurrentVersion int
+	LatestVersion  int
+	Applied        []AppliedMigration
+	Pending        []Migration
+}
+
+// AppliedMigration is a migration recorded in the SchemaMigration table.
+type AppliedMigration struct {
+	Version   int
+	Name      string
+	AppliedAt time.Time
+}
+
+// IncompatibleSchema is an error that signifies that the schema version does not match the one the service expects.
+type IncompatibleSchema struct {
+	CurrentVersion  int
+	ExpectedVersion int
+}
+
+func (e *IncompatibleSchema) Error() string {
+	if e.CurrentVersion < e.ExpectedVersion {
+		return fmt.Sprintf("schema is at version %v but the service expects version %v: run the migrate up command", e.CurrentVersion, e.ExpectedVersion)
+	}
+	return fmt.Sprintf("schema is at version %v but the service only knows up to version %v: deploy a newer service or migrate down", e.CurrentVersion, e.ExpectedVersion)
+}
+
+// GetMigrations returns the embedded migrations in version order.
+func GetMigrations() ([]Migration, error) {
+	entries, err := files.ReadDir("sql")
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read embedded migrations")
+	}
+	byVersion := map[int]*Migration{}
+	for _, entry := range entries {
+		matches := fileNamePattern.FindStringSubmatch(entry.Name())
+		if matches == nil {
+			return nil, errors.Errorf("migration file %v must be named <version>_<name>.<up|down>.sql", entry.Name())
+		}
+		version, err := strconv.Atoi(matches[1])
+		if err != nil || version <= 0 {
+			return nil, errors.Errorf("migration file %v must have a positive version", entry.Name())
+		}
+		contents, err := files.ReadFile(path.Join("sql", entry.Name()))
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to read migration file %v", entry.Name())
+		}
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: matches[2]}
+			byVersion[version] = m
+		}
+		if m.Name != matches[2] {
+			return nil, errors.Errorf("migration version %v is used by both %v and %v", version, m.Name, matches[2])
+		}
+		if matches[3] == "up" {
+			m.Up = string(contents)
+		} else {
+			m.Down = string(contents)
+		}
+	}
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if strings.TrimSpace(m.Up) == "" || strings.TrimSpace(m.Down) == "" {
+			return nil, errors.Errorf("migration %v_%v must have both an up and a down file", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			return nil, errors.Errorf("migration versions must be sequential starting at 1: missing version %v", i+1)
+		}
+	}
+	return migrations, nil
+}
+
+// GetStatements splits a migration file into its individual statements.
+func GetStatements(contents string) []string {
+	var statements []string
+	for _, statement := range strings.Split(contents, ";\n") {
+		statement = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(statement), ";"))
+		if statement != "" {
+			statements = append(statements, statement)
+		}
+	}
+	return statements
+}
+
+// Migrator applies the embedded migrations to a db.
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+// NewMigrator returns a Migrator
+func NewMigrator(mysqldb *sql.DB) (Migrator, error) {
+	migrations, err := GetMigrations()
+	if err != nil {
+		return Migrator{}, err
+	}
+	return Migrator{
+		db:         mysqldb,
+		migrations: migrations,
+	}, nil
+}
+
+// LatestVersion is the version the schema is at once every migration is applied.
+func (m Migrator) LatestVersion() int {
+	return len(m.migrations)
+}
+
+// Status returns the current state of the schema.
+func (m Migrator) Status() (Status, error) {
+	applied, err := m.getApplied()
+	if err != nil {
+		return Status{}, err
+	}
+	status := Status{
+		LatestVersion: m.LatestVersion(),
+		Applied:       applied,
+	}
+	if len(applied) > 0 {
+		status.CurrentVersion = applied[len(applied)-1].Version
+	}
+	for _, migration := range m.migrations {
+		if migration.Version > status.CurrentVersion {
+			status.Pending = append(status.Pending, migration)
+		}
+	}
+	return status, nil
+}
+
+// CheckCompatible returns an IncompatibleSchema error unless every migration, and only those migrations, are applied.
+func (m Migrator) CheckCompatible() error {
+	status, err := m.Status()
+	if err != nil {
+		return err
+	}
+	if status.CurrentVersion != status.LatestVersion {
+		return &IncompatibleSchema{
+			CurrentVersion:  status.CurrentVersion,
+			ExpectedVersion: status.LatestVersion,
+		}
+	}
+	return nil
+}
+
+// Up applies every pending migration.
+func (m Migrator) Up() error {
+	return m.To(m.LatestVersion())
+}
+
+// Down reverts the most recently applied migration.
+func (m Migrator) Down() error {
+	status, err := m.Status()
+	if err != nil {
+		return err
+	}
+	if status.CurrentVersion == 0 {
+		return nil
+	}
+	return m.To(status.CurrentVersion - 1)
+}
+
+// To applies or reverts migrations until the schema is at the given version.
+func (m Migrator) To(version int) error {
+	if version < 0 || version > m.LatestVersion() {
+		return errors.Errorf("version must be between 0 and %v", m.LatestVersion())
+	}
+	status, err := m.Status()
+	if err != nil {
+		return err
+	}
+	if status.CurrentVersion > m.LatestVersion() {
+		return &IncompatibleSchema{
+			CurrentVersion:  status.CurrentVersion,
+			ExpectedVersion: m.LatestVersion(),
+		}
+	}
+	for current := status.CurrentVersion; current < version; current++ {
+		err = m.apply(m.migrations[current])
+		if err != nil {
+			return err
+		}
+	}
+	for current := status.CurrentVersion; current > version; current-- {
+		err = m.revert(m.migrations[current-1])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// apply runs the migration's up statements and records it.
+// MySQL commits schema changes immediately, so a failure part way through needs to be fixed by hand.
+func (m Migrator) apply(migration Migration) error {
+	for i, statement := range GetStatements(migration.Up) {
+		_, err := m.db.Exec(statement)
+		if err != nil {
+			return errors.Wrapf(err, "unable to apply migration %v_%v at statement %v", migration.Version, migration.Name, i+1)
+		}
+	}
+	_, err := m.db.Exec("INSERT INTO `"+schemaMigrationTable+"` (`version`, `name`, `appliedAt`) VALUES (?, ?, ?)", migration.Version, migration.Name, time.Now().UTC())
+	if err != nil {
+		return errors.Wrapf(err, "unable to record migration %v_%v", migration.Version, migration.Name)
+	}
+	return nil
+}
+
+// revert runs the migration's down statements and removes its record.
+func (m Migrator) revert(migration Migration) error {
+	for i, statement := range GetStatements(migration.Down) {
+		_, err := m.db.Exec(statement)
+		if err != nil {
+			return errors.Wrapf(err, "unable to revert migration %v_%v at statement %v", migration.Version, migration.Name, i+1)
+		}
+	}
+	_, err := m.db.Exec("DELETE FROM `"+schemaMigrationTable+"` WHERE `version` = ?", migration.Version)
+	if err != nil {
+		return errors.Wrapf(err, "unable to remove the record of migration %v_%v", migration.Version, migration.Name)
+	}
+	return nil
+}
+
+func (m Migrator) getApplied() (applied []AppliedMigration, returnErr error) {
+	if m.db == nil {
+		returnErr = errors.New("db is not configured")
+		return
+	}
+	_, err := m.db.Exec("CREATE TABLE IF NOT EXISTS `" + schemaMigrationTable + "` (" +
+		"`version` INT NOT NULL, " +
+		"`name` VARCHAR(255) NOT NULL, " +
+		"`appliedAt` DATETIME NOT NULL, " +
+		"PRIMARY KEY (`version`)" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8")
+	if err != nil {
+		returnErr = errors.Wrap(err, "unable to create the schema version table")
+		return
+	}
+	rows, err := m.db.Query("SELECT `version`, `name`, `appliedAt` FROM `" + schemaMigrationTable + "` ORDER BY `version` ASC")
+	if err != nil {
+		returnErr = errors.Wrap(err, "unable to get the applied migrations")
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var a AppliedMigration
+		err := rows.Scan(&a.Version, &a.Name, &a.AppliedAt)
+		if err != nil {
+			returnErr = err
+			return
+		}
+		applied = append(applied, a)
+	}
+	returnErr = rows.Err()
+	return
+}
@@ -0,0 +1,54 @@
+package migrations
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetMigrations(t *testing.T) {
+	migrations, err := GetMigrations()
+	require.NoError(t, err)
+	require.NotEmpty(t, migrations)
+	for i, m := range migrations {
+		require.Equal(t, i+1, m.Version)
+		require.NotEmpty(t, m.Name)
+		require.NotEmpty(t, GetStatements(m.Up), "migration %v has no up statements", m.Version)
+		require.NotEmpty(t, GetStatements(m.Down), "migration %v has no down statements", m.Version)
+	}
+}
+
+func TestGetStatements(t *testing.T) {
+	cases := []struct {
+		name             string
+		paramContents    string
+		returnStatements []string
+	}{
+		{
+			name:             "test multiple statements",
+			paramContents:    "CREATE TABLE `A` (\n  `ID` BIGINT\n);\n\nCREATE TABLE `B` (\n  `ID` BIGINT\n);\n",
+			returnStatements: []string{"CREATE TABLE `A` (\n  `ID` BIGINT\n)", "CREATE TABLE `B` (\n  `ID` BIGINT\n)"},
+		},
+		{
+			name:             "test single statement without a trailing newline",
+			paramContents:    "DROP TABLE `A`;",
+			returnStatements: []string{"DROP TABLE `A`"},
+		},
+		{
+			name:          "test empty",
+			paramContents: "\n\n",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.returnStatements, GetStatements(tc.paramContents))
+		})
+	}
+}
+
+func TestIncompatibleSchemaError(t *testing.T) {
+	err := &IncompatibleSchema{CurrentVersion: 1, ExpectedVersion: 2}
+	require.Equal(t, "schema is at version 1 but the service expects version 2: run the migrate up command", err.Error())
+	err = &IncompatibleSchema{CurrentVersion: 3, ExpectedVersion: 2}
+	require.Equal(t, "schema is at version 3 but the service only knows up to version 2: deploy a newer service or migrate down", err.Error())
+}
@@ -0,0 +1,19 @@
+DROP TABLE IF EXISTS `PagePropertyString`;
+
+DROP TABLE IF EXISTS `PagePropertyNumber`;
+
+DROP TABLE IF EXISTS `PagePropertyOrder`;
+
+DROP TABLE IF EXISTS `PageOwner`;
+
+DROP TABLE IF EXISTS `Page`;
+
+DROP TABLE IF EXISTS `Property`;
+
+DROP TABLE IF EXISTS `PageTemplate`;
+
+DROP TABLE IF EXISTS `Version`;
+
+DROP TABLE IF EXISTS `User`;
+
+DROP TABLE IF EXISTS `healthcheck`;
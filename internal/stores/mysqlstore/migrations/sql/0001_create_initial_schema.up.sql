@@ -0,0 +1,113 @@
+CREATE TABLE IF NOT EXISTS `healthcheck` (
+  `status` CHAR(2) NOT NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+CREATE TABLE IF NOT EXISTS `User` (
+  `ID` BIGINT NOT NULL AUTO_INCREMENT,
+  `guid` VARCHAR(15) NOT NULL,
+  `email` VARCHAR(255) NOT NULL,
+  `createdAt` DATETIME NOT NULL,
+  `updatedAt` DATETIME NOT NULL,
+  `deletedAt` DATETIME NULL,
+  PRIMARY KEY (`ID`),
+  UNIQUE KEY `User_guid` (`guid`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+CREATE TABLE IF NOT EXISTS `Version` (
+  `ID` BIGINT NOT NULL AUTO_INCREMENT,
+  `guid` VARCHAR(15) NOT NULL,
+  `name` VARCHAR(255) NOT NULL,
+  `createdAt` DATETIME NOT NULL,
+  `updatedAt` DATETIME NOT NULL,
+  `deletedAt` DATETIME NULL,
+  PRIMARY KEY (`ID`),
+  UNIQUE KEY `Version_guid` (`guid`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+CREATE TABLE IF NOT EXISTS `PageTemplate` (
+  `ID` BIGINT NOT NULL AUTO_INCREMENT,
+  `Version_ID` BIGINT NOT NULL,
+  `guid` VARCHAR(15) NOT NULL,
+  `name` VARCHAR(255) NOT NULL,
+  `hasProperties` BOOLEAN NOT NULL DEFAULT TRUE,
+  `hasDetails` BOOLEAN NOT NULL DEFAULT TRUE,
+  `hasRelations` BOOLEAN NOT NULL DEFAULT TRUE,
+  `createdAt` DATETIME NOT NULL,
+  `updatedAt` DATETIME NOT NULL,
+  `deletedAt` DATETIME NULL,
+  PRIMARY KEY (`ID`),
+  UNIQUE KEY `PageTemplate_guid` (`guid`),
+  KEY `PageTemplate_Version_ID` (`Version_ID`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+CREATE TABLE IF NOT EXISTS `Property` (
+  `ID` BIGINT NOT NULL AUTO_INCREMENT,
+  `Version_ID` BIGINT NOT NULL,
+  `type` CHAR(2) NOT NULL,
+  `key` VARCHAR(255) NOT NULL,
+  `createdAt` DATETIME NOT NULL,
+  `updatedAt` DATETIME NOT NULL,
+  `deletedAt` DATETIME NULL,
+  PRIMARY KEY (`ID`),
+  KEY `Property_key` (`key`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+CREATE TABLE IF NOT EXISTS `Page` (
+  `ID` BIGINT NOT NULL AUTO_INCREMENT,
+  `PageTemplate_ID` BIGINT NOT NULL,
+  `Version_ID` BIGINT NOT NULL,
+  `guid` VARCHAR(15) NOT NULL,
+  `title` VARCHAR(255) NOT NULL,
+  `summary` TEXT NOT NULL,
+  `permission` CHAR(2) NOT NULL,
+  `createdAt` DATETIME NOT NULL,
+  `updatedAt` DATETIME NOT NULL,
+  `deletedAt` DATETIME NULL,
+  PRIMARY KEY (`ID`),
+  KEY `Page_guid` (`guid`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+CREATE TABLE IF NOT EXISTS `PageOwner` (
+  `ID` BIGINT NOT NULL AUTO_INCREMENT,
+  `Page_ID` BIGINT NOT NULL,
+  `User_ID` BIGINT NOT NULL,
+  `isOwner` BOOLEAN NOT NULL DEFAULT FALSE,
+  PRIMARY KEY (`ID`),
+  KEY `PageOwner_Page_ID` (`Page_ID`),
+  KEY `PageOwner_User_ID` (`User_ID`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+CREATE TABLE IF NOT EXISTS `PagePropertyOrder` (
+  `Page_ID` BIGINT NOT NULL,
+  `Property_ID` BIGINT NOT NULL,
+  `order` INT NOT NULL,
+  KEY `PagePropertyOrder_Page_ID` (`Page_ID`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+CREATE TABLE IF NOT EXISTS `PagePropertyNumber` (
+  `ID` BIGINT NOT NULL AUTO_INCREMENT,
+  `Page_ID` BIGINT NOT NULL,
+  `Property_ID` BIGINT NOT NULL,
+  `Version_ID` BIGINT NOT NULL,
+  `value` DOUBLE NOT NULL,
+  `permission` CHAR(2) NOT NULL,
+  `createdAt` DATETIME NOT NULL,
+  `updatedAt` DATETIME NOT NULL,
+  `deletedAt` DATETIME NULL,
+  PRIMARY KEY (`ID`),
+  KEY `PagePropertyNumber_Page_ID` (`Page_ID`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+CREATE TABLE IF NOT EXISTS `PagePropertyString` (
+  `ID` BIGINT NOT NULL AUTO_INCREMENT,
+  `Page_ID` BIGINT NOT NULL,
+  `Property_ID` BIGINT NOT NULL,
+  `Version_ID` BIGINT NOT NULL,
+  `value` TEXT NOT NULL,
+  `permission` CHAR(2) NOT NULL,
+  `createdAt` DATETIME NOT NULL,
+  `updatedAt` DATETIME NOT NULL,
+  `deletedAt` DATETIME NULL,
+  PRIMARY KEY (`ID`),
+  KEY `PagePropertyString_Page_ID` (`Page_ID`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
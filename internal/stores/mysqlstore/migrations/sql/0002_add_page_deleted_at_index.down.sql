@@ -0,0 +1 @@
+DROP INDEX `Page_deletedAt` ON `Page`;
@@ -0,0 +1 @@
+CREATE INDEX `Page_deletedAt` ON `Page` (`deletedAt`);
@@ -0,0 +1,32 @@
+CREATE TABLE IF NOT EXISTS `Webhook` (
+  `ID` BIGINT NOT NULL AUTO_INCREMENT,
+  `User_ID` BIGINT NOT NULL,
+  `guid` VARCHAR(24) NOT NULL,
+  `url` VARCHAR(2048) NOT NULL,
+  `events` VARCHAR(255) NOT NULL,
+  `secret` VARCHAR(255) NOT NULL,
+  `createdAt` DATETIME NOT NULL,
+  PRIMARY KEY (`ID`),
+  UNIQUE KEY `Webhook_guid` (`guid`),
+  KEY `Webhook_User_ID` (`User_ID`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+CREATE TABLE IF NOT EXISTS `WebhookDelivery` (
+  `ID` BIGINT NOT NULL AUTO_INCREMENT,
+  `Webhook_ID` BIGINT NOT NULL,
+  `guid` VARCHAR(24) NOT NULL,
+  `event` VARCHAR(64) NOT NULL,
+  `payload` MEDIUMTEXT NOT NULL,
+  `status` VARCHAR(16) NOT NULL,
+  `attempts` INT NOT NULL DEFAULT 0,
+  `responseStatus` INT NOT NULL DEFAULT 0,
+  `lastError` TEXT NOT NULL,
+  `nextAttemptAt` DATETIME NULL,
+  `lastAttemptAt` DATETIME NULL,
+  `deliveredAt` DATETIME NULL,
+  `createdAt` DATETIME NOT NULL,
+  PRIMARY KEY (`ID`),
+  UNIQUE KEY `WebhookDelivery_guid` (`guid`),
+  KEY `WebhookDelivery_Webhook_ID` (`Webhook_ID`),
+  KEY `WebhookDelivery_status_nextAttemptAt` (`status`, `nextAttemptAt`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS `EventSubscriber`;
+
+DROP TABLE IF EXISTS `Event`;
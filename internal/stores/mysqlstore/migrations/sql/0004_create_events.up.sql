@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS `Event` (
+  `ID` BIGINT NOT NULL AUTO_INCREMENT,
+  `guid` VARCHAR(24) NOT NULL,
+  `type` VARCHAR(64) NOT NULL,
+  `pageGuid` VARCHAR(15) NOT NULL,
+  `userGuid` VARCHAR(15) NOT NULL,
+  `payload` MEDIUMTEXT NOT NULL,
+  `createdAt` DATETIME NOT NULL,
+  PRIMARY KEY (`ID`),
+  UNIQUE KEY `Event_guid` (`guid`),
+  KEY `Event_createdAt` (`createdAt`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+CREATE TABLE IF NOT EXISTS `EventSubscriber` (
+  `name` VARCHAR(64) NOT NULL,
+  `eventOffset` BIGINT NOT NULL,
+  `claimedUntil` DATETIME NOT NULL,
+  `updatedAt` DATETIME NOT NULL,
+  PRIMARY KEY (`name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
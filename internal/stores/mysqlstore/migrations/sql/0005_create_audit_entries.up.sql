@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS `AuditEntry` (
+  `ID` BIGINT NOT NULL AUTO_INCREMENT,
+  `guid` VARCHAR(24) NOT NULL,
+  `actorType` VARCHAR(16) NOT NULL,
+  `userGuid` VARCHAR(15) NOT NULL,
+  `method` VARCHAR(8) NOT NULL,
+  `route` VARCHAR(255) NOT NULL,
+  `targetGuid` VARCHAR(24) NOT NULL,
+  `pageGuid` VARCHAR(15) NOT NULL,
+  `status` INT NOT NULL,
+  `beforeSummary` MEDIUMTEXT NOT NULL,
+  `afterSummary` MEDIUMTEXT NOT NULL,
+  `requestId` VARCHAR(128) NOT NULL,
+  `createdAt` DATETIME NOT NULL,
+  PRIMARY KEY (`ID`),
+  UNIQUE KEY `AuditEntry_guid` (`guid`),
+  KEY `AuditEntry_userGuid` (`userGuid`),
+  KEY `AuditEntry_pageGuid` (`pageGuid`),
+  KEY `AuditEntry_targetGuid` (`targetGuid`),
+  KEY `AuditEntry_createdAt` (`createdAt`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
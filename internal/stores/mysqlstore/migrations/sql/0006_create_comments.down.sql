@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS `CommentMention`;
+
+DROP TABLE IF EXISTS `Comment`;
@@ -0,0 +1,30 @@
+CREATE TABLE IF NOT EXISTS `Comment` (
+  `ID` BIGINT NOT NULL AUTO_INCREMENT,
+  `Page_ID` BIGINT NOT NULL,
+  `User_ID` BIGINT NOT NULL,
+  `guid` VARCHAR(24) NOT NULL,
+  `threadGuid` VARCHAR(24) NOT NULL,
+  `detailGuid` VARCHAR(64) NOT NULL,
+  `partitionGuid` VARCHAR(64) NOT NULL,
+  `body` TEXT NOT NULL,
+  `mentions` TEXT NOT NULL,
+  `resolvedAt` DATETIME NULL,
+  `resolvedByGuid` VARCHAR(15) NOT NULL,
+  `createdAt` DATETIME NOT NULL,
+  `updatedAt` DATETIME NOT NULL,
+  PRIMARY KEY (`ID`),
+  UNIQUE KEY `Comment_guid` (`guid`),
+  KEY `Comment_Page_ID_threadGuid` (`Page_ID`, `threadGuid`),
+  KEY `Comment_threadGuid` (`threadGuid`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+CREATE TABLE IF NOT EXISTS `CommentMention` (
+  `ID` BIGINT NOT NULL AUTO_INCREMENT,
+  `Comment_ID` BIGINT NOT NULL,
+  `Page_ID` BIGINT NOT NULL,
+  `userGuid` VARCHAR(15) NOT NULL,
+  PRIMARY KEY (`ID`),
+  KEY `CommentMention_Comment_ID` (`Comment_ID`),
+  KEY `CommentMention_Page_ID` (`Page_ID`),
+  KEY `CommentMention_userGuid_Comment_ID` (`userGuid`, `Comment_ID`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
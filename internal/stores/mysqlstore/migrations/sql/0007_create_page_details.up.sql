@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS `PageDetail` (
+  `ID` BIGINT NOT NULL AUTO_INCREMENT,
+  `Page_ID` BIGINT NOT NULL,
+  `guid` VARCHAR(64) NOT NULL,
+  `title` VARCHAR(255) NOT NULL,
+  `summary` TEXT NOT NULL,
+  `partitions` MEDIUMTEXT NOT NULL,
+  `sortOrder` INT NOT NULL,
+  `createdAt` DATETIME NOT NULL,
+  `updatedAt` DATETIME NOT NULL,
+  PRIMARY KEY (`ID`),
+  UNIQUE KEY `PageDetail_Page_ID_guid` (`Page_ID`, `guid`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
@@ -7,57 +7,37 @@ import (
 	// used to import the "mysql" package
 	_ "github.com/go-sql-driver/mysql"
 
-	"github.com/worlve/sp-service/internal/util/env"
+	"github.com/worlve/sp-service/internal/config"
 )
 
-const (
-	defaultMySQLHost         = "127.0.0.1:3306"
-	defaultMySQLProtocol     = "tcp"
-	defaultMySQLDatabase     = "spiderweb_dev"
-	defaultMySQLUser         = "spiderweb_dev"
-	defaultMySQLPassword     = "password"
-	defaultMySQLCharset      = "utf8"
-	defaultMySQLRootUser     = "root"
-	defaultMySQLRootPassword = "rootpassword"
-)
-
-// SetupRootMySQL returns a MySQL db with the credentials pulled from the env vars for the root user.
+// SetupRootMySQL returns a MySQL db connected as the root user.
 // Note that root access won't be available outside of local development.
-func SetupRootMySQL(database string) (*sql.DB, error) {
-	if database == "" {
-		database = getMySQLDatabase()
-	}
-	dsnFormat := fmt.Sprintf("%v:%v@%v(%v)/%v?charset=%v&parseTime=true",
-		getMySQLRootUser(),
-		getMySQLRootPassword(),
-		getMySQLProtocol(),
-		getMySQLHost(),
-		database,
-		getMySQLCharset())
-	return setupMySQL(dsnFormat)
+func SetupRootMySQL(c config.MySQL, database string) (*sql.DB, error) {
+	return setupMySQL(c, c.RootUser, c.RootPassword, database)
 }
 
-// SetupMySQL returns a MySQL db with the credentials pulled from the env vars.
-func SetupMySQL(database string) (*sql.DB, error) {
+// SetupMySQL returns a MySQL db connected as the service's user.
+func SetupMySQL(c config.MySQL, database string) (*sql.DB, error) {
+	return setupMySQL(c, c.User, c.Password, database)
+}
+
+func setupMySQL(c config.MySQL, user, password, database string) (*sql.DB, error) {
 	if database == "" {
-		database = getMySQLDatabase()
+		database = c.Database
 	}
 	dsnFormat := fmt.Sprintf("%v:%v@%v(%v)/%v?charset=%v&parseTime=true",
-		getMySQLUser(),
-		getMySQLPassword(),
-		getMySQLProtocol(),
-		getMySQLHost(),
+		user,
+		password,
+		c.Protocol,
+		c.Host,
 		database,
-		getMySQLCharset())
-	return setupMySQL(dsnFormat)
-}
-
-func setupMySQL(dsnFormat string) (*sql.DB, error) {
+		c.Charset)
 	// see: https://github.com/go-sql-driver/mysql/wiki/Examples#a-word-on-sqlopen
 	db, err := sql.Open("mysql", dsnFormat)
 	if err != nil {
 		return db, err
 	}
+	db.SetMaxOpenConns(c.MaxOpenConns)
 	// Open doesn't open a connection. Validate DSN data:
 	err = db.Ping()
 	if err != nil {
@@ -65,35 +45,3 @@ func setupMySQL(dsnFormat string) (*sql.DB, error) {
 	}
 	return db, nil
 }
-
-func getMySQLUser() string {
-	return env.Get("MYSQL_USER", defaultMySQLUser)
-}
-
-func getMySQLPassword() string {
-	return env.Get("MYSQL_PASSWORD", defaultMySQLPassword)
-}
-
-func getMySQLRootUser() string {
-	return env.Get("MYSQL_USER", defaultMySQLRootUser)
-}
-
-func getMySQLRootPassword() string {
-	return env.Get("MYSQL_PASSWORD", defaultMySQLRootPassword)
-}
-
-func getMySQLProtocol() string {
-	return env.Get("MYSQL_PROTOCOL", defaultMySQLProtocol)
-}
-
-func getMySQLHost() string {
-	return env.Get("MYSQL_HOST", defaultMySQLHost)
-}
-
-func getMySQLDatabase() string {
-	return env.Get("MYSQL_DATABASE", defaultMySQLDatabase)
-}
-
-func getMySQLCharset() string {
-	return env.Get("MYSQL_CHARSET", defaultMySQLCharset)
-}
@@ -1,60 +1,63 @@
 // This test file sets up Main so that it:
-// 1. Only runs this packages' tests if it can establish a connection to the local database container and pull in the setup.sql file.
-// 2. It will create a new database under the root db user, and execute the setup.sql file
+// 1. Only runs this packages' tests if it can establish a connection to the local database container.
+// 2. It will create a new database under the root db user, and apply every migration in the migrations package.
 // 3. Specific stores' tests should assert that mysqldb is setup and ready to pass to the store.
 // 4. Once the tests run, it will close and remove the temporarly database.
 // It is the responsibility of the individual tests to reset the tables to a testable state before
-// runnning their tests: you can only assume that the setup.sql command added the neccesary tables
+// runnning their tests: you can only assume that the migrations added the neccesary tables
 // and that the tables likely contain junk content that needs to be deleted.
 // The helper functions `clearTableForTest` and `execPreTestQueries` can be used by the tests to
 // prepare the test before execution.
 package mysqlstore
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
-	"io/ioutil"
 	"math/rand"
 	"os"
-	"strings"
 	"testing"
 	"time"
 
-	"github.com/worlve/sp-service/internal/util/env"
+	"github.com/worlve/sp-service/internal/config"
+	"github.com/worlve/sp-service/internal/stores/mysqlstore/migrations"
+	"github.com/worlve/sp-service/internal/util/wrapsql"
 )
 
 var mysqldb *sql.DB
 var mysqldbName string
+var mysqlConfig config.MySQL
 
 func getDb() (*sql.DB, string, bool, error) {
-	filePath := env.Get("SETUP_SQL_FILEPATH", "/Users/rhyeen/Documents/repos/project-spiderweb/project-spiderweb-db/setup.sql")
-	if filePath == "" {
-		return nil, "", false, nil
-	}
-	fileBytes, err := ioutil.ReadFile(filePath)
+	c, err := config.Load(os.Getenv(config.FileEnvKey))
 	if err != nil {
 		return nil, "", false, err
 	}
-	fileString := string(fileBytes)
-	queries := getQueries(fileString)
+	mysqlConfig = c.MySQL
+	rootDB, err := SetupRootMySQL(mysqlConfig, "")
+	if err != nil {
+		fmt.Printf("Unable to connect to MySQL: %v\n", err)
+		return nil, "", false, nil
+	}
+	rootDB.Close()
 	newDB, newDBName, err := createAndOpenNewDB()
 	if err != nil {
 		return nil, "", false, err
 	}
-	err = executeQueries(newDB, queries)
+	migrator, err := migrations.NewMigrator(newDB)
+	if err != nil {
+		return newDB, newDBName, false, err
+	}
+	err = migrator.Up()
 	if err != nil {
 		return newDB, newDBName, false, err
 	}
 	return newDB, newDBName, true, nil
 }
 
-func getQueries(fileString string) []string {
-	return strings.Split(string(fileString), ";\n")
-}
-
 func createAndOpenNewDB() (*sql.DB, string, error) {
 	newDBName := getRandomDBName()
-	rootDB, err := SetupRootMySQL("")
+	rootDB, err := SetupRootMySQL(mysqlConfig, "")
 	if err != nil {
 		return nil, newDBName, err
 	}
@@ -65,7 +68,7 @@ func createAndOpenNewDB() (*sql.DB, string, error) {
 	}
 	rootDB.Close()
 
-	db, err := SetupRootMySQL(newDBName)
+	db, err := SetupRootMySQL(mysqlConfig, newDBName)
 	if err != nil {
 		return nil, newDBName, err
 	}
@@ -82,7 +85,7 @@ func getRandomDBName() string {
 	return string(b)
 }
 
-func executeQueries(db *sql.DB, queries []string) error {
+func executeQueries(db wrapsql.DB, queries []string) error {
 	if db == nil {
 		return nil
 	}
@@ -93,7 +96,7 @@ func executeQueries(db *sql.DB, queries []string) error {
 		if query == "" {
 			continue
 		}
-		_, err := db.Exec(query)
+		_, err := db.ExecContext(context.Background(), query)
 		if err != nil {
 			return err
 		}
@@ -103,7 +106,7 @@ func executeQueries(db *sql.DB, queries []string) error {
 
 func closeAndRemoveDb(db *sql.DB, dbName string) error {
 	db.Close()
-	rootDB, err := SetupRootMySQL("")
+	rootDB, err := SetupRootMySQL(mysqlConfig, "")
 	if err != nil {
 		return err
 	}
@@ -142,11 +145,11 @@ func TestMain(m *testing.M) {
 	os.Exit(result)
 }
 
-func clearTableForTest(db *sql.DB, table string) error {
+func clearTableForTest(db wrapsql.DB, table string) error {
 	if db == nil {
 		return nil
 	}
-	statement, err := db.Prepare(fmt.Sprintf("TRUNCATE TABLE `%v`", table))
+	statement, err := db.PrepareContext(context.Background(), fmt.Sprintf("TRUNCATE TABLE `%v`", table))
 	if err != nil {
 		return err
 	}
@@ -155,6 +158,6 @@ func clearTableForTest(db *sql.DB, table string) error {
 	return err
 }
 
-func execPreTestQueries(db *sql.DB, queries []string) error {
+func execPreTestQueries(db wrapsql.DB, queries []string) error {
 	return executeQueries(db, queries)
 }
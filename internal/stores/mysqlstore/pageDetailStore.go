@@ -1,16 +1,21 @@
 package mysqlstore
 
 import (
+	"context"
 	"database/sql"
-	"errors"
+	"encoding/json"
+	"time"
 
 	"github.com/worlve/sp-service/internal/models/pagedetail"
 	"github.com/worlve/sp-service/internal/stores/storeerror"
+	"github.com/worlve/sp-service/internal/util/guidgen"
+	"github.com/worlve/sp-service/internal/util/wrapsql"
+	"github.com/pkg/errors"
 )
 
 // PageDetailStore is the mysql for a page detail
 type PageDetailStore struct {
-	db *sql.DB
+	db wrapsql.DB
 }
 
 // NewPageDetailStore returns a PageDetailStore
@@ -20,22 +25,201 @@ func NewPageDetailStore(mysqldb *sql.DB) PageDetailStore {
 	}
 }
 
-// UpdatePageDetail updates the given page.
-func (s PageDetailStore) UpdatePageDetail(record pagedetail.PageDetail) error {
+// UpdatePageDetail sets the title and summary of the page's detail, and returns the detail as it's stored.
+// If the page has no detail with the record's GUID, a storeerror.NotFound will be returned.
+func (s PageDetailStore) UpdatePageDetail(ctx context.Context, pageGUID string, record pagedetail.PageDetail) (pagedetail.PageDetail, error) {
+	if pageGUID == "" {
+		return pagedetail.PageDetail{}, errors.New("must provide pageGUID to update the page detail")
+	}
 	if record.GUID == "" {
-		return errors.New("must provide record.GUID to update the page")
+		return pagedetail.PageDetail{}, errors.New("must provide record.GUID to update the page detail")
 	}
 	if record.Title == "" {
-		return errors.New("must provide record.Title to update the page")
+		return pagedetail.PageDetail{}, errors.New("must provide record.Title to update the page detail")
+	}
+	if s.db == nil {
+		return pagedetail.PageDetail{}, &storeerror.DBNotSetUp{}
+	}
+	var updated pagedetail.PageDetail
+	err := wrapsql.WithinTransaction(ctx, s.db, func(tx wrapsql.DB) error {
+		pageID, err := PageStore{db: tx}.getPageID(ctx, pageGUID)
+		if err != nil {
+			return errors.Wrapf(err, "unable to get Page.ID for guid: %v", pageGUID)
+		}
+		statement := wrapsql.SelectStatement{
+			Selectors: []string{"ID", "partitions"},
+			FromTable: "PageDetail",
+			WhereClause: wrapsql.WhereClause{
+				Operator: "AND", WhereOperations: []wrapsql.WhereOperation{
+					{LeftSide: "Page_ID", Operator: "= ?"},
+					{LeftSide: "guid", Operator: "= ?"},
+				},
+			},
+			Limit: 1,
+		}
+		rows, err := wrapsql.Select(ctx, tx, statement, pageID, record.GUID)
+		var partitions string
+		err = wrapsql.GetSingleRow(record.GUID, rows, err, &updated.ID, &partitions)
+		if err != nil {
+			return err
+		}
+		updated.Partitions, err = unmarshalStoredPartitions(partitions)
+		if err != nil {
+			return errors.Wrapf(err, "unable to read the partitions of detail %v", record.GUID)
+		}
+		updated.GUID, updated.Title, updated.Summary = record.GUID, record.Title, record.Summary
+		return wrapsql.ExecSingleUpdate(ctx, tx, wrapsql.UpdateQuery{
+			UpdateTable: "PageDetail",
+			InjectedValues: wrapsql.InjectedValues{
+				"title":     record.Title,
+				"summary":   record.Summary,
+				"updatedAt": time.Now(),
+			},
+			WhereClause: wrapsql.WhereClause{
+				Operator: "AND", WhereOperations: []wrapsql.WhereOperation{
+					{LeftSide: "ID", Operator: "= ?"},
+				},
+			},
+		}, updated.ID)
+	})
+	if err != nil {
+		return pagedetail.PageDetail{}, err
+	}
+	return updated, nil
+}
+
+// GetPageDetails returns the page's details in order.
+func (s PageDetailStore) GetPageDetails(ctx context.Context, pageGUID string) (details []pagedetail.PageDetail, returnErr error) {
+	if pageGUID == "" {
+		returnErr = errors.New("must provide pageGUID to get the page details")
+		return
+	}
+	if s.db == nil {
+		returnErr = &storeerror.DBNotSetUp{}
+		return
+	}
+	statement := wrapsql.SelectStatement{
+		Selectors: []string{"PageDetail.ID", "PageDetail.guid", "PageDetail.title", "PageDetail.summary", "PageDetail.partitions"},
+		FromTable: "PageDetail",
+		JoinClauses: []wrapsql.JoinClause{
+			{JoinTable: "Page", On: wrapsql.OnClause{LeftSide: "PageDetail.Page_ID", RightSide: "Page.ID"}},
+		},
+		WhereClause: wrapsql.WhereClause{
+			Operator: "AND", WhereOperations: []wrapsql.WhereOperation{
+				{LeftSide: "Page.guid", Operator: "= ?"},
+			},
+		},
+		OrderClause: wrapsql.OrderClause{
+			Column: "PageDetail.sortOrder",
+			SortBy: "ASC",
+		},
+	}
+	rows, err := wrapsql.Select(ctx, s.db, statement, pageGUID)
+	if err != nil {
+		returnErr = err
+		return
+	}
+	defer rows.Close()
+	details = make([]pagedetail.PageDetail, 0)
+	for rows.Next() {
+		var d pagedetail.PageDetail
+		var partitions string
+		err := rows.Scan(&d.ID, &d.GUID, &d.Title, &d.Summary, &partitions)
+		if err != nil {
+			returnErr = err
+			return
+		}
+		d.Partitions, err = unmarshalStoredPartitions(partitions)
+		if err != nil {
+			returnErr = errors.Wrapf(err, "unable to read the partitions of detail %v", d.GUID)
+			return
+		}
+		details = append(details, d)
+	}
+	returnErr = rows.Err()
+	return
+}
+
+// ReplacePageDetails replaces the current page's details with the new details, in order.
+// Details without a GUID are given one.
+func (s PageDetailStore) ReplacePageDetails(ctx context.Context, pageGUID string, details []pagedetail.PageDetail) error {
+	if pageGUID == "" {
+		return errors.New("must provide pageGUID to replace the page details")
 	}
 	if s.db == nil {
 		return &storeerror.DBNotSetUp{}
 	}
-	statement, err := s.db.Prepare("UPDATE `Page` SET `title` = ?, `summary` = ? WHERE `guid` = ?")
+	err := setPageDetailGUIDs(details)
 	if err != nil {
 		return err
 	}
-	defer statement.Close()
-	_, err = statement.Exec(record.Title, record.Summary, record.GUID)
-	return err
+	return wrapsql.WithinTransaction(ctx, s.db, func(tx wrapsql.DB) error {
+		pageID, err := PageStore{db: tx}.getPageID(ctx, pageGUID)
+		if err != nil {
+			return errors.Wrapf(err, "unable to get Page.ID for guid: %v", pageGUID)
+		}
+		err = wrapsql.ExecDelete(ctx, tx, wrapsql.DeleteQuery{
+			FromTable: "PageDetail",
+			WhereClause: wrapsql.WhereClause{
+				Operator: "AND", WhereOperations: []wrapsql.WhereOperation{
+					{LeftSide: "Page_ID", Operator: "= ?"},
+				},
+			},
+		}, pageID)
+		if err != nil {
+			return errors.Wrap(err, "unable to delete page details")
+		}
+		t := time.Now()
+		for i, d := range details {
+			partitions, err := json.Marshal(d.Partitions)
+			if err != nil {
+				return errors.Wrapf(err, "unable to write the partitions of detail %v", d.GUID)
+			}
+			id, err := wrapsql.ExecSingleInsert(ctx, tx, wrapsql.InsertQuery{
+				IntoTable: "PageDetail",
+				InjectedValues: wrapsql.InjectedValues{
+					"Page_ID":    pageID,
+					"guid":       d.GUID,
+					"title":      d.Title,
+					"summary":    d.Summary,
+					"partitions": string(partitions),
+					"sortOrder":  i,
+					"createdAt":  t,
+					"updatedAt":  t,
+				},
+			})
+			if err != nil {
+				return errors.Wrap(err, "unable to insert page detail")
+			}
+			details[i].ID = id
+		}
+		return nil
+	})
+}
+
+// setPageDetailGUIDs generates a GUID for each detail without one; a page can't have two details with the same GUID.
+func setPageDetailGUIDs(details []pagedetail.PageDetail) error {
+	seen := make(map[string]bool)
+	for i := range details {
+		if details[i].GUID == "" {
+			details[i].GUID = guidgen.GenerateGUID("DT", 15)
+		}
+		if seen[details[i].GUID] {
+			return &storeerror.DupEntry{ID: details[i].GUID}
+		}
+		seen[details[i].GUID] = true
+	}
+	return nil
+}
+
+func unmarshalStoredPartitions(stored string) ([]pagedetail.Partition, error) {
+	partitions := make([]pagedetail.Partition, 0)
+	if stored == "" || stored == "null" {
+		return partitions, nil
+	}
+	err := json.Unmarshal([]byte(stored), &partitions)
+	if err != nil {
+		return nil, err
+	}
+	return partitions, pagedetail.UnmarshalPartitions(partitions)
 }
@@ -1,6 +1,7 @@
 package mysqlstore
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"time"
@@ -17,7 +18,7 @@ import (
 
 // PageStore is the mysql for pages
 type PageStore struct {
-	db *sql.DB
+	db wrapsql.DB
 }
 
 // NewPageStore returns a PageStore
@@ -28,7 +29,7 @@ func NewPageStore(mysqldb *sql.DB) PageStore {
 }
 
 // CreatePage creates a new page.
-func (s PageStore) CreatePage(record page.Page, ownerID int64) (page.Page, error) {
+func (s PageStore) CreatePage(ctx context.Context, record page.Page, ownerID int64) (page.Page, error) {
 	if record.GUID == "" {
 		return record, errors.New("must provide record.GUID to create the page")
 	}
@@ -53,32 +54,36 @@ func (s PageStore) CreatePage(record page.Page, ownerID int64) (page.Page, error
 	t := time.Now()
 	record.CreatedAt = &t
 	record.UpdatedAt = &t
-	id, err := wrapsql.ExecSingleInsert(s.db, wrapsql.InsertQuery{
-		IntoTable: "Page",
-		InjectedValues: wrapsql.InjectedValues{
-			"PageTemplate_ID": record.PageTemplate.ID,
-			"Version_ID":      record.Version.ID,
-			"guid":            record.GUID,
-			"title":           record.Title,
-			"summary":         record.Summary,
-			"permission":      record.PermissionType,
-			"createdAt":       record.CreatedAt,
-			"updatedAt":       record.UpdatedAt,
-		},
-	})
-	if err != nil {
-		return record, err
-	}
-	record.ID = id
-	_, err = wrapsql.ExecSingleInsert(s.db, wrapsql.InsertQuery{
-		IntoTable: "PageOwner",
-		InjectedValues: wrapsql.InjectedValues{
-			"Page_ID": record.ID,
-			"User_ID": ownerID,
-			"isOwner": true,
-		},
+	err := wrapsql.WithinTransaction(ctx, s.db, func(tx wrapsql.DB) error {
+		id, err := wrapsql.ExecSingleInsert(ctx, tx, wrapsql.InsertQuery{
+			IntoTable: "Page",
+			InjectedValues: wrapsql.InjectedValues{
+				"PageTemplate_ID": record.PageTemplate.ID,
+				"Version_ID":      record.Version.ID,
+				"guid":            record.GUID,
+				"title":           record.Title,
+				"summary":         record.Summary,
+				"permission":      record.PermissionType,
+				"createdAt":       record.CreatedAt,
+				"updatedAt":       record.UpdatedAt,
+			},
+		})
+		if err != nil {
+			return err
+		}
+		record.ID = id
+		_, err = wrapsql.ExecSingleInsert(ctx, tx, wrapsql.InsertQuery{
+			IntoTable: "PageOwner",
+			InjectedValues: wrapsql.InjectedValues{
+				"Page_ID": record.ID,
+				"User_ID": ownerID,
+				"isOwner": true,
+			},
+		})
+		return err
 	})
 	if err != nil {
+		record.ID = 0
 		return record, err
 	}
 	return record, nil
@@ -86,7 +91,7 @@ func (s PageStore) CreatePage(record page.Page, ownerID int64) (page.Page, error
 
 // CanEditPage checks if the given user can modify the given page. If not, a storeerror.NotAuthorized will be returned.
 // Will also return whether or not the user is the original owner.
-func (s PageStore) CanEditPage(guid, userID string) (bool, error) {
+func (s PageStore) CanEditPage(ctx context.Context, guid, userID string) (bool, error) {
 	if guid == "" {
 		return false, errors.New("must provide a guid to check privileges")
 	}
@@ -111,7 +116,7 @@ func (s PageStore) CanEditPage(guid, userID string) (bool, error) {
 		},
 		Limit: 1,
 	}
-	rows, err := s.db.Query(wrapsql.GetSelectString(statement), guid, userID)
+	rows, err := wrapsql.Select(ctx, s.db, statement, guid, userID)
 	var isOwner bool
 	err = wrapsql.GetSingleRow(guid, rows, err, &isOwner)
 	if _, ok := err.(*storeerror.NotFound); ok {
@@ -125,8 +130,8 @@ func (s PageStore) CanEditPage(guid, userID string) (bool, error) {
 
 // CanReadPage checks if the given user can read the given page. If not, a storeerror.NotAuthorized will be returned.
 // Will also return whether or not the user is the original owner.
-func (s PageStore) CanReadPage(guid, userID string) (bool, error) {
-	isOwner, err := s.CanEditPage(guid, userID)
+func (s PageStore) CanReadPage(ctx context.Context, guid, userID string) (bool, error) {
+	isOwner, err := s.CanEditPage(ctx, guid, userID)
 	if err != nil {
 		if _, ok := err.(*storeerror.NotAuthorized); !ok {
 			return isOwner, err
@@ -148,7 +153,7 @@ func (s PageStore) CanReadPage(guid, userID string) (bool, error) {
 		},
 		Limit: 1,
 	}
-	rows, err := s.db.Query(wrapsql.GetSelectString(statement), guid)
+	rows, err := wrapsql.Select(ctx, s.db, statement, guid)
 	var pagePermission string
 	err = wrapsql.GetSingleRow(guid, rows, err, &pagePermission)
 	if _, ok := err.(*storeerror.NotFound); ok {
@@ -164,8 +169,38 @@ func (s PageStore) CanReadPage(guid, userID string) (bool, error) {
 	return p.IsPublic(), nil
 }
 
+// GetPageOwner returns the GUID of the user who owns the given page, even once it's been removed.
+// If the page doesn't exist, a storeerror.NotFound will be returned.
+func (s PageStore) GetPageOwner(ctx context.Context, guid string) (string, error) {
+	if guid == "" {
+		return "", errors.New("must provide a guid to get the owner")
+	}
+	if s.db == nil {
+		return "", &storeerror.DBNotSetUp{}
+	}
+	statement := wrapsql.SelectStatement{
+		Selectors: []string{"User.guid"},
+		FromTable: "PageOwner",
+		JoinClauses: []wrapsql.JoinClause{
+			{JoinTable: "Page", On: wrapsql.OnClause{LeftSide: "PageOwner.Page_ID", RightSide: "Page.ID"}},
+			{JoinTable: "User", On: wrapsql.OnClause{LeftSide: "PageOwner.User_ID", RightSide: "User.ID"}},
+		},
+		WhereClause: wrapsql.WhereClause{
+			Operator: "AND", WhereOperations: []wrapsql.WhereOperation{
+				{LeftSide: "Page.guid", Operator: "= ?"},
+				{LeftSide: "PageOwner.isOwner", Operator: "= TRUE"},
+			},
+		},
+		Limit: 1,
+	}
+	rows, err := wrapsql.Select(ctx, s.db, statement, guid)
+	var ownerID string
+	err = wrapsql.GetSingleRow(guid, rows, err, &ownerID)
+	return ownerID, err
+}
+
 // UpdatePage sets the given page.
-func (s PageStore) UpdatePage(record page.Page) error {
+func (s PageStore) UpdatePage(ctx context.Context, record page.Page) error {
 	if record.GUID == "" {
 		return errors.New("must provide record.GUID to update the page")
 	}
@@ -196,11 +231,11 @@ func (s PageStore) UpdatePage(record page.Page) error {
 	if record.DeletedAt != nil {
 		query.InjectedValues["deletedAt"] = record.DeletedAt
 	}
-	return wrapsql.ExecSingleUpdate(s.db, query, record.GUID)
+	return wrapsql.ExecSingleUpdate(ctx, s.db, query, record.GUID)
 }
 
 // GetPage returns back the given page.
-func (s PageStore) GetPage(guid string) (page.Page, error) {
+func (s PageStore) GetPage(ctx context.Context, guid string) (page.Page, error) {
 	if guid == "" {
 		return page.Page{}, errors.New("must provide guid to get the page")
 	}
@@ -219,7 +254,7 @@ func (s PageStore) GetPage(guid string) (page.Page, error) {
 		},
 		Limit: 1,
 	}
-	rows, err := s.db.Query(wrapsql.GetSelectString(statement), guid)
+	rows, err := wrapsql.Select(ctx, s.db, statement, guid)
 	p := page.Page{
 		GUID: guid,
 	}
@@ -237,7 +272,54 @@ func (s PageStore) GetPage(guid string) (page.Page, error) {
 }
 
 // GetPages returns a list of pages based on the nextBatchId
-func (s PageStore) GetPages(userID, thisBatchID string, limit int) (pages []page.Page, total int, nextBatchID string, returnErr error) {
+func (s PageStore) GetPages(ctx context.Context, userID, thisBatchID string, limit int) ([]page.Page, int, string, error) {
+	return s.getPages(ctx, userID, thisBatchID, limit, false)
+}
+
+// GetUserPageGUIDs returns the GUIDs of the pages the user owns or that are shared with them, removed ones included.
+func (s PageStore) GetUserPageGUIDs(ctx context.Context, userID string) ([]string, error) {
+	if userID == "" {
+		return nil, errors.New("must provide userID to get the page GUIDs")
+	}
+	if s.db == nil {
+		return nil, &storeerror.DBNotSetUp{}
+	}
+	statement := wrapsql.SelectStatement{
+		Selectors: []string{"Page.guid"},
+		FromTable: "PageOwner",
+		JoinClauses: []wrapsql.JoinClause{
+			{JoinTable: "Page", On: wrapsql.OnClause{LeftSide: "PageOwner.Page_ID", RightSide: "Page.ID"}},
+			{JoinTable: "User", On: wrapsql.OnClause{LeftSide: "PageOwner.User_ID", RightSide: "User.ID"}},
+		},
+		WhereClause: wrapsql.WhereClause{
+			Operator: "AND", WhereOperations: []wrapsql.WhereOperation{
+				{LeftSide: "User.guid", Operator: "= ?"},
+			},
+		},
+	}
+	rows, err := wrapsql.Select(ctx, s.db, statement, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	guids := make([]string, 0)
+	for rows.Next() {
+		var guid string
+		err := rows.Scan(&guid)
+		if err != nil {
+			return nil, err
+		}
+		guids = append(guids, guid)
+	}
+	return guids, rows.Err()
+}
+
+// GetRemovedPages returns a list of removed pages based on the nextBatchId
+func (s PageStore) GetRemovedPages(ctx context.Context, userID, thisBatchID string, limit int) ([]page.Page, int, string, error) {
+	return s.getPages(ctx, userID, thisBatchID, limit, true)
+}
+
+func (s PageStore) getPages(ctx context.Context, userID, thisBatchID string, limit int, removed bool) (pages []page.Page, total int, nextBatchID string, returnErr error) {
 	if userID == "" {
 		returnErr = errors.New("must provide userID to get pages")
 		return
@@ -245,14 +327,14 @@ func (s PageStore) GetPages(userID, thisBatchID string, limit int) (pages []page
 	var err error
 	thisPageID := int64(0)
 	if thisBatchID != "" {
-		thisPageID, err = s.getPageID(thisBatchID)
+		thisPageID, err = s.getPageID(ctx, thisBatchID)
 		if err != nil {
 			returnErr = errors.Wrapf(err, "unable to use thisBatchID: %v", thisBatchID)
 			return
 		}
 	}
 	statement := wrapsql.SelectStatement{
-		Selectors: []string{"Page.guid", "Page.ID", "Version.guid", "PageTemplate.guid", "Page.title", "Page.summary", "Page.permission", "Page.createdAt", "Page.updatedAt"},
+		Selectors: []string{"Page.guid", "Page.ID", "Version.guid", "PageTemplate.guid", "Page.title", "Page.summary", "Page.permission", "Page.createdAt", "Page.updatedAt", "Page.deletedAt"},
 		FromTable: "Page",
 		JoinClauses: []wrapsql.JoinClause{
 			{JoinTable: "PageOwner", On: wrapsql.OnClause{LeftSide: "PageOwner.Page_ID", RightSide: "Page.ID"}},
@@ -264,12 +346,12 @@ func (s PageStore) GetPages(userID, thisBatchID string, limit int) (pages []page
 			Operator: "AND", WhereOperations: []wrapsql.WhereOperation{
 				{LeftSide: "Page.ID", Operator: fmt.Sprintf(">= %v", thisPageID)},
 				{LeftSide: "User.guid", Operator: "= ?"},
-				{LeftSide: "Page.deletedAt", Operator: "IS NULL"},
+				{LeftSide: "Page.deletedAt", Operator: getDeletedAtOperator(removed)},
 			},
 		},
 		Limit: limit + 1, // plus one so we can get an extra record to determine the nextBatchID
 	}
-	rows, err := s.db.Query(wrapsql.GetSelectString(statement), userID)
+	rows, err := wrapsql.Select(ctx, s.db, statement, userID)
 	if err != nil {
 		returnErr = err
 		return
@@ -282,7 +364,7 @@ func (s PageStore) GetPages(userID, thisBatchID string, limit int) (pages []page
 	defer rows.Close()
 	for rows.Next() {
 		p := page.Page{}
-		err := rows.Scan(&p.GUID, &p.ID, &p.Version.GUID, &p.PageTemplate.GUID, &p.Title, &p.Summary, &permissionString, &p.CreatedAt, &p.UpdatedAt)
+		err := rows.Scan(&p.GUID, &p.ID, &p.Version.GUID, &p.PageTemplate.GUID, &p.Title, &p.Summary, &permissionString, &p.CreatedAt, &p.UpdatedAt, &p.DeletedAt)
 		if err != nil {
 			returnErr = err
 			return
@@ -303,14 +385,21 @@ func (s PageStore) GetPages(userID, thisBatchID string, limit int) (pages []page
 		nextBatchID = lastPage.GUID
 		pages = pages[:len(pages)-1]
 	}
-	total, err = s.getTotalPages(userID)
+	total, err = s.getTotalPages(ctx, userID, removed)
 	if err != nil {
 		returnErr = err
 	}
 	return
 }
 
-func (s PageStore) getTotalPages(userID string) (int, error) {
+func getDeletedAtOperator(removed bool) string {
+	if removed {
+		return "IS NOT NULL"
+	}
+	return "IS NULL"
+}
+
+func (s PageStore) getTotalPages(ctx context.Context, userID string, removed bool) (int, error) {
 	if userID == "" {
 		return -1, errors.New("must provide userID to get pages")
 	}
@@ -324,11 +413,11 @@ func (s PageStore) getTotalPages(userID string) (int, error) {
 		WhereClause: wrapsql.WhereClause{
 			Operator: "AND", WhereOperations: []wrapsql.WhereOperation{
 				{LeftSide: "User.guid", Operator: "= ?"},
-				{LeftSide: "Page.deletedAt", Operator: "IS NULL"},
+				{LeftSide: "Page.deletedAt", Operator: getDeletedAtOperator(removed)},
 			},
 		},
 	}
-	rows, err := s.db.Query(wrapsql.GetSelectString(statement), userID)
+	rows, err := wrapsql.Select(ctx, s.db, statement, userID)
 	var total int
 	err = wrapsql.GetSingleRow(userID, rows, err, &total)
 	if err != nil {
@@ -337,7 +426,7 @@ func (s PageStore) getTotalPages(userID string) (int, error) {
 	return total, nil
 }
 
-func (s PageStore) getPageID(guid string) (int64, error) {
+func (s PageStore) getPageID(ctx context.Context, guid string) (int64, error) {
 	if guid == "" {
 		return -1, errors.New("must provide guid to get the page id")
 	}
@@ -351,46 +440,258 @@ func (s PageStore) getPageID(guid string) (int64, error) {
 		},
 		Limit: 1,
 	}
-	rows, err := s.db.Query(wrapsql.GetSelectString(statement), guid)
+	rows, err := wrapsql.Select(ctx, s.db, statement, guid)
 	var pageID int64
 	err = wrapsql.GetSingleRow(guid, rows, err, &pageID)
 	return pageID, err
 }
 
 // RemovePage marks the given page and removed by setting the deletedAt property.
-func (s PageStore) RemovePage(guid string) error {
+func (s PageStore) RemovePage(ctx context.Context, guid string) error {
 	t := time.Now()
-	return s.UpdatePage(page.Page{
+	return s.UpdatePage(ctx, page.Page{
 		GUID:      guid,
 		DeletedAt: &t,
 	})
 }
 
+// RestorePage restores the given removed page, along with its properties, by clearing the deletedAt property.
+// If the page is not removed, a storeerror.NotFound will be returned.
+func (s PageStore) RestorePage(ctx context.Context, guid string) error {
+	if guid == "" {
+		return errors.New("must provide guid to restore the page")
+	}
+	if s.db == nil {
+		return &storeerror.DBNotSetUp{}
+	}
+	pageID, err := s.getRemovedPageID(ctx, guid)
+	if err != nil {
+		return err
+	}
+	return wrapsql.ExecSingleUpdate(ctx, s.db, wrapsql.UpdateQuery{
+		UpdateTable: "Page",
+		InjectedValues: wrapsql.InjectedValues{
+			"deletedAt": nil,
+			"updatedAt": time.Now(),
+		},
+		WhereClause: wrapsql.WhereClause{
+			Operator: "AND", WhereOperations: []wrapsql.WhereOperation{
+				{LeftSide: "ID", Operator: "= ?"},
+			},
+		},
+	}, pageID)
+}
+
+// TransferPage makes the user the page's owner in place of its current owner, whether or not the page is removed.
+// If the page or the user doesn't exist, a storeerror.NotFound will be returned.
+func (s PageStore) TransferPage(ctx context.Context, guid, userID string) error {
+	if guid == "" {
+		return errors.New("must provide guid to transfer the page")
+	}
+	if userID == "" {
+		return errors.New("must provide userID to transfer the page")
+	}
+	if s.db == nil {
+		return &storeerror.DBNotSetUp{}
+	}
+	return wrapsql.WithinTransaction(ctx, s.db, func(tx wrapsql.DB) error {
+		pageID, err := PageStore{db: tx}.getPageID(ctx, guid)
+		if err != nil {
+			return err
+		}
+		u, err := UserStore{db: tx}.GetUser(ctx, userID)
+		if err != nil {
+			return err
+		}
+		// the current owner is replaced, and the user's existing row is dropped so they aren't listed twice
+		deletes := []struct {
+			operation wrapsql.WhereOperation
+			args      []interface{}
+		}{
+			{operation: wrapsql.WhereOperation{LeftSide: "isOwner", Operator: "= TRUE"}, args: []interface{}{pageID}},
+			{operation: wrapsql.WhereOperation{LeftSide: "User_ID", Operator: "= ?"}, args: []interface{}{pageID, u.ID}},
+		}
+		for _, d := range deletes {
+			err = wrapsql.ExecDelete(ctx, tx, wrapsql.DeleteQuery{
+				FromTable: "PageOwner",
+				WhereClause: wrapsql.WhereClause{
+					Operator: "AND", WhereOperations: []wrapsql.WhereOperation{
+						{LeftSide: "Page_ID", Operator: "= ?"},
+						d.operation,
+					},
+				},
+			}, d.args...)
+			if err != nil {
+				return errors.Wrap(err, "unable to delete from PageOwner")
+			}
+		}
+		_, err = wrapsql.ExecSingleInsert(ctx, tx, wrapsql.InsertQuery{
+			IntoTable: "PageOwner",
+			InjectedValues: wrapsql.InjectedValues{
+				"Page_ID": pageID,
+				"User_ID": u.ID,
+				"isOwner": true,
+			},
+		})
+		return err
+	})
+}
+
+// PurgePage permanently deletes the given removed page, along with its owners, properties, details, and comments.
+// If the page is not removed, a storeerror.NotFound will be returned.
+func (s PageStore) PurgePage(ctx context.Context, guid string) error {
+	if guid == "" {
+		return errors.New("must provide guid to purge the page")
+	}
+	if s.db == nil {
+		return &storeerror.DBNotSetUp{}
+	}
+	return wrapsql.WithinTransaction(ctx, s.db, func(tx wrapsql.DB) error {
+		txStore := PageStore{db: tx}
+		pageID, err := txStore.getRemovedPageID(ctx, guid)
+		if err != nil {
+			return err
+		}
+		return txStore.purgePages(ctx, []int64{pageID})
+	})
+}
+
+// PurgeRemovedPages permanently deletes every page removed before the given time.
+// Returns the number of pages purged.
+func (s PageStore) PurgeRemovedPages(ctx context.Context, removedBefore time.Time) (int, error) {
+	if s.db == nil {
+		return 0, &storeerror.DBNotSetUp{}
+	}
+	var pageIDs []int64
+	err := wrapsql.WithinTransaction(ctx, s.db, func(tx wrapsql.DB) error {
+		txStore := PageStore{db: tx}
+		var err error
+		pageIDs, err = txStore.getPageIDsRemovedBefore(ctx, removedBefore)
+		if err != nil {
+			return err
+		}
+		return txStore.purgePages(ctx, pageIDs)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(pageIDs), nil
+}
+
+func (s PageStore) getRemovedPageID(ctx context.Context, guid string) (int64, error) {
+	statement := wrapsql.SelectStatement{
+		Selectors: []string{"ID"},
+		FromTable: "Page",
+		WhereClause: wrapsql.WhereClause{
+			Operator: "AND", WhereOperations: []wrapsql.WhereOperation{
+				{LeftSide: "guid", Operator: "= ?"},
+				{LeftSide: "deletedAt", Operator: "IS NOT NULL"},
+			},
+		},
+		Limit: 1,
+	}
+	rows, err := wrapsql.Select(ctx, s.db, statement, guid)
+	var pageID int64
+	err = wrapsql.GetSingleRow(guid, rows, err, &pageID)
+	return pageID, err
+}
+
+func (s PageStore) getPageIDsRemovedBefore(ctx context.Context, removedBefore time.Time) (pageIDs []int64, returnErr error) {
+	statement := wrapsql.SelectStatement{
+		Selectors: []string{"ID"},
+		FromTable: "Page",
+		WhereClause: wrapsql.WhereClause{
+			Operator: "AND", WhereOperations: []wrapsql.WhereOperation{
+				{LeftSide: "deletedAt", Operator: "IS NOT NULL"},
+				{LeftSide: "deletedAt", Operator: "< ?"},
+			},
+		},
+	}
+	rows, err := wrapsql.Select(ctx, s.db, statement, removedBefore)
+	if err != nil {
+		returnErr = err
+		return
+	}
+	if err := rows.Err(); err != nil {
+		returnErr = err
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var pageID int64
+		err := rows.Scan(&pageID)
+		if err != nil {
+			returnErr = err
+			return
+		}
+		pageIDs = append(pageIDs, pageID)
+	}
+	return
+}
+
+func (s PageStore) purgePages(ctx context.Context, pageIDs []int64) error {
+	if len(pageIDs) == 0 {
+		return nil
+	}
+	var args []interface{}
+	for _, pageID := range pageIDs {
+		args = append(args, pageID)
+	}
+	tables := []struct {
+		name   string
+		column string
+	}{
+		{name: "PagePropertyOrder", column: "Page_ID"},
+		{name: "PagePropertyNumber", column: "Page_ID"},
+		{name: "PagePropertyString", column: "Page_ID"},
+		{name: "PageOwner", column: "Page_ID"},
+		{name: "CommentMention", column: "Page_ID"},
+		{name: "Comment", column: "Page_ID"},
+		{name: "PageDetail", column: "Page_ID"},
+		{name: "Page", column: "ID"},
+	}
+	for _, table := range tables {
+		query := wrapsql.DeleteQuery{
+			FromTable: table.name,
+			WhereClause: wrapsql.WhereClause{
+				Operator: "AND", WhereOperations: []wrapsql.WhereOperation{
+					{LeftSide: table.column, Operator: "IN (" + wrapsql.GetNValueStubList(len(pageIDs)) + ")"},
+				},
+			},
+		}
+		err := wrapsql.ExecDelete(ctx, s.db, query, args...)
+		if err != nil {
+			return errors.Wrapf(err, "unable to delete from %v", table.name)
+		}
+	}
+	return nil
+}
+
 // GetUniquePageGUID returns a guid for the page that is guaranteed to be unique or errors.
 // If the proposedPageGuid is not a zero-value and not unique, it will error.
-func (s PageStore) GetUniquePageGUID(proposedPageGUID string) (string, error) {
-	return s.getUniquePageGUID(proposedPageGUID, 0)
+func (s PageStore) GetUniquePageGUID(ctx context.Context, proposedPageGUID string) (string, error) {
+	return s.getUniquePageGUID(ctx, proposedPageGUID, 0)
 }
 
-func (s PageStore) getUniquePageGUID(proposedPageGUID string, retry int) (string, error) {
+func (s PageStore) getUniquePageGUID(ctx context.Context, proposedPageGUID string, retry int) (string, error) {
 	err := guidgen.CheckProposedGUID(proposedPageGUID, "PG", 15)
 	if err != nil {
 		return "", err
 	}
-	return getUniqueGUID(s.db, "PG", 15, "Page", proposedPageGUID, 0)
+	return getUniqueGUID(ctx, s.db, "PG", 15, "Page", proposedPageGUID, 0)
 }
 
 // GetPageProperties returns the page's properties.
-func (s PageStore) GetPageProperties(pageGUID string) (returnProperties []property.Property, returnErr error) {
+func (s PageStore) GetPageProperties(ctx context.Context, pageGUID string) (returnProperties []property.Property, returnErr error) {
 	if pageGUID == "" {
 		returnErr = errors.New("must provide pageGUID to get the page properties")
 		return
 	}
-	stringPP, stringPPOrder, err := s.getStringTypePageProperties(pageGUID)
+	stringPP, stringPPOrder, err := s.getStringTypePageProperties(ctx, pageGUID)
 	if err != nil {
 		returnErr = errors.Wrap(err, "unable to get string type page properties")
 	}
-	numberPP, numberPPOrder, err := s.getNumberTypePageProperties(pageGUID)
+	numberPP, numberPPOrder, err := s.getNumberTypePageProperties(ctx, pageGUID)
 	if err != nil {
 		returnErr = errors.Wrap(err, "unable to get number type page properties")
 	}
@@ -418,7 +719,7 @@ func (s PageStore) GetPageProperties(pageGUID string) (returnProperties []proper
 	return
 }
 
-func (s PageStore) getStringTypePageProperties(pageGUID string) (returnProperties []property.Property, returnPropertiesOrder []int64, returnErr error) {
+func (s PageStore) getStringTypePageProperties(ctx context.Context, pageGUID string) (returnProperties []property.Property, returnPropertiesOrder []int64, returnErr error) {
 	statement := wrapsql.SelectStatement{
 		Selectors: []string{"Property.ID", "Property.type", "Property.key", "PagePropertyString.value", "PagePropertyOrder.order"},
 		FromTable: "Page",
@@ -449,7 +750,7 @@ func (s PageStore) getStringTypePageProperties(pageGUID string) (returnPropertie
 			SortBy: "ASC",
 		},
 	}
-	rows, err := s.db.Query(wrapsql.GetSelectString(statement), pageGUID)
+	rows, err := wrapsql.Select(ctx, s.db, statement, pageGUID)
 	if err != nil {
 		returnErr = err
 		return
@@ -480,7 +781,7 @@ func (s PageStore) getStringTypePageProperties(pageGUID string) (returnPropertie
 	return
 }
 
-func (s PageStore) getNumberTypePageProperties(pageGUID string) (returnProperties []property.Property, returnPropertiesOrder []int64, returnErr error) {
+func (s PageStore) getNumberTypePageProperties(ctx context.Context, pageGUID string) (returnProperties []property.Property, returnPropertiesOrder []int64, returnErr error) {
 	statement := wrapsql.SelectStatement{
 		Selectors: []string{"Property.ID", "Property.type", "Property.key", "PagePropertyNumber.value", "PagePropertyOrder.order"},
 		FromTable: "Page",
@@ -511,7 +812,7 @@ func (s PageStore) getNumberTypePageProperties(pageGUID string) (returnPropertie
 			SortBy: "ASC",
 		},
 	}
-	rows, err := s.db.Query(wrapsql.GetSelectString(statement), pageGUID)
+	rows, err := wrapsql.Select(ctx, s.db, statement, pageGUID)
 	if err != nil {
 		returnErr = err
 		return
@@ -543,55 +844,67 @@ func (s PageStore) getNumberTypePageProperties(pageGUID string) (returnPropertie
 }
 
 // ReplacePageProperties replaces the current page's properties with the new properties.
-func (s PageStore) ReplacePageProperties(pageGUID string, pageProperties []property.Property) error {
-	// @TODO: all this needs to be wrapped into a transaction with rollback.
+func (s PageStore) ReplacePageProperties(ctx context.Context, pageGUID string, pageProperties []property.Property) error {
 	if pageGUID == "" {
 		return errors.New("must provide pageGUID to replace the page properties")
 	}
-	pageID, err := s.getPageID(pageGUID)
+	if s.db == nil {
+		return &storeerror.DBNotSetUp{}
+	}
+	return wrapsql.WithinTransaction(ctx, s.db, func(tx wrapsql.DB) error {
+		return PageStore{db: tx}.replacePageProperties(ctx, pageGUID, pageProperties)
+	})
+}
+
+func (s PageStore) replacePageProperties(ctx context.Context, pageGUID string, pageProperties []property.Property) error {
+	pageID, err := s.getPageID(ctx, pageGUID)
 	if err != nil {
-		return errors.Wrapf(err, "unable to get Page.ID for guid: %v", pageID)
+		return errors.Wrapf(err, "unable to get Page.ID for guid: %v", pageGUID)
 	}
-	err = s.setPagePropertyIDs(pageProperties)
+	err = s.setPagePropertyIDs(ctx, pageProperties)
 	if err != nil {
 		return errors.Wrap(err, "unable to get Property.ID for the pageProperties")
 	}
-	err = s.deletePageProperties(pageID)
+	err = s.deletePageProperties(ctx, pageID)
 	if err != nil {
 		return errors.Wrap(err, "unable to delete page properties")
 	}
-	err = s.addPagePropertyOrders(pageID, pageProperties)
+	err = s.addPagePropertyOrders(ctx, pageID, pageProperties)
 	if err != nil {
 		return errors.Wrap(err, "unable to add page properties orders")
 	}
-	err = s.addTypedPageProperties(pageID, pageProperties, property.TypeNumber)
+	err = s.addTypedPageProperties(ctx, pageID, pageProperties, property.TypeNumber)
 	if err != nil {
 		return errors.Wrap(err, "unable to add number type page properties")
 	}
-	err = s.addTypedPageProperties(pageID, pageProperties, property.TypeString)
+	err = s.addTypedPageProperties(ctx, pageID, pageProperties, property.TypeString)
 	if err != nil {
 		return errors.Wrap(err, "unable to add string type page properties")
 	}
 	return nil
 }
 
-func (s PageStore) addPagePropertyOrders(pageID int64, pageProperties []property.Property) error {
+func (s PageStore) addPagePropertyOrders(ctx context.Context, pageID int64, pageProperties []property.Property) error {
+	if len(pageProperties) == 0 {
+		return nil
+	}
 	query := wrapsql.BatchInsertQuery{
-		IntoTable: "PagePropertyOrder",
+		IntoTable:           "PagePropertyOrder",
+		BatchInjectedValues: wrapsql.BatchInjectedValues{},
 	}
 	for i, pageProperty := range pageProperties {
 		query.BatchInjectedValues["Page_ID"] = append(query.BatchInjectedValues["Page_ID"], pageID)
 		query.BatchInjectedValues["Property_ID"] = append(query.BatchInjectedValues["Property_ID"], pageProperty.ID)
 		query.BatchInjectedValues["order"] = append(query.BatchInjectedValues["order"], i)
 	}
-	err := wrapsql.ExecBatchInsert(s.db, query)
+	err := wrapsql.ExecBatchInsert(ctx, s.db, query)
 	if err != nil {
 		return errors.Wrap(err, "unable to insert page property order")
 	}
 	return nil
 }
 
-func (s PageStore) addTypedPageProperties(pageID int64, pageProperties []property.Property, propertyType property.Type) error {
+func (s PageStore) addTypedPageProperties(ctx context.Context, pageID int64, pageProperties []property.Property, propertyType property.Type) error {
 	scopedPageProperties := getTypedProperties(pageProperties, propertyType)
 	if len(scopedPageProperties) == 0 {
 		return nil
@@ -607,7 +920,8 @@ func (s PageStore) addTypedPageProperties(pageID int64, pageProperties []propert
 		return errors.Errorf("unsupported page property type for instert: %v", propertyType)
 	}
 	query := wrapsql.BatchInsertQuery{
-		IntoTable: tableName,
+		IntoTable:           tableName,
+		BatchInjectedValues: wrapsql.BatchInjectedValues{},
 	}
 	for _, pageProperty := range scopedPageProperties {
 		query.BatchInjectedValues["Page_ID"] = append(query.BatchInjectedValues["Page_ID"], pageID)
@@ -621,7 +935,7 @@ func (s PageStore) addTypedPageProperties(pageID int64, pageProperties []propert
 		query.BatchInjectedValues["createdAt"] = append(query.BatchInjectedValues["createdAt"], t)
 		query.BatchInjectedValues["updatedAt"] = append(query.BatchInjectedValues["updatedAt"], t)
 	}
-	err := wrapsql.ExecBatchInsert(s.db, query)
+	err := wrapsql.ExecBatchInsert(ctx, s.db, query)
 	if err != nil {
 		return errors.Wrap(err, "unable to insert page property order")
 	}
@@ -638,7 +952,7 @@ func getTypedProperties(properties []property.Property, propertyType property.Ty
 	return
 }
 
-func (s PageStore) deletePageProperties(pageID int64) error {
+func (s PageStore) deletePageProperties(ctx context.Context, pageID int64) error {
 	genericWhereClause := wrapsql.WhereClause{
 		Operator: "AND", WhereOperations: []wrapsql.WhereOperation{
 			{LeftSide: "Page_ID", Operator: "= ?"},
@@ -648,7 +962,7 @@ func (s PageStore) deletePageProperties(pageID int64) error {
 		FromTable:   "PagePropertyOrder",
 		WhereClause: genericWhereClause,
 	}
-	err := wrapsql.ExecDelete(s.db, query, pageID)
+	err := wrapsql.ExecDelete(ctx, s.db, query, pageID)
 	if err != nil {
 		return errors.Wrap(err, "unable to delete from PagePropertyOrder")
 	}
@@ -656,7 +970,7 @@ func (s PageStore) deletePageProperties(pageID int64) error {
 		FromTable:   "PagePropertyNumber",
 		WhereClause: genericWhereClause,
 	}
-	err = wrapsql.ExecDelete(s.db, query, pageID)
+	err = wrapsql.ExecDelete(ctx, s.db, query, pageID)
 	if err != nil {
 		return errors.Wrap(err, "unable to delete from PagePropertyNumber")
 	}
@@ -664,19 +978,22 @@ func (s PageStore) deletePageProperties(pageID int64) error {
 		FromTable:   "PagePropertyString",
 		WhereClause: genericWhereClause,
 	}
-	err = wrapsql.ExecDelete(s.db, query, pageID)
+	err = wrapsql.ExecDelete(ctx, s.db, query, pageID)
 	if err != nil {
 		return errors.Wrap(err, "unable to delete from PagePropertyString")
 	}
 	return nil
 }
 
-func (s PageStore) setPagePropertyIDs(pageProperties []property.Property) error {
+func (s PageStore) setPagePropertyIDs(ctx context.Context, pageProperties []property.Property) error {
+	if len(pageProperties) == 0 {
+		return nil
+	}
 	var keys []string
 	for _, p := range pageProperties {
 		keys = append(keys, p.Key)
 	}
-	pps, err := s.getPropertyIDs(keys)
+	pps, err := s.getPropertyIDs(ctx, keys)
 	if err != nil {
 		return err
 	}
@@ -695,7 +1012,7 @@ func (s PageStore) setPagePropertyIDs(pageProperties []property.Property) error
 	return nil
 }
 
-func (s PageStore) getPropertyIDs(propertyKeys []string) (returnProperties []property.Property, returnErr error) {
+func (s PageStore) getPropertyIDs(ctx context.Context, propertyKeys []string) (returnProperties []property.Property, returnErr error) {
 	for i, propertyKey := range propertyKeys {
 		if propertyKey == "" {
 			return nil, errors.Errorf("property key at %v must be non-zero value", i)
@@ -710,7 +1027,11 @@ func (s PageStore) getPropertyIDs(propertyKeys []string) (returnProperties []pro
 			},
 		},
 	}
-	rows, err := s.db.Query(wrapsql.GetSelectString(statement), propertyKeys)
+	args := make([]interface{}, 0, len(propertyKeys))
+	for _, propertyKey := range propertyKeys {
+		args = append(args, propertyKey)
+	}
+	rows, err := wrapsql.Select(ctx, s.db, statement, args...)
 	if err != nil {
 		returnErr = err
 		return
@@ -1,9 +1,10 @@
 package mysqlstore
 
 import (
-	"database/sql"
+	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/worlve/sp-service/internal/models/pagetemplate"
 	"github.com/worlve/sp-service/internal/models/permission"
@@ -13,10 +14,11 @@ import (
 	"github.com/worlve/sp-service/internal/models/page"
 	"github.com/worlve/sp-service/internal/models/version"
 	"github.com/worlve/sp-service/internal/util/testutils"
+	"github.com/worlve/sp-service/internal/util/wrapsql"
 	"github.com/stretchr/testify/require"
 )
 
-func testPageStoreClearAllTables(db *sql.DB) error {
+func testPageStoreClearAllTables(db wrapsql.DB) error {
 	tables := []string{"Page", "PageOwner", "PageTemplate", "User", "Version"}
 	for _, table := range tables {
 		err := clearTableForTest(db, table)
@@ -28,6 +30,7 @@ func testPageStoreClearAllTables(db *sql.DB) error {
 }
 
 func TestCreatePage(t *testing.T) {
+	ctx := context.Background()
 	cases := []struct {
 		name                   string
 		shouldReplaceDBWithNil bool
@@ -90,7 +93,7 @@ func TestCreatePage(t *testing.T) {
 			if tc.shouldReplaceDBWithNil {
 				pageStore.db = nil
 			}
-			result, err := pageStore.CreatePage(tc.paramRecord, tc.paramOwnerID)
+			result, err := pageStore.CreatePage(ctx, tc.paramRecord, tc.paramOwnerID)
 			errExpected := testutils.TestErrorAgainstCase(t, err, tc.returnErr)
 			if errExpected {
 				return
@@ -99,13 +102,13 @@ func TestCreatePage(t *testing.T) {
 			result.UpdatedAt = nil
 			result.DeletedAt = nil
 			require.Equal(t, tc.returnPage, result)
-			p, err := pageStore.GetPage(tc.expectedPageGUID)
+			p, err := pageStore.GetPage(ctx, tc.expectedPageGUID)
 			require.NoError(t, err)
 			p.CreatedAt = nil
 			p.UpdatedAt = nil
 			p.DeletedAt = nil
 			require.Equal(t, tc.expectedDPPage, p)
-			isOwner, err := pageStore.CanEditPage(tc.expectedPageGUID, tc.expectedOwnerGUID)
+			isOwner, err := pageStore.CanEditPage(ctx, tc.expectedPageGUID, tc.expectedOwnerGUID)
 			require.NoError(t, err)
 			require.Equal(t, true, isOwner)
 		})
@@ -113,6 +116,7 @@ func TestCreatePage(t *testing.T) {
 }
 
 func TestCanEditPage(t *testing.T) {
+	ctx := context.Background()
 	cases := []struct {
 		name                   string
 		shouldReplaceDBWithNil bool
@@ -146,7 +150,7 @@ func TestCanEditPage(t *testing.T) {
 			if tc.shouldReplaceDBWithNil {
 				pageStore.db = nil
 			}
-			canEdit, err := pageStore.CanEditPage(tc.paramGUID, tc.paramUserID)
+			canEdit, err := pageStore.CanEditPage(ctx, tc.paramGUID, tc.paramUserID)
 			errExpected := testutils.TestErrorAgainstCase(t, err, tc.returnErr)
 			if errExpected {
 				return
@@ -157,6 +161,7 @@ func TestCanEditPage(t *testing.T) {
 }
 
 func TestCanReadPage(t *testing.T) {
+	ctx := context.Background()
 	cases := []struct {
 		name                   string
 		shouldReplaceDBWithNil bool
@@ -214,7 +219,7 @@ func TestCanReadPage(t *testing.T) {
 			if tc.shouldReplaceDBWithNil {
 				pageStore.db = nil
 			}
-			canRead, err := pageStore.CanReadPage(tc.paramGUID, tc.paramUserID)
+			canRead, err := pageStore.CanReadPage(ctx, tc.paramGUID, tc.paramUserID)
 			errExpected := testutils.TestErrorAgainstCase(t, err, tc.returnErr)
 			if errExpected {
 				return
@@ -225,6 +230,7 @@ func TestCanReadPage(t *testing.T) {
 }
 
 func TestUpdatePage(t *testing.T) {
+	ctx := context.Background()
 	cases := []struct {
 		name                   string
 		shouldReplaceDBWithNil bool
@@ -275,12 +281,12 @@ func TestUpdatePage(t *testing.T) {
 			if tc.shouldReplaceDBWithNil {
 				pageStore.db = nil
 			}
-			err = pageStore.UpdatePage(tc.paramRecord)
+			err = pageStore.UpdatePage(ctx, tc.paramRecord)
 			errExpected := testutils.TestErrorAgainstCase(t, err, tc.returnErr)
 			if errExpected {
 				return
 			}
-			p, err := pageStore.GetPage(tc.expectedPageGUID)
+			p, err := pageStore.GetPage(ctx, tc.expectedPageGUID)
 			require.NoError(t, err)
 			p.CreatedAt = nil
 			p.UpdatedAt = nil
@@ -291,6 +297,7 @@ func TestUpdatePage(t *testing.T) {
 }
 
 func TestGetPage(t *testing.T) {
+	ctx := context.Background()
 	cases := []struct {
 		name                   string
 		shouldReplaceDBWithNil bool
@@ -334,7 +341,7 @@ func TestGetPage(t *testing.T) {
 			if tc.shouldReplaceDBWithNil {
 				pageStore.db = nil
 			}
-			p, err := pageStore.GetPage(tc.paramGUID)
+			p, err := pageStore.GetPage(ctx, tc.paramGUID)
 			errExpected := testutils.TestErrorAgainstCase(t, err, tc.returnErr)
 			if errExpected {
 				return
@@ -348,6 +355,7 @@ func TestGetPage(t *testing.T) {
 }
 
 func TestGetPages(t *testing.T) {
+	ctx := context.Background()
 	cases := []struct {
 		name                   string
 		shouldReplaceDBWithNil bool
@@ -445,7 +453,7 @@ func TestGetPages(t *testing.T) {
 			if tc.shouldReplaceDBWithNil {
 				pageStore.db = nil
 			}
-			pages, total, nextBatchID, err := pageStore.GetPages(tc.paramUserID, tc.paramThisBatchID, tc.paramLimit)
+			pages, total, nextBatchID, err := pageStore.GetPages(ctx, tc.paramUserID, tc.paramThisBatchID, tc.paramLimit)
 			errExpected := testutils.TestErrorAgainstCase(t, err, tc.returnErr)
 			if errExpected {
 				return
@@ -463,6 +471,7 @@ func TestGetPages(t *testing.T) {
 }
 
 func TestRemovePage(t *testing.T) {
+	ctx := context.Background()
 	cases := []struct {
 		name                   string
 		shouldReplaceDBWithNil bool
@@ -495,7 +504,7 @@ func TestRemovePage(t *testing.T) {
 			if tc.shouldReplaceDBWithNil {
 				pageStore.db = nil
 			}
-			err = pageStore.RemovePage(tc.paramGUID)
+			err = pageStore.RemovePage(ctx, tc.paramGUID)
 			errExpected := testutils.TestErrorAgainstCase(t, err, tc.returnErr)
 			if errExpected {
 				return
@@ -503,7 +512,7 @@ func TestRemovePage(t *testing.T) {
 			if tc.expectedPageGUID == "" {
 				return
 			}
-			_, err = pageStore.GetPage(tc.expectedPageGUID)
+			_, err = pageStore.GetPage(ctx, tc.expectedPageGUID)
 			if _, ok := err.(*storeerror.NotFound); !ok {
 				t.Fatalf("Page %v was not deleted", tc.expectedPageGUID)
 			}
@@ -511,7 +520,167 @@ func TestRemovePage(t *testing.T) {
 	}
 }
 
+func TestRestorePage(t *testing.T) {
+	ctx := context.Background()
+	cases := []struct {
+		name                   string
+		shouldReplaceDBWithNil bool
+		preTestQueries         []string
+		paramGUID              string
+		returnErr              error
+	}{
+		{
+			name: "happy path",
+			preTestQueries: []string{
+				"INSERT INTO Page (`Version_ID`, `PageTemplate_ID`, `guid`, `title`, `summary`, `permission`, `createdAt`, `updatedAt`, `deletedAt`) VALUES( 1, 1, \"PG_1\", \"original title\", \"\", \"PR\", NOW(), NOW(), NOW() )",
+				"INSERT INTO Version (`guid`, `name`, `createdAt`, `updatedAt`) VALUES( \"VR_1\", \"TEST_VERSION\", NOW(), NOW())",
+				"INSERT INTO PageTemplate (`Version_ID`, `guid`, `name`, `hasProperties`, `hasDetails`, `hasRelations`, `createdAt`, `updatedAt`) VALUES(1, \"PGT_1\", \"TEST_TEMPLATE\", true, true, true, NOW(), NOW())",
+			},
+			paramGUID: "PG_1",
+		},
+		{
+			name: "page is not removed",
+			preTestQueries: []string{
+				"INSERT INTO Page (`Version_ID`, `PageTemplate_ID`, `guid`, `title`, `summary`, `permission`, `createdAt`, `updatedAt`) VALUES( 1, 1, \"PG_1\", \"original title\", \"\", \"PR\", NOW(), NOW() )",
+			},
+			paramGUID: "PG_1",
+			returnErr: &storeerror.NotFound{ID: "PG_1"},
+		},
+		{
+			name:                   "db not set up",
+			shouldReplaceDBWithNil: true,
+			paramGUID:              "PG_1",
+			returnErr:              &storeerror.DBNotSetUp{},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			pageStore := PageStore{
+				db: mysqldb,
+			}
+			err := testPageStoreClearAllTables(pageStore.db)
+			require.NoError(t, err)
+			err = execPreTestQueries(pageStore.db, tc.preTestQueries)
+			require.NoError(t, err)
+			if tc.shouldReplaceDBWithNil {
+				pageStore.db = nil
+			}
+			err = pageStore.RestorePage(ctx, tc.paramGUID)
+			errExpected := testutils.TestErrorAgainstCase(t, err, tc.returnErr)
+			if errExpected {
+				return
+			}
+			p, err := pageStore.GetPage(ctx, tc.paramGUID)
+			require.NoError(t, err)
+			require.Nil(t, p.DeletedAt)
+		})
+	}
+}
+
+func TestPurgePage(t *testing.T) {
+	ctx := context.Background()
+	cases := []struct {
+		name                   string
+		shouldReplaceDBWithNil bool
+		preTestQueries         []string
+		paramGUID              string
+		returnErr              error
+	}{
+		{
+			name: "happy path",
+			preTestQueries: []string{
+				"INSERT INTO Page (`Version_ID`, `PageTemplate_ID`, `guid`, `title`, `summary`, `permission`, `createdAt`, `updatedAt`, `deletedAt`) VALUES( 1, 1, \"PG_1\", \"original title\", \"\", \"PR\", NOW(), NOW(), NOW() )",
+				"INSERT INTO PageOwner (`Page_ID`, `User_ID`, `isOwner`) VALUES( 1, 1, true)",
+			},
+			paramGUID: "PG_1",
+		},
+		{
+			name: "page is not removed",
+			preTestQueries: []string{
+				"INSERT INTO Page (`Version_ID`, `PageTemplate_ID`, `guid`, `title`, `summary`, `permission`, `createdAt`, `updatedAt`) VALUES( 1, 1, \"PG_1\", \"original title\", \"\", \"PR\", NOW(), NOW() )",
+				"INSERT INTO PageOwner (`Page_ID`, `User_ID`, `isOwner`) VALUES( 1, 1, true)",
+			},
+			paramGUID: "PG_1",
+			returnErr: &storeerror.NotFound{ID: "PG_1"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			pageStore := PageStore{
+				db: mysqldb,
+			}
+			err := testPageStoreClearAllTables(pageStore.db)
+			require.NoError(t, err)
+			err = execPreTestQueries(pageStore.db, tc.preTestQueries)
+			require.NoError(t, err)
+			if tc.shouldReplaceDBWithNil {
+				pageStore.db = nil
+			}
+			err = pageStore.PurgePage(ctx, tc.paramGUID)
+			errExpected := testutils.TestErrorAgainstCase(t, err, tc.returnErr)
+			if errExpected {
+				return
+			}
+			_, err = pageStore.getPageID(ctx, tc.paramGUID)
+			if _, ok := err.(*storeerror.NotFound); !ok {
+				t.Fatalf("Page %v was not purged", tc.paramGUID)
+			}
+			var pageOwnerCount int
+			rows, err := mysqldb.Query("SELECT COUNT(1) FROM PageOwner")
+			err = wrapsql.GetSingleRow("", rows, err, &pageOwnerCount)
+			require.NoError(t, err)
+			require.Equal(t, 0, pageOwnerCount)
+		})
+	}
+}
+
+func TestPurgeRemovedPages(t *testing.T) {
+	ctx := context.Background()
+	cases := []struct {
+		name              string
+		preTestQueries    []string
+		paramRemovedDays  int
+		expectedPageGUIDs []string
+		returnCount       int
+		returnErr         error
+	}{
+		{
+			name: "happy path, only purges pages removed before the retention period",
+			preTestQueries: []string{
+				"INSERT INTO Page (`Version_ID`, `PageTemplate_ID`, `guid`, `title`, `summary`, `permission`, `createdAt`, `updatedAt`, `deletedAt`) VALUES( 1, 1, \"PG_1\", \"old title\", \"\", \"PR\", NOW(), NOW(), NOW() - INTERVAL 40 DAY )",
+				"INSERT INTO Page (`Version_ID`, `PageTemplate_ID`, `guid`, `title`, `summary`, `permission`, `createdAt`, `updatedAt`, `deletedAt`) VALUES( 1, 1, \"PG_2\", \"recent title\", \"\", \"PR\", NOW(), NOW(), NOW() - INTERVAL 1 DAY )",
+				"INSERT INTO Page (`Version_ID`, `PageTemplate_ID`, `guid`, `title`, `summary`, `permission`, `createdAt`, `updatedAt`) VALUES( 1, 1, \"PG_3\", \"active title\", \"\", \"PR\", NOW(), NOW() )",
+			},
+			paramRemovedDays:  30,
+			expectedPageGUIDs: []string{"PG_2", "PG_3"},
+			returnCount:       1,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			pageStore := PageStore{
+				db: mysqldb,
+			}
+			err := testPageStoreClearAllTables(pageStore.db)
+			require.NoError(t, err)
+			err = execPreTestQueries(pageStore.db, tc.preTestQueries)
+			require.NoError(t, err)
+			count, err := pageStore.PurgeRemovedPages(ctx, time.Now().AddDate(0, 0, -tc.paramRemovedDays))
+			errExpected := testutils.TestErrorAgainstCase(t, err, tc.returnErr)
+			if errExpected {
+				return
+			}
+			require.Equal(t, tc.returnCount, count)
+			for _, guid := range tc.expectedPageGUIDs {
+				_, err = pageStore.getPageID(ctx, guid)
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
 func TestGetUniquePageGUID(t *testing.T) {
+	ctx := context.Background()
 	cases := []struct {
 		name                   string
 		shouldReplaceDBWithNil bool
@@ -546,7 +715,7 @@ func TestGetUniquePageGUID(t *testing.T) {
 				"INSERT INTO Page (`Version_ID`, `PageTemplate_ID`, `guid`, `title`, `summary`, `permission`, `createdAt`, `updatedAt`) VALUES( 1, 1, \"PG_123456789012\", \"original title\", \"\", \"PR\", NOW(), NOW() )",
 			},
 			paramProposedPageGUID: "PG_123456789012",
-			returnErr:             errors.New("the proposed guid PG_123456789012 already exists"),
+			returnErr:             errors.New("Duplicate id: PG_123456789012\nthe proposed guid PG_123456789012 already exists"),
 		},
 	}
 	for _, tc := range cases {
@@ -561,7 +730,7 @@ func TestGetUniquePageGUID(t *testing.T) {
 			if tc.shouldReplaceDBWithNil {
 				pageStore.db = nil
 			}
-			result, err := pageStore.GetUniquePageGUID(tc.paramProposedPageGUID)
+			result, err := pageStore.GetUniquePageGUID(ctx, tc.paramProposedPageGUID)
 			errExpected := testutils.TestErrorAgainstCase(t, err, tc.returnErr)
 			if errExpected {
 				return
@@ -573,6 +742,7 @@ func TestGetUniquePageGUID(t *testing.T) {
 }
 
 func TestGetPageProperties(t *testing.T) {
+	ctx := context.Background()
 	cases := []struct {
 		name                   string
 		shouldReplaceDBWithNil bool
@@ -623,7 +793,7 @@ func TestGetPageProperties(t *testing.T) {
 			if tc.shouldReplaceDBWithNil {
 				pageStore.db = nil
 			}
-			pageProperties, err := pageStore.GetPageProperties(tc.paramPageGUID)
+			pageProperties, err := pageStore.GetPageProperties(ctx, tc.paramPageGUID)
 			errExpected := testutils.TestErrorAgainstCase(t, err, tc.returnErr)
 			if errExpected {
 				return
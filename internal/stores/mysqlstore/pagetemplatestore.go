@@ -1,6 +1,7 @@
 package mysqlstore
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 
@@ -12,7 +13,7 @@ import (
 
 // PageTemplateStore is the mysql for pagetemplates
 type PageTemplateStore struct {
-	db *sql.DB
+	db wrapsql.DB
 }
 
 // NewPageTemplateStore returns a PageTemplateStore
@@ -23,7 +24,7 @@ func NewPageTemplateStore(mysqldb *sql.DB) PageTemplateStore {
 }
 
 // GetPageTemplate returns the given pagetemplate.
-func (s PageTemplateStore) GetPageTemplate(guid string) (pagetemplate.PageTemplate, error) {
+func (s PageTemplateStore) GetPageTemplate(ctx context.Context, guid string) (pagetemplate.PageTemplate, error) {
 	if guid == "" {
 		return pagetemplate.PageTemplate{}, errors.New("must provide guid to get the pageTemplate")
 	}
@@ -41,7 +42,7 @@ func (s PageTemplateStore) GetPageTemplate(guid string) (pagetemplate.PageTempla
 		},
 		Limit: 1,
 	}
-	rows, err := s.db.Query(wrapsql.GetSelectString(statement), guid)
+	rows, err := wrapsql.Select(ctx, s.db, statement, guid)
 	var pageTemplate pagetemplate.PageTemplate
 	err = wrapsql.GetSingleRow(guid, rows, err, &pageTemplate.ID, &pageTemplate.GUID, &pageTemplate.Name)
 	return pageTemplate, err
@@ -1,16 +1,17 @@
 package mysqlstore
 
 import (
-	"database/sql"
+	"context"
 	"testing"
 
 	"github.com/worlve/sp-service/internal/models/pagetemplate"
 
 	"github.com/worlve/sp-service/internal/util/testutils"
+	"github.com/worlve/sp-service/internal/util/wrapsql"
 	"github.com/stretchr/testify/require"
 )
 
-func testPageTemplateStoreClearAllTables(db *sql.DB) error {
+func testPageTemplateStoreClearAllTables(db wrapsql.DB) error {
 	tables := []string{"PageTemplate"}
 	for _, table := range tables {
 		err := clearTableForTest(db, table)
@@ -22,6 +23,7 @@ func testPageTemplateStoreClearAllTables(db *sql.DB) error {
 }
 
 func TestGetPageTemplate(t *testing.T) {
+	ctx := context.Background()
 	cases := []struct {
 		name                   string
 		shouldReplaceDBWithNil bool
@@ -55,7 +57,7 @@ func TestGetPageTemplate(t *testing.T) {
 			if tc.shouldReplaceDBWithNil {
 				pageTemplateStore.db = nil
 			}
-			result, err := pageTemplateStore.GetPageTemplate(tc.paramGUID)
+			result, err := pageTemplateStore.GetPageTemplate(ctx, tc.paramGUID)
 			errExpected := testutils.TestErrorAgainstCase(t, err, tc.returnErr)
 			if errExpected {
 				return
@@ -0,0 +1,24 @@
+package mysqlstore
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+// CheckPoolSaturation returns a readiness check that errors once the share of the pool's connection limit in use
+// reaches maxSaturation. Pools without a limit are never saturated.
+func CheckPoolSaturation(mysqldb *sql.DB, maxSaturation float64) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		stats := mysqldb.Stats()
+		if stats.MaxOpenConnections <= 0 {
+			return nil
+		}
+		saturation := float64(stats.InUse) / float64(stats.MaxOpenConnections)
+		if saturation >= maxSaturation {
+			return errors.Errorf("%v of %v connections in use", stats.InUse, stats.MaxOpenConnections)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,47 @@
+package mysqlstore
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/worlve/sp-service/internal/stores/store"
+	"github.com/worlve/sp-service/internal/stores/storeerror"
+	"github.com/worlve/sp-service/internal/util/wrapsql"
+)
+
+// UnitOfWork is the mysql for running multiple store calls within a single transaction
+type UnitOfWork struct {
+	db *sql.DB
+}
+
+// NewUnitOfWork returns a UnitOfWork
+func NewUnitOfWork(mysqldb *sql.DB) UnitOfWork {
+	return UnitOfWork{
+		db: mysqldb,
+	}
+}
+
+// Do runs fn with stores bound to a single transaction.
+// The transaction is committed if fn returns nil and rolled back otherwise.
+func (u UnitOfWork) Do(ctx context.Context, fn func(stores store.Stores) error) error {
+	if u.db == nil {
+		return &storeerror.DBNotSetUp{}
+	}
+	return wrapsql.WithinTransaction(ctx, u.db, func(tx wrapsql.DB) error {
+		return fn(newStores(tx))
+	})
+}
+
+func newStores(db wrapsql.DB) store.Stores {
+	return store.Stores{
+		PageStore:         PageStore{db: db},
+		PageDetailStore:   PageDetailStore{db: db},
+		PageTemplateStore: PageTemplateStore{db: db},
+		UserStore:         UserStore{db: db},
+		VersionStore:      VersionStore{db: db},
+		WebhookStore:      WebhookStore{db: db},
+		EventStore:        EventStore{db: db},
+		AuditStore:        AuditStore{db: db},
+		CommentStore:      CommentStore{db: db},
+	}
+}
@@ -0,0 +1,125 @@
+package mysqlstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/worlve/sp-service/internal/models/page"
+	"github.com/worlve/sp-service/internal/models/pagetemplate"
+	"github.com/worlve/sp-service/internal/models/permission"
+	"github.com/worlve/sp-service/internal/models/property"
+	"github.com/worlve/sp-service/internal/models/version"
+	"github.com/worlve/sp-service/internal/stores/store"
+	"github.com/worlve/sp-service/internal/stores/storeerror"
+	"github.com/worlve/sp-service/internal/util/testutils"
+	"github.com/worlve/sp-service/internal/util/wrapsql"
+	"github.com/stretchr/testify/require"
+)
+
+func testUnitOfWorkClearAllTables(db wrapsql.DB) error {
+	tables := []string{"Page", "PageOwner", "PageTemplate", "User", "Version", "Property", "PagePropertyOrder", "PagePropertyNumber", "PagePropertyString"}
+	for _, table := range tables {
+		err := clearTableForTest(db, table)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestUnitOfWorkDo(t *testing.T) {
+	ctx := context.Background()
+	cases := []struct {
+		name                   string
+		shouldReplaceDBWithNil bool
+		preTestQueries         []string
+		fnErr                  error
+		expectedPageErr        error
+		expectedProperties     []property.Property
+		returnErr              error
+	}{
+		{
+			name: "happy path, commits every change",
+			preTestQueries: []string{
+				"INSERT INTO User (`guid`, `email`, `createdAt`, `updatedAt`) VALUES( \"UR_1\", \"bob@test.com\", NOW(), NOW())",
+				"INSERT INTO Version (`guid`, `name`, `createdAt`, `updatedAt`) VALUES( \"VR_1\", \"TEST_VERSION\", NOW(), NOW())",
+				"INSERT INTO PageTemplate (`Version_ID`, `guid`, `name`, `hasProperties`, `hasDetails`, `hasRelations`, `createdAt`, `updatedAt`) VALUES(1, \"PGT_1\", \"TEST_TEMPLATE\", true, true, true, NOW(), NOW())",
+				"INSERT INTO Property (`Version_ID`, `type`, `key`, `createdAt`, `updatedAt`) VALUES( 1, \"ST\", \"color\", NOW(), NOW())",
+			},
+			expectedProperties: []property.Property{
+				property.Property{ID: 1, Key: "color", Type: property.TypeString, Value: "blue"},
+			},
+		},
+		{
+			name: "failure rolls back every change",
+			preTestQueries: []string{
+				"INSERT INTO User (`guid`, `email`, `createdAt`, `updatedAt`) VALUES( \"UR_1\", \"bob@test.com\", NOW(), NOW())",
+				"INSERT INTO Version (`guid`, `name`, `createdAt`, `updatedAt`) VALUES( \"VR_1\", \"TEST_VERSION\", NOW(), NOW())",
+				"INSERT INTO PageTemplate (`Version_ID`, `guid`, `name`, `hasProperties`, `hasDetails`, `hasRelations`, `createdAt`, `updatedAt`) VALUES(1, \"PGT_1\", \"TEST_TEMPLATE\", true, true, true, NOW(), NOW())",
+				"INSERT INTO Property (`Version_ID`, `type`, `key`, `createdAt`, `updatedAt`) VALUES( 1, \"ST\", \"color\", NOW(), NOW())",
+			},
+			fnErr:           errors.New("failed after the last store call"),
+			expectedPageErr: &storeerror.NotFound{ID: "PG_1"},
+			returnErr:       errors.New("failed after the last store call"),
+		},
+		{
+			name:                   "db not set up",
+			shouldReplaceDBWithNil: true,
+			returnErr:              &storeerror.DBNotSetUp{},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			unitOfWork := UnitOfWork{
+				db: mysqldb,
+			}
+			err := testUnitOfWorkClearAllTables(unitOfWork.db)
+			require.NoError(t, err)
+			err = execPreTestQueries(unitOfWork.db, tc.preTestQueries)
+			require.NoError(t, err)
+			if tc.shouldReplaceDBWithNil {
+				unitOfWork.db = nil
+			}
+			err = unitOfWork.Do(ctx, func(stores store.Stores) error {
+				_, err := stores.PageStore.CreatePage(ctx, page.Page{
+					GUID:           "PG_1",
+					Title:          "new title",
+					Version:        version.Version{ID: 1, GUID: "VR_1"},
+					PermissionType: permission.TypePrivate,
+					PageTemplate:   pagetemplate.PageTemplate{ID: 1, GUID: "PGT_1"},
+				}, 1)
+				if err != nil {
+					return err
+				}
+				err = stores.PageStore.ReplacePageProperties(ctx, "PG_1", []property.Property{
+					property.Property{Key: "color", Type: property.TypeString, Value: "blue"},
+				})
+				if err != nil {
+					return err
+				}
+				return tc.fnErr
+			})
+			errExpected := testutils.TestErrorAgainstCase(t, err, tc.returnErr)
+			if tc.shouldReplaceDBWithNil {
+				return
+			}
+			pageStore := PageStore{
+				db: mysqldb,
+			}
+			_, err = pageStore.GetPage(ctx, "PG_1")
+			testutils.TestErrorAgainstCase(t, err, tc.expectedPageErr)
+			if errExpected {
+				var pageOwnerCount int
+				rows, err := mysqldb.Query("SELECT COUNT(1) FROM PageOwner")
+				err = wrapsql.GetSingleRow("", rows, err, &pageOwnerCount)
+				require.NoError(t, err)
+				require.Equal(t, 0, pageOwnerCount)
+				return
+			}
+			properties, err := pageStore.GetPageProperties(ctx, "PG_1")
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedProperties, properties)
+		})
+	}
+}
@@ -1,10 +1,13 @@
 package mysqlstore
 
 import (
+	"context"
 	"database/sql"
 	"errors"
+	"time"
 
 	"github.com/worlve/sp-service/internal/models/appuser"
+	"github.com/worlve/sp-service/internal/util/guidgen"
 	"github.com/worlve/sp-service/internal/util/wrapsql"
 
 	"github.com/worlve/sp-service/internal/stores/storeerror"
@@ -12,7 +15,7 @@ import (
 
 // UserStore is the mysql for versions
 type UserStore struct {
-	db *sql.DB
+	db wrapsql.DB
 }
 
 // NewUserStore returns a UserStore
@@ -23,7 +26,7 @@ func NewUserStore(mysqldb *sql.DB) UserStore {
 }
 
 // GetUser returns the given appuser.
-func (s UserStore) GetUser(guid string) (appuser.User, error) {
+func (s UserStore) GetUser(ctx context.Context, guid string) (appuser.User, error) {
 	if guid == "" {
 		return appuser.User{}, errors.New("must provide guid to get the user")
 	}
@@ -41,8 +44,49 @@ func (s UserStore) GetUser(guid string) (appuser.User, error) {
 		},
 		Limit: 1,
 	}
-	rows, err := s.db.Query(wrapsql.GetSelectString(statement), guid)
+	rows, err := wrapsql.Select(ctx, s.db, statement, guid)
 	var u appuser.User
 	err = wrapsql.GetSingleRow(guid, rows, err, &u.ID, &u.GUID, &u.Email)
 	return u, err
 }
+
+// CreateUser creates a new user.
+func (s UserStore) CreateUser(ctx context.Context, record appuser.User) (appuser.User, error) {
+	if record.GUID == "" {
+		return record, errors.New("must provide record.GUID to create the user")
+	}
+	if record.Email == "" {
+		return record, errors.New("must provide record.Email to create the user")
+	}
+	if s.db == nil {
+		return record, &storeerror.DBNotSetUp{}
+	}
+	t := time.Now()
+	id, err := wrapsql.ExecSingleInsert(ctx, s.db, wrapsql.InsertQuery{
+		IntoTable: "User",
+		InjectedValues: wrapsql.InjectedValues{
+			"guid":      record.GUID,
+			"email":     record.Email,
+			"createdAt": t,
+			"updatedAt": t,
+		},
+	})
+	if err != nil {
+		return record, err
+	}
+	record.ID = id
+	return record, nil
+}
+
+// GetUniqueUserGUID returns a guid for the user that is guaranteed to be unique or errors.
+// If the proposedUserGUID is not a zero-value and not unique, it will error.
+func (s UserStore) GetUniqueUserGUID(ctx context.Context, proposedUserGUID string) (string, error) {
+	err := guidgen.CheckProposedGUID(proposedUserGUID, "UR", 15)
+	if err != nil {
+		return "", err
+	}
+	if s.db == nil {
+		return "", &storeerror.DBNotSetUp{}
+	}
+	return getUniqueGUID(ctx, s.db, "UR", 15, "User", proposedUserGUID, 0)
+}
@@ -1,16 +1,17 @@
 package mysqlstore
 
 import (
-	"database/sql"
+	"context"
 	"testing"
 
 	"github.com/worlve/sp-service/internal/models/appuser"
 
 	"github.com/worlve/sp-service/internal/util/testutils"
+	"github.com/worlve/sp-service/internal/util/wrapsql"
 	"github.com/stretchr/testify/require"
 )
 
-func testUserStoreClearAllTables(db *sql.DB) error {
+func testUserStoreClearAllTables(db wrapsql.DB) error {
 	tables := []string{"User"}
 	for _, table := range tables {
 		err := clearTableForTest(db, table)
@@ -22,6 +23,7 @@ func testUserStoreClearAllTables(db *sql.DB) error {
 }
 
 func TestGetUser(t *testing.T) {
+	ctx := context.Background()
 	cases := []struct {
 		name                   string
 		shouldReplaceDBWithNil bool
@@ -55,7 +57,7 @@ func TestGetUser(t *testing.T) {
 			if tc.shouldReplaceDBWithNil {
 				userStore.db = nil
 			}
-			result, err := userStore.GetUser(tc.paramGUID)
+			result, err := userStore.GetUser(ctx, tc.paramGUID)
 			errExpected := testutils.TestErrorAgainstCase(t, err, tc.returnErr)
 			if errExpected {
 				return
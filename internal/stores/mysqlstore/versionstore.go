@@ -1,6 +1,7 @@
 package mysqlstore
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 
@@ -12,7 +13,7 @@ import (
 
 // VersionStore is the mysql for versions
 type VersionStore struct {
-	db *sql.DB
+	db wrapsql.DB
 }
 
 // NewVersionStore returns a VersionStore
@@ -23,7 +24,7 @@ func NewVersionStore(mysqldb *sql.DB) VersionStore {
 }
 
 // GetVersion returns the given version.
-func (s VersionStore) GetVersion(guid string) (version.Version, error) {
+func (s VersionStore) GetVersion(ctx context.Context, guid string) (version.Version, error) {
 	if guid == "" {
 		return version.Version{}, errors.New("must provide guid to get the version")
 	}
@@ -41,7 +42,7 @@ func (s VersionStore) GetVersion(guid string) (version.Version, error) {
 		},
 		Limit: 1,
 	}
-	rows, err := s.db.Query(wrapsql.GetSelectString(statement), guid)
+	rows, err := wrapsql.Select(ctx, s.db, statement, guid)
 	var v version.Version
 	err = wrapsql.GetSingleRow(guid, rows, err, &v.ID, &v.GUID, &v.Name)
 	return v, err
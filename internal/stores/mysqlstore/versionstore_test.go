@@ -1,16 +1,17 @@
 package mysqlstore
 
 import (
-	"database/sql"
+	"context"
 	"testing"
 
 	"github.com/worlve/sp-service/internal/models/version"
 
 	"github.com/worlve/sp-service/internal/util/testutils"
+	"github.com/worlve/sp-service/internal/util/wrapsql"
 	"github.com/stretchr/testify/require"
 )
 
-func testVersionStoreClearAllTables(db *sql.DB) error {
+func testVersionStoreClearAllTables(db wrapsql.DB) error {
 	tables := []string{"Version"}
 	for _, table := range tables {
 		err := clearTableForTest(db, table)
@@ -22,6 +23,7 @@ func testVersionStoreClearAllTables(db *sql.DB) error {
 }
 
 func TestGetVersion(t *testing.T) {
+	ctx := context.Background()
 	cases := []struct {
 		name                   string
 		shouldReplaceDBWithNil bool
@@ -55,7 +57,7 @@ func TestGetVersion(t *testing.T) {
 			if tc.shouldReplaceDBWithNil {
 				versionStore.db = nil
 			}
-			result, err := versionStore.GetVersion(tc.paramGUID)
+			result, err := versionStore.GetVersion(ctx, tc.paramGUID)
 			errExpected := testutils.TestErrorAgainstCase(t, err, tc.returnErr)
 			if errExpected {
 				return
@@ -0,0 +1,435 @@
+package mysqlstore
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/worlve/sp-service/internal/models/webhook"
+	"github.com/worlve/sp-service/internal/stores/storeerror"
+	"github.com/worlve/sp-service/internal/util/wrapsql"
+	"github.com/pkg/errors"
+)
+
+// WebhookStore is the mysql for webhooks and their deliveries
+type WebhookStore struct {
+	db wrapsql.DB
+}
+
+// NewWebhookStore returns a WebhookStore
+func NewWebhookStore(mysqldb *sql.DB) WebhookStore {
+	return WebhookStore{
+		db: mysqldb,
+	}
+}
+
+var webhookSelectors = []string{"Webhook.ID", "Webhook.guid", "User.guid", "Webhook.url", "Webhook.events", "Webhook.secret", "Webhook.createdAt"}
+
+var webhookJoinClauses = []wrapsql.JoinClause{
+	{JoinTable: "User", On: wrapsql.OnClause{LeftSide: "Webhook.User_ID", RightSide: "User.ID"}},
+}
+
+// CreateWebhook creates a new webhook for record.UserID.
+func (s WebhookStore) CreateWebhook(ctx context.Context, record webhook.Webhook) (webhook.Webhook, error) {
+	if record.GUID == "" {
+		return record, errors.New("must provide record.GUID to create the webhook")
+	}
+	if record.URL == "" {
+		return record, errors.New("must provide record.URL to create the webhook")
+	}
+	if len(record.Events) == 0 {
+		return record, errors.New("must provide record.Events to create the webhook")
+	}
+	if s.db == nil {
+		return record, &storeerror.DBNotSetUp{}
+	}
+	u, err := UserStore{db: s.db}.GetUser(ctx, record.UserID)
+	if err != nil {
+		return record, err
+	}
+	t := time.Now()
+	record.CreatedAt = &t
+	id, err := wrapsql.ExecSingleInsert(ctx, s.db, wrapsql.InsertQuery{
+		IntoTable: "Webhook",
+		InjectedValues: wrapsql.InjectedValues{
+			"User_ID":   u.ID,
+			"guid":      record.GUID,
+			"url":       record.URL,
+			"events":    joinEventTypes(record.Events),
+			"secret":    record.Secret,
+			"createdAt": record.CreatedAt,
+		},
+	})
+	if err != nil {
+		return record, err
+	}
+	record.ID = id
+	return record, nil
+}
+
+// GetWebhook returns the given webhook, including its secret.
+func (s WebhookStore) GetWebhook(ctx context.Context, guid string) (webhook.Webhook, error) {
+	if guid == "" {
+		return webhook.Webhook{}, errors.New("must provide guid to get the webhook")
+	}
+	if s.db == nil {
+		return webhook.Webhook{}, &storeerror.DBNotSetUp{}
+	}
+	webhooks, err := s.getWebhooks(ctx, "Webhook.guid", guid)
+	if err != nil {
+		return webhook.Webhook{}, err
+	}
+	if len(webhooks) == 0 {
+		return webhook.Webhook{}, &storeerror.NotFound{
+			ID: guid,
+		}
+	}
+	return webhooks[0], nil
+}
+
+// GetWebhooks returns the user's webhooks in the order they were created, including their secrets.
+func (s WebhookStore) GetWebhooks(ctx context.Context, userID string) ([]webhook.Webhook, error) {
+	if userID == "" {
+		return nil, errors.New("must provide userID to get webhooks")
+	}
+	if s.db == nil {
+		return nil, &storeerror.DBNotSetUp{}
+	}
+	return s.getWebhooks(ctx, "User.guid", userID)
+}
+
+func (s WebhookStore) getWebhooks(ctx context.Context, column, value string) (webhooks []webhook.Webhook, returnErr error) {
+	statement := wrapsql.SelectStatement{
+		Selectors:   webhookSelectors,
+		FromTable:   "Webhook",
+		JoinClauses: webhookJoinClauses,
+		WhereClause: wrapsql.WhereClause{
+			Operator: "AND", WhereOperations: []wrapsql.WhereOperation{
+				{LeftSide: column, Operator: "= ?"},
+			},
+		},
+		OrderClause: wrapsql.OrderClause{
+			Column: "Webhook.ID",
+			SortBy: "ASC",
+		},
+	}
+	rows, err := wrapsql.Select(ctx, s.db, statement, value)
+	if err != nil {
+		returnErr = err
+		return
+	}
+	defer rows.Close()
+	webhooks = make([]webhook.Webhook, 0)
+	for rows.Next() {
+		var w webhook.Webhook
+		var events string
+		err := rows.Scan(&w.ID, &w.GUID, &w.UserID, &w.URL, &events, &w.Secret, &w.CreatedAt)
+		if err != nil {
+			returnErr = err
+			return
+		}
+		w.Events, err = splitEventTypes(events)
+		if err != nil {
+			returnErr = err
+			return
+		}
+		webhooks = append(webhooks, w)
+	}
+	returnErr = rows.Err()
+	return
+}
+
+// RemoveWebhook permanently deletes the given webhook, along with its deliveries.
+func (s WebhookStore) RemoveWebhook(ctx context.Context, guid string) error {
+	if guid == "" {
+		return errors.New("must provide guid to remove the webhook")
+	}
+	if s.db == nil {
+		return &storeerror.DBNotSetUp{}
+	}
+	return wrapsql.WithinTransaction(ctx, s.db, func(tx wrapsql.DB) error {
+		w, err := WebhookStore{db: tx}.GetWebhook(ctx, guid)
+		if err != nil {
+			return err
+		}
+		tables := []struct {
+			name   string
+			column string
+		}{
+			{name: "WebhookDelivery", column: "Webhook_ID"},
+			{name: "Webhook", column: "ID"},
+		}
+		for _, table := range tables {
+			err = wrapsql.ExecDelete(ctx, tx, wrapsql.DeleteQuery{
+				FromTable: table.name,
+				WhereClause: wrapsql.WhereClause{
+					Operator: "AND", WhereOperations: []wrapsql.WhereOperation{
+						{LeftSide: table.column, Operator: "= ?"},
+					},
+				},
+			}, w.ID)
+			if err != nil {
+				return errors.Wrapf(err, "unable to delete from %v", table.name)
+			}
+		}
+		return nil
+	})
+}
+
+// CreateDeliveries adds the deliveries to the outbox.
+func (s WebhookStore) CreateDeliveries(ctx context.Context, records []webhook.Delivery) error {
+	if s.db == nil {
+		return &storeerror.DBNotSetUp{}
+	}
+	if len(records) == 0 {
+		return nil
+	}
+	return wrapsql.WithinTransaction(ctx, s.db, func(tx wrapsql.DB) error {
+		webhookIDs := map[string]int64{}
+		t := time.Now()
+		for _, record := range records {
+			if record.GUID == "" {
+				return errors.New("must provide record.GUID to create the delivery")
+			}
+			webhookID, ok := webhookIDs[record.WebhookGUID]
+			if !ok {
+				w, err := WebhookStore{db: tx}.GetWebhook(ctx, record.WebhookGUID)
+				if err != nil {
+					return err
+				}
+				webhookID = w.ID
+				webhookIDs[record.WebhookGUID] = webhookID
+			}
+			_, err := wrapsql.ExecSingleInsert(ctx, tx, wrapsql.InsertQuery{
+				IntoTable: "WebhookDelivery",
+				InjectedValues: wrapsql.InjectedValues{
+					"Webhook_ID":     webhookID,
+					"guid":           record.GUID,
+					"event":          record.Event,
+					"payload":        string(record.Payload),
+					"status":         record.Status,
+					"attempts":       record.Attempts,
+					"responseStatus": record.ResponseStatus,
+					"lastError":      record.LastError,
+					"nextAttemptAt":  record.NextAttemptAt,
+					"createdAt":      t,
+				},
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+var deliverySelectors = []string{"WebhookDelivery.ID", "WebhookDelivery.guid", "Webhook.guid", "WebhookDelivery.event", "WebhookDelivery.payload", "WebhookDelivery.status", "WebhookDelivery.attempts", "WebhookDelivery.responseStatus", "WebhookDelivery.lastError", "WebhookDelivery.nextAttemptAt", "WebhookDelivery.lastAttemptAt", "WebhookDelivery.deliveredAt", "WebhookDelivery.createdAt"}
+
+var deliveryJoinClauses = []wrapsql.JoinClause{
+	{JoinTable: "Webhook", On: wrapsql.OnClause{LeftSide: "WebhookDelivery.Webhook_ID", RightSide: "Webhook.ID"}},
+}
+
+func scanDeliveries(rows *sql.Rows, queryErr error) (deliveries []webhook.Delivery, returnErr error) {
+	if queryErr != nil {
+		returnErr = queryErr
+		return
+	}
+	defer rows.Close()
+	deliveries = make([]webhook.Delivery, 0)
+	for rows.Next() {
+		var d webhook.Delivery
+		var payload string
+		err := rows.Scan(&d.ID, &d.GUID, &d.WebhookGUID, &d.Event, &payload, &d.Status, &d.Attempts, &d.ResponseStatus, &d.LastError, &d.NextAttemptAt, &d.LastAttemptAt, &d.DeliveredAt, &d.CreatedAt)
+		if err != nil {
+			returnErr = err
+			return
+		}
+		d.Payload = []byte(payload)
+		deliveries = append(deliveries, d)
+	}
+	returnErr = rows.Err()
+	return
+}
+
+// GetDueDeliveries returns up to limit pending deliveries whose next attempt is due by dueBy, oldest first.
+func (s WebhookStore) GetDueDeliveries(ctx context.Context, dueBy time.Time, limit int) ([]webhook.Delivery, error) {
+	if s.db == nil {
+		return nil, &storeerror.DBNotSetUp{}
+	}
+	statement := wrapsql.SelectStatement{
+		Selectors:   deliverySelectors,
+		FromTable:   "WebhookDelivery",
+		JoinClauses: deliveryJoinClauses,
+		WhereClause: wrapsql.WhereClause{
+			Operator: "AND", WhereOperations: []wrapsql.WhereOperation{
+				{LeftSide: "WebhookDelivery.status", Operator: "= ?"},
+				{LeftSide: "WebhookDelivery.nextAttemptAt", Operator: "<= ?"},
+			},
+		},
+		OrderClause: wrapsql.OrderClause{
+			Column: "WebhookDelivery.ID",
+			SortBy: "ASC",
+		},
+		Limit: limit,
+	}
+	rows, err := wrapsql.Select(ctx, s.db, statement, webhook.DeliveryPending, dueBy)
+	return scanDeliveries(rows, err)
+}
+
+// ClaimDelivery pushes the delivery's next attempt back to leaseUntil, so no one else attempts it in the meantime.
+// Returns false if the delivery was already claimed or changed since record was read.
+func (s WebhookStore) ClaimDelivery(ctx context.Context, record webhook.Delivery, leaseUntil time.Time) (bool, error) {
+	if record.GUID == "" {
+		return false, errors.New("must provide record.GUID to claim the delivery")
+	}
+	if record.NextAttemptAt == nil {
+		return false, nil
+	}
+	if s.db == nil {
+		return false, &storeerror.DBNotSetUp{}
+	}
+	rowsAffected, err := wrapsql.ExecUpdate(ctx, s.db, wrapsql.UpdateQuery{
+		UpdateTable: "WebhookDelivery",
+		InjectedValues: wrapsql.InjectedValues{
+			"nextAttemptAt": leaseUntil,
+		},
+		WhereClause: wrapsql.WhereClause{
+			Operator: "AND", WhereOperations: []wrapsql.WhereOperation{
+				{LeftSide: "guid", Operator: "= ?"},
+				{LeftSide: "status", Operator: "= ?"},
+				{LeftSide: "attempts", Operator: "= ?"},
+				{LeftSide: "nextAttemptAt", Operator: "= ?"},
+			},
+		},
+	}, record.GUID, webhook.DeliveryPending, record.Attempts, record.NextAttemptAt)
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected == 1, nil
+}
+
+// UpdateDelivery records the outcome of an attempt to send the delivery.
+func (s WebhookStore) UpdateDelivery(ctx context.Context, record webhook.Delivery) error {
+	if record.GUID == "" {
+		return errors.New("must provide record.GUID to update the delivery")
+	}
+	if s.db == nil {
+		return &storeerror.DBNotSetUp{}
+	}
+	return wrapsql.ExecSingleUpdate(ctx, s.db, wrapsql.UpdateQuery{
+		UpdateTable: "WebhookDelivery",
+		InjectedValues: wrapsql.InjectedValues{
+			"status":         record.Status,
+			"attempts":       record.Attempts,
+			"responseStatus": record.ResponseStatus,
+			"lastError":      record.LastError,
+			"nextAttemptAt":  record.NextAttemptAt,
+			"lastAttemptAt":  record.LastAttemptAt,
+			"deliveredAt":    record.DeliveredAt,
+		},
+		WhereClause: wrapsql.WhereClause{
+			Operator: "AND", WhereOperations: []wrapsql.WhereOperation{
+				{LeftSide: "guid", Operator: "= ?"},
+			},
+		},
+	}, record.GUID)
+}
+
+// GetDeliveries returns a batch of the webhook's deliveries, newest first, based on the nextBatchId
+func (s WebhookStore) GetDeliveries(ctx context.Context, webhookGUID, thisBatchID string, limit int) (deliveries []webhook.Delivery, total int, nextBatchID string, returnErr error) {
+	if webhookGUID == "" {
+		returnErr = errors.New("must provide webhookGUID to get deliveries")
+		return
+	}
+	if s.db == nil {
+		returnErr = &storeerror.DBNotSetUp{}
+		return
+	}
+	whereOperations := []wrapsql.WhereOperation{
+		{LeftSide: "Webhook.guid", Operator: "= ?"},
+	}
+	args := []interface{}{webhookGUID}
+	if thisBatchID != "" {
+		thisDeliveryID, err := s.getDeliveryID(ctx, thisBatchID)
+		if err != nil {
+			returnErr = errors.Wrapf(err, "unable to use thisBatchID: %v", thisBatchID)
+			return
+		}
+		whereOperations = append(whereOperations, wrapsql.WhereOperation{LeftSide: "WebhookDelivery.ID", Operator: "<= ?"})
+		args = append(args, thisDeliveryID)
+	}
+	statement := wrapsql.SelectStatement{
+		Selectors:   deliverySelectors,
+		FromTable:   "WebhookDelivery",
+		JoinClauses: deliveryJoinClauses,
+		WhereClause: wrapsql.WhereClause{
+			Operator:        "AND",
+			WhereOperations: whereOperations,
+		},
+		OrderClause: wrapsql.OrderClause{
+			Column: "WebhookDelivery.ID",
+			SortBy: "DESC",
+		},
+		Limit: limit + 1, // plus one so we can get an extra record to determine the nextBatchID
+	}
+	rows, err := wrapsql.Select(ctx, s.db, statement, args...)
+	deliveries, returnErr = scanDeliveries(rows, err)
+	if returnErr != nil {
+		return
+	}
+	if len(deliveries) > limit {
+		nextBatchID = deliveries[len(deliveries)-1].GUID
+		deliveries = deliveries[:len(deliveries)-1]
+	}
+	countStatement := wrapsql.SelectStatement{
+		Selectors:   []string{"COUNT(1)"},
+		FromTable:   "WebhookDelivery",
+		JoinClauses: deliveryJoinClauses,
+		WhereClause: wrapsql.WhereClause{
+			Operator: "AND", WhereOperations: []wrapsql.WhereOperation{
+				{LeftSide: "Webhook.guid", Operator: "= ?"},
+			},
+		},
+	}
+	rows, err = wrapsql.Select(ctx, s.db, countStatement, webhookGUID)
+	returnErr = wrapsql.GetSingleRow(webhookGUID, rows, err, &total)
+	return
+}
+
+func (s WebhookStore) getDeliveryID(ctx context.Context, guid string) (int64, error) {
+	statement := wrapsql.SelectStatement{
+		Selectors: []string{"ID"},
+		FromTable: "WebhookDelivery",
+		WhereClause: wrapsql.WhereClause{
+			Operator: "AND", WhereOperations: []wrapsql.WhereOperation{
+				{LeftSide: "guid", Operator: "= ?"},
+			},
+		},
+		Limit: 1,
+	}
+	rows, err := wrapsql.Select(ctx, s.db, statement, guid)
+	var id int64
+	err = wrapsql.GetSingleRow(guid, rows, err, &id)
+	return id, err
+}
+
+func joinEventTypes(eventTypes []webhook.EventType) string {
+	events := make([]string, 0, len(eventTypes))
+	for _, e := range eventTypes {
+		events = append(events, string(e))
+	}
+	return strings.Join(events, ",")
+}
+
+func splitEventTypes(events string) ([]webhook.EventType, error) {
+	eventTypes := make([]webhook.EventType, 0)
+	for _, e := range strings.Split(events, ",") {
+		eventType, err := webhook.GetEventType(e)
+		if err != nil {
+			return nil, err
+		}
+		eventTypes = append(eventTypes, eventType)
+	}
+	return eventTypes, nil
+}
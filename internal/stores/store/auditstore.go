@@ -0,0 +1,13 @@
+package store
+
+import (
+	"context"
+
+	"github.com/worlve/sp-service/internal/models/audit"
+)
+
+// AuditStore defines the required functionality for any associated store.
+type AuditStore interface {
+	CreateEntries(ctx context.Context, records []audit.Entry) error
+	GetEntries(ctx context.Context, filter audit.Filter, nextBatchID string, limit int) ([]audit.Entry, int, string, error)
+}
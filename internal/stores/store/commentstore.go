@@ -0,0 +1,18 @@
+package store
+
+import (
+	"context"
+
+	"github.com/worlve/sp-service/internal/models/comment"
+)
+
+// CommentStore defines the required functionality for any associated store.
+type CommentStore interface {
+	CreateComment(ctx context.Context, record comment.Comment) (comment.Comment, error)
+	GetComment(ctx context.Context, commentGUID string) (comment.Comment, error)
+	GetThreads(ctx context.Context, pageGUID string, filter comment.Filter, nextBatchID string, limit int) ([]comment.Comment, int, string, error)
+	GetReplies(ctx context.Context, threadGUID string, nextBatchID string, limit int) ([]comment.Comment, int, string, error)
+	GetMentions(ctx context.Context, userID string, nextBatchID string, limit int) ([]comment.Comment, int, string, error)
+	UpdateComment(ctx context.Context, record comment.Comment) (comment.Comment, error)
+	RemoveComment(ctx context.Context, commentGUID string) error
+}
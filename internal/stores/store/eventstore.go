@@ -0,0 +1,21 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/worlve/sp-service/internal/models/event"
+)
+
+// EventStore defines the required functionality for any associated store.
+type EventStore interface {
+	CreateEvent(ctx context.Context, record event.Event) (event.Event, error)
+	GetEvents(ctx context.Context, afterOffset int64, limit int) ([]event.Event, error)
+	GetLastOffset(ctx context.Context) (int64, error)
+	PurgeEvents(ctx context.Context, createdBefore time.Time) error
+	GetSubscriberOffset(ctx context.Context, subscriber string) (int64, error)
+	ClaimSubscriber(ctx context.Context, subscriber string, now, leaseUntil time.Time) (bool, error)
+	AdvanceSubscriberOffset(ctx context.Context, subscriber string, from, to int64) (bool, error)
+	SetSubscriberOffset(ctx context.Context, subscriber string, offset int64) error
+	GetSubscriberOffsets(ctx context.Context) (map[string]int64, error)
+}
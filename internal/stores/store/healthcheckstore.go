@@ -1,6 +1,8 @@
 package store
 
+import "context"
+
 // HealthcheckStore defines the required functionality for any associated store.
 type HealthcheckStore interface {
-	IsHealthy() (bool, error)
+	IsHealthy(ctx context.Context) (bool, error)
 }
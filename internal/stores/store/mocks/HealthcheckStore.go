@@ -1,6 +1,7 @@
 // Code generated by mockery v1.0.0. DO NOT EDIT.
 package mocks
 
+import context "context"
 import mock "github.com/stretchr/testify/mock"
 
 // HealthcheckStore is an autogenerated mock type for the HealthcheckStore type
@@ -8,20 +9,20 @@ type HealthcheckStore struct {
 	mock.Mock
 }
 
-// IsHealthy provides a mock function with given fields:
-func (_m *HealthcheckStore) IsHealthy() (bool, error) {
-	ret := _m.Called()
+// IsHealthy provides a mock function with given fields: ctx
+func (_m *HealthcheckStore) IsHealthy(ctx context.Context) (bool, error) {
+	ret := _m.Called(ctx)
 
 	var r0 bool
-	if rf, ok := ret.Get(0).(func() bool); ok {
-		r0 = rf()
+	if rf, ok := ret.Get(0).(func(context.Context) bool); ok {
+		r0 = rf(ctx)
 	} else {
 		r0 = ret.Get(0).(bool)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func() error); ok {
-		r1 = rf()
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -1,28 +1,31 @@
 // Code generated by mockery v1.0.0. DO NOT EDIT.
 package mocks
 
+import context "context"
 import mock "github.com/stretchr/testify/mock"
 import page "github.com/worlve/sp-service/internal/models/page"
+import property "github.com/worlve/sp-service/internal/models/property"
+import time "time"
 
 // PageStore is an autogenerated mock type for the PageStore type
 type PageStore struct {
 	mock.Mock
 }
 
-// CanEditPage provides a mock function with given fields: pageGUID, userID
-func (_m *PageStore) CanEditPage(pageGUID string, userID string) (bool, error) {
-	ret := _m.Called(pageGUID, userID)
+// CanEditPage provides a mock function with given fields: ctx, pageGUID, userID
+func (_m *PageStore) CanEditPage(ctx context.Context, pageGUID string, userID string) (bool, error) {
+	ret := _m.Called(ctx, pageGUID, userID)
 
 	var r0 bool
-	if rf, ok := ret.Get(0).(func(string, string) bool); ok {
-		r0 = rf(pageGUID, userID)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) bool); ok {
+		r0 = rf(ctx, pageGUID, userID)
 	} else {
 		r0 = ret.Get(0).(bool)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(string, string) error); ok {
-		r1 = rf(pageGUID, userID)
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, pageGUID, userID)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -30,20 +33,20 @@ func (_m *PageStore) CanEditPage(pageGUID string, userID string) (bool, error) {
 	return r0, r1
 }
 
-// CanReadPage provides a mock function with given fields: pageGUID, userID
-func (_m *PageStore) CanReadPage(pageGUID string, userID string) (bool, error) {
-	ret := _m.Called(pageGUID, userID)
+// CanReadPage provides a mock function with given fields: ctx, pageGUID, userID
+func (_m *PageStore) CanReadPage(ctx context.Context, pageGUID string, userID string) (bool, error) {
+	ret := _m.Called(ctx, pageGUID, userID)
 
 	var r0 bool
-	if rf, ok := ret.Get(0).(func(string, string) bool); ok {
-		r0 = rf(pageGUID, userID)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) bool); ok {
+		r0 = rf(ctx, pageGUID, userID)
 	} else {
 		r0 = ret.Get(0).(bool)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(string, string) error); ok {
-		r1 = rf(pageGUID, userID)
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, pageGUID, userID)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -51,20 +54,20 @@ func (_m *PageStore) CanReadPage(pageGUID string, userID string) (bool, error) {
 	return r0, r1
 }
 
-// CreatePage provides a mock function with given fields: record, ownerID
-func (_m *PageStore) CreatePage(record page.Page, ownerID int64) (page.Page, error) {
-	ret := _m.Called(record, ownerID)
+// CreatePage provides a mock function with given fields: ctx, record, ownerID
+func (_m *PageStore) CreatePage(ctx context.Context, record page.Page, ownerID int64) (page.Page, error) {
+	ret := _m.Called(ctx, record, ownerID)
 
 	var r0 page.Page
-	if rf, ok := ret.Get(0).(func(page.Page, int64) page.Page); ok {
-		r0 = rf(record, ownerID)
+	if rf, ok := ret.Get(0).(func(context.Context, page.Page, int64) page.Page); ok {
+		r0 = rf(ctx, record, ownerID)
 	} else {
 		r0 = ret.Get(0).(page.Page)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(page.Page, int64) error); ok {
-		r1 = rf(record, ownerID)
+	if rf, ok := ret.Get(1).(func(context.Context, page.Page, int64) error); ok {
+		r1 = rf(ctx, record, ownerID)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -72,20 +75,20 @@ func (_m *PageStore) CreatePage(record page.Page, ownerID int64) (page.Page, err
 	return r0, r1
 }
 
-// GetPage provides a mock function with given fields: pageGUID
-func (_m *PageStore) GetPage(pageGUID string) (page.Page, error) {
-	ret := _m.Called(pageGUID)
+// GetPage provides a mock function with given fields: ctx, pageGUID
+func (_m *PageStore) GetPage(ctx context.Context, pageGUID string) (page.Page, error) {
+	ret := _m.Called(ctx, pageGUID)
 
 	var r0 page.Page
-	if rf, ok := ret.Get(0).(func(string) page.Page); ok {
-		r0 = rf(pageGUID)
+	if rf, ok := ret.Get(0).(func(context.Context, string) page.Page); ok {
+		r0 = rf(ctx, pageGUID)
 	} else {
 		r0 = ret.Get(0).(page.Page)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(pageGUID)
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, pageGUID)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -93,13 +96,94 @@ func (_m *PageStore) GetPage(pageGUID string) (page.Page, error) {
 	return r0, r1
 }
 
-// GetPages provides a mock function with given fields: userID, nextBatchID, limit
-func (_m *PageStore) GetPages(userID string, nextBatchID string, limit int) ([]page.Page, int, string, error) {
-	ret := _m.Called(userID, nextBatchID, limit)
+// GetPageOwner provides a mock function with given fields: ctx, pageGUID
+func (_m *PageStore) GetPageOwner(ctx context.Context, pageGUID string) (string, error) {
+	ret := _m.Called(ctx, pageGUID)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(context.Context, string) string); ok {
+		r0 = rf(ctx, pageGUID)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, pageGUID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPageProperties provides a mock function with given fields: ctx, pageGUID
+func (_m *PageStore) GetPageProperties(ctx context.Context, pageGUID string) ([]property.Property, error) {
+	ret := _m.Called(ctx, pageGUID)
+
+	var r0 []property.Property
+	if rf, ok := ret.Get(0).(func(context.Context, string) []property.Property); ok {
+		r0 = rf(ctx, pageGUID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]property.Property)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, pageGUID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPages provides a mock function with given fields: ctx, userID, nextBatchID, limit
+func (_m *PageStore) GetPages(ctx context.Context, userID string, nextBatchID string, limit int) ([]page.Page, int, string, error) {
+	ret := _m.Called(ctx, userID, nextBatchID, limit)
+
+	var r0 []page.Page
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int) []page.Page); ok {
+		r0 = rf(ctx, userID, nextBatchID, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]page.Page)
+		}
+	}
+
+	var r1 int
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, int) int); ok {
+		r1 = rf(ctx, userID, nextBatchID, limit)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+
+	var r2 string
+	if rf, ok := ret.Get(2).(func(context.Context, string, string, int) string); ok {
+		r2 = rf(ctx, userID, nextBatchID, limit)
+	} else {
+		r2 = ret.Get(2).(string)
+	}
+
+	var r3 error
+	if rf, ok := ret.Get(3).(func(context.Context, string, string, int) error); ok {
+		r3 = rf(ctx, userID, nextBatchID, limit)
+	} else {
+		r3 = ret.Error(3)
+	}
+
+	return r0, r1, r2, r3
+}
+
+// GetRemovedPages provides a mock function with given fields: ctx, userID, nextBatchID, limit
+func (_m *PageStore) GetRemovedPages(ctx context.Context, userID string, nextBatchID string, limit int) ([]page.Page, int, string, error) {
+	ret := _m.Called(ctx, userID, nextBatchID, limit)
 
 	var r0 []page.Page
-	if rf, ok := ret.Get(0).(func(string, string, int) []page.Page); ok {
-		r0 = rf(userID, nextBatchID, limit)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int) []page.Page); ok {
+		r0 = rf(ctx, userID, nextBatchID, limit)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]page.Page)
@@ -107,22 +191,22 @@ func (_m *PageStore) GetPages(userID string, nextBatchID string, limit int) ([]p
 	}
 
 	var r1 int
-	if rf, ok := ret.Get(1).(func(string, string, int) int); ok {
-		r1 = rf(userID, nextBatchID, limit)
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, int) int); ok {
+		r1 = rf(ctx, userID, nextBatchID, limit)
 	} else {
 		r1 = ret.Get(1).(int)
 	}
 
 	var r2 string
-	if rf, ok := ret.Get(2).(func(string, string, int) string); ok {
-		r2 = rf(userID, nextBatchID, limit)
+	if rf, ok := ret.Get(2).(func(context.Context, string, string, int) string); ok {
+		r2 = rf(ctx, userID, nextBatchID, limit)
 	} else {
 		r2 = ret.Get(2).(string)
 	}
 
 	var r3 error
-	if rf, ok := ret.Get(3).(func(string, string, int) error); ok {
-		r3 = rf(userID, nextBatchID, limit)
+	if rf, ok := ret.Get(3).(func(context.Context, string, string, int) error); ok {
+		r3 = rf(ctx, userID, nextBatchID, limit)
 	} else {
 		r3 = ret.Error(3)
 	}
@@ -130,20 +214,78 @@ func (_m *PageStore) GetPages(userID string, nextBatchID string, limit int) ([]p
 	return r0, r1, r2, r3
 }
 
-// GetUniquePageGUID provides a mock function with given fields: proposedPageGUID
-func (_m *PageStore) GetUniquePageGUID(proposedPageGUID string) (string, error) {
-	ret := _m.Called(proposedPageGUID)
+// GetUniquePageGUID provides a mock function with given fields: ctx, proposedPageGUID
+func (_m *PageStore) GetUniquePageGUID(ctx context.Context, proposedPageGUID string) (string, error) {
+	ret := _m.Called(ctx, proposedPageGUID)
 
 	var r0 string
-	if rf, ok := ret.Get(0).(func(string) string); ok {
-		r0 = rf(proposedPageGUID)
+	if rf, ok := ret.Get(0).(func(context.Context, string) string); ok {
+		r0 = rf(ctx, proposedPageGUID)
 	} else {
 		r0 = ret.Get(0).(string)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(proposedPageGUID)
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, proposedPageGUID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUserPageGUIDs provides a mock function with given fields: ctx, userID
+func (_m *PageStore) GetUserPageGUIDs(ctx context.Context, userID string) ([]string, error) {
+	ret := _m.Called(ctx, userID)
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func(context.Context, string) []string); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PurgePage provides a mock function with given fields: ctx, pageGUID
+func (_m *PageStore) PurgePage(ctx context.Context, pageGUID string) error {
+	ret := _m.Called(ctx, pageGUID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, pageGUID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// PurgeRemovedPages provides a mock function with given fields: ctx, removedBefore
+func (_m *PageStore) PurgeRemovedPages(ctx context.Context, removedBefore time.Time) (int, error) {
+	ret := _m.Called(ctx, removedBefore)
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) int); ok {
+		r0 = rf(ctx, removedBefore)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, removedBefore)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -151,13 +293,55 @@ func (_m *PageStore) GetUniquePageGUID(proposedPageGUID string) (string, error)
 	return r0, r1
 }
 
-// RemovePage provides a mock function with given fields: pageGUID
-func (_m *PageStore) RemovePage(pageGUID string) error {
-	ret := _m.Called(pageGUID)
+// RemovePage provides a mock function with given fields: ctx, pageGUID
+func (_m *PageStore) RemovePage(ctx context.Context, pageGUID string) error {
+	ret := _m.Called(ctx, pageGUID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, pageGUID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ReplacePageProperties provides a mock function with given fields: ctx, pageGUID, pageProperties
+func (_m *PageStore) ReplacePageProperties(ctx context.Context, pageGUID string, pageProperties []property.Property) error {
+	ret := _m.Called(ctx, pageGUID, pageProperties)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []property.Property) error); ok {
+		r0 = rf(ctx, pageGUID, pageProperties)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RestorePage provides a mock function with given fields: ctx, pageGUID
+func (_m *PageStore) RestorePage(ctx context.Context, pageGUID string) error {
+	ret := _m.Called(ctx, pageGUID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, pageGUID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// TransferPage provides a mock function with given fields: ctx, pageGUID, userID
+func (_m *PageStore) TransferPage(ctx context.Context, pageGUID string, userID string) error {
+	ret := _m.Called(ctx, pageGUID, userID)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string) error); ok {
-		r0 = rf(pageGUID)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, pageGUID, userID)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -165,13 +349,13 @@ func (_m *PageStore) RemovePage(pageGUID string) error {
 	return r0
 }
 
-// UpdatePage provides a mock function with given fields: record
-func (_m *PageStore) UpdatePage(record page.Page) error {
-	ret := _m.Called(record)
+// UpdatePage provides a mock function with given fields: ctx, record
+func (_m *PageStore) UpdatePage(ctx context.Context, record page.Page) error {
+	ret := _m.Called(ctx, record)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(page.Page) error); ok {
-		r0 = rf(record)
+	if rf, ok := ret.Get(0).(func(context.Context, page.Page) error); ok {
+		r0 = rf(ctx, record)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -1,6 +1,7 @@
 // Code generated by mockery v1.0.0. DO NOT EDIT.
 package mocks
 
+import context "context"
 import mock "github.com/stretchr/testify/mock"
 import pagetemplate "github.com/worlve/sp-service/internal/models/pagetemplate"
 
@@ -9,20 +10,20 @@ type PageTemplateStore struct {
 	mock.Mock
 }
 
-// GetPageTemplate provides a mock function with given fields: pageTemplateGUID
-func (_m *PageTemplateStore) GetPageTemplate(pageTemplateGUID string) (pagetemplate.PageTemplate, error) {
-	ret := _m.Called(pageTemplateGUID)
+// GetPageTemplate provides a mock function with given fields: ctx, pageTemplateGUID
+func (_m *PageTemplateStore) GetPageTemplate(ctx context.Context, pageTemplateGUID string) (pagetemplate.PageTemplate, error) {
+	ret := _m.Called(ctx, pageTemplateGUID)
 
 	var r0 pagetemplate.PageTemplate
-	if rf, ok := ret.Get(0).(func(string) pagetemplate.PageTemplate); ok {
-		r0 = rf(pageTemplateGUID)
+	if rf, ok := ret.Get(0).(func(context.Context, string) pagetemplate.PageTemplate); ok {
+		r0 = rf(ctx, pageTemplateGUID)
 	} else {
 		r0 = ret.Get(0).(pagetemplate.PageTemplate)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(pageTemplateGUID)
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, pageTemplateGUID)
 	} else {
 		r1 = ret.Error(1)
 	}
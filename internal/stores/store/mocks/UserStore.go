@@ -2,6 +2,7 @@
 package mocks
 
 import appuser "github.com/worlve/sp-service/internal/models/appuser"
+import context "context"
 import mock "github.com/stretchr/testify/mock"
 
 // UserStore is an autogenerated mock type for the UserStore type
@@ -9,20 +10,62 @@ type UserStore struct {
 	mock.Mock
 }
 
-// GetUser provides a mock function with given fields: userGUID
-func (_m *UserStore) GetUser(userGUID string) (appuser.User, error) {
-	ret := _m.Called(userGUID)
+// CreateUser provides a mock function with given fields: ctx, record
+func (_m *UserStore) CreateUser(ctx context.Context, record appuser.User) (appuser.User, error) {
+	ret := _m.Called(ctx, record)
 
 	var r0 appuser.User
-	if rf, ok := ret.Get(0).(func(string) appuser.User); ok {
-		r0 = rf(userGUID)
+	if rf, ok := ret.Get(0).(func(context.Context, appuser.User) appuser.User); ok {
+		r0 = rf(ctx, record)
 	} else {
 		r0 = ret.Get(0).(appuser.User)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(userGUID)
+	if rf, ok := ret.Get(1).(func(context.Context, appuser.User) error); ok {
+		r1 = rf(ctx, record)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUniqueUserGUID provides a mock function with given fields: ctx, proposedUserGUID
+func (_m *UserStore) GetUniqueUserGUID(ctx context.Context, proposedUserGUID string) (string, error) {
+	ret := _m.Called(ctx, proposedUserGUID)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(context.Context, string) string); ok {
+		r0 = rf(ctx, proposedUserGUID)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, proposedUserGUID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUser provides a mock function with given fields: ctx, userGUID
+func (_m *UserStore) GetUser(ctx context.Context, userGUID string) (appuser.User, error) {
+	ret := _m.Called(ctx, userGUID)
+
+	var r0 appuser.User
+	if rf, ok := ret.Get(0).(func(context.Context, string) appuser.User); ok {
+		r0 = rf(ctx, userGUID)
+	} else {
+		r0 = ret.Get(0).(appuser.User)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userGUID)
 	} else {
 		r1 = ret.Error(1)
 	}
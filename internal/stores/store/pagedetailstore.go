@@ -1,8 +1,14 @@
 package store
 
-import "github.com/worlve/sp-service/internal/models/pagedetail"
+import (
+	"context"
+
+	"github.com/worlve/sp-service/internal/models/pagedetail"
+)
 
 // PageDetailStore defines the required functionality for any associated store.
 type PageDetailStore interface {
-	UpdatePageDetail(record pagedetail.PageDetail) error
+	UpdatePageDetail(ctx context.Context, pageGUID string, record pagedetail.PageDetail) (pagedetail.PageDetail, error)
+	GetPageDetails(ctx context.Context, pageGUID string) ([]pagedetail.PageDetail, error)
+	ReplacePageDetails(ctx context.Context, pageGUID string, details []pagedetail.PageDetail) error
 }
@@ -1,20 +1,30 @@
 package store
 
 import (
+	"context"
+	"time"
+
 	"github.com/worlve/sp-service/internal/models/page"
 	"github.com/worlve/sp-service/internal/models/property"
 )
 
 // PageStore defines the required functionality for any associated store.
 type PageStore interface {
-	GetUniquePageGUID(proposedPageGUID string) (string, error)
-	CanEditPage(pageGUID, userID string) (bool, error)
-	CanReadPage(pageGUID, userID string) (bool, error)
-	UpdatePage(record page.Page) error
-	CreatePage(record page.Page, ownerID int64) (page.Page, error)
-	GetPage(pageGUID string) (page.Page, error)
-	GetPages(userID string, nextBatchID string, limit int) ([]page.Page, int, string, error)
-	RemovePage(pageGUID string) error
-	GetPageProperties(pageGUID string) ([]property.Property, error)
-	ReplacePageProperties(pageGUID string, pageProperties []property.Property) error
+	GetUniquePageGUID(ctx context.Context, proposedPageGUID string) (string, error)
+	CanEditPage(ctx context.Context, pageGUID, userID string) (bool, error)
+	CanReadPage(ctx context.Context, pageGUID, userID string) (bool, error)
+	GetPageOwner(ctx context.Context, pageGUID string) (string, error)
+	UpdatePage(ctx context.Context, record page.Page) error
+	CreatePage(ctx context.Context, record page.Page, ownerID int64) (page.Page, error)
+	GetPage(ctx context.Context, pageGUID string) (page.Page, error)
+	GetPages(ctx context.Context, userID string, nextBatchID string, limit int) ([]page.Page, int, string, error)
+	GetUserPageGUIDs(ctx context.Context, userID string) ([]string, error)
+	RemovePage(ctx context.Context, pageGUID string) error
+	GetRemovedPages(ctx context.Context, userID string, nextBatchID string, limit int) ([]page.Page, int, string, error)
+	RestorePage(ctx context.Context, pageGUID string) error
+	TransferPage(ctx context.Context, pageGUID, userID string) error
+	PurgePage(ctx context.Context, pageGUID string) error
+	PurgeRemovedPages(ctx context.Context, removedBefore time.Time) (int, error)
+	GetPageProperties(ctx context.Context, pageGUID string) ([]property.Property, error)
+	ReplacePageProperties(ctx context.Context, pageGUID string, pageProperties []property.Property) error
 }
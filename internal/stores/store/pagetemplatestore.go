@@ -1,10 +1,12 @@
 package store
 
 import (
+	"context"
+
 	"github.com/worlve/sp-service/internal/models/pagetemplate"
 )
 
 // PageTemplateStore defines the required functionality for any associated store.
 type PageTemplateStore interface {
-	GetPageTemplate(pageTemplateGUID string) (pagetemplate.PageTemplate, error)
+	GetPageTemplate(ctx context.Context, pageTemplateGUID string) (pagetemplate.PageTemplate, error)
 }
@@ -0,0 +1,36 @@
+package store
+
+import "context"
+
+// Stores are the stores available within a single unit of work.
+type Stores struct {
+	PageStore         PageStore
+	PageDetailStore   PageDetailStore
+	PageTemplateStore PageTemplateStore
+	UserStore         UserStore
+	VersionStore      VersionStore
+	WebhookStore      WebhookStore
+	EventStore        EventStore
+	AuditStore        AuditStore
+	CommentStore      CommentStore
+}
+
+// UnitOfWork defines the required functionality for running multiple store calls atomically.
+// If fn returns an error, none of the changes made through the given stores are kept.
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(stores Stores) error) error
+}
+
+// Bound returns a UnitOfWork that runs fn with the given stores, such as for a service that's already bound to a unit
+// of work calling a method that starts one of its own: the method joins the unit of work rather than starting another.
+func Bound(stores Stores) UnitOfWork {
+	return boundUnitOfWork{stores: stores}
+}
+
+type boundUnitOfWork struct {
+	stores Stores
+}
+
+func (u boundUnitOfWork) Do(ctx context.Context, fn func(stores Stores) error) error {
+	return fn(u.stores)
+}
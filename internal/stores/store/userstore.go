@@ -1,8 +1,14 @@
 package store
 
-import "github.com/worlve/sp-service/internal/models/appuser"
+import (
+	"context"
+
+	"github.com/worlve/sp-service/internal/models/appuser"
+)
 
 // UserStore defines the required functionality for any associated store.
 type UserStore interface {
-	GetUser(userGUID string) (appuser.User, error)
+	GetUser(ctx context.Context, userGUID string) (appuser.User, error)
+	GetUniqueUserGUID(ctx context.Context, proposedUserGUID string) (string, error)
+	CreateUser(ctx context.Context, record appuser.User) (appuser.User, error)
 }
@@ -0,0 +1,21 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/worlve/sp-service/internal/models/webhook"
+)
+
+// WebhookStore defines the required functionality for any associated store.
+type WebhookStore interface {
+	CreateWebhook(ctx context.Context, record webhook.Webhook) (webhook.Webhook, error)
+	GetWebhook(ctx context.Context, webhookGUID string) (webhook.Webhook, error)
+	GetWebhooks(ctx context.Context, userID string) ([]webhook.Webhook, error)
+	RemoveWebhook(ctx context.Context, webhookGUID string) error
+	CreateDeliveries(ctx context.Context, records []webhook.Delivery) error
+	GetDueDeliveries(ctx context.Context, dueBy time.Time, limit int) ([]webhook.Delivery, error)
+	ClaimDelivery(ctx context.Context, record webhook.Delivery, leaseUntil time.Time) (bool, error)
+	UpdateDelivery(ctx context.Context, record webhook.Delivery) error
+	GetDeliveries(ctx context.Context, webhookGUID string, nextBatchID string, limit int) ([]webhook.Delivery, int, string, error)
+}
@@ -0,0 +1,36 @@
+package storeerror
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// Canceled is an error that signifies that the store call was abandoned because its context was canceled or its deadline passed.
+type Canceled struct {
+	Err error
+}
+
+func (e *Canceled) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("Store call canceled\n%v", e.Err)
+	}
+	return "Store call canceled"
+}
+
+// DeadlineExceeded is true when the store call ran out of time rather than being canceled by the caller.
+func (e *Canceled) DeadlineExceeded() bool {
+	return e.Err == context.DeadlineExceeded
+}
+
+// FromContext returns err as a Canceled error when ctx is done, or err as is otherwise.
+func FromContext(ctx context.Context, err error) error {
+	if err == nil || ctx.Err() == nil {
+		return err
+	}
+	if _, ok := errors.Cause(err).(*Canceled); ok {
+		return err
+	}
+	return &Canceled{Err: ctx.Err()}
+}
@@ -0,0 +1,14 @@
+package storeerror
+
+import "fmt"
+
+// InvalidField is an error that signifies that a field refers to something the store doesn't have where it's expected,
+// such as a comment's anchor to a detail of another page.
+type InvalidField struct {
+	Field   string
+	Message string
+}
+
+func (e *InvalidField) Error() string {
+	return fmt.Sprintf("Invalid %v: %v", e.Field, e.Message)
+}
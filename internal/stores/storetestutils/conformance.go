@@ -0,0 +1,1046 @@
+// Package storetestutils is a conformance test suite that every store backend must pass.
+// Each backend's tests call RunConformanceTests with a NewBackend function that returns
+// stores backed by an empty db seeded with the given Fixtures.
+package storetestutils
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/worlve/sp-service/internal/models/appuser"
+	"github.com/worlve/sp-service/internal/models/audit"
+	"github.com/worlve/sp-service/internal/models/comment"
+	"github.com/worlve/sp-service/internal/models/event"
+	"github.com/worlve/sp-service/internal/models/page"
+	"github.com/worlve/sp-service/internal/models/pagedetail"
+	"github.com/worlve/sp-service/internal/models/pagetemplate"
+	"github.com/worlve/sp-service/internal/models/permission"
+	"github.com/worlve/sp-service/internal/models/property"
+	"github.com/worlve/sp-service/internal/models/version"
+	"github.com/worlve/sp-service/internal/models/webhook"
+	"github.com/worlve/sp-service/internal/stores/store"
+	"github.com/worlve/sp-service/internal/stores/storeerror"
+	"github.com/stretchr/testify/require"
+)
+
+// Fixtures is the reference data a backend must be seeded with before running the conformance tests.
+// Its records do not have IDs set; backends are free to assign them.
+type Fixtures struct {
+	Users         []appuser.User
+	Versions      []version.Version
+	PageTemplates []pagetemplate.PageTemplate
+	Properties    []property.Property
+}
+
+// Backend is the set of stores under test.
+type Backend struct {
+	Stores           store.Stores
+	HealthcheckStore store.HealthcheckStore
+	UnitOfWork       store.UnitOfWork
+}
+
+// NewBackend returns a Backend bound to an empty db seeded with the given fixtures.
+type NewBackend func(t *testing.T, fixtures Fixtures) Backend
+
+// DefaultFixtures returns the fixtures the conformance tests rely on.
+func DefaultFixtures() Fixtures {
+	return Fixtures{
+		Users: []appuser.User{
+			{GUID: "UR_1", Email: "owner@test.com"},
+			{GUID: "UR_2", Email: "reader@test.com"},
+		},
+		Versions: []version.Version{
+			{GUID: "VR_1", Name: "first version"},
+			{GUID: "VR_2", Name: "second version"},
+		},
+		PageTemplates: []pagetemplate.PageTemplate{
+			{GUID: "PGT_1", Name: "first template"},
+			{GUID: "PGT_2", Name: "second template"},
+		},
+		Properties: []property.Property{
+			{Key: "color", Type: property.TypeString},
+			{Key: "population", Type: property.TypeNumber},
+		},
+	}
+}
+
+// RunConformanceTests runs every conformance test against backends returned by newBackend.
+func RunConformanceTests(t *testing.T, newBackend NewBackend) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, b Backend)
+	}{
+		{name: "reference data", fn: testReferenceData},
+		{name: "healthcheck", fn: testHealthcheck},
+		{name: "create and get page", fn: testCreateAndGetPage},
+		{name: "unique page guid", fn: testGetUniquePageGUID},
+		{name: "page privileges", fn: testPagePrivileges},
+		{name: "transfer page", fn: testTransferPage},
+		{name: "create user", fn: testCreateUser},
+		{name: "update page", fn: testUpdatePage},
+		{name: "page batches", fn: testGetPages},
+		{name: "trash", fn: testTrash},
+		{name: "purge removed pages", fn: testPurgeRemovedPages},
+		{name: "page properties", fn: testPageProperties},
+		{name: "page detail", fn: testUpdatePageDetail},
+		{name: "page details", fn: testPageDetails},
+		{name: "webhooks", fn: testWebhooks},
+		{name: "webhook deliveries", fn: testWebhookDeliveries},
+		{name: "events", fn: testEvents},
+		{name: "event subscribers", fn: testEventSubscribers},
+		{name: "audit entries", fn: testAuditEntries},
+		{name: "comments", fn: testComments},
+		{name: "unit of work", fn: testUnitOfWork},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.fn(t, newBackend(t, DefaultFixtures()))
+		})
+	}
+}
+
+type pageRefs struct {
+	owner        appuser.User
+	reader       appuser.User
+	version      version.Version
+	pageTemplate pagetemplate.PageTemplate
+}
+
+func getPageRefs(t *testing.T, b Backend) pageRefs {
+	ctx := context.Background()
+	owner, err := b.Stores.UserStore.GetUser(ctx, "UR_1")
+	require.NoError(t, err)
+	reader, err := b.Stores.UserStore.GetUser(ctx, "UR_2")
+	require.NoError(t, err)
+	v, err := b.Stores.VersionStore.GetVersion(ctx, "VR_1")
+	require.NoError(t, err)
+	pt, err := b.Stores.PageTemplateStore.GetPageTemplate(ctx, "PGT_1")
+	require.NoError(t, err)
+	return pageRefs{owner: owner, reader: reader, version: v, pageTemplate: pt}
+}
+
+func createPage(t *testing.T, b Backend, refs pageRefs, guid string, permissionType permission.Type) page.Page {
+	ctx := context.Background()
+	record, err := b.Stores.PageStore.CreatePage(ctx, page.Page{
+		GUID:           guid,
+		Title:          "title " + guid,
+		Summary:        "summary " + guid,
+		Version:        refs.version,
+		PageTemplate:   refs.pageTemplate,
+		PermissionType: permissionType,
+	}, refs.owner.ID)
+	require.NoError(t, err)
+	return record
+}
+
+func getGUIDs(pages []page.Page) []string {
+	guids := make([]string, 0, len(pages))
+	for _, p := range pages {
+		guids = append(guids, p.GUID)
+	}
+	return guids
+}
+
+func requireNotFound(t *testing.T, err error) {
+	require.Error(t, err)
+	_, ok := err.(*storeerror.NotFound)
+	require.True(t, ok, "expected a storeerror.NotFound but got %v", err)
+}
+
+func requireNotAuthorized(t *testing.T, err error) {
+	require.Error(t, err)
+	_, ok := err.(*storeerror.NotAuthorized)
+	require.True(t, ok, "expected a storeerror.NotAuthorized but got %v", err)
+}
+
+func testReferenceData(t *testing.T, b Backend) {
+	ctx := context.Background()
+	u, err := b.Stores.UserStore.GetUser(ctx, "UR_1")
+	require.NoError(t, err)
+	require.NotZero(t, u.ID)
+	require.Equal(t, appuser.User{ID: u.ID, GUID: "UR_1", Email: "owner@test.com"}, u)
+	_, err = b.Stores.UserStore.GetUser(ctx, "UR_MISSING")
+	requireNotFound(t, err)
+	_, err = b.Stores.UserStore.GetUser(ctx, "")
+	require.Error(t, err)
+
+	v, err := b.Stores.VersionStore.GetVersion(ctx, "VR_2")
+	require.NoError(t, err)
+	require.NotZero(t, v.ID)
+	require.Equal(t, version.Version{ID: v.ID, GUID: "VR_2", Name: "second version"}, v)
+	_, err = b.Stores.VersionStore.GetVersion(ctx, "VR_MISSING")
+	requireNotFound(t, err)
+	_, err = b.Stores.VersionStore.GetVersion(ctx, "")
+	require.Error(t, err)
+
+	pt, err := b.Stores.PageTemplateStore.GetPageTemplate(ctx, "PGT_2")
+	require.NoError(t, err)
+	require.NotZero(t, pt.ID)
+	require.Equal(t, pagetemplate.PageTemplate{ID: pt.ID, GUID: "PGT_2", Name: "second template"}, pt)
+	_, err = b.Stores.PageTemplateStore.GetPageTemplate(ctx, "PGT_MISSING")
+	requireNotFound(t, err)
+	_, err = b.Stores.PageTemplateStore.GetPageTemplate(ctx, "")
+	require.Error(t, err)
+}
+
+func testHealthcheck(t *testing.T, b Backend) {
+	ctx := context.Background()
+	isHealthy, err := b.HealthcheckStore.IsHealthy(ctx)
+	require.NoError(t, err)
+	require.True(t, isHealthy)
+}
+
+func testCreateAndGetPage(t *testing.T, b Backend) {
+	ctx := context.Background()
+	refs := getPageRefs(t, b)
+	record := createPage(t, b, refs, "PG_1", permission.TypePrivate)
+	require.NotZero(t, record.ID)
+	require.NotNil(t, record.CreatedAt)
+	require.NotNil(t, record.UpdatedAt)
+
+	p, err := b.Stores.PageStore.GetPage(ctx, "PG_1")
+	require.NoError(t, err)
+	require.Equal(t, record.ID, p.ID)
+	require.Equal(t, "PG_1", p.GUID)
+	require.Equal(t, "title PG_1", p.Title)
+	require.Equal(t, "summary PG_1", p.Summary)
+	require.Equal(t, "VR_1", p.Version.GUID)
+	require.Equal(t, "PGT_1", p.PageTemplate.GUID)
+	require.Equal(t, permission.TypePrivate, p.PermissionType)
+	require.NotNil(t, p.CreatedAt)
+	require.NotNil(t, p.UpdatedAt)
+	require.Nil(t, p.DeletedAt)
+
+	_, err = b.Stores.PageStore.GetPage(ctx, "PG_MISSING")
+	requireNotFound(t, err)
+	_, err = b.Stores.PageStore.GetPage(ctx, "")
+	require.Error(t, err)
+
+	_, err = b.Stores.PageStore.CreatePage(ctx, page.Page{GUID: "PG_2", Title: "title"}, refs.owner.ID)
+	require.Error(t, err)
+}
+
+func testGetUniquePageGUID(t *testing.T, b Backend) {
+	ctx := context.Background()
+	refs := getPageRefs(t, b)
+	createPage(t, b, refs, "PG_123456789012", permission.TypePrivate)
+
+	guid, err := b.Stores.PageStore.GetUniquePageGUID(ctx, "PG_999999999999")
+	require.NoError(t, err)
+	require.Equal(t, "PG_999999999999", guid)
+
+	_, err = b.Stores.PageStore.GetUniquePageGUID(ctx, "PG_123456789012")
+	require.Error(t, err)
+	_, ok := err.(*storeerror.DupEntry)
+	require.True(t, ok, "expected a storeerror.DupEntry but got %v", err)
+
+	guid, err = b.Stores.PageStore.GetUniquePageGUID(ctx, "")
+	require.NoError(t, err)
+	require.Len(t, guid, 15)
+	require.True(t, strings.HasPrefix(guid, "PG_"))
+
+	_, err = b.Stores.PageStore.GetUniquePageGUID(ctx, "PG_1")
+	require.Error(t, err)
+}
+
+func testPagePrivileges(t *testing.T, b Backend) {
+	ctx := context.Background()
+	refs := getPageRefs(t, b)
+	createPage(t, b, refs, "PG_PRIVATE", permission.TypePrivate)
+	createPage(t, b, refs, "PG_PUBLIC", permission.TypePublic)
+
+	isOwner, err := b.Stores.PageStore.CanEditPage(ctx, "PG_PRIVATE", "UR_1")
+	require.NoError(t, err)
+	require.True(t, isOwner)
+	_, err = b.Stores.PageStore.CanEditPage(ctx, "PG_PRIVATE", "UR_2")
+	requireNotAuthorized(t, err)
+	_, err = b.Stores.PageStore.CanEditPage(ctx, "PG_MISSING", "UR_1")
+	requireNotAuthorized(t, err)
+
+	canRead, err := b.Stores.PageStore.CanReadPage(ctx, "PG_PRIVATE", "UR_1")
+	require.NoError(t, err)
+	require.True(t, canRead)
+	canRead, err = b.Stores.PageStore.CanReadPage(ctx, "PG_PRIVATE", "UR_2")
+	require.NoError(t, err)
+	require.False(t, canRead)
+	canRead, err = b.Stores.PageStore.CanReadPage(ctx, "PG_PUBLIC", "UR_2")
+	require.NoError(t, err)
+	require.True(t, canRead)
+	_, err = b.Stores.PageStore.CanReadPage(ctx, "PG_MISSING", "UR_2")
+	requireNotAuthorized(t, err)
+}
+
+func testTransferPage(t *testing.T, b Backend) {
+	ctx := context.Background()
+	refs := getPageRefs(t, b)
+	createPage(t, b, refs, "PG_1", permission.TypePrivate)
+	createPage(t, b, refs, "PG_2", permission.TypePrivate)
+	ownerID, err := b.Stores.PageStore.GetPageOwner(ctx, "PG_1")
+	require.NoError(t, err)
+	require.Equal(t, "UR_1", ownerID)
+
+	err = b.Stores.PageStore.TransferPage(ctx, "PG_1", "UR_2")
+	require.NoError(t, err)
+	ownerID, err = b.Stores.PageStore.GetPageOwner(ctx, "PG_1")
+	require.NoError(t, err)
+	require.Equal(t, "UR_2", ownerID)
+	isOwner, err := b.Stores.PageStore.CanEditPage(ctx, "PG_1", "UR_2")
+	require.NoError(t, err)
+	require.True(t, isOwner)
+	_, err = b.Stores.PageStore.CanEditPage(ctx, "PG_1", "UR_1")
+	requireNotAuthorized(t, err)
+	pages, total, _, err := b.Stores.PageStore.GetPages(ctx, "UR_2", "", 10)
+	require.NoError(t, err)
+	require.Equal(t, []string{"PG_1"}, getGUIDs(pages))
+	require.Equal(t, 1, total)
+	pages, total, _, err = b.Stores.PageStore.GetPages(ctx, "UR_1", "", 10)
+	require.NoError(t, err)
+	require.Equal(t, []string{"PG_2"}, getGUIDs(pages))
+	require.Equal(t, 1, total)
+
+	err = b.Stores.PageStore.RemovePage(ctx, "PG_2")
+	require.NoError(t, err)
+	err = b.Stores.PageStore.TransferPage(ctx, "PG_2", "UR_2")
+	require.NoError(t, err)
+	pages, _, _, err = b.Stores.PageStore.GetRemovedPages(ctx, "UR_2", "", 10)
+	require.NoError(t, err)
+	require.Equal(t, []string{"PG_2"}, getGUIDs(pages))
+	ownerID, err = b.Stores.PageStore.GetPageOwner(ctx, "PG_2")
+	require.NoError(t, err)
+	require.Equal(t, "UR_2", ownerID)
+	guids, err := b.Stores.PageStore.GetUserPageGUIDs(ctx, "UR_2")
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"PG_1", "PG_2"}, guids)
+	guids, err = b.Stores.PageStore.GetUserPageGUIDs(ctx, "UR_1")
+	require.NoError(t, err)
+	require.Empty(t, guids)
+	_, err = b.Stores.PageStore.GetPageOwner(ctx, "PG_MISSING")
+	requireNotFound(t, err)
+
+	err = b.Stores.PageStore.TransferPage(ctx, "PG_MISSING", "UR_2")
+	requireNotFound(t, err)
+	err = b.Stores.PageStore.TransferPage(ctx, "PG_1", "UR_MISSING")
+	requireNotFound(t, err)
+	err = b.Stores.PageStore.TransferPage(ctx, "", "UR_2")
+	require.Error(t, err)
+}
+
+func testCreateUser(t *testing.T, b Backend) {
+	ctx := context.Background()
+	guid, err := b.Stores.UserStore.GetUniqueUserGUID(ctx, "")
+	require.NoError(t, err)
+	require.Len(t, guid, 15)
+	require.True(t, strings.HasPrefix(guid, "UR_"))
+
+	u, err := b.Stores.UserStore.CreateUser(ctx, appuser.User{GUID: "UR_123456789012", Email: "new@test.com"})
+	require.NoError(t, err)
+	require.NotZero(t, u.ID)
+	got, err := b.Stores.UserStore.GetUser(ctx, "UR_123456789012")
+	require.NoError(t, err)
+	require.Equal(t, u, got)
+
+	_, err = b.Stores.UserStore.GetUniqueUserGUID(ctx, "UR_123456789012")
+	require.Error(t, err)
+	_, ok := err.(*storeerror.DupEntry)
+	require.True(t, ok, "expected a storeerror.DupEntry but got %v", err)
+	_, err = b.Stores.UserStore.GetUniqueUserGUID(ctx, "UR_1")
+	require.Error(t, err)
+	_, err = b.Stores.UserStore.CreateUser(ctx, appuser.User{GUID: "UR_999999999999"})
+	require.Error(t, err)
+}
+
+func testUpdatePage(t *testing.T, b Backend) {
+	ctx := context.Background()
+	refs := getPageRefs(t, b)
+	createPage(t, b, refs, "PG_1", permission.TypePrivate)
+	v, err := b.Stores.VersionStore.GetVersion(ctx, "VR_2")
+	require.NoError(t, err)
+	pt, err := b.Stores.PageTemplateStore.GetPageTemplate(ctx, "PGT_2")
+	require.NoError(t, err)
+
+	err = b.Stores.PageStore.UpdatePage(ctx, page.Page{GUID: "PG_1", Title: "new title"})
+	require.NoError(t, err)
+	p, err := b.Stores.PageStore.GetPage(ctx, "PG_1")
+	require.NoError(t, err)
+	require.Equal(t, "new title", p.Title)
+	require.Equal(t, "summary PG_1", p.Summary)
+	require.Equal(t, permission.TypePrivate, p.PermissionType)
+
+	err = b.Stores.PageStore.UpdatePage(ctx, page.Page{GUID: "PG_1", Summary: "new summary", Version: v, PageTemplate: pt, PermissionType: permission.TypePublic})
+	require.NoError(t, err)
+	p, err = b.Stores.PageStore.GetPage(ctx, "PG_1")
+	require.NoError(t, err)
+	require.Equal(t, "new title", p.Title)
+	require.Equal(t, "new summary", p.Summary)
+	require.Equal(t, "VR_2", p.Version.GUID)
+	require.Equal(t, "PGT_2", p.PageTemplate.GUID)
+	require.Equal(t, permission.TypePublic, p.PermissionType)
+
+	err = b.Stores.PageStore.UpdatePage(ctx, page.Page{Title: "new title"})
+	require.Error(t, err)
+}
+
+func testGetPages(t *testing.T, b Backend) {
+	ctx := context.Background()
+	refs := getPageRefs(t, b)
+	createPage(t, b, refs, "PG_1", permission.TypePrivate)
+	createPage(t, b, refs, "PG_2", permission.TypePrivate)
+	createPage(t, b, refs, "PG_3", permission.TypePublic)
+
+	pages, total, nextBatchID, err := b.Stores.PageStore.GetPages(ctx, "UR_1", "", 2)
+	require.NoError(t, err)
+	require.Equal(t, []string{"PG_1", "PG_2"}, getGUIDs(pages))
+	require.Equal(t, 3, total)
+	require.Equal(t, "PG_3", nextBatchID)
+	require.Equal(t, "VR_1", pages[0].Version.GUID)
+	require.Equal(t, "PGT_1", pages[0].PageTemplate.GUID)
+	require.Equal(t, "title PG_1", pages[0].Title)
+
+	pages, total, nextBatchID, err = b.Stores.PageStore.GetPages(ctx, "UR_1", nextBatchID, 2)
+	require.NoError(t, err)
+	require.Equal(t, []string{"PG_3"}, getGUIDs(pages))
+	require.Equal(t, 3, total)
+	require.Equal(t, "", nextBatchID)
+
+	pages, total, nextBatchID, err = b.Stores.PageStore.GetPages(ctx, "UR_2", "", 2)
+	require.NoError(t, err)
+	require.Equal(t, []page.Page{}, pages)
+	require.Equal(t, 0, total)
+	require.Equal(t, "", nextBatchID)
+
+	_, _, _, err = b.Stores.PageStore.GetPages(ctx, "UR_1", "PG_MISSING", 2)
+	require.Error(t, err)
+	_, _, _, err = b.Stores.PageStore.GetPages(ctx, "", "", 2)
+	require.Error(t, err)
+}
+
+func testTrash(t *testing.T, b Backend) {
+	ctx := context.Background()
+	refs := getPageRefs(t, b)
+	createPage(t, b, refs, "PG_1", permission.TypePrivate)
+	createPage(t, b, refs, "PG_2", permission.TypePrivate)
+
+	err := b.Stores.PageStore.RestorePage(ctx, "PG_1")
+	requireNotFound(t, err)
+	err = b.Stores.PageStore.PurgePage(ctx, "PG_1")
+	requireNotFound(t, err)
+
+	err = b.Stores.PageStore.RemovePage(ctx, "PG_1")
+	require.NoError(t, err)
+	_, err = b.Stores.PageStore.GetPage(ctx, "PG_1")
+	requireNotFound(t, err)
+	pages, total, _, err := b.Stores.PageStore.GetPages(ctx, "UR_1", "", 10)
+	require.NoError(t, err)
+	require.Equal(t, []string{"PG_2"}, getGUIDs(pages))
+	require.Equal(t, 1, total)
+	pages, total, _, err = b.Stores.PageStore.GetRemovedPages(ctx, "UR_1", "", 10)
+	require.NoError(t, err)
+	require.Equal(t, []string{"PG_1"}, getGUIDs(pages))
+	require.Equal(t, 1, total)
+	require.NotNil(t, pages[0].DeletedAt)
+
+	err = b.Stores.PageStore.RestorePage(ctx, "PG_1")
+	require.NoError(t, err)
+	_, err = b.Stores.PageStore.GetPage(ctx, "PG_1")
+	require.NoError(t, err)
+
+	err = b.Stores.PageStore.ReplacePageProperties(ctx, "PG_2", []property.Property{
+		{Key: "color", Type: property.TypeString, Value: "blue"},
+	})
+	require.NoError(t, err)
+	err = b.Stores.PageStore.RemovePage(ctx, "PG_2")
+	require.NoError(t, err)
+	err = b.Stores.PageStore.PurgePage(ctx, "PG_2")
+	require.NoError(t, err)
+	pages, total, _, err = b.Stores.PageStore.GetRemovedPages(ctx, "UR_1", "", 10)
+	require.NoError(t, err)
+	require.Equal(t, []page.Page{}, pages)
+	require.Equal(t, 0, total)
+	_, err = b.Stores.PageStore.CanEditPage(ctx, "PG_2", "UR_1")
+	requireNotAuthorized(t, err)
+	guid, err := b.Stores.PageStore.GetUniquePageGUID(ctx, "PG_222222222222")
+	require.NoError(t, err)
+	require.Equal(t, "PG_222222222222", guid)
+}
+
+func testPurgeRemovedPages(t *testing.T, b Backend) {
+	ctx := context.Background()
+	refs := getPageRefs(t, b)
+	createPage(t, b, refs, "PG_1", permission.TypePrivate)
+	createPage(t, b, refs, "PG_2", permission.TypePrivate)
+	createPage(t, b, refs, "PG_3", permission.TypePrivate)
+	removedAt := time.Now().Add(-48 * time.Hour)
+	err := b.Stores.PageStore.UpdatePage(ctx, page.Page{GUID: "PG_1", DeletedAt: &removedAt})
+	require.NoError(t, err)
+	err = b.Stores.PageStore.RemovePage(ctx, "PG_2")
+	require.NoError(t, err)
+
+	purged, err := b.Stores.PageStore.PurgeRemovedPages(ctx, time.Now().Add(-24*time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, 1, purged)
+	pages, _, _, err := b.Stores.PageStore.GetRemovedPages(ctx, "UR_1", "", 10)
+	require.NoError(t, err)
+	require.Equal(t, []string{"PG_2"}, getGUIDs(pages))
+	pages, _, _, err = b.Stores.PageStore.GetPages(ctx, "UR_1", "", 10)
+	require.NoError(t, err)
+	require.Equal(t, []string{"PG_3"}, getGUIDs(pages))
+
+	purged, err = b.Stores.PageStore.PurgeRemovedPages(ctx, time.Now().Add(-24*time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, 0, purged)
+}
+
+func testPageProperties(t *testing.T, b Backend) {
+	ctx := context.Background()
+	refs := getPageRefs(t, b)
+	createPage(t, b, refs, "PG_1", permission.TypePrivate)
+
+	properties, err := b.Stores.PageStore.GetPageProperties(ctx, "PG_1")
+	require.NoError(t, err)
+	require.Empty(t, properties)
+
+	err = b.Stores.PageStore.ReplacePageProperties(ctx, "PG_1", []property.Property{
+		{Key: "population", Type: property.TypeNumber, Value: float64(120)},
+		{Key: "color", Type: property.TypeString, Value: "blue"},
+	})
+	require.NoError(t, err)
+	properties, err = b.Stores.PageStore.GetPageProperties(ctx, "PG_1")
+	require.NoError(t, err)
+	require.Len(t, properties, 2)
+	require.NotZero(t, properties[0].ID)
+	require.NotZero(t, properties[1].ID)
+	require.Equal(t, []property.Property{
+		{ID: properties[0].ID, Key: "population", Type: property.TypeNumber, Value: float64(120)},
+		{ID: properties[1].ID, Key: "color", Type: property.TypeString, Value: "blue"},
+	}, properties)
+
+	err = b.Stores.PageStore.ReplacePageProperties(ctx, "PG_1", []property.Property{
+		{Key: "color", Type: property.TypeString, Value: "red"},
+	})
+	require.NoError(t, err)
+	properties, err = b.Stores.PageStore.GetPageProperties(ctx, "PG_1")
+	require.NoError(t, err)
+	require.Equal(t, []property.Property{
+		{ID: properties[0].ID, Key: "color", Type: property.TypeString, Value: "red"},
+	}, properties)
+
+	err = b.Stores.PageStore.ReplacePageProperties(ctx, "PG_1", []property.Property{
+		{Key: "unknown", Type: property.TypeString, Value: "red"},
+	})
+	require.Error(t, err)
+	err = b.Stores.PageStore.ReplacePageProperties(ctx, "PG_MISSING", []property.Property{})
+	require.Error(t, err)
+
+	err = b.Stores.PageStore.ReplacePageProperties(ctx, "PG_1", []property.Property{})
+	require.NoError(t, err)
+	properties, err = b.Stores.PageStore.GetPageProperties(ctx, "PG_1")
+	require.NoError(t, err)
+	require.Empty(t, properties)
+}
+
+func testUpdatePageDetail(t *testing.T, b Backend) {
+	ctx := context.Background()
+	refs := getPageRefs(t, b)
+	createPage(t, b, refs, "PG_1", permission.TypePrivate)
+	createPage(t, b, refs, "PG_2", permission.TypePrivate)
+	partitions := []pagedetail.Partition{{GUID: "P_1", Type: pagedetail.PartitionTypeParagraph, TypeString: "p", Value: "long ago"}}
+	err := b.Stores.PageDetailStore.ReplacePageDetails(ctx, "PG_1", []pagedetail.PageDetail{
+		{GUID: "DT_1", Title: "history", Partitions: partitions},
+		{GUID: "DT_2", Title: "geography"},
+	})
+	require.NoError(t, err)
+
+	updated, err := b.Stores.PageDetailStore.UpdatePageDetail(ctx, "PG_1", pagedetail.PageDetail{GUID: "DT_1", Title: "detail title", Summary: "detail summary"})
+	require.NoError(t, err)
+	require.NotZero(t, updated.ID)
+	require.Equal(t, pagedetail.PageDetail{ID: updated.ID, GUID: "DT_1", Title: "detail title", Summary: "detail summary", Partitions: partitions}, updated)
+	details, err := b.Stores.PageDetailStore.GetPageDetails(ctx, "PG_1")
+	require.NoError(t, err)
+	require.Equal(t, []pagedetail.PageDetail{
+		updated,
+		{ID: details[1].ID, GUID: "DT_2", Title: "geography", Partitions: []pagedetail.Partition{}},
+	}, details)
+	p, err := b.Stores.PageStore.GetPage(ctx, "PG_1")
+	require.NoError(t, err)
+	require.NotEqual(t, "detail title", p.Title, "the page's own title is left alone")
+
+	_, err = b.Stores.PageDetailStore.UpdatePageDetail(ctx, "PG_2", pagedetail.PageDetail{GUID: "DT_1", Title: "moved"})
+	requireNotFound(t, err)
+	_, err = b.Stores.PageDetailStore.UpdatePageDetail(ctx, "PG_1", pagedetail.PageDetail{GUID: "DT_MISSING", Title: "missing"})
+	requireNotFound(t, err)
+	_, err = b.Stores.PageDetailStore.UpdatePageDetail(ctx, "PG_1", pagedetail.PageDetail{GUID: "DT_1"})
+	require.Error(t, err)
+}
+
+func testPageDetails(t *testing.T, b Backend) {
+	ctx := context.Background()
+	refs := getPageRefs(t, b)
+	createPage(t, b, refs, "PG_1", permission.TypePrivate)
+
+	details, err := b.Stores.PageDetailStore.GetPageDetails(ctx, "PG_1")
+	require.NoError(t, err)
+	require.Empty(t, details)
+
+	partitions := []pagedetail.Partition{
+		{Type: pagedetail.PartitionTypeParagraph, TypeString: "p", Partitions: []pagedetail.Partition{
+			{Type: pagedetail.PartitionTypeText, TypeString: "text", Value: "see "},
+			{Type: pagedetail.PartitionTypeRelation, TypeString: "relation", Value: "the capital", Relation: "PG_2"},
+		}},
+	}
+	replacements := []pagedetail.PageDetail{
+		{GUID: "DT_1", Title: "history", Summary: "long ago", Partitions: partitions},
+		{Title: "geography"},
+	}
+	err = b.Stores.PageDetailStore.ReplacePageDetails(ctx, "PG_1", replacements)
+	require.NoError(t, err)
+	require.NotEmpty(t, replacements[1].GUID)
+	details, err = b.Stores.PageDetailStore.GetPageDetails(ctx, "PG_1")
+	require.NoError(t, err)
+	require.Len(t, details, 2)
+	require.NotZero(t, details[0].ID)
+	require.NotZero(t, details[1].ID)
+	require.Equal(t, []pagedetail.PageDetail{
+		{ID: details[0].ID, GUID: "DT_1", Title: "history", Summary: "long ago", Partitions: partitions},
+		{ID: details[1].ID, GUID: replacements[1].GUID, Title: "geography", Partitions: []pagedetail.Partition{}},
+	}, details)
+
+	err = b.Stores.PageDetailStore.ReplacePageDetails(ctx, "PG_1", []pagedetail.PageDetail{
+		{GUID: "DT_2", Title: "people"},
+	})
+	require.NoError(t, err)
+	details, err = b.Stores.PageDetailStore.GetPageDetails(ctx, "PG_1")
+	require.NoError(t, err)
+	require.Equal(t, []string{"DT_2"}, []string{details[0].GUID})
+	require.Len(t, details, 1)
+
+	err = b.Stores.PageDetailStore.ReplacePageDetails(ctx, "PG_1", []pagedetail.PageDetail{
+		{GUID: "DT_3"}, {GUID: "DT_3"},
+	})
+	require.Error(t, err)
+	_, ok := err.(*storeerror.DupEntry)
+	require.True(t, ok, "expected a storeerror.DupEntry but got %v", err)
+	err = b.Stores.PageDetailStore.ReplacePageDetails(ctx, "PG_MISSING", []pagedetail.PageDetail{})
+	require.Error(t, err)
+
+	err = b.Stores.PageStore.RemovePage(ctx, "PG_1")
+	require.NoError(t, err)
+	err = b.Stores.PageStore.PurgePage(ctx, "PG_1")
+	require.NoError(t, err)
+	details, err = b.Stores.PageDetailStore.GetPageDetails(ctx, "PG_1")
+	require.NoError(t, err)
+	require.Empty(t, details)
+}
+
+func testWebhooks(t *testing.T, b Backend) {
+	ctx := context.Background()
+	events := []webhook.EventType{webhook.EventPageCreated, webhook.EventPageDetailUpdated}
+
+	w, err := b.Stores.WebhookStore.CreateWebhook(ctx, webhook.Webhook{GUID: "WH_1", UserID: "UR_1", URL: "http://localhost/hook", Events: events, Secret: "secret"})
+	require.NoError(t, err)
+	require.NotZero(t, w.ID)
+	require.NotNil(t, w.CreatedAt)
+	_, err = b.Stores.WebhookStore.CreateWebhook(ctx, webhook.Webhook{GUID: "WH_2", UserID: "UR_2", URL: "http://localhost/other", Events: events, Secret: "other"})
+	require.NoError(t, err)
+	got, err := b.Stores.WebhookStore.GetWebhook(ctx, "WH_1")
+	require.NoError(t, err)
+	require.Equal(t, "UR_1", got.UserID)
+	require.Equal(t, "http://localhost/hook", got.URL)
+	require.Equal(t, events, got.Events)
+	require.Equal(t, "secret", got.Secret)
+	webhooks, err := b.Stores.WebhookStore.GetWebhooks(ctx, "UR_1")
+	require.NoError(t, err)
+	require.Len(t, webhooks, 1)
+	require.Equal(t, "WH_1", webhooks[0].GUID)
+
+	_, err = b.Stores.WebhookStore.CreateWebhook(ctx, webhook.Webhook{GUID: "WH_3", UserID: "UR_MISSING", URL: "http://localhost/hook", Events: events})
+	requireNotFound(t, err)
+	_, err = b.Stores.WebhookStore.GetWebhook(ctx, "WH_MISSING")
+	requireNotFound(t, err)
+
+	err = b.Stores.WebhookStore.RemoveWebhook(ctx, "WH_1")
+	require.NoError(t, err)
+	webhooks, err = b.Stores.WebhookStore.GetWebhooks(ctx, "UR_1")
+	require.NoError(t, err)
+	require.Empty(t, webhooks)
+	err = b.Stores.WebhookStore.RemoveWebhook(ctx, "WH_1")
+	requireNotFound(t, err)
+}
+
+func testWebhookDeliveries(t *testing.T, b Backend) {
+	ctx := context.Background()
+	_, err := b.Stores.WebhookStore.CreateWebhook(ctx, webhook.Webhook{GUID: "WH_1", UserID: "UR_1", URL: "http://localhost/hook", Events: webhook.EventTypes, Secret: "secret"})
+	require.NoError(t, err)
+	now := time.Now().UTC().Truncate(time.Second)
+	later := now.Add(time.Hour)
+	err = b.Stores.WebhookStore.CreateDeliveries(ctx, []webhook.Delivery{
+		{GUID: "WD_1", WebhookGUID: "WH_1", Event: webhook.EventPageCreated, Payload: []byte(`{"n":1}`), Status: webhook.DeliveryPending, NextAttemptAt: &now},
+		{GUID: "WD_2", WebhookGUID: "WH_1", Event: webhook.EventPageUpdated, Payload: []byte(`{"n":2}`), Status: webhook.DeliveryPending, NextAttemptAt: &later},
+		{GUID: "WD_3", WebhookGUID: "WH_1", Event: webhook.EventPageRemoved, Payload: []byte(`{"n":3}`), Status: webhook.DeliveryPending, NextAttemptAt: &now},
+	})
+	require.NoError(t, err)
+	err = b.Stores.WebhookStore.CreateDeliveries(ctx, []webhook.Delivery{{GUID: "WD_4", WebhookGUID: "WH_MISSING", Status: webhook.DeliveryPending}})
+	requireNotFound(t, err)
+
+	due, err := b.Stores.WebhookStore.GetDueDeliveries(ctx, now, 10)
+	require.NoError(t, err)
+	require.Len(t, due, 2)
+	require.Equal(t, "WD_1", due[0].GUID)
+	require.Equal(t, "WD_3", due[1].GUID)
+	require.Equal(t, "WH_1", due[0].WebhookGUID)
+	require.Equal(t, `{"n":1}`, string(due[0].Payload))
+
+	claimed, err := b.Stores.WebhookStore.ClaimDelivery(ctx, due[0], later)
+	require.NoError(t, err)
+	require.True(t, claimed)
+	claimed, err = b.Stores.WebhookStore.ClaimDelivery(ctx, due[0], later)
+	require.NoError(t, err)
+	require.False(t, claimed, "a delivery can only be claimed once")
+	due, err = b.Stores.WebhookStore.GetDueDeliveries(ctx, now, 10)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+
+	delivered := due[0]
+	delivered.Status = webhook.DeliveryDelivered
+	delivered.Attempts = 1
+	delivered.ResponseStatus = 204
+	delivered.NextAttemptAt = nil
+	delivered.LastAttemptAt = &now
+	delivered.DeliveredAt = &now
+	err = b.Stores.WebhookStore.UpdateDelivery(ctx, delivered)
+	require.NoError(t, err)
+
+	deliveries, total, nextBatchID, err := b.Stores.WebhookStore.GetDeliveries(ctx, "WH_1", "", 2)
+	require.NoError(t, err)
+	require.Equal(t, 3, total)
+	require.Equal(t, "WD_1", nextBatchID)
+	require.Len(t, deliveries, 2)
+	require.Equal(t, "WD_3", deliveries[0].GUID)
+	require.Equal(t, webhook.DeliveryDelivered, deliveries[0].Status)
+	require.Equal(t, 1, deliveries[0].Attempts)
+	require.Equal(t, 204, deliveries[0].ResponseStatus)
+	require.Nil(t, deliveries[0].NextAttemptAt)
+	require.True(t, now.Equal(*deliveries[0].DeliveredAt))
+	deliveries, _, nextBatchID, err = b.Stores.WebhookStore.GetDeliveries(ctx, "WH_1", nextBatchID, 2)
+	require.NoError(t, err)
+	require.Empty(t, nextBatchID)
+	require.Len(t, deliveries, 1)
+	require.Equal(t, "WD_1", deliveries[0].GUID)
+
+	err = b.Stores.WebhookStore.RemoveWebhook(ctx, "WH_1")
+	require.NoError(t, err)
+	_, total, _, err = b.Stores.WebhookStore.GetDeliveries(ctx, "WH_1", "", 2)
+	require.NoError(t, err)
+	require.Zero(t, total)
+}
+
+func testEvents(t *testing.T, b Backend) {
+	ctx := context.Background()
+	offset, err := b.Stores.EventStore.GetLastOffset(ctx)
+	require.NoError(t, err)
+	require.Zero(t, offset)
+
+	var created []event.Event
+	for _, guid := range []string{"EV_1", "EV_2", "EV_3"} {
+		e, err := b.Stores.EventStore.CreateEvent(ctx, event.Event{GUID: guid, Type: event.TypePageRemoved, PageID: "PG_1", UserID: "UR_1", Payload: []byte(`{"pageId":"PG_1"}`)})
+		require.NoError(t, err)
+		require.NotNil(t, e.CreatedAt)
+		created = append(created, e)
+	}
+	require.True(t, created[0].Offset < created[1].Offset && created[1].Offset < created[2].Offset, "offsets must count up")
+	offset, err = b.Stores.EventStore.GetLastOffset(ctx)
+	require.NoError(t, err)
+	require.Equal(t, created[2].Offset, offset)
+
+	events, err := b.Stores.EventStore.GetEvents(ctx, created[0].Offset, 1)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, "EV_2", events[0].GUID)
+	require.Equal(t, event.TypePageRemoved, events[0].Type)
+	require.Equal(t, "PG_1", events[0].PageID)
+	require.Equal(t, "UR_1", events[0].UserID)
+	require.Equal(t, `{"pageId":"PG_1"}`, string(events[0].Payload))
+	events, err = b.Stores.EventStore.GetEvents(ctx, created[2].Offset, 10)
+	require.NoError(t, err)
+	require.Empty(t, events)
+
+	err = b.UnitOfWork.Do(ctx, func(stores store.Stores) error {
+		_, err := stores.EventStore.CreateEvent(ctx, event.Event{GUID: "EV_ROLLBACK", Type: event.TypePageRemoved, PageID: "PG_1", UserID: "UR_1", Payload: []byte(`{}`)})
+		require.NoError(t, err)
+		return errors.New("failed")
+	})
+	require.Error(t, err)
+	events, err = b.Stores.EventStore.GetEvents(ctx, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, events, 3, "events created in a rolled back unit of work aren't kept")
+
+	err = b.Stores.EventStore.PurgeEvents(ctx, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	events, err = b.Stores.EventStore.GetEvents(ctx, 0, 10)
+	require.NoError(t, err)
+	require.Empty(t, events)
+}
+
+func testEventSubscribers(t *testing.T, b Backend) {
+	ctx := context.Background()
+	_, err := b.Stores.EventStore.GetSubscriberOffset(ctx, "webhooks")
+	requireNotFound(t, err)
+	advanced, err := b.Stores.EventStore.AdvanceSubscriberOffset(ctx, "webhooks", 0, 1)
+	require.NoError(t, err)
+	require.False(t, advanced, "a subscriber must be added before it's advanced")
+
+	err = b.Stores.EventStore.SetSubscriberOffset(ctx, "webhooks", 5)
+	require.NoError(t, err)
+	advanced, err = b.Stores.EventStore.AdvanceSubscriberOffset(ctx, "webhooks", 5, 7)
+	require.NoError(t, err)
+	require.True(t, advanced)
+	advanced, err = b.Stores.EventStore.AdvanceSubscriberOffset(ctx, "webhooks", 5, 8)
+	require.NoError(t, err)
+	require.False(t, advanced, "the offset moved on since it was read")
+	offset, err := b.Stores.EventStore.GetSubscriberOffset(ctx, "webhooks")
+	require.NoError(t, err)
+	require.Equal(t, int64(7), offset)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	claimed, err := b.Stores.EventStore.ClaimSubscriber(ctx, "webhooks", now, now.Add(time.Minute))
+	require.NoError(t, err)
+	require.True(t, claimed)
+	claimed, err = b.Stores.EventStore.ClaimSubscriber(ctx, "webhooks", now.Add(time.Second), now.Add(2*time.Minute))
+	require.NoError(t, err)
+	require.False(t, claimed, "a subscriber can't be claimed until its lease ends")
+	claimed, err = b.Stores.EventStore.ClaimSubscriber(ctx, "webhooks", now.Add(time.Minute), now.Add(2*time.Minute))
+	require.NoError(t, err)
+	require.True(t, claimed)
+	claimed, err = b.Stores.EventStore.ClaimSubscriber(ctx, "search", now, now.Add(time.Minute))
+	require.NoError(t, err)
+	require.False(t, claimed, "a subscriber must be added before it's claimed")
+
+	err = b.Stores.EventStore.SetSubscriberOffset(ctx, "webhooks", 2)
+	require.NoError(t, err)
+	err = b.Stores.EventStore.SetSubscriberOffset(ctx, "search", 9)
+	require.NoError(t, err)
+	offsets, err := b.Stores.EventStore.GetSubscriberOffsets(ctx)
+	require.NoError(t, err)
+	require.Equal(t, map[string]int64{"webhooks": 2, "search": 9}, offsets)
+}
+
+func testAuditEntries(t *testing.T, b Backend) {
+	ctx := context.Background()
+	err := b.Stores.AuditStore.CreateEntries(ctx, []audit.Entry{
+		{GUID: "AE_1", ActorType: audit.ActorProxyUser, UserID: "UR_1", Method: "POST", Route: "/api/pages", TargetID: "PG_1", PageID: "PG_1", Status: 201, After: []byte(`{"title":"a"}`), RequestID: "RQ_1"},
+		{GUID: "AE_2", ActorType: audit.ActorAdmin, Method: "PUT", Route: "/api/pages/:pageID", TargetID: "PG_1", PageID: "PG_1", Status: 200, Before: []byte(`{"title":"a"}`), After: []byte(`{"title":"b"}`), RequestID: "RQ_2"},
+		{GUID: "AE_3", ActorType: audit.ActorProxyUser, UserID: "UR_2", Method: "POST", Route: "/api/webhooks", TargetID: "WH_1", Status: 201, RequestID: "RQ_3"},
+	})
+	require.NoError(t, err)
+	err = b.Stores.AuditStore.CreateEntries(ctx, []audit.Entry{{ActorType: audit.ActorAdmin}})
+	require.Error(t, err)
+
+	entries, total, nextBatchID, err := b.Stores.AuditStore.GetEntries(ctx, audit.Filter{}, "", 2)
+	require.NoError(t, err)
+	require.Equal(t, 3, total)
+	require.Equal(t, "AE_1", nextBatchID)
+	require.Len(t, entries, 2)
+	require.Equal(t, "AE_3", entries[0].GUID)
+	require.Equal(t, "AE_2", entries[1].GUID)
+	require.Equal(t, audit.ActorAdmin, entries[1].ActorType)
+	require.Empty(t, entries[1].UserID)
+	require.Equal(t, "PUT", entries[1].Method)
+	require.Equal(t, "/api/pages/:pageID", entries[1].Route)
+	require.Equal(t, "PG_1", entries[1].TargetID)
+	require.Equal(t, "PG_1", entries[1].PageID)
+	require.Equal(t, 200, entries[1].Status)
+	require.Equal(t, `{"title":"a"}`, string(entries[1].Before))
+	require.Equal(t, `{"title":"b"}`, string(entries[1].After))
+	require.Equal(t, "RQ_2", entries[1].RequestID)
+	require.NotNil(t, entries[1].CreatedAt)
+	require.Empty(t, entries[0].Before, "an entry without a summary has none")
+	entries, _, nextBatchID, err = b.Stores.AuditStore.GetEntries(ctx, audit.Filter{}, nextBatchID, 2)
+	require.NoError(t, err)
+	require.Empty(t, nextBatchID)
+	require.Len(t, entries, 1)
+	require.Equal(t, "AE_1", entries[0].GUID)
+	_, _, _, err = b.Stores.AuditStore.GetEntries(ctx, audit.Filter{}, "AE_MISSING", 2)
+	require.Error(t, err)
+
+	entries, total, _, err = b.Stores.AuditStore.GetEntries(ctx, audit.Filter{PageID: "PG_1"}, "", 10)
+	require.NoError(t, err)
+	require.Equal(t, 2, total)
+	require.Equal(t, []string{"AE_2", "AE_1"}, []string{entries[0].GUID, entries[1].GUID})
+	entries, total, _, err = b.Stores.AuditStore.GetEntries(ctx, audit.Filter{UserID: "UR_2"}, "", 10)
+	require.NoError(t, err)
+	require.Equal(t, 1, total)
+	require.Equal(t, "AE_3", entries[0].GUID)
+	entries, total, _, err = b.Stores.AuditStore.GetEntries(ctx, audit.Filter{PageID: "PG_1", ActorType: audit.ActorProxyUser}, "", 10)
+	require.NoError(t, err)
+	require.Equal(t, 1, total)
+	require.Equal(t, "AE_1", entries[0].GUID)
+	_, total, _, err = b.Stores.AuditStore.GetEntries(ctx, audit.Filter{TargetID: "WH_1"}, "", 10)
+	require.NoError(t, err)
+	require.Equal(t, 1, total)
+	since := time.Now().Add(time.Hour)
+	entries, total, nextBatchID, err = b.Stores.AuditStore.GetEntries(ctx, audit.Filter{Since: &since}, "", 10)
+	require.NoError(t, err)
+	require.Zero(t, total)
+	require.Empty(t, entries)
+	require.Empty(t, nextBatchID)
+	_, total, _, err = b.Stores.AuditStore.GetEntries(ctx, audit.Filter{Until: &since}, "", 10)
+	require.NoError(t, err)
+	require.Equal(t, 3, total)
+}
+
+func getCommentGUIDs(comments []comment.Comment) []string {
+	guids := make([]string, 0, len(comments))
+	for _, c := range comments {
+		guids = append(guids, c.GUID)
+	}
+	return guids
+}
+
+func testComments(t *testing.T, b Backend) {
+	ctx := context.Background()
+	refs := getPageRefs(t, b)
+	createPage(t, b, refs, "PG_1", permission.TypePrivate)
+	createPage(t, b, refs, "PG_2", permission.TypePrivate)
+	records := []comment.Comment{
+		{GUID: "CM_1", PageGUID: "PG_1", UserID: "UR_1", Body: "about the page"},
+		{GUID: "CM_2", PageGUID: "PG_1", UserID: "UR_2", Body: "about a detail @UR_1", Mentions: []string{"UR_1"}, Anchor: &comment.Anchor{DetailID: "PD_1", PartitionID: "P_1"}},
+		{GUID: "CM_3", PageGUID: "PG_1", ThreadGUID: "CM_1", UserID: "UR_2", Body: "first reply"},
+		{GUID: "CM_4", PageGUID: "PG_1", ThreadGUID: "CM_1", UserID: "UR_1", Body: "second reply @UR_2 @UR_1", Mentions: []string{"UR_2", "UR_1"}},
+		{GUID: "CM_5", PageGUID: "PG_2", UserID: "UR_1", Body: "on another page"},
+	}
+	for _, record := range records {
+		created, err := b.Stores.CommentStore.CreateComment(ctx, record)
+		require.NoError(t, err)
+		require.NotNil(t, created.CreatedAt)
+	}
+	_, err := b.Stores.CommentStore.CreateComment(ctx, comment.Comment{GUID: "CM_6", PageGUID: "PG_MISSING", UserID: "UR_1", Body: "body"})
+	requireNotFound(t, err)
+	_, err = b.Stores.CommentStore.CreateComment(ctx, comment.Comment{GUID: "CM_6", PageGUID: "PG_1", UserID: "UR_1"})
+	require.Error(t, err)
+
+	c, err := b.Stores.CommentStore.GetComment(ctx, "CM_2")
+	require.NoError(t, err)
+	require.Equal(t, "PG_1", c.PageGUID)
+	require.Equal(t, "UR_2", c.UserID)
+	require.Empty(t, c.ThreadGUID)
+	require.Equal(t, &comment.Anchor{DetailID: "PD_1", PartitionID: "P_1"}, c.Anchor)
+	require.Equal(t, "about a detail @UR_1", c.Body)
+	require.Equal(t, []string{"UR_1"}, c.Mentions)
+	require.Nil(t, c.ResolvedAt)
+	require.NotNil(t, c.CreatedAt)
+	require.NotNil(t, c.UpdatedAt)
+	c, err = b.Stores.CommentStore.GetComment(ctx, "CM_3")
+	require.NoError(t, err)
+	require.Equal(t, "CM_1", c.ThreadGUID)
+	require.Nil(t, c.Anchor)
+	require.Equal(t, []string{}, c.Mentions)
+	_, err = b.Stores.CommentStore.GetComment(ctx, "CM_MISSING")
+	requireNotFound(t, err)
+
+	threads, total, nextBatchID, err := b.Stores.CommentStore.GetThreads(ctx, "PG_1", comment.Filter{}, "", 1)
+	require.NoError(t, err)
+	require.Equal(t, 2, total)
+	require.Equal(t, "CM_1", nextBatchID)
+	require.Equal(t, []string{"CM_2"}, getCommentGUIDs(threads))
+	threads, _, nextBatchID, err = b.Stores.CommentStore.GetThreads(ctx, "PG_1", comment.Filter{}, nextBatchID, 1)
+	require.NoError(t, err)
+	require.Empty(t, nextBatchID)
+	require.Equal(t, []string{"CM_1"}, getCommentGUIDs(threads))
+	threads, total, _, err = b.Stores.CommentStore.GetThreads(ctx, "PG_1", comment.Filter{DetailID: "PD_1"}, "", 10)
+	require.NoError(t, err)
+	require.Equal(t, 1, total)
+	require.Equal(t, []string{"CM_2"}, getCommentGUIDs(threads))
+
+	replies, total, nextBatchID, err := b.Stores.CommentStore.GetReplies(ctx, "CM_1", "", 1)
+	require.NoError(t, err)
+	require.Equal(t, 2, total)
+	require.Equal(t, "CM_4", nextBatchID)
+	require.Equal(t, []string{"CM_3"}, getCommentGUIDs(replies))
+	replies, _, nextBatchID, err = b.Stores.CommentStore.GetReplies(ctx, "CM_1", nextBatchID, 1)
+	require.NoError(t, err)
+	require.Empty(t, nextBatchID)
+	require.Equal(t, []string{"CM_4"}, getCommentGUIDs(replies))
+	_, _, _, err = b.Stores.CommentStore.GetReplies(ctx, "CM_1", "CM_MISSING", 1)
+	require.Error(t, err)
+
+	mentions, total, _, err := b.Stores.CommentStore.GetMentions(ctx, "UR_1", "", 10)
+	require.NoError(t, err)
+	require.Equal(t, 2, total)
+	require.Equal(t, []string{"CM_4", "CM_2"}, getCommentGUIDs(mentions))
+
+	resolvedAt := time.Now().Truncate(time.Second)
+	updated, err := b.Stores.CommentStore.UpdateComment(ctx, comment.Comment{GUID: "CM_2", Body: "edited, cc @UR_2", Mentions: []string{"UR_2"}, ResolvedAt: &resolvedAt, ResolvedBy: "UR_1"})
+	require.NoError(t, err)
+	require.Equal(t, "edited, cc @UR_2", updated.Body)
+	require.Equal(t, []string{"UR_2"}, updated.Mentions)
+	require.NotNil(t, updated.ResolvedAt)
+	require.Equal(t, "UR_1", updated.ResolvedBy)
+	require.Equal(t, &comment.Anchor{DetailID: "PD_1", PartitionID: "P_1"}, updated.Anchor, "updating doesn't move the thread")
+	_, err = b.Stores.CommentStore.UpdateComment(ctx, comment.Comment{GUID: "CM_MISSING", Body: "body"})
+	requireNotFound(t, err)
+	mentions, _, _, err = b.Stores.CommentStore.GetMentions(ctx, "UR_1", "", 10)
+	require.NoError(t, err)
+	require.Equal(t, []string{"CM_4"}, getCommentGUIDs(mentions))
+	resolved, open := true, false
+	threads, _, _, err = b.Stores.CommentStore.GetThreads(ctx, "PG_1", comment.Filter{Resolved: &resolved}, "", 10)
+	require.NoError(t, err)
+	require.Equal(t, []string{"CM_2"}, getCommentGUIDs(threads))
+	threads, _, _, err = b.Stores.CommentStore.GetThreads(ctx, "PG_1", comment.Filter{Resolved: &open}, "", 10)
+	require.NoError(t, err)
+	require.Equal(t, []string{"CM_1"}, getCommentGUIDs(threads))
+
+	err = b.Stores.CommentStore.RemoveComment(ctx, "CM_1")
+	require.NoError(t, err)
+	_, err = b.Stores.CommentStore.GetComment(ctx, "CM_4")
+	requireNotFound(t, err)
+	mentions, total, _, err = b.Stores.CommentStore.GetMentions(ctx, "UR_1", "", 10)
+	require.NoError(t, err)
+	require.Zero(t, total)
+	require.Empty(t, mentions)
+	err = b.Stores.CommentStore.RemoveComment(ctx, "CM_1")
+	requireNotFound(t, err)
+
+	err = b.Stores.PageStore.RemovePage(ctx, "PG_2")
+	require.NoError(t, err)
+	err = b.Stores.PageStore.PurgePage(ctx, "PG_2")
+	require.NoError(t, err)
+	_, err = b.Stores.CommentStore.GetComment(ctx, "CM_5")
+	requireNotFound(t, err)
+}
+
+func testUnitOfWork(t *testing.T, b Backend) {
+	ctx := context.Background()
+	refs := getPageRefs(t, b)
+	errFailed := errors.New("failed after the last store call")
+
+	err := b.UnitOfWork.Do(ctx, func(stores store.Stores) error {
+		createPage(t, Backend{Stores: stores}, refs, "PG_ROLLBACK", permission.TypePrivate)
+		err := stores.PageStore.ReplacePageProperties(ctx, "PG_ROLLBACK", []property.Property{
+			{Key: "color", Type: property.TypeString, Value: "blue"},
+		})
+		require.NoError(t, err)
+		_, err = stores.PageStore.GetPage(ctx, "PG_ROLLBACK")
+		require.NoError(t, err)
+		return errFailed
+	})
+	require.Equal(t, errFailed, err)
+	_, err = b.Stores.PageStore.GetPage(ctx, "PG_ROLLBACK")
+	requireNotFound(t, err)
+	_, err = b.Stores.PageStore.CanEditPage(ctx, "PG_ROLLBACK", "UR_1")
+	requireNotAuthorized(t, err)
+
+	err = b.UnitOfWork.Do(ctx, func(stores store.Stores) error {
+		createPage(t, Backend{Stores: stores}, refs, "PG_COMMIT", permission.TypePrivate)
+		return stores.PageStore.ReplacePageProperties(ctx, "PG_COMMIT", []property.Property{
+			{Key: "color", Type: property.TypeString, Value: "blue"},
+		})
+	})
+	require.NoError(t, err)
+	_, err = b.Stores.PageStore.GetPage(ctx, "PG_COMMIT")
+	require.NoError(t, err)
+	properties, err := b.Stores.PageStore.GetPageProperties(ctx, "PG_COMMIT")
+	require.NoError(t, err)
+	require.Len(t, properties, 1)
+}
@@ -0,0 +1,65 @@
+// Package auditlog lets the services describe the changes a request made, so they can be written to its audit entries.
+package auditlog
+
+import (
+	"context"
+	"sync"
+)
+
+// Change is what a request did to a single target.
+type Change struct {
+	TargetID string
+	PageID   string
+	// Before and After summarize the target as it was before and after the change; they're encoded as JSON.
+	Before interface{}
+	After  interface{}
+}
+
+// Recorder collects the changes made while handling a request.
+type Recorder struct {
+	mu      sync.Mutex
+	changes []Change
+}
+
+// Changes returns the changes recorded so far, in the order they were made.
+func (r *Recorder) Changes() []Change {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Change(nil), r.changes...)
+}
+
+type recorderKeyType string
+
+const recorderKey = recorderKeyType("auditRecorder")
+
+// WithRecorder returns a context with a new Recorder on it, along with the Recorder.
+func WithRecorder(ctx context.Context) (context.Context, *Recorder) {
+	r := &Recorder{}
+	return context.WithValue(ctx, recorderKey, r), r
+}
+
+// Record adds the change to the context's Recorder; it does nothing when there isn't one,
+// such as for changes made by the jobs rather than a request.
+func Record(ctx context.Context, change Change) {
+	r, ok := ctx.Value(recorderKey).(*Recorder)
+	if !ok {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.changes = append(r.changes, change)
+}
+
+// WithBuffer returns a context whose changes are held back from the context's Recorder until flush is called,
+// for changes made within a transaction that may still be rolled back. Changes that are never flushed are dropped.
+func WithBuffer(ctx context.Context) (context.Context, func()) {
+	if _, ok := ctx.Value(recorderKey).(*Recorder); !ok {
+		return ctx, func() {}
+	}
+	bufferedCtx, buffer := WithRecorder(ctx)
+	return bufferedCtx, func() {
+		for _, change := range buffer.Changes() {
+			Record(ctx, change)
+		}
+	}
+}
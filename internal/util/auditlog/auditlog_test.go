@@ -0,0 +1,99 @@
+package auditlog
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecord(t *testing.T) {
+	cases := []struct {
+		name          string
+		withRecorder  bool
+		paramChanges  []Change
+		returnChanges []Change
+	}{
+		{
+			name:         "test changes are recorded in order",
+			withRecorder: true,
+			paramChanges: []Change{
+				{TargetID: "PG_1", PageID: "PG_1", After: "a"},
+				{TargetID: "PD_1", PageID: "PG_1", Before: "b"},
+			},
+			returnChanges: []Change{
+				{TargetID: "PG_1", PageID: "PG_1", After: "a"},
+				{TargetID: "PD_1", PageID: "PG_1", Before: "b"},
+			},
+		},
+		{
+			name:         "test nothing recorded without changes",
+			withRecorder: true,
+		},
+		{
+			name: "test changes are ignored without a recorder",
+			paramChanges: []Change{
+				{TargetID: "PG_1"},
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			var recorder *Recorder
+			if tc.withRecorder {
+				ctx, recorder = WithRecorder(ctx)
+			}
+			for _, change := range tc.paramChanges {
+				Record(ctx, change)
+			}
+			if recorder != nil {
+				require.Equal(t, tc.returnChanges, recorder.Changes())
+			}
+		})
+	}
+}
+
+func TestWithBuffer(t *testing.T) {
+	cases := []struct {
+		name          string
+		withRecorder  bool
+		flush         bool
+		returnChanges []Change
+	}{
+		{
+			name:          "test flushed changes are recorded",
+			withRecorder:  true,
+			flush:         true,
+			returnChanges: []Change{{TargetID: "PG_0"}, {TargetID: "PG_1"}, {TargetID: "PG_2"}},
+		},
+		{
+			name:          "test changes that aren't flushed are dropped",
+			withRecorder:  true,
+			returnChanges: []Change{{TargetID: "PG_0"}},
+		},
+		{
+			name:  "test buffer without a recorder",
+			flush: true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			var recorder *Recorder
+			if tc.withRecorder {
+				ctx, recorder = WithRecorder(ctx)
+			}
+			Record(ctx, Change{TargetID: "PG_0"})
+			bufferedCtx, flush := WithBuffer(ctx)
+			Record(bufferedCtx, Change{TargetID: "PG_1"})
+			Record(bufferedCtx, Change{TargetID: "PG_2"})
+			if tc.flush {
+				flush()
+			}
+			if recorder != nil {
+				require.Equal(t, tc.returnChanges, recorder.Changes())
+			}
+		})
+	}
+}
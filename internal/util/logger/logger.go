@@ -0,0 +1,37 @@
+// Package logger provides the service's configured zap logger and carries request-scoped loggers on the context.
+package logger
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+// New returns the service's logger.
+// Development loggers are human readable and include debug logs; otherwise logs are JSON at info level and above.
+func New(development bool) (*zap.Logger, error) {
+	if development {
+		return zap.NewDevelopment()
+	}
+	return zap.NewProduction()
+}
+
+type loggerKeyType string
+
+const loggerKey = loggerKeyType("logger")
+
+// SetOnContext sets the logger on the context, typically one already scoped to the request's fields.
+func SetOnContext(ctx context.Context, l *zap.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey, l)
+}
+
+// GetFromContext returns the logger from the context.
+// A no-op logger is returned if none is set so callers never need to check.
+func GetFromContext(ctx context.Context) *zap.Logger {
+	if ctx != nil {
+		if l, ok := ctx.Value(loggerKey).(*zap.Logger); ok && l != nil {
+			return l
+		}
+	}
+	return zap.NewNop()
+}
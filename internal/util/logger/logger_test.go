@@ -0,0 +1,22 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestGetFromContext(t *testing.T) {
+	require.NotNil(t, GetFromContext(context.Background()))
+	var buf bytes.Buffer
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), zapcore.AddSync(&buf), zapcore.InfoLevel)
+	l := zap.New(core).With(zap.String("requestId", "REQUEST"))
+	ctx := SetOnContext(context.Background(), l)
+	GetFromContext(ctx).Info("hello")
+	require.Contains(t, buf.String(), `"requestId":"REQUEST"`)
+	require.Contains(t, buf.String(), `"msg":"hello"`)
+}
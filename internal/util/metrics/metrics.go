@@ -0,0 +1,318 @@
+// Package metrics is a minimal metrics registry that is exposed in the Prometheus text format.
+// It has no dependency on a Prometheus client or server so it can be scraped by anything that understands the format.
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Default is the registry the service's metrics are registered on and that is served on /metrics.
+var Default = NewRegistry()
+
+// DefaultBuckets are the histogram buckets, in seconds, used for latencies.
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// labelSeparator joins label values into a series key; it can't appear in valid UTF-8.
+const labelSeparator = "\xff"
+
+type metric interface {
+	name() string
+	write(w io.Writer)
+}
+
+// Registry holds a set of metrics and writes them in the Prometheus text format.
+type Registry struct {
+	mu      sync.Mutex
+	metrics map[string]metric
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		metrics: make(map[string]metric),
+	}
+}
+
+func (r *Registry) register(m metric) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.metrics[m.name()]; ok {
+		panic(fmt.Sprintf("metric %v is already registered", m.name()))
+	}
+	r.metrics[m.name()] = m
+}
+
+// Write writes every metric, sorted by name, in the Prometheus text format.
+func (r *Registry) Write(w io.Writer) error {
+	r.mu.Lock()
+	names := make([]string, 0, len(r.metrics))
+	for name := range r.metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	metrics := make([]metric, 0, len(names))
+	for _, name := range names {
+		metrics = append(metrics, r.metrics[name])
+	}
+	r.mu.Unlock()
+	var buf bytes.Buffer
+	for _, m := range metrics {
+		m.write(&buf)
+	}
+	_, err := buf.WriteTo(w)
+	if err != nil {
+		return errors.Wrap(err, "unable to write metrics")
+	}
+	return nil
+}
+
+type desc struct {
+	metricName string
+	help       string
+	metricType string
+	labelNames []string
+}
+
+func (d desc) name() string {
+	return d.metricName
+}
+
+func (d desc) writeHeader(w io.Writer) {
+	fmt.Fprintf(w, "# HELP %v %v\n", d.metricName, escapeHelp(d.help))
+	fmt.Fprintf(w, "# TYPE %v %v\n", d.metricName, d.metricType)
+}
+
+func (d desc) checkLabelValues(labelValues []string) {
+	if len(labelValues) != len(d.labelNames) {
+		panic(fmt.Sprintf("metric %v expects %v label values but got %v", d.metricName, len(d.labelNames), len(labelValues)))
+	}
+}
+
+// CounterVec is a set of counters partitioned by label values.
+type CounterVec struct {
+	desc
+	mu     sync.Mutex
+	series map[string]*counterSeries
+}
+
+type counterSeries struct {
+	labelValues []string
+	value       float64
+}
+
+// NewCounterVec registers and returns a new CounterVec.
+func (r *Registry) NewCounterVec(name, help string, labelNames ...string) *CounterVec {
+	c := &CounterVec{
+		desc:   desc{metricName: name, help: help, metricType: "counter", labelNames: labelNames},
+		series: make(map[string]*counterSeries),
+	}
+	r.register(c)
+	return c
+}
+
+// Inc increments the counter for the given label values by 1.
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add increments the counter for the given label values by v, which must not be negative.
+func (c *CounterVec) Add(v float64, labelValues ...string) {
+	c.checkLabelValues(labelValues)
+	if v < 0 {
+		panic(fmt.Sprintf("counter %v can not decrease", c.metricName))
+	}
+	key := strings.Join(labelValues, labelSeparator)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s, ok := c.series[key]
+	if !ok {
+		s = &counterSeries{labelValues: append([]string(nil), labelValues...)}
+		c.series[key] = s
+	}
+	s.value += v
+}
+
+// Value returns the current value of the counter for the given label values.
+func (c *CounterVec) Value(labelValues ...string) float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if s, ok := c.series[strings.Join(labelValues, labelSeparator)]; ok {
+		return s.value
+	}
+	return 0
+}
+
+func (c *CounterVec) write(w io.Writer) {
+	c.writeHeader(w)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	keys := make([]string, 0, len(c.series))
+	for key := range c.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		s := c.series[key]
+		fmt.Fprintf(w, "%v%v %v\n", c.metricName, formatLabels(c.labelNames, s.labelValues), formatValue(s.value))
+	}
+}
+
+// HistogramVec is a set of histograms partitioned by label values.
+type HistogramVec struct {
+	desc
+	buckets []float64
+	mu      sync.Mutex
+	series  map[string]*histogramSeries
+}
+
+type histogramSeries struct {
+	labelValues  []string
+	bucketCounts []uint64
+	count        uint64
+	sum          float64
+}
+
+// NewHistogramVec registers and returns a new HistogramVec with the given upper bounds for its buckets.
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labelNames ...string) *HistogramVec {
+	sortedBuckets := append([]float64(nil), buckets...)
+	sort.Float64s(sortedBuckets)
+	h := &HistogramVec{
+		desc:    desc{metricName: name, help: help, metricType: "histogram", labelNames: labelNames},
+		buckets: sortedBuckets,
+		series:  make(map[string]*histogramSeries),
+	}
+	r.register(h)
+	return h
+}
+
+// Observe adds the value to the histogram for the given label values.
+func (h *HistogramVec) Observe(v float64, labelValues ...string) {
+	h.checkLabelValues(labelValues)
+	key := strings.Join(labelValues, labelSeparator)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s, ok := h.series[key]
+	if !ok {
+		s = &histogramSeries{
+			labelValues:  append([]string(nil), labelValues...),
+			bucketCounts: make([]uint64, len(h.buckets)),
+		}
+		h.series[key] = s
+	}
+	for i, upperBound := range h.buckets {
+		if v <= upperBound {
+			s.bucketCounts[i]++
+		}
+	}
+	s.count++
+	s.sum += v
+}
+
+// Count returns the number of observations for the given label values.
+func (h *HistogramVec) Count(labelValues ...string) uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if s, ok := h.series[strings.Join(labelValues, labelSeparator)]; ok {
+		return s.count
+	}
+	return 0
+}
+
+func (h *HistogramVec) write(w io.Writer) {
+	h.writeHeader(w)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	bucketLabelNames := append(append([]string(nil), h.labelNames...), "le")
+	keys := make([]string, 0, len(h.series))
+	for key := range h.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		s := h.series[key]
+		for i, upperBound := range h.buckets {
+			bucketLabelValues := append(append([]string(nil), s.labelValues...), formatValue(upperBound))
+			fmt.Fprintf(w, "%v_bucket%v %v\n", h.metricName, formatLabels(bucketLabelNames, bucketLabelValues), s.bucketCounts[i])
+		}
+		infLabelValues := append(append([]string(nil), s.labelValues...), "+Inf")
+		fmt.Fprintf(w, "%v_bucket%v %v\n", h.metricName, formatLabels(bucketLabelNames, infLabelValues), s.count)
+		labels := formatLabels(h.labelNames, s.labelValues)
+		fmt.Fprintf(w, "%v_sum%v %v\n", h.metricName, labels, formatValue(s.sum))
+		fmt.Fprintf(w, "%v_count%v %v\n", h.metricName, labels, s.count)
+	}
+}
+
+// ValueFunc is a metric without labels whose value is read when the metric is written.
+type ValueFunc struct {
+	desc
+	fn func() float64
+}
+
+// NewGaugeFunc registers a gauge whose value is returned by fn.
+func (r *Registry) NewGaugeFunc(name, help string, fn func() float64) *ValueFunc {
+	v := &ValueFunc{
+		desc: desc{metricName: name, help: help, metricType: "gauge"},
+		fn:   fn,
+	}
+	r.register(v)
+	return v
+}
+
+// NewCounterFunc registers a counter whose value is returned by fn, which must never decrease.
+func (r *Registry) NewCounterFunc(name, help string, fn func() float64) *ValueFunc {
+	v := &ValueFunc{
+		desc: desc{metricName: name, help: help, metricType: "counter"},
+		fn:   fn,
+	}
+	r.register(v)
+	return v
+}
+
+func (v *ValueFunc) write(w io.Writer) {
+	v.writeHeader(w)
+	fmt.Fprintf(w, "%v %v\n", v.metricName, formatValue(v.fn()))
+}
+
+func formatLabels(names, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	pairs := make([]string, 0, len(names))
+	for i, name := range names {
+		pairs = append(pairs, fmt.Sprintf("%v=\"%v\"", name, escapeLabelValue(values[i])))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var helpReplacer = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+func escapeHelp(help string) string {
+	return helpReplacer.Replace(help)
+}
+
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+func escapeLabelValue(value string) string {
+	return labelValueReplacer.Replace(value)
+}
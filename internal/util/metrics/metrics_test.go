@@ -0,0 +1,93 @@
+package metrics
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrite(t *testing.T) {
+	cases := []struct {
+		name           string
+		paramRegister  func(r *Registry)
+		returnExpected string
+	}{
+		{
+			name: "test counter vec",
+			paramRegister: func(r *Registry) {
+				c := r.NewCounterVec("requests_total", "Total requests.", "method", "status")
+				c.Inc("GET", "200")
+				c.Inc("GET", "200")
+				c.Add(1.5, "POST", "500")
+			},
+			returnExpected: "# HELP requests_total Total requests.\n" +
+				"# TYPE requests_total counter\n" +
+				"requests_total{method=\"GET\",status=\"200\"} 2\n" +
+				"requests_total{method=\"POST\",status=\"500\"} 1.5\n",
+		},
+		{
+			name: "test histogram vec",
+			paramRegister: func(r *Registry) {
+				h := r.NewHistogramVec("latency_seconds", "Latency.", []float64{1, 0.1}, "route")
+				h.Observe(0.05, "/a")
+				h.Observe(0.5, "/a")
+				h.Observe(3, "/a")
+			},
+			returnExpected: "# HELP latency_seconds Latency.\n" +
+				"# TYPE latency_seconds histogram\n" +
+				"latency_seconds_bucket{route=\"/a\",le=\"0.1\"} 1\n" +
+				"latency_seconds_bucket{route=\"/a\",le=\"1\"} 2\n" +
+				"latency_seconds_bucket{route=\"/a\",le=\"+Inf\"} 3\n" +
+				"latency_seconds_sum{route=\"/a\"} 3.55\n" +
+				"latency_seconds_count{route=\"/a\"} 3\n",
+		},
+		{
+			name: "test value funcs are sorted by name",
+			paramRegister: func(r *Registry) {
+				r.NewGaugeFunc("b_gauge", "A gauge.", func() float64 { return 4 })
+				r.NewCounterFunc("a_total", "A counter.", func() float64 { return math.Inf(1) })
+			},
+			returnExpected: "# HELP a_total A counter.\n" +
+				"# TYPE a_total counter\n" +
+				"a_total +Inf\n" +
+				"# HELP b_gauge A gauge.\n" +
+				"# TYPE b_gauge gauge\n" +
+				"b_gauge 4\n",
+		},
+		{
+			name: "test help and label values are escaped",
+			paramRegister: func(r *Registry) {
+				r.NewCounterVec("escaped_total", "Line one\nline \\two.", "value").Inc("a \"quoted\"\nvalue\\")
+			},
+			returnExpected: "# HELP escaped_total Line one\\nline \\\\two.\n" +
+				"# TYPE escaped_total counter\n" +
+				"escaped_total{value=\"a \\\"quoted\\\"\\nvalue\\\\\"} 1\n",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := NewRegistry()
+			tc.paramRegister(r)
+			var buf bytes.Buffer
+			require.NoError(t, r.Write(&buf))
+			require.Equal(t, tc.returnExpected, buf.String())
+		})
+	}
+}
+
+func TestRegisterDuplicate(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounterVec("dup_total", "Duplicate.")
+	require.Panics(t, func() {
+		r.NewGaugeFunc("dup_total", "Duplicate.", func() float64 { return 0 })
+	})
+}
+
+func TestWrongLabelCount(t *testing.T) {
+	c := NewRegistry().NewCounterVec("labels_total", "Labels.", "a", "b")
+	require.Panics(t, func() {
+		c.Inc("only one")
+	})
+}
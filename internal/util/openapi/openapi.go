@@ -0,0 +1,265 @@
+// Package openapi checks JSON requests and responses against the service's OpenAPI 3 spec, which is generated from
+// its routes, so the docs and the handlers can't drift apart.
+// Unlike OpenAPI, an object can't have properties its schema doesn't list unless the schema sets additionalProperties,
+// and a request can't set a readOnly property. Required properties are only checked in responses, since the generated
+// schemas are of how each type is marshaled.
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/worlve/sp-service/internal/util/openapi3"
+	"github.com/pkg/errors"
+)
+
+const jsonMediaType = "application/json"
+
+// schemaRefPrefix is where $refs to the document's component schemas start; no other $refs are supported.
+const schemaRefPrefix = "#/components/schemas/"
+
+// maxRefHops is how many $refs in a row are followed before the spec is considered to have a $ref cycle.
+const maxRefHops = 32
+
+// Spec is an OpenAPI 3 spec.
+type Spec struct {
+	Operations []*Operation
+	components map[string]*openapi3.Schema
+}
+
+// Operation is a single method on a path in the spec.
+type Operation struct {
+	ID     string
+	Method string
+	// Path is the path's template, such as /api/pages/{pageID}.
+	Path       string
+	spec       *Spec
+	segments   []string
+	definition *openapi3.Operation
+}
+
+// Violation is why part of a request or response doesn't match the spec.
+type Violation struct {
+	// Field is the path of the value in the body, such as operations[1].title, or the name of a query parameter.
+	Field   string
+	Message string
+}
+
+func (v Violation) String() string {
+	if v.Field == "" {
+		return v.Message
+	}
+	return fmt.Sprintf("%v: %v", v.Field, v.Message)
+}
+
+// New returns the spec of the generated document. It errors if any $ref can't be resolved.
+func New(document openapi3.Document) (*Spec, error) {
+	s := &Spec{components: document.Components.Schemas}
+	for pathTemplate, pathItem := range document.Paths {
+		for method, definition := range pathItem {
+			if definition == nil {
+				continue
+			}
+			s.Operations = append(s.Operations, &Operation{
+				ID:         definition.OperationID,
+				Method:     strings.ToUpper(method),
+				Path:       pathTemplate,
+				spec:       s,
+				segments:   strings.Split(strings.Trim(pathTemplate, "/"), "/"),
+				definition: definition,
+			})
+		}
+	}
+	sort.Slice(s.Operations, func(i, j int) bool {
+		if s.Operations[i].Path == s.Operations[j].Path {
+			return s.Operations[i].Method < s.Operations[j].Method
+		}
+		return s.Operations[i].Path < s.Operations[j].Path
+	})
+	err := s.checkRefs(document)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Parse reads a spec written as JSON, such as the one served at /api/openapi.json.
+func Parse(content []byte) (*Spec, error) {
+	var document openapi3.Document
+	err := json.Unmarshal(content, &document)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse the spec")
+	}
+	return New(document)
+}
+
+// Find returns the operation for the method and path, such as /api/pages/PG_1.
+// When more than one path template matches, the one with the most literal segments wins.
+func (s *Spec) Find(method, path string) (*Operation, bool) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	var found *Operation
+	foundLiterals := -1
+	for _, operation := range s.Operations {
+		if operation.Method != method {
+			continue
+		}
+		literals, ok := operation.match(segments)
+		if ok && literals > foundLiterals {
+			found, foundLiterals = operation, literals
+		}
+	}
+	return found, found != nil
+}
+
+// match returns whether the segments match the operation's path, and how many of them matched literally.
+func (o *Operation) match(segments []string) (int, bool) {
+	if len(segments) != len(o.segments) {
+		return 0, false
+	}
+	literals := 0
+	for i, segment := range o.segments {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			if segments[i] == "" {
+				return 0, false
+			}
+			continue
+		}
+		if segment != segments[i] {
+			return 0, false
+		}
+		literals++
+	}
+	return literals, true
+}
+
+// HasJSONBody returns whether the operation takes a JSON body that ValidateRequest checks.
+func (o *Operation) HasJSONBody() bool {
+	if o.definition.RequestBody == nil {
+		return false
+	}
+	_, ok := o.definition.RequestBody.Content[jsonMediaType]
+	return ok
+}
+
+// ValidateRequest returns how the query parameters and body don't match the spec.
+// The body is only checked when the operation takes a JSON body.
+func (o *Operation) ValidateRequest(query url.Values, body []byte) []Violation {
+	v := &validator{spec: o.spec, request: true}
+	for _, parameter := range o.definition.Parameters {
+		if parameter.In != "query" {
+			continue
+		}
+		value, ok := query[parameter.Name]
+		if !ok {
+			if parameter.Required {
+				v.add(parameter.Name, "is required")
+			}
+			continue
+		}
+		v.validateQuery(parameter.Name, parameter.Schema, value[0])
+	}
+	if requestBody := o.definition.RequestBody; requestBody != nil {
+		if mediaType, ok := requestBody.Content[jsonMediaType]; ok && (requestBody.Required || len(body) > 0) {
+			v.validateBody(mediaType.Schema, body)
+		}
+	}
+	return v.violations
+}
+
+// ValidateResponse returns how the JSON body sent with status doesn't match the spec.
+// Responses with a status the operation doesn't document aren't checked.
+func (o *Operation) ValidateResponse(status int, body []byte) []Violation {
+	response, ok := o.definition.Responses[fmt.Sprint(status)]
+	if !ok {
+		response, ok = o.definition.Responses["default"]
+	}
+	if !ok {
+		return nil
+	}
+	mediaType, ok := response.Content[jsonMediaType]
+	if !ok {
+		return nil
+	}
+	v := &validator{spec: o.spec}
+	v.validateBody(mediaType.Schema, body)
+	return v.violations
+}
+
+// resolve follows the schema's $refs until it gets to a schema without one.
+// Only $refs to the component schemas, such as #/components/schemas/Meta, are supported.
+func (s *Spec) resolve(schema *openapi3.Schema) (*openapi3.Schema, error) {
+	for hops := 0; hops < maxRefHops; hops++ {
+		if schema.Ref == "" {
+			return schema, nil
+		}
+		if !strings.HasPrefix(schema.Ref, schemaRefPrefix) {
+			return schema, errors.Errorf("$ref %v is not to a schema within the spec", schema.Ref)
+		}
+		target, ok := s.components[strings.TrimPrefix(schema.Ref, schemaRefPrefix)]
+		if !ok || target == nil {
+			return schema, errors.Errorf("failed to resolve $ref %v", schema.Ref)
+		}
+		schema = target
+	}
+	return schema, errors.New("too many $refs in a row, there may be a cycle")
+}
+
+// checkRefs resolves every $ref in the document.
+func (s *Spec) checkRefs(document openapi3.Document) error {
+	var schemas []*openapi3.Schema
+	for _, schema := range document.Components.Schemas {
+		schemas = append(schemas, schema)
+	}
+	for _, operation := range s.Operations {
+		for _, parameter := range operation.definition.Parameters {
+			schemas = append(schemas, parameter.Schema)
+		}
+		if operation.definition.RequestBody != nil {
+			for _, mediaType := range operation.definition.RequestBody.Content {
+				schemas = append(schemas, mediaType.Schema)
+			}
+		}
+		for _, response := range operation.definition.Responses {
+			for _, mediaType := range response.Content {
+				schemas = append(schemas, mediaType.Schema)
+			}
+		}
+	}
+	seen := map[*openapi3.Schema]bool{}
+	for _, schema := range schemas {
+		err := s.checkSchemaRefs(schema, seen)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkSchemaRefs resolves every $ref in the schema and the schemas within it.
+func (s *Spec) checkSchemaRefs(schema *openapi3.Schema, seen map[*openapi3.Schema]bool) error {
+	if schema == nil || seen[schema] {
+		return nil
+	}
+	seen[schema] = true
+	if schema.Ref != "" {
+		resolved, err := s.resolve(schema)
+		if err != nil {
+			return err
+		}
+		return s.checkSchemaRefs(resolved, seen)
+	}
+	children := append([]*openapi3.Schema{schema.Items, schema.AdditionalProperties}, schema.AllOf...)
+	for _, property := range schema.Properties {
+		children = append(children, property)
+	}
+	for _, child := range children {
+		err := s.checkSchemaRefs(child, seen)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
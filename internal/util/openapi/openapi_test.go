@@ -0,0 +1,358 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/worlve/sp-service/internal/util/openapi3"
+)
+
+// testDocument returns a document with a schema for each of the checks.
+func testDocument() openapi3.Document {
+	itemRef := openapi3.Ref("item")
+	return openapi3.Document{
+		OpenAPI: openapi3.Version,
+		Components: openapi3.Components{Schemas: map[string]*openapi3.Schema{
+			"meta": {
+				Type:       "object",
+				Required:   []string{"httpStatus"},
+				Properties: map[string]*openapi3.Schema{"httpStatus": {Type: "string"}},
+			},
+			"item": {
+				Type:     "object",
+				Required: []string{"id", "name", "tags"},
+				Properties: map[string]*openapi3.Schema{
+					"id":        {Type: "string", ReadOnly: true},
+					"name":      {Type: "string"},
+					"count":     {Type: "integer"},
+					"tags":      {Type: "array", Items: openapi3.Ref("tag")},
+					"labels":    {Type: "object", AdditionalProperties: &openapi3.Schema{Type: "string"}},
+					"value":     {},
+					"updatedAt": {Type: "string", Format: "date-time", Nullable: true},
+					"parent":    {AllOf: []*openapi3.Schema{openapi3.Ref("meta")}, Nullable: true},
+				},
+			},
+			"tag": {Type: "string", Enum: []string{"red", "blue"}},
+		}},
+		Paths: map[string]openapi3.PathItem{
+			"/items": {
+				"post": &openapi3.Operation{
+					OperationID: "createItem",
+					Parameters: []openapi3.Parameter{
+						{Name: "mode", In: "query", Schema: &openapi3.Schema{Type: "string", Enum: []string{"fast", "slow"}}},
+					},
+					RequestBody: &openapi3.RequestBody{
+						Required: true,
+						Content:  map[string]openapi3.MediaType{"application/json": {Schema: itemRef}},
+					},
+					Responses: map[string]openapi3.Response{
+						"200": {Description: "Item", Content: map[string]openapi3.MediaType{"application/json": {Schema: &openapi3.Schema{
+							Type:       "object",
+							Required:   []string{"result", "meta"},
+							Properties: map[string]*openapi3.Schema{"result": itemRef, "meta": openapi3.Ref("meta")},
+						}}}},
+					},
+				},
+			},
+			"/items/{itemId}": {
+				"get": &openapi3.Operation{OperationID: "getItem", Responses: map[string]openapi3.Response{"200": {Description: "Item"}}},
+			},
+			"/items/latest": {
+				"get": &openapi3.Operation{OperationID: "getLatestItem", Responses: map[string]openapi3.Response{"200": {Description: "Item"}}},
+			},
+			"/items:upload": {
+				"post": &openapi3.Operation{
+					OperationID: "uploadItems",
+					RequestBody: &openapi3.RequestBody{
+						Content: map[string]openapi3.MediaType{"application/zip": {Schema: openapi3.Binary()}},
+					},
+					Responses: map[string]openapi3.Response{
+						"200": {Description: "Uploaded", Content: map[string]openapi3.MediaType{"application/json": {Schema: openapi3.Binary()}}},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestNew(t *testing.T) {
+	cases := []struct {
+		name          string
+		paramDocument func(document openapi3.Document) openapi3.Document
+		returnErr     bool
+		returnPaths   []string
+	}{
+		{
+			name:          "test spec",
+			paramDocument: func(document openapi3.Document) openapi3.Document { return document },
+			returnPaths:   []string{"/items", "/items/latest", "/items/{itemId}", "/items:upload"},
+		},
+		{
+			name: "test missing schema",
+			paramDocument: func(document openapi3.Document) openapi3.Document {
+				delete(document.Components.Schemas, "tag")
+				return document
+			},
+			returnErr: true,
+		},
+		{
+			name: "test ref outside the spec",
+			paramDocument: func(document openapi3.Document) openapi3.Document {
+				document.Components.Schemas["item"].Properties["tags"].Items = &openapi3.Schema{Ref: "items.json#/tag"}
+				return document
+			},
+			returnErr: true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			spec, err := New(tc.paramDocument(testDocument()))
+			if tc.returnErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			var paths []string
+			for _, operation := range spec.Operations {
+				paths = append(paths, operation.Path)
+			}
+			require.Equal(t, tc.returnPaths, paths)
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	content, err := json.Marshal(testDocument())
+	require.NoError(t, err)
+	spec, err := Parse(content)
+	require.NoError(t, err)
+	operation, ok := spec.Find("POST", "/items")
+	require.True(t, ok)
+	require.Equal(t, []Violation{{Field: "id", Message: "is read only"}}, operation.ValidateRequest(nil, []byte(`{"id":"IT_1","name":"test","tags":[]}`)))
+
+	_, err = Parse([]byte("openapi: 3.0.3"))
+	require.Error(t, err)
+}
+
+func TestNewReflected(t *testing.T) {
+	schemas := &openapi3.Schemas{}
+	spec, err := New(openapi3.Document{
+		OpenAPI: openapi3.Version,
+		Paths: map[string]openapi3.PathItem{
+			"/api/items": {
+				"post": &openapi3.Operation{
+					OperationID: "createItem",
+					RequestBody: &openapi3.RequestBody{
+						Required: true,
+						Content: map[string]openapi3.MediaType{
+							"application/json": {Schema: schemas.For(struct {
+								Name string `json:"name"`
+							}{})},
+						},
+					},
+				},
+			},
+		},
+		Components: openapi3.Components{Schemas: schemas.Components},
+	})
+	require.NoError(t, err)
+	operation, ok := spec.Find("POST", "/api/items")
+	require.True(t, ok)
+	require.Equal(t, "createItem", operation.ID)
+	require.Equal(t, []Violation{{Field: "name", Message: "must be a string"}}, operation.ValidateRequest(nil, []byte(`{"name":1}`)))
+}
+
+func TestFind(t *testing.T) {
+	spec, err := New(testDocument())
+	require.NoError(t, err)
+	cases := []struct {
+		name        string
+		paramMethod string
+		paramPath   string
+		returnID    string
+	}{
+		{
+			name:        "test literal path",
+			paramMethod: "POST",
+			paramPath:   "/items",
+			returnID:    "createItem",
+		},
+		{
+			name:        "test templated path",
+			paramMethod: "GET",
+			paramPath:   "/items/IT_1",
+			returnID:    "getItem",
+		},
+		{
+			name:        "test literal segment wins over a template",
+			paramMethod: "GET",
+			paramPath:   "/items/latest",
+			returnID:    "getLatestItem",
+		},
+		{
+			name:        "test custom method",
+			paramMethod: "POST",
+			paramPath:   "/items:upload",
+			returnID:    "uploadItems",
+		},
+		{
+			name:        "test other method",
+			paramMethod: "DELETE",
+			paramPath:   "/items/IT_1",
+		},
+		{
+			name:        "test unknown path",
+			paramMethod: "GET",
+			paramPath:   "/items/IT_1/parts",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			operation, ok := spec.Find(tc.paramMethod, tc.paramPath)
+			require.Equal(t, tc.returnID != "", ok)
+			if ok {
+				require.Equal(t, tc.returnID, operation.ID)
+			}
+		})
+	}
+}
+
+func TestValidateRequest(t *testing.T) {
+	spec, err := New(testDocument())
+	require.NoError(t, err)
+	cases := []struct {
+		name             string
+		paramPath        string
+		paramQuery       url.Values
+		paramBody        string
+		returnViolations []Violation
+	}{
+		{
+			name:      "test valid request",
+			paramPath: "/items",
+			paramQuery: url.Values{
+				"mode": []string{"fast"},
+			},
+			paramBody: `{"name":"test","count":2,"tags":["red"],"labels":{"a":"b"},"value":[1.5],"updatedAt":null,"parent":null}`,
+		},
+		{
+			name:      "test read only and unknown properties",
+			paramPath: "/items",
+			paramBody: `{"id":"IT_1","name":"test","tags":[],"color":"red"}`,
+			returnViolations: []Violation{
+				{Field: "color", Message: "is not allowed"},
+				{Field: "id", Message: "is read only"},
+			},
+		},
+		{
+			name:      "test wrong types",
+			paramPath: "/items",
+			paramQuery: url.Values{
+				"mode": []string{"medium"},
+			},
+			paramBody: `{"name":1,"count":1.5,"tags":["green"],"labels":{"a":1},"parent":{}}`,
+			returnViolations: []Violation{
+				{Field: "mode", Message: "must be one of: fast, slow"},
+				{Field: "count", Message: "must be an integer"},
+				{Field: "labels.a", Message: "must be a string"},
+				{Field: "name", Message: "must be a string"},
+				{Field: "tags[0]", Message: "must be one of: red, blue"},
+			},
+		},
+		{
+			name:      "test missing properties aren't checked in requests",
+			paramPath: "/items",
+			paramBody: `{"name":"test"}`,
+		},
+		{
+			name:      "test not JSON",
+			paramPath: "/items",
+			paramBody: `{"name":"test"`,
+			returnViolations: []Violation{
+				{Message: "body is not valid JSON"},
+			},
+		},
+		{
+			name:      "test no body",
+			paramPath: "/items",
+			returnViolations: []Violation{
+				{Message: "body is required"},
+			},
+		},
+		{
+			name:      "test body that isn't JSON isn't checked",
+			paramPath: "/items:upload",
+			paramBody: "PK",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			operation, ok := spec.Find("POST", tc.paramPath)
+			require.True(t, ok)
+			violations := operation.ValidateRequest(tc.paramQuery, []byte(tc.paramBody))
+			require.Equal(t, tc.returnViolations, violations)
+		})
+	}
+}
+
+func TestValidateResponse(t *testing.T) {
+	spec, err := New(testDocument())
+	require.NoError(t, err)
+	cases := []struct {
+		name             string
+		paramPath        string
+		paramStatus      int
+		paramBody        string
+		returnViolations []Violation
+	}{
+		{
+			name:        "test valid response",
+			paramStatus: 200,
+			paramBody:   `{"result":{"id":"IT_1","name":"test","tags":["blue"],"updatedAt":"2020-01-02T15:04:05Z"},"meta":{"httpStatus":"200 - OK"}}`,
+		},
+		{
+			name:        "test read only properties are required in responses",
+			paramStatus: 200,
+			paramBody:   `{"result":{"name":"test","tags":[],"updatedAt":"yesterday","parent":{}},"meta":{"httpStatus":"200 - OK"}}`,
+			returnViolations: []Violation{
+				{Field: "result.id", Message: "is required"},
+				{Field: "result.parent.httpStatus", Message: "is required"},
+				{Field: "result.updatedAt", Message: "must be an RFC 3339 date-time"},
+			},
+		},
+		{
+			name:        "test null that isn't nullable",
+			paramStatus: 200,
+			paramBody:   `{"result":{"id":"IT_1","name":null,"tags":null},"meta":{"httpStatus":"200 - OK"}}`,
+			returnViolations: []Violation{
+				{Field: "result.name", Message: "must not be null"},
+				{Field: "result.tags", Message: "must not be null"},
+			},
+		},
+		{
+			name:        "test binary response isn't checked",
+			paramPath:   "/items:upload",
+			paramStatus: 200,
+			paramBody:   `{"openapi":"3.0.3"}`,
+		},
+		{
+			name:        "test status that isn't documented",
+			paramStatus: 400,
+			paramBody:   `{"meta":{"httpStatus":"400 - Bad Request"}}`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := "/items"
+			if tc.paramPath != "" {
+				path = tc.paramPath
+			}
+			operation, ok := spec.Find("POST", path)
+			require.True(t, ok)
+			violations := operation.ValidateResponse(tc.paramStatus, []byte(tc.paramBody))
+			require.Equal(t, tc.returnViolations, violations)
+		})
+	}
+}
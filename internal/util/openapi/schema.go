@@ -0,0 +1,231 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/worlve/sp-service/internal/util/openapi3"
+)
+
+// validator collects the violations of a single request or response.
+type validator struct {
+	spec *Spec
+	// request is set when checking a request, which can't set readOnly properties and doesn't need to send required ones.
+	request    bool
+	violations []Violation
+}
+
+func (v *validator) add(field, message string) {
+	v.violations = append(v.violations, Violation{Field: field, Message: message})
+}
+
+// validateBody checks the JSON body against the schema of the request body's or response's media type.
+func (v *validator) validateBody(schema *openapi3.Schema, body []byte) {
+	if schema == nil {
+		return
+	}
+	schema, err := v.spec.resolve(schema)
+	if err != nil {
+		v.add("", err.Error())
+		return
+	}
+	if schema.Format == "binary" {
+		return
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		v.add("", "body is required")
+		return
+	}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value interface{}
+	err = decoder.Decode(&value)
+	if err == nil {
+		_, err = decoder.Token()
+	}
+	if err != io.EOF {
+		v.add("", "body is not valid JSON")
+		return
+	}
+	v.validate("", schema, value)
+}
+
+// validateQuery checks a query parameter, which is always a string, against the type and enum of its schema.
+func (v *validator) validateQuery(name string, schema *openapi3.Schema, raw string) {
+	if schema == nil {
+		return
+	}
+	var value interface{} = raw
+	switch schema.Type {
+	case "integer":
+		if _, err := strconv.ParseInt(raw, 10, 64); err != nil {
+			v.add(name, "must be an integer")
+			return
+		}
+		value = json.Number(raw)
+	case "number":
+		if _, err := strconv.ParseFloat(raw, 64); err != nil {
+			v.add(name, "must be a number")
+			return
+		}
+		value = json.Number(raw)
+	case "boolean":
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			v.add(name, "must be a boolean")
+			return
+		}
+		value = parsed
+	}
+	v.validateEnum(name, schema, value)
+}
+
+func (v *validator) validate(field string, schema *openapi3.Schema, value interface{}) {
+	schema, err := v.spec.resolve(schema)
+	if err != nil {
+		v.add(field, err.Error())
+		return
+	}
+	if value == nil {
+		if schema.Nullable {
+			return
+		}
+		if schema.Type != "" {
+			v.add(field, "must not be null")
+			return
+		}
+		if len(schema.AllOf) == 0 {
+			return
+		}
+	}
+	if len(schema.AllOf) > 0 {
+		for _, part := range schema.AllOf {
+			v.validate(field, part, value)
+		}
+		return
+	}
+	switch schema.Type {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			v.add(field, "must be an object")
+			return
+		}
+		v.validateObject(field, schema, object)
+	case "array":
+		array, ok := value.([]interface{})
+		if !ok {
+			v.add(field, "must be an array")
+			return
+		}
+		if schema.Items == nil {
+			return
+		}
+		for i, item := range array {
+			v.validate(fmt.Sprintf("%v[%v]", field, i), schema.Items, item)
+		}
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			v.add(field, "must be a string")
+			return
+		}
+		if schema.Format == "date-time" {
+			if _, err := time.Parse(time.RFC3339, str); err != nil {
+				v.add(field, "must be an RFC 3339 date-time")
+				return
+			}
+		}
+	case "integer":
+		number, ok := value.(json.Number)
+		if !ok {
+			v.add(field, "must be an integer")
+			return
+		}
+		if _, err := number.Int64(); err != nil {
+			v.add(field, "must be an integer")
+			return
+		}
+	case "number":
+		if _, ok := value.(json.Number); !ok {
+			v.add(field, "must be a number")
+			return
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			v.add(field, "must be a boolean")
+			return
+		}
+	}
+	v.validateEnum(field, schema, value)
+}
+
+func (v *validator) validateObject(field string, schema *openapi3.Schema, object map[string]interface{}) {
+	// the schemas describe how the types are marshaled, so required properties are always in a response,
+	// but requests are decoded without them and their handlers say which ones are missing
+	for _, name := range schema.Required {
+		if _, ok := object[name]; ok || v.request {
+			continue
+		}
+		v.add(join(field, name), "is required")
+	}
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		property, ok := schema.Properties[name]
+		if !ok || property == nil {
+			// a property the schema doesn't list is only allowed when the schema sets additionalProperties
+			if schema.AdditionalProperties == nil {
+				v.add(join(field, name), "is not allowed")
+				continue
+			}
+			v.validate(join(field, name), schema.AdditionalProperties, object[name])
+			continue
+		}
+		if v.request && v.isReadOnly(property) {
+			v.add(join(field, name), "is read only")
+			continue
+		}
+		v.validate(join(field, name), property, object[name])
+	}
+}
+
+func (v *validator) validateEnum(field string, schema *openapi3.Schema, value interface{}) {
+	if len(schema.Enum) == 0 {
+		return
+	}
+	for _, allowed := range schema.Enum {
+		if allowed == fmt.Sprint(value) {
+			return
+		}
+	}
+	v.add(field, fmt.Sprintf("must be one of: %v", strings.Join(schema.Enum, ", ")))
+}
+
+// isReadOnly returns whether the property, or the schema it refers to, is readOnly.
+func (v *validator) isReadOnly(property *openapi3.Schema) bool {
+	if property.ReadOnly {
+		return true
+	}
+	resolved, err := v.spec.resolve(property)
+	if err != nil {
+		return false
+	}
+	return resolved.ReadOnly
+}
+
+func join(field, name string) string {
+	if field == "" {
+		return name
+	}
+	return field + "." + name
+}
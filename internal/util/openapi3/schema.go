@@ -0,0 +1,158 @@
+package openapi3
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	rawMessageType    = reflect.TypeOf(json.RawMessage{})
+	marshalerType     = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// Schemas reflects the schemas of Go types, keeping each named struct type in Components so it's only described once.
+type Schemas struct {
+	Components map[string]*Schema
+}
+
+// Ref returns a $ref to the named schema in the document's components.
+func Ref(name string) *Schema {
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// For returns the schema of the value's type, or the empty schema when value is nil.
+func (s *Schemas) For(value interface{}) *Schema {
+	if value == nil {
+		return &Schema{}
+	}
+	return s.forType(reflect.TypeOf(value))
+}
+
+// Define adds the schema of the value's type to the components as name, and returns a $ref to it.
+func (s *Schemas) Define(name string, value interface{}) *Schema {
+	s.init()
+	if _, ok := s.Components[name]; !ok {
+		s.Components[name] = s.forStruct(reflect.TypeOf(value))
+	}
+	return Ref(name)
+}
+
+func (s *Schemas) init() {
+	if s.Components == nil {
+		s.Components = map[string]*Schema{}
+	}
+}
+
+func (s *Schemas) forType(t reflect.Type) *Schema {
+	if t.Kind() == reflect.Ptr {
+		schema := s.forType(t.Elem())
+		if schema.Ref != "" {
+			return &Schema{AllOf: []*Schema{schema}, Nullable: true}
+		}
+		schema.Nullable = true
+		return schema
+	}
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == rawMessageType, t.Implements(marshalerType):
+		return &Schema{}
+	case t.Implements(textMarshalerType):
+		return &Schema{Type: "string"}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: s.forType(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: s.forType(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.forStruct(t)
+		}
+		s.init()
+		name := t.String()
+		if _, ok := s.Components[name]; !ok {
+			// set a placeholder first so types that refer to themselves end up as a $ref
+			s.Components[name] = &Schema{}
+			s.Components[name] = s.forStruct(t)
+		}
+		return Ref(name)
+	}
+	return &Schema{}
+}
+
+// forStruct returns the object schema of a struct, with the same properties encoding/json marshals.
+// Properties without omitempty are always sent, so they're required.
+func (s *Schemas) forStruct(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	s.addFields(schema, t)
+	return schema
+}
+
+func (s *Schemas) addFields(schema *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options := parseTag(tag)
+		fieldType := field.Type
+		if field.Anonymous && name == "" {
+			if fieldType.Kind() == reflect.Ptr {
+				fieldType = fieldType.Elem()
+			}
+			if fieldType.Kind() == reflect.Struct {
+				s.addFields(schema, fieldType)
+				continue
+			}
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		property := s.forType(fieldType)
+		if hasOption(options, "string") && property.Type != "" && property.Type != "string" {
+			property = &Schema{Type: "string"}
+		}
+		schema.Properties[name] = property
+		if !hasOption(options, "omitempty") {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
+
+func parseTag(tag string) (string, string) {
+	if i := strings.Index(tag, ","); i >= 0 {
+		return tag[:i], tag[i+1:]
+	}
+	return tag, ""
+}
+
+func hasOption(options, option string) bool {
+	for _, o := range strings.Split(options, ",") {
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
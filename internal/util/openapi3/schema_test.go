@@ -0,0 +1,94 @@
+package openapi3
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testTag string
+
+func (t testTag) MarshalText() ([]byte, error) {
+	return []byte(t), nil
+}
+
+type testBase struct {
+	ID string `json:"id"`
+}
+
+type testItem struct {
+	testBase
+	Name      string            `json:"name"`
+	Count     int               `json:"count,omitempty"`
+	Price     float64           `json:"price,string"`
+	Tags      []testTag         `json:"tags"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	Data      []byte            `json:"data,omitempty"`
+	Raw       json.RawMessage   `json:"raw,omitempty"`
+	UpdatedAt *time.Time        `json:"updatedAt"`
+	Parent    *testItem         `json:"parent,omitempty"`
+	Children  []testItem        `json:"children,omitempty"`
+	Internal  string            `json:"-"`
+	Untagged  bool
+	private   string
+}
+
+func TestFor(t *testing.T) {
+	itemRef := Ref("openapi3.testItem")
+	cases := []struct {
+		name             string
+		paramValue       interface{}
+		returnSchema     *Schema
+		returnComponents map[string]*Schema
+	}{
+		{
+			name:         "test nil",
+			returnSchema: &Schema{},
+		},
+		{
+			name:         "test scalar",
+			paramValue:   true,
+			returnSchema: &Schema{Type: "boolean"},
+		},
+		{
+			name:         "test anonymous struct",
+			paramValue:   struct{ A int }{},
+			returnSchema: &Schema{Type: "object", Properties: map[string]*Schema{"A": {Type: "integer"}}, Required: []string{"A"}},
+		},
+		{
+			name:         "test named struct",
+			paramValue:   []testItem{},
+			returnSchema: &Schema{Type: "array", Items: itemRef},
+			returnComponents: map[string]*Schema{
+				"openapi3.testItem": {
+					Type: "object",
+					Properties: map[string]*Schema{
+						"id":        {Type: "string"},
+						"name":      {Type: "string"},
+						"count":     {Type: "integer"},
+						"price":     {Type: "string"},
+						"tags":      {Type: "array", Items: &Schema{Type: "string"}},
+						"labels":    {Type: "object", AdditionalProperties: &Schema{Type: "string"}},
+						"data":      {Type: "string", Format: "byte"},
+						"raw":       {},
+						"updatedAt": {Type: "string", Format: "date-time", Nullable: true},
+						"parent":    {AllOf: []*Schema{itemRef}, Nullable: true},
+						"children":  {Type: "array", Items: itemRef},
+						"Untagged":  {Type: "boolean"},
+					},
+					Required: []string{"id", "name", "price", "tags", "updatedAt", "Untagged"},
+				},
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			schemas := Schemas{}
+			schema := schemas.For(tc.paramValue)
+			require.Equal(t, tc.returnSchema, schema)
+			require.Equal(t, tc.returnComponents, schemas.Components)
+		})
+	}
+}
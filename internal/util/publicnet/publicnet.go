@@ -0,0 +1,87 @@
+// Package publicnet keeps outgoing requests to user-given URLs, such as webhook deliveries, on the public internet,
+// so they can't be used to reach the service's own network or its cloud metadata endpoint.
+package publicnet
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// sharedAddressSpace is the carrier-grade NAT range, which isn't reachable from the public internet either.
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// IsPublicIP returns whether the IP is reachable on the public internet: it isn't loopback, private, link-local,
+// such as the 169.254.169.254 metadata endpoint, multicast, or unspecified.
+func IsPublicIP(ip net.IP) bool {
+	return !ip.IsLoopback() &&
+		!ip.IsPrivate() &&
+		!ip.IsLinkLocalUnicast() &&
+		!ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() &&
+		!ip.IsMulticast() &&
+		!ip.IsUnspecified() &&
+		!sharedAddressSpace.Contains(ip)
+}
+
+// CheckURL returns an error unless the URL is an absolute http or https URL whose host isn't a local name,
+// such as localhost or metadata.google.internal, or a non-public IP. Hosts are resolved when they're dialed, see Control.
+func CheckURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return errors.New("must be an absolute http or https URL")
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") || strings.HasSuffix(host, ".internal") {
+		return errors.Errorf("host %v is not public", host)
+	}
+	if ip := net.ParseIP(host); ip != nil && !IsPublicIP(ip) {
+		return errors.Errorf("host %v is not public", host)
+	}
+	return nil
+}
+
+// Control refuses connections to non-public IPs. It's a net.Dialer Control, so it checks the address a host
+// resolved to when it's dialed, after any DNS lookup.
+func Control(network, address string, c syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return errors.Wrapf(err, "invalid address %v", address)
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !IsPublicIP(ip) {
+		return errors.Errorf("refusing to connect to %v, it's not a public address", host)
+	}
+	return nil
+}
+
+// NewClient returns a client that only connects to public IPs, doesn't go through a proxy, and doesn't follow
+// redirects, returning the redirect response instead, so a public URL can't send it on to a private one.
+func NewClient(timeout time.Duration) *http.Client {
+	return newClient(timeout, Control)
+}
+
+func newClient(timeout time.Duration, control func(network, address string, c syscall.RawConn) error) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   timeout,
+		KeepAlive: 30 * time.Second,
+		Control:   control,
+	}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+			MaxIdleConns:        100,
+			IdleConnTimeout:     90 * time.Second,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
@@ -0,0 +1,98 @@
+package publicnet
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsPublicIP(t *testing.T) {
+	cases := []struct {
+		name         string
+		paramIP      string
+		returnPublic bool
+	}{
+		{name: "test public IPv4", paramIP: "93.184.216.34", returnPublic: true},
+		{name: "test public IPv6", paramIP: "2606:2800:220:1:248:1893:25c8:1946", returnPublic: true},
+		{name: "test loopback", paramIP: "127.0.0.1"},
+		{name: "test IPv6 loopback", paramIP: "::1"},
+		{name: "test private", paramIP: "10.1.2.3"},
+		{name: "test private 192.168", paramIP: "192.168.0.10"},
+		{name: "test IPv6 unique local", paramIP: "fd00::1"},
+		{name: "test metadata endpoint", paramIP: "169.254.169.254"},
+		{name: "test IPv4-mapped metadata endpoint", paramIP: "::ffff:169.254.169.254"},
+		{name: "test shared address space", paramIP: "100.64.0.1"},
+		{name: "test unspecified", paramIP: "0.0.0.0"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.returnPublic, IsPublicIP(net.ParseIP(tc.paramIP)))
+		})
+	}
+}
+
+func TestCheckURL(t *testing.T) {
+	cases := []struct {
+		name        string
+		paramURL    string
+		returnError bool
+	}{
+		{name: "test public host", paramURL: "https://example.com/hooks"},
+		{name: "test public IP", paramURL: "http://93.184.216.34:8080/hooks"},
+		{name: "test not http", paramURL: "ftp://example.com/hooks", returnError: true},
+		{name: "test relative", paramURL: "/hooks", returnError: true},
+		{name: "test localhost", paramURL: "http://localhost:8080/hooks", returnError: true},
+		{name: "test localhost subdomain", paramURL: "http://api.localhost/hooks", returnError: true},
+		{name: "test metadata host", paramURL: "http://metadata.google.internal/computeMetadata/v1/", returnError: true},
+		{name: "test loopback IP", paramURL: "http://127.0.0.1/hooks", returnError: true},
+		{name: "test private IP", paramURL: "http://10.0.0.5/hooks", returnError: true},
+		{name: "test metadata IP", paramURL: "http://169.254.169.254/latest/meta-data/", returnError: true},
+		{name: "test IPv6 loopback", paramURL: "http://[::1]:8080/hooks", returnError: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := CheckURL(tc.paramURL)
+			if tc.returnError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	var received int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received++
+	}))
+	defer server.Close()
+
+	_, err := NewClient(time.Second).Get(server.URL)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "not a public address")
+	require.Zero(t, received)
+}
+
+func TestNewClientRedirects(t *testing.T) {
+	var followed bool
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		followed = true
+	}))
+	defer target.Close()
+	redirector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL, http.StatusFound)
+	}))
+	defer redirector.Close()
+
+	// the test servers are on loopback, so only the redirects are checked
+	resp, err := newClient(time.Second, nil).Get(redirector.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusFound, resp.StatusCode)
+	require.False(t, followed)
+}
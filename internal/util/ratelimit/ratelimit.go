@@ -0,0 +1,106 @@
+// Package ratelimit limits how often a key can be used with token buckets.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/worlve/sp-service/internal/util/clock"
+)
+
+// sweepInterval is how often the MemoryStore forgets buckets that have refilled.
+const sweepInterval = time.Minute
+
+// Limit allows bursts of up to Requests, refilled at Requests per Per.
+type Limit struct {
+	Requests int
+	Per      time.Duration
+}
+
+// IsUnlimited is true when the limit doesn't restrict anything.
+func (l Limit) IsUnlimited() bool {
+	return l.Requests <= 0 || l.Per <= 0
+}
+
+// Store keeps the token buckets.
+// The MemoryStore limits each instance separately; a shared store limits across instances.
+type Store interface {
+	// Take takes a token from the key's bucket. When it's empty, it returns false and how long until a token is available.
+	Take(ctx context.Context, key string, limit Limit) (bool, time.Duration, error)
+}
+
+// MemoryStore keeps the token buckets in memory.
+type MemoryStore struct {
+	Clock     clock.Clock
+	mutex     sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	limit   Limit
+}
+
+// Take takes a token from the key's bucket. When it's empty, it returns false and how long until a token is available.
+func (s *MemoryStore) Take(ctx context.Context, key string, limit Limit) (bool, time.Duration, error) {
+	if limit.IsUnlimited() {
+		return true, 0, nil
+	}
+	now := s.Clock.Now()
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.buckets == nil {
+		s.buckets = map[string]*bucket{}
+		s.lastSweep = now
+	}
+	s.sweep(now)
+	b, ok := s.buckets[key]
+	if !ok || b.limit != limit {
+		b = &bucket{
+			tokens:  float64(limit.Requests),
+			updated: now,
+			limit:   limit,
+		}
+		s.buckets[key] = b
+	}
+	b.refill(now)
+	if b.tokens < 1 {
+		return false, b.timeUntilToken(), nil
+	}
+	b.tokens--
+	return true, 0, nil
+}
+
+// sweep forgets the buckets that have refilled, since they're the same as a new bucket.
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for key, b := range s.buckets {
+		if now.Sub(b.updated) >= b.limit.Per {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+func (b *bucket) rate() float64 {
+	return float64(b.limit.Requests) / float64(b.limit.Per)
+}
+
+func (b *bucket) refill(now time.Time) {
+	elapsed := now.Sub(b.updated)
+	if elapsed <= 0 {
+		return
+	}
+	b.tokens = math.Min(float64(b.limit.Requests), b.tokens+float64(elapsed)*b.rate())
+	b.updated = now
+}
+
+func (b *bucket) timeUntilToken() time.Duration {
+	return time.Duration(math.Ceil((1 - b.tokens) / b.rate()))
+}
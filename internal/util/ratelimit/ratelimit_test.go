@@ -0,0 +1,93 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/worlve/sp-service/internal/util/clock"
+)
+
+func TestMemoryStoreTake(t *testing.T) {
+	limit := Limit{Requests: 2, Per: time.Minute}
+	cases := []struct {
+		name             string
+		paramLimit       Limit
+		paramTakes       int
+		paramWait        time.Duration
+		returnAllowed    bool
+		returnRetryAfter time.Duration
+	}{
+		{
+			name:          "test burst up to the limit",
+			paramLimit:    limit,
+			paramTakes:    1,
+			returnAllowed: true,
+		},
+		{
+			name:             "test over the limit",
+			paramLimit:       limit,
+			paramTakes:       2,
+			returnAllowed:    false,
+			returnRetryAfter: 30 * time.Second,
+		},
+		{
+			name:             "test partially refilled",
+			paramLimit:       limit,
+			paramTakes:       2,
+			paramWait:        10 * time.Second,
+			returnAllowed:    false,
+			returnRetryAfter: 20 * time.Second,
+		},
+		{
+			name:          "test refilled",
+			paramLimit:    limit,
+			paramTakes:    2,
+			paramWait:     30 * time.Second,
+			returnAllowed: true,
+		},
+		{
+			name:          "test unlimited",
+			paramLimit:    Limit{},
+			paramTakes:    100,
+			returnAllowed: true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+			store := &MemoryStore{Clock: clock.MockClock{MockedTime: &now}}
+			ctx := context.Background()
+			for i := 0; i < tc.paramTakes; i++ {
+				allowed, _, err := store.Take(ctx, "key", tc.paramLimit)
+				require.NoError(t, err)
+				require.True(t, allowed)
+			}
+			now = now.Add(tc.paramWait)
+			allowed, retryAfter, err := store.Take(ctx, "key", tc.paramLimit)
+			require.NoError(t, err)
+			require.Equal(t, tc.returnAllowed, allowed)
+			require.Equal(t, tc.returnRetryAfter, retryAfter)
+			allowed, _, err = store.Take(ctx, "other key", tc.paramLimit)
+			require.NoError(t, err)
+			require.True(t, allowed)
+		})
+	}
+}
+
+func TestMemoryStoreSweep(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := &MemoryStore{Clock: clock.MockClock{MockedTime: &now}}
+	ctx := context.Background()
+	_, _, err := store.Take(ctx, "short", Limit{Requests: 1, Per: time.Second})
+	require.NoError(t, err)
+	_, _, err = store.Take(ctx, "long", Limit{Requests: 1, Per: time.Hour})
+	require.NoError(t, err)
+	now = now.Add(sweepInterval)
+	_, _, err = store.Take(ctx, "new", Limit{Requests: 1, Per: time.Second})
+	require.NoError(t, err)
+	require.Len(t, store.buckets, 2)
+	require.Contains(t, store.buckets, "long")
+	require.Contains(t, store.buckets, "new")
+}
@@ -0,0 +1,44 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+)
+
+// WriterExporter writes each span as a line of JSON, such as to stdout or a file.
+type WriterExporter struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+	closer  io.Closer
+}
+
+// NewWriterExporter returns a WriterExporter that writes to w.
+// If w is also an io.Closer, it's closed on Shutdown.
+func NewWriterExporter(w io.Writer) *WriterExporter {
+	e := &WriterExporter{
+		encoder: json.NewEncoder(w),
+	}
+	if closer, ok := w.(io.Closer); ok {
+		e.closer = closer
+	}
+	return e
+}
+
+// ExportSpan writes the span.
+func (e *WriterExporter) ExportSpan(span SpanData) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.encoder.Encode(span)
+}
+
+// Shutdown closes the underlying writer if it can be closed.
+func (e *WriterExporter) Shutdown(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.closer == nil {
+		return nil
+	}
+	return e.closer.Close()
+}
@@ -0,0 +1,244 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultOTLPEndpoint is where an OpenTelemetry collector accepts OTLP over HTTP by default.
+const DefaultOTLPEndpoint = "http://localhost:4318"
+
+const (
+	otlpTracesPath       = "/v1/traces"
+	otlpScopeName        = "github.com/worlve/sp-service"
+	otlpMaxBatchSize     = 512
+	otlpMaxQueueSize     = 4096
+	otlpStatusCodeError  = 2
+	otlpDefaultFlushTime = 5 * time.Second
+)
+
+// OTLPExporter sends spans in batches to an OpenTelemetry collector using OTLP/HTTP with JSON encoding.
+// Spans are dropped rather than blocking the caller if the collector can't keep up.
+type OTLPExporter struct {
+	url         string
+	serviceName string
+	client      *http.Client
+	spans       chan SpanData
+	flush       chan chan struct{}
+	done        chan struct{}
+	// OnError is called with any error sending a batch; errors are ignored if it's nil.
+	OnError func(err error)
+}
+
+// NewOTLPExporter returns an OTLPExporter that sends to the collector at endpoint, such as DefaultOTLPEndpoint,
+// and starts sending batches every flushInterval until Shutdown.
+func NewOTLPExporter(endpoint, serviceName string, flushInterval time.Duration) *OTLPExporter {
+	if flushInterval <= 0 {
+		flushInterval = otlpDefaultFlushTime
+	}
+	e := &OTLPExporter{
+		url:         strings.TrimSuffix(endpoint, "/") + otlpTracesPath,
+		serviceName: serviceName,
+		client:      &http.Client{Timeout: 10 * time.Second},
+		spans:       make(chan SpanData, otlpMaxQueueSize),
+		flush:       make(chan chan struct{}),
+		done:        make(chan struct{}),
+	}
+	go e.run(flushInterval)
+	return e
+}
+
+// ExportSpan queues the span to be sent with the next batch.
+func (e *OTLPExporter) ExportSpan(span SpanData) {
+	select {
+	case e.spans <- span:
+	default:
+	}
+}
+
+// Shutdown sends every queued span and stops the exporter.
+func (e *OTLPExporter) Shutdown(ctx context.Context) error {
+	flushed := make(chan struct{})
+	select {
+	case e.flush <- flushed:
+	case <-e.done:
+		return nil
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err(), "unable to send remaining spans")
+	}
+	select {
+	case <-flushed:
+		return nil
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err(), "unable to send remaining spans")
+	}
+}
+
+func (e *OTLPExporter) run(flushInterval time.Duration) {
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+	batch := make([]SpanData, 0, otlpMaxBatchSize)
+	send := func() {
+		if len(batch) == 0 {
+			return
+		}
+		err := e.send(batch)
+		if err != nil && e.OnError != nil {
+			e.OnError(err)
+		}
+		batch = batch[:0]
+	}
+	for {
+		select {
+		case span := <-e.spans:
+			batch = append(batch, span)
+			if len(batch) >= otlpMaxBatchSize {
+				send()
+			}
+		case <-ticker.C:
+			send()
+		case flushed := <-e.flush:
+			for len(e.spans) > 0 {
+				batch = append(batch, <-e.spans)
+				if len(batch) >= otlpMaxBatchSize {
+					send()
+				}
+			}
+			send()
+			close(e.done)
+			close(flushed)
+			return
+		}
+	}
+}
+
+func (e *OTLPExporter) send(batch []SpanData) error {
+	body, err := json.Marshal(newOTLPRequest(e.serviceName, batch))
+	if err != nil {
+		return errors.Wrap(err, "unable to encode spans")
+	}
+	resp, err := e.client.Post(e.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrapf(err, "unable to send %v spans", len(batch))
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return errors.Errorf("unable to send %v spans: collector responded with %v", len(batch), resp.Status)
+	}
+	return nil
+}
+
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpAttribute `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope struct {
+		Name string `json:"name"`
+	} `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	Kind              SpanKind        `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes,omitempty"`
+	Status            *otlpStatus     `json:"status,omitempty"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpAttribute struct {
+	Key   string                 `json:"key"`
+	Value map[string]interface{} `json:"value"`
+}
+
+func newOTLPRequest(serviceName string, batch []SpanData) otlpRequest {
+	scopeSpans := otlpScopeSpans{
+		Spans: make([]otlpSpan, 0, len(batch)),
+	}
+	scopeSpans.Scope.Name = otlpScopeName
+	for _, span := range batch {
+		s := otlpSpan{
+			TraceID:           span.TraceID,
+			SpanID:            span.SpanID,
+			ParentSpanID:      span.ParentSpanID,
+			Name:              span.Name,
+			Kind:              span.Kind,
+			StartTimeUnixNano: strconv.FormatInt(span.StartTime.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(span.EndTime.UnixNano(), 10),
+			Attributes:        newOTLPAttributes(span.Attributes),
+		}
+		if span.Err != "" {
+			s.Status = &otlpStatus{Code: otlpStatusCodeError, Message: span.Err}
+		}
+		scopeSpans.Spans = append(scopeSpans.Spans, s)
+	}
+	return otlpRequest{
+		ResourceSpans: []otlpResourceSpans{
+			{
+				Resource: otlpResource{
+					Attributes: newOTLPAttributes(map[string]interface{}{"service.name": serviceName}),
+				},
+				ScopeSpans: []otlpScopeSpans{scopeSpans},
+			},
+		},
+	}
+}
+
+func newOTLPAttributes(attributes map[string]interface{}) []otlpAttribute {
+	otlpAttributes := make([]otlpAttribute, 0, len(attributes))
+	for key, value := range attributes {
+		otlpAttributes = append(otlpAttributes, otlpAttribute{
+			Key:   key,
+			Value: newOTLPValue(value),
+		})
+	}
+	sort.Slice(otlpAttributes, func(i, j int) bool {
+		return otlpAttributes[i].Key < otlpAttributes[j].Key
+	})
+	return otlpAttributes
+}
+
+func newOTLPValue(value interface{}) map[string]interface{} {
+	switch v := value.(type) {
+	case string:
+		return map[string]interface{}{"stringValue": v}
+	case bool:
+		return map[string]interface{}{"boolValue": v}
+	case int:
+		return map[string]interface{}{"intValue": strconv.Itoa(v)}
+	case int64:
+		return map[string]interface{}{"intValue": strconv.FormatInt(v, 10)}
+	case float64:
+		return map[string]interface{}{"doubleValue": v}
+	default:
+		return map[string]interface{}{"stringValue": fmt.Sprint(v)}
+	}
+}
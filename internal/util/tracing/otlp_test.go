@@ -0,0 +1,86 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOTLPExporter(t *testing.T) {
+	var mu sync.Mutex
+	var requests []otlpRequest
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		var req otlpRequest
+		json.Unmarshal(body, &req)
+		mu.Lock()
+		defer mu.Unlock()
+		requests = append(requests, req)
+		paths = append(paths, r.URL.Path+" "+r.Header.Get("Content-Type"))
+	}))
+	defer server.Close()
+	exporter := NewOTLPExporter(server.URL+"/", "sp-service", time.Hour)
+	start := time.Unix(0, 1000)
+	exporter.ExportSpan(SpanData{
+		Name:         "db.select Page",
+		Kind:         SpanKindClient,
+		TraceID:      "4bf92f3577b34da6a3ce929d0e0e4736",
+		SpanID:       "00f067aa0ba902b7",
+		ParentSpanID: "00f067aa0ba902b8",
+		StartTime:    start,
+		EndTime:      start.Add(time.Microsecond),
+		Attributes:   map[string]interface{}{"db.sql.table": "Page", "rows": 3, "cached": false},
+		Err:          "failed",
+	})
+	require.NoError(t, exporter.Shutdown(context.Background()))
+	require.NoError(t, exporter.Shutdown(context.Background()))
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, []string{"/v1/traces application/json"}, paths)
+	require.Len(t, requests, 1)
+	resourceSpans := requests[0].ResourceSpans[0]
+	require.Equal(t, "service.name", resourceSpans.Resource.Attributes[0].Key)
+	require.Equal(t, "sp-service", resourceSpans.Resource.Attributes[0].Value["stringValue"])
+	spans := resourceSpans.ScopeSpans[0].Spans
+	require.Len(t, spans, 1)
+	require.Equal(t, otlpSpan{
+		TraceID:           "4bf92f3577b34da6a3ce929d0e0e4736",
+		SpanID:            "00f067aa0ba902b7",
+		ParentSpanID:      "00f067aa0ba902b8",
+		Name:              "db.select Page",
+		Kind:              SpanKindClient,
+		StartTimeUnixNano: "1000",
+		EndTimeUnixNano:   "2000",
+		Attributes: []otlpAttribute{
+			{Key: "cached", Value: map[string]interface{}{"boolValue": false}},
+			{Key: "db.sql.table", Value: map[string]interface{}{"stringValue": "Page"}},
+			{Key: "rows", Value: map[string]interface{}{"intValue": "3"}},
+		},
+		Status: &otlpStatus{Code: otlpStatusCodeError, Message: "failed"},
+	}, spans[0])
+}
+
+func TestOTLPExporterError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	var exportErr error
+	exporter := NewOTLPExporter(server.URL, "sp-service", time.Hour)
+	exporter.OnError = func(err error) {
+		exportErr = err
+	}
+	exporter.ExportSpan(SpanData{Name: "dropped"})
+	require.NoError(t, exporter.Shutdown(context.Background()))
+	require.Error(t, exportErr)
+	require.Equal(t, "unable to send 1 spans: collector responded with 503 Service Unavailable", errors.Cause(exportErr).Error())
+}
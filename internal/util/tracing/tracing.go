@@ -0,0 +1,233 @@
+// Package tracing records spans for the work done while handling a request, modeled after OpenTelemetry.
+// Spans are propagated through context.Context and handed to an Exporter as they end.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TraceParentHeaderKey is the W3C Trace Context header used to continue a trace from the caller.
+const TraceParentHeaderKey = "traceparent"
+
+// SpanKind describes the relationship of a span to its parent and children, matching the OTLP values.
+type SpanKind int
+
+// All the valid values for SpanKind
+const (
+	SpanKindInternal SpanKind = 1
+	SpanKindServer   SpanKind = 2
+	SpanKindClient   SpanKind = 3
+)
+
+// SpanData is a finished span as it is exported.
+type SpanData struct {
+	Name         string                 `json:"name"`
+	Kind         SpanKind               `json:"kind"`
+	TraceID      string                 `json:"traceId"`
+	SpanID       string                 `json:"spanId"`
+	ParentSpanID string                 `json:"parentSpanId,omitempty"`
+	StartTime    time.Time              `json:"startTime"`
+	EndTime      time.Time              `json:"endTime"`
+	Attributes   map[string]interface{} `json:"attributes,omitempty"`
+	Err          string                 `json:"error,omitempty"`
+}
+
+// Duration returns how long the span took.
+func (d SpanData) Duration() time.Duration {
+	return d.EndTime.Sub(d.StartTime)
+}
+
+// Exporter receives every span as it ends.
+type Exporter interface {
+	ExportSpan(span SpanData)
+	Shutdown(ctx context.Context) error
+}
+
+// Tracer starts spans and exports them when they end.
+type Tracer struct {
+	Exporter Exporter
+}
+
+var (
+	tracerMu      sync.RWMutex
+	defaultTracer *Tracer
+)
+
+// SetTracer sets the tracer used for spans started without a parent span on the context.
+// Spans aren't recorded until a tracer is set.
+func SetTracer(t *Tracer) {
+	tracerMu.Lock()
+	defer tracerMu.Unlock()
+	defaultTracer = t
+}
+
+func getTracer() *Tracer {
+	tracerMu.RLock()
+	defer tracerMu.RUnlock()
+	return defaultTracer
+}
+
+// Span is a single unit of work within a trace.
+// A nil Span is valid and does nothing, which is what StartSpan returns when tracing is off.
+type Span struct {
+	tracer *Tracer
+	mu     sync.Mutex
+	data   SpanData
+	ended  bool
+}
+
+type spanKeyType string
+
+const spanKey = spanKeyType("span")
+
+// remoteParent is the caller's span, as given by the traceparent header.
+type remoteParent struct {
+	traceID string
+	spanID  string
+}
+
+type remoteParentKeyType string
+
+const remoteParentKey = remoteParentKeyType("remoteParent")
+
+// StartSpan starts a span as a child of the span on the context, or as the root of a new trace if there isn't one.
+// The returned context carries the new span; End must be called on the span once the work is done.
+func StartSpan(ctx context.Context, name string, kind SpanKind) (context.Context, *Span) {
+	parent := SpanFromContext(ctx)
+	tracer := getTracer()
+	if parent != nil {
+		tracer = parent.tracer
+	}
+	if tracer == nil || tracer.Exporter == nil {
+		return ctx, nil
+	}
+	span := &Span{
+		tracer: tracer,
+		data: SpanData{
+			Name:       name,
+			Kind:       kind,
+			SpanID:     newID(8),
+			StartTime:  time.Now(),
+			Attributes: make(map[string]interface{}),
+		},
+	}
+	if parent != nil {
+		span.data.TraceID = parent.data.TraceID
+		span.data.ParentSpanID = parent.data.SpanID
+	} else if remote, ok := ctx.Value(remoteParentKey).(remoteParent); ok {
+		span.data.TraceID = remote.traceID
+		span.data.ParentSpanID = remote.spanID
+	} else {
+		span.data.TraceID = newID(16)
+	}
+	return context.WithValue(ctx, spanKey, span), span
+}
+
+// SpanFromContext returns the current span on the context, or nil if there isn't one.
+func SpanFromContext(ctx context.Context) *Span {
+	if ctx == nil {
+		return nil
+	}
+	span, _ := ctx.Value(spanKey).(*Span)
+	return span
+}
+
+// TraceID returns the id of the span's trace, or an empty string for a nil span.
+func (s *Span) TraceID() string {
+	if s == nil {
+		return ""
+	}
+	return s.data.TraceID
+}
+
+// SetName replaces the name of the span, such as once an HTTP route has been matched.
+func (s *Span) SetName(name string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Name = name
+}
+
+// SetAttribute sets a key value pair on the span. Values should be strings, bools, ints, or floats.
+func (s *Span) SetAttribute(key string, value interface{}) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Attributes[key] = value
+}
+
+// RecordError marks the span as failed with the given error; a nil error is ignored.
+func (s *Span) RecordError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Err = err.Error()
+}
+
+// End finishes the span and exports it. Only the first call has any effect.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.EndTime = time.Now()
+	data := s.data
+	s.mu.Unlock()
+	s.tracer.Exporter.ExportSpan(data)
+}
+
+// ContextWithTraceParent returns a context that continues the trace from the given traceparent header.
+// The context is returned as is if the header is empty or invalid.
+func ContextWithTraceParent(ctx context.Context, traceParent string) context.Context {
+	parts := strings.Split(strings.TrimSpace(traceParent), "-")
+	if len(parts) != 4 || parts[0] != "00" || !isHexID(parts[1], 16) || !isHexID(parts[2], 8) || !isHexID(parts[3], 1) {
+		return ctx
+	}
+	return context.WithValue(ctx, remoteParentKey, remoteParent{
+		traceID: parts[1],
+		spanID:  parts[2],
+	})
+}
+
+// TraceParent returns the traceparent header that continues the span's trace in another service.
+func (s *Span) TraceParent() string {
+	if s == nil {
+		return ""
+	}
+	return fmt.Sprintf("00-%v-%v-01", s.data.TraceID, s.data.SpanID)
+}
+
+func isHexID(s string, byteLength int) bool {
+	b, err := hex.DecodeString(s)
+	if err != nil || len(b) != byteLength {
+		return false
+	}
+	// all zero ids are invalid per the W3C spec, except for the flags.
+	if byteLength == 1 {
+		return true
+	}
+	return strings.Trim(s, "0") != ""
+}
+
+func newID(byteLength int) string {
+	b := make([]byte, byteLength)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,134 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingExporter struct {
+	mu    sync.Mutex
+	spans []SpanData
+}
+
+func (e *recordingExporter) ExportSpan(span SpanData) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = append(e.spans, span)
+}
+
+func (e *recordingExporter) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+func TestStartSpan(t *testing.T) {
+	exporter := &recordingExporter{}
+	SetTracer(&Tracer{Exporter: exporter})
+	defer SetTracer(nil)
+	ctx, root := StartSpan(context.Background(), "root", SpanKindServer)
+	childCtx, child := StartSpan(ctx, "child", SpanKindInternal)
+	_, grandchild := StartSpan(childCtx, "grandchild", SpanKindClient)
+	grandchild.SetAttribute("db.sql.table", "Page")
+	grandchild.RecordError(errors.New("failed"))
+	grandchild.End()
+	child.End()
+	root.SetName("renamed root")
+	root.End()
+	root.End()
+	require.Len(t, exporter.spans, 3)
+	g, c, r := exporter.spans[0], exporter.spans[1], exporter.spans[2]
+	require.Equal(t, "renamed root", r.Name)
+	require.Len(t, r.TraceID, 32)
+	require.Len(t, r.SpanID, 16)
+	require.Empty(t, r.ParentSpanID)
+	require.Equal(t, r.TraceID, c.TraceID)
+	require.Equal(t, r.SpanID, c.ParentSpanID)
+	require.Equal(t, r.TraceID, g.TraceID)
+	require.Equal(t, c.SpanID, g.ParentSpanID)
+	require.Equal(t, SpanKindClient, g.Kind)
+	require.Equal(t, "Page", g.Attributes["db.sql.table"])
+	require.Equal(t, "failed", g.Err)
+	require.False(t, g.EndTime.Before(g.StartTime))
+}
+
+func TestStartSpanWithoutTracer(t *testing.T) {
+	ctx, span := StartSpan(context.Background(), "off", SpanKindInternal)
+	require.Nil(t, span)
+	require.Nil(t, SpanFromContext(ctx))
+	span.SetName("ignored")
+	span.SetAttribute("ignored", true)
+	span.RecordError(errors.New("ignored"))
+	span.End()
+	require.Empty(t, span.TraceID())
+	require.Empty(t, span.TraceParent())
+}
+
+func TestContextWithTraceParent(t *testing.T) {
+	cases := []struct {
+		name               string
+		paramTraceParent   string
+		returnTraceID      string
+		returnParentSpanID string
+	}{
+		{
+			name:               "test valid traceparent continues the trace",
+			paramTraceParent:   "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			returnTraceID:      "4bf92f3577b34da6a3ce929d0e0e4736",
+			returnParentSpanID: "00f067aa0ba902b7",
+		},
+		{
+			name:             "test unsupported version starts a new trace",
+			paramTraceParent: "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		},
+		{
+			name:             "test all zero trace id starts a new trace",
+			paramTraceParent: "00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		},
+		{
+			name:             "test malformed traceparent starts a new trace",
+			paramTraceParent: "00-nothex-00f067aa0ba902b7-01",
+		},
+		{
+			name: "test missing traceparent starts a new trace",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			exporter := &recordingExporter{}
+			SetTracer(&Tracer{Exporter: exporter})
+			defer SetTracer(nil)
+			ctx := ContextWithTraceParent(context.Background(), tc.paramTraceParent)
+			_, span := StartSpan(ctx, "server", SpanKindServer)
+			span.End()
+			require.Len(t, exporter.spans, 1)
+			if tc.returnTraceID == "" {
+				require.Len(t, exporter.spans[0].TraceID, 32)
+				require.NotEqual(t, "4bf92f3577b34da6a3ce929d0e0e4736", exporter.spans[0].TraceID)
+				require.Empty(t, exporter.spans[0].ParentSpanID)
+				return
+			}
+			require.Equal(t, tc.returnTraceID, exporter.spans[0].TraceID)
+			require.Equal(t, tc.returnParentSpanID, exporter.spans[0].ParentSpanID)
+			require.Equal(t, "00-"+tc.returnTraceID+"-"+exporter.spans[0].SpanID+"-01", span.TraceParent())
+		})
+	}
+}
+
+func TestWriterExporter(t *testing.T) {
+	var buf bytes.Buffer
+	SetTracer(&Tracer{Exporter: NewWriterExporter(&buf)})
+	defer SetTracer(nil)
+	_, span := StartSpan(context.Background(), "written", SpanKindInternal)
+	span.SetAttribute("count", 2)
+	span.End()
+	var data SpanData
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &data))
+	require.Equal(t, "written", data.Name)
+	require.Equal(t, SpanKindInternal, data.Kind)
+	require.Equal(t, float64(2), data.Attributes["count"])
+}
@@ -1,7 +1,57 @@
 package transaction
 
+import (
+	"context"
+	"regexp"
+
+	"github.com/worlve/sp-service/internal/util/guidgen"
+	"go.uber.org/zap"
+)
+
+// RequestIDHeaderKey is the header a request ID is read from and returned on.
+const RequestIDHeaderKey = "X-Request-ID"
+
+// validRequestID limits propagated request IDs to something safe to log and echo back.
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
 // Transaction are the details that are attached to each request.
 type Transaction struct {
-	TransactionID   string
-	RequestID       string
+	TransactionID string
+	RequestID     string
+}
+
+// New returns the Transaction for a new request.
+// The given requestID, such as one from the X-Request-ID header, is kept so it propagates across services;
+// a new one is generated if it's empty or invalid. The TransactionID is always unique to this service's handling of the request.
+func New(requestID string) Transaction {
+	if !validRequestID.MatchString(requestID) {
+		requestID = guidgen.GenerateGUID("RQ", 24)
+	}
+	return Transaction{
+		TransactionID: guidgen.GenerateGUID("TX", 24),
+		RequestID:     requestID,
+	}
+}
+
+// LogFields returns the fields every log for the transaction should include.
+func (t Transaction) LogFields() []zap.Field {
+	return []zap.Field{
+		zap.String("requestId", t.RequestID),
+		zap.String("transactionId", t.TransactionID),
+	}
+}
+
+type transactionKeyType string
+
+const transactionKey = transactionKeyType("transaction")
+
+// SetOnContext sets the Transaction on the context.
+func SetOnContext(ctx context.Context, t Transaction) context.Context {
+	return context.WithValue(ctx, transactionKey, t)
+}
+
+// GetFromContext returns the Transaction from the context.
+func GetFromContext(ctx context.Context) (Transaction, bool) {
+	t, ok := ctx.Value(transactionKey).(Transaction)
+	return t, ok
 }
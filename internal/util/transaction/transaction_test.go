@@ -0,0 +1,56 @@
+package transaction
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	cases := []struct {
+		name           string
+		paramRequestID string
+		keepsRequestID bool
+	}{
+		{
+			name:           "test incoming request id is propagated",
+			paramRequestID: "abc-123.DEF:4_5",
+			keepsRequestID: true,
+		},
+		{
+			name:           "test missing request id is generated",
+			paramRequestID: "",
+		},
+		{
+			name:           "test request id with unsafe characters is replaced",
+			paramRequestID: "abc\n123",
+		},
+		{
+			name:           "test request id that is too long is replaced",
+			paramRequestID: strings.Repeat("a", 129),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tx := New(tc.paramRequestID)
+			if tc.keepsRequestID {
+				require.Equal(t, tc.paramRequestID, tx.RequestID)
+			} else {
+				require.True(t, strings.HasPrefix(tx.RequestID, "RQ_"), tx.RequestID)
+			}
+			require.True(t, strings.HasPrefix(tx.TransactionID, "TX_"), tx.TransactionID)
+			require.NotEqual(t, tx.TransactionID, New(tc.paramRequestID).TransactionID)
+		})
+	}
+}
+
+func TestContext(t *testing.T) {
+	_, ok := GetFromContext(context.Background())
+	require.False(t, ok)
+	tx := New("REQUEST")
+	got, ok := GetFromContext(SetOnContext(context.Background(), tx))
+	require.True(t, ok)
+	require.Equal(t, tx, got)
+}
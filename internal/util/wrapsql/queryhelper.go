@@ -1,11 +1,98 @@
 package wrapsql
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
+	"time"
 
 	"github.com/worlve/sp-service/internal/stores/storeerror"
+	"github.com/worlve/sp-service/internal/util/tracing"
+	"github.com/pkg/errors"
 )
 
+// DB is the functionality shared by *sql.DB and *sql.Tx that the query helpers rely on.
+// This allows the same helpers (and the stores using them) to run either directly against
+// the database or within a transaction.
+type DB interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// QueryObserver is told the operation, table, duration, and error of every query run through the helpers.
+type QueryObserver func(operation, table string, duration time.Duration, err error)
+
+var queryObserver QueryObserver = func(operation, table string, duration time.Duration, err error) {}
+
+// SetQueryObserver sets the observer for every query run through the helpers, such as for recording metrics.
+// It isn't safe to call while queries are running so it should be set before the stores are used.
+func SetQueryObserver(observer QueryObserver) {
+	queryObserver = observer
+}
+
+// startQuery starts a span for the query as a child of the span on ctx.
+// The returned func must be called with the query's error to end the span and tell the observer about the query.
+// It returns the error as a storeerror.Canceled when ctx was canceled or its deadline passed.
+func startQuery(ctx context.Context, operation, table, query string) func(err error) error {
+	start := time.Now()
+	_, span := tracing.StartSpan(ctx, fmt.Sprintf("db.%v %v", operation, table), tracing.SpanKindClient)
+	span.SetAttribute("db.system", "mysql")
+	span.SetAttribute("db.operation", operation)
+	span.SetAttribute("db.sql.table", table)
+	span.SetAttribute("db.statement", query)
+	return func(err error) error {
+		err = storeerror.FromContext(ctx, err)
+		span.RecordError(err)
+		span.End()
+		queryObserver(operation, table, time.Since(start), err)
+		return err
+	}
+}
+
+// WithinTransaction runs fn within a transaction, committing it if fn returns nil and rolling it back otherwise.
+// If db is already a transaction, fn joins that transaction and the caller remains responsible for committing it.
+func WithinTransaction(ctx context.Context, db DB, fn func(tx DB) error) (err error) {
+	sqlDB, ok := db.(*sql.DB)
+	if !ok {
+		return fn(db)
+	}
+	_, span := tracing.StartSpan(ctx, "db.transaction", tracing.SpanKindClient)
+	span.SetAttribute("db.system", "mysql")
+	defer func() {
+		err = storeerror.FromContext(ctx, err)
+		span.RecordError(err)
+		span.End()
+	}()
+	tx, err := sqlDB.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "unable to begin transaction")
+	}
+	err = fn(tx)
+	if err != nil {
+		rollbackErr := tx.Rollback()
+		if rollbackErr != nil {
+			return errors.Wrapf(err, "unable to rollback transaction: %v", rollbackErr)
+		}
+		return err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return errors.Wrap(err, "unable to commit transaction")
+	}
+	return nil
+}
+
+// Select runs the select statement with the given args injected.
+func Select(ctx context.Context, db DB, statement SelectStatement, args ...interface{}) (rows *sql.Rows, err error) {
+	queryString := GetSelectString(statement)
+	done := startQuery(ctx, "select", statement.FromTable, queryString)
+	defer func() {
+		err = done(err)
+	}()
+	return db.QueryContext(ctx, queryString, args...)
+}
+
 // GetSingleRow extracts the given sql.Rows to return a single row scanned into the given columns
 func GetSingleRow(guid string, rows *sql.Rows, queryErr error, columns ...interface{}) error {
 	if queryErr != nil {
@@ -28,16 +115,20 @@ func GetSingleRow(guid string, rows *sql.Rows, queryErr error, columns ...interf
 }
 
 // ExecSingleInsert executes a single INSERT command and returns the lastInsertID
-func ExecSingleInsert(db *sql.DB, query InsertQuery) (lastInsertID int64, err error) {
+func ExecSingleInsert(ctx context.Context, db DB, query InsertQuery) (lastInsertID int64, err error) {
 	var statement *sql.Stmt
 	var result sql.Result
 	queryString, orderedValues := GetInsertString(query)
-	statement, err = db.Prepare(queryString)
+	done := startQuery(ctx, "insert", query.IntoTable, queryString)
+	defer func() {
+		err = done(err)
+	}()
+	statement, err = db.PrepareContext(ctx, queryString)
 	if err != nil {
 		return
 	}
 	defer statement.Close()
-	result, err = statement.Exec(orderedValues...)
+	result, err = statement.ExecContext(ctx, orderedValues...)
 	if err != nil {
 		return
 	}
@@ -46,15 +137,19 @@ func ExecSingleInsert(db *sql.DB, query InsertQuery) (lastInsertID int64, err er
 }
 
 // ExecBatchInsert executes a batch INSERT command
-func ExecBatchInsert(db *sql.DB, query BatchInsertQuery) (err error) {
+func ExecBatchInsert(ctx context.Context, db DB, query BatchInsertQuery) (err error) {
 	var statement *sql.Stmt
 	queryString, orderedValues := GetBatchInsertString(query)
-	statement, err = db.Prepare(queryString)
+	done := startQuery(ctx, "batchInsert", query.IntoTable, queryString)
+	defer func() {
+		err = done(err)
+	}()
+	statement, err = db.PrepareContext(ctx, queryString)
 	if err != nil {
 		return
 	}
 	defer statement.Close()
-	_, err = statement.Exec(orderedValues...)
+	_, err = statement.ExecContext(ctx, orderedValues...)
 	if err != nil {
 		return
 	}
@@ -62,27 +157,55 @@ func ExecBatchInsert(db *sql.DB, query BatchInsertQuery) (err error) {
 }
 
 // ExecSingleUpdate executes a single UPDATE command
-func ExecSingleUpdate(db *sql.DB, query UpdateQuery, whereClauseInjectedValues ...interface{}) (err error) {
+func ExecSingleUpdate(ctx context.Context, db DB, query UpdateQuery, whereClauseInjectedValues ...interface{}) (err error) {
 	var statement *sql.Stmt
 	queryString, orderedValues := GetUpdateString(query, whereClauseInjectedValues...)
-	statement, err = db.Prepare(queryString)
+	done := startQuery(ctx, "update", query.UpdateTable, queryString)
+	defer func() {
+		err = done(err)
+	}()
+	statement, err = db.PrepareContext(ctx, queryString)
 	if err != nil {
 		return
 	}
 	defer statement.Close()
-	_, err = statement.Exec(orderedValues...)
+	_, err = statement.ExecContext(ctx, orderedValues...)
 	return
 }
 
+// ExecUpdate executes an UPDATE command and returns the number of rows it changed.
+func ExecUpdate(ctx context.Context, db DB, query UpdateQuery, whereClauseInjectedValues ...interface{}) (rowsAffected int64, err error) {
+	var statement *sql.Stmt
+	queryString, orderedValues := GetUpdateString(query, whereClauseInjectedValues...)
+	done := startQuery(ctx, "update", query.UpdateTable, queryString)
+	defer func() {
+		err = done(err)
+	}()
+	statement, err = db.PrepareContext(ctx, queryString)
+	if err != nil {
+		return
+	}
+	defer statement.Close()
+	result, err := statement.ExecContext(ctx, orderedValues...)
+	if err != nil {
+		return
+	}
+	return result.RowsAffected()
+}
+
 // ExecDelete executes a DELETE command
-func ExecDelete(db *sql.DB, query DeleteQuery, whereClauseInjectedValues ...interface{}) (err error) {
+func ExecDelete(ctx context.Context, db DB, query DeleteQuery, whereClauseInjectedValues ...interface{}) (err error) {
 	var statement *sql.Stmt
 	queryString, orderedValues := GetDeleteString(query, whereClauseInjectedValues...)
-	statement, err = db.Prepare(queryString)
+	done := startQuery(ctx, "delete", query.FromTable, queryString)
+	defer func() {
+		err = done(err)
+	}()
+	statement, err = db.PrepareContext(ctx, queryString)
 	if err != nil {
 		return
 	}
 	defer statement.Close()
-	_, err = statement.Exec(orderedValues...)
+	_, err = statement.ExecContext(ctx, orderedValues...)
 	return
 }
@@ -0,0 +1,76 @@
+package spclient
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// ExportParams are the params for Export.
+type ExportParams struct {
+	// Format is the archive's format; it's zip when empty.
+	Format ArchiveFormat
+	// VersionID limits the archive to the pages in that version when it's set.
+	VersionID string
+}
+
+// ImportParams are the params for Import.
+type ImportParams struct {
+	// Format is the archive's format; it's zip when empty.
+	Format ArchiveFormat
+	// Conflict is how page ids that already exist are handled; the service remaps them when it's empty.
+	Conflict ConflictStrategy
+}
+
+// Export returns an archive of all of the user's pages.
+func (c Client) Export(ctx context.Context, params ExportParams) ([]byte, error) {
+	query := formatQuery(params.Format)
+	if params.VersionID != "" {
+		query.Set("versionId", params.VersionID)
+	}
+	resp, err := c.send(ctx, request{
+		method: http.MethodGet,
+		path:   "/export",
+		query:  query,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if resp.statusCode >= 300 {
+		return nil, decode(resp, nil)
+	}
+	return resp.body, nil
+}
+
+// Import imports an archive created by Export into the user's account.
+func (c Client) Import(ctx context.Context, archive []byte, params ImportParams) (ImportResult, error) {
+	query := formatQuery(params.Format)
+	if params.Conflict != "" {
+		query.Set("conflict", string(params.Conflict))
+	}
+	contentType := "application/zip"
+	if params.Format == ArchiveFormatNDJSON {
+		contentType = "application/x-ndjson"
+	}
+	resp, err := c.send(ctx, request{
+		method:      http.MethodPost,
+		path:        "/import",
+		query:       query,
+		body:        archive,
+		contentType: contentType,
+	})
+	if err != nil {
+		return ImportResult{}, err
+	}
+	var result ImportResult
+	err = decode(resp, &result)
+	return result, err
+}
+
+func formatQuery(format ArchiveFormat) url.Values {
+	query := url.Values{}
+	if format != "" {
+		query.Set("format", string(format))
+	}
+	return query
+}
@@ -0,0 +1,258 @@
+// Package spclient is a Go client for the sp-service API.
+//
+// Each call sends the client's auth headers, unwraps the result from the response's {result, meta} envelope,
+// and returns an *Error for any response that isn't a 2xx. Idempotent calls are retried after network errors
+// and responses that say to try again later.
+package spclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Headers the service authenticates requests with.
+const (
+	AdminAuthSecretHeaderKey = "X-ADMIN-AUTH-SECRET"
+	UserIDHeaderKey          = "X-USER-ID"
+)
+
+// Defaults for the Client's optional fields.
+const (
+	DefaultAPIPath      = "api"
+	DefaultMaxRetries   = 2
+	DefaultRetryBackoff = 100 * time.Millisecond
+)
+
+// Client calls the sp-service API. Only BaseURL is required.
+type Client struct {
+	// BaseURL is the scheme and host the service is served at, such as http://localhost:8782.
+	BaseURL string
+	// APIPath is the path the API is served under; it's DefaultAPIPath when empty.
+	APIPath         string
+	AdminAuthSecret string
+	// UserID is the user the calls act as; without one, calls are made as an admin.
+	UserID string
+	// HTTPClient sends the requests; it's http.DefaultClient when nil.
+	HTTPClient *http.Client
+	// MaxRetries is how many times an idempotent call is retried; it's DefaultMaxRetries when 0, and calls aren't retried when it's negative.
+	MaxRetries int
+	// RetryBackoff is the wait before the first retry, which doubles for each retry after; it's DefaultRetryBackoff when 0.
+	// A Retry-After header from the service is used instead when there is one.
+	RetryBackoff time.Duration
+}
+
+// AsUser returns a copy of the client that acts as the user.
+func (c Client) AsUser(userID string) Client {
+	c.UserID = userID
+	return c
+}
+
+type request struct {
+	method      string
+	path        string
+	query       url.Values
+	body        []byte
+	contentType string
+}
+
+type response struct {
+	statusCode int
+	header     http.Header
+	body       []byte
+}
+
+type envelope struct {
+	Result json.RawMessage `json:"result"`
+	Meta   struct {
+		HTTPStatus string       `json:"httpStatus"`
+		Code       Code         `json:"code"`
+		Message    string       `json:"message"`
+		Details    []FieldError `json:"details"`
+		RequestID  string       `json:"requestId"`
+	} `json:"meta"`
+}
+
+// call sends a request with a JSON body and decodes the response's result into result, when it isn't nil.
+func (c Client) call(ctx context.Context, method, path string, query url.Values, body interface{}, result interface{}) error {
+	req := request{
+		method: method,
+		path:   path,
+		query:  query,
+	}
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return errors.Wrap(err, "failed to encode request body")
+		}
+		req.body = encoded
+		req.contentType = "application/json"
+	}
+	resp, err := c.send(ctx, req)
+	if err != nil {
+		return err
+	}
+	return decode(resp, result)
+}
+
+// decode returns an *Error for a response that isn't a 2xx, and decodes the result into result even when it is an error,
+// since some calls, like BatchPages, respond with a result either way.
+func decode(resp response, result interface{}) error {
+	var e envelope
+	if err := json.Unmarshal(resp.body, &e); err != nil {
+		if resp.statusCode >= 300 {
+			return &Error{StatusCode: resp.statusCode, Message: http.StatusText(resp.statusCode)}
+		}
+		return errors.Wrap(err, "failed to decode response")
+	}
+	if result != nil && len(e.Result) > 0 {
+		if err := json.Unmarshal(e.Result, result); err != nil {
+			return errors.Wrap(err, "failed to decode response result")
+		}
+	}
+	if resp.statusCode >= 300 {
+		return &Error{
+			StatusCode: resp.statusCode,
+			Code:       e.Meta.Code,
+			Message:    e.Meta.Message,
+			Details:    e.Meta.Details,
+			RequestID:  e.Meta.RequestID,
+		}
+	}
+	return nil
+}
+
+// send makes the request, retrying it when it's idempotent and failed in a way that may not happen again.
+func (c Client) send(ctx context.Context, req request) (response, error) {
+	maxRetries := c.maxRetries()
+	if !isIdempotent(req.method) {
+		maxRetries = 0
+	}
+	backoff := c.retryBackoff()
+	for attempt := 0; ; attempt++ {
+		resp, err := c.sendOnce(ctx, req)
+		if attempt >= maxRetries || !shouldRetry(resp, err) || ctx.Err() != nil {
+			return resp, err
+		}
+		wait := backoff << uint(attempt)
+		if retryAfter, ok := getRetryAfter(resp); ok {
+			wait = retryAfter
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return resp, errors.Wrapf(ctx.Err(), "failed to %v %v", req.method, req.path)
+		case <-timer.C:
+		}
+	}
+}
+
+func (c Client) sendOnce(ctx context.Context, req request) (response, error) {
+	r, err := http.NewRequest(req.method, c.url(req.path, req.query), bytes.NewReader(req.body))
+	if err != nil {
+		return response{}, errors.Wrapf(err, "failed to create request to %v %v", req.method, req.path)
+	}
+	r = r.WithContext(ctx)
+	if req.contentType != "" {
+		r.Header.Set("Content-Type", req.contentType)
+	}
+	if c.AdminAuthSecret != "" {
+		r.Header.Set(AdminAuthSecretHeaderKey, c.AdminAuthSecret)
+	}
+	if c.UserID != "" {
+		r.Header.Set(UserIDHeaderKey, c.UserID)
+	}
+	resp, err := c.httpClient().Do(r)
+	if err != nil {
+		return response{}, errors.Wrapf(err, "failed to %v %v", req.method, req.path)
+	}
+	defer resp.Body.Close()
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return response{}, errors.Wrapf(err, "failed to read response to %v %v", req.method, req.path)
+	}
+	return response{
+		statusCode: resp.StatusCode,
+		header:     resp.Header,
+		body:       respBody,
+	}, nil
+}
+
+func (c Client) url(path string, query url.Values) string {
+	apiPath := c.APIPath
+	if apiPath == "" {
+		apiPath = DefaultAPIPath
+	}
+	u := fmt.Sprintf("%v/%v%v", strings.TrimRight(c.BaseURL, "/"), strings.Trim(apiPath, "/"), path)
+	if len(query) > 0 {
+		u = u + "?" + query.Encode()
+	}
+	return u
+}
+
+func (c Client) httpClient() *http.Client {
+	if c.HTTPClient == nil {
+		return http.DefaultClient
+	}
+	return c.HTTPClient
+}
+
+func (c Client) maxRetries() int {
+	if c.MaxRetries == 0 {
+		return DefaultMaxRetries
+	}
+	if c.MaxRetries < 0 {
+		return 0
+	}
+	return c.MaxRetries
+}
+
+func (c Client) retryBackoff() time.Duration {
+	if c.RetryBackoff == 0 {
+		return DefaultRetryBackoff
+	}
+	return c.RetryBackoff
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+		return true
+	default:
+		return false
+	}
+}
+
+// shouldRetry is true for network errors, and for responses from the service or a proxy that say to try again later.
+func shouldRetry(resp response, err error) bool {
+	if err != nil {
+		return true
+	}
+	switch resp.statusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+func getRetryAfter(resp response) (time.Duration, bool) {
+	if resp.header == nil {
+		return 0, false
+	}
+	seconds, err := strconv.Atoi(resp.header.Get("Retry-After"))
+	if err != nil || seconds < 0 {
+		return 0, false
+	}
+	return time.Duration(seconds) * time.Second, true
+}
@@ -0,0 +1,439 @@
+package spclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/worlve/sp-service/internal/api"
+	archivehandler "github.com/worlve/sp-service/internal/api/handlers/archive"
+	pagehandler "github.com/worlve/sp-service/internal/api/handlers/page"
+	pagedetailhandler "github.com/worlve/sp-service/internal/api/handlers/pagedetail"
+	"github.com/worlve/sp-service/internal/models/appuser"
+	"github.com/worlve/sp-service/internal/models/pagetemplate"
+	"github.com/worlve/sp-service/internal/models/property"
+	"github.com/worlve/sp-service/internal/models/version"
+	archiveservice "github.com/worlve/sp-service/internal/services/archive"
+	pageservice "github.com/worlve/sp-service/internal/services/page"
+	pagedetailservice "github.com/worlve/sp-service/internal/services/pagedetail"
+	"github.com/worlve/sp-service/internal/stores/memorystore"
+	"github.com/worlve/sp-service/internal/util/clock"
+	"github.com/worlve/sp-service/internal/util/openapi"
+)
+
+const testAdminAuthSecret = "test-secret"
+
+// newTestServer serves the real router over the in-memory stores, checking every request and response against the API spec.
+func newTestServer(t *testing.T) *httptest.Server {
+	memdb := memorystore.NewDB()
+	memdb.AddUser(appuser.User{GUID: "UR_1", Email: "one@worlve.com"})
+	memdb.AddUser(appuser.User{GUID: "UR_2", Email: "two@worlve.com"})
+	memdb.AddVersion(version.Version{GUID: "VR_1", Name: "Default"})
+	memdb.AddVersion(version.Version{GUID: "VR_2", Name: "New Campaign Changes"})
+	memdb.AddPageTemplate(pagetemplate.PageTemplate{GUID: "PGT_1", Name: "Default"})
+	memdb.AddProperty(property.Property{Key: "population", Type: property.TypeNumber})
+	memdb.AddProperty(property.Property{Key: "description", Type: property.TypeString})
+	pageStore := memorystore.NewPageStore(memdb)
+	pageDetailStore := memorystore.NewPageDetailStore(memdb)
+	pageTemplateStore := memorystore.NewPageTemplateStore(memdb)
+	versionStore := memorystore.NewVersionStore(memdb)
+	userStore := memorystore.NewUserStore(memdb)
+	unitOfWork := memorystore.NewUnitOfWork(memdb)
+	var routerHandlers []api.RouterHandler
+	routerHandlers = append(routerHandlers, pagehandler.PageRouterHandlers("api", pageservice.PageService{
+		PageStore:         pageStore,
+		PageTemplateStore: pageTemplateStore,
+		VersionStore:      versionStore,
+		UserStore:         userStore,
+		UnitOfWork:        unitOfWork,
+	})...)
+	routerHandlers = append(routerHandlers, pagedetailhandler.PageDetailRouterHandlers("api", pagedetailservice.PageDetailService{
+		UnitOfWork: unitOfWork,
+	})...)
+	routerHandlers = append(routerHandlers, archivehandler.ArchiveRouterHandlers("api", archiveservice.ArchiveService{
+		PageStore:         pageStore,
+		PageDetailStore:   pageDetailStore,
+		PageTemplateStore: pageTemplateStore,
+		VersionStore:      versionStore,
+		UserStore:         userStore,
+		UnitOfWork:        unitOfWork,
+		Clock:             clock.RealClock{},
+	})...)
+	router := api.NewRouter("api", "static/test", routerHandlers)
+	spec, err := openapi.New(router.OpenAPI)
+	require.NoError(t, err)
+	return httptest.NewServer(&api.Handler{
+		AuthN:   api.AuthN{AdminAuthSecret: testAdminAuthSecret},
+		AuthZ:   api.AuthZ{APIPath: "api"},
+		Router:  router,
+		APIPath: "api",
+		SpecValidator: &api.SpecValidator{
+			Spec:   spec,
+			Strict: true,
+		},
+	})
+}
+
+func newTestClient(server *httptest.Server) Client {
+	return Client{
+		BaseURL:         server.URL,
+		AdminAuthSecret: testAdminAuthSecret,
+		UserID:          "UR_1",
+		RetryBackoff:    time.Millisecond,
+	}
+}
+
+func TestPages(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+	client := newTestClient(server)
+	ctx := context.Background()
+
+	pageID, err := client.CreatePage(ctx, CreatePageParams{
+		Title:          "test title",
+		VersionID:      "VR_1",
+		PageTemplateID: "PGT_1",
+		Permission:     PermissionPrivate,
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, pageID)
+
+	require.NoError(t, client.UpdatePage(ctx, pageID, UpdatePageParams{Summary: "test summary"}))
+	page, err := client.GetPage(ctx, pageID)
+	require.NoError(t, err)
+	require.Equal(t, "test title", page.Title)
+	require.Equal(t, "test summary", page.Summary)
+	require.Equal(t, PermissionPrivate, page.Permission)
+
+	properties := []Property{
+		{Key: "population", Type: PropertyTypeNumber, Value: float64(2000)},
+		{Key: "description", Type: PropertyTypeString, Value: "test description"},
+	}
+	require.NoError(t, client.ReplacePageProperties(ctx, pageID, properties))
+	gotProperties, err := client.GetPageProperties(ctx, pageID)
+	require.NoError(t, err)
+	require.Equal(t, properties, gotProperties)
+
+	entirePage, err := client.GetEntirePage(ctx, pageID)
+	require.NoError(t, err)
+	require.Equal(t, "VR_1", entirePage.Version.GUID)
+	require.Equal(t, "PGT_1", entirePage.PageTemplate.GUID)
+
+	err = client.SetPageDetail(ctx, pageID, "DT_1", SetPageDetailParams{
+		Title:      "test detail",
+		Partitions: []Partition{{Type: "h1", Value: "test heading"}},
+	})
+	detailErr, ok := err.(*Error)
+	require.True(t, ok, "only the page's existing details can be set: %v", err)
+	require.Equal(t, CodeNotFound, detailErr.Code)
+
+	otherPages, err := client.AsUser("UR_2").GetPages(ctx, "")
+	require.NoError(t, err)
+	require.Equal(t, 0, otherPages.Total)
+
+	require.NoError(t, client.RemovePage(ctx, pageID))
+	removed, err := client.GetRemovedPages(ctx, "")
+	require.NoError(t, err)
+	require.Equal(t, 1, removed.Total)
+	require.Equal(t, pageID, removed.Batch[0].GUID)
+	require.NotNil(t, removed.Batch[0].DeletedAt)
+
+	require.NoError(t, client.RestorePage(ctx, pageID))
+	_, err = client.GetPage(ctx, pageID)
+	require.NoError(t, err)
+
+	require.NoError(t, client.RemovePage(ctx, pageID))
+	require.NoError(t, client.PurgePage(ctx, pageID))
+	_, err = client.GetPage(ctx, pageID)
+	require.Error(t, err)
+	apiErr, ok := err.(*Error)
+	require.True(t, ok, "a purged page is gone: %v", err)
+	require.NotEqual(t, 0, apiErr.StatusCode)
+}
+
+func TestPageIterator(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+	client := newTestClient(server)
+	ctx := context.Background()
+	var created []string
+	for i := 0; i < 23; i++ {
+		pageID, err := client.CreatePage(ctx, CreatePageParams{
+			Title:          "test title",
+			VersionID:      "VR_1",
+			PageTemplateID: "PGT_1",
+			Permission:     PermissionPrivate,
+		})
+		require.NoError(t, err)
+		created = append(created, pageID)
+	}
+	first, err := client.GetPages(ctx, "")
+	require.NoError(t, err)
+	require.True(t, len(first.Batch) < len(created), "the pages span more than one batch")
+	require.NotNil(t, first.NextBatch)
+
+	var iterated []string
+	pages := client.Pages()
+	for pages.Next(ctx) {
+		iterated = append(iterated, pages.Page().GUID)
+	}
+	require.NoError(t, pages.Err())
+	require.Equal(t, len(created), pages.Total())
+	sort.Strings(created)
+	sort.Strings(iterated)
+	require.Equal(t, created, iterated)
+
+	otherPages := client.AsUser("UR_2").Pages()
+	require.False(t, otherPages.Next(ctx))
+	require.NoError(t, otherPages.Err())
+
+	unauthenticated := Client{BaseURL: server.URL}.Pages()
+	require.False(t, unauthenticated.Next(ctx))
+	require.True(t, IsCode(unauthenticated.Err(), CodeUnauthenticated))
+}
+
+func TestBatchPages(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+	client := newTestClient(server)
+	ctx := context.Background()
+	pageID, err := client.CreatePage(ctx, CreatePageParams{
+		Title:          "test title",
+		VersionID:      "VR_1",
+		PageTemplateID: "PGT_1",
+		Permission:     PermissionPrivate,
+	})
+	require.NoError(t, err)
+	cases := []struct {
+		name         string
+		paramParams  BatchPagesParams
+		returnCodes  []Code
+		returnStatus int
+	}{
+		{
+			name: "test successful batch",
+			paramParams: BatchPagesParams{
+				Operations: []BatchOperation{
+					{Type: BatchOperationCreate, Title: "test batch title", VersionID: "VR_1", PageTemplateID: "PGT_1", Permission: PermissionPublic},
+					{Type: BatchOperationPermission, GUID: pageID, Permission: PermissionPublic},
+				},
+			},
+			returnCodes: []Code{"", ""},
+		},
+		{
+			name: "test failed atomic batch",
+			paramParams: BatchPagesParams{
+				Atomic: true,
+				Operations: []BatchOperation{
+					{Type: BatchOperationUpdate, GUID: pageID, Title: "test new title"},
+					{Type: BatchOperationRemove, GUID: "PG_MISSING"},
+				},
+			},
+			returnCodes:  []Code{CodeFailedDependency, CodeForbidden},
+			returnStatus: http.StatusForbidden,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := client.BatchPages(ctx, tc.paramParams)
+			if tc.returnStatus == 0 {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				require.Equal(t, tc.returnStatus, err.(*Error).StatusCode)
+			}
+			var codes []Code
+			for _, operationResult := range result.Results {
+				codes = append(codes, operationResult.Code)
+			}
+			require.Equal(t, tc.returnCodes, codes)
+		})
+	}
+}
+
+func TestExportImport(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+	client := newTestClient(server)
+	ctx := context.Background()
+	pageID, err := client.CreatePage(ctx, CreatePageParams{
+		Title:          "test title",
+		VersionID:      "VR_1",
+		PageTemplateID: "PGT_1",
+		Permission:     PermissionPrivate,
+	})
+	require.NoError(t, err)
+	archive, err := client.Export(ctx, ExportParams{Format: ArchiveFormatNDJSON})
+	require.NoError(t, err)
+	require.Contains(t, string(archive), pageID)
+	versionArchive, err := client.Export(ctx, ExportParams{Format: ArchiveFormatNDJSON, VersionID: "VR_2"})
+	require.NoError(t, err)
+	require.NotContains(t, string(versionArchive), pageID)
+
+	result, err := client.Import(ctx, archive, ImportParams{Format: ArchiveFormatNDJSON, Conflict: ConflictSkip})
+	require.NoError(t, err)
+	require.Equal(t, []string{pageID}, result.Skipped)
+
+	_, err = client.Import(ctx, archive, ImportParams{Format: ArchiveFormatNDJSON, Conflict: ConflictFail})
+	require.True(t, IsCode(err, CodeAlreadyExists), "a conflicting page fails the import: %v", err)
+}
+
+func TestErrors(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+	cases := []struct {
+		name          string
+		paramClient   Client
+		paramParams   CreatePageParams
+		returnStatus  int
+		returnCode    Code
+		returnDetails []FieldError
+	}{
+		{
+			name:         "test wrong admin auth secret",
+			paramClient:  Client{BaseURL: server.URL, AdminAuthSecret: "wrong", UserID: "UR_1"},
+			returnStatus: http.StatusUnauthorized,
+			returnCode:   CodeUnauthenticated,
+		},
+		{
+			name:        "test invalid field",
+			paramClient: newTestClient(server),
+			paramParams: CreatePageParams{
+				VersionID:      "VR_1",
+				PageTemplateID: "PGT_1",
+				Permission:     PermissionPrivate,
+			},
+			returnStatus:  http.StatusBadRequest,
+			returnCode:    CodeValidationFailed,
+			returnDetails: []FieldError{{Field: "title", Message: "must provide title"}},
+		},
+		{
+			name:        "test invalid value",
+			paramClient: newTestClient(server),
+			paramParams: CreatePageParams{
+				Title:          "test title",
+				VersionID:      "VR_1",
+				PageTemplateID: "PGT_1",
+				Permission:     "XX",
+			},
+			returnStatus: http.StatusBadRequest,
+			returnCode:   CodeValidationFailed,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tc.paramClient.CreatePage(context.Background(), tc.paramParams)
+			require.Error(t, err)
+			e, ok := err.(*Error)
+			require.True(t, ok, "%T is an *Error", err)
+			require.Equal(t, tc.returnStatus, e.StatusCode)
+			require.Equal(t, tc.returnCode, e.Code)
+			require.NotEmpty(t, e.RequestID)
+			if tc.returnDetails != nil {
+				require.Equal(t, tc.returnDetails, e.Details)
+			}
+		})
+	}
+}
+
+func TestRetries(t *testing.T) {
+	cases := []struct {
+		name           string
+		paramMethod    string
+		paramFailures  int
+		paramStatus    int
+		paramRetries   int
+		returnAttempts int
+		returnErr      bool
+	}{
+		{
+			name:           "test idempotent call is retried",
+			paramMethod:    http.MethodGet,
+			paramFailures:  2,
+			paramStatus:    http.StatusServiceUnavailable,
+			returnAttempts: 3,
+		},
+		{
+			name:           "test retries run out",
+			paramMethod:    http.MethodDelete,
+			paramFailures:  5,
+			paramStatus:    http.StatusTooManyRequests,
+			paramRetries:   1,
+			returnAttempts: 2,
+			returnErr:      true,
+		},
+		{
+			name:           "test retries are turned off",
+			paramMethod:    http.MethodGet,
+			paramFailures:  1,
+			paramStatus:    http.StatusBadGateway,
+			paramRetries:   -1,
+			returnAttempts: 1,
+			returnErr:      true,
+		},
+		{
+			name:           "test call that isn't idempotent isn't retried",
+			paramMethod:    http.MethodPost,
+			paramFailures:  1,
+			paramStatus:    http.StatusServiceUnavailable,
+			returnAttempts: 1,
+			returnErr:      true,
+		},
+		{
+			name:           "test error that won't change isn't retried",
+			paramMethod:    http.MethodGet,
+			paramFailures:  1,
+			paramStatus:    http.StatusInternalServerError,
+			returnAttempts: 1,
+			returnErr:      true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			attempts := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				attempts++
+				require.Equal(t, testAdminAuthSecret, r.Header.Get(AdminAuthSecretHeaderKey))
+				require.Equal(t, "UR_1", r.Header.Get(UserIDHeaderKey))
+				if attempts <= tc.paramFailures {
+					w.Header().Set("Retry-After", "0")
+					w.WriteHeader(tc.paramStatus)
+					w.Write([]byte(`{"meta":{"httpStatus":"failed","code":"UNAVAILABLE"}}`))
+					return
+				}
+				w.Write([]byte(`{"meta":{"httpStatus":"200 - OK"}}`))
+			}))
+			defer server.Close()
+			client := newTestClient(server)
+			client.MaxRetries = tc.paramRetries
+			err := client.call(context.Background(), tc.paramMethod, "/pages", nil, nil, nil)
+			require.Equal(t, tc.returnAttempts, attempts)
+			require.Equal(t, tc.returnErr, err != nil)
+		})
+	}
+}
+
+func TestCodes(t *testing.T) {
+	codes := map[api.Code]Code{
+		api.CodeInvalidRequest:      CodeInvalidRequest,
+		api.CodeValidationFailed:    CodeValidationFailed,
+		api.CodeUnauthenticated:     CodeUnauthenticated,
+		api.CodeForbidden:           CodeForbidden,
+		api.CodeNotFound:            CodeNotFound,
+		api.CodeMethodNotAllowed:    CodeMethodNotAllowed,
+		api.CodeAlreadyExists:       CodeAlreadyExists,
+		api.CodeFailedDependency:    CodeFailedDependency,
+		api.CodeRateLimited:         CodeRateLimited,
+		api.CodeClientClosedRequest: CodeClientClosedRequest,
+		api.CodeInternal:            CodeInternal,
+		api.CodeUnavailable:         CodeUnavailable,
+		api.CodeTimedOut:            CodeTimedOut,
+	}
+	for apiCode, code := range codes {
+		require.Equal(t, string(apiCode), string(code))
+	}
+}
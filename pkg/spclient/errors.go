@@ -0,0 +1,63 @@
+package spclient
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// Code is the stable code the service sends with each error, in the response's meta.
+type Code string
+
+// Codes the service sends; see the API docs for when each is used.
+const (
+	CodeInvalidRequest      Code = "INVALID_REQUEST"
+	CodeValidationFailed    Code = "VALIDATION_FAILED"
+	CodeUnauthenticated     Code = "UNAUTHENTICATED"
+	CodeForbidden           Code = "FORBIDDEN"
+	CodeNotFound            Code = "NOT_FOUND"
+	CodeMethodNotAllowed    Code = "METHOD_NOT_ALLOWED"
+	CodeAlreadyExists       Code = "ALREADY_EXISTS"
+	CodeFailedDependency    Code = "FAILED_DEPENDENCY"
+	CodeRateLimited         Code = "RATE_LIMITED"
+	CodeClientClosedRequest Code = "CLIENT_CLOSED_REQUEST"
+	CodeInternal            Code = "INTERNAL"
+	CodeUnavailable         Code = "UNAVAILABLE"
+	CodeTimedOut            Code = "TIMED_OUT"
+)
+
+// Error is a response from the service with a status other than 2xx.
+type Error struct {
+	StatusCode int
+	Code       Code
+	Message    string
+	// Details are the invalid fields when Code is CodeValidationFailed.
+	Details   []FieldError
+	RequestID string
+}
+
+// FieldError is why a single field of the request is invalid.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	message := fmt.Sprintf("sp-service responded %v", e.StatusCode)
+	if e.Code != "" {
+		message = fmt.Sprintf("%v %v", message, e.Code)
+	}
+	if e.Message != "" {
+		message = fmt.Sprintf("%v: %v", message, e.Message)
+	}
+	if e.RequestID != "" {
+		message = fmt.Sprintf("%v (request %v)", message, e.RequestID)
+	}
+	return message
+}
+
+// IsCode is true when err is an *Error with the code, even if it has been wrapped.
+func IsCode(err error, code Code) bool {
+	e, ok := errors.Cause(err).(*Error)
+	return ok && e.Code == code
+}
@@ -0,0 +1,206 @@
+package spclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// CreatePageParams are the params for CreatePage.
+type CreatePageParams struct {
+	Title          string     `json:"title"`
+	Summary        string     `json:"summary"`
+	VersionID      string     `json:"versionId"`
+	PageTemplateID string     `json:"pageTemplateId"`
+	Permission     Permission `json:"permission"`
+}
+
+// UpdatePageParams are the params for UpdatePage. Fields left empty aren't changed.
+type UpdatePageParams struct {
+	Title          string     `json:"title,omitempty"`
+	Summary        string     `json:"summary,omitempty"`
+	VersionID      string     `json:"versionId,omitempty"`
+	PageTemplateID string     `json:"pageTemplateId,omitempty"`
+	Permission     Permission `json:"permission,omitempty"`
+}
+
+// SetPageDetailParams are the params for SetPageDetail.
+type SetPageDetailParams struct {
+	Title      string      `json:"title"`
+	Summary    string      `json:"summary"`
+	Partitions []Partition `json:"partitions"`
+}
+
+// BatchPagesParams are the params for BatchPages. When Atomic is set, every operation is run in a single transaction.
+type BatchPagesParams struct {
+	Atomic     bool             `json:"atomic"`
+	Operations []BatchOperation `json:"operations"`
+}
+
+// CreatePage creates a page and returns its id.
+func (c Client) CreatePage(ctx context.Context, params CreatePageParams) (string, error) {
+	var result struct {
+		GUID string `json:"id"`
+	}
+	err := c.call(ctx, http.MethodPost, "/pages", nil, params, &result)
+	return result.GUID, err
+}
+
+// GetPage returns the page without its properties and details.
+func (c Client) GetPage(ctx context.Context, pageID string) (ReducedPage, error) {
+	var result ReducedPage
+	err := c.call(ctx, http.MethodGet, pagePath(pageID), nil, nil, &result)
+	return result, err
+}
+
+// GetEntirePage returns the page with its properties and details.
+func (c Client) GetEntirePage(ctx context.Context, pageID string) (Page, error) {
+	var result Page
+	err := c.call(ctx, http.MethodGet, pagePath(pageID)+"/full", nil, nil, &result)
+	return result, err
+}
+
+// UpdatePage updates the page with the params that aren't empty.
+func (c Client) UpdatePage(ctx context.Context, pageID string, params UpdatePageParams) error {
+	return c.call(ctx, http.MethodPatch, pagePath(pageID), nil, params, nil)
+}
+
+// RemovePage moves the page to the trash.
+func (c Client) RemovePage(ctx context.Context, pageID string) error {
+	return c.call(ctx, http.MethodDelete, pagePath(pageID), nil, nil, nil)
+}
+
+// GetPages returns a single batch of the user's pages. Pass the previous batch's NextBatch.ParamValue as nextBatchID
+// to get the batch after it, or use Pages to iterate over every page.
+func (c Client) GetPages(ctx context.Context, nextBatchID string) (PageBatch, error) {
+	return c.getPageBatch(ctx, "/pages", nextBatchQuery(nil, nextBatchID))
+}
+
+// Pages iterates over every one of the user's pages.
+func (c Client) Pages() *PageIterator {
+	return &PageIterator{client: c, path: "/pages"}
+}
+
+// GetRemovedPages returns a single batch of the user's pages in the trash; see GetPages.
+func (c Client) GetRemovedPages(ctx context.Context, nextBatchID string) (PageBatch, error) {
+	return c.getPageBatch(ctx, "/trash/pages", nextBatchQuery(nil, nextBatchID))
+}
+
+// RemovedPages iterates over every one of the user's pages in the trash.
+func (c Client) RemovedPages() *PageIterator {
+	return &PageIterator{client: c, path: "/trash/pages"}
+}
+
+// RestorePage restores the page from the trash.
+func (c Client) RestorePage(ctx context.Context, pageID string) error {
+	return c.call(ctx, http.MethodPost, fmt.Sprintf("/trash%v/restore", pagePath(pageID)), nil, nil, nil)
+}
+
+// PurgePage permanently deletes the page from the trash.
+func (c Client) PurgePage(ctx context.Context, pageID string) error {
+	return c.call(ctx, http.MethodDelete, "/trash"+pagePath(pageID), nil, nil, nil)
+}
+
+// BatchPages runs the operations in a single request. When the batch is atomic and an operation fails,
+// the result is returned along with the *Error, so each operation's outcome can still be checked.
+func (c Client) BatchPages(ctx context.Context, params BatchPagesParams) (BatchPagesResult, error) {
+	var result BatchPagesResult
+	err := c.call(ctx, http.MethodPost, "/pages:batch", nil, params, &result)
+	return result, err
+}
+
+// GetPageProperties returns the page's properties, in order.
+func (c Client) GetPageProperties(ctx context.Context, pageID string) ([]Property, error) {
+	var result []Property
+	err := c.call(ctx, http.MethodGet, pagePath(pageID)+"/properties", nil, nil, &result)
+	return result, err
+}
+
+// ReplacePageProperties replaces every one of the page's properties with the properties, in order.
+func (c Client) ReplacePageProperties(ctx context.Context, pageID string, properties []Property) error {
+	if properties == nil {
+		properties = []Property{}
+	}
+	return c.call(ctx, http.MethodPut, pagePath(pageID)+"/properties", nil, properties, nil)
+}
+
+// SetPageDetail sets the title and summary of one of the page's details; a detail the page doesn't have is NOT_FOUND.
+func (c Client) SetPageDetail(ctx context.Context, pageID, detailID string, params SetPageDetailParams) error {
+	return c.call(ctx, http.MethodPut, fmt.Sprintf("%v/details/%v", pagePath(pageID), url.PathEscape(detailID)), nil, params, nil)
+}
+
+func (c Client) getPageBatch(ctx context.Context, path string, query url.Values) (PageBatch, error) {
+	var result PageBatch
+	err := c.call(ctx, http.MethodGet, path, query, nil, &result)
+	return result, err
+}
+
+func pagePath(pageID string) string {
+	return "/pages/" + url.PathEscape(pageID)
+}
+
+func nextBatchQuery(nextBatch *NextBatch, nextBatchID string) url.Values {
+	if nextBatch != nil {
+		return url.Values{nextBatch.ParamKey: []string{nextBatch.ParamValue}}
+	}
+	if nextBatchID != "" {
+		return url.Values{"nextBatchId": []string{nextBatchID}}
+	}
+	return nil
+}
+
+// PageIterator gets a list of pages a batch at a time, as they're needed.
+//
+//	pages := client.Pages()
+//	for pages.Next(ctx) {
+//		page := pages.Page()
+//	}
+//	err := pages.Err()
+type PageIterator struct {
+	client    Client
+	path      string
+	started   bool
+	batch     []ReducedPage
+	nextBatch *NextBatch
+	page      ReducedPage
+	total     int
+	err       error
+}
+
+// Next moves to the next page, getting the next batch when needed. It's false after the last page or an error.
+func (it *PageIterator) Next(ctx context.Context) bool {
+	for len(it.batch) == 0 {
+		if it.err != nil || (it.started && it.nextBatch == nil) {
+			return false
+		}
+		query := nextBatchQuery(it.nextBatch, "")
+		batch, err := it.client.getPageBatch(ctx, it.path, query)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		it.started = true
+		it.batch = batch.Batch
+		it.nextBatch = batch.NextBatch
+		it.total = batch.Total
+	}
+	it.page = it.batch[0]
+	it.batch = it.batch[1:]
+	return true
+}
+
+// Page is the current page.
+func (it *PageIterator) Page() ReducedPage {
+	return it.page
+}
+
+// Total is the number of pages in the list, as of the last batch.
+func (it *PageIterator) Total() int {
+	return it.total
+}
+
+// Err is the error that stopped the iteration, if any.
+func (it *PageIterator) Err() error {
+	return it.err
+}
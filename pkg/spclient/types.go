@@ -0,0 +1,170 @@
+package spclient
+
+import "time"
+
+// Permission is who can see a page.
+type Permission string
+
+// All the valid values for Permission
+const (
+	PermissionPrivate    Permission = "PR"
+	PermissionPublic     Permission = "PU"
+	PermissionPublicOnly Permission = "PO"
+	PermissionLinkOnly   Permission = "LO"
+)
+
+// ReducedPage is a page without its properties and details, as the page lists and GetPage return it.
+type ReducedPage struct {
+	GUID           string     `json:"id"`
+	VersionID      string     `json:"versionId"`
+	PageTemplateID string     `json:"pageTemplateId"`
+	Title          string     `json:"title"`
+	Summary        string     `json:"summary"`
+	Permission     Permission `json:"permission"`
+	CreatedAt      *time.Time `json:"createdAt"`
+	UpdatedAt      *time.Time `json:"updatedAt"`
+	DeletedAt      *time.Time `json:"deletedAt,omitempty"`
+}
+
+// Page is the entire page, as GetEntirePage returns it.
+type Page struct {
+	GUID         string       `json:"id"`
+	Version      Version      `json:"version"`
+	PageTemplate PageTemplate `json:"pageTemplate"`
+	Title        string       `json:"title"`
+	Summary      string       `json:"summary"`
+	Permission   Permission   `json:"permission"`
+	Properties   []Property   `json:"properties"`
+	Details      []PageDetail `json:"details"`
+	CreatedAt    *time.Time   `json:"createdAt"`
+	UpdatedAt    *time.Time   `json:"updatedAt"`
+	DeletedAt    *time.Time   `json:"deletedAt,omitempty"`
+}
+
+// Version is the version of the world a page belongs to.
+type Version struct {
+	GUID       string `json:"id"`
+	Name       string `json:"name"`
+	ParentGUID string `json:"parentId"`
+}
+
+// PageTemplate is the template a page is laid out with.
+type PageTemplate struct {
+	GUID string `json:"guid"`
+	Name string `json:"name"`
+}
+
+// PropertyType is the type of a property's value.
+type PropertyType string
+
+// All the valid values for PropertyType
+const (
+	PropertyTypeNumber PropertyType = "number"
+	PropertyTypeString PropertyType = "string"
+)
+
+// Property is a key/value pair on a page. Value is a float64 for number properties and a string otherwise.
+type Property struct {
+	Key   string       `json:"key"`
+	Type  PropertyType `json:"type"`
+	Value interface{}  `json:"value"`
+}
+
+// PageDetail is a single detail of a page, made up of partitions.
+type PageDetail struct {
+	GUID       string      `json:"id"`
+	Title      string      `json:"title"`
+	Summary    string      `json:"summary"`
+	Partitions []Partition `json:"partitions"`
+}
+
+// Partition is a piece of a page detail, such as a paragraph, an image, or a list of partitions.
+type Partition struct {
+	Type       string      `json:"type"`
+	Value      string      `json:"value,omitempty"`
+	Partitions []Partition `json:"partitions,omitempty"`
+	Items      []Partition `json:"items,omitempty"`
+	AltText    string      `json:"altText,omitempty"`
+	Link       string      `json:"link,omitempty"`
+	Relation   string      `json:"relation,omitempty"`
+	Color      string      `json:"color,omitempty"`
+}
+
+// PageBatch is a single batch of a list of pages.
+type PageBatch struct {
+	Batch []ReducedPage `json:"batch"`
+	Total int           `json:"total"`
+	// NextBatch is how to get the next batch, and is nil for the last batch.
+	NextBatch *NextBatch `json:"nextBatch,omitempty"`
+}
+
+// NextBatch is the query parameter to send to get the next batch.
+type NextBatch struct {
+	ParamKey   string `json:"paramKey"`
+	ParamValue string `json:"paramValue"`
+}
+
+// BatchOperationType is what a BatchOperation does.
+type BatchOperationType string
+
+// All the valid values for BatchOperationType
+const (
+	BatchOperationCreate     BatchOperationType = "create"
+	BatchOperationUpdate     BatchOperationType = "update"
+	BatchOperationPermission BatchOperationType = "permission"
+	BatchOperationRemove     BatchOperationType = "remove"
+)
+
+// BatchOperation is a single operation for BatchPages. GUID is the page to change, and must be empty to create a page.
+type BatchOperation struct {
+	Type           BatchOperationType `json:"op"`
+	GUID           string             `json:"id,omitempty"`
+	Title          string             `json:"title,omitempty"`
+	Summary        string             `json:"summary,omitempty"`
+	VersionID      string             `json:"versionId,omitempty"`
+	Permission     Permission         `json:"permission,omitempty"`
+	PageTemplateID string             `json:"pageTemplateId,omitempty"`
+}
+
+// BatchPagesResult is the result of each operation of BatchPages, in order.
+type BatchPagesResult struct {
+	Atomic  bool                   `json:"atomic"`
+	Results []BatchOperationResult `json:"results"`
+}
+
+// BatchOperationResult is the result of a single operation of BatchPages.
+type BatchOperationResult struct {
+	Index      int                `json:"index"`
+	Type       BatchOperationType `json:"op"`
+	GUID       string             `json:"id,omitempty"`
+	HTTPStatus string             `json:"httpStatus"`
+	Code       Code               `json:"code,omitempty"`
+	Message    string             `json:"message,omitempty"`
+}
+
+// ArchiveFormat is the file format of an archive.
+type ArchiveFormat string
+
+// All the valid values for ArchiveFormat
+const (
+	ArchiveFormatZip    ArchiveFormat = "zip"
+	ArchiveFormatNDJSON ArchiveFormat = "ndjson"
+)
+
+// ConflictStrategy is how Import handles page ids from the archive that already exist.
+type ConflictStrategy string
+
+// All the valid values for ConflictStrategy
+const (
+	ConflictRemap ConflictStrategy = "remap"
+	ConflictSkip  ConflictStrategy = "skip"
+	ConflictFail  ConflictStrategy = "fail"
+)
+
+// ImportResult is the outcome of a successful Import.
+type ImportResult struct {
+	// Pages maps each imported page's id in the archive to its id after the import.
+	Pages map[string]string `json:"pages"`
+	// Skipped are the ids of the pages in the archive that were not imported due to a conflict.
+	Skipped []string `json:"skipped"`
+}